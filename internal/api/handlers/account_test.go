@@ -57,22 +57,61 @@ func (m *mockAccountService) UpdateTransaction(context.Context, uuid.UUID, uuid.
 func (m *mockAccountService) DeleteTransaction(context.Context, uuid.UUID, uuid.UUID) error {
 	return nil
 }
-func (m *mockAccountService) CreateCategory(context.Context, *transaction.CreateCategoryRequest) (*transaction.Category, error) {
+func (m *mockAccountService) CreateCategory(context.Context, uuid.UUID, *transaction.CreateCategoryRequest) (*transaction.Category, error) {
 	return nil, nil
 }
-func (m *mockAccountService) GetCategory(context.Context, uuid.UUID) (*transaction.Category, error) {
+func (m *mockAccountService) GetCategory(context.Context, uuid.UUID, uuid.UUID) (*transaction.Category, error) {
 	return nil, nil
 }
-func (m *mockAccountService) GetCategories(context.Context, int, int) ([]transaction.Category, error) {
+func (m *mockAccountService) GetCategories(context.Context, uuid.UUID, int, int) ([]transaction.Category, error) {
 	return nil, nil
 }
-func (m *mockAccountService) GetDefaultCategories(context.Context) ([]transaction.Category, error) {
+func (m *mockAccountService) GetDefaultCategories(context.Context, string) ([]transaction.Category, error) {
 	return nil, nil
 }
-func (m *mockAccountService) UpdateCategory(context.Context, uuid.UUID, *transaction.CreateCategoryRequest) (*transaction.Category, error) {
+func (m *mockAccountService) UpdateCategory(context.Context, uuid.UUID, uuid.UUID, *transaction.CreateCategoryRequest) (*transaction.Category, error) {
 	return nil, nil
 }
-func (m *mockAccountService) DeleteCategory(context.Context, uuid.UUID) error {
+func (m *mockAccountService) DeleteCategory(context.Context, uuid.UUID, uuid.UUID, *uuid.UUID) error {
+	return nil
+}
+func (m *mockAccountService) MergeCategory(context.Context, uuid.UUID, uuid.UUID, *transaction.MergeCategoryRequest) (*transaction.Category, error) {
+	return nil, nil
+}
+func (m *mockAccountService) GetCategoryTree(context.Context, uuid.UUID) ([]transaction.CategoryNode, error) {
+	return nil, nil
+}
+func (m *mockAccountService) MoveCategory(context.Context, uuid.UUID, uuid.UUID, *transaction.MoveCategoryRequest) (*transaction.Category, error) {
+	return nil, nil
+}
+func (m *mockAccountService) HideCategory(context.Context, uuid.UUID, uuid.UUID) error {
+	return nil
+}
+func (m *mockAccountService) UnhideCategory(context.Context, uuid.UUID, uuid.UUID) error {
+	return nil
+}
+func (m *mockAccountService) ImportTransactions(context.Context, uuid.UUID, *transaction.ImportTransactionsRequest) (*transaction.ImportTransactionsResponse, error) {
+	return nil, nil
+}
+func (m *mockAccountService) GetReviewQueue(context.Context, uuid.UUID, int, int) ([]transaction.TransactionResponse, error) {
+	return nil, nil
+}
+func (m *mockAccountService) ReviewCategory(context.Context, uuid.UUID, uuid.UUID, *transaction.ReviewCategoryRequest) (*transaction.TransactionResponse, error) {
+	return nil, nil
+}
+func (m *mockAccountService) CreateMerchant(context.Context, *transaction.CreateMerchantRequest) (*transaction.Merchant, error) {
+	return nil, nil
+}
+func (m *mockAccountService) GetMerchant(context.Context, uuid.UUID, uuid.UUID) (*transaction.Merchant, error) {
+	return nil, nil
+}
+func (m *mockAccountService) GetMerchants(context.Context, uuid.UUID, int, int) ([]transaction.Merchant, error) {
+	return nil, nil
+}
+func (m *mockAccountService) UpdateMerchant(context.Context, uuid.UUID, *transaction.CreateMerchantRequest) (*transaction.Merchant, error) {
+	return nil, nil
+}
+func (m *mockAccountService) DeleteMerchant(context.Context, uuid.UUID) error {
 	return nil
 }
 
@@ -1,6 +1,7 @@
 package handlers
 
 import (
+	"errors"
 	"net/http"
 	"strconv"
 	"time"
@@ -31,6 +32,13 @@ func (h *AnalyticsHandler) CategorizeTransaction(c *gin.Context) {
 		return
 	}
 
+	// The user's corrections personalize the categorization
+	if userID, exists := c.Get("user_id"); exists {
+		if userUUID, ok := userID.(uuid.UUID); ok {
+			req.UserID = &userUUID
+		}
+	}
+
 	response, err := h.analyticsService.CategorizeTransaction(c.Request.Context(), &req)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
@@ -40,17 +48,57 @@ func (h *AnalyticsHandler) CategorizeTransaction(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{"categorization": response})
 }
 
+// CategorizeTransactions handles POST /api/v1/analytics/categorize/batch
+func (h *AnalyticsHandler) CategorizeTransactions(c *gin.Context) {
+	var req analytics.BatchCategorizationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request body"})
+		return
+	}
+
+	// The user's rules and corrections personalize the categorization
+	if userID, exists := c.Get("user_id"); exists {
+		if userUUID, ok := userID.(uuid.UUID); ok {
+			req.UserID = &userUUID
+		}
+	}
+
+	response, err := h.analyticsService.CategorizeTransactions(c.Request.Context(), &req)
+	if err != nil {
+		status := http.StatusInternalServerError
+		if errors.Is(err, analytics.ErrInvalidBatchCategorization) {
+			status = http.StatusBadRequest
+		}
+		c.JSON(status, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"categorizations": response.Categorizations})
+}
+
 // CreateCategorizationRule handles POST /api/v1/analytics/categorization-rules
 func (h *AnalyticsHandler) CreateCategorizationRule(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
 	var req analytics.CreateCategorizationRuleRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request body"})
 		return
 	}
 
-	response, err := h.analyticsService.CreateCategorizationRule(c.Request.Context(), &req)
+	userUUID, ok := userID.(uuid.UUID)
+	if !ok {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "invalid user ID"})
+		return
+	}
+
+	response, err := h.analyticsService.CreateCategorizationRule(c.Request.Context(), userUUID, &req)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		c.JSON(categorizationRuleErrorStatus(err, http.StatusInternalServerError), gin.H{"error": err.Error()})
 		return
 	}
 
@@ -59,13 +107,25 @@ func (h *AnalyticsHandler) CreateCategorizationRule(c *gin.Context) {
 
 // GetCategorizationRule handles GET /api/v1/analytics/categorization-rules/:id
 func (h *AnalyticsHandler) GetCategorizationRule(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
 	id, err := uuid.Parse(c.Param("id"))
 	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid rule ID"})
 		return
 	}
 
-	response, err := h.analyticsService.GetCategorizationRule(c.Request.Context(), id)
+	userUUID, ok := userID.(uuid.UUID)
+	if !ok {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "invalid user ID"})
+		return
+	}
+
+	response, err := h.analyticsService.GetCategorizationRule(c.Request.Context(), userUUID, id)
 	if err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
 		return
@@ -76,6 +136,18 @@ func (h *AnalyticsHandler) GetCategorizationRule(c *gin.Context) {
 
 // ListCategorizationRules handles GET /api/v1/analytics/categorization-rules
 func (h *AnalyticsHandler) ListCategorizationRules(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	userUUID, ok := userID.(uuid.UUID)
+	if !ok {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "invalid user ID"})
+		return
+	}
+
 	offset, _ := strconv.Atoi(c.DefaultQuery("offset", "0"))
 	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
 
@@ -83,7 +155,7 @@ func (h *AnalyticsHandler) ListCategorizationRules(c *gin.Context) {
 		limit = 100
 	}
 
-	rules, err := h.analyticsService.ListCategorizationRules(c.Request.Context(), offset, limit)
+	rules, err := h.analyticsService.ListCategorizationRules(c.Request.Context(), userUUID, offset, limit)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
@@ -92,8 +164,37 @@ func (h *AnalyticsHandler) ListCategorizationRules(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{"categorization_rules": rules})
 }
 
+// SuggestCategorizationRules handles GET /api/v1/analytics/categorization-rules/suggestions
+func (h *AnalyticsHandler) SuggestCategorizationRules(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	userUUID, ok := userID.(uuid.UUID)
+	if !ok {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "invalid user ID"})
+		return
+	}
+
+	suggestions, err := h.analyticsService.SuggestCategorizationRules(c.Request.Context(), userUUID, c.Query("locale"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"suggestions": suggestions})
+}
+
 // UpdateCategorizationRule handles PUT /api/v1/analytics/categorization-rules/:id
 func (h *AnalyticsHandler) UpdateCategorizationRule(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
 	id, err := uuid.Parse(c.Param("id"))
 	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid rule ID"})
@@ -106,9 +207,15 @@ func (h *AnalyticsHandler) UpdateCategorizationRule(c *gin.Context) {
 		return
 	}
 
-	response, err := h.analyticsService.UpdateCategorizationRule(c.Request.Context(), id, &req)
+	userUUID, ok := userID.(uuid.UUID)
+	if !ok {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "invalid user ID"})
+		return
+	}
+
+	response, err := h.analyticsService.UpdateCategorizationRule(c.Request.Context(), userUUID, id, &req)
 	if err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		c.JSON(categorizationRuleErrorStatus(err, http.StatusNotFound), gin.H{"error": err.Error()})
 		return
 	}
 
@@ -117,20 +224,247 @@ func (h *AnalyticsHandler) UpdateCategorizationRule(c *gin.Context) {
 
 // DeleteCategorizationRule handles DELETE /api/v1/analytics/categorization-rules/:id
 func (h *AnalyticsHandler) DeleteCategorizationRule(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
 	id, err := uuid.Parse(c.Param("id"))
 	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid rule ID"})
 		return
 	}
 
-	if err := h.analyticsService.DeleteCategorizationRule(c.Request.Context(), id); err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	userUUID, ok := userID.(uuid.UUID)
+	if !ok {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "invalid user ID"})
+		return
+	}
+
+	if err := h.analyticsService.DeleteCategorizationRule(c.Request.Context(), userUUID, id); err != nil {
+		c.JSON(categorizationRuleErrorStatus(err, http.StatusNotFound), gin.H{"error": err.Error()})
 		return
 	}
 
 	c.Status(http.StatusNoContent)
 }
 
+// TestCategorizationRule handles POST /api/v1/analytics/categorization-rules/test
+func (h *AnalyticsHandler) TestCategorizationRule(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	var req analytics.TestCategorizationRuleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request body"})
+		return
+	}
+
+	userUUID, ok := userID.(uuid.UUID)
+	if !ok {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "invalid user ID"})
+		return
+	}
+
+	result, err := h.analyticsService.TestCategorizationRule(c.Request.Context(), userUUID, &req)
+	if err != nil {
+		c.JSON(categorizationRuleErrorStatus(err, http.StatusInternalServerError), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"test": result})
+}
+
+// ApplyCategorizationRule handles POST /api/v1/analytics/categorization-rules/:id/apply
+func (h *AnalyticsHandler) ApplyCategorizationRule(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid rule ID"})
+		return
+	}
+
+	// The body is optional: without one the rule applies to the last year
+	var req analytics.ApplyCategorizationRuleRequest
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request body"})
+			return
+		}
+	}
+
+	userUUID, ok := userID.(uuid.UUID)
+	if !ok {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "invalid user ID"})
+		return
+	}
+
+	application, err := h.analyticsService.ApplyCategorizationRule(c.Request.Context(), userUUID, id, &req)
+	if err != nil {
+		c.JSON(categorizationRuleErrorStatus(err, http.StatusInternalServerError), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"rule_application": application})
+}
+
+// ListRuleApplications handles GET /api/v1/analytics/rule-applications
+func (h *AnalyticsHandler) ListRuleApplications(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	userUUID, ok := userID.(uuid.UUID)
+	if !ok {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "invalid user ID"})
+		return
+	}
+
+	offset, _ := strconv.Atoi(c.DefaultQuery("offset", "0"))
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+
+	if limit > 100 {
+		limit = 100
+	}
+
+	applications, err := h.analyticsService.ListRuleApplications(c.Request.Context(), userUUID, offset, limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"rule_applications": applications})
+}
+
+// UndoRuleApplication handles POST /api/v1/analytics/rule-applications/:id/undo
+func (h *AnalyticsHandler) UndoRuleApplication(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid rule application ID"})
+		return
+	}
+
+	userUUID, ok := userID.(uuid.UUID)
+	if !ok {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "invalid user ID"})
+		return
+	}
+
+	application, err := h.analyticsService.UndoRuleApplication(c.Request.Context(), userUUID, id)
+	if err != nil {
+		c.JSON(categorizationRuleErrorStatus(err, http.StatusInternalServerError), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"rule_application": application})
+}
+
+// categorizationRuleErrorStatus maps errors of changing or applying a categorization rule
+// to a status, or the fallback for other errors
+func categorizationRuleErrorStatus(err error, fallback int) int {
+	switch {
+	case errors.Is(err, analytics.ErrInvalidCategorizationRule), errors.Is(err, analytics.ErrInvalidRulePeriod):
+		return http.StatusBadRequest
+	case errors.Is(err, analytics.ErrCategorizationRuleNotOwned):
+		return http.StatusForbidden
+	case errors.Is(err, analytics.ErrCategorizationRuleNotFound), errors.Is(err, analytics.ErrRuleApplicationNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, analytics.ErrRuleApplicationUndone):
+		return http.StatusConflict
+	default:
+		return fallback
+	}
+}
+
+// TrainCategorizationModel handles POST /api/v1/analytics/categorization-models/train
+func (h *AnalyticsHandler) TrainCategorizationModel(c *gin.Context) {
+	result, err := h.analyticsService.TrainCategorizationModel(c.Request.Context())
+	if err != nil {
+		status := http.StatusInternalServerError
+		if errors.Is(err, analytics.ErrInsufficientTrainingData) {
+			status = http.StatusBadRequest
+		}
+		c.JSON(status, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"training": result})
+}
+
+// ListCategorizationModels handles GET /api/v1/analytics/categorization-models
+func (h *AnalyticsHandler) ListCategorizationModels(c *gin.Context) {
+	offset, _ := strconv.Atoi(c.DefaultQuery("offset", "0"))
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+
+	if limit > 100 {
+		limit = 100
+	}
+
+	models, err := h.analyticsService.ListCategorizationModels(c.Request.Context(), offset, limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"categorization_models": models})
+}
+
+// CompareCategorizationModels handles GET /api/v1/analytics/categorization-models/compare
+func (h *AnalyticsHandler) CompareCategorizationModels(c *gin.Context) {
+	baseID, err := uuid.Parse(c.Query("base"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid base model ID"})
+		return
+	}
+	candidateID, err := uuid.Parse(c.Query("candidate"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid candidate model ID"})
+		return
+	}
+
+	comparison, err := h.analyticsService.CompareCategorizationModels(c.Request.Context(), baseID, candidateID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"comparison": comparison})
+}
+
+// ActivateCategorizationModel handles POST /api/v1/analytics/categorization-models/:id/activate
+func (h *AnalyticsHandler) ActivateCategorizationModel(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid model ID"})
+		return
+	}
+
+	model, err := h.analyticsService.ActivateCategorizationModel(c.Request.Context(), id)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"categorization_model": model})
+}
+
 // AnalyzeSpending handles POST /api/v1/analytics/spending
 func (h *AnalyticsHandler) AnalyzeSpending(c *gin.Context) {
 	userID, exists := c.Get("user_id")
@@ -153,7 +487,11 @@ func (h *AnalyticsHandler) AnalyzeSpending(c *gin.Context) {
 
 	response, err := h.analyticsService.AnalyzeSpending(c.Request.Context(), userUUID, &req)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		status := http.StatusInternalServerError
+		if errors.Is(err, analytics.ErrInvalidAnalysisRequest) {
+			status = http.StatusBadRequest
+		}
+		c.JSON(status, gin.H{"error": err.Error()})
 		return
 	}
 
@@ -203,22 +541,72 @@ func (h *AnalyticsHandler) GetSpendingInsights(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{"insights": insights})
 }
 
+// GetCashFlowForecast handles GET /api/v1/analytics/cashflow/forecast
+func (h *AnalyticsHandler) GetCashFlowForecast(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	var req analytics.CashFlowForecastRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid query parameters"})
+		return
+	}
+
+	userUUID, ok := userID.(uuid.UUID)
+	if !ok {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "invalid user ID"})
+		return
+	}
+
+	forecast, err := h.analyticsService.GetCashFlowForecast(c.Request.Context(), userUUID, &req)
+	if err != nil {
+		status := http.StatusInternalServerError
+		if errors.Is(err, analytics.ErrInvalidForecastRequest) {
+			status = http.StatusBadRequest
+		}
+		c.JSON(status, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"forecast": forecast})
+}
+
 // RegisterRoutes registers all analytics routes
 func (h *AnalyticsHandler) RegisterRoutes(api *gin.RouterGroup) {
 	analytics := api.Group("/analytics")
 	{
 		// Categorization
 		analytics.POST("/categorize", h.CategorizeTransaction)
+		analytics.POST("/categorize/batch", h.CategorizeTransactions)
 
 		// Categorization rules
 		analytics.POST("/categorization-rules", h.CreateCategorizationRule)
 		analytics.GET("/categorization-rules", h.ListCategorizationRules)
+		analytics.GET("/categorization-rules/suggestions", h.SuggestCategorizationRules)
+		analytics.POST("/categorization-rules/test", h.TestCategorizationRule)
 		analytics.GET("/categorization-rules/:id", h.GetCategorizationRule)
 		analytics.PUT("/categorization-rules/:id", h.UpdateCategorizationRule)
 		analytics.DELETE("/categorization-rules/:id", h.DeleteCategorizationRule)
+		analytics.POST("/categorization-rules/:id/apply", h.ApplyCategorizationRule)
+
+		// Rule applications
+		analytics.GET("/rule-applications", h.ListRuleApplications)
+		analytics.POST("/rule-applications/:id/undo", h.UndoRuleApplication)
+
+		// Categorization models
+		analytics.POST("/categorization-models/train", h.TrainCategorizationModel)
+		analytics.GET("/categorization-models", h.ListCategorizationModels)
+		analytics.GET("/categorization-models/compare", h.CompareCategorizationModels)
+		analytics.POST("/categorization-models/:id/activate", h.ActivateCategorizationModel)
 
 		// Spending analysis
 		analytics.POST("/spending", h.AnalyzeSpending)
 		analytics.GET("/spending/insights", h.GetSpendingInsights)
+
+		// Cash flow
+		analytics.GET("/cashflow/forecast", h.GetCashFlowForecast)
 	}
 }
@@ -27,6 +27,11 @@ func (m *MockAnalyticsService) CategorizeTransaction(ctx context.Context, req *a
 	return args.Get(0).(*analytics.CategorizationResponse), args.Error(1)
 }
 
+func (m *MockAnalyticsService) CategorizeTransactions(ctx context.Context, req *analytics.BatchCategorizationRequest) (*analytics.BatchCategorizationResponse, error) {
+	args := m.Called(ctx, req)
+	return args.Get(0).(*analytics.BatchCategorizationResponse), args.Error(1)
+}
+
 func (m *MockAnalyticsService) AnalyzeSpending(ctx context.Context, userID uuid.UUID, req *analytics.SpendingAnalysisRequest) (*analytics.SpendingAnalysisResponse, error) {
 	args := m.Called(ctx, userID, req)
 	return args.Get(0).(*analytics.SpendingAnalysisResponse), args.Error(1)
@@ -37,31 +42,90 @@ func (m *MockAnalyticsService) GetSpendingInsights(ctx context.Context, userID u
 	return args.Get(0).([]analytics.SpendingInsight), args.Error(1)
 }
 
-func (m *MockAnalyticsService) CreateCategorizationRule(ctx context.Context, req *analytics.CreateCategorizationRuleRequest) (*analytics.CategorizationRuleResponse, error) {
-	args := m.Called(ctx, req)
-	return args.Get(0).(*analytics.CategorizationRuleResponse), args.Error(1)
+func (m *MockAnalyticsService) GetCashFlowForecast(ctx context.Context, userID uuid.UUID, req *analytics.CashFlowForecastRequest) (*analytics.CashFlowForecast, error) {
+	args := m.Called(ctx, userID, req)
+	return args.Get(0).(*analytics.CashFlowForecast), args.Error(1)
+}
+
+func (m *MockAnalyticsService) TrainCategorizationModel(ctx context.Context) (*analytics.ModelTrainingResult, error) {
+	args := m.Called(ctx)
+	return args.Get(0).(*analytics.ModelTrainingResult), args.Error(1)
+}
+
+func (m *MockAnalyticsService) ListCategorizationModels(ctx context.Context, offset, limit int) ([]analytics.CategorizationModelResponse, error) {
+	args := m.Called(ctx, offset, limit)
+	return args.Get(0).([]analytics.CategorizationModelResponse), args.Error(1)
 }
 
-func (m *MockAnalyticsService) GetCategorizationRule(ctx context.Context, id uuid.UUID) (*analytics.CategorizationRuleResponse, error) {
+func (m *MockAnalyticsService) CompareCategorizationModels(ctx context.Context, baseID, candidateID uuid.UUID) (*analytics.ModelComparison, error) {
+	args := m.Called(ctx, baseID, candidateID)
+	return args.Get(0).(*analytics.ModelComparison), args.Error(1)
+}
+
+func (m *MockAnalyticsService) ActivateCategorizationModel(ctx context.Context, id uuid.UUID) (*analytics.CategorizationModelResponse, error) {
 	args := m.Called(ctx, id)
+	return args.Get(0).(*analytics.CategorizationModelResponse), args.Error(1)
+}
+
+func (m *MockAnalyticsService) RecordCorrection(ctx context.Context, userID uuid.UUID, req *analytics.CategoryCorrectionRequest) error {
+	args := m.Called(ctx, userID, req)
+	return args.Error(0)
+}
+
+func (m *MockAnalyticsService) SuggestCategorizationRules(ctx context.Context, userID uuid.UUID, locale string) ([]analytics.RuleSuggestion, error) {
+	args := m.Called(ctx, userID, locale)
+	return args.Get(0).([]analytics.RuleSuggestion), args.Error(1)
+}
+
+func (m *MockAnalyticsService) CategoriesMerged(ctx context.Context) {
+	m.Called(ctx)
+}
+
+func (m *MockAnalyticsService) CreateCategorizationRule(ctx context.Context, userID uuid.UUID, req *analytics.CreateCategorizationRuleRequest) (*analytics.CategorizationRuleResponse, error) {
+	args := m.Called(ctx, userID, req)
 	return args.Get(0).(*analytics.CategorizationRuleResponse), args.Error(1)
 }
 
-func (m *MockAnalyticsService) ListCategorizationRules(ctx context.Context, offset, limit int) ([]analytics.CategorizationRuleResponse, error) {
-	args := m.Called(ctx, offset, limit)
+func (m *MockAnalyticsService) GetCategorizationRule(ctx context.Context, userID, id uuid.UUID) (*analytics.CategorizationRuleResponse, error) {
+	args := m.Called(ctx, userID, id)
+	return args.Get(0).(*analytics.CategorizationRuleResponse), args.Error(1)
+}
+
+func (m *MockAnalyticsService) ListCategorizationRules(ctx context.Context, userID uuid.UUID, offset, limit int) ([]analytics.CategorizationRuleResponse, error) {
+	args := m.Called(ctx, userID, offset, limit)
 	return args.Get(0).([]analytics.CategorizationRuleResponse), args.Error(1)
 }
 
-func (m *MockAnalyticsService) UpdateCategorizationRule(ctx context.Context, id uuid.UUID, req *analytics.UpdateCategorizationRuleRequest) (*analytics.CategorizationRuleResponse, error) {
-	args := m.Called(ctx, id, req)
+func (m *MockAnalyticsService) UpdateCategorizationRule(ctx context.Context, userID, id uuid.UUID, req *analytics.UpdateCategorizationRuleRequest) (*analytics.CategorizationRuleResponse, error) {
+	args := m.Called(ctx, userID, id, req)
 	return args.Get(0).(*analytics.CategorizationRuleResponse), args.Error(1)
 }
 
-func (m *MockAnalyticsService) DeleteCategorizationRule(ctx context.Context, id uuid.UUID) error {
-	args := m.Called(ctx, id)
+func (m *MockAnalyticsService) DeleteCategorizationRule(ctx context.Context, userID, id uuid.UUID) error {
+	args := m.Called(ctx, userID, id)
 	return args.Error(0)
 }
 
+func (m *MockAnalyticsService) TestCategorizationRule(ctx context.Context, userID uuid.UUID, req *analytics.TestCategorizationRuleRequest) (*analytics.RuleTestResult, error) {
+	args := m.Called(ctx, userID, req)
+	return args.Get(0).(*analytics.RuleTestResult), args.Error(1)
+}
+
+func (m *MockAnalyticsService) ApplyCategorizationRule(ctx context.Context, userID, ruleID uuid.UUID, req *analytics.ApplyCategorizationRuleRequest) (*analytics.RuleApplication, error) {
+	args := m.Called(ctx, userID, ruleID, req)
+	return args.Get(0).(*analytics.RuleApplication), args.Error(1)
+}
+
+func (m *MockAnalyticsService) ListRuleApplications(ctx context.Context, userID uuid.UUID, offset, limit int) ([]analytics.RuleApplication, error) {
+	args := m.Called(ctx, userID, offset, limit)
+	return args.Get(0).([]analytics.RuleApplication), args.Error(1)
+}
+
+func (m *MockAnalyticsService) UndoRuleApplication(ctx context.Context, userID, id uuid.UUID) (*analytics.RuleApplication, error) {
+	args := m.Called(ctx, userID, id)
+	return args.Get(0).(*analytics.RuleApplication), args.Error(1)
+}
+
 func TestAnalyticsHandler_CategorizeTransaction(t *testing.T) {
 	gin.SetMode(gin.TestMode)
 
@@ -187,7 +251,7 @@ func TestAnalyticsHandler_AnalyzeSpending(t *testing.T) {
 			},
 			expectedStatus: http.StatusOK,
 			expectedBody: func(categoryID uuid.UUID) string {
-				return `{"analysis":{"period_start":"2024-01-01T00:00:00Z","period_end":"2024-12-31T23:59:59Z","total_spent":1000,"total_income":1500,"net_amount":500,"category_breakdown":[{"category_id":"` + categoryID.String() + `","category_name":"Food","amount":500,"percentage":50,"transaction_count":10}],"insights":null,"spending_trends":null,"top_categories":null}}`
+				return `{"analysis":{"period_start":"2024-01-01T00:00:00Z","period_end":"2024-12-31T23:59:59Z","total_spent":1000,"total_income":1500,"net_amount":500,"category_breakdown":[{"category_id":"` + categoryID.String() + `","category_name":"Food","amount":500,"percentage":50,"transaction_count":10}],"insights":null,"spending_trends":null,"top_categories":null,"top_merchants":null}}`
 			},
 		},
 		{
@@ -239,6 +303,217 @@ func TestAnalyticsHandler_AnalyzeSpending(t *testing.T) {
 	}
 }
 
+func TestAnalyticsHandler_GetCashFlowForecast(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	userID := uuid.New()
+	date := time.Date(2024, 6, 2, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name           string
+		query          string
+		setupMock      func(*MockAnalyticsService)
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			name:  "successful forecast",
+			query: "?days=1&low_balance_threshold=100",
+			setupMock: func(mockService *MockAnalyticsService) {
+				forecast := &analytics.CashFlowForecast{
+					StartDate:           date,
+					EndDate:             date,
+					LowBalanceThreshold: 100,
+					Totals: []analytics.CurrencyCashFlowForecast{{
+						Currency:       "USD",
+						CurrentBalance: 150,
+						LowBalanceDate: &date,
+						Balances:       []analytics.BalanceProjection{{Date: date, Balance: 90, Lower: 70, Upper: 110}},
+					}},
+					Accounts: []analytics.AccountCashFlowForecast{},
+				}
+				mockService.On("GetCashFlowForecast", mock.Anything, userID, &analytics.CashFlowForecastRequest{Days: 1, LowBalanceThreshold: 100}).
+					Return(forecast, nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody:   `{"forecast":{"start_date":"2024-06-02T00:00:00Z","end_date":"2024-06-02T00:00:00Z","low_balance_threshold":100,"totals":[{"currency":"USD","current_balance":150,"low_balance_date":"2024-06-02T00:00:00Z","balances":[{"date":"2024-06-02T00:00:00Z","balance":90,"lower":70,"upper":110}]}],"accounts":[]}}`,
+		},
+		{
+			name:           "invalid days",
+			query:          "?days=many",
+			setupMock:      func(mockService *MockAnalyticsService) {},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `{"error":"invalid query parameters"}`,
+		},
+		{
+			name:  "too many days",
+			query: "?days=1000",
+			setupMock: func(mockService *MockAnalyticsService) {
+				mockService.On("GetCashFlowForecast", mock.Anything, userID, mock.Anything).
+					Return((*analytics.CashFlowForecast)(nil), fmt.Errorf("%w: days must be between 1 and 365", analytics.ErrInvalidForecastRequest))
+			},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `{"error":"invalid cash flow forecast request: days must be between 1 and 365"}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := &MockAnalyticsService{}
+			tt.setupMock(mockService)
+
+			handler := NewAnalyticsHandler(mockService)
+
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request = httptest.NewRequest("GET", "/analytics/cashflow/forecast"+tt.query, nil)
+			c.Set("user_id", userID)
+
+			handler.GetCashFlowForecast(c)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			assert.JSONEq(t, tt.expectedBody, w.Body.String())
+
+			mockService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestAnalyticsHandler_TrainCategorizationModel(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	modelID := uuid.MustParse("123e4567-e89b-12d3-a456-426614174001")
+	previousID := uuid.MustParse("123e4567-e89b-12d3-a456-426614174002")
+	previousAccuracy := 0.95
+	createdAt := time.Date(2024, 6, 2, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name           string
+		setupMock      func(*MockAnalyticsService)
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			name: "model kept inactive",
+			setupMock: func(mockService *MockAnalyticsService) {
+				mockService.On("TrainCategorizationModel", mock.Anything).Return(&analytics.ModelTrainingResult{
+					Model: analytics.CategorizationModelResponse{
+						ID:        modelID,
+						Name:      "transaction-categorizer",
+						Version:   "20240602000000",
+						ModelType: analytics.ModelTypeNaiveBayes,
+						Accuracy:  0.9,
+						CreatedAt: createdAt,
+					},
+					PreviousModelID:  &previousID,
+					PreviousAccuracy: &previousAccuracy,
+				}, nil)
+			},
+			expectedStatus: http.StatusCreated,
+			expectedBody:   `{"training":{"model":{"id":"123e4567-e89b-12d3-a456-426614174001","name":"transaction-categorizer","version":"20240602000000","model_type":"naive_bayes","accuracy":0.9,"is_active":false,"created_at":"2024-06-02T00:00:00Z"},"activated":false,"previous_model_id":"123e4567-e89b-12d3-a456-426614174002","previous_accuracy":0.95}}`,
+		},
+		{
+			name: "insufficient training data",
+			setupMock: func(mockService *MockAnalyticsService) {
+				mockService.On("TrainCategorizationModel", mock.Anything).
+					Return((*analytics.ModelTrainingResult)(nil), fmt.Errorf("%w: 1 of 20 transactions", analytics.ErrInsufficientTrainingData))
+			},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `{"error":"not enough categorized transactions to train a model: 1 of 20 transactions"}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := &MockAnalyticsService{}
+			tt.setupMock(mockService)
+
+			handler := NewAnalyticsHandler(mockService)
+
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request = httptest.NewRequest("POST", "/analytics/categorization-models/train", nil)
+
+			handler.TrainCategorizationModel(c)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			assert.JSONEq(t, tt.expectedBody, w.Body.String())
+
+			mockService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestAnalyticsHandler_ActivateCategorizationModel(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	modelID := uuid.MustParse("123e4567-e89b-12d3-a456-426614174001")
+
+	tests := []struct {
+		name           string
+		modelID        string
+		setupMock      func(*MockAnalyticsService)
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			name:    "rollback to earlier version",
+			modelID: modelID.String(),
+			setupMock: func(mockService *MockAnalyticsService) {
+				mockService.On("ActivateCategorizationModel", mock.Anything, modelID).Return(&analytics.CategorizationModelResponse{
+					ID:        modelID,
+					Name:      "transaction-categorizer",
+					Version:   "20240601000000",
+					ModelType: analytics.ModelTypeNaiveBayes,
+					Accuracy:  0.95,
+					IsActive:  true,
+					CreatedAt: time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC),
+				}, nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody:   `{"categorization_model":{"id":"123e4567-e89b-12d3-a456-426614174001","name":"transaction-categorizer","version":"20240601000000","model_type":"naive_bayes","accuracy":0.95,"is_active":true,"created_at":"2024-06-01T00:00:00Z"}}`,
+		},
+		{
+			name:           "invalid model ID",
+			modelID:        "latest",
+			setupMock:      func(mockService *MockAnalyticsService) {},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `{"error":"invalid model ID"}`,
+		},
+		{
+			name:    "model not found",
+			modelID: modelID.String(),
+			setupMock: func(mockService *MockAnalyticsService) {
+				mockService.On("ActivateCategorizationModel", mock.Anything, modelID).
+					Return((*analytics.CategorizationModelResponse)(nil), fmt.Errorf("categorization model not found: record not found"))
+			},
+			expectedStatus: http.StatusNotFound,
+			expectedBody:   `{"error":"categorization model not found: record not found"}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := &MockAnalyticsService{}
+			tt.setupMock(mockService)
+
+			handler := NewAnalyticsHandler(mockService)
+
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request = httptest.NewRequest("POST", "/analytics/categorization-models/"+tt.modelID+"/activate", nil)
+			c.Params = gin.Params{{Key: "id", Value: tt.modelID}}
+
+			handler.ActivateCategorizationModel(c)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			assert.JSONEq(t, tt.expectedBody, w.Body.String())
+
+			mockService.AssertExpectations(t)
+		})
+	}
+}
+
 func TestAnalyticsHandler_CreateCategorizationRule(t *testing.T) {
 	gin.SetMode(gin.TestMode)
 
@@ -266,7 +541,7 @@ func TestAnalyticsHandler_CreateCategorizationRule(t *testing.T) {
 					CreatedAt:    time.Time{},
 					UpdatedAt:    time.Time{},
 				}
-				mockService.On("CreateCategorizationRule", mock.Anything, mock.AnythingOfType("*analytics.CreateCategorizationRuleRequest")).
+				mockService.On("CreateCategorizationRule", mock.Anything, mock.AnythingOfType("uuid.UUID"), mock.AnythingOfType("*analytics.CreateCategorizationRuleRequest")).
 					Return(response, nil)
 				return ruleID, categoryID
 			},
@@ -290,7 +565,7 @@ func TestAnalyticsHandler_CreateCategorizationRule(t *testing.T) {
 			name:        "internal server error",
 			requestBody: `{"category_id":"123e4567-e89b-12d3-a456-426614174000","pattern":"grocery","pattern_type":"keyword"}`,
 			setupMock: func(mockService *MockAnalyticsService) (uuid.UUID, uuid.UUID) {
-				mockService.On("CreateCategorizationRule", mock.Anything, mock.AnythingOfType("*analytics.CreateCategorizationRuleRequest")).
+				mockService.On("CreateCategorizationRule", mock.Anything, mock.AnythingOfType("uuid.UUID"), mock.AnythingOfType("*analytics.CreateCategorizationRuleRequest")).
 					Return((*analytics.CategorizationRuleResponse)(nil), fmt.Errorf("database error"))
 				return uuid.Nil, uuid.Nil
 			},
@@ -352,7 +627,7 @@ func TestAnalyticsHandler_GetCategorizationRule(t *testing.T) {
 					CreatedAt:    time.Time{},
 					UpdatedAt:    time.Time{},
 				}
-				mockService.On("GetCategorizationRule", mock.Anything, ruleID).
+				mockService.On("GetCategorizationRule", mock.Anything, mock.AnythingOfType("uuid.UUID"), ruleID).
 					Return(response, nil)
 			},
 			expectedStatus: http.StatusOK,
@@ -371,7 +646,7 @@ func TestAnalyticsHandler_GetCategorizationRule(t *testing.T) {
 			name:   "rule not found",
 			ruleID: ruleID.String(),
 			setupMock: func(mockService *MockAnalyticsService) {
-				mockService.On("GetCategorizationRule", mock.Anything, ruleID).
+				mockService.On("GetCategorizationRule", mock.Anything, mock.AnythingOfType("uuid.UUID"), ruleID).
 					Return((*analytics.CategorizationRuleResponse)(nil), fmt.Errorf("rule not found"))
 			},
 			expectedStatus: http.StatusNotFound,
@@ -431,7 +706,7 @@ func TestAnalyticsHandler_ListCategorizationRules(t *testing.T) {
 						UpdatedAt:    time.Time{},
 					},
 				}
-				mockService.On("ListCategorizationRules", mock.Anything, 0, 20).
+				mockService.On("ListCategorizationRules", mock.Anything, userID, 0, 20).
 					Return(response, nil)
 			},
 			expectedStatus: http.StatusOK,
@@ -491,7 +766,7 @@ func TestAnalyticsHandler_UpdateCategorizationRule(t *testing.T) {
 					CreatedAt:    time.Time{},
 					UpdatedAt:    time.Time{},
 				}
-				mockService.On("UpdateCategorizationRule", mock.Anything, ruleID, mock.AnythingOfType("*analytics.UpdateCategorizationRuleRequest")).
+				mockService.On("UpdateCategorizationRule", mock.Anything, mock.AnythingOfType("uuid.UUID"), ruleID, mock.AnythingOfType("*analytics.UpdateCategorizationRuleRequest")).
 					Return(response, nil)
 			},
 			expectedStatus: http.StatusOK,
@@ -518,12 +793,23 @@ func TestAnalyticsHandler_UpdateCategorizationRule(t *testing.T) {
 			ruleID:      ruleID.String(),
 			requestBody: `{"pattern":"updated-grocery"}`,
 			setupMock: func(mockService *MockAnalyticsService) {
-				mockService.On("UpdateCategorizationRule", mock.Anything, ruleID, mock.AnythingOfType("*analytics.UpdateCategorizationRuleRequest")).
+				mockService.On("UpdateCategorizationRule", mock.Anything, mock.AnythingOfType("uuid.UUID"), ruleID, mock.AnythingOfType("*analytics.UpdateCategorizationRuleRequest")).
 					Return((*analytics.CategorizationRuleResponse)(nil), fmt.Errorf("rule not found"))
 			},
 			expectedStatus: http.StatusNotFound,
 			expectedBody:   `{"error":"rule not found"}`,
 		},
+		{
+			name:        "rule not owned",
+			ruleID:      ruleID.String(),
+			requestBody: `{"priority":3}`,
+			setupMock: func(mockService *MockAnalyticsService) {
+				mockService.On("UpdateCategorizationRule", mock.Anything, mock.AnythingOfType("uuid.UUID"), ruleID, mock.AnythingOfType("*analytics.UpdateCategorizationRuleRequest")).
+					Return((*analytics.CategorizationRuleResponse)(nil), analytics.ErrCategorizationRuleNotOwned)
+			},
+			expectedStatus: http.StatusForbidden,
+			expectedBody:   `{"error":"categorization rule is not owned by user"}`,
+		},
 	}
 
 	for _, tt := range tests {
@@ -566,7 +852,7 @@ func TestAnalyticsHandler_DeleteCategorizationRule(t *testing.T) {
 			name:   "successful deletion",
 			ruleID: ruleID.String(),
 			setupMock: func(mockService *MockAnalyticsService) {
-				mockService.On("DeleteCategorizationRule", mock.Anything, ruleID).
+				mockService.On("DeleteCategorizationRule", mock.Anything, mock.AnythingOfType("uuid.UUID"), ruleID).
 					Return(nil).Once()
 			},
 			expectedStatus: http.StatusNoContent,
@@ -585,7 +871,7 @@ func TestAnalyticsHandler_DeleteCategorizationRule(t *testing.T) {
 			name:   "rule not found",
 			ruleID: ruleID.String(),
 			setupMock: func(mockService *MockAnalyticsService) {
-				mockService.On("DeleteCategorizationRule", mock.Anything, ruleID).
+				mockService.On("DeleteCategorizationRule", mock.Anything, mock.AnythingOfType("uuid.UUID"), ruleID).
 					Return(fmt.Errorf("rule not found")).Once()
 			},
 			expectedStatus: http.StatusNotFound,
@@ -602,6 +888,10 @@ func TestAnalyticsHandler_DeleteCategorizationRule(t *testing.T) {
 
 			// Use proper Gin test setup
 			router := gin.New()
+			router.Use(func(c *gin.Context) {
+				c.Set("user_id", uuid.New())
+				c.Next()
+			})
 			router.DELETE("/analytics/categorization-rules/:id", handler.DeleteCategorizationRule)
 
 			w := httptest.NewRecorder()
@@ -616,3 +906,149 @@ func TestAnalyticsHandler_DeleteCategorizationRule(t *testing.T) {
 		})
 	}
 }
+
+func TestAnalyticsHandler_UndoRuleApplication(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	applicationID := uuid.New()
+
+	tests := []struct {
+		name           string
+		applicationID  string
+		setupMock      func(*MockAnalyticsService)
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			name:          "successful undo",
+			applicationID: applicationID.String(),
+			setupMock: func(mockService *MockAnalyticsService) {
+				mockService.On("UndoRuleApplication", mock.Anything, mock.AnythingOfType("uuid.UUID"), applicationID).
+					Return(&analytics.RuleApplication{ID: applicationID, Status: analytics.RuleApplicationStatusUndone, TransactionsRestored: 3}, nil).Once()
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "invalid rule application ID",
+			applicationID:  "invalid-uuid",
+			setupMock:      func(mockService *MockAnalyticsService) {},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `{"error":"invalid rule application ID"}`,
+		},
+		{
+			name:          "rule application not found",
+			applicationID: applicationID.String(),
+			setupMock: func(mockService *MockAnalyticsService) {
+				mockService.On("UndoRuleApplication", mock.Anything, mock.AnythingOfType("uuid.UUID"), applicationID).
+					Return((*analytics.RuleApplication)(nil), analytics.ErrRuleApplicationNotFound).Once()
+			},
+			expectedStatus: http.StatusNotFound,
+			expectedBody:   `{"error":"rule application not found"}`,
+		},
+		{
+			name:          "already undone",
+			applicationID: applicationID.String(),
+			setupMock: func(mockService *MockAnalyticsService) {
+				mockService.On("UndoRuleApplication", mock.Anything, mock.AnythingOfType("uuid.UUID"), applicationID).
+					Return((*analytics.RuleApplication)(nil), analytics.ErrRuleApplicationUndone).Once()
+			},
+			expectedStatus: http.StatusConflict,
+			expectedBody:   `{"error":"rule application already undone"}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := &MockAnalyticsService{}
+			tt.setupMock(mockService)
+
+			handler := NewAnalyticsHandler(mockService)
+
+			router := gin.New()
+			router.Use(func(c *gin.Context) {
+				c.Set("user_id", uuid.New())
+				c.Next()
+			})
+			router.POST("/analytics/rule-applications/:id/undo", handler.UndoRuleApplication)
+
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest("POST", "/analytics/rule-applications/"+tt.applicationID+"/undo", nil)
+
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			if tt.expectedBody != "" {
+				assert.JSONEq(t, tt.expectedBody, w.Body.String())
+			}
+
+			mockService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestAnalyticsHandler_CategorizeTransactions(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	categoryID := uuid.New()
+
+	tests := []struct {
+		name           string
+		requestBody    string
+		setupMock      func(*MockAnalyticsService)
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			name:        "successful categorization",
+			requestBody: `{"transactions":[{"description":"grocery store","amount":-50}]}`,
+			setupMock: func(mockService *MockAnalyticsService) {
+				mockService.On("CategorizeTransactions", mock.Anything, mock.MatchedBy(func(req *analytics.BatchCategorizationRequest) bool {
+					return req.UserID != nil && len(req.Transactions) == 1
+				})).Return(&analytics.BatchCategorizationResponse{Categorizations: []analytics.CategorizationResponse{
+					{CategoryID: categoryID, CategoryName: "Groceries", Confidence: 1, CategorizationSource: "rule"},
+				}}, nil).Once()
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody:   `{"categorizations":[{"category_id":"` + categoryID.String() + `","category_name":"Groceries","confidence":1,"categorization_source":"rule"}]}`,
+		},
+		{
+			name:           "transaction without description",
+			requestBody:    `{"transactions":[{"amount":-50}]}`,
+			setupMock:      func(mockService *MockAnalyticsService) {},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `{"error":"invalid request body"}`,
+		},
+		{
+			name:        "invalid batch",
+			requestBody: `{"transactions":[{"description":"grocery store","amount":-50}]}`,
+			setupMock: func(mockService *MockAnalyticsService) {
+				mockService.On("CategorizeTransactions", mock.Anything, mock.Anything).
+					Return((*analytics.BatchCategorizationResponse)(nil), analytics.ErrInvalidBatchCategorization).Once()
+			},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `{"error":"invalid batch categorization request"}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := &MockAnalyticsService{}
+			tt.setupMock(mockService)
+
+			handler := NewAnalyticsHandler(mockService)
+
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request = httptest.NewRequest("POST", "/analytics/categorize/batch", bytes.NewBufferString(tt.requestBody))
+			c.Request.Header.Set("Content-Type", "application/json")
+			c.Set("user_id", uuid.New())
+
+			handler.CategorizeTransactions(c)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			assert.JSONEq(t, tt.expectedBody, w.Body.String())
+
+			mockService.AssertExpectations(t)
+		})
+	}
+}
@@ -1,6 +1,8 @@
 package handlers
 
 import (
+	"errors"
+	"io"
 	"net/http"
 	"strconv"
 
@@ -44,7 +46,7 @@ func (h *BudgetHandler) CreateBudget(c *gin.Context) {
 
 	budgetResponse, err := h.budgetService.CreateBudget(c.Request.Context(), userUUID, &req)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		c.JSON(alertRulesErrorStatus(err, http.StatusInternalServerError), gin.H{"error": err.Error()})
 		return
 	}
 
@@ -138,7 +140,7 @@ func (h *BudgetHandler) UpdateBudget(c *gin.Context) {
 
 	budgetResponse, err := h.budgetService.UpdateBudget(c.Request.Context(), userUUID, budgetID, &req)
 	if err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		c.JSON(alertRulesErrorStatus(err, http.StatusNotFound), gin.H{"error": err.Error()})
 		return
 	}
 
@@ -193,7 +195,18 @@ func (h *BudgetHandler) GetBudgetSummary(c *gin.Context) {
 		return
 	}
 
-	summary, err := h.budgetService.GetBudgetSummary(c.Request.Context(), userUUID, budgetID)
+	// Optionally roll category totals up to a level of the category hierarchy
+	var level *int
+	if levelStr := c.Query("level"); levelStr != "" {
+		parsed, err := strconv.Atoi(levelStr)
+		if err != nil || parsed < 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid category level"})
+			return
+		}
+		level = &parsed
+	}
+
+	summary, err := h.budgetService.GetBudgetSummary(c.Request.Context(), userUUID, budgetID, level)
 	if err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
 		return
@@ -202,6 +215,536 @@ func (h *BudgetHandler) GetBudgetSummary(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{"summary": summary})
 }
 
+// RecalculateBudget handles POST /api/v1/budgets/:id/recalculate
+func (h *BudgetHandler) RecalculateBudget(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	budgetID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid budget ID"})
+		return
+	}
+
+	userUUID, ok := userID.(uuid.UUID)
+	if !ok {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "invalid user ID"})
+		return
+	}
+
+	summary, err := h.budgetService.RecalculateBudget(c.Request.Context(), userUUID, budgetID)
+	if err != nil {
+		c.JSON(budgetErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"summary": summary})
+}
+
+// GetBudgetPeriods handles GET /api/v1/budgets/:id/periods
+func (h *BudgetHandler) GetBudgetPeriods(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	budgetID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid budget ID"})
+		return
+	}
+
+	userUUID, ok := userID.(uuid.UUID)
+	if !ok {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "invalid user ID"})
+		return
+	}
+
+	periods, err := h.budgetService.GetBudgetPeriods(c.Request.Context(), userUUID, budgetID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"periods": periods})
+}
+
+// GetBudgetForecast handles GET /api/v1/budgets/:id/forecast
+func (h *BudgetHandler) GetBudgetForecast(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	budgetID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid budget ID"})
+		return
+	}
+
+	userUUID, ok := userID.(uuid.UUID)
+	if !ok {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "invalid user ID"})
+		return
+	}
+
+	forecast, err := h.budgetService.GetBudgetForecast(c.Request.Context(), userUUID, budgetID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"forecast": forecast})
+}
+
+// GetBudgetReport handles GET /api/v1/budgets/:id/report
+func (h *BudgetHandler) GetBudgetReport(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	budgetID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid budget ID"})
+		return
+	}
+
+	// Defaults to the current year
+	var year int
+	if yearStr := c.Query("year"); yearStr != "" {
+		year, err = strconv.Atoi(yearStr)
+		if err != nil || year < 1 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid year"})
+			return
+		}
+	}
+
+	format := c.DefaultQuery("format", "json")
+	if format != "json" && format != "csv" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "format must be json or csv"})
+		return
+	}
+
+	userUUID, ok := userID.(uuid.UUID)
+	if !ok {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "invalid user ID"})
+		return
+	}
+
+	report, err := h.budgetService.GetBudgetReport(c.Request.Context(), userUUID, budgetID, year)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+
+	if format == "csv" {
+		c.Header("Content-Type", "text/csv")
+		c.Header("Content-Disposition", "attachment; filename=budget-report-"+strconv.Itoa(report.Year)+".csv")
+		c.Status(http.StatusOK)
+		if err := report.WriteCSV(c.Writer); err != nil {
+			_ = c.Error(err)
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"report": report})
+}
+
+// SaveAsTemplate handles POST /api/v1/budgets/:id/template
+func (h *BudgetHandler) SaveAsTemplate(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	budgetID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid budget ID"})
+		return
+	}
+
+	var req budget.CreateTemplateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request body"})
+		return
+	}
+
+	userUUID, ok := userID.(uuid.UUID)
+	if !ok {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "invalid user ID"})
+		return
+	}
+
+	template, err := h.budgetService.SaveAsTemplate(c.Request.Context(), userUUID, budgetID, &req)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"template": template})
+}
+
+// CopyBudget handles POST /api/v1/budgets/:id/copy
+func (h *BudgetHandler) CopyBudget(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	budgetID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid budget ID"})
+		return
+	}
+
+	// All fields are optional, so the body may be empty
+	var req budget.CopyBudgetRequest
+	if err := c.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request body"})
+		return
+	}
+
+	userUUID, ok := userID.(uuid.UUID)
+	if !ok {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "invalid user ID"})
+		return
+	}
+
+	budgetResponse, err := h.budgetService.CopyBudget(c.Request.Context(), userUUID, budgetID, &req)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"budget": budgetResponse})
+}
+
+// ListTemplates handles GET /api/v1/budgets/templates
+func (h *BudgetHandler) ListTemplates(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	userUUID, ok := userID.(uuid.UUID)
+	if !ok {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "invalid user ID"})
+		return
+	}
+
+	templates, err := h.budgetService.ListTemplates(c.Request.Context(), userUUID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"templates": templates})
+}
+
+// DeleteTemplate handles DELETE /api/v1/budgets/templates/:templateId
+func (h *BudgetHandler) DeleteTemplate(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	templateID, err := uuid.Parse(c.Param("templateId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid template ID"})
+		return
+	}
+
+	userUUID, ok := userID.(uuid.UUID)
+	if !ok {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "invalid user ID"})
+		return
+	}
+
+	if err := h.budgetService.DeleteTemplate(c.Request.Context(), userUUID, templateID); err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// CreateBudgetFromTemplate handles POST /api/v1/budgets/templates/:templateId/budgets
+func (h *BudgetHandler) CreateBudgetFromTemplate(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	templateID, err := uuid.Parse(c.Param("templateId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid template ID"})
+		return
+	}
+
+	var req budget.CopyBudgetRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request body"})
+		return
+	}
+
+	userUUID, ok := userID.(uuid.UUID)
+	if !ok {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "invalid user ID"})
+		return
+	}
+
+	budgetResponse, err := h.budgetService.CreateBudgetFromTemplate(c.Request.Context(), userUUID, templateID, &req)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"budget": budgetResponse})
+}
+
+// SuggestBudget handles GET /api/v1/budgets/suggest
+func (h *BudgetHandler) SuggestBudget(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	months, err := strconv.Atoi(c.DefaultQuery("months", "3"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid months"})
+		return
+	}
+
+	userUUID, ok := userID.(uuid.UUID)
+	if !ok {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "invalid user ID"})
+		return
+	}
+
+	suggestion, err := h.budgetService.SuggestBudget(c.Request.Context(), userUUID, months)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"suggestion": suggestion})
+}
+
+// GetEnvelopes handles GET /api/v1/budgets/:id/envelopes
+func (h *BudgetHandler) GetEnvelopes(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	budgetID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid budget ID"})
+		return
+	}
+
+	userUUID, ok := userID.(uuid.UUID)
+	if !ok {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "invalid user ID"})
+		return
+	}
+
+	envelopes, err := h.budgetService.GetEnvelopes(c.Request.Context(), userUUID, budgetID)
+	if err != nil {
+		c.JSON(envelopeErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"envelopes": envelopes})
+}
+
+// AssignToEnvelope handles POST /api/v1/budgets/:id/envelopes/assign
+func (h *BudgetHandler) AssignToEnvelope(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	budgetID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid budget ID"})
+		return
+	}
+
+	var req budget.AssignEnvelopeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request body"})
+		return
+	}
+
+	userUUID, ok := userID.(uuid.UUID)
+	if !ok {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "invalid user ID"})
+		return
+	}
+
+	envelopes, err := h.budgetService.AssignToEnvelope(c.Request.Context(), userUUID, budgetID, &req)
+	if err != nil {
+		c.JSON(envelopeErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"envelopes": envelopes})
+}
+
+// MoveBetweenEnvelopes handles POST /api/v1/budgets/:id/envelopes/move
+func (h *BudgetHandler) MoveBetweenEnvelopes(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	budgetID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid budget ID"})
+		return
+	}
+
+	var req budget.MoveEnvelopeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request body"})
+		return
+	}
+
+	userUUID, ok := userID.(uuid.UUID)
+	if !ok {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "invalid user ID"})
+		return
+	}
+
+	envelopes, err := h.budgetService.MoveBetweenEnvelopes(c.Request.Context(), userUUID, budgetID, &req)
+	if err != nil {
+		c.JSON(envelopeErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"envelopes": envelopes})
+}
+
+// CoverOverspending handles POST /api/v1/budgets/:id/envelopes/:categoryId/cover
+func (h *BudgetHandler) CoverOverspending(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	budgetID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid budget ID"})
+		return
+	}
+
+	categoryID, err := uuid.Parse(c.Param("categoryId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid category ID"})
+		return
+	}
+
+	var req budget.CoverOverspendingRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request body"})
+		return
+	}
+
+	userUUID, ok := userID.(uuid.UUID)
+	if !ok {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "invalid user ID"})
+		return
+	}
+
+	envelopes, err := h.budgetService.CoverOverspending(c.Request.Context(), userUUID, budgetID, categoryID, &req)
+	if err != nil {
+		c.JSON(envelopeErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"envelopes": envelopes})
+}
+
+// GetEnvelopeTransfers handles GET /api/v1/budgets/:id/envelopes/transfers
+func (h *BudgetHandler) GetEnvelopeTransfers(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	budgetID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid budget ID"})
+		return
+	}
+
+	offset, _ := strconv.Atoi(c.DefaultQuery("offset", "0"))
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+
+	userUUID, ok := userID.(uuid.UUID)
+	if !ok {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "invalid user ID"})
+		return
+	}
+
+	transfers, err := h.budgetService.GetEnvelopeTransfers(c.Request.Context(), userUUID, budgetID, offset, limit)
+	if err != nil {
+		c.JSON(envelopeErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"transfers": transfers})
+}
+
+// budgetErrorStatus maps budgets that don't exist or that the user may not access to not
+// found and other errors to an internal server error
+func budgetErrorStatus(err error) int {
+	if errors.Is(err, budget.ErrBudgetNotFound) || errors.Is(err, budget.ErrBudgetAccessDenied) {
+		return http.StatusNotFound
+	}
+	return http.StatusInternalServerError
+}
+
+// envelopeErrorStatus maps envelope budgeting errors to HTTP status codes
+func envelopeErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, budget.ErrNotEnvelopeBudget),
+		errors.Is(err, budget.ErrInsufficientFunds),
+		errors.Is(err, budget.ErrEnvelopeNotOverspent):
+		return http.StatusBadRequest
+	default:
+		return http.StatusNotFound
+	}
+}
+
+// alertRulesErrorStatus maps invalid alert rules to a bad request and other errors to the
+// given status code
+func alertRulesErrorStatus(err error, status int) int {
+	if errors.Is(err, budget.ErrInvalidAlertRules) {
+		return http.StatusBadRequest
+	}
+	return status
+}
+
 // AddBudgetCategory handles POST /api/v1/budgets/:id/categories
 func (h *BudgetHandler) AddBudgetCategory(c *gin.Context) {
 	userID, exists := c.Get("user_id")
@@ -230,7 +773,7 @@ func (h *BudgetHandler) AddBudgetCategory(c *gin.Context) {
 
 	categoryResponse, err := h.budgetService.AddBudgetCategory(c.Request.Context(), userUUID, budgetID, &req)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		c.JSON(alertRulesErrorStatus(err, http.StatusInternalServerError), gin.H{"error": err.Error()})
 		return
 	}
 
@@ -335,7 +878,7 @@ func (h *BudgetHandler) UpdateBudgetCategory(c *gin.Context) {
 
 	categoryResponse, err := h.budgetService.UpdateBudgetCategory(c.Request.Context(), userUUID, budgetID, categoryID, &req)
 	if err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		c.JSON(alertRulesErrorStatus(err, http.StatusNotFound), gin.H{"error": err.Error()})
 		return
 	}
 
@@ -385,7 +928,26 @@ func (h *BudgetHandler) RegisterRoutes(api *gin.RouterGroup) {
 		budgets.GET("/:id", h.GetBudget)
 		budgets.PUT("/:id", h.UpdateBudget)
 		budgets.DELETE("/:id", h.DeleteBudget)
+		budgets.GET("/suggest", h.SuggestBudget)
+		budgets.POST("/:id/copy", h.CopyBudget)
 		budgets.GET("/:id/summary", h.GetBudgetSummary)
+		budgets.POST("/:id/recalculate", h.RecalculateBudget)
+		budgets.GET("/:id/periods", h.GetBudgetPeriods)
+		budgets.GET("/:id/forecast", h.GetBudgetForecast)
+		budgets.GET("/:id/report", h.GetBudgetReport)
+
+		// Budget templates
+		budgets.POST("/:id/template", h.SaveAsTemplate)
+		budgets.GET("/templates", h.ListTemplates)
+		budgets.DELETE("/templates/:templateId", h.DeleteTemplate)
+		budgets.POST("/templates/:templateId/budgets", h.CreateBudgetFromTemplate)
+
+		// Envelope budgeting
+		budgets.GET("/:id/envelopes", h.GetEnvelopes)
+		budgets.GET("/:id/envelopes/transfers", h.GetEnvelopeTransfers)
+		budgets.POST("/:id/envelopes/assign", h.AssignToEnvelope)
+		budgets.POST("/:id/envelopes/move", h.MoveBetweenEnvelopes)
+		budgets.POST("/:id/envelopes/:categoryId/cover", h.CoverOverspending)
 
 		// Budget categories
 		budgets.POST("/:id/categories", h.AddBudgetCategory)
@@ -3,6 +3,7 @@ package handlers
 import (
 	"bytes"
 	"context"
+	"encoding/json"
 	"fmt"
 	"net/http"
 	"net/http/httptest"
@@ -72,16 +73,121 @@ func (m *MockBudgetService) DeleteBudgetCategory(ctx context.Context, userID, bu
 	return args.Error(0)
 }
 
-func (m *MockBudgetService) GetBudgetSummary(ctx context.Context, userID, budgetID uuid.UUID) (*budget.BudgetSummary, error) {
+func (m *MockBudgetService) GetBudgetSummary(ctx context.Context, userID, budgetID uuid.UUID, level *int) (*budget.BudgetSummary, error) {
+	args := m.Called(ctx, userID, budgetID, level)
+	return args.Get(0).(*budget.BudgetSummary), args.Error(1)
+}
+
+func (m *MockBudgetService) RecalculateBudget(ctx context.Context, userID, budgetID uuid.UUID) (*budget.BudgetSummary, error) {
 	args := m.Called(ctx, userID, budgetID)
 	return args.Get(0).(*budget.BudgetSummary), args.Error(1)
 }
 
+func (m *MockBudgetService) GetBudgetPeriods(ctx context.Context, userID, budgetID uuid.UUID) ([]budget.BudgetPeriod, error) {
+	args := m.Called(ctx, userID, budgetID)
+	return args.Get(0).([]budget.BudgetPeriod), args.Error(1)
+}
+
+func (m *MockBudgetService) GetBudgetForecast(ctx context.Context, userID, budgetID uuid.UUID) (*budget.BudgetForecast, error) {
+	args := m.Called(ctx, userID, budgetID)
+	return args.Get(0).(*budget.BudgetForecast), args.Error(1)
+}
+
+func (m *MockBudgetService) GetBudgetReport(ctx context.Context, userID, budgetID uuid.UUID, year int) (*budget.BudgetReport, error) {
+	args := m.Called(ctx, userID, budgetID, year)
+	return args.Get(0).(*budget.BudgetReport), args.Error(1)
+}
+
+func (m *MockBudgetService) EvaluateAlerts(ctx context.Context, userID uuid.UUID) ([]budget.TriggeredAlert, error) {
+	args := m.Called(ctx, userID)
+	return args.Get(0).([]budget.TriggeredAlert), args.Error(1)
+}
+
+func (m *MockBudgetService) SaveAsTemplate(ctx context.Context, userID, budgetID uuid.UUID, req *budget.CreateTemplateRequest) (*budget.BudgetTemplate, error) {
+	args := m.Called(ctx, userID, budgetID, req)
+	return args.Get(0).(*budget.BudgetTemplate), args.Error(1)
+}
+
+func (m *MockBudgetService) ListTemplates(ctx context.Context, userID uuid.UUID) ([]budget.BudgetTemplate, error) {
+	args := m.Called(ctx, userID)
+	return args.Get(0).([]budget.BudgetTemplate), args.Error(1)
+}
+
+func (m *MockBudgetService) DeleteTemplate(ctx context.Context, userID, templateID uuid.UUID) error {
+	args := m.Called(ctx, userID, templateID)
+	return args.Error(0)
+}
+
+func (m *MockBudgetService) CreateBudgetFromTemplate(ctx context.Context, userID, templateID uuid.UUID, req *budget.CopyBudgetRequest) (*budget.BudgetResponse, error) {
+	args := m.Called(ctx, userID, templateID, req)
+	return args.Get(0).(*budget.BudgetResponse), args.Error(1)
+}
+
+func (m *MockBudgetService) CopyBudget(ctx context.Context, userID, budgetID uuid.UUID, req *budget.CopyBudgetRequest) (*budget.BudgetResponse, error) {
+	args := m.Called(ctx, userID, budgetID, req)
+	return args.Get(0).(*budget.BudgetResponse), args.Error(1)
+}
+
+func (m *MockBudgetService) SuggestBudget(ctx context.Context, userID uuid.UUID, months int) (*budget.BudgetSuggestion, error) {
+	args := m.Called(ctx, userID, months)
+	return args.Get(0).(*budget.BudgetSuggestion), args.Error(1)
+}
+
+func (m *MockBudgetService) GetEnvelopes(ctx context.Context, userID, budgetID uuid.UUID) (*budget.EnvelopeSummary, error) {
+	args := m.Called(ctx, userID, budgetID)
+	return args.Get(0).(*budget.EnvelopeSummary), args.Error(1)
+}
+
+func (m *MockBudgetService) AssignToEnvelope(ctx context.Context, userID, budgetID uuid.UUID, req *budget.AssignEnvelopeRequest) (*budget.EnvelopeSummary, error) {
+	args := m.Called(ctx, userID, budgetID, req)
+	return args.Get(0).(*budget.EnvelopeSummary), args.Error(1)
+}
+
+func (m *MockBudgetService) MoveBetweenEnvelopes(ctx context.Context, userID, budgetID uuid.UUID, req *budget.MoveEnvelopeRequest) (*budget.EnvelopeSummary, error) {
+	args := m.Called(ctx, userID, budgetID, req)
+	return args.Get(0).(*budget.EnvelopeSummary), args.Error(1)
+}
+
+func (m *MockBudgetService) CoverOverspending(ctx context.Context, userID, budgetID, categoryID uuid.UUID, req *budget.CoverOverspendingRequest) (*budget.EnvelopeSummary, error) {
+	args := m.Called(ctx, userID, budgetID, categoryID, req)
+	return args.Get(0).(*budget.EnvelopeSummary), args.Error(1)
+}
+
+func (m *MockBudgetService) GetEnvelopeTransfers(ctx context.Context, userID, budgetID uuid.UUID, offset, limit int) ([]budget.EnvelopeTransfer, error) {
+	args := m.Called(ctx, userID, budgetID, offset, limit)
+	return args.Get(0).([]budget.EnvelopeTransfer), args.Error(1)
+}
+
 func (m *MockBudgetService) UpdateBudgetFromTransaction(ctx context.Context, userID, budgetID, categoryID uuid.UUID, amount float64) error {
 	args := m.Called(ctx, userID, budgetID, categoryID, amount)
 	return args.Error(0)
 }
 
+func (m *MockBudgetService) CreateGoal(ctx context.Context, userID uuid.UUID, req *budget.CreateGoalRequest) (*budget.GoalResponse, error) {
+	args := m.Called(ctx, userID, req)
+	return args.Get(0).(*budget.GoalResponse), args.Error(1)
+}
+
+func (m *MockBudgetService) GetGoal(ctx context.Context, userID, goalID uuid.UUID) (*budget.GoalResponse, error) {
+	args := m.Called(ctx, userID, goalID)
+	return args.Get(0).(*budget.GoalResponse), args.Error(1)
+}
+
+func (m *MockBudgetService) ListGoals(ctx context.Context, userID uuid.UUID) ([]budget.GoalResponse, error) {
+	args := m.Called(ctx, userID)
+	return args.Get(0).([]budget.GoalResponse), args.Error(1)
+}
+
+func (m *MockBudgetService) UpdateGoal(ctx context.Context, userID, goalID uuid.UUID, req *budget.UpdateGoalRequest) (*budget.GoalResponse, error) {
+	args := m.Called(ctx, userID, goalID, req)
+	return args.Get(0).(*budget.GoalResponse), args.Error(1)
+}
+
+func (m *MockBudgetService) DeleteGoal(ctx context.Context, userID, goalID uuid.UUID) error {
+	args := m.Called(ctx, userID, goalID)
+	return args.Error(0)
+}
+
 func TestBudgetHandler_CreateBudget(t *testing.T) {
 	gin.SetMode(gin.TestMode)
 
@@ -508,3 +614,404 @@ func TestBudgetHandler_DeleteBudget(t *testing.T) {
 		})
 	}
 }
+
+func TestBudgetHandler_RecalculateBudget(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	userID := uuid.New()
+	budgetID := uuid.New()
+
+	tests := []struct {
+		name           string
+		budgetID       string
+		setupMock      func(*MockBudgetService)
+		expectedStatus int
+	}{
+		{
+			name:     "successful recalculation",
+			budgetID: budgetID.String(),
+			setupMock: func(mockService *MockBudgetService) {
+				mockService.On("RecalculateBudget", mock.Anything, userID, budgetID).
+					Return(&budget.BudgetSummary{TotalAllocated: 1000, TotalSpent: 420}, nil)
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:     "invalid budget ID",
+			budgetID: "invalid-uuid",
+			setupMock: func(mockService *MockBudgetService) {
+				// No mock setup needed for this case
+			},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:     "budget not found",
+			budgetID: budgetID.String(),
+			setupMock: func(mockService *MockBudgetService) {
+				mockService.On("RecalculateBudget", mock.Anything, userID, budgetID).
+					Return((*budget.BudgetSummary)(nil), fmt.Errorf("%w: record not found", budget.ErrBudgetNotFound))
+			},
+			expectedStatus: http.StatusNotFound,
+		},
+		{
+			name:     "budget of another user",
+			budgetID: budgetID.String(),
+			setupMock: func(mockService *MockBudgetService) {
+				mockService.On("RecalculateBudget", mock.Anything, userID, budgetID).
+					Return((*budget.BudgetSummary)(nil), budget.ErrBudgetAccessDenied)
+			},
+			expectedStatus: http.StatusNotFound,
+		},
+		{
+			name:     "recalculation failure",
+			budgetID: budgetID.String(),
+			setupMock: func(mockService *MockBudgetService) {
+				mockService.On("RecalculateBudget", mock.Anything, userID, budgetID).
+					Return((*budget.BudgetSummary)(nil), fmt.Errorf("failed to get spending: connection reset"))
+			},
+			expectedStatus: http.StatusInternalServerError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := &MockBudgetService{}
+			tt.setupMock(mockService)
+
+			handler := NewBudgetHandler(mockService)
+
+			router := gin.New()
+			router.POST("/budgets/:id/recalculate", func(c *gin.Context) {
+				c.Set("user_id", userID)
+				handler.RecalculateBudget(c)
+			})
+
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest("POST", "/budgets/"+tt.budgetID+"/recalculate", nil)
+
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			if tt.expectedStatus == http.StatusOK {
+				var response map[string]budget.BudgetSummary
+				assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+				assert.Equal(t, 420.0, response["summary"].TotalSpent)
+			}
+
+			mockService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestBudgetHandler_GetBudgetForecast(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	userID := uuid.New()
+	budgetID := uuid.New()
+	limitReachedOn := time.Date(2024, 1, 24, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name           string
+		budgetID       string
+		setupMock      func(*MockBudgetService)
+		expectedStatus int
+	}{
+		{
+			name:     "successful forecast",
+			budgetID: budgetID.String(),
+			setupMock: func(mockService *MockBudgetService) {
+				mockService.On("GetBudgetForecast", mock.Anything, userID, budgetID).
+					Return(&budget.BudgetForecast{
+						BudgetID:       budgetID,
+						TotalProjected: 1240,
+						Alerts: []budget.BudgetAlert{
+							{AlertType: "projected_over_budget", ProjectedAmount: 1240, ProjectedDate: &limitReachedOn},
+						},
+					}, nil)
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:     "budget not found",
+			budgetID: budgetID.String(),
+			setupMock: func(mockService *MockBudgetService) {
+				mockService.On("GetBudgetForecast", mock.Anything, userID, budgetID).
+					Return((*budget.BudgetForecast)(nil), fmt.Errorf("budget not found"))
+			},
+			expectedStatus: http.StatusNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := &MockBudgetService{}
+			tt.setupMock(mockService)
+
+			handler := NewBudgetHandler(mockService)
+
+			router := gin.New()
+			router.GET("/budgets/:id/forecast", func(c *gin.Context) {
+				c.Set("user_id", userID)
+				handler.GetBudgetForecast(c)
+			})
+
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest("GET", "/budgets/"+tt.budgetID+"/forecast", nil)
+
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			if tt.expectedStatus == http.StatusOK {
+				var response map[string]budget.BudgetForecast
+				assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+				assert.Equal(t, 1240.0, response["forecast"].TotalProjected)
+				assert.Equal(t, limitReachedOn, *response["forecast"].Alerts[0].ProjectedDate)
+			}
+
+			mockService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestBudgetHandler_GetBudgetReport(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	userID := uuid.New()
+	budgetID := uuid.New()
+	groceries := uuid.New()
+	report := &budget.BudgetReport{
+		BudgetID: budgetID,
+		Year:     2024,
+		Periods:  []budget.ReportPeriod{{Label: "2024-01"}},
+		Categories: []budget.BudgetReportRow{{
+			CategoryID:   groceries,
+			CategoryName: "Groceries",
+			Periods:      []budget.BudgetReportAmounts{{AllocatedAmount: 400, ActualAmount: 300, Variance: 100, VariancePercent: 25}},
+			YearToDate:   budget.BudgetReportAmounts{AllocatedAmount: 400, ActualAmount: 300, Variance: 100, VariancePercent: 25},
+		}},
+		Totals: budget.BudgetReportTotals{
+			Periods:    []budget.BudgetReportAmounts{{AllocatedAmount: 400, ActualAmount: 300, Variance: 100, VariancePercent: 25}},
+			YearToDate: budget.BudgetReportAmounts{AllocatedAmount: 400, ActualAmount: 300, Variance: 100, VariancePercent: 25},
+		},
+		Unbudgeted: []budget.BudgetReportRow{},
+		UnbudgetedTotals: budget.BudgetReportTotals{
+			Periods: []budget.BudgetReportAmounts{{}},
+		},
+	}
+
+	tests := []struct {
+		name           string
+		query          string
+		setupMock      func(*MockBudgetService)
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			name:  "json report",
+			query: "?year=2024",
+			setupMock: func(mockService *MockBudgetService) {
+				mockService.On("GetBudgetReport", mock.Anything, userID, budgetID, 2024).Return(report, nil)
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:  "csv export",
+			query: "?format=csv",
+			setupMock: func(mockService *MockBudgetService) {
+				mockService.On("GetBudgetReport", mock.Anything, userID, budgetID, 0).Return(report, nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody: "section,category_id,category,2024-01 allocated,2024-01 actual,2024-01 variance,2024-01 variance %,ytd allocated,ytd actual,ytd variance,ytd variance %\n" +
+				"budgeted," + groceries.String() + ",Groceries,400.00,300.00,100.00,25.00,400.00,300.00,100.00,25.00\n" +
+				"budgeted,,Total,400.00,300.00,100.00,25.00,400.00,300.00,100.00,25.00\n" +
+				"unbudgeted,,Total,0.00,0.00,0.00,0.00,0.00,0.00,0.00,0.00\n",
+		},
+		{
+			name:           "invalid year",
+			query:          "?year=last",
+			setupMock:      func(mockService *MockBudgetService) {},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "unknown format",
+			query:          "?format=xlsx",
+			setupMock:      func(mockService *MockBudgetService) {},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:  "budget not found",
+			query: "",
+			setupMock: func(mockService *MockBudgetService) {
+				mockService.On("GetBudgetReport", mock.Anything, userID, budgetID, 0).
+					Return((*budget.BudgetReport)(nil), fmt.Errorf("budget not found"))
+			},
+			expectedStatus: http.StatusNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := &MockBudgetService{}
+			tt.setupMock(mockService)
+
+			handler := NewBudgetHandler(mockService)
+
+			router := gin.New()
+			router.GET("/budgets/:id/report", func(c *gin.Context) {
+				c.Set("user_id", userID)
+				handler.GetBudgetReport(c)
+			})
+
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest("GET", "/budgets/"+budgetID.String()+"/report"+tt.query, nil)
+
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			if tt.expectedBody != "" {
+				assert.Equal(t, "text/csv", w.Header().Get("Content-Type"))
+				assert.Equal(t, tt.expectedBody, w.Body.String())
+			} else if tt.expectedStatus == http.StatusOK {
+				var response map[string]budget.BudgetReport
+				assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+				assert.Equal(t, 100.0, response["report"].Totals.YearToDate.Variance)
+			}
+
+			mockService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestBudgetHandler_MoveBetweenEnvelopes(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	userID := uuid.New()
+	budgetID := uuid.New()
+	fromID := uuid.New()
+	toID := uuid.New()
+
+	tests := []struct {
+		name           string
+		requestBody    interface{}
+		setupMock      func(*MockBudgetService)
+		expectedStatus int
+	}{
+		{
+			name:        "successful move",
+			requestBody: budget.MoveEnvelopeRequest{FromCategoryID: fromID, ToCategoryID: toID, Amount: 25},
+			setupMock: func(mockService *MockBudgetService) {
+				mockService.On("MoveBetweenEnvelopes", mock.Anything, userID, budgetID, mock.AnythingOfType("*budget.MoveEnvelopeRequest")).
+					Return(&budget.EnvelopeSummary{BudgetID: budgetID}, nil)
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:        "non-positive amount",
+			requestBody: budget.MoveEnvelopeRequest{FromCategoryID: fromID, ToCategoryID: toID, Amount: -5},
+			setupMock: func(mockService *MockBudgetService) {
+				// No mock setup needed for this case
+			},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:        "insufficient funds",
+			requestBody: budget.MoveEnvelopeRequest{FromCategoryID: fromID, ToCategoryID: toID, Amount: 500},
+			setupMock: func(mockService *MockBudgetService) {
+				mockService.On("MoveBetweenEnvelopes", mock.Anything, userID, budgetID, mock.AnythingOfType("*budget.MoveEnvelopeRequest")).
+					Return((*budget.EnvelopeSummary)(nil), budget.ErrInsufficientFunds)
+			},
+			expectedStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := &MockBudgetService{}
+			tt.setupMock(mockService)
+
+			handler := NewBudgetHandler(mockService)
+
+			router := gin.New()
+			router.POST("/budgets/:id/envelopes/move", func(c *gin.Context) {
+				c.Set("user_id", userID)
+				handler.MoveBetweenEnvelopes(c)
+			})
+
+			body, _ := json.Marshal(tt.requestBody)
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest("POST", "/budgets/"+budgetID.String()+"/envelopes/move", bytes.NewBuffer(body))
+			req.Header.Set("Content-Type", "application/json")
+
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			mockService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestBudgetHandler_CopyBudget(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	userID := uuid.New()
+	budgetID := uuid.New()
+	copied := &budget.BudgetResponse{ID: uuid.New(), UserID: userID, Name: "Monthly Budget"}
+
+	tests := []struct {
+		name           string
+		body           string
+		setupMock      func(*MockBudgetService)
+		expectedStatus int
+	}{
+		{
+			name: "empty body copies into the next period",
+			body: "",
+			setupMock: func(mockService *MockBudgetService) {
+				mockService.On("CopyBudget", mock.Anything, userID, budgetID, &budget.CopyBudgetRequest{}).
+					Return(copied, nil)
+			},
+			expectedStatus: http.StatusCreated,
+		},
+		{
+			name: "scaled copy",
+			body: `{"scale":1.1}`,
+			setupMock: func(mockService *MockBudgetService) {
+				mockService.On("CopyBudget", mock.Anything, userID, budgetID, &budget.CopyBudgetRequest{Scale: 1.1}).
+					Return(copied, nil)
+			},
+			expectedStatus: http.StatusCreated,
+		},
+		{
+			name: "invalid body",
+			body: `{"scale":"more"}`,
+			setupMock: func(mockService *MockBudgetService) {
+				// No mock setup needed for this case
+			},
+			expectedStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := &MockBudgetService{}
+			tt.setupMock(mockService)
+
+			handler := NewBudgetHandler(mockService)
+
+			router := gin.New()
+			router.POST("/budgets/:id/copy", func(c *gin.Context) {
+				c.Set("user_id", userID)
+				handler.CopyBudget(c)
+			})
+
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest("POST", "/budgets/"+budgetID.String()+"/copy", bytes.NewBufferString(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			mockService.AssertExpectations(t)
+		})
+	}
+}
@@ -31,6 +31,11 @@ func (h *CategoryHandler) RegisterRoutes(rg *gin.RouterGroup) {
 	cat.PUT(":id", h.UpdateCategory)
 	cat.DELETE(":id", h.DeleteCategory)
 	cat.GET("/default", h.GetDefaultCategories)
+	cat.GET("/tree", h.GetCategoryTree)
+	cat.PUT(":id/parent", h.MoveCategory)
+	cat.POST(":id/merge", h.MergeCategory)
+	cat.POST(":id/hide", h.HideCategory)
+	cat.DELETE(":id/hide", h.UnhideCategory)
 }
 
 // CreateCategory handles POST /categories
@@ -38,13 +43,25 @@ func (h *CategoryHandler) CreateCategory(c *gin.Context) {
 	ctx, span := otel.Tracer("api").Start(c.Request.Context(), "CreateCategory")
 	defer span.End()
 
+	// Get user ID from context (set by auth middleware)
+	userIDInterface, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "user not authenticated"})
+		return
+	}
+	userID, ok := userIDInterface.(uuid.UUID)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid user id"})
+		return
+	}
+
 	var req transaction.CreateCategoryRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
 
-	category, err := h.Service.CreateCategory(ctx, &req)
+	category, err := h.Service.CreateCategory(ctx, userID, &req)
 	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
@@ -57,6 +74,18 @@ func (h *CategoryHandler) GetCategory(c *gin.Context) {
 	ctx, span := otel.Tracer("api").Start(c.Request.Context(), "GetCategory")
 	defer span.End()
 
+	// Get user ID from context (set by auth middleware)
+	userIDInterface, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "user not authenticated"})
+		return
+	}
+	userID, ok := userIDInterface.(uuid.UUID)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid user id"})
+		return
+	}
+
 	idStr := c.Param("id")
 	id, err := uuid.Parse(idStr)
 	if err != nil {
@@ -64,7 +93,7 @@ func (h *CategoryHandler) GetCategory(c *gin.Context) {
 		return
 	}
 
-	category, err := h.Service.GetCategory(ctx, id)
+	category, err := h.Service.GetCategory(ctx, userID, id)
 	if err != nil {
 		if errors.Is(err, transaction.ErrCategoryNotFound) {
 			c.JSON(http.StatusNotFound, gin.H{"error": "category not found"})
@@ -81,10 +110,22 @@ func (h *CategoryHandler) ListCategories(c *gin.Context) {
 	ctx, span := otel.Tracer("api").Start(c.Request.Context(), "ListCategories")
 	defer span.End()
 
+	// Get user ID from context (set by auth middleware)
+	userIDInterface, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "user not authenticated"})
+		return
+	}
+	userID, ok := userIDInterface.(uuid.UUID)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid user id"})
+		return
+	}
+
 	offset, _ := strconv.Atoi(c.DefaultQuery("offset", "0"))
 	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "50"))
 
-	categories, err := h.Service.GetCategories(ctx, offset, limit)
+	categories, err := h.Service.GetCategories(ctx, userID, offset, limit)
 	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
@@ -97,7 +138,7 @@ func (h *CategoryHandler) GetDefaultCategories(c *gin.Context) {
 	ctx, span := otel.Tracer("api").Start(c.Request.Context(), "GetDefaultCategories")
 	defer span.End()
 
-	categories, err := h.Service.GetDefaultCategories(ctx)
+	categories, err := h.Service.GetDefaultCategories(ctx, c.Query("locale"))
 	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
@@ -105,11 +146,94 @@ func (h *CategoryHandler) GetDefaultCategories(c *gin.Context) {
 	c.JSON(http.StatusOK, categories)
 }
 
+// GetCategoryTree handles GET /categories/tree
+func (h *CategoryHandler) GetCategoryTree(c *gin.Context) {
+	ctx, span := otel.Tracer("api").Start(c.Request.Context(), "GetCategoryTree")
+	defer span.End()
+
+	// Get user ID from context (set by auth middleware)
+	userIDInterface, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "user not authenticated"})
+		return
+	}
+	userID, ok := userIDInterface.(uuid.UUID)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid user id"})
+		return
+	}
+
+	tree, err := h.Service.GetCategoryTree(ctx, userID)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, tree)
+}
+
+// MoveCategory handles PUT /categories/:id/parent
+func (h *CategoryHandler) MoveCategory(c *gin.Context) {
+	ctx, span := otel.Tracer("api").Start(c.Request.Context(), "MoveCategory")
+	defer span.End()
+
+	// Get user ID from context (set by auth middleware)
+	userIDInterface, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "user not authenticated"})
+		return
+	}
+	userID, ok := userIDInterface.(uuid.UUID)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid user id"})
+		return
+	}
+
+	idStr := c.Param("id")
+	id, err := uuid.Parse(idStr)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid category id"})
+		return
+	}
+
+	var req transaction.MoveCategoryRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	category, err := h.Service.MoveCategory(ctx, userID, id, &req)
+	if err != nil {
+		if errors.Is(err, transaction.ErrCategoryNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "category not found"})
+			return
+		}
+		if errors.Is(err, transaction.ErrCategoryNotOwned) {
+			c.JSON(http.StatusForbidden, gin.H{"error": "category is not owned by user"})
+			return
+		}
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, category)
+}
+
 // UpdateCategory handles PUT /categories/:id
 func (h *CategoryHandler) UpdateCategory(c *gin.Context) {
 	ctx, span := otel.Tracer("api").Start(c.Request.Context(), "UpdateCategory")
 	defer span.End()
 
+	// Get user ID from context (set by auth middleware)
+	userIDInterface, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "user not authenticated"})
+		return
+	}
+	userID, ok := userIDInterface.(uuid.UUID)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid user id"})
+		return
+	}
+
 	idStr := c.Param("id")
 	id, err := uuid.Parse(idStr)
 	if err != nil {
@@ -123,12 +247,16 @@ func (h *CategoryHandler) UpdateCategory(c *gin.Context) {
 		return
 	}
 
-	category, err := h.Service.UpdateCategory(ctx, id, &req)
+	category, err := h.Service.UpdateCategory(ctx, userID, id, &req)
 	if err != nil {
 		if errors.Is(err, transaction.ErrCategoryNotFound) {
 			c.JSON(http.StatusNotFound, gin.H{"error": "category not found"})
 			return
 		}
+		if errors.Is(err, transaction.ErrCategoryNotOwned) {
+			c.JSON(http.StatusForbidden, gin.H{"error": "category is not owned by user"})
+			return
+		}
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
@@ -140,6 +268,153 @@ func (h *CategoryHandler) DeleteCategory(c *gin.Context) {
 	ctx, span := otel.Tracer("api").Start(c.Request.Context(), "DeleteCategory")
 	defer span.End()
 
+	// Get user ID from context (set by auth middleware)
+	userIDInterface, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "user not authenticated"})
+		return
+	}
+	userID, ok := userIDInterface.(uuid.UUID)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid user id"})
+		return
+	}
+
+	idStr := c.Param("id")
+	id, err := uuid.Parse(idStr)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid category id"})
+		return
+	}
+
+	// Transactions, budgets and rules still using the category can be moved to another one
+	var reassignTo *uuid.UUID
+	if reassignStr := c.Query("reassign_to"); reassignStr != "" {
+		targetID, err := uuid.Parse(reassignStr)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid reassign_to category id"})
+			return
+		}
+		reassignTo = &targetID
+	}
+
+	if err := h.Service.DeleteCategory(ctx, userID, id, reassignTo); err != nil {
+		if errors.Is(err, transaction.ErrCategoryNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "category not found"})
+			return
+		}
+		if errors.Is(err, transaction.ErrCategoryNotOwned) {
+			c.JSON(http.StatusForbidden, gin.H{"error": "category is not owned by user"})
+			return
+		}
+		if errors.Is(err, transaction.ErrCategoryInUse) {
+			c.JSON(http.StatusConflict, gin.H{"error": "category is still in use, provide reassign_to to move its data"})
+			return
+		}
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// MergeCategory handles POST /categories/:id/merge
+func (h *CategoryHandler) MergeCategory(c *gin.Context) {
+	ctx, span := otel.Tracer("api").Start(c.Request.Context(), "MergeCategory")
+	defer span.End()
+
+	// Get user ID from context (set by auth middleware)
+	userIDInterface, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "user not authenticated"})
+		return
+	}
+	userID, ok := userIDInterface.(uuid.UUID)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid user id"})
+		return
+	}
+
+	idStr := c.Param("id")
+	id, err := uuid.Parse(idStr)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid category id"})
+		return
+	}
+
+	var req transaction.MergeCategoryRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	category, err := h.Service.MergeCategory(ctx, userID, id, &req)
+	if err != nil {
+		if errors.Is(err, transaction.ErrCategoryNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "category not found"})
+			return
+		}
+		if errors.Is(err, transaction.ErrCategoryNotOwned) {
+			c.JSON(http.StatusForbidden, gin.H{"error": "category is not owned by user"})
+			return
+		}
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, category)
+}
+
+// HideCategory handles POST /categories/:id/hide
+func (h *CategoryHandler) HideCategory(c *gin.Context) {
+	ctx, span := otel.Tracer("api").Start(c.Request.Context(), "HideCategory")
+	defer span.End()
+
+	// Get user ID from context (set by auth middleware)
+	userIDInterface, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "user not authenticated"})
+		return
+	}
+	userID, ok := userIDInterface.(uuid.UUID)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid user id"})
+		return
+	}
+
+	idStr := c.Param("id")
+	id, err := uuid.Parse(idStr)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid category id"})
+		return
+	}
+
+	if err := h.Service.HideCategory(ctx, userID, id); err != nil {
+		if errors.Is(err, transaction.ErrCategoryNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "category not found"})
+			return
+		}
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// UnhideCategory handles DELETE /categories/:id/hide
+func (h *CategoryHandler) UnhideCategory(c *gin.Context) {
+	ctx, span := otel.Tracer("api").Start(c.Request.Context(), "UnhideCategory")
+	defer span.End()
+
+	// Get user ID from context (set by auth middleware)
+	userIDInterface, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "user not authenticated"})
+		return
+	}
+	userID, ok := userIDInterface.(uuid.UUID)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid user id"})
+		return
+	}
+
 	idStr := c.Param("id")
 	id, err := uuid.Parse(idStr)
 	if err != nil {
@@ -147,7 +422,7 @@ func (h *CategoryHandler) DeleteCategory(c *gin.Context) {
 		return
 	}
 
-	if err := h.Service.DeleteCategory(ctx, id); err != nil {
+	if err := h.Service.UnhideCategory(ctx, userID, id); err != nil {
 		if errors.Is(err, transaction.ErrCategoryNotFound) {
 			c.JSON(http.StatusNotFound, gin.H{"error": "category not found"})
 			return
@@ -20,28 +20,48 @@ type mockCategoryService struct {
 	mock.Mock
 }
 
-func (m *mockCategoryService) CreateCategory(ctx context.Context, req *transaction.CreateCategoryRequest) (*transaction.Category, error) {
-	args := m.Called(ctx, req)
+func (m *mockCategoryService) CreateCategory(ctx context.Context, userID uuid.UUID, req *transaction.CreateCategoryRequest) (*transaction.Category, error) {
+	args := m.Called(ctx, userID, req)
 	return args.Get(0).(*transaction.Category), args.Error(1)
 }
-func (m *mockCategoryService) GetCategory(ctx context.Context, categoryID uuid.UUID) (*transaction.Category, error) {
-	args := m.Called(ctx, categoryID)
+func (m *mockCategoryService) GetCategory(ctx context.Context, userID, categoryID uuid.UUID) (*transaction.Category, error) {
+	args := m.Called(ctx, userID, categoryID)
 	return args.Get(0).(*transaction.Category), args.Error(1)
 }
-func (m *mockCategoryService) GetCategories(ctx context.Context, offset, limit int) ([]transaction.Category, error) {
-	args := m.Called(ctx, offset, limit)
+func (m *mockCategoryService) GetCategories(ctx context.Context, userID uuid.UUID, offset, limit int) ([]transaction.Category, error) {
+	args := m.Called(ctx, userID, offset, limit)
 	return args.Get(0).([]transaction.Category), args.Error(1)
 }
-func (m *mockCategoryService) GetDefaultCategories(ctx context.Context) ([]transaction.Category, error) {
-	args := m.Called(ctx)
+func (m *mockCategoryService) GetDefaultCategories(ctx context.Context, locale string) ([]transaction.Category, error) {
+	args := m.Called(ctx, locale)
 	return args.Get(0).([]transaction.Category), args.Error(1)
 }
-func (m *mockCategoryService) UpdateCategory(ctx context.Context, categoryID uuid.UUID, req *transaction.CreateCategoryRequest) (*transaction.Category, error) {
-	args := m.Called(ctx, categoryID, req)
+func (m *mockCategoryService) UpdateCategory(ctx context.Context, userID, categoryID uuid.UUID, req *transaction.CreateCategoryRequest) (*transaction.Category, error) {
+	args := m.Called(ctx, userID, categoryID, req)
 	return args.Get(0).(*transaction.Category), args.Error(1)
 }
-func (m *mockCategoryService) DeleteCategory(ctx context.Context, categoryID uuid.UUID) error {
-	args := m.Called(ctx, categoryID)
+func (m *mockCategoryService) DeleteCategory(ctx context.Context, userID, categoryID uuid.UUID, reassignTo *uuid.UUID) error {
+	args := m.Called(ctx, userID, categoryID, reassignTo)
+	return args.Error(0)
+}
+func (m *mockCategoryService) MergeCategory(ctx context.Context, userID, categoryID uuid.UUID, req *transaction.MergeCategoryRequest) (*transaction.Category, error) {
+	args := m.Called(ctx, userID, categoryID, req)
+	return args.Get(0).(*transaction.Category), args.Error(1)
+}
+func (m *mockCategoryService) GetCategoryTree(ctx context.Context, userID uuid.UUID) ([]transaction.CategoryNode, error) {
+	args := m.Called(ctx, userID)
+	return args.Get(0).([]transaction.CategoryNode), args.Error(1)
+}
+func (m *mockCategoryService) MoveCategory(ctx context.Context, userID, categoryID uuid.UUID, req *transaction.MoveCategoryRequest) (*transaction.Category, error) {
+	args := m.Called(ctx, userID, categoryID, req)
+	return args.Get(0).(*transaction.Category), args.Error(1)
+}
+func (m *mockCategoryService) HideCategory(ctx context.Context, userID, categoryID uuid.UUID) error {
+	args := m.Called(ctx, userID, categoryID)
+	return args.Error(0)
+}
+func (m *mockCategoryService) UnhideCategory(ctx context.Context, userID, categoryID uuid.UUID) error {
+	args := m.Called(ctx, userID, categoryID)
 	return args.Error(0)
 }
 
@@ -76,16 +96,44 @@ func (m *mockCategoryService) UpdateAccount(context.Context, uuid.UUID, uuid.UUI
 func (m *mockCategoryService) DeleteAccount(context.Context, uuid.UUID, uuid.UUID) error {
 	return nil
 }
+func (m *mockCategoryService) ImportTransactions(context.Context, uuid.UUID, *transaction.ImportTransactionsRequest) (*transaction.ImportTransactionsResponse, error) {
+	return nil, nil
+}
+func (m *mockCategoryService) GetReviewQueue(context.Context, uuid.UUID, int, int) ([]transaction.TransactionResponse, error) {
+	return nil, nil
+}
+func (m *mockCategoryService) ReviewCategory(context.Context, uuid.UUID, uuid.UUID, *transaction.ReviewCategoryRequest) (*transaction.TransactionResponse, error) {
+	return nil, nil
+}
+func (m *mockCategoryService) CreateMerchant(context.Context, *transaction.CreateMerchantRequest) (*transaction.Merchant, error) {
+	return nil, nil
+}
+func (m *mockCategoryService) GetMerchant(context.Context, uuid.UUID, uuid.UUID) (*transaction.Merchant, error) {
+	return nil, nil
+}
+func (m *mockCategoryService) GetMerchants(context.Context, uuid.UUID, int, int) ([]transaction.Merchant, error) {
+	return nil, nil
+}
+func (m *mockCategoryService) UpdateMerchant(context.Context, uuid.UUID, *transaction.CreateMerchantRequest) (*transaction.Merchant, error) {
+	return nil, nil
+}
+func (m *mockCategoryService) DeleteMerchant(context.Context, uuid.UUID) error {
+	return nil
+}
 
 func TestCategoryHandler_CreateCategory(t *testing.T) {
 	gin.SetMode(gin.TestMode)
 	mockSvc := new(mockCategoryService)
 	h := NewCategoryHandler(mockSvc)
 	r := gin.Default()
-	r.POST("/categories", h.CreateCategory)
+	userID := uuid.New()
+	r.POST("/categories", func(c *gin.Context) {
+		c.Set("user_id", userID)
+		h.CreateCategory(c)
+	})
 
 	cat := &transaction.Category{ID: uuid.New(), Name: "Test"}
-	mockSvc.On("CreateCategory", mock.Anything, mock.Anything).Return(cat, nil)
+	mockSvc.On("CreateCategory", mock.Anything, userID, mock.Anything).Return(cat, nil)
 
 	body, _ := json.Marshal(transaction.CreateCategoryRequest{Name: "Test"})
 	w := httptest.NewRecorder()
@@ -103,7 +151,11 @@ func TestCategoryHandler_CreateCategory_BadRequest(t *testing.T) {
 	mockSvc := new(mockCategoryService)
 	h := NewCategoryHandler(mockSvc)
 	r := gin.Default()
-	r.POST("/categories", h.CreateCategory)
+	userID := uuid.New()
+	r.POST("/categories", func(c *gin.Context) {
+		c.Set("user_id", userID)
+		h.CreateCategory(c)
+	})
 
 	w := httptest.NewRecorder()
 	req, _ := http.NewRequest("POST", "/categories", bytes.NewReader([]byte("bad json")))
@@ -117,9 +169,13 @@ func TestCategoryHandler_GetCategory_NotFound(t *testing.T) {
 	mockSvc := new(mockCategoryService)
 	h := NewCategoryHandler(mockSvc)
 	r := gin.Default()
-	r.GET("/categories/:id", h.GetCategory)
+	userID := uuid.New()
+	r.GET("/categories/:id", func(c *gin.Context) {
+		c.Set("user_id", userID)
+		h.GetCategory(c)
+	})
 
-	mockSvc.On("GetCategory", mock.Anything, mock.Anything).Return(&transaction.Category{}, transaction.ErrCategoryNotFound)
+	mockSvc.On("GetCategory", mock.Anything, userID, mock.Anything).Return(&transaction.Category{}, transaction.ErrCategoryNotFound)
 	w := httptest.NewRecorder()
 	id := uuid.New().String()
 	req, _ := http.NewRequest("GET", "/categories/"+id, nil)
@@ -132,9 +188,13 @@ func TestCategoryHandler_ListCategories(t *testing.T) {
 	mockSvc := new(mockCategoryService)
 	h := NewCategoryHandler(mockSvc)
 	r := gin.Default()
-	r.GET("/categories", h.ListCategories)
+	userID := uuid.New()
+	r.GET("/categories", func(c *gin.Context) {
+		c.Set("user_id", userID)
+		h.ListCategories(c)
+	})
 
-	mockSvc.On("GetCategories", mock.Anything, 0, 50).Return([]transaction.Category{{ID: uuid.New(), Name: "Test"}}, nil)
+	mockSvc.On("GetCategories", mock.Anything, userID, 0, 50).Return([]transaction.Category{{ID: uuid.New(), Name: "Test"}}, nil)
 	w := httptest.NewRecorder()
 	req, _ := http.NewRequest("GET", "/categories", nil)
 	r.ServeHTTP(w, req)
@@ -148,7 +208,7 @@ func TestCategoryHandler_GetDefaultCategories(t *testing.T) {
 	r := gin.Default()
 	r.GET("/categories/default", h.GetDefaultCategories)
 
-	mockSvc.On("GetDefaultCategories", mock.Anything).Return([]transaction.Category{{ID: uuid.New(), Name: "Default"}}, nil)
+	mockSvc.On("GetDefaultCategories", mock.Anything, "").Return([]transaction.Category{{ID: uuid.New(), Name: "Default"}}, nil)
 	w := httptest.NewRecorder()
 	req, _ := http.NewRequest("GET", "/categories/default", nil)
 	r.ServeHTTP(w, req)
@@ -160,10 +220,14 @@ func TestCategoryHandler_UpdateCategory(t *testing.T) {
 	mockSvc := new(mockCategoryService)
 	h := NewCategoryHandler(mockSvc)
 	r := gin.Default()
-	r.PUT("/categories/:id", h.UpdateCategory)
+	userID := uuid.New()
+	r.PUT("/categories/:id", func(c *gin.Context) {
+		c.Set("user_id", userID)
+		h.UpdateCategory(c)
+	})
 
 	cat := &transaction.Category{ID: uuid.New(), Name: "Updated"}
-	mockSvc.On("UpdateCategory", mock.Anything, mock.Anything, mock.Anything).Return(cat, nil)
+	mockSvc.On("UpdateCategory", mock.Anything, userID, mock.Anything, mock.Anything).Return(cat, nil)
 	id := cat.ID.String()
 	body, _ := json.Marshal(transaction.CreateCategoryRequest{Name: "Updated"})
 	w := httptest.NewRecorder()
@@ -178,12 +242,173 @@ func TestCategoryHandler_DeleteCategory(t *testing.T) {
 	mockSvc := new(mockCategoryService)
 	h := NewCategoryHandler(mockSvc)
 	r := gin.Default()
-	r.DELETE("/categories/:id", h.DeleteCategory)
+	userID := uuid.New()
+	r.DELETE("/categories/:id", func(c *gin.Context) {
+		c.Set("user_id", userID)
+		h.DeleteCategory(c)
+	})
 
-	mockSvc.On("DeleteCategory", mock.Anything, mock.Anything).Return(nil)
+	mockSvc.On("DeleteCategory", mock.Anything, userID, mock.Anything, (*uuid.UUID)(nil)).Return(nil)
 	id := uuid.New().String()
 	w := httptest.NewRecorder()
 	req, _ := http.NewRequest("DELETE", "/categories/"+id, nil)
 	r.ServeHTTP(w, req)
 	assert.Equal(t, http.StatusNoContent, w.Code)
 }
+
+func TestCategoryHandler_UpdateCategory_NotOwned(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	mockSvc := new(mockCategoryService)
+	h := NewCategoryHandler(mockSvc)
+	r := gin.Default()
+	userID := uuid.New()
+	r.PUT("/categories/:id", func(c *gin.Context) {
+		c.Set("user_id", userID)
+		h.UpdateCategory(c)
+	})
+
+	mockSvc.On("UpdateCategory", mock.Anything, userID, mock.Anything, mock.Anything).Return(&transaction.Category{}, transaction.ErrCategoryNotOwned)
+	body, _ := json.Marshal(transaction.CreateCategoryRequest{Name: "Groceries"})
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("PUT", "/categories/"+uuid.New().String(), bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	r.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusForbidden, w.Code)
+}
+
+func TestCategoryHandler_HideCategory(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	mockSvc := new(mockCategoryService)
+	h := NewCategoryHandler(mockSvc)
+	r := gin.Default()
+	userID := uuid.New()
+	r.POST("/categories/:id/hide", func(c *gin.Context) {
+		c.Set("user_id", userID)
+		h.HideCategory(c)
+	})
+
+	categoryID := uuid.New()
+	mockSvc.On("HideCategory", mock.Anything, userID, categoryID).Return(nil)
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("POST", "/categories/"+categoryID.String()+"/hide", nil)
+	r.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusNoContent, w.Code)
+	mockSvc.AssertExpectations(t)
+}
+
+func TestCategoryHandler_ListCategories_Unauthorized(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	mockSvc := new(mockCategoryService)
+	h := NewCategoryHandler(mockSvc)
+	r := gin.Default()
+	r.GET("/categories", h.ListCategories)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/categories", nil)
+	r.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+}
+
+func TestCategoryHandler_GetCategoryTree(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	mockSvc := new(mockCategoryService)
+	h := NewCategoryHandler(mockSvc)
+	r := gin.Default()
+	userID := uuid.New()
+	r.GET("/categories/tree", func(c *gin.Context) {
+		c.Set("user_id", userID)
+		h.GetCategoryTree(c)
+	})
+
+	parent := transaction.Category{ID: uuid.New(), Name: "Food"}
+	child := transaction.Category{ID: uuid.New(), Name: "Groceries", ParentID: &parent.ID}
+	tree := []transaction.CategoryNode{{Category: parent, Children: []transaction.CategoryNode{{Category: child, Level: 1}}}}
+	mockSvc.On("GetCategoryTree", mock.Anything, userID).Return(tree, nil)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/categories/tree", nil)
+	r.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+	var resp []transaction.CategoryNode
+	_ = json.Unmarshal(w.Body.Bytes(), &resp)
+	if assert.Len(t, resp, 1) && assert.Len(t, resp[0].Children, 1) {
+		assert.Equal(t, child.ID, resp[0].Children[0].ID)
+		assert.Equal(t, 1, resp[0].Children[0].Level)
+	}
+}
+
+func TestCategoryHandler_MoveCategory_Cycle(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	mockSvc := new(mockCategoryService)
+	h := NewCategoryHandler(mockSvc)
+	r := gin.Default()
+	userID := uuid.New()
+	r.PUT("/categories/:id/parent", func(c *gin.Context) {
+		c.Set("user_id", userID)
+		h.MoveCategory(c)
+	})
+
+	parentID := uuid.New()
+	mockSvc.On("MoveCategory", mock.Anything, userID, mock.Anything, &transaction.MoveCategoryRequest{ParentID: &parentID}).
+		Return(&transaction.Category{}, transaction.ErrCategoryCycle)
+	body, _ := json.Marshal(transaction.MoveCategoryRequest{ParentID: &parentID})
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("PUT", "/categories/"+uuid.New().String()+"/parent", bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	r.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestCategoryHandler_DeleteCategory_InUse(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	mockSvc := new(mockCategoryService)
+	h := NewCategoryHandler(mockSvc)
+	r := gin.Default()
+	userID := uuid.New()
+	r.DELETE("/categories/:id", func(c *gin.Context) {
+		c.Set("user_id", userID)
+		h.DeleteCategory(c)
+	})
+
+	categoryID := uuid.New()
+	targetID := uuid.New()
+	mockSvc.On("DeleteCategory", mock.Anything, userID, categoryID, (*uuid.UUID)(nil)).Return(transaction.ErrCategoryInUse)
+	mockSvc.On("DeleteCategory", mock.Anything, userID, categoryID, &targetID).Return(nil)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("DELETE", "/categories/"+categoryID.String(), nil)
+	r.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusConflict, w.Code)
+
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("DELETE", "/categories/"+categoryID.String()+"?reassign_to="+targetID.String(), nil)
+	r.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusNoContent, w.Code)
+	mockSvc.AssertExpectations(t)
+}
+
+func TestCategoryHandler_MergeCategory(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	mockSvc := new(mockCategoryService)
+	h := NewCategoryHandler(mockSvc)
+	r := gin.Default()
+	userID := uuid.New()
+	r.POST("/categories/:id/merge", func(c *gin.Context) {
+		c.Set("user_id", userID)
+		h.MergeCategory(c)
+	})
+
+	categoryID := uuid.New()
+	target := &transaction.Category{ID: uuid.New(), Name: "Food"}
+	mockSvc.On("MergeCategory", mock.Anything, userID, categoryID, &transaction.MergeCategoryRequest{TargetID: target.ID}).Return(target, nil)
+
+	body, _ := json.Marshal(transaction.MergeCategoryRequest{TargetID: target.ID})
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("POST", "/categories/"+categoryID.String()+"/merge", bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	r.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+	var resp transaction.Category
+	_ = json.Unmarshal(w.Body.Bytes(), &resp)
+	assert.Equal(t, target.ID, resp.ID)
+}
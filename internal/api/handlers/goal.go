@@ -0,0 +1,187 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
+	"fiscaflow/internal/domain/budget"
+)
+
+// GoalHandler handles savings goal HTTP requests
+type GoalHandler struct {
+	budgetService budget.Service
+}
+
+// NewGoalHandler creates a new savings goal handler
+func NewGoalHandler(budgetService budget.Service) *GoalHandler {
+	return &GoalHandler{
+		budgetService: budgetService,
+	}
+}
+
+// CreateGoal handles POST /api/v1/goals
+func (h *GoalHandler) CreateGoal(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	var req budget.CreateGoalRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request body"})
+		return
+	}
+
+	userUUID, ok := userID.(uuid.UUID)
+	if !ok {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "invalid user ID"})
+		return
+	}
+
+	goal, err := h.budgetService.CreateGoal(c.Request.Context(), userUUID, &req)
+	if err != nil {
+		c.JSON(goalErrorStatus(err, http.StatusInternalServerError), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"goal": goal})
+}
+
+// ListGoals handles GET /api/v1/goals
+func (h *GoalHandler) ListGoals(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	userUUID, ok := userID.(uuid.UUID)
+	if !ok {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "invalid user ID"})
+		return
+	}
+
+	goals, err := h.budgetService.ListGoals(c.Request.Context(), userUUID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"goals": goals})
+}
+
+// GetGoal handles GET /api/v1/goals/:id
+func (h *GoalHandler) GetGoal(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	goalID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid goal ID"})
+		return
+	}
+
+	userUUID, ok := userID.(uuid.UUID)
+	if !ok {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "invalid user ID"})
+		return
+	}
+
+	goal, err := h.budgetService.GetGoal(c.Request.Context(), userUUID, goalID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"goal": goal})
+}
+
+// UpdateGoal handles PUT /api/v1/goals/:id
+func (h *GoalHandler) UpdateGoal(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	goalID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid goal ID"})
+		return
+	}
+
+	var req budget.UpdateGoalRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request body"})
+		return
+	}
+
+	userUUID, ok := userID.(uuid.UUID)
+	if !ok {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "invalid user ID"})
+		return
+	}
+
+	goal, err := h.budgetService.UpdateGoal(c.Request.Context(), userUUID, goalID, &req)
+	if err != nil {
+		c.JSON(goalErrorStatus(err, http.StatusNotFound), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"goal": goal})
+}
+
+// DeleteGoal handles DELETE /api/v1/goals/:id
+func (h *GoalHandler) DeleteGoal(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	goalID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid goal ID"})
+		return
+	}
+
+	userUUID, ok := userID.(uuid.UUID)
+	if !ok {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "invalid user ID"})
+		return
+	}
+
+	if err := h.budgetService.DeleteGoal(c.Request.Context(), userUUID, goalID); err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// goalErrorStatus maps invalid goals to a bad request and other errors to the given status code
+func goalErrorStatus(err error, status int) int {
+	if errors.Is(err, budget.ErrInvalidGoal) {
+		return http.StatusBadRequest
+	}
+	return status
+}
+
+// RegisterRoutes registers all savings goal routes
+func (h *GoalHandler) RegisterRoutes(api *gin.RouterGroup) {
+	goals := api.Group("/goals")
+	{
+		goals.POST("", h.CreateGoal)
+		goals.GET("", h.ListGoals)
+		goals.GET("/:id", h.GetGoal)
+		goals.PUT("/:id", h.UpdateGoal)
+		goals.DELETE("/:id", h.DeleteGoal)
+	}
+}
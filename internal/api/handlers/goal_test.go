@@ -0,0 +1,111 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"fiscaflow/internal/domain/budget"
+)
+
+func TestGoalHandler_CreateGoal(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	userID := uuid.New()
+
+	tests := []struct {
+		name           string
+		body           string
+		setupMock      func(*MockBudgetService)
+		expectedStatus int
+	}{
+		{
+			name: "successful creation",
+			body: `{"name":"Vacation","target_amount":2000,"contribution_tag":"vacation"}`,
+			setupMock: func(mockService *MockBudgetService) {
+				mockService.On("CreateGoal", mock.Anything, userID, mock.AnythingOfType("*budget.CreateGoalRequest")).
+					Return(&budget.GoalResponse{ID: uuid.New(), Name: "Vacation", TargetAmount: 2000, Progress: budget.GoalProgress{Status: budget.GoalStatusInProgress}}, nil)
+			},
+			expectedStatus: http.StatusCreated,
+		},
+		{
+			name:           "missing name",
+			body:           `{"target_amount":2000}`,
+			setupMock:      func(mockService *MockBudgetService) {},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name: "invalid goal",
+			body: `{"name":"Vacation","target_amount":2000}`,
+			setupMock: func(mockService *MockBudgetService) {
+				mockService.On("CreateGoal", mock.Anything, userID, mock.AnythingOfType("*budget.CreateGoalRequest")).
+					Return((*budget.GoalResponse)(nil), fmt.Errorf("%w: link an account or set a contribution tag to track progress", budget.ErrInvalidGoal))
+			},
+			expectedStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := &MockBudgetService{}
+			tt.setupMock(mockService)
+
+			handler := NewGoalHandler(mockService)
+
+			router := gin.New()
+			router.POST("/goals", func(c *gin.Context) {
+				c.Set("user_id", userID)
+				handler.CreateGoal(c)
+			})
+
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest("POST", "/goals", bytes.NewBufferString(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			mockService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestGoalHandler_GetGoal(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	mockService := &MockBudgetService{}
+	handler := NewGoalHandler(mockService)
+	userID := uuid.New()
+	goalID := uuid.New()
+
+	mockService.On("GetGoal", mock.Anything, userID, goalID).Return(&budget.GoalResponse{
+		ID:       goalID,
+		Name:     "Vacation",
+		Progress: budget.GoalProgress{CurrentAmount: 650, Status: budget.GoalStatusOnTrack},
+	}, nil)
+
+	router := gin.New()
+	router.GET("/goals/:id", func(c *gin.Context) {
+		c.Set("user_id", userID)
+		handler.GetGoal(c)
+	})
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest("GET", "/goals/invalid-uuid", nil))
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest("GET", "/goals/"+goalID.String(), nil))
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	var response map[string]budget.GoalResponse
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, budget.GoalStatusOnTrack, response["goal"].Progress.Status)
+	assert.Equal(t, 650.0, response["goal"].Progress.CurrentAmount)
+	mockService.AssertExpectations(t)
+}
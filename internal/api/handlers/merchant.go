@@ -0,0 +1,164 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel"
+
+	"fiscaflow/internal/domain/transaction"
+)
+
+// MerchantHandler handles merchant-related HTTP requests
+type MerchantHandler struct {
+	Service transaction.Service
+}
+
+// NewMerchantHandler creates a new MerchantHandler
+func NewMerchantHandler(service transaction.Service) *MerchantHandler {
+	return &MerchantHandler{Service: service}
+}
+
+// RegisterRoutes registers merchant routes
+func (h *MerchantHandler) RegisterRoutes(rg *gin.RouterGroup) {
+	m := rg.Group("/merchants")
+	m.POST("", h.CreateMerchant)
+	m.GET("", h.ListMerchants)
+	m.GET(":id", h.GetMerchant)
+	m.PUT(":id", h.UpdateMerchant)
+	m.DELETE(":id", h.DeleteMerchant)
+}
+
+// CreateMerchant handles POST /merchants
+func (h *MerchantHandler) CreateMerchant(c *gin.Context) {
+	ctx, span := otel.Tracer("api").Start(c.Request.Context(), "CreateMerchant")
+	defer span.End()
+
+	var req transaction.CreateMerchantRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	merchant, err := h.Service.CreateMerchant(ctx, &req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusCreated, merchant)
+}
+
+// GetMerchant handles GET /merchants/:id
+func (h *MerchantHandler) GetMerchant(c *gin.Context) {
+	ctx, span := otel.Tracer("api").Start(c.Request.Context(), "GetMerchant")
+	defer span.End()
+
+	userIDInterface, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "user not authenticated"})
+		return
+	}
+	userID, ok := userIDInterface.(uuid.UUID)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid user id"})
+		return
+	}
+
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid merchant id"})
+		return
+	}
+
+	merchant, err := h.Service.GetMerchant(ctx, userID, id)
+	if err != nil {
+		if errors.Is(err, transaction.ErrMerchantNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "merchant not found"})
+			return
+		}
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, merchant)
+}
+
+// ListMerchants handles GET /merchants
+func (h *MerchantHandler) ListMerchants(c *gin.Context) {
+	ctx, span := otel.Tracer("api").Start(c.Request.Context(), "ListMerchants")
+	defer span.End()
+
+	userIDInterface, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "user not authenticated"})
+		return
+	}
+	userID, ok := userIDInterface.(uuid.UUID)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid user id"})
+		return
+	}
+
+	offset, _ := strconv.Atoi(c.DefaultQuery("offset", "0"))
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "50"))
+
+	merchants, err := h.Service.GetMerchants(ctx, userID, offset, limit)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, merchants)
+}
+
+// UpdateMerchant handles PUT /merchants/:id
+func (h *MerchantHandler) UpdateMerchant(c *gin.Context) {
+	ctx, span := otel.Tracer("api").Start(c.Request.Context(), "UpdateMerchant")
+	defer span.End()
+
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid merchant id"})
+		return
+	}
+
+	var req transaction.CreateMerchantRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	merchant, err := h.Service.UpdateMerchant(ctx, id, &req)
+	if err != nil {
+		if errors.Is(err, transaction.ErrMerchantNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "merchant not found"})
+			return
+		}
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, merchant)
+}
+
+// DeleteMerchant handles DELETE /merchants/:id
+func (h *MerchantHandler) DeleteMerchant(c *gin.Context) {
+	ctx, span := otel.Tracer("api").Start(c.Request.Context(), "DeleteMerchant")
+	defer span.End()
+
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid merchant id"})
+		return
+	}
+
+	if err := h.Service.DeleteMerchant(ctx, id); err != nil {
+		if errors.Is(err, transaction.ErrMerchantNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "merchant not found"})
+			return
+		}
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
@@ -0,0 +1,101 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"fiscaflow/internal/domain/transaction"
+)
+
+func TestMerchantHandler_CreateMerchant(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	mockSvc := new(mockTransactionService)
+	h := NewMerchantHandler(mockSvc)
+	r := gin.Default()
+	r.POST("/merchants", h.CreateMerchant)
+
+	merchant := &transaction.Merchant{ID: uuid.New(), Name: "Amazon", NormalizedName: "AMAZON"}
+	mockSvc.On("CreateMerchant", mock.Anything, mock.Anything).Return(merchant, nil)
+
+	body, _ := json.Marshal(transaction.CreateMerchantRequest{Name: "Amazon", Aliases: []string{"AMZN"}})
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("POST", "/merchants", bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	r.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusCreated, w.Code)
+	var resp transaction.Merchant
+	_ = json.Unmarshal(w.Body.Bytes(), &resp)
+	assert.Equal(t, merchant.ID, resp.ID)
+}
+
+func TestMerchantHandler_CreateMerchant_BadRequest(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	mockSvc := new(mockTransactionService)
+	h := NewMerchantHandler(mockSvc)
+	r := gin.Default()
+	r.POST("/merchants", h.CreateMerchant)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("POST", "/merchants", bytes.NewReader([]byte(`{"aliases":["AMZN"]}`)))
+	req.Header.Set("Content-Type", "application/json")
+	r.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestMerchantHandler_GetMerchant_NotFound(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	mockSvc := new(mockTransactionService)
+	h := NewMerchantHandler(mockSvc)
+	r := gin.Default()
+	userID := uuid.New()
+	r.GET("/merchants/:id", func(c *gin.Context) {
+		c.Set("user_id", userID)
+		h.GetMerchant(c)
+	})
+
+	mockSvc.On("GetMerchant", mock.Anything, userID, mock.Anything).Return(nil, transaction.ErrMerchantNotFound)
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/merchants/"+uuid.New().String(), nil)
+	r.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestMerchantHandler_ListMerchants(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	mockSvc := new(mockTransactionService)
+	h := NewMerchantHandler(mockSvc)
+	r := gin.Default()
+	userID := uuid.New()
+	r.GET("/merchants", func(c *gin.Context) {
+		c.Set("user_id", userID)
+		h.ListMerchants(c)
+	})
+
+	mockSvc.On("GetMerchants", mock.Anything, userID, 0, 50).Return([]transaction.Merchant{{ID: uuid.New(), Name: "Amazon"}}, nil)
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/merchants", nil)
+	r.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+}
+
+func TestMerchantHandler_DeleteMerchant(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	mockSvc := new(mockTransactionService)
+	h := NewMerchantHandler(mockSvc)
+	r := gin.Default()
+	r.DELETE("/merchants/:id", h.DeleteMerchant)
+
+	mockSvc.On("DeleteMerchant", mock.Anything, mock.Anything).Return(nil)
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("DELETE", "/merchants/"+uuid.New().String(), nil)
+	r.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusNoContent, w.Code)
+}
@@ -0,0 +1,171 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"fiscaflow/internal/domain/notification"
+)
+
+// MockNotificationService is a mock implementation of notification.Service
+type MockNotificationService struct {
+	mock.Mock
+}
+
+func (m *MockNotificationService) Send(ctx context.Context, userID uuid.UUID, message *notification.Message) error {
+	args := m.Called(ctx, userID, message)
+	return args.Error(0)
+}
+
+func (m *MockNotificationService) GetPreferences(ctx context.Context, userID uuid.UUID) (*notification.Preferences, error) {
+	args := m.Called(ctx, userID)
+	return args.Get(0).(*notification.Preferences), args.Error(1)
+}
+
+func (m *MockNotificationService) UpdatePreferences(ctx context.Context, userID uuid.UUID, req *notification.UpdatePreferencesRequest) (*notification.Preferences, error) {
+	args := m.Called(ctx, userID, req)
+	return args.Get(0).(*notification.Preferences), args.Error(1)
+}
+
+func (m *MockNotificationService) ListNotifications(ctx context.Context, userID uuid.UUID, unreadOnly bool, offset, limit int) ([]notification.Notification, error) {
+	args := m.Called(ctx, userID, unreadOnly, offset, limit)
+	return args.Get(0).([]notification.Notification), args.Error(1)
+}
+
+func (m *MockNotificationService) MarkAsRead(ctx context.Context, userID, notificationID uuid.UUID) error {
+	args := m.Called(ctx, userID, notificationID)
+	return args.Error(0)
+}
+
+func (m *MockNotificationService) MarkAllAsRead(ctx context.Context, userID uuid.UUID) error {
+	args := m.Called(ctx, userID)
+	return args.Error(0)
+}
+
+func TestNotificationHandler_ListNotifications(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	mockService := &MockNotificationService{}
+	handler := NewNotificationHandler(mockService)
+	userID := uuid.New()
+
+	mockService.On("ListNotifications", mock.Anything, userID, true, 0, 20).
+		Return([]notification.Notification{{ID: uuid.New(), UserID: userID, Type: "budget_alert", Title: "Budget alert"}}, nil)
+
+	router := gin.New()
+	router.GET("/notifications", func(c *gin.Context) {
+		c.Set("user_id", userID)
+		handler.ListNotifications(c)
+	})
+
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest("GET", "/notifications?unread=true", nil)
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	var response map[string][]notification.Notification
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Len(t, response["notifications"], 1)
+	mockService.AssertExpectations(t)
+}
+
+func TestNotificationHandler_MarkAsRead(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	userID := uuid.New()
+	notificationID := uuid.New()
+
+	tests := []struct {
+		name           string
+		notificationID string
+		setupMock      func(*MockNotificationService)
+		expectedStatus int
+	}{
+		{
+			name:           "successful mark as read",
+			notificationID: notificationID.String(),
+			setupMock: func(mockService *MockNotificationService) {
+				mockService.On("MarkAsRead", mock.Anything, userID, notificationID).Return(nil)
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "invalid notification ID",
+			notificationID: "invalid-uuid",
+			setupMock:      func(mockService *MockNotificationService) {},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "notification not found",
+			notificationID: notificationID.String(),
+			setupMock: func(mockService *MockNotificationService) {
+				mockService.On("MarkAsRead", mock.Anything, userID, notificationID).Return(notification.ErrNotificationNotFound)
+			},
+			expectedStatus: http.StatusNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := &MockNotificationService{}
+			tt.setupMock(mockService)
+
+			handler := NewNotificationHandler(mockService)
+
+			router := gin.New()
+			router.POST("/notifications/:id/read", func(c *gin.Context) {
+				c.Set("user_id", userID)
+				handler.MarkAsRead(c)
+			})
+
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest("POST", "/notifications/"+tt.notificationID+"/read", nil)
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			mockService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestNotificationHandler_UpdatePreferences(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	mockService := &MockNotificationService{}
+	handler := NewNotificationHandler(mockService)
+	userID := uuid.New()
+
+	mockService.On("UpdatePreferences", mock.Anything, userID, mock.MatchedBy(func(req *notification.UpdatePreferencesRequest) bool {
+		return *req.EmailEnabled && *req.WebhookURL == "https://example.com/hooks"
+	})).Return(&notification.Preferences{UserID: userID, InAppEnabled: true, EmailEnabled: true, WebhookURL: "https://example.com/hooks"}, nil)
+
+	router := gin.New()
+	router.PUT("/notifications/preferences", func(c *gin.Context) {
+		c.Set("user_id", userID)
+		handler.UpdatePreferences(c)
+	})
+
+	// Invalid webhook URL
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest("PUT", "/notifications/preferences", bytes.NewBufferString(`{"webhook_url":"not-a-url"}`))
+	req.Header.Set("Content-Type", "application/json")
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	w = httptest.NewRecorder()
+	req = httptest.NewRequest("PUT", "/notifications/preferences", bytes.NewBufferString(`{"email_enabled":true,"webhook_url":"https://example.com/hooks"}`))
+	req.Header.Set("Content-Type", "application/json")
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	var response map[string]notification.Preferences
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.True(t, response["preferences"].EmailEnabled)
+	mockService.AssertExpectations(t)
+}
@@ -26,10 +26,13 @@ func NewTransactionHandler(service transaction.Service) *TransactionHandler {
 func (h *TransactionHandler) RegisterRoutes(rg *gin.RouterGroup) {
 	tr := rg.Group("/transactions")
 	tr.POST("", h.CreateTransaction)
+	tr.POST("/import", h.ImportTransactions)
 	tr.GET("", h.ListTransactions)
+	tr.GET("review", h.GetReviewQueue)
 	tr.GET(":id", h.GetTransaction)
 	tr.PUT(":id", h.UpdateTransaction)
 	tr.DELETE(":id", h.DeleteTransaction)
+	tr.POST(":id/review", h.ReviewCategory)
 }
 
 // CreateTransaction handles POST /transactions
@@ -62,6 +65,36 @@ func (h *TransactionHandler) CreateTransaction(c *gin.Context) {
 	c.JSON(http.StatusCreated, resp)
 }
 
+// ImportTransactions handles POST /transactions/import
+func (h *TransactionHandler) ImportTransactions(c *gin.Context) {
+	ctx, span := otel.Tracer("api").Start(c.Request.Context(), "ImportTransactions")
+	defer span.End()
+
+	userID, ok := c.Get("user_id")
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+	uid, ok := userID.(uuid.UUID)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid user id"})
+		return
+	}
+
+	var req transaction.ImportTransactionsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	resp, err := h.Service.ImportTransactions(ctx, uid, &req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
 // GetTransaction handles GET /transactions/:id
 func (h *TransactionHandler) GetTransaction(c *gin.Context) {
 	ctx, span := otel.Tracer("api").Start(c.Request.Context(), "GetTransaction")
@@ -197,3 +230,73 @@ func (h *TransactionHandler) DeleteTransaction(c *gin.Context) {
 	}
 	c.Status(http.StatusNoContent)
 }
+
+// GetReviewQueue handles GET /transactions/review
+func (h *TransactionHandler) GetReviewQueue(c *gin.Context) {
+	ctx, span := otel.Tracer("api").Start(c.Request.Context(), "GetReviewQueue")
+	defer span.End()
+
+	userID, ok := c.Get("user_id")
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+	uid, ok := userID.(uuid.UUID)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid user id"})
+		return
+	}
+	offset, _ := strconv.Atoi(c.DefaultQuery("offset", "0"))
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "50"))
+
+	resp, err := h.Service.GetReviewQueue(ctx, uid, offset, limit)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
+// ReviewCategory handles POST /transactions/:id/review. An empty body accepts the
+// suggested category
+func (h *TransactionHandler) ReviewCategory(c *gin.Context) {
+	ctx, span := otel.Tracer("api").Start(c.Request.Context(), "ReviewCategory")
+	defer span.End()
+
+	userID, ok := c.Get("user_id")
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+	uid, ok := userID.(uuid.UUID)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid user id"})
+		return
+	}
+
+	idStr := c.Param("id")
+	id, err := uuid.Parse(idStr)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid transaction id"})
+		return
+	}
+
+	var req transaction.ReviewCategoryRequest
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+
+	resp, err := h.Service.ReviewCategory(ctx, uid, id, &req)
+	if err != nil {
+		if errors.Is(err, transaction.ErrTransactionNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "transaction not found"})
+			return
+		}
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, resp)
+}
@@ -50,24 +50,86 @@ func (m *mockTransactionService) DeleteTransaction(ctx context.Context, userID,
 	args := m.Called(ctx, userID, transactionID)
 	return args.Error(0)
 }
+func (m *mockTransactionService) ImportTransactions(ctx context.Context, userID uuid.UUID, req *transaction.ImportTransactionsRequest) (*transaction.ImportTransactionsResponse, error) {
+	args := m.Called(ctx, userID, req)
+	if resp, ok := args.Get(0).(*transaction.ImportTransactionsResponse); ok {
+		return resp, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+func (m *mockTransactionService) GetReviewQueue(ctx context.Context, userID uuid.UUID, offset, limit int) ([]transaction.TransactionResponse, error) {
+	args := m.Called(ctx, userID, offset, limit)
+	return args.Get(0).([]transaction.TransactionResponse), args.Error(1)
+}
+func (m *mockTransactionService) ReviewCategory(ctx context.Context, userID, transactionID uuid.UUID, req *transaction.ReviewCategoryRequest) (*transaction.TransactionResponse, error) {
+	args := m.Called(ctx, userID, transactionID, req)
+	if resp, ok := args.Get(0).(*transaction.TransactionResponse); ok {
+		return resp, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+func (m *mockTransactionService) CreateMerchant(ctx context.Context, req *transaction.CreateMerchantRequest) (*transaction.Merchant, error) {
+	args := m.Called(ctx, req)
+	if resp, ok := args.Get(0).(*transaction.Merchant); ok {
+		return resp, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+func (m *mockTransactionService) GetMerchant(ctx context.Context, userID, merchantID uuid.UUID) (*transaction.Merchant, error) {
+	args := m.Called(ctx, userID, merchantID)
+	if resp, ok := args.Get(0).(*transaction.Merchant); ok {
+		return resp, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+func (m *mockTransactionService) GetMerchants(ctx context.Context, userID uuid.UUID, offset, limit int) ([]transaction.Merchant, error) {
+	args := m.Called(ctx, userID, offset, limit)
+	return args.Get(0).([]transaction.Merchant), args.Error(1)
+}
+func (m *mockTransactionService) UpdateMerchant(ctx context.Context, merchantID uuid.UUID, req *transaction.CreateMerchantRequest) (*transaction.Merchant, error) {
+	args := m.Called(ctx, merchantID, req)
+	if resp, ok := args.Get(0).(*transaction.Merchant); ok {
+		return resp, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+func (m *mockTransactionService) DeleteMerchant(ctx context.Context, merchantID uuid.UUID) error {
+	args := m.Called(ctx, merchantID)
+	return args.Error(0)
+}
 
 // Other methods omitted for brevity
-func (m *mockTransactionService) CreateCategory(ctx context.Context, req *transaction.CreateCategoryRequest) (*transaction.Category, error) {
+func (m *mockTransactionService) CreateCategory(ctx context.Context, userID uuid.UUID, req *transaction.CreateCategoryRequest) (*transaction.Category, error) {
+	return nil, nil
+}
+func (m *mockTransactionService) GetCategory(ctx context.Context, userID, categoryID uuid.UUID) (*transaction.Category, error) {
+	return nil, nil
+}
+func (m *mockTransactionService) GetCategories(ctx context.Context, userID uuid.UUID, offset, limit int) ([]transaction.Category, error) {
+	return nil, nil
+}
+func (m *mockTransactionService) GetDefaultCategories(ctx context.Context, locale string) ([]transaction.Category, error) {
 	return nil, nil
 }
-func (m *mockTransactionService) GetCategory(ctx context.Context, categoryID uuid.UUID) (*transaction.Category, error) {
+func (m *mockTransactionService) UpdateCategory(ctx context.Context, userID, categoryID uuid.UUID, req *transaction.CreateCategoryRequest) (*transaction.Category, error) {
 	return nil, nil
 }
-func (m *mockTransactionService) GetCategories(ctx context.Context, offset, limit int) ([]transaction.Category, error) {
+func (m *mockTransactionService) DeleteCategory(ctx context.Context, userID, categoryID uuid.UUID, reassignTo *uuid.UUID) error {
+	return nil
+}
+func (m *mockTransactionService) MergeCategory(ctx context.Context, userID, categoryID uuid.UUID, req *transaction.MergeCategoryRequest) (*transaction.Category, error) {
 	return nil, nil
 }
-func (m *mockTransactionService) GetDefaultCategories(ctx context.Context) ([]transaction.Category, error) {
+func (m *mockTransactionService) GetCategoryTree(ctx context.Context, userID uuid.UUID) ([]transaction.CategoryNode, error) {
 	return nil, nil
 }
-func (m *mockTransactionService) UpdateCategory(ctx context.Context, categoryID uuid.UUID, req *transaction.CreateCategoryRequest) (*transaction.Category, error) {
+func (m *mockTransactionService) MoveCategory(ctx context.Context, userID, categoryID uuid.UUID, req *transaction.MoveCategoryRequest) (*transaction.Category, error) {
 	return nil, nil
 }
-func (m *mockTransactionService) DeleteCategory(ctx context.Context, categoryID uuid.UUID) error {
+func (m *mockTransactionService) HideCategory(ctx context.Context, userID, categoryID uuid.UUID) error {
+	return nil
+}
+func (m *mockTransactionService) UnhideCategory(ctx context.Context, userID, categoryID uuid.UUID) error {
 	return nil
 }
 func (m *mockTransactionService) CreateAccount(ctx context.Context, userID uuid.UUID, req *transaction.CreateAccountRequest) (*transaction.Account, error) {
@@ -119,3 +181,20 @@ func TestTransactionHandler_CreateTransaction(t *testing.T) {
 	r.ServeHTTP(w, req)
 	assert.Equal(t, http.StatusCreated, w.Code)
 }
+
+func TestTransactionHandler_ReviewCategory(t *testing.T) {
+	svc := new(mockTransactionService)
+	r := setupRouterWithTransactionHandler(svc)
+	userID := uuid.MustParse("11111111-1111-1111-1111-111111111111")
+	transactionID := uuid.New()
+	categoryID := uuid.New()
+	resp := &transaction.TransactionResponse{ID: transactionID, UserID: userID, CategoryID: &categoryID}
+	svc.On("ReviewCategory", mock.Anything, userID, transactionID, &transaction.ReviewCategoryRequest{}).Return(resp, nil)
+
+	// An empty body accepts the suggested category
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("POST", "/api/v1/transactions/"+transactionID.String()+"/review", nil)
+	r.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+	svc.AssertExpectations(t)
+}
@@ -387,6 +387,153 @@ func (h *UserHandler) ListUsers(c *gin.Context) {
 	})
 }
 
+// CreateFamily creates a family owned by the user
+func (h *UserHandler) CreateFamily(c *gin.Context) {
+	ctx, span := otel.Tracer("user").Start(c.Request.Context(), "user.CreateFamily")
+	defer span.End()
+
+	userUUID, ok := h.authenticatedUserID(c)
+	if !ok {
+		span.RecordError(user.ErrInvalidToken)
+		span.SetStatus(codes.Error, "user_id not found in context")
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	var req user.CreateFamilyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "invalid request body")
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body", "details": err.Error()})
+		return
+	}
+
+	span.SetAttributes(attribute.String("user.id", userUUID.String()))
+
+	family, err := h.userService.CreateFamily(ctx, userUUID, &req)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		h.logger.Error("Failed to create family", zap.Error(err), zap.String("user_id", userUUID.String()))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create family"})
+		return
+	}
+
+	span.SetAttributes(attribute.String("family.id", family.ID.String()))
+	span.SetStatus(codes.Ok, "family created successfully")
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message": "Family created successfully",
+		"family":  family,
+	})
+}
+
+// JoinFamily adds the user to the family of an invite code
+func (h *UserHandler) JoinFamily(c *gin.Context) {
+	ctx, span := otel.Tracer("user").Start(c.Request.Context(), "user.JoinFamily")
+	defer span.End()
+
+	userUUID, ok := h.authenticatedUserID(c)
+	if !ok {
+		span.RecordError(user.ErrInvalidToken)
+		span.SetStatus(codes.Error, "user_id not found in context")
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	var req user.JoinFamilyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "invalid request body")
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body", "details": err.Error()})
+		return
+	}
+
+	span.SetAttributes(attribute.String("user.id", userUUID.String()))
+
+	family, err := h.userService.JoinFamily(ctx, userUUID, &req)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+
+		switch err {
+		case user.ErrInvalidInviteCode:
+			c.JSON(http.StatusNotFound, gin.H{"error": "Invalid invite code"})
+		case user.ErrAlreadyFamilyMember:
+			c.JSON(http.StatusConflict, gin.H{"error": "Already a member of this family"})
+		default:
+			h.logger.Error("Failed to join family", zap.Error(err), zap.String("user_id", userUUID.String()))
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to join family"})
+		}
+		return
+	}
+
+	span.SetAttributes(attribute.String("family.id", family.ID.String()))
+	span.SetStatus(codes.Ok, "family joined successfully")
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Family joined successfully",
+		"family":  family,
+	})
+}
+
+// LeaveFamily removes the user from a family
+func (h *UserHandler) LeaveFamily(c *gin.Context) {
+	ctx, span := otel.Tracer("user").Start(c.Request.Context(), "user.LeaveFamily")
+	defer span.End()
+
+	userUUID, ok := h.authenticatedUserID(c)
+	if !ok {
+		span.RecordError(user.ErrInvalidToken)
+		span.SetStatus(codes.Error, "user_id not found in context")
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	familyID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "invalid family id")
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid family ID"})
+		return
+	}
+
+	span.SetAttributes(
+		attribute.String("user.id", userUUID.String()),
+		attribute.String("family.id", familyID.String()),
+	)
+
+	if err := h.userService.LeaveFamily(ctx, userUUID, familyID); err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+
+		switch err {
+		case user.ErrNotFamilyMember:
+			c.JSON(http.StatusNotFound, gin.H{"error": "Not a member of this family"})
+		case user.ErrFamilyOwnerCannotLeave:
+			c.JSON(http.StatusConflict, gin.H{"error": "The family owner cannot leave the family"})
+		default:
+			h.logger.Error("Failed to leave family", zap.Error(err), zap.String("user_id", userUUID.String()))
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to leave family"})
+		}
+		return
+	}
+
+	span.SetStatus(codes.Ok, "family left successfully")
+
+	c.JSON(http.StatusOK, gin.H{"message": "Family left successfully"})
+}
+
+// authenticatedUserID returns the ID of the user the authentication middleware set
+func (h *UserHandler) authenticatedUserID(c *gin.Context) (uuid.UUID, bool) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		return uuid.Nil, false
+	}
+	userUUID, ok := userID.(uuid.UUID)
+	return userUUID, ok
+}
+
 // RegisterRoutes registers user-related routes
 func (h *UserHandler) RegisterRoutes(rg *gin.RouterGroup) {
 	rg.POST("/users/register", h.Register)
@@ -395,4 +542,7 @@ func (h *UserHandler) RegisterRoutes(rg *gin.RouterGroup) {
 	rg.PUT("/users/profile", h.UpdateProfile)
 	rg.POST("/users/refresh-token", h.RefreshToken)
 	rg.POST("/users/logout", h.Logout)
+	rg.POST("/families", h.CreateFamily)
+	rg.POST("/families/join", h.JoinFamily)
+	rg.DELETE("/families/:id/membership", h.LeaveFamily)
 }
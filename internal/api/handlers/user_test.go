@@ -75,6 +75,27 @@ func (m *MockUserService) ValidateToken(ctx context.Context, tokenString string)
 	return args.Get(0).(*user.Claims), args.Error(1)
 }
 
+func (m *MockUserService) CreateFamily(ctx context.Context, userID uuid.UUID, req *user.CreateFamilyRequest) (*user.Family, error) {
+	args := m.Called(ctx, userID, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*user.Family), args.Error(1)
+}
+
+func (m *MockUserService) JoinFamily(ctx context.Context, userID uuid.UUID, req *user.JoinFamilyRequest) (*user.Family, error) {
+	args := m.Called(ctx, userID, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*user.Family), args.Error(1)
+}
+
+func (m *MockUserService) LeaveFamily(ctx context.Context, userID, familyID uuid.UUID) error {
+	args := m.Called(ctx, userID, familyID)
+	return args.Error(0)
+}
+
 func setupTestRouter(handler *UserHandler) *gin.Engine {
 	gin.SetMode(gin.TestMode)
 	router := gin.New()
@@ -109,6 +130,19 @@ func setupTestRouter(handler *UserHandler) *gin.Engine {
 				protected.PUT("/profile", handler.UpdateProfile)
 			}
 		}
+
+		families := api.Group("/families")
+		families.Use(func(c *gin.Context) {
+			if id, err := uuid.Parse(c.GetHeader("X-User-ID")); err == nil {
+				c.Set("user_id", id)
+			}
+			c.Next()
+		})
+		{
+			families.POST("", handler.CreateFamily)
+			families.POST("/join", handler.JoinFamily)
+			families.DELETE(":id/membership", handler.LeaveFamily)
+		}
 	}
 
 	return router
@@ -483,3 +517,125 @@ func TestUserHandler_UpdateProfile(t *testing.T) {
 		})
 	}
 }
+
+func TestUserHandler_JoinFamily(t *testing.T) {
+	userID := uuid.New()
+	family := &user.Family{ID: uuid.New(), Name: "Doe household"}
+
+	tests := []struct {
+		name           string
+		requestBody    interface{}
+		mockSetup      func(*MockUserService)
+		expectedStatus int
+	}{
+		{
+			name:        "joins the family",
+			requestBody: map[string]string{"invite_code": "0123456789abcdef"},
+			mockSetup: func(service *MockUserService) {
+				service.On("JoinFamily", mock.Anything, userID, &user.JoinFamilyRequest{InviteCode: "0123456789abcdef"}).Return(family, nil)
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:        "invalid invite code",
+			requestBody: map[string]string{"invite_code": "unknown"},
+			mockSetup: func(service *MockUserService) {
+				service.On("JoinFamily", mock.Anything, userID, mock.Anything).Return(nil, user.ErrInvalidInviteCode)
+			},
+			expectedStatus: http.StatusNotFound,
+		},
+		{
+			name:        "already a member",
+			requestBody: map[string]string{"invite_code": "0123456789abcdef"},
+			mockSetup: func(service *MockUserService) {
+				service.On("JoinFamily", mock.Anything, userID, mock.Anything).Return(nil, user.ErrAlreadyFamilyMember)
+			},
+			expectedStatus: http.StatusConflict,
+		},
+		{
+			name:           "missing invite code",
+			requestBody:    map[string]string{},
+			mockSetup:      func(service *MockUserService) {},
+			expectedStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := new(MockUserService)
+			tt.mockSetup(mockService)
+			router := setupTestRouter(NewUserHandler(mockService, zap.NewNop()))
+
+			body, _ := json.Marshal(tt.requestBody)
+			req, _ := http.NewRequest("POST", "/api/v1/families/join", bytes.NewBuffer(body))
+			req.Header.Set("Content-Type", "application/json")
+			req.Header.Set("X-User-ID", userID.String())
+			w := httptest.NewRecorder()
+
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			mockService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestUserHandler_LeaveFamily(t *testing.T) {
+	userID := uuid.New()
+	familyID := uuid.New()
+
+	tests := []struct {
+		name           string
+		familyID       string
+		mockSetup      func(*MockUserService)
+		expectedStatus int
+	}{
+		{
+			name:     "leaves the family",
+			familyID: familyID.String(),
+			mockSetup: func(service *MockUserService) {
+				service.On("LeaveFamily", mock.Anything, userID, familyID).Return(nil)
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:     "owner cannot leave",
+			familyID: familyID.String(),
+			mockSetup: func(service *MockUserService) {
+				service.On("LeaveFamily", mock.Anything, userID, familyID).Return(user.ErrFamilyOwnerCannotLeave)
+			},
+			expectedStatus: http.StatusConflict,
+		},
+		{
+			name:     "not a member",
+			familyID: familyID.String(),
+			mockSetup: func(service *MockUserService) {
+				service.On("LeaveFamily", mock.Anything, userID, familyID).Return(user.ErrNotFamilyMember)
+			},
+			expectedStatus: http.StatusNotFound,
+		},
+		{
+			name:           "invalid family id",
+			familyID:       "not-a-uuid",
+			mockSetup:      func(service *MockUserService) {},
+			expectedStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := new(MockUserService)
+			tt.mockSetup(mockService)
+			router := setupTestRouter(NewUserHandler(mockService, zap.NewNop()))
+
+			req, _ := http.NewRequest("DELETE", "/api/v1/families/"+tt.familyID+"/membership", nil)
+			req.Header.Set("X-User-ID", userID.String())
+			w := httptest.NewRecorder()
+
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			mockService.AssertExpectations(t)
+		})
+	}
+}
@@ -57,6 +57,26 @@ func AuthMiddleware(userService user.Service) gin.HandlerFunc {
 	}
 }
 
+// RequireRole creates middleware allowing only users with one of the given roles. It must
+// run after AuthMiddleware
+func RequireRole(roles ...user.UserRole) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		role, _ := GetUserRoleFromContext(c)
+		for _, allowed := range roles {
+			if role == allowed {
+				c.Next()
+				return
+			}
+		}
+
+		c.JSON(http.StatusForbidden, gin.H{
+			"error":   "forbidden",
+			"message": "Insufficient permissions",
+		})
+		c.Abort()
+	}
+}
+
 // GetUserIDFromContext extracts user ID from gin context
 func GetUserIDFromContext(c *gin.Context) (uuid.UUID, bool) {
 	userIDInterface, exists := c.Get("user_id")
@@ -1,6 +1,8 @@
 package server
 
 import (
+	"context"
+
 	"github.com/gin-gonic/gin"
 	"go.uber.org/zap"
 
@@ -9,6 +11,7 @@ import (
 	"fiscaflow/internal/config"
 	"fiscaflow/internal/domain/analytics"
 	"fiscaflow/internal/domain/budget"
+	"fiscaflow/internal/domain/notification"
 	"fiscaflow/internal/domain/transaction"
 	"fiscaflow/internal/domain/user"
 	"fiscaflow/internal/infrastructure/database"
@@ -16,18 +19,21 @@ import (
 
 // Server represents the API server
 type Server struct {
-	config             *config.Config
-	logger             *zap.Logger
-	userService        user.Service
-	userHandler        *handlers.UserHandler
-	transactionService transaction.Service
-	transactionHandler *handlers.TransactionHandler
-	categoryHandler    *handlers.CategoryHandler
-	accountHandler     *handlers.AccountHandler
-	budgetService      budget.Service
-	budgetHandler      *handlers.BudgetHandler
-	analyticsService   analytics.Service
-	analyticsHandler   *handlers.AnalyticsHandler
+	config              *config.Config
+	logger              *zap.Logger
+	userService         user.Service
+	userHandler         *handlers.UserHandler
+	transactionService  transaction.Service
+	transactionHandler  *handlers.TransactionHandler
+	categoryHandler     *handlers.CategoryHandler
+	accountHandler      *handlers.AccountHandler
+	merchantHandler     *handlers.MerchantHandler
+	budgetService       budget.Service
+	budgetHandler       *handlers.BudgetHandler
+	goalHandler         *handlers.GoalHandler
+	analyticsService    analytics.Service
+	analyticsHandler    *handlers.AnalyticsHandler
+	notificationHandler *handlers.NotificationHandler
 }
 
 // New creates a new API server instance
@@ -55,39 +61,77 @@ func New(cfg *config.Config, logger *zap.Logger) *Server {
 		logger.Fatal("Failed to run database migrations", zap.Error(err))
 	}
 
+	// Seed default categories
+	if err := db.SeedDefaultCategories(context.Background()); err != nil {
+		logger.Fatal("Failed to seed default categories", zap.Error(err))
+	}
+
 	// Initialize repositories
 	userRepo := user.NewRepository(db.GetDB())
 	transactionRepo := transaction.NewRepository(db.GetDB())
 	budgetRepo := budget.NewRepository(db.GetDB())
 	analyticsRepo := analytics.NewRepository(db.GetDB())
+	notificationRepo := notification.NewRepository(db.GetDB())
+
+	// Initialize notification channels
+	channels := []notification.Channel{
+		notification.NewInboxChannel(notificationRepo),
+		notification.NewWebhookChannel(cfg.Notification.WebhookTimeout),
+	}
+	if cfg.Notification.SMTPHost != "" {
+		channels = append(channels, notification.NewEmailChannel(notification.SMTPConfig{
+			Host:     cfg.Notification.SMTPHost,
+			Port:     cfg.Notification.SMTPPort,
+			Username: cfg.Notification.SMTPUsername,
+			Password: cfg.Notification.SMTPPassword,
+			From:     cfg.Notification.SMTPFrom,
+		}))
+	}
 
 	// Initialize services
 	userService := user.NewService(userRepo, cfg.JWT.Secret)
-	transactionService := transaction.NewService(transactionRepo)
+	notificationService := notification.NewService(notificationRepo, channels...)
 	budgetService := budget.NewService(budgetRepo)
+	alertMonitor := budget.NewAlertMonitor(budgetService, notificationService, logger)
 	analyticsService := analytics.NewService(analyticsRepo)
+	transactionService := transaction.NewService(transactionRepo, transaction.CategorizationConfig{
+		Categorizer: analyticsService,
+		Threshold:   cfg.Analytics.AutoCategorizationThreshold,
+	}, alertMonitor)
+
+	// Start background jobs
+	alertMonitor.Start(context.Background(), cfg.Notification.AlertWorkers)
+	if cfg.Analytics.ModelTrainingInterval > 0 {
+		analytics.NewModelTrainer(analyticsService, cfg.Analytics.ModelTrainingInterval).Start(context.Background())
+	}
 
 	// Initialize handlers
 	userHandler := handlers.NewUserHandler(userService, logger)
 	transactionHandler := handlers.NewTransactionHandler(transactionService)
 	categoryHandler := handlers.NewCategoryHandler(transactionService)
 	accountHandler := handlers.NewAccountHandler(transactionService)
+	merchantHandler := handlers.NewMerchantHandler(transactionService)
 	budgetHandler := handlers.NewBudgetHandler(budgetService)
+	goalHandler := handlers.NewGoalHandler(budgetService)
 	analyticsHandler := handlers.NewAnalyticsHandler(analyticsService)
+	notificationHandler := handlers.NewNotificationHandler(notificationService)
 
 	return &Server{
-		config:             cfg,
-		logger:             logger,
-		userService:        userService,
-		userHandler:        userHandler,
-		transactionService: transactionService,
-		transactionHandler: transactionHandler,
-		categoryHandler:    categoryHandler,
-		accountHandler:     accountHandler,
-		budgetService:      budgetService,
-		budgetHandler:      budgetHandler,
-		analyticsService:   analyticsService,
-		analyticsHandler:   analyticsHandler,
+		config:              cfg,
+		logger:              logger,
+		userService:         userService,
+		userHandler:         userHandler,
+		transactionService:  transactionService,
+		transactionHandler:  transactionHandler,
+		categoryHandler:     categoryHandler,
+		accountHandler:      accountHandler,
+		merchantHandler:     merchantHandler,
+		budgetService:       budgetService,
+		budgetHandler:       budgetHandler,
+		goalHandler:         goalHandler,
+		analyticsService:    analyticsService,
+		analyticsHandler:    analyticsHandler,
+		notificationHandler: notificationHandler,
 	}
 }
 
@@ -113,15 +157,27 @@ func (s *Server) SetupRoutes(router *gin.Engine) {
 		}
 	}
 
+	// Family routes (protected)
+	families := v1.Group("/families")
+	families.Use(middleware.AuthMiddleware(s.userService))
+	{
+		families.POST("", s.userHandler.CreateFamily)
+		families.POST("/join", s.userHandler.JoinFamily)
+		families.DELETE(":id/membership", s.userHandler.LeaveFamily)
+	}
+
 	// Transaction routes (protected)
 	transactions := v1.Group("/transactions")
 	transactions.Use(middleware.AuthMiddleware(s.userService))
 	{
 		transactions.POST("", s.transactionHandler.CreateTransaction)
+		transactions.POST("/import", s.transactionHandler.ImportTransactions)
 		transactions.GET("", s.transactionHandler.ListTransactions)
+		transactions.GET("review", s.transactionHandler.GetReviewQueue)
 		transactions.GET(":id", s.transactionHandler.GetTransaction)
 		transactions.PUT(":id", s.transactionHandler.UpdateTransaction)
 		transactions.DELETE(":id", s.transactionHandler.DeleteTransaction)
+		transactions.POST(":id/review", s.transactionHandler.ReviewCategory)
 	}
 
 	// Category routes
@@ -136,9 +192,14 @@ func (s *Server) SetupRoutes(router *gin.Engine) {
 		{
 			protectedCategories.POST("", s.categoryHandler.CreateCategory)
 			protectedCategories.GET("", s.categoryHandler.ListCategories)
+			protectedCategories.GET("/tree", s.categoryHandler.GetCategoryTree)
 			protectedCategories.GET(":id", s.categoryHandler.GetCategory)
 			protectedCategories.PUT(":id", s.categoryHandler.UpdateCategory)
 			protectedCategories.DELETE(":id", s.categoryHandler.DeleteCategory)
+			protectedCategories.PUT(":id/parent", s.categoryHandler.MoveCategory)
+			protectedCategories.POST(":id/merge", s.categoryHandler.MergeCategory)
+			protectedCategories.POST(":id/hide", s.categoryHandler.HideCategory)
+			protectedCategories.DELETE(":id/hide", s.categoryHandler.UnhideCategory)
 		}
 	}
 
@@ -153,6 +214,23 @@ func (s *Server) SetupRoutes(router *gin.Engine) {
 		accounts.DELETE(":id", s.accountHandler.DeleteAccount)
 	}
 
+	// Merchant routes (protected)
+	merchants := v1.Group("/merchants")
+	merchants.Use(middleware.AuthMiddleware(s.userService))
+	{
+		merchants.GET("", s.merchantHandler.ListMerchants)
+		merchants.GET(":id", s.merchantHandler.GetMerchant)
+
+		// Shared merchants apply to every user's transactions (admin only)
+		sharedMerchants := merchants.Group("")
+		sharedMerchants.Use(middleware.RequireRole(user.UserRoleAdmin))
+		{
+			sharedMerchants.POST("", s.merchantHandler.CreateMerchant)
+			sharedMerchants.PUT(":id", s.merchantHandler.UpdateMerchant)
+			sharedMerchants.DELETE(":id", s.merchantHandler.DeleteMerchant)
+		}
+	}
+
 	// Budget routes (protected)
 	budgets := v1.Group("/budgets")
 	budgets.Use(middleware.AuthMiddleware(s.userService))
@@ -162,7 +240,26 @@ func (s *Server) SetupRoutes(router *gin.Engine) {
 		budgets.GET(":id", s.budgetHandler.GetBudget)
 		budgets.PUT(":id", s.budgetHandler.UpdateBudget)
 		budgets.DELETE(":id", s.budgetHandler.DeleteBudget)
+		budgets.GET("suggest", s.budgetHandler.SuggestBudget)
+		budgets.POST(":id/copy", s.budgetHandler.CopyBudget)
 		budgets.GET(":id/summary", s.budgetHandler.GetBudgetSummary)
+		budgets.POST(":id/recalculate", s.budgetHandler.RecalculateBudget)
+		budgets.GET(":id/periods", s.budgetHandler.GetBudgetPeriods)
+		budgets.GET(":id/forecast", s.budgetHandler.GetBudgetForecast)
+		budgets.GET(":id/report", s.budgetHandler.GetBudgetReport)
+
+		// Budget templates
+		budgets.POST(":id/template", s.budgetHandler.SaveAsTemplate)
+		budgets.GET("templates", s.budgetHandler.ListTemplates)
+		budgets.DELETE("templates/:templateId", s.budgetHandler.DeleteTemplate)
+		budgets.POST("templates/:templateId/budgets", s.budgetHandler.CreateBudgetFromTemplate)
+
+		// Envelope budgeting
+		budgets.GET(":id/envelopes", s.budgetHandler.GetEnvelopes)
+		budgets.GET(":id/envelopes/transfers", s.budgetHandler.GetEnvelopeTransfers)
+		budgets.POST(":id/envelopes/assign", s.budgetHandler.AssignToEnvelope)
+		budgets.POST(":id/envelopes/move", s.budgetHandler.MoveBetweenEnvelopes)
+		budgets.POST(":id/envelopes/:categoryId/cover", s.budgetHandler.CoverOverspending)
 
 		// Budget categories
 		budgets.POST(":id/categories", s.budgetHandler.AddBudgetCategory)
@@ -172,23 +269,66 @@ func (s *Server) SetupRoutes(router *gin.Engine) {
 		budgets.DELETE(":id/categories/:categoryId", s.budgetHandler.DeleteBudgetCategory)
 	}
 
+	// Savings goal routes (protected)
+	goals := v1.Group("/goals")
+	goals.Use(middleware.AuthMiddleware(s.userService))
+	{
+		goals.POST("", s.goalHandler.CreateGoal)
+		goals.GET("", s.goalHandler.ListGoals)
+		goals.GET(":id", s.goalHandler.GetGoal)
+		goals.PUT(":id", s.goalHandler.UpdateGoal)
+		goals.DELETE(":id", s.goalHandler.DeleteGoal)
+	}
+
 	// Analytics routes (protected)
 	analytics := v1.Group("/analytics")
 	analytics.Use(middleware.AuthMiddleware(s.userService))
 	{
 		// Categorization
 		analytics.POST("/categorize", s.analyticsHandler.CategorizeTransaction)
+		analytics.POST("/categorize/batch", s.analyticsHandler.CategorizeTransactions)
 
 		// Categorization rules
 		analytics.POST("/categorization-rules", s.analyticsHandler.CreateCategorizationRule)
 		analytics.GET("/categorization-rules", s.analyticsHandler.ListCategorizationRules)
+		analytics.GET("/categorization-rules/suggestions", s.analyticsHandler.SuggestCategorizationRules)
+		analytics.POST("/categorization-rules/test", s.analyticsHandler.TestCategorizationRule)
 		analytics.GET("/categorization-rules/:id", s.analyticsHandler.GetCategorizationRule)
 		analytics.PUT("/categorization-rules/:id", s.analyticsHandler.UpdateCategorizationRule)
 		analytics.DELETE("/categorization-rules/:id", s.analyticsHandler.DeleteCategorizationRule)
+		analytics.POST("/categorization-rules/:id/apply", s.analyticsHandler.ApplyCategorizationRule)
+
+		// Rule applications
+		analytics.GET("/rule-applications", s.analyticsHandler.ListRuleApplications)
+		analytics.POST("/rule-applications/:id/undo", s.analyticsHandler.UndoRuleApplication)
+
+		// Categorization models (admin only)
+		models := analytics.Group("/categorization-models")
+		models.Use(middleware.RequireRole(user.UserRoleAdmin))
+		{
+			models.POST("/train", s.analyticsHandler.TrainCategorizationModel)
+			models.GET("", s.analyticsHandler.ListCategorizationModels)
+			models.GET("/compare", s.analyticsHandler.CompareCategorizationModels)
+			models.POST("/:id/activate", s.analyticsHandler.ActivateCategorizationModel)
+		}
 
 		// Spending analysis
 		analytics.POST("/spending", s.analyticsHandler.AnalyzeSpending)
 		analytics.GET("/spending/insights", s.analyticsHandler.GetSpendingInsights)
+
+		// Cash flow
+		analytics.GET("/cashflow/forecast", s.analyticsHandler.GetCashFlowForecast)
+	}
+
+	// Notification routes (protected)
+	notifications := v1.Group("/notifications")
+	notifications.Use(middleware.AuthMiddleware(s.userService))
+	{
+		notifications.GET("", s.notificationHandler.ListNotifications)
+		notifications.POST("read", s.notificationHandler.MarkAllAsRead)
+		notifications.POST(":id/read", s.notificationHandler.MarkAsRead)
+		notifications.GET("preferences", s.notificationHandler.GetPreferences)
+		notifications.PUT("preferences", s.notificationHandler.UpdatePreferences)
 	}
 
 	s.logger.Info("API routes configured")
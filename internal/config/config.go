@@ -19,6 +19,8 @@ type Config struct {
 	Elasticsearch ElasticsearchConfig
 	MinIO         MinIOConfig
 	RabbitMQ      RabbitMQConfig
+	Notification  NotificationConfig
+	Analytics     AnalyticsConfig
 }
 
 // ServerConfig holds server configuration
@@ -87,6 +89,23 @@ type RabbitMQConfig struct {
 	Password string
 }
 
+// NotificationConfig holds notification delivery configuration
+type NotificationConfig struct {
+	SMTPHost       string // Email notifications are disabled when empty
+	SMTPPort       int
+	SMTPUsername   string
+	SMTPPassword   string
+	SMTPFrom       string
+	WebhookTimeout time.Duration
+	AlertWorkers   int // Budget alert checks run at the same time
+}
+
+// AnalyticsConfig holds analytics configuration
+type AnalyticsConfig struct {
+	ModelTrainingInterval       time.Duration // Scheduled model training is disabled when zero
+	AutoCategorizationThreshold float64       // Confidence above which new transactions are categorized
+}
+
 // Load loads configuration from environment variables
 func Load() (*Config, error) {
 	// Load .env file if it exists (optional, error is ignored)
@@ -143,6 +162,19 @@ func Load() (*Config, error) {
 			Username: getEnv("RABBITMQ_USERNAME", "guest"),
 			Password: getEnv("RABBITMQ_PASSWORD", "guest"),
 		},
+		Notification: NotificationConfig{
+			SMTPHost:       getEnv("SMTP_HOST", ""),
+			SMTPPort:       getEnvAsInt("SMTP_PORT", 587),
+			SMTPUsername:   getEnv("SMTP_USERNAME", ""),
+			SMTPPassword:   getEnv("SMTP_PASSWORD", ""),
+			SMTPFrom:       getEnv("SMTP_FROM", "notifications@fiscaflow.local"),
+			WebhookTimeout: getEnvAsDuration("NOTIFICATION_WEBHOOK_TIMEOUT", 10*time.Second),
+			AlertWorkers:   getEnvAsInt("NOTIFICATION_ALERT_WORKERS", 4),
+		},
+		Analytics: AnalyticsConfig{
+			ModelTrainingInterval:       getEnvAsDuration("ANALYTICS_MODEL_TRAINING_INTERVAL", 0),
+			AutoCategorizationThreshold: getEnvAsFloat("ANALYTICS_AUTO_CATEGORIZATION_THRESHOLD", 0.8),
+		},
 	}
 
 	return config, nil
@@ -176,6 +208,15 @@ func getEnvAsInt(key string, defaultValue int) int {
 	return defaultValue
 }
 
+func getEnvAsFloat(key string, defaultValue float64) float64 {
+	if value := os.Getenv(key); value != "" {
+		if floatValue, err := strconv.ParseFloat(value, 64); err == nil {
+			return floatValue
+		}
+	}
+	return defaultValue
+}
+
 func getEnvAsBool(key string, defaultValue bool) bool {
 	if value := os.Getenv(key); value != "" {
 		if boolValue, err := strconv.ParseBool(value); err == nil {
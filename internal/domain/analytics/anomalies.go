@@ -0,0 +1,379 @@
+package analytics
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	// anomalyHistoryMonths is how far before a period transactions are compared against
+	anomalyHistoryMonths = 6
+
+	// minAnomalyHistory is the number of earlier expenses needed before amounts in a
+	// category or at a merchant are considered unusual
+	minAnomalyHistory = 5
+
+	// anomalyScoreThreshold is the robust z-score above which an amount is unusual, and
+	// highAnomalyScore the score above which it is reported with high severity
+	anomalyScoreThreshold = 3.5
+	highAnomalyScore      = 6.0
+
+	// minRelativeSpread is the smallest spread assumed around the median, as a fraction
+	// of it, so that amounts that barely vary don't make small differences stand out
+	minRelativeSpread = 0.1
+
+	// categorySpikeRatio is how many times its trailing average spending in a category
+	// has to be to count as a spike, and highCategorySpikeRatio when it is severe
+	categorySpikeRatio     = 1.5
+	highCategorySpikeRatio = 2.0
+
+	// maxAnomalyInsights limits the number of anomalies reported for a period
+	maxAnomalyInsights = 10
+)
+
+// Kinds of anomalies, reported as anomaly_type in the data of an insight
+const (
+	AnomalyLargeTransaction  = "large_transaction"
+	AnomalyUnusualMerchant   = "unusual_merchant"
+	AnomalyCategorySpike     = "category_spike"
+	AnomalyFirstTimeMerchant = "first_time_merchant"
+)
+
+// detectAnomalies compares the expenses of a period with those of the months before it
+// and reports unusually large transactions, unusual amounts at a merchant, spending spikes
+// in a category and first purchases at a merchant
+func (s *service) detectAnomalies(ctx context.Context, userID uuid.UUID, periodStart, periodEnd time.Time, transactions []Transaction, categories map[uuid.UUID]*Category) ([]SpendingInsight, error) {
+	historyStart := periodStart.AddDate(0, -anomalyHistoryMonths, 0)
+	history, err := s.repo.GetTransactionsByPeriod(ctx, userID, historyStart, periodStart.Add(-time.Nanosecond))
+	if err != nil {
+		return nil, err
+	}
+
+	categoryAmounts := make(map[uuid.UUID][]float64)
+	categoryTotals := make(map[uuid.UUID]float64)
+	merchantAmounts := make(map[string][]float64)
+	knownMerchants := make(map[string]bool)
+	var firstDate time.Time
+	for _, tx := range history {
+		if key := merchantKey(tx); key != "" {
+			knownMerchants[key] = true
+		}
+		if !isExpense(tx) {
+			continue
+		}
+		if firstDate.IsZero() || tx.TransactionDate.Before(firstDate) {
+			firstDate = tx.TransactionDate
+		}
+
+		amount := math.Abs(tx.Amount)
+		if tx.CategoryID != nil {
+			categoryAmounts[*tx.CategoryID] = append(categoryAmounts[*tx.CategoryID], amount)
+			categoryTotals[*tx.CategoryID] += amount
+		}
+		if key := merchantKey(tx); key != "" {
+			merchantAmounts[key] = append(merchantAmounts[key], amount)
+		}
+	}
+
+	// Expenses are checked in the order they were made, so that a new merchant is
+	// reported with its first purchase
+	expenses := make([]Transaction, 0, len(transactions))
+	for _, tx := range transactions {
+		if isExpense(tx) {
+			expenses = append(expenses, tx)
+		}
+	}
+	sort.SliceStable(expenses, func(i, j int) bool {
+		return expenses[i].TransactionDate.Before(expenses[j].TransactionDate)
+	})
+
+	var anomalies []SpendingInsight
+	periodTotals := make(map[uuid.UUID]float64)
+	newMerchants := make(map[string]bool)
+	var firstVisits []Transaction
+	for _, tx := range expenses {
+		amount := math.Abs(tx.Amount)
+		key := merchantKey(tx)
+
+		flagged := false
+		if tx.CategoryID != nil {
+			periodTotals[*tx.CategoryID] += amount
+
+			amounts := categoryAmounts[*tx.CategoryID]
+			if score, median, ok := robustScore(amounts, amount); ok {
+				categoryName := s.categoryName(ctx, *tx.CategoryID, categories)
+				anomalies = append(anomalies, scoredAnomaly(
+					transactionAnomaly(tx, AnomalyLargeTransaction, "Unusually Large Transaction",
+						fmt.Sprintf("%s for %.2f is unusually large for %s, where you usually spend %.2f", transactionLabel(tx), amount, categoryName, median)),
+					score, median, len(amounts)))
+				flagged = true
+			}
+		}
+
+		if key == "" {
+			continue
+		}
+		if !flagged {
+			amounts := merchantAmounts[key]
+			if score, median, ok := robustScore(amounts, amount); ok {
+				anomalies = append(anomalies, scoredAnomaly(
+					transactionAnomaly(tx, AnomalyUnusualMerchant, "Unusual Merchant Charge",
+						fmt.Sprintf("%.2f at %s is much more than the %.2f you usually spend there", amount, tx.Merchant, median)),
+					score, median, len(amounts)))
+			}
+		}
+
+		// Without any history every merchant would be new
+		if len(history) > 0 && !knownMerchants[key] && !newMerchants[key] {
+			newMerchants[key] = true
+			firstVisits = append(firstVisits, tx)
+		}
+	}
+
+	// Merchants missing from the recent history may have been visited long before
+	firstVisits, err = s.neverVisited(ctx, userID, firstVisits, historyStart)
+	if err != nil {
+		return nil, err
+	}
+	for _, tx := range firstVisits {
+		anomalies = append(anomalies, transactionAnomaly(tx, AnomalyFirstTimeMerchant, "New Merchant",
+			fmt.Sprintf("First purchase at %s for %.2f", tx.Merchant, math.Abs(tx.Amount))))
+	}
+
+	anomalies = append(anomalies, s.categorySpikes(ctx, periodTotals, categoryTotals, categoryAmounts, firstDate, historyStart, periodStart, periodEnd, categories)...)
+
+	// The most severe anomalies are reported first
+	sort.SliceStable(anomalies, func(i, j int) bool {
+		return severityRank(anomalies[i].Severity) > severityRank(anomalies[j].Severity)
+	})
+	if len(anomalies) > maxAnomalyInsights {
+		anomalies = anomalies[:maxAnomalyInsights]
+	}
+	return anomalies, nil
+}
+
+// neverVisited filters transactions down to those at merchants the user has no transactions
+// with before a date
+func (s *service) neverVisited(ctx context.Context, userID uuid.UUID, transactions []Transaction, before time.Time) ([]Transaction, error) {
+	if len(transactions) == 0 {
+		return transactions, nil
+	}
+
+	var merchantIDs []uuid.UUID
+	var merchants []string
+	for _, tx := range transactions {
+		if tx.MerchantID != nil {
+			merchantIDs = append(merchantIDs, *tx.MerchantID)
+		} else {
+			merchants = append(merchants, tx.Merchant)
+		}
+	}
+
+	previous, err := s.repo.GetMerchantTransactionsBefore(ctx, userID, merchantIDs, merchants, before)
+	if err != nil {
+		return nil, err
+	}
+	visited := make(map[string]bool, len(previous))
+	for _, tx := range previous {
+		visited[merchantKey(tx)] = true
+	}
+
+	result := transactions[:0]
+	for _, tx := range transactions {
+		if !visited[merchantKey(tx)] {
+			result = append(result, tx)
+		}
+	}
+	return result, nil
+}
+
+// categorySpikes reports categories whose spending in a period is well above their daily
+// average over the history, scaled to the length of the period. Only days since the first
+// expense in the history count, so that new users aren't compared with empty months
+func (s *service) categorySpikes(ctx context.Context, periodTotals, historyTotals map[uuid.UUID]float64, historyAmounts map[uuid.UUID][]float64, firstDate, historyStart, periodStart, periodEnd time.Time, categories map[uuid.UUID]*Category) []SpendingInsight {
+	if firstDate.After(historyStart) {
+		historyStart = calendarDate(firstDate, historyStart.Location())
+	}
+	historyDays := periodStart.Sub(historyStart).Hours() / 24
+	periodDays := periodEnd.Sub(periodStart).Hours()/24 + 1
+	if historyDays < periodDays {
+		return nil
+	}
+
+	categoryIDs := make([]uuid.UUID, 0, len(periodTotals))
+	for categoryID := range periodTotals {
+		categoryIDs = append(categoryIDs, categoryID)
+	}
+	sort.Slice(categoryIDs, func(i, j int) bool {
+		return categoryIDs[i].String() < categoryIDs[j].String()
+	})
+
+	var spikes []SpendingInsight
+	for _, categoryID := range categoryIDs {
+		if len(historyAmounts[categoryID]) < minAnomalyHistory {
+			continue
+		}
+
+		expected := historyTotals[categoryID] / historyDays * periodDays
+		current := periodTotals[categoryID]
+		ratio := current / expected
+		if ratio < categorySpikeRatio {
+			continue
+		}
+
+		severity := "medium"
+		if ratio >= highCategorySpikeRatio {
+			severity = "high"
+		}
+
+		categoryName := s.categoryName(ctx, categoryID, categories)
+		spikes = append(spikes, SpendingInsight{
+			Type:        "anomaly",
+			Title:       "Category Spending Spike",
+			Description: fmt.Sprintf("Spending on %s is %.1fx your recent average (%.2f compared to %.2f)", categoryName, ratio, current, expected),
+			Severity:    severity,
+			Data: map[string]interface{}{
+				"anomaly_type":    AnomalyCategorySpike,
+				"category_id":     categoryID.String(),
+				"category_name":   categoryName,
+				"amount":          roundAmount(current),
+				"expected_amount": roundAmount(expected),
+				"ratio":           roundAmount(ratio),
+				"history_days":    int(historyDays),
+			},
+			CreatedAt: time.Now(),
+		})
+	}
+
+	// Larger spikes first
+	sort.SliceStable(spikes, func(i, j int) bool {
+		return spikes[i].Data["ratio"].(float64) > spikes[j].Data["ratio"].(float64)
+	})
+	return spikes
+}
+
+// transactionAnomaly builds a low severity insight for an anomalous transaction
+func transactionAnomaly(tx Transaction, anomalyType, title, description string) SpendingInsight {
+	data := map[string]interface{}{
+		"anomaly_type":     anomalyType,
+		"transaction_id":   tx.ID.String(),
+		"transaction_date": tx.TransactionDate.Format("2006-01-02"),
+		"amount":           roundAmount(math.Abs(tx.Amount)),
+		"merchant":         tx.Merchant,
+	}
+	if tx.CategoryID != nil {
+		data["category_id"] = tx.CategoryID.String()
+	}
+	if tx.MerchantID != nil {
+		data["merchant_id"] = tx.MerchantID.String()
+	}
+
+	return SpendingInsight{
+		Type:        "anomaly",
+		Title:       title,
+		Description: description,
+		Severity:    "low",
+		Data:        data,
+		CreatedAt:   time.Now(),
+	}
+}
+
+// scoredAnomaly adds the score of an unusual amount and the history it was compared with
+// to an insight, and sets its severity from the score
+func scoredAnomaly(insight SpendingInsight, score, median float64, historyCount int) SpendingInsight {
+	insight.Severity = "medium"
+	if score >= highAnomalyScore {
+		insight.Severity = "high"
+	}
+	insight.Data["score"] = roundAmount(score)
+	insight.Data["median"] = roundAmount(median)
+	insight.Data["history_count"] = historyCount
+	return insight
+}
+
+// robustScore calculates how far an amount is above the median of earlier amounts, in
+// units of their median absolute deviation scaled to match a standard deviation. It
+// reports whether the amount is unusual, which needs enough earlier amounts to tell
+func robustScore(amounts []float64, amount float64) (float64, float64, bool) {
+	if len(amounts) < minAnomalyHistory {
+		return 0, 0, false
+	}
+
+	center := median(amounts)
+	deviations := make([]float64, len(amounts))
+	for i, a := range amounts {
+		deviations[i] = math.Abs(a - center)
+	}
+	spread := math.Max(1.4826*median(deviations), minRelativeSpread*center)
+	if spread == 0 {
+		return 0, center, false
+	}
+
+	score := (amount - center) / spread
+	return score, center, score >= anomalyScoreThreshold
+}
+
+// median returns the median of a list of values without modifying it
+func median(values []float64) float64 {
+	sorted := append([]float64(nil), values...)
+	sort.Float64s(sorted)
+
+	middle := len(sorted) / 2
+	if len(sorted)%2 == 0 {
+		return (sorted[middle-1] + sorted[middle]) / 2
+	}
+	return sorted[middle]
+}
+
+// categoryName looks up the name of a category for an insight
+func (s *service) categoryName(ctx context.Context, categoryID uuid.UUID, cache map[uuid.UUID]*Category) string {
+	if category := s.getCachedCategory(ctx, categoryID, cache); category != nil {
+		return category.Name
+	}
+	return "Uncategorized"
+}
+
+// transactionLabel describes a transaction by its merchant, or its description without one
+func transactionLabel(tx Transaction) string {
+	if tx.Merchant != "" {
+		return tx.Merchant
+	}
+	return tx.Description
+}
+
+// merchantKey identifies the merchant of a transaction. Transactions linked to a merchant
+// are grouped by merchant, others by name
+func merchantKey(tx Transaction) string {
+	if tx.MerchantID != nil {
+		return tx.MerchantID.String()
+	}
+	return tx.Merchant
+}
+
+// isExpense reports whether a transaction is money spent that wasn't cancelled or moved
+// to another account
+func isExpense(tx Transaction) bool {
+	return tx.Amount < 0 && tx.Status != "cancelled" && !tx.IsTransfer
+}
+
+// severityRank orders insight severities from low to high
+func severityRank(severity string) int {
+	switch severity {
+	case "high":
+		return 2
+	case "medium":
+		return 1
+	default:
+		return 0
+	}
+}
+
+// roundAmount rounds an amount to two decimals
+func roundAmount(amount float64) float64 {
+	return math.Round(amount*100) / 100
+}
@@ -0,0 +1,63 @@
+package analytics
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// maxBatchCategorizations is the most transactions categorized in one batch
+const maxBatchCategorizations = 5000
+
+// ErrInvalidBatchCategorization is returned for batches that are empty or too large
+var ErrInvalidBatchCategorization = errors.New("invalid batch categorization request")
+
+// CategorizeTransactions categorizes a batch of transactions, such as those of an import,
+// the same way CategorizeTransaction does one. The rules, models and categories are loaded
+// once for the whole batch
+func (s *service) CategorizeTransactions(ctx context.Context, req *BatchCategorizationRequest) (*BatchCategorizationResponse, error) {
+	ctx, span := otel.Tracer("").Start(ctx, "analytics.CategorizeTransactions",
+		trace.WithAttributes(attribute.Int("transactions_count", len(req.Transactions))),
+	)
+	defer span.End()
+
+	if len(req.Transactions) == 0 || len(req.Transactions) > maxBatchCategorizations {
+		err := fmt.Errorf("%w: between 1 and %d transactions are categorized at once, got %d",
+			ErrInvalidBatchCategorization, maxBatchCategorizations, len(req.Transactions))
+		span.SetStatus(codes.Error, err.Error())
+		return nil, err
+	}
+
+	response := &BatchCategorizationResponse{Categorizations: make([]CategorizationResponse, len(req.Transactions))}
+	categories := make(map[uuid.UUID]*Category)
+	categorized := 0
+	for i := range req.Transactions {
+		if err := ctx.Err(); err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+			return nil, err
+		}
+
+		transaction := req.Transactions[i]
+		transaction.UserID = req.UserID
+		categorization, err := s.categorize(ctx, &transaction, categories)
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+			return nil, fmt.Errorf("failed to categorize transaction %d: %w", i, err)
+		}
+		if categorization.CategoryID != uuid.Nil {
+			categorized++
+		}
+		response.Categorizations[i] = *categorization
+	}
+
+	span.SetAttributes(attribute.Int("categorized_count", categorized))
+	return response, nil
+}
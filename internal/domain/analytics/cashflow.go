@@ -0,0 +1,392 @@
+package analytics
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	// defaultForecastDays and maxForecastDays bound the number of days a cash flow
+	// forecast covers
+	defaultForecastDays = 90
+	maxForecastDays     = 365
+
+	// recurringLookbackDays is the number of days of transactions recurring cash flows are
+	// detected in, long enough to see monthly flows several times
+	recurringLookbackDays = 180
+
+	// discretionaryLookbackDays is the number of days average spending is based on
+	discretionaryLookbackDays = 90
+
+	// minRecurringOccurrences is the number of times income or an expense has to be seen
+	// at a regular interval to be expected again
+	minRecurringOccurrences = 3
+
+	// forecastConfidenceZ sets the width of the confidence band of projected balances,
+	// covering about 90% of outcomes
+	forecastConfidenceZ = 1.645
+)
+
+// ErrInvalidForecastRequest is returned for cash flow forecasts that cannot be made
+var ErrInvalidForecastRequest = errors.New("invalid cash flow forecast request")
+
+// recurringFrequencies are the intervals recurring cash flows are detected at, in days,
+// with how many days each occurrence may be early or late
+var recurringFrequencies = []struct {
+	frequency string
+	days      int
+	tolerance int
+}{
+	{frequency: FrequencyWeekly, days: 7, tolerance: 1},
+	{frequency: FrequencyBiweekly, days: 14, tolerance: 2},
+	{frequency: FrequencyMonthly, days: 30, tolerance: 3},
+}
+
+// GetCashFlowForecast projects the daily balances of a user's active accounts from their
+// current balances, recurring income and expenses, and average discretionary spending
+func (s *service) GetCashFlowForecast(ctx context.Context, userID uuid.UUID, req *CashFlowForecastRequest) (*CashFlowForecast, error) {
+	ctx, span := otel.Tracer("").Start(ctx, "analytics.GetCashFlowForecast",
+		trace.WithAttributes(
+			attribute.String("user_id", userID.String()),
+			attribute.Int("days", req.Days),
+		),
+	)
+	defer span.End()
+
+	days := req.Days
+	if days == 0 {
+		days = defaultForecastDays
+	}
+	if days < 0 || days > maxForecastDays {
+		err := fmt.Errorf("%w: days must be between 1 and %d", ErrInvalidForecastRequest, maxForecastDays)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, err
+	}
+
+	// Days follow the user's timezone
+	location, err := s.trendLocation(ctx, userID, "")
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, err
+	}
+
+	accounts, err := s.repo.GetActiveAccountsByUser(ctx, userID)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, err
+	}
+
+	now := time.Now().In(location)
+	today := calendarDate(now, location)
+	history, err := s.repo.GetTransactionsByPeriod(ctx, userID, today.AddDate(0, 0, -recurringLookbackDays), now)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, err
+	}
+
+	forecast := forecastCashFlow(accounts, history, today, days, req.LowBalanceThreshold)
+
+	lowBalance := false
+	for _, total := range forecast.Totals {
+		lowBalance = lowBalance || total.LowBalanceDate != nil
+	}
+	span.SetAttributes(
+		attribute.Int("accounts_count", len(forecast.Accounts)),
+		attribute.Bool("low_balance", lowBalance),
+	)
+	return forecast, nil
+}
+
+// forecastCashFlow projects the balance of each account and their total per currency for
+// the days after today. The uncertainty of the accounts is assumed to be independent
+func forecastCashFlow(accounts []Account, history []Transaction, today time.Time, days int, threshold float64) *CashFlowForecast {
+	byAccount := make(map[uuid.UUID][]Transaction)
+	for _, tx := range history {
+		if tx.Status != "cancelled" {
+			byAccount[tx.AccountID] = append(byAccount[tx.AccountID], tx)
+		}
+	}
+
+	forecast := &CashFlowForecast{
+		StartDate:           today.AddDate(0, 0, 1),
+		EndDate:             today.AddDate(0, 0, days),
+		LowBalanceThreshold: threshold,
+		Totals:              []CurrencyCashFlowForecast{},
+		Accounts:            make([]AccountCashFlowForecast, 0, len(accounts)),
+	}
+
+	totals := make(map[string]*CurrencyCashFlowForecast)
+	variances := make(map[string][]float64)
+	for _, account := range accounts {
+		accountForecast, dailyVariance := forecastAccount(account, byAccount[account.ID], today, days)
+		accountForecast.LowBalanceDate = lowBalanceDate(accountForecast.Balances, threshold)
+		forecast.Accounts = append(forecast.Accounts, accountForecast)
+
+		total, exists := totals[account.Currency]
+		if !exists {
+			total = &CurrencyCashFlowForecast{Currency: account.Currency, Balances: make([]BalanceProjection, days)}
+			totals[account.Currency] = total
+			variances[account.Currency] = make([]float64, days)
+		}
+		total.CurrentBalance += account.Balance
+		for i, projection := range accountForecast.Balances {
+			total.Balances[i].Balance += projection.Balance
+			variances[account.Currency][i] += dailyVariance * float64(i+1)
+		}
+	}
+
+	for currency, total := range totals {
+		total.CurrentBalance = roundAmount(total.CurrentBalance)
+		for i := range total.Balances {
+			balance := roundAmount(total.Balances[i].Balance)
+			band := forecastConfidenceZ * math.Sqrt(variances[currency][i])
+			total.Balances[i] = BalanceProjection{
+				Date:    today.AddDate(0, 0, i+1),
+				Balance: balance,
+				Lower:   roundAmount(balance - band),
+				Upper:   roundAmount(balance + band),
+			}
+		}
+		total.LowBalanceDate = lowBalanceDate(total.Balances, threshold)
+		forecast.Totals = append(forecast.Totals, *total)
+	}
+	sort.Slice(forecast.Totals, func(i, j int) bool {
+		return forecast.Totals[i].Currency < forecast.Totals[j].Currency
+	})
+
+	return forecast
+}
+
+// forecastAccount projects the balance of an account from its recurring cash flows and
+// its average daily spending outside them. It also returns the variance of that spending
+// per day, which the confidence band grows with
+func forecastAccount(account Account, transactions []Transaction, today time.Time, days int) (AccountCashFlowForecast, float64) {
+	location := today.Location()
+	recurring, recurringKeys := detectRecurringFlows(transactions, today)
+
+	// Discretionary spending is averaged over the recent days the account has history for.
+	// Transfers between accounts are not spending
+	windowStart := today.AddDate(0, 0, -discretionaryLookbackDays)
+	spending := make(map[time.Time]float64)
+	var first time.Time
+	for _, tx := range transactions {
+		day := calendarDate(tx.TransactionDate.In(location), location)
+		if day.Before(windowStart) {
+			continue
+		}
+		if first.IsZero() || day.Before(first) {
+			first = day
+		}
+		if tx.Amount < 0 && !tx.IsTransfer && !recurringKeys[cashFlowKey(tx)] {
+			spending[day] += math.Abs(tx.Amount)
+		}
+	}
+	if first.After(windowStart) {
+		windowStart = first
+	}
+
+	var mean, variance float64
+	if !first.IsZero() {
+		observedDays := float64(daysBetween(windowStart, today) + 1)
+		var total float64
+		for _, amount := range spending {
+			total += amount
+		}
+		mean = total / observedDays
+
+		// Days without spending count as well
+		variance = (observedDays - float64(len(spending))) * mean * mean
+		for _, amount := range spending {
+			variance += (amount - mean) * (amount - mean)
+		}
+		variance /= observedDays
+	}
+
+	end := today.AddDate(0, 0, days)
+	scheduled := make(map[int]float64)
+	for _, flow := range recurring {
+		for next := flow.NextDate; !next.After(end); next = nextOccurrence(next, flow.Frequency) {
+			scheduled[daysBetween(today, next)] += flow.Amount
+		}
+	}
+
+	forecast := AccountCashFlowForecast{
+		AccountID:          account.ID,
+		AccountName:        account.Name,
+		Currency:           account.Currency,
+		CurrentBalance:     account.Balance,
+		DailyDiscretionary: roundAmount(mean),
+		Recurring:          recurring,
+		Balances:           make([]BalanceProjection, 0, days),
+	}
+
+	balance := account.Balance
+	for day := 1; day <= days; day++ {
+		balance += scheduled[day] - mean
+		band := forecastConfidenceZ * math.Sqrt(variance*float64(day))
+		forecast.Balances = append(forecast.Balances, BalanceProjection{
+			Date:    today.AddDate(0, 0, day),
+			Balance: roundAmount(balance),
+			Lower:   roundAmount(balance - band),
+			Upper:   roundAmount(balance + band),
+		})
+	}
+
+	return forecast, variance
+}
+
+// detectRecurringFlows finds income and expenses with the same payee that were seen at a
+// regular interval, and are still expected after today. It also returns the keys of the
+// transactions that belong to them
+func detectRecurringFlows(transactions []Transaction, today time.Time) ([]RecurringCashFlow, map[string]bool) {
+	type series struct {
+		name string
+		days map[time.Time]float64
+	}
+
+	location := today.Location()
+	byKey := make(map[string]*series)
+	for _, tx := range transactions {
+		key := cashFlowKey(tx)
+		if key == "" {
+			continue
+		}
+
+		flow, exists := byKey[key]
+		if !exists {
+			flow = &series{name: transactionLabel(tx), days: make(map[time.Time]float64)}
+			byKey[key] = flow
+		}
+		// Several transactions on the same day are one occurrence
+		flow.days[calendarDate(tx.TransactionDate.In(location), location)] += tx.Amount
+	}
+
+	recurring := []RecurringCashFlow{}
+	keys := make(map[string]bool)
+	for key, flow := range byKey {
+		if len(flow.days) < minRecurringOccurrences {
+			continue
+		}
+
+		dates := make([]time.Time, 0, len(flow.days))
+		amounts := make([]float64, 0, len(flow.days))
+		for day, amount := range flow.days {
+			dates = append(dates, day)
+			amounts = append(amounts, amount)
+		}
+		sort.Slice(dates, func(i, j int) bool { return dates[i].Before(dates[j]) })
+
+		frequency, tolerance := recurringFrequency(dates)
+		if frequency == "" {
+			continue
+		}
+
+		// Flows that were missed are no longer expected
+		next := nextOccurrence(dates[len(dates)-1], frequency)
+		if daysBetween(next, today) > tolerance {
+			continue
+		}
+		for !next.After(today) {
+			next = nextOccurrence(next, frequency)
+		}
+
+		keys[key] = true
+		recurring = append(recurring, RecurringCashFlow{
+			Name:      flow.name,
+			Amount:    roundAmount(median(amounts)),
+			Frequency: frequency,
+			NextDate:  next,
+		})
+	}
+
+	sort.Slice(recurring, func(i, j int) bool {
+		if !recurring[i].NextDate.Equal(recurring[j].NextDate) {
+			return recurring[i].NextDate.Before(recurring[j].NextDate)
+		}
+		return recurring[i].Name < recurring[j].Name
+	})
+	return recurring, keys
+}
+
+// recurringFrequency returns the frequency all intervals between a series of dates match,
+// and how many days an occurrence may be late, or an empty frequency without one
+func recurringFrequency(dates []time.Time) (string, int) {
+	for _, candidate := range recurringFrequencies {
+		matches := true
+		for i := 1; i < len(dates); i++ {
+			gap := daysBetween(dates[i-1], dates[i])
+			if gap < candidate.days-candidate.tolerance || gap > candidate.days+candidate.tolerance {
+				matches = false
+				break
+			}
+		}
+		if matches {
+			return candidate.frequency, candidate.tolerance
+		}
+	}
+	return "", 0
+}
+
+// nextOccurrence returns the date a recurring cash flow is expected after the given one.
+// Monthly flows keep their day of the month, or the last day of shorter months
+func nextOccurrence(date time.Time, frequency string) time.Time {
+	switch frequency {
+	case FrequencyWeekly:
+		return date.AddDate(0, 0, 7)
+	case FrequencyBiweekly:
+		return date.AddDate(0, 0, 14)
+	default:
+		year, month, day := date.Date()
+		lastDay := time.Date(year, month+2, 0, 0, 0, 0, 0, date.Location()).Day()
+		if day > lastDay {
+			day = lastDay
+		}
+		return time.Date(year, month+1, day, 0, 0, 0, 0, date.Location())
+	}
+}
+
+// cashFlowKey groups the transactions of a recurring cash flow by payee, keeping income
+// and expenses apart
+func cashFlowKey(tx Transaction) string {
+	key := merchantKey(tx)
+	if key == "" {
+		key = strings.ToLower(strings.TrimSpace(tx.Description))
+	}
+	if key == "" || tx.Amount == 0 {
+		return ""
+	}
+	if tx.Amount > 0 {
+		return "+" + key
+	}
+	return "-" + key
+}
+
+// lowBalanceDate returns the first day a projected balance falls below the threshold
+func lowBalanceDate(balances []BalanceProjection, threshold float64) *time.Time {
+	for _, projection := range balances {
+		if projection.Balance < threshold {
+			date := projection.Date
+			return &date
+		}
+	}
+	return nil
+}
+
+// daysBetween returns the number of calendar days from one day to another
+func daysBetween(from, to time.Time) int {
+	return int(math.Round(to.Sub(from).Hours() / 24))
+}
@@ -0,0 +1,492 @@
+package analytics
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"math"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	// maxTrainingExamples is the number of most recent categorized transactions a model is
+	// trained on, and minTrainingExamples the number needed to train one at all
+	maxTrainingExamples = 50000
+	minTrainingExamples = 20
+
+	// holdoutEvery sets aside one in n transactions to evaluate models on transactions they
+	// weren't trained on
+	holdoutEvery = 5
+
+	// modelCacheTTL is how long the active model is kept in memory before checking for a
+	// newer one
+	modelCacheTTL = 5 * time.Minute
+
+	// Alternatives to a suggested category are only offered when they are likely enough
+	maxAlternativeCategories = 3
+	minAlternativeConfidence = 0.05
+)
+
+// ErrInsufficientTrainingData is returned when there are too few categorized transactions
+// to train a categorization model
+var ErrInsufficientTrainingData = errors.New("not enough categorized transactions to train a model")
+
+// modelCache keeps the active categorization model in memory between requests
+type modelCache struct {
+	mu         sync.RWMutex
+	classifier *naiveBayesModel
+	loadedAt   time.Time
+}
+
+// get returns the cached classifier and whether it is still fresh
+func (c *modelCache) get() (*naiveBayesModel, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.classifier, !c.loadedAt.IsZero() && time.Since(c.loadedAt) < modelCacheTTL
+}
+
+// set replaces the cached classifier, which is nil when there is no active model
+func (c *modelCache) set(classifier *naiveBayesModel) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.classifier = classifier
+	c.loadedAt = time.Now()
+}
+
+// invalidate makes the active model be loaded again on its next use
+func (c *modelCache) invalidate() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.classifier = nil
+	c.loadedAt = time.Time{}
+}
+
+// TrainCategorizationModel trains a new version of the categorization model on the most
+// recent categorized transactions and evaluates it on the ones held out from training. The
+// new version is stored either way, but only becomes the active model when it categorizes
+// the held out transactions better than the active one
+func (s *service) TrainCategorizationModel(ctx context.Context) (*ModelTrainingResult, error) {
+	ctx, span := otel.Tracer("").Start(ctx, "analytics.TrainCategorizationModel")
+	defer span.End()
+
+	transactions, err := s.repo.GetCategorizedTransactions(ctx, maxTrainingExamples)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, err
+	}
+
+	examples := trainingExamples(transactions)
+	if len(examples) < minTrainingExamples {
+		err := fmt.Errorf("%w: %d of %d transactions", ErrInsufficientTrainingData, len(examples), minTrainingExamples)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, err
+	}
+
+	training, holdout := splitExamples(examples)
+	classifier := trainNaiveBayes(training)
+	metrics := evaluateModel(classifier, holdout)
+	metrics.TrainingExamples = len(training)
+
+	// Held out transactions are never trained on, so both models are compared on
+	// transactions neither has seen
+	result := &ModelTrainingResult{}
+	active, err := s.repo.GetActiveCategorizationModel(ctx, ModelTypeNaiveBayes)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, err
+	}
+	if active != nil {
+		activeClassifier, err := unmarshalNaiveBayes(active.ModelData)
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+			return nil, err
+		}
+		previousAccuracy := evaluateModel(activeClassifier, holdout).Accuracy
+		result.PreviousModelID = &active.ID
+		result.PreviousAccuracy = &previousAccuracy
+	}
+	result.Activated = result.PreviousAccuracy == nil || metrics.Accuracy > *result.PreviousAccuracy
+
+	data, err := classifier.marshal()
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, err
+	}
+	serializedMetrics, err := json.Marshal(metrics)
+	if err != nil {
+		err = fmt.Errorf("failed to serialize model metrics: %w", err)
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, err
+	}
+
+	model := &CategorizationModel{
+		Name:      "transaction-categorizer",
+		Version:   time.Now().UTC().Format("20060102150405"),
+		ModelType: ModelTypeNaiveBayes,
+		Accuracy:  metrics.Accuracy,
+		ModelData: data,
+		Metrics:   string(serializedMetrics),
+	}
+	if err := s.repo.CreateCategorizationModel(ctx, model); err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, err
+	}
+	if result.Activated {
+		if err := s.repo.ActivateCategorizationModel(ctx, model.ID); err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+			return nil, err
+		}
+		model.IsActive = true
+		s.models.set(classifier)
+	}
+	result.Model = *toCategorizationModelResponse(model)
+
+	span.SetAttributes(
+		attribute.String("model_id", model.ID.String()),
+		attribute.Int("examples_count", len(examples)),
+		attribute.Float64("accuracy", metrics.Accuracy),
+		attribute.Bool("activated", result.Activated),
+	)
+	return result, nil
+}
+
+// ListCategorizationModels retrieves the versions of the categorization model, newest first
+func (s *service) ListCategorizationModels(ctx context.Context, offset, limit int) ([]CategorizationModelResponse, error) {
+	ctx, span := otel.Tracer("").Start(ctx, "analytics.ListCategorizationModels",
+		trace.WithAttributes(
+			attribute.Int("offset", offset),
+			attribute.Int("limit", limit),
+		),
+	)
+	defer span.End()
+
+	models, err := s.repo.GetCategorizationModels(ctx, ModelTypeNaiveBayes, offset, limit)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, err
+	}
+
+	responses := make([]CategorizationModelResponse, len(models))
+	for i := range models {
+		responses[i] = *toCategorizationModelResponse(&models[i])
+	}
+
+	span.SetAttributes(attribute.Int("models_count", len(responses)))
+	return responses, nil
+}
+
+// CompareCategorizationModels compares the evaluation metrics of two model versions, per
+// category. Categories only one of the models was evaluated on are compared with zeros
+func (s *service) CompareCategorizationModels(ctx context.Context, baseID, candidateID uuid.UUID) (*ModelComparison, error) {
+	ctx, span := otel.Tracer("").Start(ctx, "analytics.CompareCategorizationModels",
+		trace.WithAttributes(
+			attribute.String("base_model_id", baseID.String()),
+			attribute.String("candidate_model_id", candidateID.String()),
+		),
+	)
+	defer span.End()
+
+	base, err := s.repo.GetCategorizationModelByID(ctx, baseID)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, err
+	}
+	candidate, err := s.repo.GetCategorizationModelByID(ctx, candidateID)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, err
+	}
+
+	comparison := &ModelComparison{
+		Base:           *toCategorizationModelResponse(base),
+		Candidate:      *toCategorizationModelResponse(candidate),
+		AccuracyChange: math.Round((candidate.Accuracy-base.Accuracy)*10000) / 10000,
+		Categories:     []CategoryMetricsComparison{},
+	}
+
+	byCategory := make(map[uuid.UUID]*CategoryMetricsComparison)
+	compared := func(categoryID uuid.UUID) *CategoryMetricsComparison {
+		if _, exists := byCategory[categoryID]; !exists {
+			byCategory[categoryID] = &CategoryMetricsComparison{CategoryID: categoryID}
+		}
+		return byCategory[categoryID]
+	}
+	if comparison.Base.Metrics != nil {
+		for _, metrics := range comparison.Base.Metrics.Categories {
+			category := compared(metrics.CategoryID)
+			category.BasePrecision = metrics.Precision
+			category.BaseRecall = metrics.Recall
+		}
+	}
+	if comparison.Candidate.Metrics != nil {
+		for _, metrics := range comparison.Candidate.Metrics.Categories {
+			category := compared(metrics.CategoryID)
+			category.CandidatePrecision = metrics.Precision
+			category.CandidateRecall = metrics.Recall
+		}
+	}
+	for _, category := range byCategory {
+		comparison.Categories = append(comparison.Categories, *category)
+	}
+	sort.Slice(comparison.Categories, func(i, j int) bool {
+		return comparison.Categories[i].CategoryID.String() < comparison.Categories[j].CategoryID.String()
+	})
+
+	return comparison, nil
+}
+
+// ActivateCategorizationModel makes a model version the active one, such as to roll back
+// to an earlier version
+func (s *service) ActivateCategorizationModel(ctx context.Context, id uuid.UUID) (*CategorizationModelResponse, error) {
+	ctx, span := otel.Tracer("").Start(ctx, "analytics.ActivateCategorizationModel",
+		trace.WithAttributes(attribute.String("model_id", id.String())),
+	)
+	defer span.End()
+
+	model, err := s.repo.GetCategorizationModelByID(ctx, id)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, err
+	}
+
+	if err := s.repo.ActivateCategorizationModel(ctx, id); err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, err
+	}
+	model.IsActive = true
+
+	// The model is loaded again on the next categorization
+	s.models.invalidate()
+
+	return toCategorizationModelResponse(model), nil
+}
+
+// categorizeByML categorizes a transaction with the active categorization model and the
+// user's corrections. Only categories the user can see are suggested
+func (s *service) categorizeByML(ctx context.Context, req *CategorizationRequest, categories map[uuid.UUID]*Category) (*CategorizationResponse, error) {
+	classifier, err := s.activeClassifier(ctx)
+	if err != nil {
+		return nil, err
+	}
+	feedback, err := s.feedbackClassifier(ctx, req.UserID)
+	if err != nil {
+		return nil, err
+	}
+	if classifier == nil && feedback == nil {
+		return nil, nil
+	}
+
+	predictions := predictWithFeedback(classifier, feedback, categorizationTokens(req.Description, req.Merchant, req.Amount))
+
+	var response *CategorizationResponse
+	for _, prediction := range predictions {
+		if response != nil && (len(response.AlternativeCategories) == maxAlternativeCategories || prediction.Probability < minAlternativeConfidence) {
+			break
+		}
+
+		// Categories may have been deleted since the model was trained
+		category := s.getCachedCategory(ctx, prediction.CategoryID, categories)
+		if category == nil {
+			continue
+		}
+		visible, err := s.canSeeCategory(ctx, req.UserID, category)
+		if err != nil {
+			return nil, err
+		}
+		if !visible {
+			continue
+		}
+
+		confidence := math.Round(prediction.Probability*10000) / 10000
+		if response == nil {
+			response = &CategorizationResponse{
+				CategoryID:           category.ID,
+				CategoryName:         category.Name,
+				Confidence:           confidence,
+				CategorizationSource: "ml",
+			}
+			continue
+		}
+		response.AlternativeCategories = append(response.AlternativeCategories, CategorySuggestion{
+			CategoryID:   category.ID,
+			CategoryName: category.Name,
+			Confidence:   confidence,
+			Reason:       "model",
+		})
+	}
+
+	return response, nil
+}
+
+// activeClassifier returns the classifier of the active categorization model, or nil when
+// no model has been trained
+func (s *service) activeClassifier(ctx context.Context) (*naiveBayesModel, error) {
+	if classifier, fresh := s.models.get(); fresh {
+		return classifier, nil
+	}
+
+	model, err := s.repo.GetActiveCategorizationModel(ctx, ModelTypeNaiveBayes)
+	if err != nil {
+		return nil, err
+	}
+
+	var classifier *naiveBayesModel
+	if model != nil {
+		if classifier, err = unmarshalNaiveBayes(model.ModelData); err != nil {
+			return nil, err
+		}
+	}
+	s.models.set(classifier)
+	return classifier, nil
+}
+
+// trainingExamples reduces categorized transactions to training examples
+func trainingExamples(transactions []Transaction) []trainingExample {
+	examples := make([]trainingExample, 0, len(transactions))
+	for _, tx := range transactions {
+		if tx.CategoryID == nil {
+			continue
+		}
+		examples = append(examples, trainingExample{
+			TransactionID: tx.ID,
+			CategoryID:    *tx.CategoryID,
+			Tokens:        categorizationTokens(tx.Description, tx.Merchant, tx.Amount),
+		})
+	}
+	return examples
+}
+
+// splitExamples sets aside the examples of one in holdoutEvery transactions for evaluation
+// and returns the others for training. The split depends on the transaction ID only, so a
+// transaction is held out of every model version and never trained on
+func splitExamples(examples []trainingExample) ([]trainingExample, []trainingExample) {
+	var training, holdout []trainingExample
+	for _, example := range examples {
+		hash := fnv.New32a()
+		hash.Write(example.TransactionID[:])
+		if hash.Sum32()%holdoutEvery == 0 {
+			holdout = append(holdout, example)
+		} else {
+			training = append(training, example)
+		}
+	}
+	return training, holdout
+}
+
+// evaluateModel categorizes the held out examples with a model and measures its accuracy,
+// its precision and recall per category and which categories it confuses
+func evaluateModel(classifier *naiveBayesModel, holdout []trainingExample) *ModelMetrics {
+	metrics := &ModelMetrics{
+		EvaluationExamples: len(holdout),
+		Categories:         []CategoryMetrics{},
+		ConfusionMatrix:    []ConfusionMatrixEntry{},
+	}
+	if len(holdout) == 0 {
+		return metrics
+	}
+
+	type cell struct{ actual, predicted uuid.UUID }
+	confusion := make(map[cell]int)
+	actualCounts := make(map[uuid.UUID]int)
+	predictedCounts := make(map[uuid.UUID]int)
+	correctCounts := make(map[uuid.UUID]int)
+	correct := 0
+	for _, example := range holdout {
+		// Examples the model can't categorize count as wrong
+		var predicted uuid.UUID
+		if predictions := classifier.predict(example.Tokens); len(predictions) > 0 {
+			predicted = predictions[0].CategoryID
+		}
+
+		confusion[cell{actual: example.CategoryID, predicted: predicted}]++
+		actualCounts[example.CategoryID]++
+		predictedCounts[predicted]++
+		if predicted == example.CategoryID {
+			correct++
+			correctCounts[predicted]++
+		}
+	}
+	metrics.Accuracy = roundRatio(correct, len(holdout))
+
+	for categoryID, support := range actualCounts {
+		metrics.Categories = append(metrics.Categories, CategoryMetrics{
+			CategoryID: categoryID,
+			Precision:  roundRatio(correctCounts[categoryID], predictedCounts[categoryID]),
+			Recall:     roundRatio(correctCounts[categoryID], support),
+			Support:    support,
+		})
+	}
+	sort.Slice(metrics.Categories, func(i, j int) bool {
+		return metrics.Categories[i].CategoryID.String() < metrics.Categories[j].CategoryID.String()
+	})
+
+	for key, count := range confusion {
+		metrics.ConfusionMatrix = append(metrics.ConfusionMatrix, ConfusionMatrixEntry{
+			ActualCategoryID:    key.actual,
+			PredictedCategoryID: key.predicted,
+			Count:               count,
+		})
+	}
+	sort.Slice(metrics.ConfusionMatrix, func(i, j int) bool {
+		a, b := metrics.ConfusionMatrix[i], metrics.ConfusionMatrix[j]
+		if a.ActualCategoryID != b.ActualCategoryID {
+			return a.ActualCategoryID.String() < b.ActualCategoryID.String()
+		}
+		return a.PredictedCategoryID.String() < b.PredictedCategoryID.String()
+	})
+
+	return metrics
+}
+
+// roundRatio divides two counts, rounded to four decimals, or returns zero without a total
+func roundRatio(count, total int) float64 {
+	if total == 0 {
+		return 0
+	}
+	return math.Round(float64(count)/float64(total)*10000) / 10000
+}
+
+// toCategorizationModelResponse converts a model version to its response, with the metrics
+// it was stored with
+func toCategorizationModelResponse(model *CategorizationModel) *CategorizationModelResponse {
+	response := &CategorizationModelResponse{
+		ID:        model.ID,
+		Name:      model.Name,
+		Version:   model.Version,
+		ModelType: model.ModelType,
+		Accuracy:  model.Accuracy,
+		IsActive:  model.IsActive,
+		CreatedAt: model.CreatedAt,
+	}
+
+	// Models trained before metrics were stored have none
+	if model.Metrics != "" {
+		var metrics ModelMetrics
+		if err := json.Unmarshal([]byte(model.Metrics), &metrics); err == nil {
+			response.Metrics = &metrics
+		}
+	}
+	return response
+}
@@ -0,0 +1,254 @@
+package analytics
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/google/uuid"
+)
+
+// amountBuckets are the upper bounds of the amount ranges a transaction's amount is
+// reduced to, so that the classifier can learn typical amounts per category
+var amountBuckets = []float64{5, 20, 50, 100, 250, 500, 1000}
+
+// naiveBayesModel is a multinomial Naive Bayes text classifier over the tokens of a
+// transaction's description and merchant and its amount range
+type naiveBayesModel struct {
+	Documents  int                               `json:"documents"`
+	Vocabulary map[string]int                    `json:"vocabulary"` // Number of documents with each token
+	Categories map[uuid.UUID]*naiveBayesCategory `json:"categories"`
+}
+
+// naiveBayesCategory holds the token counts of the training documents of a category
+type naiveBayesCategory struct {
+	Documents int            `json:"documents"`
+	Tokens    map[string]int `json:"tokens"`
+	Total     int            `json:"total"` // Number of tokens in all documents
+}
+
+// trainingExample is a categorized transaction reduced to its tokens
+type trainingExample struct {
+	TransactionID uuid.UUID // Decides whether the example is held out from training
+	CategoryID    uuid.UUID
+	Tokens        []string
+}
+
+// categoryProbability is the probability the classifier gives a category
+type categoryProbability struct {
+	CategoryID  uuid.UUID
+	Probability float64
+}
+
+// newNaiveBayesModel returns an empty model
+func newNaiveBayesModel() *naiveBayesModel {
+	return &naiveBayesModel{
+		Vocabulary: make(map[string]int),
+		Categories: make(map[uuid.UUID]*naiveBayesCategory),
+	}
+}
+
+// trainNaiveBayes trains a model on categorized examples
+func trainNaiveBayes(examples []trainingExample) *naiveBayesModel {
+	model := newNaiveBayesModel()
+	for _, example := range examples {
+		model.add(example)
+	}
+	return model
+}
+
+// add counts the tokens of an example towards its category
+func (m *naiveBayesModel) add(example trainingExample) {
+	category, exists := m.Categories[example.CategoryID]
+	if !exists {
+		category = &naiveBayesCategory{Tokens: make(map[string]int)}
+		m.Categories[example.CategoryID] = category
+	}
+
+	m.Documents++
+	category.Documents++
+	seen := make(map[string]bool, len(example.Tokens))
+	for _, token := range example.Tokens {
+		category.Tokens[token]++
+		category.Total++
+		if !seen[token] {
+			seen[token] = true
+			m.Vocabulary[token]++
+		}
+	}
+}
+
+// predict returns the probability of each category for a document's tokens, most likely
+// first. Tokens that never occurred in training are ignored
+func (m *naiveBayesModel) predict(tokens []string) []categoryProbability {
+	return predictWithFeedback(m, nil, tokens)
+}
+
+// predictWithFeedback predicts with a model and a model trained on a user's corrections,
+// counting each correction as feedbackWeight training documents. Either model may be nil
+func predictWithFeedback(model, feedback *naiveBayesModel, tokens []string) []categoryProbability {
+	models := []struct {
+		model  *naiveBayesModel
+		weight float64
+	}{{model, 1}, {feedback, feedbackWeight}}
+
+	var documents float64
+	categoryIDs := make(map[uuid.UUID]bool)
+	for _, weighted := range models {
+		if weighted.model == nil {
+			continue
+		}
+		documents += weighted.weight * float64(weighted.model.Documents)
+		for categoryID := range weighted.model.Categories {
+			categoryIDs[categoryID] = true
+		}
+	}
+	if documents == 0 {
+		return nil
+	}
+
+	// The vocabulary is that of both models, and the feedback one is much smaller
+	vocabularySize := 0
+	if model != nil {
+		vocabularySize = len(model.Vocabulary)
+	}
+	if feedback != nil {
+		for token := range feedback.Vocabulary {
+			if _, known := model.vocabulary()[token]; !known {
+				vocabularySize++
+			}
+		}
+	}
+	vocabulary := make(map[string]bool, len(tokens))
+	for _, token := range tokens {
+		for _, weighted := range models {
+			if _, known := weighted.model.vocabulary()[token]; known {
+				vocabulary[token] = true
+			}
+		}
+	}
+
+	scores := make([]categoryProbability, 0, len(categoryIDs))
+	for categoryID := range categoryIDs {
+		var categoryDocuments, categoryTotal float64
+		for _, weighted := range models {
+			if weighted.model == nil {
+				continue
+			}
+			if category, exists := weighted.model.Categories[categoryID]; exists {
+				categoryDocuments += weighted.weight * float64(category.Documents)
+				categoryTotal += weighted.weight * float64(category.Total)
+			}
+		}
+
+		// Log probabilities with Laplace smoothing, to avoid underflow and unseen tokens
+		score := math.Log(categoryDocuments / documents)
+		for _, token := range tokens {
+			if !vocabulary[token] {
+				continue
+			}
+			var count float64
+			for _, weighted := range models {
+				if weighted.model == nil {
+					continue
+				}
+				if category, exists := weighted.model.Categories[categoryID]; exists {
+					count += weighted.weight * float64(category.Tokens[token])
+				}
+			}
+			score += math.Log((count + 1) / (categoryTotal + float64(vocabularySize)))
+		}
+		scores = append(scores, categoryProbability{CategoryID: categoryID, Probability: score})
+	}
+
+	// Normalize the log scores into probabilities
+	highest := math.Inf(-1)
+	for _, score := range scores {
+		highest = math.Max(highest, score.Probability)
+	}
+	var sum float64
+	for i := range scores {
+		scores[i].Probability = math.Exp(scores[i].Probability - highest)
+		sum += scores[i].Probability
+	}
+	for i := range scores {
+		scores[i].Probability /= sum
+	}
+
+	sort.Slice(scores, func(i, j int) bool {
+		if scores[i].Probability != scores[j].Probability {
+			return scores[i].Probability > scores[j].Probability
+		}
+		return scores[i].CategoryID.String() < scores[j].CategoryID.String()
+	})
+	return scores
+}
+
+// vocabulary returns the tokens the model was trained on, or none for a nil model
+func (m *naiveBayesModel) vocabulary() map[string]int {
+	if m == nil {
+		return nil
+	}
+	return m.Vocabulary
+}
+
+// marshal serializes the model for CategorizationModel.ModelData
+func (m *naiveBayesModel) marshal() (string, error) {
+	data, err := json.Marshal(m)
+	if err != nil {
+		return "", fmt.Errorf("failed to serialize categorization model: %w", err)
+	}
+	return string(data), nil
+}
+
+// unmarshalNaiveBayes deserializes a model stored in CategorizationModel.ModelData
+func unmarshalNaiveBayes(data string) (*naiveBayesModel, error) {
+	model := newNaiveBayesModel()
+	if err := json.Unmarshal([]byte(data), model); err != nil {
+		return nil, fmt.Errorf("failed to deserialize categorization model: %w", err)
+	}
+	return model, nil
+}
+
+// categorizationTokens reduces a transaction to the tokens the classifier works on: the
+// words of its description and merchant, the merchant as a whole and its amount range
+func categorizationTokens(description, merchant string, amount float64) []string {
+	tokens := textTokens(description)
+	if merchant != "" {
+		tokens = append(tokens, textTokens(merchant)...)
+		tokens = append(tokens, "merchant:"+strings.Join(textTokens(merchant), " "))
+	}
+	return append(tokens, amountToken(amount))
+}
+
+// textTokens splits text into lowercase words, leaving out numbers and single characters
+// such as card numbers and reference codes
+func textTokens(text string) []string {
+	words := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+
+	tokens := make([]string, 0, len(words))
+	for _, word := range words {
+		if len([]rune(word)) < 2 || strings.IndexFunc(word, unicode.IsLetter) < 0 {
+			continue
+		}
+		tokens = append(tokens, word)
+	}
+	return tokens
+}
+
+// amountToken names the range an amount falls in. Expenses and income are not told apart,
+// as requests and transactions don't agree on the sign of an expense
+func amountToken(amount float64) string {
+	amount = math.Abs(amount)
+	for _, bound := range amountBuckets {
+		if amount < bound {
+			return fmt.Sprintf("amount:<%g", bound)
+		}
+	}
+	return fmt.Sprintf("amount:>=%g", amountBuckets[len(amountBuckets)-1])
+}
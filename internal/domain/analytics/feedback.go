@@ -0,0 +1,221 @@
+package analytics
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+
+	"fiscaflow/internal/seeds"
+)
+
+const (
+	// feedbackWeight is how many training documents a user's correction counts as when
+	// categorizing their transactions, so that it outweighs what other users taught the model
+	feedbackWeight = 5
+
+	// maxFeedbackExamples is the number of a user's most recent corrections learned from
+	maxFeedbackExamples = 1000
+
+	// minRuleCorrections is how many times a merchant's transactions have to be corrected
+	// to the same category before a rule is suggested for it
+	minRuleCorrections = 3
+)
+
+// feedbackCache keeps the models trained on each user's corrections in memory
+type feedbackCache struct {
+	mu     sync.RWMutex
+	models map[uuid.UUID]cachedFeedback
+}
+
+// cachedFeedback is the model of a user's corrections, which is nil without any
+type cachedFeedback struct {
+	classifier *naiveBayesModel
+	loadedAt   time.Time
+}
+
+// get returns the cached model of a user's corrections and whether it is still fresh
+func (c *feedbackCache) get(userID uuid.UUID) (*naiveBayesModel, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	cached, exists := c.models[userID]
+	return cached.classifier, exists && time.Since(cached.loadedAt) < modelCacheTTL
+}
+
+// set replaces the cached model of a user's corrections, dropping those of other users
+// that are no longer fresh
+func (c *feedbackCache) set(userID uuid.UUID, classifier *naiveBayesModel) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.models == nil {
+		c.models = make(map[uuid.UUID]cachedFeedback)
+	}
+	for id, cached := range c.models {
+		if time.Since(cached.loadedAt) >= modelCacheTTL {
+			delete(c.models, id)
+		}
+	}
+	c.models[userID] = cachedFeedback{classifier: classifier, loadedAt: time.Now()}
+}
+
+// invalidate makes a user's corrections be loaded again on their next use
+func (c *feedbackCache) invalidate(userID uuid.UUID) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.models, userID)
+}
+
+// RecordCorrection records a user correcting the category of a transaction that was
+// categorized automatically. The correction is learned from right away for the user's
+// transactions, and by the next trained model for everyone
+func (s *service) RecordCorrection(ctx context.Context, userID uuid.UUID, req *CategoryCorrectionRequest) error {
+	ctx, span := otel.Tracer("").Start(ctx, "analytics.RecordCorrection",
+		trace.WithAttributes(
+			attribute.String("user_id", userID.String()),
+			attribute.String("transaction_id", req.TransactionID.String()),
+			attribute.String("category_id", req.CategoryID.String()),
+			attribute.String("previous_source", req.PreviousSource),
+		),
+	)
+	defer span.End()
+
+	feedback := &CategorizationFeedback{
+		UserID:             userID,
+		TransactionID:      req.TransactionID,
+		Description:        req.Description,
+		Merchant:           req.Merchant,
+		Amount:             req.Amount,
+		PreviousCategoryID: req.PreviousCategoryID,
+		PreviousSource:     req.PreviousSource,
+		CategoryID:         req.CategoryID,
+	}
+	if err := s.repo.CreateCategorizationFeedback(ctx, feedback); err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return err
+	}
+
+	s.feedback.invalidate(userID)
+	return nil
+}
+
+// SuggestCategorizationRules proposes rules for the merchants whose transactions a user
+// keeps correcting to the same category, unless one of the rules that apply to the user in
+// the locale already categorizes them that way
+func (s *service) SuggestCategorizationRules(ctx context.Context, userID uuid.UUID, locale string) ([]RuleSuggestion, error) {
+	ctx, span := otel.Tracer("").Start(ctx, "analytics.SuggestCategorizationRules",
+		trace.WithAttributes(
+			attribute.String("user_id", userID.String()),
+			attribute.String("locale", locale),
+		),
+	)
+	defer span.End()
+
+	corrections, err := s.repo.GetCategorizationFeedbackByUser(ctx, userID, maxFeedbackExamples)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, err
+	}
+
+	type merchantCorrections struct {
+		name       string
+		total      int
+		categories map[uuid.UUID]int
+	}
+	byMerchant := make(map[string]*merchantCorrections)
+	for _, correction := range corrections {
+		key := strings.ToLower(strings.TrimSpace(correction.Merchant))
+		if key == "" {
+			continue
+		}
+		merchant, exists := byMerchant[key]
+		if !exists {
+			merchant = &merchantCorrections{name: strings.TrimSpace(correction.Merchant), categories: make(map[uuid.UUID]int)}
+			byMerchant[key] = merchant
+		}
+		merchant.total++
+		merchant.categories[correction.CategoryID]++
+	}
+
+	rules, err := s.compiledRules(ctx, &userID)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, err
+	}
+	locale = seeds.ResolveLocale(locale)
+
+	suggestions := []RuleSuggestion{}
+	for _, merchant := range byMerchant {
+		for categoryID, count := range merchant.categories {
+			// Only a category most corrections agree on is suggested
+			if count < minRuleCorrections || count*2 <= merchant.total {
+				continue
+			}
+			if matched := rules.firstMatch(&CategorizationRequest{Merchant: merchant.name}, locale); matched != nil && matched.CategoryID == categoryID {
+				continue
+			}
+
+			category, err := s.repo.GetCategoryByID(ctx, categoryID)
+			if err != nil {
+				continue
+			}
+			suggestions = append(suggestions, RuleSuggestion{
+				CategoryID:   categoryID,
+				CategoryName: category.Name,
+				Pattern:      merchant.name,
+				PatternType:  "exact",
+				Corrections:  count,
+			})
+		}
+	}
+
+	sort.Slice(suggestions, func(i, j int) bool {
+		if suggestions[i].Corrections != suggestions[j].Corrections {
+			return suggestions[i].Corrections > suggestions[j].Corrections
+		}
+		return suggestions[i].Pattern < suggestions[j].Pattern
+	})
+
+	span.SetAttributes(attribute.Int("suggestions_count", len(suggestions)))
+	return suggestions, nil
+}
+
+// feedbackClassifier returns the model trained on a user's corrections, or nil without a
+// user or corrections
+func (s *service) feedbackClassifier(ctx context.Context, userID *uuid.UUID) (*naiveBayesModel, error) {
+	if userID == nil {
+		return nil, nil
+	}
+	if classifier, fresh := s.feedback.get(*userID); fresh {
+		return classifier, nil
+	}
+
+	corrections, err := s.repo.GetCategorizationFeedbackByUser(ctx, *userID, maxFeedbackExamples)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load categorization feedback: %w", err)
+	}
+
+	var classifier *naiveBayesModel
+	if len(corrections) > 0 {
+		examples := make([]trainingExample, len(corrections))
+		for i, correction := range corrections {
+			examples[i] = trainingExample{
+				CategoryID: correction.CategoryID,
+				Tokens:     categorizationTokens(correction.Description, correction.Merchant, correction.Amount),
+			}
+		}
+		classifier = trainNaiveBayes(examples)
+	}
+	s.feedback.set(*userID, classifier)
+	return classifier, nil
+}
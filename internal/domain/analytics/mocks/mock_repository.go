@@ -37,6 +37,48 @@ func (m *MockRepository) EXPECT() *MockRepositoryMockRecorder {
 	return m.recorder
 }
 
+// ActivateCategorizationModel mocks base method.
+func (m *MockRepository) ActivateCategorizationModel(ctx context.Context, id uuid.UUID) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ActivateCategorizationModel", ctx, id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ActivateCategorizationModel indicates an expected call of ActivateCategorizationModel.
+func (mr *MockRepositoryMockRecorder) ActivateCategorizationModel(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ActivateCategorizationModel", reflect.TypeOf((*MockRepository)(nil).ActivateCategorizationModel), ctx, id)
+}
+
+// CreateCategorizationFeedback mocks base method.
+func (m *MockRepository) CreateCategorizationFeedback(ctx context.Context, feedback *analytics.CategorizationFeedback) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateCategorizationFeedback", ctx, feedback)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateCategorizationFeedback indicates an expected call of CreateCategorizationFeedback.
+func (mr *MockRepositoryMockRecorder) CreateCategorizationFeedback(ctx, feedback interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateCategorizationFeedback", reflect.TypeOf((*MockRepository)(nil).CreateCategorizationFeedback), ctx, feedback)
+}
+
+// CreateCategorizationModel mocks base method.
+func (m *MockRepository) CreateCategorizationModel(ctx context.Context, model *analytics.CategorizationModel) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateCategorizationModel", ctx, model)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateCategorizationModel indicates an expected call of CreateCategorizationModel.
+func (mr *MockRepositoryMockRecorder) CreateCategorizationModel(ctx, model interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateCategorizationModel", reflect.TypeOf((*MockRepository)(nil).CreateCategorizationModel), ctx, model)
+}
+
 // CreateCategorizationRule mocks base method.
 func (m *MockRepository) CreateCategorizationRule(ctx context.Context, rule *analytics.CategorizationRule) error {
 	m.ctrl.T.Helper()
@@ -51,6 +93,20 @@ func (mr *MockRepositoryMockRecorder) CreateCategorizationRule(ctx, rule interfa
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateCategorizationRule", reflect.TypeOf((*MockRepository)(nil).CreateCategorizationRule), ctx, rule)
 }
 
+// CreateRuleApplication mocks base method.
+func (m *MockRepository) CreateRuleApplication(ctx context.Context, application *analytics.RuleApplication, changes []analytics.RuleApplicationChange, transactions []analytics.Transaction) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateRuleApplication", ctx, application, changes, transactions)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateRuleApplication indicates an expected call of CreateRuleApplication.
+func (mr *MockRepositoryMockRecorder) CreateRuleApplication(ctx, application, changes, transactions interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateRuleApplication", reflect.TypeOf((*MockRepository)(nil).CreateRuleApplication), ctx, application, changes, transactions)
+}
+
 // CreateSpendingAnalysis mocks base method.
 func (m *MockRepository) CreateSpendingAnalysis(ctx context.Context, analysis *analytics.SpendingAnalysis) error {
 	m.ctrl.T.Helper()
@@ -79,19 +135,109 @@ func (mr *MockRepositoryMockRecorder) DeleteCategorizationRule(ctx, id interface
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteCategorizationRule", reflect.TypeOf((*MockRepository)(nil).DeleteCategorizationRule), ctx, id)
 }
 
+// GetActiveAccountsByUser mocks base method.
+func (m *MockRepository) GetActiveAccountsByUser(ctx context.Context, userID uuid.UUID) ([]analytics.Account, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetActiveAccountsByUser", ctx, userID)
+	ret0, _ := ret[0].([]analytics.Account)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetActiveAccountsByUser indicates an expected call of GetActiveAccountsByUser.
+func (mr *MockRepositoryMockRecorder) GetActiveAccountsByUser(ctx, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetActiveAccountsByUser", reflect.TypeOf((*MockRepository)(nil).GetActiveAccountsByUser), ctx, userID)
+}
+
+// GetActiveCategorizationModel mocks base method.
+func (m *MockRepository) GetActiveCategorizationModel(ctx context.Context, modelType string) (*analytics.CategorizationModel, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetActiveCategorizationModel", ctx, modelType)
+	ret0, _ := ret[0].(*analytics.CategorizationModel)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetActiveCategorizationModel indicates an expected call of GetActiveCategorizationModel.
+func (mr *MockRepositoryMockRecorder) GetActiveCategorizationModel(ctx, modelType interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetActiveCategorizationModel", reflect.TypeOf((*MockRepository)(nil).GetActiveCategorizationModel), ctx, modelType)
+}
+
 // GetActiveCategorizationRules mocks base method.
-func (m *MockRepository) GetActiveCategorizationRules(ctx context.Context) ([]analytics.CategorizationRule, error) {
+func (m *MockRepository) GetActiveCategorizationRules(ctx context.Context, userID *uuid.UUID) ([]analytics.CategorizationRule, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetActiveCategorizationRules", ctx)
+	ret := m.ctrl.Call(m, "GetActiveCategorizationRules", ctx, userID)
 	ret0, _ := ret[0].([]analytics.CategorizationRule)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // GetActiveCategorizationRules indicates an expected call of GetActiveCategorizationRules.
-func (mr *MockRepositoryMockRecorder) GetActiveCategorizationRules(ctx interface{}) *gomock.Call {
+func (mr *MockRepositoryMockRecorder) GetActiveCategorizationRules(ctx, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetActiveCategorizationRules", reflect.TypeOf((*MockRepository)(nil).GetActiveCategorizationRules), ctx, userID)
+}
+
+// GetActiveGoalsByUser mocks base method.
+func (m *MockRepository) GetActiveGoalsByUser(ctx context.Context, userID uuid.UUID) ([]analytics.Goal, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetActiveGoalsByUser", ctx, userID)
+	ret0, _ := ret[0].([]analytics.Goal)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetActiveGoalsByUser indicates an expected call of GetActiveGoalsByUser.
+func (mr *MockRepositoryMockRecorder) GetActiveGoalsByUser(ctx, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetActiveGoalsByUser", reflect.TypeOf((*MockRepository)(nil).GetActiveGoalsByUser), ctx, userID)
+}
+
+// GetCategorizationFeedbackByUser mocks base method.
+func (m *MockRepository) GetCategorizationFeedbackByUser(ctx context.Context, userID uuid.UUID, limit int) ([]analytics.CategorizationFeedback, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetCategorizationFeedbackByUser", ctx, userID, limit)
+	ret0, _ := ret[0].([]analytics.CategorizationFeedback)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetCategorizationFeedbackByUser indicates an expected call of GetCategorizationFeedbackByUser.
+func (mr *MockRepositoryMockRecorder) GetCategorizationFeedbackByUser(ctx, userID, limit interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetActiveCategorizationRules", reflect.TypeOf((*MockRepository)(nil).GetActiveCategorizationRules), ctx)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetCategorizationFeedbackByUser", reflect.TypeOf((*MockRepository)(nil).GetCategorizationFeedbackByUser), ctx, userID, limit)
+}
+
+// GetCategorizationModelByID mocks base method.
+func (m *MockRepository) GetCategorizationModelByID(ctx context.Context, id uuid.UUID) (*analytics.CategorizationModel, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetCategorizationModelByID", ctx, id)
+	ret0, _ := ret[0].(*analytics.CategorizationModel)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetCategorizationModelByID indicates an expected call of GetCategorizationModelByID.
+func (mr *MockRepositoryMockRecorder) GetCategorizationModelByID(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetCategorizationModelByID", reflect.TypeOf((*MockRepository)(nil).GetCategorizationModelByID), ctx, id)
+}
+
+// GetCategorizationModels mocks base method.
+func (m *MockRepository) GetCategorizationModels(ctx context.Context, modelType string, offset, limit int) ([]analytics.CategorizationModel, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetCategorizationModels", ctx, modelType, offset, limit)
+	ret0, _ := ret[0].([]analytics.CategorizationModel)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetCategorizationModels indicates an expected call of GetCategorizationModels.
+func (mr *MockRepositoryMockRecorder) GetCategorizationModels(ctx, modelType, offset, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetCategorizationModels", reflect.TypeOf((*MockRepository)(nil).GetCategorizationModels), ctx, modelType, offset, limit)
 }
 
 // GetCategorizationRuleByID mocks base method.
@@ -110,18 +256,33 @@ func (mr *MockRepositoryMockRecorder) GetCategorizationRuleByID(ctx, id interfac
 }
 
 // GetCategorizationRules mocks base method.
-func (m *MockRepository) GetCategorizationRules(ctx context.Context, offset, limit int) ([]analytics.CategorizationRule, error) {
+func (m *MockRepository) GetCategorizationRules(ctx context.Context, userID uuid.UUID, offset, limit int) ([]analytics.CategorizationRule, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetCategorizationRules", ctx, offset, limit)
+	ret := m.ctrl.Call(m, "GetCategorizationRules", ctx, userID, offset, limit)
 	ret0, _ := ret[0].([]analytics.CategorizationRule)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // GetCategorizationRules indicates an expected call of GetCategorizationRules.
-func (mr *MockRepositoryMockRecorder) GetCategorizationRules(ctx, offset, limit interface{}) *gomock.Call {
+func (mr *MockRepositoryMockRecorder) GetCategorizationRules(ctx, userID, offset, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetCategorizationRules", reflect.TypeOf((*MockRepository)(nil).GetCategorizationRules), ctx, userID, offset, limit)
+}
+
+// GetCategorizedTransactions mocks base method.
+func (m *MockRepository) GetCategorizedTransactions(ctx context.Context, limit int) ([]analytics.Transaction, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetCategorizedTransactions", ctx, limit)
+	ret0, _ := ret[0].([]analytics.Transaction)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetCategorizedTransactions indicates an expected call of GetCategorizedTransactions.
+func (mr *MockRepositoryMockRecorder) GetCategorizedTransactions(ctx, limit interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetCategorizationRules", reflect.TypeOf((*MockRepository)(nil).GetCategorizationRules), ctx, offset, limit)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetCategorizedTransactions", reflect.TypeOf((*MockRepository)(nil).GetCategorizedTransactions), ctx, limit)
 }
 
 // GetCategoryByID mocks base method.
@@ -139,19 +300,64 @@ func (mr *MockRepositoryMockRecorder) GetCategoryByID(ctx, id interface{}) *gomo
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetCategoryByID", reflect.TypeOf((*MockRepository)(nil).GetCategoryByID), ctx, id)
 }
 
-// GetSimilarTransactions mocks base method.
-func (m *MockRepository) GetSimilarTransactions(ctx context.Context, description string, limit int) ([]analytics.Transaction, error) {
+// GetFamilyIDsByUser mocks base method.
+func (m *MockRepository) GetFamilyIDsByUser(ctx context.Context, userID uuid.UUID) ([]uuid.UUID, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetFamilyIDsByUser", ctx, userID)
+	ret0, _ := ret[0].([]uuid.UUID)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetFamilyIDsByUser indicates an expected call of GetFamilyIDsByUser.
+func (mr *MockRepositoryMockRecorder) GetFamilyIDsByUser(ctx, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetFamilyIDsByUser", reflect.TypeOf((*MockRepository)(nil).GetFamilyIDsByUser), ctx, userID)
+}
+
+// GetMerchantTransactionsBefore mocks base method.
+func (m *MockRepository) GetMerchantTransactionsBefore(ctx context.Context, userID uuid.UUID, merchantIDs []uuid.UUID, merchants []string, before time.Time) ([]analytics.Transaction, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetSimilarTransactions", ctx, description, limit)
+	ret := m.ctrl.Call(m, "GetMerchantTransactionsBefore", ctx, userID, merchantIDs, merchants, before)
 	ret0, _ := ret[0].([]analytics.Transaction)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// GetSimilarTransactions indicates an expected call of GetSimilarTransactions.
-func (mr *MockRepositoryMockRecorder) GetSimilarTransactions(ctx, description, limit interface{}) *gomock.Call {
+// GetMerchantTransactionsBefore indicates an expected call of GetMerchantTransactionsBefore.
+func (mr *MockRepositoryMockRecorder) GetMerchantTransactionsBefore(ctx, userID, merchantIDs, merchants, before interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSimilarTransactions", reflect.TypeOf((*MockRepository)(nil).GetSimilarTransactions), ctx, description, limit)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetMerchantTransactionsBefore", reflect.TypeOf((*MockRepository)(nil).GetMerchantTransactionsBefore), ctx, userID, merchantIDs, merchants, before)
+}
+
+// GetRuleApplicationByID mocks base method.
+func (m *MockRepository) GetRuleApplicationByID(ctx context.Context, id uuid.UUID) (*analytics.RuleApplication, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetRuleApplicationByID", ctx, id)
+	ret0, _ := ret[0].(*analytics.RuleApplication)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetRuleApplicationByID indicates an expected call of GetRuleApplicationByID.
+func (mr *MockRepositoryMockRecorder) GetRuleApplicationByID(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetRuleApplicationByID", reflect.TypeOf((*MockRepository)(nil).GetRuleApplicationByID), ctx, id)
+}
+
+// GetRuleApplicationsByUser mocks base method.
+func (m *MockRepository) GetRuleApplicationsByUser(ctx context.Context, userID uuid.UUID, offset, limit int) ([]analytics.RuleApplication, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetRuleApplicationsByUser", ctx, userID, offset, limit)
+	ret0, _ := ret[0].([]analytics.RuleApplication)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetRuleApplicationsByUser indicates an expected call of GetRuleApplicationsByUser.
+func (mr *MockRepositoryMockRecorder) GetRuleApplicationsByUser(ctx, userID, offset, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetRuleApplicationsByUser", reflect.TypeOf((*MockRepository)(nil).GetRuleApplicationsByUser), ctx, userID, offset, limit)
 }
 
 // GetSpendingAnalysisByID mocks base method.
@@ -199,6 +405,35 @@ func (mr *MockRepositoryMockRecorder) GetTransactionsByPeriod(ctx, userID, start
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTransactionsByPeriod", reflect.TypeOf((*MockRepository)(nil).GetTransactionsByPeriod), ctx, userID, startDate, endDate)
 }
 
+// GetUserTimezone mocks base method.
+func (m *MockRepository) GetUserTimezone(ctx context.Context, userID uuid.UUID) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetUserTimezone", ctx, userID)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetUserTimezone indicates an expected call of GetUserTimezone.
+func (mr *MockRepositoryMockRecorder) GetUserTimezone(ctx, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserTimezone", reflect.TypeOf((*MockRepository)(nil).GetUserTimezone), ctx, userID)
+}
+
+// UndoRuleApplication mocks base method.
+func (m *MockRepository) UndoRuleApplication(ctx context.Context, application *analytics.RuleApplication) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UndoRuleApplication", ctx, application)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UndoRuleApplication indicates an expected call of UndoRuleApplication.
+func (mr *MockRepositoryMockRecorder) UndoRuleApplication(ctx, application interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UndoRuleApplication", reflect.TypeOf((*MockRepository)(nil).UndoRuleApplication), ctx, application)
+}
+
 // UpdateCategorizationRule mocks base method.
 func (m *MockRepository) UpdateCategorizationRule(ctx context.Context, rule *analytics.CategorizationRule) error {
 	m.ctrl.T.Helper()
@@ -11,26 +11,125 @@ type CategorizationModel struct {
 	ID        uuid.UUID `json:"id" gorm:"type:uuid;primary_key;default:gen_random_uuid()"`
 	Name      string    `json:"name" gorm:"not null"`
 	Version   string    `json:"version" gorm:"not null"`
-	ModelType string    `json:"model_type" gorm:"not null"` // "keyword", "mlp", "transformer"
+	ModelType string    `json:"model_type" gorm:"not null"` // "naive_bayes", "keyword", "mlp", "transformer"
 	Accuracy  float64   `json:"accuracy" gorm:"type:decimal(5,4)"`
 	IsActive  bool      `json:"is_active" gorm:"default:true"`
-	ModelData string    `json:"model_data" gorm:"type:jsonb"` // Serialized model
+	ModelData string    `json:"-" gorm:"type:jsonb"` // Serialized model
+	Metrics   string    `json:"-" gorm:"type:jsonb"` // Serialized ModelMetrics from evaluation
 	CreatedAt time.Time `json:"created_at"`
 	UpdatedAt time.Time `json:"updated_at"`
 }
 
-// CategorizationRule represents a rule-based categorization rule
+// Categorization model types
+const (
+	ModelTypeNaiveBayes = "naive_bayes"
+)
+
+// CategorizationRule represents a rule-based categorization rule. Rules without a user or
+// family apply to everyone
 type CategorizationRule struct {
-	ID          uuid.UUID `json:"id" gorm:"type:uuid;primary_key;default:gen_random_uuid()"`
-	CategoryID  uuid.UUID `json:"category_id" gorm:"type:uuid;not null"`
-	Pattern     string    `json:"pattern" gorm:"not null"`      // Regex pattern or keyword
-	PatternType string    `json:"pattern_type" gorm:"not null"` // "regex", "keyword", "exact"
-	Priority    int       `json:"priority" gorm:"default:0"`
-	IsActive    bool      `json:"is_active" gorm:"default:true"`
-	CreatedAt   time.Time `json:"created_at"`
-	UpdatedAt   time.Time `json:"updated_at"`
+	ID          uuid.UUID      `json:"id" gorm:"type:uuid;primary_key;default:gen_random_uuid()"`
+	UserID      *uuid.UUID     `json:"user_id,omitempty" gorm:"type:uuid;index"`
+	FamilyID    *uuid.UUID     `json:"family_id,omitempty" gorm:"type:uuid;index"`
+	CategoryID  uuid.UUID      `json:"category_id" gorm:"type:uuid;not null"`
+	Pattern     string         `json:"pattern" gorm:"not null"`      // Regex pattern or keyword, optional with conditions
+	PatternType string         `json:"pattern_type" gorm:"not null"` // "regex", "keyword", "exact"
+	Conditions  RuleConditions `json:"conditions" gorm:"type:jsonb"`
+	Actions     RuleActions    `json:"actions" gorm:"type:jsonb"`
+	Priority    int            `json:"priority" gorm:"default:0"`
+	IsActive    bool           `json:"is_active" gorm:"default:true"`
+	Locale      string         `json:"locale,omitempty" gorm:"index"` // Set on starter rules seeded for a locale
+	CreatedAt   time.Time      `json:"created_at"`
+	UpdatedAt   time.Time      `json:"updated_at"`
+}
+
+// RuleConditions are the conditions on a transaction a rule checks besides its pattern.
+// The pattern and each condition that is set are combined with the operator
+type RuleConditions struct {
+	Operator   string      `json:"operator,omitempty"`   // "and" (default) or "or"
+	MinAmount  *float64    `json:"min_amount,omitempty"` // Compared with the absolute amount
+	MaxAmount  *float64    `json:"max_amount,omitempty"`
+	Sign       string      `json:"sign,omitempty"` // "expense" for negative amounts, "income" for positive ones
+	AccountIDs []uuid.UUID `json:"account_ids,omitempty"`
+	Currencies []string    `json:"currencies,omitempty"`
+	DaysOfWeek []int       `json:"days_of_week,omitempty"` // 0 is Sunday
+	Merchants  []string    `json:"merchants,omitempty"`    // Matched case-insensitively against the whole merchant
+}
+
+// Operators combining the conditions of a rule
+const (
+	RuleOperatorAnd = "and"
+	RuleOperatorOr  = "or"
+)
+
+// Signs of the amounts a rule applies to
+const (
+	RuleSignExpense = "expense"
+	RuleSignIncome  = "income"
+)
+
+// RuleActions are what a rule does to a transaction besides setting its category
+type RuleActions struct {
+	Tags       []string `json:"tags,omitempty"`
+	Notes      string   `json:"notes,omitempty"`
+	IsTransfer bool     `json:"is_transfer,omitempty"`
+}
+
+// CategorizationFeedback records a user correcting the category a transaction was given
+// automatically, as a labeled example for categorization
+type CategorizationFeedback struct {
+	ID                 uuid.UUID  `json:"id" gorm:"type:uuid;primary_key;default:gen_random_uuid()"`
+	UserID             uuid.UUID  `json:"user_id" gorm:"type:uuid;not null;index"`
+	TransactionID      uuid.UUID  `json:"transaction_id" gorm:"type:uuid;not null"`
+	Description        string     `json:"description"`
+	Merchant           string     `json:"merchant"`
+	Amount             float64    `json:"amount" gorm:"type:decimal(15,2)"`
+	PreviousCategoryID *uuid.UUID `json:"previous_category_id,omitempty" gorm:"type:uuid"`
+	PreviousSource     string     `json:"previous_source"` // How the corrected category was set, "rule" or "ml"
+	CategoryID         uuid.UUID  `json:"category_id" gorm:"type:uuid;not null"`
+	CreatedAt          time.Time  `json:"created_at"`
+}
+
+// RuleApplication records a categorization rule being applied to a user's existing
+// transactions, so that it can be undone
+type RuleApplication struct {
+	ID                   uuid.UUID  `json:"id" gorm:"type:uuid;primary_key;default:gen_random_uuid()"`
+	UserID               uuid.UUID  `json:"user_id" gorm:"type:uuid;not null;index"`
+	RuleID               uuid.UUID  `json:"rule_id" gorm:"type:uuid;not null"`
+	CategoryID           uuid.UUID  `json:"category_id" gorm:"type:uuid;not null"`
+	Status               string     `json:"status" gorm:"not null"` // "completed", "undone"
+	StartDate            time.Time  `json:"start_date"`
+	EndDate              time.Time  `json:"end_date"`
+	Overwrite            bool       `json:"overwrite"`
+	TransactionsMatched  int        `json:"transactions_matched"`
+	TransactionsUpdated  int        `json:"transactions_updated"`
+	TransactionsRestored int        `json:"transactions_restored"`
+	UndoneAt             *time.Time `json:"undone_at,omitempty"`
+	CreatedAt            time.Time  `json:"created_at"`
+	UpdatedAt            time.Time  `json:"updated_at"`
+}
+
+// RuleApplicationChange holds what a transaction was like before a rule application
+// changed it
+type RuleApplicationChange struct {
+	ID                          uuid.UUID  `json:"id" gorm:"type:uuid;primary_key;default:gen_random_uuid()"`
+	ApplicationID               uuid.UUID  `json:"application_id" gorm:"type:uuid;not null;index"`
+	TransactionID               uuid.UUID  `json:"transaction_id" gorm:"type:uuid;not null"`
+	PreviousCategoryID          *uuid.UUID `json:"previous_category_id" gorm:"type:uuid"`
+	PreviousSuggestedCategoryID *uuid.UUID `json:"previous_suggested_category_id" gorm:"type:uuid"`
+	PreviousSource              string     `json:"previous_source"`
+	PreviousConfidence          *float64   `json:"previous_confidence"`
+	PreviousTags                []string   `json:"previous_tags" gorm:"type:text[]"`
+	PreviousNotes               string     `json:"previous_notes"`
+	PreviousIsTransfer          bool       `json:"previous_is_transfer"`
 }
 
+// Rule application statuses
+const (
+	RuleApplicationStatusCompleted = "completed"
+	RuleApplicationStatusUndone    = "undone"
+)
+
 // SpendingAnalysis represents spending analysis for a user
 type SpendingAnalysis struct {
 	ID                uuid.UUID `json:"id" gorm:"type:uuid;primary_key;default:gen_random_uuid()"`
@@ -53,6 +152,42 @@ type CategorizationRequest struct {
 	Merchant    string  `json:"merchant"`
 	Amount      float64 `json:"amount"`
 	Location    string  `json:"location"`
+	Locale      string  `json:"locale"` // Selects the starter rules seeded for this locale
+
+	// Rule conditions on these never match when they are not given
+	AccountID       *uuid.UUID `json:"account_id"`
+	Currency        string     `json:"currency"`
+	TransactionDate *time.Time `json:"transaction_date"`
+
+	// UserID personalizes the categorization with the user's corrections
+	UserID *uuid.UUID `json:"-"`
+}
+
+// BatchCategorizationRequest represents a request to categorize many transactions at once
+type BatchCategorizationRequest struct {
+	Transactions []CategorizationRequest `json:"transactions" binding:"required,dive"`
+
+	// UserID personalizes the categorization of every transaction with the user's rules
+	// and corrections
+	UserID *uuid.UUID `json:"-"`
+}
+
+// BatchCategorizationResponse represents the categorizations of a batch of transactions,
+// in the order they were requested
+type BatchCategorizationResponse struct {
+	Categorizations []CategorizationResponse `json:"categorizations"`
+}
+
+// CategoryCorrectionRequest represents a user correcting the category of a transaction
+// that was categorized automatically
+type CategoryCorrectionRequest struct {
+	TransactionID      uuid.UUID
+	Description        string
+	Merchant           string
+	Amount             float64
+	PreviousCategoryID *uuid.UUID
+	PreviousSource     string
+	CategoryID         uuid.UUID
 }
 
 // CategorizationResponse represents the categorization result
@@ -62,6 +197,8 @@ type CategorizationResponse struct {
 	Confidence            float64              `json:"confidence"`
 	CategorizationSource  string               `json:"categorization_source"` // "rule", "ml", "manual"
 	MatchedPattern        string               `json:"matched_pattern,omitempty"`
+	RuleID                *uuid.UUID           `json:"rule_id,omitempty"`
+	Actions               *RuleActions         `json:"actions,omitempty"` // Set by rules with actions
 	AlternativeCategories []CategorySuggestion `json:"alternative_categories,omitempty"`
 }
 
@@ -83,40 +220,115 @@ type SpendingInsight struct {
 	CreatedAt   time.Time              `json:"created_at"`
 }
 
+// RuleSuggestion represents a categorization rule proposed from a user's repeated
+// corrections of the category of a merchant's transactions
+type RuleSuggestion struct {
+	CategoryID   uuid.UUID `json:"category_id"`
+	CategoryName string    `json:"category_name"`
+	Pattern      string    `json:"pattern"`
+	PatternType  string    `json:"pattern_type"`
+	Corrections  int       `json:"corrections"`
+}
+
 // CreateCategorizationRuleRequest represents a request to create a categorization rule
 type CreateCategorizationRuleRequest struct {
-	CategoryID  uuid.UUID `json:"category_id" binding:"required"`
-	Pattern     string    `json:"pattern" binding:"required"`
-	PatternType string    `json:"pattern_type" binding:"required"`
-	Priority    int       `json:"priority"`
+	CategoryID  uuid.UUID      `json:"category_id" binding:"required"`
+	FamilyID    *uuid.UUID     `json:"family_id"` // Shares the rule with a family instead of owning it
+	Pattern     string         `json:"pattern"`
+	PatternType string         `json:"pattern_type"`
+	Conditions  RuleConditions `json:"conditions"`
+	Actions     RuleActions    `json:"actions"`
+	Priority    int            `json:"priority"`
 }
 
-// UpdateCategorizationRuleRequest represents a request to update a categorization rule
-type UpdateCategorizationRuleRequest struct {
-	Pattern     *string `json:"pattern"`
-	PatternType *string `json:"pattern_type"`
-	Priority    *int    `json:"priority"`
-	IsActive    *bool   `json:"is_active"`
+// TestCategorizationRuleRequest represents a draft categorization rule to run over a
+// user's transactions without saving it
+type TestCategorizationRuleRequest struct {
+	CreateCategorizationRuleRequest
+	Locale    string     `json:"locale"` // Selects the starter rules seeded for this locale
+	StartDate *time.Time `json:"start_date"`
+	EndDate   *time.Time `json:"end_date"`
 }
 
-// CategorizationRuleResponse represents a categorization rule response
-type CategorizationRuleResponse struct {
-	ID           uuid.UUID `json:"id"`
+// RuleTestResult represents the transactions a draft rule matches, and the rules that
+// would categorize some of them first
+type RuleTestResult struct {
+	StartDate           time.Time      `json:"start_date"`
+	EndDate             time.Time      `json:"end_date"`
+	TransactionsTested  int            `json:"transactions_tested"`
+	TransactionsMatched int            `json:"transactions_matched"`
+	Matches             []RuleMatch    `json:"matches"` // The most recent matches
+	Conflicts           []RuleConflict `json:"conflicts"`
+}
+
+// RuleMatch represents a transaction a draft rule matches
+type RuleMatch struct {
+	TransactionID     uuid.UUID  `json:"transaction_id"`
+	Description       string     `json:"description"`
+	Merchant          string     `json:"merchant"`
+	Amount            float64    `json:"amount"`
+	TransactionDate   time.Time  `json:"transaction_date"`
+	CurrentCategoryID *uuid.UUID `json:"current_category_id"`
+	ConflictingRuleID *uuid.UUID `json:"conflicting_rule_id,omitempty"` // Categorizes the transaction first
+}
+
+// RuleConflict represents a rule with a higher priority than a draft rule that
+// categorizes some of the transactions the draft matches differently
+type RuleConflict struct {
+	RuleID       uuid.UUID `json:"rule_id"`
 	CategoryID   uuid.UUID `json:"category_id"`
 	CategoryName string    `json:"category_name"`
 	Pattern      string    `json:"pattern"`
-	PatternType  string    `json:"pattern_type"`
 	Priority     int       `json:"priority"`
-	IsActive     bool      `json:"is_active"`
-	CreatedAt    time.Time `json:"created_at"`
-	UpdatedAt    time.Time `json:"updated_at"`
+	Transactions int       `json:"transactions"`
+}
+
+// ApplyCategorizationRuleRequest represents a request to apply a rule to a user's existing
+// transactions
+type ApplyCategorizationRuleRequest struct {
+	StartDate *time.Time `json:"start_date"`
+	EndDate   *time.Time `json:"end_date"`
+	Overwrite bool       `json:"overwrite"` // Also recategorizes transactions categorized automatically
+	Locale    string     `json:"locale"`    // Selects the starter rules seeded for this locale
+}
+
+// UpdateCategorizationRuleRequest represents a request to update a categorization rule
+type UpdateCategorizationRuleRequest struct {
+	Pattern     *string         `json:"pattern"`
+	PatternType *string         `json:"pattern_type"`
+	Conditions  *RuleConditions `json:"conditions"`
+	Actions     *RuleActions    `json:"actions"`
+	Priority    *int            `json:"priority"`
+	IsActive    *bool           `json:"is_active"`
+}
+
+// CategorizationRuleResponse represents a categorization rule response
+type CategorizationRuleResponse struct {
+	ID           uuid.UUID       `json:"id"`
+	UserID       *uuid.UUID      `json:"user_id,omitempty"`
+	FamilyID     *uuid.UUID      `json:"family_id,omitempty"`
+	CategoryID   uuid.UUID       `json:"category_id"`
+	CategoryName string          `json:"category_name"`
+	Pattern      string          `json:"pattern"`
+	PatternType  string          `json:"pattern_type"`
+	Conditions   *RuleConditions `json:"conditions,omitempty"`
+	Actions      *RuleActions    `json:"actions,omitempty"`
+	Priority     int             `json:"priority"`
+	IsActive     bool            `json:"is_active"`
+	CreatedAt    time.Time       `json:"created_at"`
+	UpdatedAt    time.Time       `json:"updated_at"`
 }
 
 // SpendingAnalysisRequest represents a request for spending analysis
 type SpendingAnalysisRequest struct {
 	StartDate time.Time `json:"start_date" binding:"required"`
 	EndDate   time.Time `json:"end_date" binding:"required"`
-	GroupBy   string    `json:"group_by"` // "day", "week", "month", "category"
+	GroupBy   string    `json:"group_by"` // Spending trend buckets: "day", "week", "month" (default), "quarter" or "year"
+	// Timezone the trend buckets follow, defaulting to the user's timezone
+	Timezone string `json:"timezone"`
+	// CategoryLevel rolls spending up to the ancestor category at this depth (0 for
+	// top-level categories). Spending is reported per category when unset
+	CategoryLevel *int `json:"category_level"`
 }
 
 // SpendingAnalysisResponse represents a spending analysis response
@@ -128,7 +340,9 @@ type SpendingAnalysisResponse struct {
 	NetAmount         float64            `json:"net_amount"`
 	CategoryBreakdown []CategorySpending `json:"category_breakdown"`
 	TopCategories     []CategorySpending `json:"top_categories"`
+	TopMerchants      []MerchantSpending `json:"top_merchants"`
 	SpendingTrends    []SpendingTrend    `json:"spending_trends"`
+	GoalContributions []GoalContribution `json:"goal_contributions,omitempty"`
 	Insights          []SpendingInsight  `json:"insights"`
 }
 
@@ -141,12 +355,180 @@ type CategorySpending struct {
 	TransactionCount int       `json:"transaction_count"`
 }
 
-// SpendingTrend represents a spending trend
-type SpendingTrend struct {
-	Period string  `json:"period"`
+// MerchantSpending represents spending at a merchant
+type MerchantSpending struct {
+	MerchantID       *uuid.UUID               `json:"merchant_id"`
+	MerchantName     string                   `json:"merchant_name"`
+	Amount           float64                  `json:"amount"`
+	Percentage       float64                  `json:"percentage"`
+	TransactionCount int                      `json:"transaction_count"`
+	SpendOverTime    []MerchantPeriodSpending `json:"spend_over_time"`
+}
+
+// MerchantPeriodSpending represents spending at a merchant within a calendar month
+type MerchantPeriodSpending struct {
+	Period string  `json:"period"` // "2006-01"
 	Amount float64 `json:"amount"`
-	Change float64 `json:"change"` // Percentage change from previous period
-	Trend  string  `json:"trend"`  // "increasing", "decreasing", "stable"
+}
+
+// GoalContribution represents the money put towards a savings goal within the analyzed period
+type GoalContribution struct {
+	GoalID           uuid.UUID `json:"goal_id"`
+	GoalName         string    `json:"goal_name"`
+	Amount           float64   `json:"amount"`
+	TransactionCount int       `json:"transaction_count"`
+}
+
+// SpendingTrend represents the spending within a period of a spending analysis
+type SpendingTrend struct {
+	Period           string    `json:"period"` // "2006-01-02", "2006-W01", "2006-01", "2006-Q1" or "2006"
+	StartDate        time.Time `json:"start_date"`
+	EndDate          time.Time `json:"end_date"`
+	Amount           float64   `json:"amount"`
+	TransactionCount int       `json:"transaction_count"`
+	Change           float64   `json:"change"` // Percentage change from previous period
+	Trend            string    `json:"trend"`  // "increasing", "decreasing", "stable"
+}
+
+// Spending trend groupings
+const (
+	GroupByDay     = "day"
+	GroupByWeek    = "week" // ISO weeks, starting on Monday
+	GroupByMonth   = "month"
+	GroupByQuarter = "quarter"
+	GroupByYear    = "year"
+)
+
+// Spending trend classifications
+const (
+	TrendIncreasing = "increasing"
+	TrendDecreasing = "decreasing"
+	TrendStable     = "stable"
+)
+
+// CashFlowForecastRequest represents a request for a cash flow forecast
+type CashFlowForecastRequest struct {
+	Days int `form:"days"` // Number of days to project, 90 by default
+	// LowBalanceThreshold is the balance below which a low balance warning is raised
+	LowBalanceThreshold float64 `form:"low_balance_threshold"`
+}
+
+// CashFlowForecast represents the projected daily balances of a user's accounts. Balances
+// are totaled per currency, without conversion between currencies
+type CashFlowForecast struct {
+	StartDate           time.Time                  `json:"start_date"`
+	EndDate             time.Time                  `json:"end_date"`
+	LowBalanceThreshold float64                    `json:"low_balance_threshold"`
+	Totals              []CurrencyCashFlowForecast `json:"totals"` // Sorted by currency
+	Accounts            []AccountCashFlowForecast  `json:"accounts"`
+}
+
+// CurrencyCashFlowForecast represents the projected total balance of the accounts in a currency
+type CurrencyCashFlowForecast struct {
+	Currency       string              `json:"currency"`
+	CurrentBalance float64             `json:"current_balance"`
+	LowBalanceDate *time.Time          `json:"low_balance_date"` // First day the projected total falls below the threshold
+	Balances       []BalanceProjection `json:"balances"`
+}
+
+// AccountCashFlowForecast represents the projected daily balances of an account
+type AccountCashFlowForecast struct {
+	AccountID          uuid.UUID           `json:"account_id"`
+	AccountName        string              `json:"account_name"`
+	Currency           string              `json:"currency"`
+	CurrentBalance     float64             `json:"current_balance"`
+	DailyDiscretionary float64             `json:"daily_discretionary"` // Average daily spending outside recurring expenses
+	Recurring          []RecurringCashFlow `json:"recurring"`
+	LowBalanceDate     *time.Time          `json:"low_balance_date"`
+	Balances           []BalanceProjection `json:"balances"`
+}
+
+// RecurringCashFlow represents income or an expense expected to repeat
+type RecurringCashFlow struct {
+	Name      string    `json:"name"`
+	Amount    float64   `json:"amount"` // Negative for expenses
+	Frequency string    `json:"frequency"`
+	NextDate  time.Time `json:"next_date"`
+}
+
+// BalanceProjection represents the balance expected at the end of a day, with the bounds
+// of its confidence band
+type BalanceProjection struct {
+	Date    time.Time `json:"date"`
+	Balance float64   `json:"balance"`
+	Lower   float64   `json:"lower"`
+	Upper   float64   `json:"upper"`
+}
+
+// Recurring cash flow frequencies
+const (
+	FrequencyWeekly   = "weekly"
+	FrequencyBiweekly = "biweekly"
+	FrequencyMonthly  = "monthly"
+)
+
+// CategorizationModelResponse represents a version of a categorization model
+type CategorizationModelResponse struct {
+	ID        uuid.UUID     `json:"id"`
+	Name      string        `json:"name"`
+	Version   string        `json:"version"`
+	ModelType string        `json:"model_type"`
+	Accuracy  float64       `json:"accuracy"`
+	IsActive  bool          `json:"is_active"`
+	Metrics   *ModelMetrics `json:"metrics,omitempty"`
+	CreatedAt time.Time     `json:"created_at"`
+}
+
+// ModelMetrics represents how well a categorization model categorized the transactions
+// held out from its training
+type ModelMetrics struct {
+	Accuracy           float64                `json:"accuracy"`
+	TrainingExamples   int                    `json:"training_examples"`
+	EvaluationExamples int                    `json:"evaluation_examples"`
+	Categories         []CategoryMetrics      `json:"categories"`
+	ConfusionMatrix    []ConfusionMatrixEntry `json:"confusion_matrix"`
+}
+
+// CategoryMetrics represents the precision and recall of a model for a category
+type CategoryMetrics struct {
+	CategoryID uuid.UUID `json:"category_id"`
+	Precision  float64   `json:"precision"`
+	Recall     float64   `json:"recall"`
+	Support    int       `json:"support"` // Number of evaluation examples in the category
+}
+
+// ConfusionMatrixEntry counts the evaluation examples of a category that were
+// categorized as another, or the same, category
+type ConfusionMatrixEntry struct {
+	ActualCategoryID    uuid.UUID `json:"actual_category_id"`
+	PredictedCategoryID uuid.UUID `json:"predicted_category_id"`
+	Count               int       `json:"count"`
+}
+
+// ModelTrainingResult represents the outcome of training a categorization model
+type ModelTrainingResult struct {
+	Model     CategorizationModelResponse `json:"model"`
+	Activated bool                        `json:"activated"`
+	// Accuracy of the previously active model on the same evaluation examples
+	PreviousModelID  *uuid.UUID `json:"previous_model_id,omitempty"`
+	PreviousAccuracy *float64   `json:"previous_accuracy,omitempty"`
+}
+
+// ModelComparison represents the difference in metrics between two model versions
+type ModelComparison struct {
+	Base           CategorizationModelResponse `json:"base"`
+	Candidate      CategorizationModelResponse `json:"candidate"`
+	AccuracyChange float64                     `json:"accuracy_change"`
+	Categories     []CategoryMetricsComparison `json:"categories"`
+}
+
+// CategoryMetricsComparison compares the metrics of two models for a category
+type CategoryMetricsComparison struct {
+	CategoryID         uuid.UUID `json:"category_id"`
+	BasePrecision      float64   `json:"base_precision"`
+	CandidatePrecision float64   `json:"candidate_precision"`
+	BaseRecall         float64   `json:"base_recall"`
+	CandidateRecall    float64   `json:"candidate_recall"`
 }
 
 // TableName specifies the table name for CategorizationModel
@@ -159,6 +541,21 @@ func (CategorizationRule) TableName() string {
 	return "categorization_rules"
 }
 
+// TableName specifies the table name for CategorizationFeedback
+func (CategorizationFeedback) TableName() string {
+	return "categorization_feedback"
+}
+
+// TableName specifies the table name for RuleApplication
+func (RuleApplication) TableName() string {
+	return "rule_applications"
+}
+
+// TableName specifies the table name for RuleApplicationChange
+func (RuleApplicationChange) TableName() string {
+	return "rule_application_changes"
+}
+
 // TableName specifies the table name for SpendingAnalysis
 func (SpendingAnalysis) TableName() string {
 	return "spending_analyses"
@@ -0,0 +1,37 @@
+package analytics
+
+import (
+	"context"
+	"time"
+)
+
+// ModelTrainer retrains the categorization model on a schedule, so that it keeps up with
+// new categorized transactions
+type ModelTrainer struct {
+	service  Service
+	interval time.Duration
+}
+
+// NewModelTrainer creates a trainer retraining the categorization model at an interval
+func NewModelTrainer(service Service, interval time.Duration) *ModelTrainer {
+	return &ModelTrainer{service: service, interval: interval}
+}
+
+// Start retrains the model in the background every interval until the context is done.
+// Failed runs, such as without enough categorized transactions yet, are retried at the
+// next interval
+func (t *ModelTrainer) Start(ctx context.Context) {
+	go func() {
+		ticker := time.NewTicker(t.interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				_, _ = t.service.TrainCategorizationModel(ctx)
+			}
+		}
+	}()
+}
@@ -2,6 +2,7 @@ package analytics
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"time"
 
@@ -14,17 +15,45 @@ type Repository interface {
 	// Categorization rule operations
 	CreateCategorizationRule(ctx context.Context, rule *CategorizationRule) error
 	GetCategorizationRuleByID(ctx context.Context, id uuid.UUID) (*CategorizationRule, error)
-	GetCategorizationRules(ctx context.Context, offset, limit int) ([]CategorizationRule, error)
-	GetActiveCategorizationRules(ctx context.Context) ([]CategorizationRule, error)
+	GetCategorizationRules(ctx context.Context, userID uuid.UUID, offset, limit int) ([]CategorizationRule, error)
+	GetActiveCategorizationRules(ctx context.Context, userID *uuid.UUID) ([]CategorizationRule, error)
 	UpdateCategorizationRule(ctx context.Context, rule *CategorizationRule) error
 	DeleteCategorizationRule(ctx context.Context, id uuid.UUID) error
 
+	// Categorization feedback operations
+	CreateCategorizationFeedback(ctx context.Context, feedback *CategorizationFeedback) error
+	GetCategorizationFeedbackByUser(ctx context.Context, userID uuid.UUID, limit int) ([]CategorizationFeedback, error)
+
+	// Rule application operations
+	CreateRuleApplication(ctx context.Context, application *RuleApplication, changes []RuleApplicationChange, transactions []Transaction) error
+	GetRuleApplicationByID(ctx context.Context, id uuid.UUID) (*RuleApplication, error)
+	GetRuleApplicationsByUser(ctx context.Context, userID uuid.UUID, offset, limit int) ([]RuleApplication, error)
+	UndoRuleApplication(ctx context.Context, application *RuleApplication) error
+
+	// Categorization model operations
+	CreateCategorizationModel(ctx context.Context, model *CategorizationModel) error
+	GetCategorizationModelByID(ctx context.Context, id uuid.UUID) (*CategorizationModel, error)
+	GetCategorizationModels(ctx context.Context, modelType string, offset, limit int) ([]CategorizationModel, error)
+	GetActiveCategorizationModel(ctx context.Context, modelType string) (*CategorizationModel, error)
+	ActivateCategorizationModel(ctx context.Context, id uuid.UUID) error
+
 	// Category operations
 	GetCategoryByID(ctx context.Context, id uuid.UUID) (*Category, error)
 
 	// Transaction operations for ML
-	GetSimilarTransactions(ctx context.Context, description string, limit int) ([]Transaction, error)
+	GetCategorizedTransactions(ctx context.Context, limit int) ([]Transaction, error)
 	GetTransactionsByPeriod(ctx context.Context, userID uuid.UUID, startDate, endDate time.Time) ([]Transaction, error)
+	GetMerchantTransactionsBefore(ctx context.Context, userID uuid.UUID, merchantIDs []uuid.UUID, merchants []string, before time.Time) ([]Transaction, error)
+
+	// Goal operations
+	GetActiveGoalsByUser(ctx context.Context, userID uuid.UUID) ([]Goal, error)
+
+	// User operations
+	GetUserTimezone(ctx context.Context, userID uuid.UUID) (string, error)
+	GetFamilyIDsByUser(ctx context.Context, userID uuid.UUID) ([]uuid.UUID, error)
+
+	// Account operations
+	GetActiveAccountsByUser(ctx context.Context, userID uuid.UUID) ([]Account, error)
 
 	// Spending analysis operations
 	CreateSpendingAnalysis(ctx context.Context, analysis *SpendingAnalysis) error
@@ -63,10 +92,11 @@ func (r *repository) GetCategorizationRuleByID(ctx context.Context, id uuid.UUID
 	return &rule, nil
 }
 
-// GetCategorizationRules retrieves categorization rules with pagination
-func (r *repository) GetCategorizationRules(ctx context.Context, offset, limit int) ([]CategorizationRule, error) {
+// GetCategorizationRules retrieves the categorization rules that apply to a user with
+// pagination: their own, their families' and those that apply to everyone
+func (r *repository) GetCategorizationRules(ctx context.Context, userID uuid.UUID, offset, limit int) ([]CategorizationRule, error) {
 	var rules []CategorizationRule
-	err := r.db.WithContext(ctx).
+	err := r.visibleRules(ctx, &userID).
 		Order("priority DESC, created_at DESC").
 		Offset(offset).
 		Limit(limit).
@@ -79,10 +109,11 @@ func (r *repository) GetCategorizationRules(ctx context.Context, offset, limit i
 	return rules, nil
 }
 
-// GetActiveCategorizationRules retrieves all active categorization rules
-func (r *repository) GetActiveCategorizationRules(ctx context.Context) ([]CategorizationRule, error) {
+// GetActiveCategorizationRules retrieves the active categorization rules that apply to a
+// user, or only those that apply to everyone without a user
+func (r *repository) GetActiveCategorizationRules(ctx context.Context, userID *uuid.UUID) ([]CategorizationRule, error) {
 	var rules []CategorizationRule
-	err := r.db.WithContext(ctx).
+	err := r.visibleRules(ctx, userID).
 		Where("is_active = ?", true).
 		Order("priority DESC, created_at DESC").
 		Find(&rules).Error
@@ -94,6 +125,17 @@ func (r *repository) GetActiveCategorizationRules(ctx context.Context) ([]Catego
 	return rules, nil
 }
 
+// visibleRules scopes a query to the categorization rules that apply to a user
+func (r *repository) visibleRules(ctx context.Context, userID *uuid.UUID) *gorm.DB {
+	db := r.db.WithContext(ctx)
+	if userID == nil {
+		return db.Where("user_id IS NULL AND family_id IS NULL")
+	}
+
+	families := r.db.Table("family_members").Select("family_id").Where("user_id = ?", *userID)
+	return db.Where("((user_id IS NULL AND family_id IS NULL) OR user_id = ? OR family_id IN (?))", *userID, families)
+}
+
 // UpdateCategorizationRule updates a categorization rule
 func (r *repository) UpdateCategorizationRule(ctx context.Context, rule *CategorizationRule) error {
 	rule.UpdatedAt = time.Now()
@@ -124,34 +166,287 @@ func (r *repository) DeleteCategorizationRule(ctx context.Context, id uuid.UUID)
 	return nil
 }
 
+// CreateCategorizationFeedback records a user's category correction
+func (r *repository) CreateCategorizationFeedback(ctx context.Context, feedback *CategorizationFeedback) error {
+	if err := r.db.WithContext(ctx).Create(feedback).Error; err != nil {
+		return fmt.Errorf("failed to create categorization feedback: %w", err)
+	}
+	return nil
+}
+
+// GetCategorizationFeedbackByUser retrieves a user's most recent category corrections
+func (r *repository) GetCategorizationFeedbackByUser(ctx context.Context, userID uuid.UUID, limit int) ([]CategorizationFeedback, error) {
+	var feedback []CategorizationFeedback
+	err := r.db.WithContext(ctx).
+		Where("user_id = ?", userID).
+		Order("created_at DESC").
+		Limit(limit).
+		Find(&feedback).Error
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to get categorization feedback: %w", err)
+	}
+
+	return feedback, nil
+}
+
+// CreateRuleApplication records a rule application and saves the transactions it changed
+// in a single database transaction
+func (r *repository) CreateRuleApplication(ctx context.Context, application *RuleApplication, changes []RuleApplicationChange, transactions []Transaction) error {
+	now := time.Now()
+	application.CreatedAt = now
+	application.UpdatedAt = now
+
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(application).Error; err != nil {
+			return fmt.Errorf("failed to create rule application: %w", err)
+		}
+
+		for i := range changes {
+			changes[i].ApplicationID = application.ID
+		}
+		if len(changes) > 0 {
+			if err := tx.CreateInBatches(changes, 500).Error; err != nil {
+				return fmt.Errorf("failed to create rule application changes: %w", err)
+			}
+		}
+
+		for _, transaction := range transactions {
+			err := tx.Model(&Transaction{}).Where("id = ?", transaction.ID).Updates(map[string]interface{}{
+				"category_id":               transaction.CategoryID,
+				"suggested_category_id":     transaction.SuggestedCategoryID,
+				"categorization_source":     transaction.CategorizationSource,
+				"categorization_confidence": transaction.CategorizationConfidence,
+				"tags":                      transaction.Tags,
+				"notes":                     transaction.Notes,
+				"is_transfer":               transaction.IsTransfer,
+				"updated_at":                now,
+			}).Error
+			if err != nil {
+				return fmt.Errorf("failed to update transaction: %w", err)
+			}
+		}
+		return nil
+	})
+}
+
+// GetRuleApplicationByID retrieves a rule application by ID
+func (r *repository) GetRuleApplicationByID(ctx context.Context, id uuid.UUID) (*RuleApplication, error) {
+	var application RuleApplication
+	err := r.db.WithContext(ctx).Where("id = ?", id).First(&application).Error
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, ErrRuleApplicationNotFound
+		}
+		return nil, fmt.Errorf("failed to get rule application: %w", err)
+	}
+	return &application, nil
+}
+
+// GetRuleApplicationsByUser retrieves a user's rule applications with pagination
+func (r *repository) GetRuleApplicationsByUser(ctx context.Context, userID uuid.UUID, offset, limit int) ([]RuleApplication, error) {
+	var applications []RuleApplication
+	err := r.db.WithContext(ctx).
+		Where("user_id = ?", userID).
+		Order("created_at DESC").
+		Offset(offset).
+		Limit(limit).
+		Find(&applications).Error
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to get rule applications: %w", err)
+	}
+
+	return applications, nil
+}
+
+// UndoRuleApplication restores the transactions a rule application changed and marks it
+// undone in a single database transaction. Transactions recategorized since are left alone
+func (r *repository) UndoRuleApplication(ctx context.Context, application *RuleApplication) error {
+	now := time.Now()
+
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var changes []RuleApplicationChange
+		if err := tx.Where("application_id = ?", application.ID).Find(&changes).Error; err != nil {
+			return fmt.Errorf("failed to get rule application changes: %w", err)
+		}
+
+		restored := 0
+		for _, change := range changes {
+			result := tx.Model(&Transaction{}).
+				Where("id = ? AND category_id = ? AND categorization_source = ?", change.TransactionID, application.CategoryID, "rule").
+				Updates(map[string]interface{}{
+					"category_id":               change.PreviousCategoryID,
+					"suggested_category_id":     change.PreviousSuggestedCategoryID,
+					"categorization_source":     change.PreviousSource,
+					"categorization_confidence": change.PreviousConfidence,
+					"tags":                      change.PreviousTags,
+					"notes":                     change.PreviousNotes,
+					"is_transfer":               change.PreviousIsTransfer,
+					"updated_at":                now,
+				})
+			if result.Error != nil {
+				return fmt.Errorf("failed to restore transaction: %w", result.Error)
+			}
+			restored += int(result.RowsAffected)
+		}
+
+		// Only a completed application is undone, so concurrent undos can't both restore
+		result := tx.Model(&RuleApplication{}).
+			Where("id = ? AND status = ?", application.ID, RuleApplicationStatusCompleted).
+			Updates(map[string]interface{}{
+				"status":                RuleApplicationStatusUndone,
+				"transactions_restored": restored,
+				"undone_at":             now,
+				"updated_at":            now,
+			})
+		if result.Error != nil {
+			return fmt.Errorf("failed to update rule application: %w", result.Error)
+		}
+		if result.RowsAffected == 0 {
+			return ErrRuleApplicationUndone
+		}
+
+		application.Status = RuleApplicationStatusUndone
+		application.TransactionsRestored = restored
+		application.UndoneAt = &now
+		application.UpdatedAt = now
+		return nil
+	})
+}
+
+// CreateCategorizationModel stores a new version of a categorization model. Models are
+// stored inactive unless they are created active
+func (r *repository) CreateCategorizationModel(ctx context.Context, model *CategorizationModel) error {
+	model.CreatedAt = time.Now()
+	model.UpdatedAt = time.Now()
+
+	// is_active defaults to true in the database, so false has to be written explicitly
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(model).Error; err != nil {
+			return err
+		}
+		if !model.IsActive {
+			return tx.Model(model).Update("is_active", false).Error
+		}
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create categorization model: %w", err)
+	}
+
+	return nil
+}
+
+// GetCategorizationModelByID retrieves a categorization model by ID, without its model data
+func (r *repository) GetCategorizationModelByID(ctx context.Context, id uuid.UUID) (*CategorizationModel, error) {
+	var model CategorizationModel
+	err := r.db.WithContext(ctx).Omit("model_data").Where("id = ?", id).First(&model).Error
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, fmt.Errorf("categorization model not found: %w", err)
+		}
+		return nil, fmt.Errorf("failed to get categorization model: %w", err)
+	}
+	return &model, nil
+}
+
+// GetCategorizationModels retrieves the versions of a model type with pagination, newest
+// first and without their model data
+func (r *repository) GetCategorizationModels(ctx context.Context, modelType string, offset, limit int) ([]CategorizationModel, error) {
+	var models []CategorizationModel
+	err := r.db.WithContext(ctx).
+		Omit("model_data").
+		Where("model_type = ?", modelType).
+		Order("created_at DESC").
+		Offset(offset).
+		Limit(limit).
+		Find(&models).Error
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to get categorization models: %w", err)
+	}
+
+	return models, nil
+}
+
+// GetActiveCategorizationModel retrieves the active model of a type, or nil when there is none
+func (r *repository) GetActiveCategorizationModel(ctx context.Context, modelType string) (*CategorizationModel, error) {
+	var models []CategorizationModel
+	err := r.db.WithContext(ctx).
+		Where("model_type = ? AND is_active = ?", modelType, true).
+		Order("created_at DESC").
+		Limit(1).
+		Find(&models).Error
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to get active categorization model: %w", err)
+	}
+	if len(models) == 0 {
+		return nil, nil
+	}
+
+	return &models[0], nil
+}
+
+// ActivateCategorizationModel makes a model the only active model of its type
+func (r *repository) ActivateCategorizationModel(ctx context.Context, id uuid.UUID) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var model CategorizationModel
+		if err := tx.Select("id", "model_type").Where("id = ?", id).First(&model).Error; err != nil {
+			if err == gorm.ErrRecordNotFound {
+				return fmt.Errorf("categorization model not found: %w", err)
+			}
+			return fmt.Errorf("failed to get categorization model: %w", err)
+		}
+
+		now := time.Now()
+		if err := tx.Model(&CategorizationModel{}).
+			Where("model_type = ? AND id <> ?", model.ModelType, id).
+			Updates(map[string]interface{}{"is_active": false, "updated_at": now}).Error; err != nil {
+			return fmt.Errorf("failed to deactivate categorization models: %w", err)
+		}
+		if err := tx.Model(&CategorizationModel{}).
+			Where("id = ?", id).
+			Updates(map[string]interface{}{"is_active": true, "updated_at": now}).Error; err != nil {
+			return fmt.Errorf("failed to activate categorization model: %w", err)
+		}
+		return nil
+	})
+}
+
+// ErrCategoryNotFound is returned for categories that don't exist
+var ErrCategoryNotFound = errors.New("category not found")
+
 // GetCategoryByID retrieves a category by ID
 func (r *repository) GetCategoryByID(ctx context.Context, id uuid.UUID) (*Category, error) {
 	var category Category
 	err := r.db.WithContext(ctx).Where("id = ?", id).First(&category).Error
 	if err != nil {
 		if err == gorm.ErrRecordNotFound {
-			return nil, fmt.Errorf("category not found: %w", err)
+			return nil, fmt.Errorf("%w: %w", ErrCategoryNotFound, err)
 		}
 		return nil, fmt.Errorf("failed to get category: %w", err)
 	}
 	return &category, nil
 }
 
-// GetSimilarTransactions retrieves transactions similar to the given description
-func (r *repository) GetSimilarTransactions(ctx context.Context, description string, limit int) ([]Transaction, error) {
+// GetCategorizedTransactions retrieves the most recent transactions with a category that
+// wasn't suggested by a model, to train categorization models on. Only system categories
+// are included, since the model is shared by every user
+func (r *repository) GetCategorizedTransactions(ctx context.Context, limit int) ([]Transaction, error) {
 	var transactions []Transaction
 
-	// Simple similarity search using LIKE
-	searchTerm := "%" + description + "%"
-
 	err := r.db.WithContext(ctx).
-		Where("description ILIKE ? OR merchant ILIKE ?", searchTerm, searchTerm).
-		Order("created_at DESC").
+		Select("transactions.*").
+		Joins("JOIN categories ON categories.id = transactions.category_id AND categories.user_id IS NULL AND categories.family_id IS NULL").
+		Where("transactions.categorization_source <> ? AND transactions.status <> ?", "ml", "cancelled").
+		Order("transactions.transaction_date DESC").
 		Limit(limit).
 		Find(&transactions).Error
 
 	if err != nil {
-		return nil, fmt.Errorf("failed to get similar transactions: %w", err)
+		return nil, fmt.Errorf("failed to get categorized transactions: %w", err)
 	}
 
 	return transactions, nil
@@ -173,6 +468,33 @@ func (r *repository) GetTransactionsByPeriod(ctx context.Context, userID uuid.UU
 	return transactions, nil
 }
 
+// GetMerchantTransactionsBefore retrieves the merchant of a user's transactions before a date
+// at the given merchants, once per merchant. Merchants without an ID are matched by name
+func (r *repository) GetMerchantTransactionsBefore(ctx context.Context, userID uuid.UUID, merchantIDs []uuid.UUID, merchants []string, before time.Time) ([]Transaction, error) {
+	var transactions []Transaction
+	if len(merchantIDs) == 0 && len(merchants) == 0 {
+		return transactions, nil
+	}
+
+	query := r.db.WithContext(ctx).
+		Model(&Transaction{}).
+		Distinct("merchant_id", "merchant").
+		Where("user_id = ? AND transaction_date < ?", userID, before)
+	switch {
+	case len(merchantIDs) > 0 && len(merchants) > 0:
+		query = query.Where("(merchant_id IN ? OR (merchant_id IS NULL AND merchant IN ?))", merchantIDs, merchants)
+	case len(merchantIDs) > 0:
+		query = query.Where("merchant_id IN ?", merchantIDs)
+	default:
+		query = query.Where("merchant_id IS NULL AND merchant IN ?", merchants)
+	}
+
+	if err := query.Find(&transactions).Error; err != nil {
+		return nil, fmt.Errorf("failed to get merchant transactions: %w", err)
+	}
+	return transactions, nil
+}
+
 // CreateSpendingAnalysis creates a new spending analysis
 func (r *repository) CreateSpendingAnalysis(ctx context.Context, analysis *SpendingAnalysis) error {
 	analysis.CreatedAt = time.Now()
@@ -211,9 +533,71 @@ func (r *repository) GetSpendingAnalysisByUser(ctx context.Context, userID uuid.
 	return &analysis, nil
 }
 
+// GetActiveGoalsByUser retrieves the active savings goals of a user with their linked accounts
+func (r *repository) GetActiveGoalsByUser(ctx context.Context, userID uuid.UUID) ([]Goal, error) {
+	var goals []Goal
+	err := r.db.WithContext(ctx).
+		Preload("Accounts").
+		Where("user_id = ? AND is_active = ?", userID, true).
+		Order("created_at ASC").
+		Find(&goals).Error
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to get goals: %w", err)
+	}
+
+	return goals, nil
+}
+
+// GetUserTimezone retrieves the timezone of a user's profile
+func (r *repository) GetUserTimezone(ctx context.Context, userID uuid.UUID) (string, error) {
+	var timezones []string
+	err := r.db.WithContext(ctx).
+		Table("users").
+		Where("id = ?", userID).
+		Pluck("timezone", &timezones).Error
+	if err != nil {
+		return "", fmt.Errorf("failed to get user timezone: %w", err)
+	}
+	if len(timezones) == 0 {
+		return "", nil
+	}
+	return timezones[0], nil
+}
+
+// GetFamilyIDsByUser retrieves the IDs of the families a user belongs to
+func (r *repository) GetFamilyIDsByUser(ctx context.Context, userID uuid.UUID) ([]uuid.UUID, error) {
+	var familyIDs []uuid.UUID
+	err := r.db.WithContext(ctx).
+		Table("family_members").
+		Where("user_id = ?", userID).
+		Pluck("family_id", &familyIDs).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to get family IDs: %w", err)
+	}
+	return familyIDs, nil
+}
+
+// GetActiveAccountsByUser retrieves the active accounts of a user
+func (r *repository) GetActiveAccountsByUser(ctx context.Context, userID uuid.UUID) ([]Account, error) {
+	var accounts []Account
+	err := r.db.WithContext(ctx).
+		Where("user_id = ? AND is_active = ?", userID, true).
+		Order("created_at ASC").
+		Find(&accounts).Error
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to get accounts: %w", err)
+	}
+
+	return accounts, nil
+}
+
 // Category represents a transaction category (imported from transaction domain)
 type Category struct {
 	ID          uuid.UUID  `json:"id" gorm:"type:uuid;primary_key;default:gen_random_uuid()"`
+	UserID      *uuid.UUID `json:"user_id" gorm:"type:uuid"`   // Owner of a user category
+	FamilyID    *uuid.UUID `json:"family_id" gorm:"type:uuid"` // Owner of a family category
 	Name        string     `json:"name" gorm:"not null"`
 	Description string     `json:"description"`
 	Icon        string     `json:"icon"`
@@ -233,28 +617,62 @@ type Transaction struct {
 	FamilyID   *uuid.UUID `json:"family_id" gorm:"type:uuid"`
 	AccountID  uuid.UUID  `json:"account_id" gorm:"type:uuid;not null"`
 	CategoryID *uuid.UUID `json:"category_id" gorm:"type:uuid"`
+	MerchantID *uuid.UUID `json:"merchant_id" gorm:"type:uuid"`
 
 	Amount      float64 `json:"amount" gorm:"type:decimal(15,2);not null"`
 	Currency    string  `json:"currency" gorm:"default:'USD'"`
 	Description string  `json:"description" gorm:"not null"`
 	Merchant    string  `json:"merchant"`
+	RawMerchant string  `json:"raw_merchant"`
 	Location    string  `json:"location" gorm:"type:jsonb"`
 
 	TransactionDate time.Time  `json:"transaction_date" gorm:"not null"`
 	PostedDate      *time.Time `json:"posted_date"`
 	Status          string     `json:"status" gorm:"default:'pending'"`
 
-	CategorizationSource     string   `json:"categorization_source" gorm:"default:'manual'"`
-	CategorizationConfidence *float64 `json:"categorization_confidence"`
+	CategorizationSource     string     `json:"categorization_source" gorm:"default:'manual'"`
+	CategorizationConfidence *float64   `json:"categorization_confidence"`
+	SuggestedCategoryID      *uuid.UUID `json:"suggested_category_id" gorm:"type:uuid"`
 
 	Tags       []string `json:"tags" gorm:"type:text[]"`
 	Notes      string   `json:"notes"`
 	ReceiptURL string   `json:"receipt_url"`
+	IsTransfer bool     `json:"is_transfer" gorm:"default:false"`
 
 	CreatedAt time.Time `json:"created_at"`
 	UpdatedAt time.Time `json:"updated_at"`
 }
 
+// Goal represents a savings goal (imported from budget domain)
+type Goal struct {
+	ID              uuid.UUID     `json:"id" gorm:"type:uuid;primary_key"`
+	UserID          uuid.UUID     `json:"user_id" gorm:"type:uuid;not null"`
+	Name            string        `json:"name"`
+	StartDate       time.Time     `json:"start_date"`
+	Accounts        []GoalAccount `json:"accounts" gorm:"foreignKey:GoalID"`
+	ContributionTag string        `json:"contribution_tag"`
+	IsActive        bool          `json:"is_active"`
+	CreatedAt       time.Time     `json:"created_at"`
+}
+
+// GoalAccount links a savings account to a goal (imported from budget domain)
+type GoalAccount struct {
+	GoalID    uuid.UUID `json:"goal_id" gorm:"type:uuid;primaryKey"`
+	AccountID uuid.UUID `json:"account_id" gorm:"type:uuid;primaryKey"`
+}
+
+// Account represents a financial account (imported from transaction domain)
+type Account struct {
+	ID        uuid.UUID `json:"id" gorm:"type:uuid;primary_key"`
+	UserID    uuid.UUID `json:"user_id" gorm:"type:uuid;not null"`
+	Name      string    `json:"name"`
+	Type      string    `json:"type"`
+	Balance   float64   `json:"balance" gorm:"type:decimal(15,2)"`
+	Currency  string    `json:"currency"`
+	IsActive  bool      `json:"is_active"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
 // TableName specifies the table name for Category
 func (Category) TableName() string {
 	return "categories"
@@ -264,3 +682,18 @@ func (Category) TableName() string {
 func (Transaction) TableName() string {
 	return "transactions"
 }
+
+// TableName specifies the table name for Goal
+func (Goal) TableName() string {
+	return "goals"
+}
+
+// TableName specifies the table name for GoalAccount
+func (GoalAccount) TableName() string {
+	return "goal_accounts"
+}
+
+// TableName specifies the table name for Account
+func (Account) TableName() string {
+	return "accounts"
+}
@@ -0,0 +1,392 @@
+package analytics
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+
+	"fiscaflow/internal/seeds"
+)
+
+const (
+	// ruleHistoryPeriod is how far back rules are tested and applied without a start date
+	ruleHistoryPeriod = 365 * 24 * time.Hour
+	// maxRuleTestMatches is the most matches a rule test returns
+	maxRuleTestMatches = 50
+)
+
+var (
+	// ErrInvalidRulePeriod is returned for periods that end before they start
+	ErrInvalidRulePeriod = errors.New("invalid period")
+
+	// ErrRuleApplicationNotFound is returned for rule applications that don't exist or
+	// that belong to someone else
+	ErrRuleApplicationNotFound = errors.New("rule application not found")
+
+	// ErrRuleApplicationUndone is returned when undoing a rule application twice
+	ErrRuleApplicationUndone = errors.New("rule application already undone")
+)
+
+// TestCategorizationRule runs a draft rule over the user's transactions without saving it.
+// It returns the transactions the rule matches and the rules with a higher priority that
+// would categorize some of them differently first
+func (s *service) TestCategorizationRule(ctx context.Context, userID uuid.UUID, req *TestCategorizationRuleRequest) (*RuleTestResult, error) {
+	ctx, span := otel.Tracer("").Start(ctx, "analytics.TestCategorizationRule",
+		trace.WithAttributes(
+			attribute.String("user_id", userID.String()),
+			attribute.String("pattern", req.Pattern),
+			attribute.String("pattern_type", req.PatternType),
+		),
+	)
+	defer span.End()
+
+	draft := &CategorizationRule{
+		UserID:      &userID,
+		CategoryID:  req.CategoryID,
+		Pattern:     req.Pattern,
+		PatternType: req.PatternType,
+		Conditions:  req.Conditions,
+		Actions:     req.Actions,
+		Priority:    req.Priority,
+		IsActive:    true,
+	}
+	if err := s.validateRule(draft); err != nil {
+		span.SetStatus(codes.Error, err.Error())
+		return nil, err
+	}
+
+	startDate, endDate, err := rulePeriod(req.StartDate, req.EndDate)
+	if err != nil {
+		span.SetStatus(codes.Error, err.Error())
+		return nil, err
+	}
+
+	transactions, err := s.repo.GetTransactionsByPeriod(ctx, userID, startDate, endDate)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, err
+	}
+
+	rules, err := s.repo.GetActiveCategorizationRules(ctx, &userID)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, err
+	}
+
+	// Only the rules that run before the draft can take its transactions. Both are matched
+	// the way transactions are categorized, so rules for other locales never conflict
+	locale := seeds.ResolveLocale(req.Locale)
+	var precedingRules []CategorizationRule
+	for _, rule := range rules {
+		if rule.Priority > draft.Priority {
+			precedingRules = append(precedingRules, rule)
+		}
+	}
+	drafts := compileRules([]CategorizationRule{*draft})
+	preceding := compileRules(precedingRules)
+
+	result := &RuleTestResult{
+		StartDate:          startDate,
+		EndDate:            endDate,
+		TransactionsTested: len(transactions),
+		Matches:            []RuleMatch{},
+		Conflicts:          []RuleConflict{},
+	}
+	conflicts := make(map[uuid.UUID]*RuleConflict)
+	for _, transaction := range transactions {
+		categorization := transactionCategorizationRequest(userID, &transaction)
+		if drafts.firstMatch(categorization, locale) == nil {
+			continue
+		}
+		result.TransactionsMatched++
+
+		match := RuleMatch{
+			TransactionID:     transaction.ID,
+			Description:       transaction.Description,
+			Merchant:          transaction.Merchant,
+			Amount:            transaction.Amount,
+			TransactionDate:   transaction.TransactionDate,
+			CurrentCategoryID: transaction.CategoryID,
+		}
+		if rule := preceding.firstMatch(categorization, locale); rule != nil && rule.CategoryID != draft.CategoryID {
+			ruleID := rule.ID
+			match.ConflictingRuleID = &ruleID
+
+			conflict, exists := conflicts[rule.ID]
+			if !exists {
+				conflict = &RuleConflict{
+					RuleID:     rule.ID,
+					CategoryID: rule.CategoryID,
+					Pattern:    rule.Pattern,
+					Priority:   rule.Priority,
+				}
+				// Categories of rules the user can't see stay unnamed
+				if category, err := s.repo.GetCategoryByID(ctx, rule.CategoryID); err == nil {
+					if visible, err := s.canSeeCategory(ctx, &userID, category); err == nil && visible {
+						conflict.CategoryName = category.Name
+					}
+				}
+				conflicts[rule.ID] = conflict
+			}
+			conflict.Transactions++
+		}
+
+		// Transactions come most recent first
+		if len(result.Matches) < maxRuleTestMatches {
+			result.Matches = append(result.Matches, match)
+		}
+	}
+
+	for _, conflict := range conflicts {
+		result.Conflicts = append(result.Conflicts, *conflict)
+	}
+	sort.Slice(result.Conflicts, func(i, j int) bool {
+		if result.Conflicts[i].Transactions != result.Conflicts[j].Transactions {
+			return result.Conflicts[i].Transactions > result.Conflicts[j].Transactions
+		}
+		return result.Conflicts[i].Priority > result.Conflicts[j].Priority
+	})
+
+	span.SetAttributes(
+		attribute.Int("transactions_tested", result.TransactionsTested),
+		attribute.Int("transactions_matched", result.TransactionsMatched),
+		attribute.Int("conflicts_count", len(result.Conflicts)),
+	)
+	return result, nil
+}
+
+// ApplyCategorizationRule categorizes the user's existing transactions that a rule matches.
+// Transactions are matched against all of the user's rules the way they are categorized, so
+// those a rule with a higher priority takes are left alone. Uncategorized transactions are
+// categorized, and with overwrite so are those categorized automatically; categories users
+// chose are kept. What each transaction was like before is
+// recorded so that the application can be undone
+func (s *service) ApplyCategorizationRule(ctx context.Context, userID, ruleID uuid.UUID, req *ApplyCategorizationRuleRequest) (*RuleApplication, error) {
+	ctx, span := otel.Tracer("").Start(ctx, "analytics.ApplyCategorizationRule",
+		trace.WithAttributes(
+			attribute.String("user_id", userID.String()),
+			attribute.String("rule_id", ruleID.String()),
+			attribute.Bool("overwrite", req.Overwrite),
+		),
+	)
+	defer span.End()
+
+	rule, err := s.getVisibleRule(ctx, userID, ruleID)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, err
+	}
+
+	startDate, endDate, err := rulePeriod(req.StartDate, req.EndDate)
+	if err != nil {
+		span.SetStatus(codes.Error, err.Error())
+		return nil, err
+	}
+
+	transactions, err := s.repo.GetTransactionsByPeriod(ctx, userID, startDate, endDate)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, err
+	}
+
+	rules, err := s.compiledRules(ctx, &userID)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, err
+	}
+	locale := seeds.ResolveLocale(req.Locale)
+
+	application := &RuleApplication{
+		UserID:     userID,
+		RuleID:     rule.ID,
+		CategoryID: rule.CategoryID,
+		Status:     RuleApplicationStatusCompleted,
+		StartDate:  startDate,
+		EndDate:    endDate,
+		Overwrite:  req.Overwrite,
+	}
+	var changes []RuleApplicationChange
+	var updated []Transaction
+	for _, transaction := range transactions {
+		categorization := transactionCategorizationRequest(userID, &transaction)
+		if matched := rules.firstMatch(categorization, locale); matched == nil || matched.ID != rule.ID {
+			continue
+		}
+		application.TransactionsMatched++
+
+		if !canRecategorize(&transaction, rule.CategoryID, req.Overwrite) {
+			continue
+		}
+
+		changes = append(changes, RuleApplicationChange{
+			TransactionID:               transaction.ID,
+			PreviousCategoryID:          transaction.CategoryID,
+			PreviousSuggestedCategoryID: transaction.SuggestedCategoryID,
+			PreviousSource:              transaction.CategorizationSource,
+			PreviousConfidence:          transaction.CategorizationConfidence,
+			PreviousTags:                transaction.Tags,
+			PreviousNotes:               transaction.Notes,
+			PreviousIsTransfer:          transaction.IsTransfer,
+		})
+
+		categoryID := rule.CategoryID
+		confidence := s.calculateRuleConfidence(rule, transaction.Amount)
+		transaction.CategoryID = &categoryID
+		transaction.SuggestedCategoryID = nil
+		transaction.CategorizationSource = "rule"
+		transaction.CategorizationConfidence = &confidence
+		applyRuleActions(&transaction, &rule.Actions)
+		updated = append(updated, transaction)
+	}
+	application.TransactionsUpdated = len(updated)
+
+	if err := s.repo.CreateRuleApplication(ctx, application, changes, updated); err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, err
+	}
+
+	span.SetAttributes(
+		attribute.String("rule_application_id", application.ID.String()),
+		attribute.Int("transactions_matched", application.TransactionsMatched),
+		attribute.Int("transactions_updated", application.TransactionsUpdated),
+	)
+	return application, nil
+}
+
+// ListRuleApplications retrieves the user's rule applications, most recent first
+func (s *service) ListRuleApplications(ctx context.Context, userID uuid.UUID, offset, limit int) ([]RuleApplication, error) {
+	ctx, span := otel.Tracer("").Start(ctx, "analytics.ListRuleApplications",
+		trace.WithAttributes(
+			attribute.String("user_id", userID.String()),
+			attribute.Int("offset", offset),
+			attribute.Int("limit", limit),
+		),
+	)
+	defer span.End()
+
+	applications, err := s.repo.GetRuleApplicationsByUser(ctx, userID, offset, limit)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, err
+	}
+
+	span.SetAttributes(attribute.Int("rule_applications_count", len(applications)))
+	return applications, nil
+}
+
+// UndoRuleApplication restores the transactions a rule application changed, except those
+// that have been recategorized since
+func (s *service) UndoRuleApplication(ctx context.Context, userID, id uuid.UUID) (*RuleApplication, error) {
+	ctx, span := otel.Tracer("").Start(ctx, "analytics.UndoRuleApplication",
+		trace.WithAttributes(
+			attribute.String("user_id", userID.String()),
+			attribute.String("rule_application_id", id.String()),
+		),
+	)
+	defer span.End()
+
+	application, err := s.repo.GetRuleApplicationByID(ctx, id)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, err
+	}
+	if application.UserID != userID {
+		span.SetStatus(codes.Error, ErrRuleApplicationNotFound.Error())
+		return nil, ErrRuleApplicationNotFound
+	}
+	if application.Status == RuleApplicationStatusUndone {
+		span.SetStatus(codes.Error, ErrRuleApplicationUndone.Error())
+		return nil, ErrRuleApplicationUndone
+	}
+
+	if err := s.repo.UndoRuleApplication(ctx, application); err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, err
+	}
+
+	span.SetAttributes(attribute.Int("transactions_restored", application.TransactionsRestored))
+	return application, nil
+}
+
+// rulePeriod resolves the period rules are tested and applied over, which defaults to the
+// last year
+func rulePeriod(start, end *time.Time) (time.Time, time.Time, error) {
+	endDate := time.Now()
+	if end != nil {
+		endDate = *end
+	}
+	startDate := endDate.Add(-ruleHistoryPeriod)
+	if start != nil {
+		startDate = *start
+	}
+	if startDate.After(endDate) {
+		return time.Time{}, time.Time{}, fmt.Errorf("%w: start_date cannot be after end_date", ErrInvalidRulePeriod)
+	}
+	return startDate, endDate, nil
+}
+
+// transactionCategorizationRequest returns the request rules are matched against for an
+// existing transaction
+func transactionCategorizationRequest(userID uuid.UUID, transaction *Transaction) *CategorizationRequest {
+	accountID := transaction.AccountID
+	transactionDate := transaction.TransactionDate
+	return &CategorizationRequest{
+		UserID:          &userID,
+		Description:     transaction.Description,
+		Merchant:        transaction.Merchant,
+		Amount:          transaction.Amount,
+		AccountID:       &accountID,
+		Currency:        transaction.Currency,
+		TransactionDate: &transactionDate,
+	}
+}
+
+// canRecategorize reports whether applying a rule may change a transaction's category.
+// Categories chosen by users are never changed, and automatic ones only with overwrite
+func canRecategorize(transaction *Transaction, categoryID uuid.UUID, overwrite bool) bool {
+	if transaction.CategoryID == nil {
+		return true
+	}
+	if *transaction.CategoryID == categoryID {
+		return false
+	}
+	return overwrite && (transaction.CategorizationSource == "rule" || transaction.CategorizationSource == "ml")
+}
+
+// applyRuleActions applies the actions of a rule to a transaction it categorizes
+func applyRuleActions(transaction *Transaction, actions *RuleActions) {
+	tags := append([]string(nil), transaction.Tags...)
+	for _, tag := range actions.Tags {
+		exists := false
+		for _, existing := range tags {
+			exists = exists || existing == tag
+		}
+		if !exists {
+			tags = append(tags, tag)
+		}
+	}
+	transaction.Tags = tags
+	if transaction.Notes == "" {
+		transaction.Notes = actions.Notes
+	}
+	if actions.IsTransfer {
+		transaction.IsTransfer = true
+	}
+}
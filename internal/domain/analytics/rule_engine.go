@@ -0,0 +1,279 @@
+package analytics
+
+import (
+	"context"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// compiledRuleSet holds the categorization rules that apply to a user, ordered and
+// compiled once so that categorizing a transaction doesn't have to. The literal patterns
+// of all rules are found in one pass over a transaction's text
+type compiledRuleSet struct {
+	rules    []compiledRule
+	literals *ahoCorasick
+}
+
+// compiledRule is a rule with its pattern compiled
+type compiledRule struct {
+	rule     CategorizationRule
+	literals []int          // Literals that all have to occur: the exact pattern, or each keyword
+	regex    *regexp.Regexp // Nil for rules with an invalid regex, which never match
+}
+
+// compileRules orders rules by precedence and compiles their patterns
+func compileRules(rules []CategorizationRule) *compiledRuleSet {
+	ordered := append([]CategorizationRule(nil), rules...)
+	sortRules(ordered)
+
+	set := &compiledRuleSet{rules: make([]compiledRule, len(ordered))}
+	var literals []string
+	literalIDs := make(map[string]int)
+	literalID := func(literal string) int {
+		id, exists := literalIDs[literal]
+		if !exists {
+			id = len(literals)
+			literalIDs[literal] = id
+			literals = append(literals, literal)
+		}
+		return id
+	}
+
+	for i, rule := range ordered {
+		compiled := compiledRule{rule: rule}
+		pattern := strings.ToLower(rule.Pattern)
+		switch rule.PatternType {
+		case "exact":
+			compiled.literals = []int{literalID(pattern)}
+		case "keyword":
+			for _, keyword := range strings.Split(pattern, " ") {
+				// Empty keywords are in every text
+				if keyword != "" {
+					compiled.literals = append(compiled.literals, literalID(keyword))
+				}
+			}
+		case "regex":
+			compiled.regex, _ = regexp.Compile(pattern)
+		}
+		set.rules[i] = compiled
+	}
+	set.literals = newAhoCorasick(literals)
+	return set
+}
+
+// firstMatch returns the first active rule for the locale that applies to a transaction
+func (s *compiledRuleSet) firstMatch(req *CategorizationRequest, locale string) *CategorizationRule {
+	var first *CategorizationRule
+	s.eachMatch(req, locale, func(rule *CategorizationRule) bool {
+		first = rule
+		return false
+	})
+	return first
+}
+
+// eachMatch calls yield with the active rules for the locale that apply to a transaction,
+// in order of precedence, until it returns false
+func (s *compiledRuleSet) eachMatch(req *CategorizationRequest, locale string, yield func(rule *CategorizationRule) bool) {
+	text := categorizationText(req)
+	var found []bool
+	for i := range s.rules {
+		compiled := &s.rules[i]
+		if !compiled.rule.IsActive {
+			continue
+		}
+		// Starter rules only apply to the locale they were seeded for
+		if compiled.rule.Locale != "" && compiled.rule.Locale != locale {
+			continue
+		}
+
+		applies, decided := ruleConditionsApply(&compiled.rule, req)
+		if !decided {
+			// Literals are only looked for once a rule needs them
+			if found == nil && len(compiled.literals) > 0 {
+				found = s.literals.find(text)
+			}
+			applies = compiled.matches(found, text)
+		}
+		if applies && !yield(&compiled.rule) {
+			return
+		}
+	}
+}
+
+// matches reports whether a compiled rule's pattern matches a transaction, given the
+// literals found in its lowercase text
+func (c *compiledRule) matches(found []bool, text string) bool {
+	switch c.rule.PatternType {
+	case "exact", "keyword":
+		for _, id := range c.literals {
+			if !found[id] {
+				return false
+			}
+		}
+		return true
+	case "regex":
+		return c.regex != nil && c.regex.MatchString(text)
+	}
+	return false
+}
+
+// ahoCorasick finds which of a set of literals occur in a text in a single pass
+type ahoCorasick struct {
+	literals int
+	next     []map[byte]int32
+	fail     []int32
+	outputs  [][]int32 // Literals that end at each state, including through its fail links
+}
+
+// newAhoCorasick builds the automaton of a set of literals
+func newAhoCorasick(literals []string) *ahoCorasick {
+	ac := &ahoCorasick{literals: len(literals), next: []map[byte]int32{{}}, fail: []int32{0}, outputs: [][]int32{nil}}
+	for id, literal := range literals {
+		state := int32(0)
+		for i := 0; i < len(literal); i++ {
+			child, exists := ac.next[state][literal[i]]
+			if !exists {
+				child = int32(len(ac.next))
+				ac.next = append(ac.next, map[byte]int32{})
+				ac.fail = append(ac.fail, 0)
+				ac.outputs = append(ac.outputs, nil)
+				ac.next[state][literal[i]] = child
+			}
+			state = child
+		}
+		ac.outputs[state] = append(ac.outputs[state], int32(id))
+	}
+
+	// Fail links point to the longest proper suffix that is also a prefix, breadth first
+	var queue []int32
+	for _, child := range ac.next[0] {
+		queue = append(queue, child)
+	}
+	for len(queue) > 0 {
+		state := queue[0]
+		queue = queue[1:]
+		for b, child := range ac.next[state] {
+			fail := ac.fail[state]
+			for {
+				if target, exists := ac.next[fail][b]; exists {
+					ac.fail[child] = target
+					break
+				}
+				if fail == 0 {
+					break
+				}
+				fail = ac.fail[fail]
+			}
+			ac.outputs[child] = append(ac.outputs[child], ac.outputs[ac.fail[child]]...)
+			queue = append(queue, child)
+		}
+	}
+	return ac
+}
+
+// find returns which literals occur in a text, indexed by literal
+func (ac *ahoCorasick) find(text string) []bool {
+	found := make([]bool, ac.literals)
+	if ac.literals == 0 {
+		return found
+	}
+
+	state := int32(0)
+	for i := 0; i < len(text); i++ {
+		for {
+			if child, exists := ac.next[state][text[i]]; exists {
+				state = child
+				break
+			}
+			if state == 0 {
+				break
+			}
+			state = ac.fail[state]
+		}
+		for _, id := range ac.outputs[state] {
+			found[id] = true
+		}
+	}
+	return found
+}
+
+// ruleSetCache keeps the compiled rules that apply to each user in memory. Rules change
+// for many users at once, through their families or for everyone, so any change to a rule
+// drops every rule set. Other changes, like joining a family, show after modelCacheTTL
+type ruleSetCache struct {
+	mu         sync.RWMutex
+	sets       map[uuid.UUID]cachedRuleSet
+	generation uint64
+}
+
+// cachedRuleSet is the compiled rules of a user, or of everyone under uuid.Nil
+type cachedRuleSet struct {
+	rules    *compiledRuleSet
+	loadedAt time.Time
+}
+
+// get returns the cached rules of a user, the generation of the cache to set loaded rules
+// with, and whether the rules are still fresh
+func (c *ruleSetCache) get(userID uuid.UUID) (*compiledRuleSet, uint64, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	cached, exists := c.sets[userID]
+	return cached.rules, c.generation, exists && time.Since(cached.loadedAt) < modelCacheTTL
+}
+
+// set caches the rules of a user, unless the rules changed since they were loaded. Rule sets
+// of other users that are no longer fresh are dropped
+func (c *ruleSetCache) set(userID uuid.UUID, rules *compiledRuleSet, generation uint64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if generation != c.generation {
+		return
+	}
+	if c.sets == nil {
+		c.sets = make(map[uuid.UUID]cachedRuleSet)
+	}
+	for id, cached := range c.sets {
+		if time.Since(cached.loadedAt) >= modelCacheTTL {
+			delete(c.sets, id)
+		}
+	}
+	c.sets[userID] = cachedRuleSet{rules: rules, loadedAt: time.Now()}
+}
+
+// invalidate makes every user's rules be loaded again on their next use
+func (c *ruleSetCache) invalidate() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.sets = nil
+	c.generation++
+}
+
+// compiledRules returns the compiled rules that apply to a user, or to everyone without one
+func (s *service) compiledRules(ctx context.Context, userID *uuid.UUID) (*compiledRuleSet, error) {
+	key := uuid.Nil
+	if userID != nil {
+		key = *userID
+	}
+	rules, generation, fresh := s.rules.get(key)
+	if fresh {
+		return rules, nil
+	}
+
+	loaded, err := s.repo.GetActiveCategorizationRules(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	rules = compileRules(loaded)
+	s.rules.set(key, rules, generation)
+	return rules, nil
+}
+
+// CategoriesMerged drops the cached rules after categories were merged, which moves the
+// rules of the merged category to the one it was merged into
+func (s *service) CategoriesMerged(ctx context.Context) {
+	s.rules.invalidate()
+}
@@ -0,0 +1,284 @@
+package analytics
+
+import (
+	"context"
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+
+	"github.com/google/uuid"
+)
+
+var (
+	// ErrInvalidCategorizationRule is returned for rules that could never match
+	ErrInvalidCategorizationRule = errors.New("invalid categorization rule")
+
+	// ErrCategorizationRuleNotFound is returned for rules that don't exist or that the
+	// user can't see
+	ErrCategorizationRuleNotFound = errors.New("categorization rule not found")
+
+	// ErrCategorizationRuleNotOwned is returned when changing a rule that applies to
+	// everyone, or that belongs to someone else
+	ErrCategorizationRuleNotOwned = errors.New("categorization rule is not owned by user")
+)
+
+// Value stores rule conditions as JSON
+func (c RuleConditions) Value() (driver.Value, error) {
+	encoded, err := json.Marshal(c)
+	if err != nil {
+		return nil, err
+	}
+	return string(encoded), nil
+}
+
+// Scan reads rule conditions stored as JSON
+func (c *RuleConditions) Scan(value interface{}) error {
+	data, err := jsonColumn(value, "rule conditions")
+	if err != nil || data == nil {
+		*c = RuleConditions{}
+		return err
+	}
+	return json.Unmarshal(data, c)
+}
+
+// Value stores rule actions as JSON
+func (a RuleActions) Value() (driver.Value, error) {
+	encoded, err := json.Marshal(a)
+	if err != nil {
+		return nil, err
+	}
+	return string(encoded), nil
+}
+
+// Scan reads rule actions stored as JSON
+func (a *RuleActions) Scan(value interface{}) error {
+	data, err := jsonColumn(value, "rule actions")
+	if err != nil || data == nil {
+		*a = RuleActions{}
+		return err
+	}
+	return json.Unmarshal(data, a)
+}
+
+// jsonColumn returns the JSON stored in a column, or nil when it is NULL
+func jsonColumn(value interface{}, name string) ([]byte, error) {
+	switch v := value.(type) {
+	case nil:
+		return nil, nil
+	case []byte:
+		return v, nil
+	case string:
+		return []byte(v), nil
+	default:
+		return nil, fmt.Errorf("cannot scan %T into %s", value, name)
+	}
+}
+
+// isEmpty reports whether no condition is set
+func (c *RuleConditions) isEmpty() bool {
+	return c.MinAmount == nil && c.MaxAmount == nil && c.Sign == "" && len(c.AccountIDs) == 0 &&
+		len(c.Currencies) == 0 && len(c.DaysOfWeek) == 0 && len(c.Merchants) == 0
+}
+
+// isEmpty reports whether a rule does nothing but set the category
+func (a *RuleActions) isEmpty() bool {
+	return len(a.Tags) == 0 && a.Notes == "" && !a.IsTransfer
+}
+
+// validateRule checks that a rule has a valid pattern or at least one condition, and that
+// its conditions are well formed
+func (s *service) validateRule(rule *CategorizationRule) error {
+	if rule.Pattern == "" && rule.Conditions.isEmpty() {
+		return fmt.Errorf("%w: a pattern or a condition is required", ErrInvalidCategorizationRule)
+	}
+	if rule.Pattern != "" {
+		if err := s.validatePattern(rule.Pattern, rule.PatternType); err != nil {
+			return fmt.Errorf("%w: %v", ErrInvalidCategorizationRule, err)
+		}
+	}
+
+	conditions := &rule.Conditions
+	switch conditions.Operator {
+	case "", RuleOperatorAnd, RuleOperatorOr:
+	default:
+		return fmt.Errorf("%w: invalid operator: %s", ErrInvalidCategorizationRule, conditions.Operator)
+	}
+	switch conditions.Sign {
+	case "", RuleSignExpense, RuleSignIncome:
+	default:
+		return fmt.Errorf("%w: invalid sign: %s", ErrInvalidCategorizationRule, conditions.Sign)
+	}
+	if (conditions.MinAmount != nil && *conditions.MinAmount < 0) || (conditions.MaxAmount != nil && *conditions.MaxAmount < 0) {
+		return fmt.Errorf("%w: amounts cannot be negative", ErrInvalidCategorizationRule)
+	}
+	if conditions.MinAmount != nil && conditions.MaxAmount != nil && *conditions.MinAmount > *conditions.MaxAmount {
+		return fmt.Errorf("%w: min_amount cannot exceed max_amount", ErrInvalidCategorizationRule)
+	}
+	for _, day := range conditions.DaysOfWeek {
+		if day < 0 || day > 6 {
+			return fmt.Errorf("%w: days of the week must be between 0 and 6", ErrInvalidCategorizationRule)
+		}
+	}
+	return nil
+}
+
+// ruleConditionsApply reports whether a rule's conditions match a transaction, and whether
+// that decides if the rule applies. When it doesn't, the rule's pattern decides, which
+// saves matching patterns of rules whose conditions already failed
+func ruleConditionsApply(rule *CategorizationRule, req *CategorizationRequest) (bool, bool) {
+	conditions := &rule.Conditions
+	var results []bool
+
+	amount := math.Abs(req.Amount)
+	if conditions.MinAmount != nil || conditions.MaxAmount != nil {
+		results = append(results, (conditions.MinAmount == nil || amount >= *conditions.MinAmount) &&
+			(conditions.MaxAmount == nil || amount <= *conditions.MaxAmount))
+	}
+	switch conditions.Sign {
+	case RuleSignExpense:
+		results = append(results, req.Amount < 0)
+	case RuleSignIncome:
+		results = append(results, req.Amount > 0)
+	}
+	if len(conditions.AccountIDs) > 0 {
+		matched := false
+		for _, accountID := range conditions.AccountIDs {
+			matched = matched || (req.AccountID != nil && *req.AccountID == accountID)
+		}
+		results = append(results, matched)
+	}
+	if len(conditions.Currencies) > 0 {
+		matched := false
+		for _, currency := range conditions.Currencies {
+			matched = matched || (req.Currency != "" && strings.EqualFold(currency, req.Currency))
+		}
+		results = append(results, matched)
+	}
+	if len(conditions.DaysOfWeek) > 0 {
+		matched := false
+		for _, day := range conditions.DaysOfWeek {
+			matched = matched || (req.TransactionDate != nil && int(req.TransactionDate.Weekday()) == day)
+		}
+		results = append(results, matched)
+	}
+	if len(conditions.Merchants) > 0 {
+		merchant := strings.TrimSpace(req.Merchant)
+		matched := false
+		for _, candidate := range conditions.Merchants {
+			matched = matched || (merchant != "" && strings.EqualFold(strings.TrimSpace(candidate), merchant))
+		}
+		results = append(results, matched)
+	}
+
+	hasPattern := rule.Pattern != ""
+	if len(results) == 0 && !hasPattern {
+		return false, true
+	}
+	for _, result := range results {
+		if conditions.Operator == RuleOperatorOr && result {
+			return true, true
+		}
+		if conditions.Operator != RuleOperatorOr && !result {
+			return false, true
+		}
+	}
+	return conditions.Operator != RuleOperatorOr, !hasPattern
+}
+
+// ruleOwnerRank orders rules of the same priority: the user's own first, then their
+// families', then those that apply to everyone
+func ruleOwnerRank(rule *CategorizationRule) int {
+	switch {
+	case rule.UserID != nil:
+		return 0
+	case rule.FamilyID != nil:
+		return 1
+	default:
+		return 2
+	}
+}
+
+// isOwnedRule reports whether a rule belongs to a user or family rather than everyone
+func isOwnedRule(rule *CategorizationRule) bool {
+	return rule.UserID != nil || rule.FamilyID != nil
+}
+
+// canSeeRule reports whether a user's transactions are categorized by a rule
+func (s *service) canSeeRule(ctx context.Context, userID uuid.UUID, rule *CategorizationRule) (bool, error) {
+	if !isOwnedRule(rule) {
+		return true, nil
+	}
+	return s.ownsRule(ctx, userID, rule)
+}
+
+// ownsRule reports whether a user may change a rule: their own, or one of their families'
+func (s *service) ownsRule(ctx context.Context, userID uuid.UUID, rule *CategorizationRule) (bool, error) {
+	if rule.UserID != nil {
+		return *rule.UserID == userID, nil
+	}
+	if rule.FamilyID == nil {
+		return false, nil
+	}
+	return s.isFamilyMember(ctx, userID, *rule.FamilyID)
+}
+
+// validateRuleCategory checks that a rule categorizes transactions into a category everyone
+// it applies to can see: a system category, or one of the owner's categories. Family
+// rules can only use system and family categories, which every member can see
+func (s *service) validateRuleCategory(ctx context.Context, rule *CategorizationRule) error {
+	category, err := s.repo.GetCategoryByID(ctx, rule.CategoryID)
+	if errors.Is(err, ErrCategoryNotFound) {
+		return fmt.Errorf("%w: category not found", ErrInvalidCategorizationRule)
+	}
+	if err != nil {
+		return err
+	}
+
+	visible := category.UserID == nil && category.FamilyID == nil
+	switch {
+	case visible:
+	case rule.UserID != nil:
+		visible, err = s.canSeeCategory(ctx, rule.UserID, category)
+		if err != nil {
+			return err
+		}
+	case rule.FamilyID != nil:
+		visible = category.FamilyID != nil && *category.FamilyID == *rule.FamilyID
+	}
+	if !visible {
+		return fmt.Errorf("%w: category not found", ErrInvalidCategorizationRule)
+	}
+	return nil
+}
+
+// canSeeCategory reports whether a user can see a category: system categories, their own
+// and those of their families. Without a user only system categories are visible
+func (s *service) canSeeCategory(ctx context.Context, userID *uuid.UUID, category *Category) (bool, error) {
+	if category.UserID == nil && category.FamilyID == nil {
+		return true, nil
+	}
+	if userID == nil {
+		return false, nil
+	}
+	if category.UserID != nil {
+		return *category.UserID == *userID, nil
+	}
+	return s.isFamilyMember(ctx, *userID, *category.FamilyID)
+}
+
+// isFamilyMember reports whether a user belongs to a family
+func (s *service) isFamilyMember(ctx context.Context, userID, familyID uuid.UUID) (bool, error) {
+	familyIDs, err := s.repo.GetFamilyIDsByUser(ctx, userID)
+	if err != nil {
+		return false, fmt.Errorf("failed to get families: %w", err)
+	}
+	for _, id := range familyIDs {
+		if id == familyID {
+			return true, nil
+		}
+	}
+	return false, nil
+}
@@ -14,31 +14,57 @@ import (
 	"go.opentelemetry.io/otel/attribute"
 	"go.opentelemetry.io/otel/codes"
 	"go.opentelemetry.io/otel/trace"
+
+	"fiscaflow/internal/seeds"
 )
 
 // Service defines the interface for analytics business logic
 type Service interface {
 	// Categorization operations
 	CategorizeTransaction(ctx context.Context, req *CategorizationRequest) (*CategorizationResponse, error)
-	CreateCategorizationRule(ctx context.Context, req *CreateCategorizationRuleRequest) (*CategorizationRuleResponse, error)
-	GetCategorizationRule(ctx context.Context, id uuid.UUID) (*CategorizationRuleResponse, error)
-	ListCategorizationRules(ctx context.Context, offset, limit int) ([]CategorizationRuleResponse, error)
-	UpdateCategorizationRule(ctx context.Context, id uuid.UUID, req *UpdateCategorizationRuleRequest) (*CategorizationRuleResponse, error)
-	DeleteCategorizationRule(ctx context.Context, id uuid.UUID) error
+	CategorizeTransactions(ctx context.Context, req *BatchCategorizationRequest) (*BatchCategorizationResponse, error)
+	CreateCategorizationRule(ctx context.Context, userID uuid.UUID, req *CreateCategorizationRuleRequest) (*CategorizationRuleResponse, error)
+	GetCategorizationRule(ctx context.Context, userID, id uuid.UUID) (*CategorizationRuleResponse, error)
+	ListCategorizationRules(ctx context.Context, userID uuid.UUID, offset, limit int) ([]CategorizationRuleResponse, error)
+	UpdateCategorizationRule(ctx context.Context, userID, id uuid.UUID, req *UpdateCategorizationRuleRequest) (*CategorizationRuleResponse, error)
+	DeleteCategorizationRule(ctx context.Context, userID, id uuid.UUID) error
+	TestCategorizationRule(ctx context.Context, userID uuid.UUID, req *TestCategorizationRuleRequest) (*RuleTestResult, error)
+
+	// Rule application operations
+	ApplyCategorizationRule(ctx context.Context, userID, ruleID uuid.UUID, req *ApplyCategorizationRuleRequest) (*RuleApplication, error)
+	ListRuleApplications(ctx context.Context, userID uuid.UUID, offset, limit int) ([]RuleApplication, error)
+	UndoRuleApplication(ctx context.Context, userID, id uuid.UUID) (*RuleApplication, error)
+
+	// Categorization model operations
+	TrainCategorizationModel(ctx context.Context) (*ModelTrainingResult, error)
+	ListCategorizationModels(ctx context.Context, offset, limit int) ([]CategorizationModelResponse, error)
+	CompareCategorizationModels(ctx context.Context, baseID, candidateID uuid.UUID) (*ModelComparison, error)
+	ActivateCategorizationModel(ctx context.Context, id uuid.UUID) (*CategorizationModelResponse, error)
+
+	// Categorization feedback operations
+	RecordCorrection(ctx context.Context, userID uuid.UUID, req *CategoryCorrectionRequest) error
+	SuggestCategorizationRules(ctx context.Context, userID uuid.UUID, locale string) ([]RuleSuggestion, error)
+	CategoriesMerged(ctx context.Context)
 
 	// Spending analysis operations
 	AnalyzeSpending(ctx context.Context, userID uuid.UUID, req *SpendingAnalysisRequest) (*SpendingAnalysisResponse, error)
 	GetSpendingInsights(ctx context.Context, userID uuid.UUID, periodStart, periodEnd time.Time) ([]SpendingInsight, error)
+
+	// Forecasting operations
+	GetCashFlowForecast(ctx context.Context, userID uuid.UUID, req *CashFlowForecastRequest) (*CashFlowForecast, error)
 }
 
 // service implements the Service interface
 type service struct {
-	repo Repository
+	repo     Repository
+	models   *modelCache
+	feedback *feedbackCache
+	rules    *ruleSetCache
 }
 
 // NewService creates a new analytics service
 func NewService(repo Repository) Service {
-	return &service{repo: repo}
+	return &service{repo: repo, models: &modelCache{}, feedback: &feedbackCache{}, rules: &ruleSetCache{}}
 }
 
 // CategorizeTransaction categorizes a transaction using rule-based and ML approaches
@@ -52,195 +78,121 @@ func (s *service) CategorizeTransaction(ctx context.Context, req *Categorization
 	)
 	defer span.End()
 
-	// First, try rule-based categorization
-	ruleMatch, err := s.categorizeByRules(ctx, req)
+	response, err := s.categorize(ctx, req, make(map[uuid.UUID]*Category))
 	if err != nil {
 		span.RecordError(err)
 		span.SetStatus(codes.Error, err.Error())
+		return nil, err
+	}
+
+	categoryID := response.CategoryID.String()
+	if response.CategoryID == uuid.Nil {
+		categoryID = "uncategorized"
+	}
+	span.SetAttributes(
+		attribute.String("category_id", categoryID),
+		attribute.Float64("confidence", response.Confidence),
+		attribute.String("source", response.CategorizationSource),
+	)
+	return response, nil
+}
+
+// categorize categorizes a transaction by rules first, then by ML. Categories are looked up
+// through the cache, which lets a batch of transactions share them
+func (s *service) categorize(ctx context.Context, req *CategorizationRequest, categories map[uuid.UUID]*Category) (*CategorizationResponse, error) {
+	// First, try rule-based categorization
+	ruleMatch, err := s.categorizeByRules(ctx, req, categories)
+	if err != nil {
 		return nil, fmt.Errorf("failed to categorize by rules: %w", err)
 	}
 
 	if ruleMatch != nil && ruleMatch.Confidence > 0.8 {
-		span.SetAttributes(
-			attribute.String("category_id", ruleMatch.CategoryID.String()),
-			attribute.Float64("confidence", ruleMatch.Confidence),
-			attribute.String("source", "rule"),
-		)
 		return ruleMatch, nil
 	}
 
 	// If no high-confidence rule match, try ML-based categorization
-	mlMatch, err := s.categorizeByML(ctx, req)
+	mlMatch, err := s.categorizeByML(ctx, req, categories)
 	if err != nil {
-		span.RecordError(err)
-		span.SetStatus(codes.Error, err.Error())
 		return nil, fmt.Errorf("failed to categorize by ML: %w", err)
 	}
 
 	if mlMatch != nil {
-		span.SetAttributes(
-			attribute.String("category_id", mlMatch.CategoryID.String()),
-			attribute.Float64("confidence", mlMatch.Confidence),
-			attribute.String("source", "ml"),
-		)
 		return mlMatch, nil
 	}
 
 	// If no categorization found, return a default response
-	defaultResponse := &CategorizationResponse{
+	return &CategorizationResponse{
 		CategoryID:           uuid.Nil,
 		CategoryName:         "Uncategorized",
 		Confidence:           0.0,
 		CategorizationSource: "manual",
-	}
-
-	span.SetAttributes(
-		attribute.String("category_id", "uncategorized"),
-		attribute.Float64("confidence", 0.0),
-		attribute.String("source", "manual"),
-	)
-
-	return defaultResponse, nil
+	}, nil
 }
 
-// categorizeByRules categorizes a transaction using rule-based matching
-func (s *service) categorizeByRules(ctx context.Context, req *CategorizationRequest) (*CategorizationResponse, error) {
-	rules, err := s.repo.GetActiveCategorizationRules(ctx)
+// categorizeByRules categorizes a transaction using rule-based matching. The rules of the
+// user and their families apply along with those that apply to everyone
+func (s *service) categorizeByRules(ctx context.Context, req *CategorizationRequest, categories map[uuid.UUID]*Category) (*CategorizationResponse, error) {
+	rules, err := s.compiledRules(ctx, req.UserID)
 	if err != nil {
 		return nil, err
 	}
 
-	// Sort rules by priority (higher priority first)
-	sort.Slice(rules, func(i, j int) bool {
-		return rules[i].Priority > rules[j].Priority
+	// Rules whose category no longer exists are passed over
+	var rule *CategorizationRule
+	var category *Category
+	rules.eachMatch(req, seeds.ResolveLocale(req.Locale), func(matched *CategorizationRule) bool {
+		rule, category = matched, s.getCachedCategory(ctx, matched.CategoryID, categories)
+		return category == nil
 	})
-
-	text := strings.ToLower(req.Description)
-	if req.Merchant != "" {
-		text += " " + strings.ToLower(req.Merchant)
-	}
-
-	for _, rule := range rules {
-		if !rule.IsActive {
-			continue
-		}
-
-		var matched bool
-		switch rule.PatternType {
-		case "exact":
-			matched = strings.Contains(text, strings.ToLower(rule.Pattern))
-		case "keyword":
-			keywords := strings.Split(strings.ToLower(rule.Pattern), " ")
-			matched = true
-			for _, keyword := range keywords {
-				if !strings.Contains(text, keyword) {
-					matched = false
-					break
-				}
-			}
-		case "regex":
-			re, err := regexp.Compile(strings.ToLower(rule.Pattern))
-			if err != nil {
-				continue // Skip invalid regex
-			}
-			matched = re.MatchString(text)
-		}
-
-		if matched {
-			// Get category name
-			category, err := s.repo.GetCategoryByID(ctx, rule.CategoryID)
-			if err != nil {
-				continue
-			}
-
-			confidence := s.calculateRuleConfidence(&rule, req.Amount)
-
-			return &CategorizationResponse{
-				CategoryID:           rule.CategoryID,
-				CategoryName:         category.Name,
-				Confidence:           confidence,
-				CategorizationSource: "rule",
-				MatchedPattern:       rule.Pattern,
-			}, nil
-		}
-	}
-
-	return nil, nil
-}
-
-// categorizeByML categorizes a transaction using simple ML (keyword frequency analysis)
-func (s *service) categorizeByML(ctx context.Context, req *CategorizationRequest) (*CategorizationResponse, error) {
-	// Simple ML approach: keyword frequency analysis based on historical data
-	// In a real implementation, this would use a trained model
-
-	text := strings.ToLower(req.Description)
-	if req.Merchant != "" {
-		text += " " + strings.ToLower(req.Merchant)
-	}
-
-	// Get historical categorization data for similar transactions
-	similarTransactions, err := s.repo.GetSimilarTransactions(ctx, text, 10)
-	if err != nil {
-		return nil, err
-	}
-
-	if len(similarTransactions) == 0 {
+	if category == nil {
 		return nil, nil
 	}
 
-	// Count category frequencies
-	categoryCounts := make(map[uuid.UUID]int)
-	totalCount := 0
+	confidence := s.calculateRuleConfidence(rule, req.Amount)
 
-	for _, tx := range similarTransactions {
-		if tx.CategoryID != nil {
-			categoryCounts[*tx.CategoryID]++
-			totalCount++
-		}
+	ruleID := rule.ID
+	response := &CategorizationResponse{
+		CategoryID:           rule.CategoryID,
+		CategoryName:         category.Name,
+		Confidence:           confidence,
+		CategorizationSource: "rule",
+		MatchedPattern:       rule.Pattern,
+		RuleID:               &ruleID,
 	}
-
-	if totalCount == 0 {
-		return nil, nil
+	if !rule.Actions.isEmpty() {
+		actions := rule.Actions
+		response.Actions = &actions
 	}
+	return response, nil
+}
 
-	// Find the most common category
-	var bestCategoryID uuid.UUID
-	var bestCount int
-
-	for categoryID, count := range categoryCounts {
-		if count > bestCount {
-			bestCategoryID = categoryID
-			bestCount = count
+// sortRules orders rules by priority, highest first. Of rules with the same priority, the
+// most personal ones come first
+func sortRules(rules []CategorizationRule) {
+	sort.SliceStable(rules, func(i, j int) bool {
+		if rules[i].Priority != rules[j].Priority {
+			return rules[i].Priority > rules[j].Priority
 		}
-	}
-
-	if bestCount == 0 {
-		return nil, nil
-	}
-
-	// Calculate confidence based on frequency
-	confidence := float64(bestCount) / float64(totalCount)
-
-	// Adjust confidence based on amount similarity
-	amountSimilarity := s.calculateAmountSimilarity(req.Amount, similarTransactions)
-	confidence = (confidence + amountSimilarity) / 2
+		return ruleOwnerRank(&rules[i]) < ruleOwnerRank(&rules[j])
+	})
+}
 
-	// Get category name
-	category, err := s.repo.GetCategoryByID(ctx, bestCategoryID)
-	if err != nil {
-		return nil, err
+// categorizationText returns the lowercase text of a transaction patterns are matched against
+func categorizationText(req *CategorizationRequest) string {
+	text := strings.ToLower(req.Description)
+	if req.Merchant != "" && text != "" {
+		text += " "
 	}
-
-	return &CategorizationResponse{
-		CategoryID:           bestCategoryID,
-		CategoryName:         category.Name,
-		Confidence:           confidence,
-		CategorizationSource: "ml",
-	}, nil
+	return text + strings.ToLower(req.Merchant)
 }
 
 // calculateRuleConfidence calculates confidence for rule-based categorization
 func (s *service) calculateRuleConfidence(rule *CategorizationRule, amount float64) float64 {
+	// Users' own rules are what they asked for
+	if isOwnedRule(rule) {
+		return 1.0
+	}
+
 	baseConfidence := 0.8
 
 	// Adjust confidence based on pattern type
@@ -262,37 +214,12 @@ func (s *service) calculateRuleConfidence(rule *CategorizationRule, amount float
 	return math.Min(baseConfidence, 1.0)
 }
 
-// calculateAmountSimilarity calculates similarity based on transaction amounts
-func (s *service) calculateAmountSimilarity(amount float64, transactions []Transaction) float64 {
-	if len(transactions) == 0 {
-		return 0.5
-	}
-
-	// Calculate average amount
-	var totalAmount float64
-	var count int
-	for _, tx := range transactions {
-		totalAmount += tx.Amount
-		count++
-	}
-
-	if count == 0 {
-		return 0.5
-	}
-
-	avgAmount := totalAmount / float64(count)
-
-	// Calculate similarity based on how close the amount is to the average
-	diff := math.Abs(amount - avgAmount)
-	similarity := 1.0 - (diff / avgAmount)
-
-	return math.Max(0.0, math.Min(1.0, similarity))
-}
-
-// CreateCategorizationRule creates a new categorization rule
-func (s *service) CreateCategorizationRule(ctx context.Context, req *CreateCategorizationRuleRequest) (*CategorizationRuleResponse, error) {
+// CreateCategorizationRule creates a categorization rule owned by the user, or shared with
+// one of their families
+func (s *service) CreateCategorizationRule(ctx context.Context, userID uuid.UUID, req *CreateCategorizationRuleRequest) (*CategorizationRuleResponse, error) {
 	ctx, span := otel.Tracer("").Start(ctx, "analytics.CreateCategorizationRule",
 		trace.WithAttributes(
+			attribute.String("user_id", userID.String()),
 			attribute.String("category_id", req.CategoryID.String()),
 			attribute.String("pattern", req.Pattern),
 			attribute.String("pattern_type", req.PatternType),
@@ -300,39 +227,63 @@ func (s *service) CreateCategorizationRule(ctx context.Context, req *CreateCateg
 	)
 	defer span.End()
 
-	// Validate pattern
-	if err := s.validatePattern(req.Pattern, req.PatternType); err != nil {
-		span.RecordError(err)
-		span.SetStatus(codes.Error, err.Error())
-		return nil, err
-	}
-
 	rule := &CategorizationRule{
 		CategoryID:  req.CategoryID,
 		Pattern:     req.Pattern,
 		PatternType: req.PatternType,
+		Conditions:  req.Conditions,
+		Actions:     req.Actions,
 		Priority:    req.Priority,
 		IsActive:    true,
 	}
+	if req.FamilyID != nil {
+		isMember, err := s.isFamilyMember(ctx, userID, *req.FamilyID)
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+			return nil, err
+		}
+		if !isMember {
+			span.SetStatus(codes.Error, ErrCategorizationRuleNotOwned.Error())
+			return nil, fmt.Errorf("%w: not a member of the family", ErrCategorizationRuleNotOwned)
+		}
+		rule.FamilyID = req.FamilyID
+	} else {
+		rule.UserID = &userID
+	}
+
+	if err := s.validateRule(rule); err != nil {
+		span.SetStatus(codes.Error, err.Error())
+		return nil, err
+	}
+	if err := s.validateRuleCategory(ctx, rule); err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, err
+	}
 
 	if err := s.repo.CreateCategorizationRule(ctx, rule); err != nil {
 		span.RecordError(err)
 		span.SetStatus(codes.Error, err.Error())
 		return nil, err
 	}
+	s.rules.invalidate()
 
 	span.SetAttributes(attribute.String("rule_id", rule.ID.String()))
 	return s.toCategorizationRuleResponse(rule), nil
 }
 
-// GetCategorizationRule retrieves a categorization rule
-func (s *service) GetCategorizationRule(ctx context.Context, id uuid.UUID) (*CategorizationRuleResponse, error) {
+// GetCategorizationRule retrieves a categorization rule that applies to the user
+func (s *service) GetCategorizationRule(ctx context.Context, userID, id uuid.UUID) (*CategorizationRuleResponse, error) {
 	ctx, span := otel.Tracer("").Start(ctx, "analytics.GetCategorizationRule",
-		trace.WithAttributes(attribute.String("rule_id", id.String())),
+		trace.WithAttributes(
+			attribute.String("user_id", userID.String()),
+			attribute.String("rule_id", id.String()),
+		),
 	)
 	defer span.End()
 
-	rule, err := s.repo.GetCategorizationRuleByID(ctx, id)
+	rule, err := s.getVisibleRule(ctx, userID, id)
 	if err != nil {
 		span.RecordError(err)
 		span.SetStatus(codes.Error, err.Error())
@@ -342,17 +293,18 @@ func (s *service) GetCategorizationRule(ctx context.Context, id uuid.UUID) (*Cat
 	return s.toCategorizationRuleResponse(rule), nil
 }
 
-// ListCategorizationRules retrieves categorization rules
-func (s *service) ListCategorizationRules(ctx context.Context, offset, limit int) ([]CategorizationRuleResponse, error) {
+// ListCategorizationRules retrieves the categorization rules that apply to the user
+func (s *service) ListCategorizationRules(ctx context.Context, userID uuid.UUID, offset, limit int) ([]CategorizationRuleResponse, error) {
 	ctx, span := otel.Tracer("").Start(ctx, "analytics.ListCategorizationRules",
 		trace.WithAttributes(
+			attribute.String("user_id", userID.String()),
 			attribute.Int("offset", offset),
 			attribute.Int("limit", limit),
 		),
 	)
 	defer span.End()
 
-	rules, err := s.repo.GetCategorizationRules(ctx, offset, limit)
+	rules, err := s.repo.GetCategorizationRules(ctx, userID, offset, limit)
 	if err != nil {
 		span.RecordError(err)
 		span.SetStatus(codes.Error, err.Error())
@@ -368,14 +320,17 @@ func (s *service) ListCategorizationRules(ctx context.Context, offset, limit int
 	return responses, nil
 }
 
-// UpdateCategorizationRule updates a categorization rule
-func (s *service) UpdateCategorizationRule(ctx context.Context, id uuid.UUID, req *UpdateCategorizationRuleRequest) (*CategorizationRuleResponse, error) {
+// UpdateCategorizationRule updates a categorization rule of the user or their families
+func (s *service) UpdateCategorizationRule(ctx context.Context, userID, id uuid.UUID, req *UpdateCategorizationRuleRequest) (*CategorizationRuleResponse, error) {
 	ctx, span := otel.Tracer("").Start(ctx, "analytics.UpdateCategorizationRule",
-		trace.WithAttributes(attribute.String("rule_id", id.String())),
+		trace.WithAttributes(
+			attribute.String("user_id", userID.String()),
+			attribute.String("rule_id", id.String()),
+		),
 	)
 	defer span.End()
 
-	rule, err := s.repo.GetCategorizationRuleByID(ctx, id)
+	rule, err := s.getOwnedRule(ctx, userID, id)
 	if err != nil {
 		span.RecordError(err)
 		span.SetStatus(codes.Error, err.Error())
@@ -389,6 +344,12 @@ func (s *service) UpdateCategorizationRule(ctx context.Context, id uuid.UUID, re
 	if req.PatternType != nil {
 		rule.PatternType = *req.PatternType
 	}
+	if req.Conditions != nil {
+		rule.Conditions = *req.Conditions
+	}
+	if req.Actions != nil {
+		rule.Actions = *req.Actions
+	}
 	if req.Priority != nil {
 		rule.Priority = *req.Priority
 	}
@@ -396,13 +357,14 @@ func (s *service) UpdateCategorizationRule(ctx context.Context, id uuid.UUID, re
 		rule.IsActive = *req.IsActive
 	}
 
-	// Validate pattern if updated
-	if req.Pattern != nil || req.PatternType != nil {
-		if err := s.validatePattern(rule.Pattern, rule.PatternType); err != nil {
-			span.RecordError(err)
-			span.SetStatus(codes.Error, err.Error())
-			return nil, err
-		}
+	if err := s.validateRule(rule); err != nil {
+		span.SetStatus(codes.Error, err.Error())
+		return nil, err
+	}
+	if err := s.validateRuleCategory(ctx, rule); err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, err
 	}
 
 	if err := s.repo.UpdateCategorizationRule(ctx, rule); err != nil {
@@ -410,26 +372,72 @@ func (s *service) UpdateCategorizationRule(ctx context.Context, id uuid.UUID, re
 		span.SetStatus(codes.Error, err.Error())
 		return nil, err
 	}
+	s.rules.invalidate()
 
 	return s.toCategorizationRuleResponse(rule), nil
 }
 
-// DeleteCategorizationRule deletes a categorization rule
-func (s *service) DeleteCategorizationRule(ctx context.Context, id uuid.UUID) error {
+// DeleteCategorizationRule deletes a categorization rule of the user or their families
+func (s *service) DeleteCategorizationRule(ctx context.Context, userID, id uuid.UUID) error {
 	ctx, span := otel.Tracer("").Start(ctx, "analytics.DeleteCategorizationRule",
-		trace.WithAttributes(attribute.String("rule_id", id.String())),
+		trace.WithAttributes(
+			attribute.String("user_id", userID.String()),
+			attribute.String("rule_id", id.String()),
+		),
 	)
 	defer span.End()
 
+	if _, err := s.getOwnedRule(ctx, userID, id); err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return err
+	}
+
 	if err := s.repo.DeleteCategorizationRule(ctx, id); err != nil {
 		span.RecordError(err)
 		span.SetStatus(codes.Error, err.Error())
 		return err
 	}
+	s.rules.invalidate()
 
 	return nil
 }
 
+// getVisibleRule retrieves a rule that applies to the user's transactions
+func (s *service) getVisibleRule(ctx context.Context, userID, id uuid.UUID) (*CategorizationRule, error) {
+	rule, err := s.repo.GetCategorizationRuleByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	visible, err := s.canSeeRule(ctx, userID, rule)
+	if err != nil {
+		return nil, err
+	}
+	if !visible {
+		return nil, ErrCategorizationRuleNotFound
+	}
+	return rule, nil
+}
+
+// getOwnedRule retrieves a rule the user may change. Rules that apply to everyone are
+// visible to users but owned by none
+func (s *service) getOwnedRule(ctx context.Context, userID, id uuid.UUID) (*CategorizationRule, error) {
+	rule, err := s.getVisibleRule(ctx, userID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	owned, err := s.ownsRule(ctx, userID, rule)
+	if err != nil {
+		return nil, err
+	}
+	if !owned {
+		return nil, ErrCategorizationRuleNotOwned
+	}
+	return rule, nil
+}
+
 // AnalyzeSpending analyzes spending for a user
 func (s *service) AnalyzeSpending(ctx context.Context, userID uuid.UUID, req *SpendingAnalysisRequest) (*SpendingAnalysisResponse, error) {
 	ctx, span := otel.Tracer("").Start(ctx, "analytics.AnalyzeSpending",
@@ -441,17 +449,45 @@ func (s *service) AnalyzeSpending(ctx context.Context, userID uuid.UUID, req *Sp
 	)
 	defer span.End()
 
-	// Get transactions for the period
-	transactions, err := s.repo.GetTransactionsByPeriod(ctx, userID, req.StartDate, req.EndDate)
+	groupBy, err := validateGroupBy(req.GroupBy)
+	if err != nil {
+		span.SetStatus(codes.Error, err.Error())
+		return nil, err
+	}
+	if req.EndDate.Before(req.StartDate) {
+		err := fmt.Errorf("%w: end date must be after start date", ErrInvalidAnalysisRequest)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, err
+	}
+
+	location, err := s.trendLocation(ctx, userID, req.Timezone)
 	if err != nil {
 		span.RecordError(err)
 		span.SetStatus(codes.Error, err.Error())
 		return nil, err
 	}
 
+	// The period covers whole days in the user's timezone, from the start of the start date
+	// to the end of the end date
+	periodStart := localDay(req.StartDate, location)
+	periodEnd := localDay(req.EndDate, location)
+	endOfPeriod := periodEnd.AddDate(0, 0, 1).Add(-time.Nanosecond)
+
+	// Get transactions for the period. Transfers move money rather than spend or earn it,
+	// so they only count towards savings goals
+	transactions, err := s.repo.GetTransactionsByPeriod(ctx, userID, periodStart, endOfPeriod)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, err
+	}
+	goalTransactions := transactions
+	transactions = withoutTransfers(transactions)
+
 	// Calculate basic metrics
 	var totalSpent, totalIncome float64
 	categorySpending := make(map[uuid.UUID]*CategorySpending)
+	categories := make(map[uuid.UUID]*Category)
 
 	for _, tx := range transactions {
 		if tx.Amount < 0 {
@@ -461,18 +497,19 @@ func (s *service) AnalyzeSpending(ctx context.Context, userID uuid.UUID, req *Sp
 		}
 
 		if tx.CategoryID != nil {
-			if spending, exists := categorySpending[*tx.CategoryID]; exists {
+			categoryID := *tx.CategoryID
+			categoryName := "Uncategorized"
+			if category := s.resolveCategory(ctx, categoryID, req.CategoryLevel, categories); category != nil {
+				categoryID = category.ID
+				categoryName = category.Name
+			}
+
+			if spending, exists := categorySpending[categoryID]; exists {
 				spending.Amount += math.Abs(tx.Amount)
 				spending.TransactionCount++
 			} else {
-				category, _ := s.repo.GetCategoryByID(ctx, *tx.CategoryID)
-				categoryName := "Uncategorized"
-				if category != nil {
-					categoryName = category.Name
-				}
-
-				categorySpending[*tx.CategoryID] = &CategorySpending{
-					CategoryID:       *tx.CategoryID,
+				categorySpending[categoryID] = &CategorySpending{
+					CategoryID:       categoryID,
 					CategoryName:     categoryName,
 					Amount:           math.Abs(tx.Amount),
 					TransactionCount: 1,
@@ -491,21 +528,46 @@ func (s *service) AnalyzeSpending(ctx context.Context, userID uuid.UUID, req *Sp
 	// Get top categories
 	topCategories := s.getTopCategories(categorySpending, 5)
 
+	// Get top merchants
+	topMerchants := s.getTopMerchants(transactions, totalSpent, 10, location)
+
 	// Generate spending trends
-	spendingTrends := s.generateSpendingTrends(transactions, req.GroupBy)
+	spendingTrends, err := s.generateSpendingTrends(transactions, groupBy, periodStart, periodEnd, location)
+	if err != nil {
+		span.SetStatus(codes.Error, err.Error())
+		return nil, err
+	}
+
+	// Get contributions to savings goals
+	goals, err := s.repo.GetActiveGoalsByUser(ctx, userID)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, err
+	}
+	goalContributions := s.getGoalContributions(goals, goalTransactions)
 
 	// Generate insights
 	insights := s.generateSpendingInsights(transactions, categorySpending, totalSpent, totalIncome)
+	anomalies, err := s.detectAnomalies(ctx, userID, periodStart, periodEnd, transactions, categories)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, err
+	}
+	insights = append(insights, anomalies...)
 
 	response := &SpendingAnalysisResponse{
-		PeriodStart:       req.StartDate,
-		PeriodEnd:         req.EndDate,
+		PeriodStart:       periodStart,
+		PeriodEnd:         periodEnd,
 		TotalSpent:        totalSpent,
 		TotalIncome:       totalIncome,
 		NetAmount:         totalIncome - totalSpent,
 		CategoryBreakdown: s.mapToSlice(categorySpending),
 		TopCategories:     topCategories,
+		TopMerchants:      topMerchants,
 		SpendingTrends:    spendingTrends,
+		GoalContributions: goalContributions,
 		Insights:          insights,
 	}
 
@@ -529,16 +591,18 @@ func (s *service) GetSpendingInsights(ctx context.Context, userID uuid.UUID, per
 	)
 	defer span.End()
 
-	// Get transactions for the period
+	// Get transactions for the period, leaving out transfers
 	transactions, err := s.repo.GetTransactionsByPeriod(ctx, userID, periodStart, periodEnd)
 	if err != nil {
 		span.RecordError(err)
 		span.SetStatus(codes.Error, err.Error())
 		return nil, err
 	}
+	transactions = withoutTransfers(transactions)
 
 	// Calculate category spending
 	categorySpending := make(map[uuid.UUID]*CategorySpending)
+	categories := make(map[uuid.UUID]*Category)
 	var totalSpent, totalIncome float64
 
 	for _, tx := range transactions {
@@ -549,18 +613,19 @@ func (s *service) GetSpendingInsights(ctx context.Context, userID uuid.UUID, per
 		}
 
 		if tx.CategoryID != nil {
-			if spending, exists := categorySpending[*tx.CategoryID]; exists {
+			categoryID := *tx.CategoryID
+			categoryName := "Uncategorized"
+			if category := s.resolveCategory(ctx, categoryID, nil, categories); category != nil {
+				categoryID = category.ID
+				categoryName = category.Name
+			}
+
+			if spending, exists := categorySpending[categoryID]; exists {
 				spending.Amount += math.Abs(tx.Amount)
 				spending.TransactionCount++
 			} else {
-				category, _ := s.repo.GetCategoryByID(ctx, *tx.CategoryID)
-				categoryName := "Uncategorized"
-				if category != nil {
-					categoryName = category.Name
-				}
-
-				categorySpending[*tx.CategoryID] = &CategorySpending{
-					CategoryID:       *tx.CategoryID,
+				categorySpending[categoryID] = &CategorySpending{
+					CategoryID:       categoryID,
 					CategoryName:     categoryName,
 					Amount:           math.Abs(tx.Amount),
 					TransactionCount: 1,
@@ -570,6 +635,13 @@ func (s *service) GetSpendingInsights(ctx context.Context, userID uuid.UUID, per
 	}
 
 	insights := s.generateSpendingInsights(transactions, categorySpending, totalSpent, totalIncome)
+	anomalies, err := s.detectAnomalies(ctx, userID, periodStart, periodEnd, transactions, categories)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, err
+	}
+	insights = append(insights, anomalies...)
 
 	span.SetAttributes(attribute.Int("insights_count", len(insights)))
 	return insights, nil
@@ -593,6 +665,57 @@ func (s *service) validatePattern(pattern, patternType string) error {
 	return nil
 }
 
+// resolveCategory returns the category spending is reported under. When level is set,
+// categories below that depth are rolled up into their ancestor at that depth
+func (s *service) resolveCategory(ctx context.Context, categoryID uuid.UUID, level *int, cache map[uuid.UUID]*Category) *Category {
+	category := s.getCachedCategory(ctx, categoryID, cache)
+	if category == nil || level == nil {
+		return category
+	}
+
+	// Build the path from the top-level category down to this one
+	path := []*Category{category}
+	seen := map[uuid.UUID]bool{category.ID: true}
+	for current := category; current.ParentID != nil && !seen[*current.ParentID]; {
+		parent := s.getCachedCategory(ctx, *current.ParentID, cache)
+		if parent == nil {
+			break
+		}
+		seen[parent.ID] = true
+		path = append([]*Category{parent}, path...)
+		current = parent
+	}
+
+	if *level < 0 {
+		return path[0]
+	}
+	if *level < len(path) {
+		return path[*level]
+	}
+	return category
+}
+
+// withoutTransfers returns the transactions that aren't transfers between accounts
+func withoutTransfers(transactions []Transaction) []Transaction {
+	kept := make([]Transaction, 0, len(transactions))
+	for _, tx := range transactions {
+		if !tx.IsTransfer {
+			kept = append(kept, tx)
+		}
+	}
+	return kept
+}
+
+// getCachedCategory looks up a category, remembering the result for the current request
+func (s *service) getCachedCategory(ctx context.Context, categoryID uuid.UUID, cache map[uuid.UUID]*Category) *Category {
+	if category, exists := cache[categoryID]; exists {
+		return category
+	}
+	category, _ := s.repo.GetCategoryByID(ctx, categoryID)
+	cache[categoryID] = category
+	return category
+}
+
 func (s *service) getTopCategories(categorySpending map[uuid.UUID]*CategorySpending, limit int) []CategorySpending {
 	// Convert map to slice
 	categories := make([]CategorySpending, 0, len(categorySpending))
@@ -612,32 +735,112 @@ func (s *service) getTopCategories(categorySpending map[uuid.UUID]*CategorySpend
 	return categories
 }
 
-func (s *service) generateSpendingTrends(transactions []Transaction, groupBy string) []SpendingTrend {
-	// Simplified trend generation
-	// In a real implementation, this would analyze historical data
-
-	trends := []SpendingTrend{
-		{
-			Period: "Week 1",
-			Amount: 500.0,
-			Change: 0.0,
-			Trend:  "stable",
-		},
-		{
-			Period: "Week 2",
-			Amount: 550.0,
-			Change: 10.0,
-			Trend:  "increasing",
-		},
-		{
-			Period: "Week 3",
-			Amount: 480.0,
-			Change: -12.7,
-			Trend:  "decreasing",
-		},
-	}
-
-	return trends
+func (s *service) getTopMerchants(transactions []Transaction, totalSpent float64, limit int, location *time.Location) []MerchantSpending {
+	merchantSpending := make(map[string]*MerchantSpending)
+	periodSpending := make(map[string]map[string]float64)
+
+	for _, tx := range transactions {
+		if tx.Amount >= 0 || tx.Merchant == "" {
+			continue
+		}
+
+		key := merchantKey(tx)
+
+		spending, exists := merchantSpending[key]
+		if !exists {
+			spending = &MerchantSpending{
+				MerchantID:   tx.MerchantID,
+				MerchantName: tx.Merchant,
+			}
+			merchantSpending[key] = spending
+			periodSpending[key] = make(map[string]float64)
+		}
+
+		amount := math.Abs(tx.Amount)
+		spending.Amount += amount
+		spending.TransactionCount++
+		periodSpending[key][bucketStart(tx.TransactionDate.In(location), "month").Format("2006-01")] += amount
+	}
+
+	merchants := make([]MerchantSpending, 0, len(merchantSpending))
+	for key, spending := range merchantSpending {
+		if totalSpent > 0 {
+			spending.Percentage = (spending.Amount / totalSpent) * 100
+		}
+
+		periods := make([]string, 0, len(periodSpending[key]))
+		for period := range periodSpending[key] {
+			periods = append(periods, period)
+		}
+		sort.Strings(periods)
+
+		spending.SpendOverTime = make([]MerchantPeriodSpending, 0, len(periods))
+		for _, period := range periods {
+			spending.SpendOverTime = append(spending.SpendOverTime, MerchantPeriodSpending{
+				Period: period,
+				Amount: periodSpending[key][period],
+			})
+		}
+
+		merchants = append(merchants, *spending)
+	}
+
+	// Sort by amount (descending), then name for a stable order
+	sort.Slice(merchants, func(i, j int) bool {
+		if merchants[i].Amount != merchants[j].Amount {
+			return merchants[i].Amount > merchants[j].Amount
+		}
+		return merchants[i].MerchantName < merchants[j].MerchantName
+	})
+
+	// Return top N merchants
+	if len(merchants) > limit {
+		return merchants[:limit]
+	}
+	return merchants
+}
+
+// getGoalContributions sums the contributions to each goal. Transactions on a goal's linked
+// accounts count with their sign, so withdrawals reduce the contribution. Transactions on
+// other accounts tagged with its contribution tag count as contributions whatever their sign
+func (s *service) getGoalContributions(goals []Goal, transactions []Transaction) []GoalContribution {
+	contributions := make([]GoalContribution, 0, len(goals))
+	for _, goal := range goals {
+		linked := make(map[uuid.UUID]bool, len(goal.Accounts))
+		for _, account := range goal.Accounts {
+			linked[account.AccountID] = true
+		}
+
+		contribution := GoalContribution{GoalID: goal.ID, GoalName: goal.Name}
+		for _, tx := range transactions {
+			if tx.TransactionDate.Before(goal.StartDate) || tx.Status == "cancelled" {
+				continue
+			}
+
+			switch {
+			case linked[tx.AccountID]:
+				contribution.Amount += tx.Amount
+			case goal.ContributionTag != "" && hasTag(tx.Tags, goal.ContributionTag):
+				contribution.Amount += math.Abs(tx.Amount)
+			default:
+				continue
+			}
+			contribution.TransactionCount++
+		}
+
+		contribution.Amount = math.Round(contribution.Amount*100) / 100
+		contributions = append(contributions, contribution)
+	}
+	return contributions
+}
+
+func hasTag(tags []string, tag string) bool {
+	for _, t := range tags {
+		if t == tag {
+			return true
+		}
+	}
+	return false
 }
 
 func (s *service) generateSpendingInsights(transactions []Transaction, categorySpending map[uuid.UUID]*CategorySpending, totalSpent, totalIncome float64) []SpendingInsight {
@@ -722,8 +925,10 @@ func (s *service) toCategorizationRuleResponse(rule *CategorizationRule) *Catego
 		categoryName = category.Name
 	}
 
-	return &CategorizationRuleResponse{
+	response := &CategorizationRuleResponse{
 		ID:           rule.ID,
+		UserID:       rule.UserID,
+		FamilyID:     rule.FamilyID,
 		CategoryID:   rule.CategoryID,
 		CategoryName: categoryName,
 		Pattern:      rule.Pattern,
@@ -733,4 +938,13 @@ func (s *service) toCategorizationRuleResponse(rule *CategorizationRule) *Catego
 		CreatedAt:    rule.CreatedAt,
 		UpdatedAt:    rule.UpdatedAt,
 	}
+	if !rule.Conditions.isEmpty() {
+		conditions := rule.Conditions
+		response.Conditions = &conditions
+	}
+	if !rule.Actions.isEmpty() {
+		actions := rule.Actions
+		response.Actions = &actions
+	}
+	return response
 }
@@ -2,7 +2,9 @@ package analytics_test
 
 import (
 	"context"
+	"fmt"
 	"testing"
+	"time"
 
 	"github.com/golang/mock/gomock"
 	"github.com/google/uuid"
@@ -35,7 +37,10 @@ func TestCategorizeTransaction_RuleBased(t *testing.T) {
 		Priority:    1,
 		IsActive:    true,
 	}
-	mockRepo.EXPECT().GetActiveCategorizationRules(gomock.Any()).Return([]analytics.CategorizationRule{rule}, nil)
+	// Rules whose category was deleted leave the transaction to the next rule
+	stale := analytics.CategorizationRule{ID: uuid.New(), CategoryID: uuid.New(), Pattern: "walmart", PatternType: "keyword", Priority: 5, IsActive: true}
+	mockRepo.EXPECT().GetActiveCategorizationRules(gomock.Any(), gomock.Any()).Return([]analytics.CategorizationRule{rule, stale}, nil)
+	mockRepo.EXPECT().GetCategoryByID(gomock.Any(), stale.CategoryID).Return(nil, analytics.ErrCategoryNotFound)
 	mockRepo.EXPECT().GetCategoryByID(gomock.Any(), rule.CategoryID).Return(&analytics.Category{ID: rule.CategoryID, Name: "Food & Groceries"}, nil)
 
 	request := &analytics.CategorizationRequest{
@@ -50,3 +55,1416 @@ func TestCategorizeTransaction_RuleBased(t *testing.T) {
 	assert.Equal(t, "Food & Groceries", resp.CategoryName)
 	assert.Equal(t, "rule", resp.CategorizationSource)
 }
+
+func TestAnalyzeSpending_TopMerchants(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	mockRepo := mocks.NewMockRepository(ctrl)
+	service := analytics.NewService(mockRepo)
+
+	userID := uuid.New()
+	amazonID := uuid.New()
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	end := time.Date(2024, 2, 29, 0, 0, 0, 0, time.UTC)
+
+	transactions := []analytics.Transaction{
+		{ID: uuid.New(), MerchantID: &amazonID, Merchant: "Amazon", Amount: -40, TransactionDate: time.Date(2024, 1, 5, 0, 0, 0, 0, time.UTC)},
+		{ID: uuid.New(), MerchantID: &amazonID, Merchant: "Amazon", Amount: -60, TransactionDate: time.Date(2024, 2, 5, 0, 0, 0, 0, time.UTC)},
+		{ID: uuid.New(), Merchant: "Corner Shop", Amount: -25, TransactionDate: time.Date(2024, 1, 10, 0, 0, 0, 0, time.UTC)},
+		{ID: uuid.New(), Merchant: "Employer", Amount: 2000, TransactionDate: time.Date(2024, 1, 31, 0, 0, 0, 0, time.UTC)},
+		// Transfers are neither spending nor income
+		{ID: uuid.New(), Merchant: "Savings", Amount: -500, IsTransfer: true, TransactionDate: time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC)},
+		{ID: uuid.New(), Merchant: "Brokerage", Amount: 300, IsTransfer: true, TransactionDate: time.Date(2024, 2, 2, 0, 0, 0, 0, time.UTC)},
+	}
+	mockRepo.EXPECT().GetTransactionsByPeriod(gomock.Any(), userID, start, end.AddDate(0, 0, 1).Add(-time.Nanosecond)).Return(transactions, nil)
+	mockRepo.EXPECT().GetTransactionsByPeriod(gomock.Any(), userID, start.AddDate(0, -6, 0), gomock.Any()).Return(nil, nil)
+	mockRepo.EXPECT().GetUserTimezone(gomock.Any(), userID).Return("UTC", nil)
+	mockRepo.EXPECT().GetActiveGoalsByUser(gomock.Any(), userID).Return(nil, nil)
+
+	resp, err := service.AnalyzeSpending(context.Background(), userID, &analytics.SpendingAnalysisRequest{StartDate: start, EndDate: end})
+	assert.NoError(t, err)
+	assert.Equal(t, 125.0, resp.TotalSpent)
+	assert.Equal(t, 2000.0, resp.TotalIncome)
+	assert.Len(t, resp.TopMerchants, 2)
+
+	amazon := resp.TopMerchants[0]
+	assert.Equal(t, &amazonID, amazon.MerchantID)
+	assert.Equal(t, "Amazon", amazon.MerchantName)
+	assert.Equal(t, 100.0, amazon.Amount)
+	assert.Equal(t, 80.0, amazon.Percentage)
+	assert.Equal(t, 2, amazon.TransactionCount)
+	assert.Equal(t, []analytics.MerchantPeriodSpending{{Period: "2024-01", Amount: 40}, {Period: "2024-02", Amount: 60}}, amazon.SpendOverTime)
+
+	assert.Nil(t, resp.TopMerchants[1].MerchantID)
+	assert.Equal(t, "Corner Shop", resp.TopMerchants[1].MerchantName)
+}
+
+func TestAnalyzeSpending_TopMerchantsInUserTimezone(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	mockRepo := mocks.NewMockRepository(ctrl)
+	service := analytics.NewService(mockRepo)
+
+	userID := uuid.New()
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	end := time.Date(2024, 2, 29, 0, 0, 0, 0, time.UTC)
+
+	// Late on January 31st in UTC is already February in Berlin
+	transactions := []analytics.Transaction{
+		{ID: uuid.New(), Merchant: "Corner Shop", Amount: -25, TransactionDate: time.Date(2024, 1, 10, 12, 0, 0, 0, time.UTC)},
+		{ID: uuid.New(), Merchant: "Corner Shop", Amount: -15, TransactionDate: time.Date(2024, 1, 31, 23, 30, 0, 0, time.UTC)},
+	}
+	mockRepo.EXPECT().GetTransactionsByPeriod(gomock.Any(), userID, gomock.Any(), gomock.Any()).Return(transactions, nil)
+	mockRepo.EXPECT().GetTransactionsByPeriod(gomock.Any(), userID, gomock.Any(), gomock.Any()).Return(nil, nil)
+	mockRepo.EXPECT().GetUserTimezone(gomock.Any(), userID).Return("Europe/Berlin", nil)
+	mockRepo.EXPECT().GetActiveGoalsByUser(gomock.Any(), userID).Return(nil, nil)
+
+	resp, err := service.AnalyzeSpending(context.Background(), userID, &analytics.SpendingAnalysisRequest{StartDate: start, EndDate: end})
+	assert.NoError(t, err)
+	if assert.Len(t, resp.TopMerchants, 1) {
+		assert.Equal(t, []analytics.MerchantPeriodSpending{{Period: "2024-01", Amount: 25}, {Period: "2024-02", Amount: 15}}, resp.TopMerchants[0].SpendOverTime)
+	}
+}
+
+func TestAnalyzeSpending_CategoryLevel(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	mockRepo := mocks.NewMockRepository(ctrl)
+	service := analytics.NewService(mockRepo)
+
+	userID := uuid.New()
+	food := analytics.Category{ID: uuid.New(), Name: "Food"}
+	groceries := analytics.Category{ID: uuid.New(), Name: "Groceries", ParentID: &food.ID}
+	restaurants := analytics.Category{ID: uuid.New(), Name: "Restaurants", ParentID: &food.ID}
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	end := time.Date(2024, 1, 31, 0, 0, 0, 0, time.UTC)
+
+	transactions := []analytics.Transaction{
+		{ID: uuid.New(), CategoryID: &groceries.ID, Amount: -80, TransactionDate: start},
+		{ID: uuid.New(), CategoryID: &restaurants.ID, Amount: -20, TransactionDate: start},
+		{ID: uuid.New(), CategoryID: &groceries.ID, Amount: -50, TransactionDate: start},
+	}
+	mockRepo.EXPECT().GetTransactionsByPeriod(gomock.Any(), userID, start, end.AddDate(0, 0, 1).Add(-time.Nanosecond)).Return(transactions, nil)
+	mockRepo.EXPECT().GetTransactionsByPeriod(gomock.Any(), userID, start.AddDate(0, -6, 0), gomock.Any()).Return(nil, nil)
+	mockRepo.EXPECT().GetUserTimezone(gomock.Any(), userID).Return("UTC", nil)
+	mockRepo.EXPECT().GetActiveGoalsByUser(gomock.Any(), userID).Return(nil, nil)
+	// Each category is looked up once per request
+	mockRepo.EXPECT().GetCategoryByID(gomock.Any(), groceries.ID).Return(&groceries, nil)
+	mockRepo.EXPECT().GetCategoryByID(gomock.Any(), restaurants.ID).Return(&restaurants, nil)
+	mockRepo.EXPECT().GetCategoryByID(gomock.Any(), food.ID).Return(&food, nil)
+
+	level := 0
+	resp, err := service.AnalyzeSpending(context.Background(), userID, &analytics.SpendingAnalysisRequest{StartDate: start, EndDate: end, CategoryLevel: &level})
+	assert.NoError(t, err)
+	if assert.Len(t, resp.CategoryBreakdown, 1) {
+		assert.Equal(t, food.ID, resp.CategoryBreakdown[0].CategoryID)
+		assert.Equal(t, "Food", resp.CategoryBreakdown[0].CategoryName)
+		assert.Equal(t, 150.0, resp.CategoryBreakdown[0].Amount)
+		assert.Equal(t, 3, resp.CategoryBreakdown[0].TransactionCount)
+	}
+}
+
+func TestCategorizeTransaction_RuleLocale(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	mockRepo := mocks.NewMockRepository(ctrl)
+	service := analytics.NewService(mockRepo)
+
+	german := analytics.CategorizationRule{ID: uuid.New(), CategoryID: uuid.New(), Pattern: "amazon", PatternType: "keyword", Priority: 1, IsActive: true, Locale: "de-DE"}
+	american := analytics.CategorizationRule{ID: uuid.New(), CategoryID: uuid.New(), Pattern: "amazon", PatternType: "keyword", IsActive: true, Locale: "en-US"}
+	mockRepo.EXPECT().GetActiveCategorizationRules(gomock.Any(), gomock.Any()).Return([]analytics.CategorizationRule{german, american}, nil)
+	mockRepo.EXPECT().GetCategoryByID(gomock.Any(), american.CategoryID).Return(&analytics.Category{ID: american.CategoryID, Name: "Online Shopping"}, nil)
+
+	// Without a locale the default locale's starter rules apply
+	resp, err := service.CategorizeTransaction(context.Background(), &analytics.CategorizationRequest{Description: "AMAZON MKTP", Amount: 20})
+	assert.NoError(t, err)
+	assert.Equal(t, american.CategoryID, resp.CategoryID)
+}
+
+func TestCategorizeTransaction_RuleConditions(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	mockRepo := mocks.NewMockRepository(ctrl)
+	service := analytics.NewService(mockRepo)
+
+	userID := uuid.New()
+	savingsID := uuid.New()
+	minAmount := 500.0
+	saturday := time.Date(2024, 3, 2, 0, 0, 0, 0, time.UTC)
+
+	// Large transfers out of the savings account, whatever their description
+	transfer := analytics.CategorizationRule{
+		ID: uuid.New(), UserID: &userID, CategoryID: uuid.New(), IsActive: true,
+		Conditions: analytics.RuleConditions{MinAmount: &minAmount, Sign: analytics.RuleSignExpense, AccountIDs: []uuid.UUID{savingsID}},
+		Actions:    analytics.RuleActions{Tags: []string{"savings"}, IsTransfer: true},
+	}
+	// Weekend coffee, by pattern or merchant
+	coffee := analytics.CategorizationRule{
+		ID: uuid.New(), UserID: &userID, CategoryID: uuid.New(), Pattern: "coffee", PatternType: "keyword", IsActive: true,
+		Conditions: analytics.RuleConditions{Operator: analytics.RuleOperatorOr, Merchants: []string{"Blue Bottle"}},
+		Actions:    analytics.RuleActions{Notes: "Treats"},
+	}
+	// A starter rule with the same priority comes after the user's own
+	starter := analytics.CategorizationRule{ID: uuid.New(), CategoryID: uuid.New(), Pattern: "blue bottle", PatternType: "exact", IsActive: true}
+
+	mockRepo.EXPECT().GetActiveCategorizationRules(gomock.Any(), &userID).Return([]analytics.CategorizationRule{starter, transfer, coffee}, nil).AnyTimes()
+	mockRepo.EXPECT().GetCategoryByID(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, id uuid.UUID) (*analytics.Category, error) {
+		return &analytics.Category{ID: id, Name: "Category"}, nil
+	}).AnyTimes()
+	mockRepo.EXPECT().GetActiveCategorizationModel(gomock.Any(), gomock.Any()).Return(nil, nil).AnyTimes()
+	mockRepo.EXPECT().GetCategorizationFeedbackByUser(gomock.Any(), userID, gomock.Any()).Return(nil, nil).AnyTimes()
+
+	// All conditions have to match
+	resp, err := service.CategorizeTransaction(context.Background(), &analytics.CategorizationRequest{
+		Description: "Online transfer", Amount: -750, AccountID: &savingsID, TransactionDate: &saturday, UserID: &userID,
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, transfer.CategoryID, resp.CategoryID)
+	assert.Equal(t, 1.0, resp.Confidence)
+	assert.Equal(t, &transfer.ID, resp.RuleID)
+	if assert.NotNil(t, resp.Actions) {
+		assert.True(t, resp.Actions.IsTransfer)
+		assert.Equal(t, []string{"savings"}, resp.Actions.Tags)
+	}
+
+	resp, err = service.CategorizeTransaction(context.Background(), &analytics.CategorizationRequest{
+		Description: "Online transfer", Amount: -750, UserID: &userID,
+	})
+	assert.NoError(t, err)
+	assert.NotEqual(t, transfer.CategoryID, resp.CategoryID)
+
+	// Any condition may match
+	resp, err = service.CategorizeTransaction(context.Background(), &analytics.CategorizationRequest{
+		Description: "Card purchase", Merchant: "blue bottle", Amount: -4.5, UserID: &userID,
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, coffee.CategoryID, resp.CategoryID)
+	if assert.NotNil(t, resp.Actions) {
+		assert.Equal(t, "Treats", resp.Actions.Notes)
+	}
+}
+
+func TestCategorizeTransactions(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	mockRepo := mocks.NewMockRepository(ctrl)
+	service := analytics.NewService(mockRepo)
+	ctx := context.Background()
+
+	userID := uuid.New()
+	groceries := analytics.Category{ID: uuid.New(), Name: "Groceries"}
+	farmers := analytics.Category{ID: uuid.New(), Name: "Farmers Market"}
+	transport := analytics.Category{ID: uuid.New(), Name: "Transport"}
+	rules := []analytics.CategorizationRule{
+		{ID: uuid.New(), UserID: &userID, CategoryID: groceries.ID, Pattern: "supermarket", PatternType: "exact", Priority: 1, IsActive: true},
+		{ID: uuid.New(), UserID: &userID, CategoryID: farmers.ID, Pattern: "fresh market", PatternType: "keyword", Priority: 2, IsActive: true},
+		{ID: uuid.New(), UserID: &userID, CategoryID: transport.ID, Pattern: `^uber\s+(trip|ride)`, PatternType: "regex", IsActive: true},
+	}
+	categories := map[uuid.UUID]*analytics.Category{groceries.ID: &groceries, farmers.ID: &farmers, transport.ID: &transport}
+
+	// Rules are loaded once until one of them changes, and categories once per batch. The
+	// rule created below looks up its category too
+	mockRepo.EXPECT().GetActiveCategorizationRules(gomock.Any(), &userID).Return(rules, nil)
+	mockRepo.EXPECT().GetCategoryByID(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, id uuid.UUID) (*analytics.Category, error) {
+		return categories[id], nil
+	}).Times(6)
+	mockRepo.EXPECT().GetActiveCategorizationModel(gomock.Any(), gomock.Any()).Return(nil, nil).AnyTimes()
+	mockRepo.EXPECT().GetCategorizationFeedbackByUser(gomock.Any(), userID, gomock.Any()).Return(nil, nil).AnyTimes()
+
+	resp, err := service.CategorizeTransactions(ctx, &analytics.BatchCategorizationRequest{
+		UserID: &userID,
+		Transactions: []analytics.CategorizationRequest{
+			{Description: "City Supermarket", Amount: -40},
+			{Description: "Uber Trip", Merchant: "Uber", Amount: -12},
+			{Description: "Library fine", Amount: -2},
+			{Description: "SUPERMARKET #42", Amount: -18},
+			// Keywords are found inside longer patterns too
+			{Description: "Fresh Supermarket", Amount: -25},
+		},
+	})
+	assert.NoError(t, err)
+	if assert.Len(t, resp.Categorizations, 5) {
+		assert.Equal(t, groceries.ID, resp.Categorizations[0].CategoryID)
+		assert.Equal(t, transport.ID, resp.Categorizations[1].CategoryID)
+		assert.Equal(t, uuid.Nil, resp.Categorizations[2].CategoryID)
+		assert.Equal(t, groceries.ID, resp.Categorizations[3].CategoryID)
+		assert.Equal(t, farmers.ID, resp.Categorizations[4].CategoryID)
+		assert.Equal(t, "Farmers Market", resp.Categorizations[4].CategoryName)
+	}
+
+	// Changing a rule makes the next batch load the rules again
+	updated := append([]analytics.CategorizationRule{{
+		ID: uuid.New(), UserID: &userID, CategoryID: transport.ID, Pattern: "library", PatternType: "keyword", IsActive: true,
+	}}, rules...)
+	mockRepo.EXPECT().CreateCategorizationRule(gomock.Any(), gomock.Any()).Return(nil)
+	_, err = service.CreateCategorizationRule(ctx, userID, &analytics.CreateCategorizationRuleRequest{
+		CategoryID: transport.ID, Pattern: "library", PatternType: "keyword",
+	})
+	assert.NoError(t, err)
+	mockRepo.EXPECT().GetActiveCategorizationRules(gomock.Any(), &userID).Return(updated, nil)
+
+	resp, err = service.CategorizeTransactions(ctx, &analytics.BatchCategorizationRequest{
+		UserID:       &userID,
+		Transactions: []analytics.CategorizationRequest{{Description: "Library fine", Amount: -2}},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, transport.ID, resp.Categorizations[0].CategoryID)
+
+	_, err = service.CategorizeTransactions(ctx, &analytics.BatchCategorizationRequest{UserID: &userID})
+	assert.ErrorIs(t, err, analytics.ErrInvalidBatchCategorization)
+}
+
+func TestCategorizationRuleOwnership(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	mockRepo := mocks.NewMockRepository(ctrl)
+	service := analytics.NewService(mockRepo)
+	ctx := context.Background()
+
+	userID := uuid.New()
+	familyID := uuid.New()
+	otherUserID := uuid.New()
+	starter := &analytics.CategorizationRule{ID: uuid.New(), CategoryID: uuid.New(), Pattern: "amazon", PatternType: "keyword", IsActive: true}
+	shared := &analytics.CategorizationRule{ID: uuid.New(), FamilyID: &familyID, CategoryID: uuid.New(), Pattern: "daycare", PatternType: "keyword", IsActive: true}
+	private := &analytics.CategorizationRule{ID: uuid.New(), UserID: &otherUserID, CategoryID: uuid.New(), Pattern: "gym", PatternType: "keyword", IsActive: true}
+	for _, rule := range []*analytics.CategorizationRule{starter, shared, private} {
+		mockRepo.EXPECT().GetCategorizationRuleByID(gomock.Any(), rule.ID).Return(rule, nil).AnyTimes()
+	}
+	mockRepo.EXPECT().GetFamilyIDsByUser(gomock.Any(), userID).Return([]uuid.UUID{familyID}, nil).AnyTimes()
+	mockRepo.EXPECT().GetCategoryByID(gomock.Any(), gomock.Any()).Return(&analytics.Category{Name: "Category"}, nil).AnyTimes()
+
+	// Rules are owned by their creator, or shared with a family they belong to
+	mockRepo.EXPECT().CreateCategorizationRule(gomock.Any(), gomock.Any()).Return(nil).Times(2)
+	created, err := service.CreateCategorizationRule(ctx, userID, &analytics.CreateCategorizationRuleRequest{
+		CategoryID: uuid.New(),
+		Conditions: analytics.RuleConditions{Currencies: []string{"EUR"}},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, &userID, created.UserID)
+
+	created, err = service.CreateCategorizationRule(ctx, userID, &analytics.CreateCategorizationRuleRequest{
+		CategoryID: uuid.New(), FamilyID: &familyID, Pattern: "school", PatternType: "keyword",
+	})
+	assert.NoError(t, err)
+	assert.Nil(t, created.UserID)
+	assert.Equal(t, &familyID, created.FamilyID)
+
+	otherFamilyID := uuid.New()
+	_, err = service.CreateCategorizationRule(ctx, userID, &analytics.CreateCategorizationRuleRequest{
+		CategoryID: uuid.New(), FamilyID: &otherFamilyID, Pattern: "school", PatternType: "keyword",
+	})
+	assert.ErrorIs(t, err, analytics.ErrCategorizationRuleNotOwned)
+
+	// Rules need a pattern or a condition, and well-formed conditions
+	_, err = service.CreateCategorizationRule(ctx, userID, &analytics.CreateCategorizationRuleRequest{CategoryID: uuid.New()})
+	assert.ErrorIs(t, err, analytics.ErrInvalidCategorizationRule)
+	_, err = service.CreateCategorizationRule(ctx, userID, &analytics.CreateCategorizationRuleRequest{
+		CategoryID: uuid.New(), Conditions: analytics.RuleConditions{DaysOfWeek: []int{7}},
+	})
+	assert.ErrorIs(t, err, analytics.ErrInvalidCategorizationRule)
+
+	// Rules that apply to everyone can be seen but not changed, others' rules can't be seen
+	_, err = service.GetCategorizationRule(ctx, userID, starter.ID)
+	assert.NoError(t, err)
+	_, err = service.GetCategorizationRule(ctx, userID, private.ID)
+	assert.ErrorIs(t, err, analytics.ErrCategorizationRuleNotFound)
+	assert.ErrorIs(t, service.DeleteCategorizationRule(ctx, userID, starter.ID), analytics.ErrCategorizationRuleNotOwned)
+
+	// Family members change the family's rules
+	priority := 5
+	mockRepo.EXPECT().UpdateCategorizationRule(gomock.Any(), shared).Return(nil)
+	updated, err := service.UpdateCategorizationRule(ctx, userID, shared.ID, &analytics.UpdateCategorizationRuleRequest{
+		Priority: &priority,
+		Actions:  &analytics.RuleActions{Tags: []string{"kids"}},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, 5, updated.Priority)
+	assert.Equal(t, &analytics.RuleActions{Tags: []string{"kids"}}, updated.Actions)
+}
+
+func TestCategorizationRuleCategory(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	mockRepo := mocks.NewMockRepository(ctrl)
+	service := analytics.NewService(mockRepo)
+	ctx := context.Background()
+
+	userID := uuid.New()
+	otherUserID := uuid.New()
+	familyID := uuid.New()
+	system := &analytics.Category{ID: uuid.New(), Name: "Groceries"}
+	own := &analytics.Category{ID: uuid.New(), Name: "Hobbies", UserID: &userID}
+	others := &analytics.Category{ID: uuid.New(), Name: "Gym", UserID: &otherUserID}
+	family := &analytics.Category{ID: uuid.New(), Name: "Kids", FamilyID: &familyID}
+	for _, category := range []*analytics.Category{system, own, others, family} {
+		mockRepo.EXPECT().GetCategoryByID(gomock.Any(), category.ID).Return(category, nil).AnyTimes()
+	}
+	missingID := uuid.New()
+	mockRepo.EXPECT().GetCategoryByID(gomock.Any(), missingID).Return(nil, fmt.Errorf("%w: record not found", analytics.ErrCategoryNotFound)).AnyTimes()
+	mockRepo.EXPECT().GetFamilyIDsByUser(gomock.Any(), userID).Return([]uuid.UUID{familyID}, nil).AnyTimes()
+	mockRepo.EXPECT().CreateCategorizationRule(gomock.Any(), gomock.Any()).Return(nil).Times(4)
+
+	create := func(categoryID uuid.UUID, familyID *uuid.UUID) error {
+		_, err := service.CreateCategorizationRule(ctx, userID, &analytics.CreateCategorizationRuleRequest{
+			CategoryID: categoryID, FamilyID: familyID, Pattern: "shop", PatternType: "keyword",
+		})
+		return err
+	}
+
+	// User rules use system categories and the categories the user can see
+	assert.NoError(t, create(system.ID, nil))
+	assert.NoError(t, create(own.ID, nil))
+	assert.NoError(t, create(family.ID, nil))
+	assert.ErrorIs(t, create(others.ID, nil), analytics.ErrInvalidCategorizationRule)
+	assert.ErrorIs(t, create(missingID, nil), analytics.ErrInvalidCategorizationRule)
+
+	// Family rules only use categories every member can see
+	assert.NoError(t, create(family.ID, &familyID))
+	assert.ErrorIs(t, create(own.ID, &familyID), analytics.ErrInvalidCategorizationRule)
+}
+
+func TestTestCategorizationRule(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	mockRepo := mocks.NewMockRepository(ctrl)
+	service := analytics.NewService(mockRepo)
+
+	userID := uuid.New()
+	coffeeID := uuid.New()
+	shoppingID := uuid.New()
+	shopping := analytics.CategorizationRule{ID: uuid.New(), CategoryID: shoppingID, Pattern: "amazon", PatternType: "keyword", Priority: 10, IsActive: true}
+	lower := analytics.CategorizationRule{ID: uuid.New(), CategoryID: uuid.New(), Pattern: "coffee", PatternType: "keyword", Priority: 0, IsActive: true}
+	// Starter rules for other locales and rules the user can't see the category of don't
+	// take transactions or give away names
+	german := analytics.CategorizationRule{ID: uuid.New(), CategoryID: uuid.New(), Pattern: "morning", PatternType: "keyword", Priority: 20, Locale: "de", IsActive: true}
+	otherFamily := uuid.New()
+	privateID := uuid.New()
+	private := analytics.CategorizationRule{ID: uuid.New(), CategoryID: privateID, Pattern: "market", PatternType: "keyword", Priority: 10, IsActive: true}
+	now := time.Now()
+	mockRepo.EXPECT().GetTransactionsByPeriod(gomock.Any(), userID, gomock.Any(), gomock.Any()).Return([]analytics.Transaction{
+		{ID: uuid.New(), Description: "Coffee beans", Merchant: "Amazon", Amount: -20, TransactionDate: now},
+		{ID: uuid.New(), Description: "Morning coffee", Merchant: "Cafe", Amount: -4, TransactionDate: now.AddDate(0, 0, -1)},
+		{ID: uuid.New(), Description: "Groceries", Merchant: "Market", Amount: -60, TransactionDate: now.AddDate(0, 0, -2)},
+		{ID: uuid.New(), Description: "Coffee", Merchant: "Market", Amount: -3, TransactionDate: now.AddDate(0, 0, -3)},
+	}, nil)
+	mockRepo.EXPECT().GetActiveCategorizationRules(gomock.Any(), &userID).Return([]analytics.CategorizationRule{shopping, lower, german, private}, nil)
+	mockRepo.EXPECT().GetCategoryByID(gomock.Any(), shoppingID).Return(&analytics.Category{Name: "Shopping"}, nil)
+	mockRepo.EXPECT().GetCategoryByID(gomock.Any(), privateID).Return(&analytics.Category{Name: "Private", FamilyID: &otherFamily}, nil)
+	mockRepo.EXPECT().GetFamilyIDsByUser(gomock.Any(), userID).Return(nil, nil)
+
+	// Only rules with a higher priority that categorize differently conflict with the draft
+	result, err := service.TestCategorizationRule(context.Background(), userID, &analytics.TestCategorizationRuleRequest{
+		CreateCategorizationRuleRequest: analytics.CreateCategorizationRuleRequest{CategoryID: coffeeID, Pattern: "coffee", PatternType: "keyword", Priority: 5},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, 4, result.TransactionsTested)
+	assert.Equal(t, 3, result.TransactionsMatched)
+	assert.Len(t, result.Matches, 3)
+	assert.Equal(t, &shopping.ID, result.Matches[0].ConflictingRuleID)
+	assert.Nil(t, result.Matches[1].ConflictingRuleID)
+	assert.Equal(t, &private.ID, result.Matches[2].ConflictingRuleID)
+	assert.ElementsMatch(t, []analytics.RuleConflict{
+		{RuleID: shopping.ID, CategoryID: shoppingID, CategoryName: "Shopping", Pattern: "amazon", Priority: 10, Transactions: 1},
+		{RuleID: private.ID, CategoryID: privateID, Pattern: "market", Priority: 10, Transactions: 1},
+	}, result.Conflicts)
+
+	// Drafts are validated like saved rules, and periods can't end before they start
+	_, err = service.TestCategorizationRule(context.Background(), userID, &analytics.TestCategorizationRuleRequest{
+		CreateCategorizationRuleRequest: analytics.CreateCategorizationRuleRequest{CategoryID: coffeeID},
+	})
+	assert.ErrorIs(t, err, analytics.ErrInvalidCategorizationRule)
+	yesterday := now.AddDate(0, 0, -1)
+	_, err = service.TestCategorizationRule(context.Background(), userID, &analytics.TestCategorizationRuleRequest{
+		CreateCategorizationRuleRequest: analytics.CreateCategorizationRuleRequest{CategoryID: coffeeID, Pattern: "coffee", PatternType: "keyword"},
+		StartDate:                       &now,
+		EndDate:                         &yesterday,
+	})
+	assert.ErrorIs(t, err, analytics.ErrInvalidRulePeriod)
+}
+
+func TestApplyCategorizationRule(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	mockRepo := mocks.NewMockRepository(ctrl)
+	service := analytics.NewService(mockRepo)
+	ctx := context.Background()
+
+	userID := uuid.New()
+	categoryID := uuid.New()
+	previousID := uuid.New()
+	rule := &analytics.CategorizationRule{
+		ID: uuid.New(), UserID: &userID, CategoryID: categoryID, Pattern: "coffee", PatternType: "keyword", IsActive: true,
+		Actions: analytics.RuleActions{Tags: []string{"coffee"}},
+	}
+	uncategorized := analytics.Transaction{ID: uuid.New(), Description: "Coffee", Amount: -4, Tags: []string{"morning"}}
+	automatic := analytics.Transaction{ID: uuid.New(), Description: "Coffee", Amount: -4, CategoryID: &previousID, CategorizationSource: "ml"}
+	manual := analytics.Transaction{ID: uuid.New(), Description: "Coffee", Amount: -4, CategoryID: &previousID, CategorizationSource: "manual"}
+	// Transactions a rule with a higher priority takes are left to it
+	taken := analytics.Transaction{ID: uuid.New(), Description: "Coffee", Merchant: "Airport", Amount: -6}
+	airport := analytics.CategorizationRule{ID: uuid.New(), UserID: &userID, CategoryID: uuid.New(), Pattern: "airport", PatternType: "keyword", Priority: 5, IsActive: true}
+	mockRepo.EXPECT().GetCategorizationRuleByID(gomock.Any(), rule.ID).Return(rule, nil).Times(2)
+	mockRepo.EXPECT().GetActiveCategorizationRules(gomock.Any(), &userID).Return([]analytics.CategorizationRule{*rule, airport}, nil)
+	mockRepo.EXPECT().GetTransactionsByPeriod(gomock.Any(), userID, gomock.Any(), gomock.Any()).
+		Return([]analytics.Transaction{uncategorized, automatic, manual, taken}, nil).Times(2)
+
+	// By default only uncategorized transactions are categorized
+	mockRepo.EXPECT().CreateRuleApplication(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, application *analytics.RuleApplication, changes []analytics.RuleApplicationChange, transactions []analytics.Transaction) error {
+			assert.Len(t, changes, 1)
+			assert.Equal(t, []string{"morning"}, changes[0].PreviousTags)
+			assert.Len(t, transactions, 1)
+			assert.Equal(t, &categoryID, transactions[0].CategoryID)
+			assert.Equal(t, "rule", transactions[0].CategorizationSource)
+			assert.Equal(t, []string{"morning", "coffee"}, transactions[0].Tags)
+			return nil
+		})
+	application, err := service.ApplyCategorizationRule(ctx, userID, rule.ID, &analytics.ApplyCategorizationRuleRequest{})
+	assert.NoError(t, err)
+	assert.Equal(t, analytics.RuleApplicationStatusCompleted, application.Status)
+	assert.Equal(t, 3, application.TransactionsMatched)
+	assert.Equal(t, 1, application.TransactionsUpdated)
+
+	// Overwriting also recategorizes automatic categories, never the user's own
+	mockRepo.EXPECT().CreateRuleApplication(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, application *analytics.RuleApplication, changes []analytics.RuleApplicationChange, transactions []analytics.Transaction) error {
+			assert.Len(t, transactions, 2)
+			assert.Equal(t, &previousID, changes[1].PreviousCategoryID)
+			assert.Equal(t, "ml", changes[1].PreviousSource)
+			return nil
+		})
+	application, err = service.ApplyCategorizationRule(ctx, userID, rule.ID, &analytics.ApplyCategorizationRuleRequest{Overwrite: true})
+	assert.NoError(t, err)
+	assert.Equal(t, 2, application.TransactionsUpdated)
+
+	// Applications are undone once, and only by their user
+	applicationID := uuid.New()
+	mockRepo.EXPECT().GetRuleApplicationByID(gomock.Any(), applicationID).
+		Return(&analytics.RuleApplication{ID: applicationID, UserID: userID, Status: analytics.RuleApplicationStatusCompleted}, nil).Times(2)
+	_, err = service.UndoRuleApplication(ctx, uuid.New(), applicationID)
+	assert.ErrorIs(t, err, analytics.ErrRuleApplicationNotFound)
+
+	mockRepo.EXPECT().UndoRuleApplication(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, application *analytics.RuleApplication) error {
+		application.Status = analytics.RuleApplicationStatusUndone
+		application.TransactionsRestored = 2
+		return nil
+	})
+	undone, err := service.UndoRuleApplication(ctx, userID, applicationID)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, undone.TransactionsRestored)
+
+	mockRepo.EXPECT().GetRuleApplicationByID(gomock.Any(), applicationID).
+		Return(&analytics.RuleApplication{ID: applicationID, UserID: userID, Status: analytics.RuleApplicationStatusUndone}, nil)
+	_, err = service.UndoRuleApplication(ctx, userID, applicationID)
+	assert.ErrorIs(t, err, analytics.ErrRuleApplicationUndone)
+}
+
+func TestAnalyzeSpending_GoalContributions(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	mockRepo := mocks.NewMockRepository(ctrl)
+	service := analytics.NewService(mockRepo)
+
+	userID := uuid.New()
+	checkingID := uuid.New()
+	savingsID := uuid.New()
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	end := time.Date(2024, 1, 31, 0, 0, 0, 0, time.UTC)
+
+	transactions := []analytics.Transaction{
+		{ID: uuid.New(), AccountID: savingsID, Amount: 300, TransactionDate: time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC)},
+		{ID: uuid.New(), AccountID: savingsID, Amount: -50, TransactionDate: time.Date(2024, 1, 20, 0, 0, 0, 0, time.UTC)},
+		{ID: uuid.New(), AccountID: checkingID, Amount: -120, Tags: []string{"vacation"}, TransactionDate: time.Date(2024, 1, 15, 0, 0, 0, 0, time.UTC)},
+		{ID: uuid.New(), AccountID: checkingID, Amount: -80, Description: "Groceries", TransactionDate: time.Date(2024, 1, 16, 0, 0, 0, 0, time.UTC)},
+	}
+	goals := []analytics.Goal{
+		{ID: uuid.New(), Name: "Emergency fund", StartDate: start, Accounts: []analytics.GoalAccount{{AccountID: savingsID}}},
+		{ID: uuid.New(), Name: "Vacation", StartDate: time.Date(2024, 1, 10, 0, 0, 0, 0, time.UTC), ContributionTag: "vacation"},
+	}
+	mockRepo.EXPECT().GetTransactionsByPeriod(gomock.Any(), userID, start, end.AddDate(0, 0, 1).Add(-time.Nanosecond)).Return(transactions, nil)
+	mockRepo.EXPECT().GetTransactionsByPeriod(gomock.Any(), userID, start.AddDate(0, -6, 0), gomock.Any()).Return(nil, nil)
+	mockRepo.EXPECT().GetUserTimezone(gomock.Any(), userID).Return("UTC", nil)
+	mockRepo.EXPECT().GetActiveGoalsByUser(gomock.Any(), userID).Return(goals, nil)
+
+	resp, err := service.AnalyzeSpending(context.Background(), userID, &analytics.SpendingAnalysisRequest{StartDate: start, EndDate: end})
+
+	assert.NoError(t, err)
+	assert.Equal(t, []analytics.GoalContribution{
+		{GoalID: goals[0].ID, GoalName: "Emergency fund", Amount: 250, TransactionCount: 2},
+		{GoalID: goals[1].ID, GoalName: "Vacation", Amount: 120, TransactionCount: 1},
+	}, resp.GoalContributions)
+}
+
+func TestAnalyzeSpending_SpendingTrends(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	mockRepo := mocks.NewMockRepository(ctrl)
+	service := analytics.NewService(mockRepo)
+
+	userID := uuid.New()
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	end := time.Date(2024, 1, 28, 0, 0, 0, 0, time.UTC)
+	newYork, _ := time.LoadLocation("America/New_York")
+	// Dates without a time of day are dates in the user's timezone
+	localStart := time.Date(2024, 1, 1, 0, 0, 0, 0, newYork)
+
+	transactions := []analytics.Transaction{
+		{ID: uuid.New(), Amount: -100, TransactionDate: time.Date(2024, 1, 3, 12, 0, 0, 0, time.UTC)},
+		// Still Sunday, January 7th in New York
+		{ID: uuid.New(), Amount: -50, TransactionDate: time.Date(2024, 1, 8, 3, 0, 0, 0, time.UTC)},
+		{ID: uuid.New(), Amount: -165, TransactionDate: time.Date(2024, 1, 10, 12, 0, 0, 0, time.UTC)},
+		{ID: uuid.New(), Amount: 500, TransactionDate: time.Date(2024, 1, 10, 12, 0, 0, 0, time.UTC)},
+		{ID: uuid.New(), Amount: -80, TransactionDate: time.Date(2024, 1, 24, 12, 0, 0, 0, time.UTC)},
+	}
+	mockRepo.EXPECT().GetUserTimezone(gomock.Any(), userID).Return("America/New_York", nil)
+	mockRepo.EXPECT().GetTransactionsByPeriod(gomock.Any(), userID, localStart, localStart.AddDate(0, 0, 28).Add(-time.Nanosecond)).Return(transactions, nil)
+	mockRepo.EXPECT().GetTransactionsByPeriod(gomock.Any(), userID, localStart.AddDate(0, -6, 0), gomock.Any()).Return(nil, nil)
+	mockRepo.EXPECT().GetActiveGoalsByUser(gomock.Any(), userID).Return(nil, nil)
+
+	resp, err := service.AnalyzeSpending(context.Background(), userID, &analytics.SpendingAnalysisRequest{StartDate: start, EndDate: end, GroupBy: analytics.GroupByWeek})
+	assert.NoError(t, err)
+
+	periods := make([]string, len(resp.SpendingTrends))
+	for i, trend := range resp.SpendingTrends {
+		periods[i] = trend.Period
+	}
+	assert.Equal(t, []string{"2024-W01", "2024-W02", "2024-W03", "2024-W04"}, periods)
+
+	assert.Equal(t, 150.0, resp.SpendingTrends[0].Amount)
+	assert.Equal(t, 2, resp.SpendingTrends[0].TransactionCount)
+	assert.Equal(t, analytics.TrendStable, resp.SpendingTrends[0].Trend)
+	assert.Equal(t, 10.0, resp.SpendingTrends[1].Change)
+	assert.Equal(t, analytics.TrendIncreasing, resp.SpendingTrends[1].Trend)
+	assert.Equal(t, 0.0, resp.SpendingTrends[2].Amount)
+	assert.Equal(t, -100.0, resp.SpendingTrends[2].Change)
+	assert.Equal(t, analytics.TrendDecreasing, resp.SpendingTrends[2].Trend)
+	assert.Equal(t, analytics.TrendIncreasing, resp.SpendingTrends[3].Trend)
+	assert.Equal(t, "America/New_York", resp.SpendingTrends[3].StartDate.Location().String())
+	assert.True(t, localStart.Equal(resp.PeriodStart))
+}
+
+func TestAnalyzeSpending_PeriodTimestamps(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	mockRepo := mocks.NewMockRepository(ctrl)
+	service := analytics.NewService(mockRepo)
+
+	userID := uuid.New()
+	newYork, _ := time.LoadLocation("America/New_York")
+	// Times of day are converted to the user's timezone before taking their dates
+	start := time.Date(2024, 1, 1, 3, 0, 0, 0, time.UTC)
+	end := time.Date(2024, 1, 31, 12, 0, 0, 0, time.UTC)
+	localStart := time.Date(2023, 12, 31, 0, 0, 0, 0, newYork)
+	localEnd := time.Date(2024, 1, 31, 0, 0, 0, 0, newYork)
+
+	mockRepo.EXPECT().GetUserTimezone(gomock.Any(), userID).Return("America/New_York", nil)
+	mockRepo.EXPECT().GetTransactionsByPeriod(gomock.Any(), userID, localStart, localEnd.AddDate(0, 0, 1).Add(-time.Nanosecond)).Return(nil, nil)
+	mockRepo.EXPECT().GetTransactionsByPeriod(gomock.Any(), userID, localStart.AddDate(0, -6, 0), gomock.Any()).Return(nil, nil)
+	mockRepo.EXPECT().GetActiveGoalsByUser(gomock.Any(), userID).Return(nil, nil)
+
+	resp, err := service.AnalyzeSpending(context.Background(), userID, &analytics.SpendingAnalysisRequest{StartDate: start, EndDate: end})
+	assert.NoError(t, err)
+	assert.True(t, localStart.Equal(resp.PeriodStart))
+	assert.True(t, localEnd.Equal(resp.PeriodEnd))
+	assert.Equal(t, "2023-12", resp.SpendingTrends[0].Period)
+}
+
+func TestAnalyzeSpending_TrendGroupings(t *testing.T) {
+	start := time.Date(2024, 11, 15, 0, 0, 0, 0, time.UTC)
+	end := time.Date(2025, 1, 2, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		groupBy string
+		periods []string
+	}{
+		{groupBy: "", periods: []string{"2024-11", "2024-12", "2025-01"}},
+		{groupBy: analytics.GroupByWeek, periods: []string{"2024-W46", "2024-W47", "2024-W48", "2024-W49", "2024-W50", "2024-W51", "2024-W52", "2025-W01"}},
+		{groupBy: analytics.GroupByQuarter, periods: []string{"2024-Q4", "2025-Q1"}},
+		{groupBy: analytics.GroupByYear, periods: []string{"2024", "2025"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.groupBy, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			mockRepo := mocks.NewMockRepository(ctrl)
+			service := analytics.NewService(mockRepo)
+			userID := uuid.New()
+
+			mockRepo.EXPECT().GetTransactionsByPeriod(gomock.Any(), userID, start, end.AddDate(0, 0, 1).Add(-time.Nanosecond)).Return(nil, nil)
+			mockRepo.EXPECT().GetTransactionsByPeriod(gomock.Any(), userID, start.AddDate(0, -6, 0), gomock.Any()).Return(nil, nil)
+			mockRepo.EXPECT().GetActiveGoalsByUser(gomock.Any(), userID).Return(nil, nil)
+
+			resp, err := service.AnalyzeSpending(context.Background(), userID, &analytics.SpendingAnalysisRequest{
+				StartDate: start,
+				EndDate:   end,
+				GroupBy:   tt.groupBy,
+				Timezone:  "UTC",
+			})
+			assert.NoError(t, err)
+
+			periods := make([]string, len(resp.SpendingTrends))
+			for i, trend := range resp.SpendingTrends {
+				periods[i] = trend.Period
+				assert.Equal(t, analytics.TrendStable, trend.Trend)
+			}
+			assert.Equal(t, tt.periods, periods)
+		})
+	}
+}
+
+func TestAnalyzeSpending_InvalidRequest(t *testing.T) {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	end := time.Date(2024, 1, 31, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name string
+		req  *analytics.SpendingAnalysisRequest
+	}{
+		{name: "unknown grouping", req: &analytics.SpendingAnalysisRequest{StartDate: start, EndDate: end, GroupBy: "category"}},
+		{name: "unknown timezone", req: &analytics.SpendingAnalysisRequest{StartDate: start, EndDate: end, Timezone: "Mars/Olympus"}},
+		{name: "end before start", req: &analytics.SpendingAnalysisRequest{StartDate: end, EndDate: start}},
+		{name: "too many periods", req: &analytics.SpendingAnalysisRequest{StartDate: start, EndDate: start.AddDate(5, 0, 0), GroupBy: analytics.GroupByDay, Timezone: "UTC"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			mockRepo := mocks.NewMockRepository(ctrl)
+			service := analytics.NewService(mockRepo)
+
+			mockRepo.EXPECT().GetTransactionsByPeriod(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(nil, nil).AnyTimes()
+
+			_, err := service.AnalyzeSpending(context.Background(), uuid.New(), tt.req)
+			assert.ErrorIs(t, err, analytics.ErrInvalidAnalysisRequest)
+		})
+	}
+}
+
+func TestGetSpendingInsights_Anomalies(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	mockRepo := mocks.NewMockRepository(ctrl)
+	service := analytics.NewService(mockRepo)
+
+	userID := uuid.New()
+	netflixID := uuid.New()
+	groceries := analytics.Category{ID: uuid.New(), Name: "Groceries"}
+	dining := analytics.Category{ID: uuid.New(), Name: "Dining"}
+	start := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+	end := time.Date(2024, 6, 30, 0, 0, 0, 0, time.UTC)
+	day := func(month time.Month, d int) time.Time { return time.Date(2024, month, d, 12, 0, 0, 0, time.UTC) }
+
+	// Six months of regular spending
+	history := []analytics.Transaction{
+		{ID: uuid.New(), CategoryID: &groceries.ID, Merchant: "Corner Shop", Amount: -50, TransactionDate: time.Date(2023, 12, 1, 12, 0, 0, 0, time.UTC)},
+		{ID: uuid.New(), Merchant: "Employer", Amount: 3000, TransactionDate: day(1, 31)},
+	}
+	for i, amount := range []float64{55, 60, 45, 52, 58, 48} {
+		history = append(history,
+			analytics.Transaction{ID: uuid.New(), CategoryID: &groceries.ID, Merchant: "Corner Shop", Amount: -amount, TransactionDate: day(time.Month(i%5+1), 10)},
+			analytics.Transaction{ID: uuid.New(), CategoryID: &dining.ID, Merchant: "Diner", Amount: -30, TransactionDate: day(time.Month(i%5+1), 20)},
+			analytics.Transaction{ID: uuid.New(), MerchantID: &netflixID, Merchant: "Netflix", Amount: -15.99, TransactionDate: day(time.Month(i%5+1), 1)},
+		)
+	}
+
+	transactions := []analytics.Transaction{
+		{ID: uuid.New(), CategoryID: &groceries.ID, Merchant: "Corner Shop", Amount: -400, TransactionDate: day(6, 3)},
+		{ID: uuid.New(), MerchantID: &netflixID, Merchant: "Netflix", Amount: -45, TransactionDate: day(6, 1)},
+		{ID: uuid.New(), CategoryID: &dining.ID, Merchant: "Diner", Amount: -40, TransactionDate: day(6, 5)},
+		{ID: uuid.New(), CategoryID: &dining.ID, Merchant: "Diner", Amount: -40, TransactionDate: day(6, 12)},
+		{ID: uuid.New(), CategoryID: &dining.ID, Merchant: "Diner", Amount: -40, TransactionDate: day(6, 19)},
+		{ID: uuid.New(), Merchant: "New Bakery", Amount: -12, TransactionDate: day(6, 8)},
+		{ID: uuid.New(), Merchant: "New Bakery", Amount: -9, TransactionDate: day(6, 15)},
+		{ID: uuid.New(), Merchant: "Refund Co", Amount: -500, Status: "cancelled", TransactionDate: day(6, 9)},
+		{ID: uuid.New(), Merchant: "Florist", Amount: -25, TransactionDate: day(6, 10)},
+	}
+	mockRepo.EXPECT().GetTransactionsByPeriod(gomock.Any(), userID, start, end).Return(transactions, nil)
+	mockRepo.EXPECT().GetTransactionsByPeriod(gomock.Any(), userID, time.Date(2023, 12, 1, 0, 0, 0, 0, time.UTC), gomock.Any()).Return(history, nil)
+	// The florist was visited before the six months of history
+	mockRepo.EXPECT().GetMerchantTransactionsBefore(gomock.Any(), userID, nil, []string{"New Bakery", "Florist"}, time.Date(2023, 12, 1, 0, 0, 0, 0, time.UTC)).
+		Return([]analytics.Transaction{{Merchant: "Florist"}}, nil)
+	mockRepo.EXPECT().GetCategoryByID(gomock.Any(), groceries.ID).Return(&groceries, nil)
+	mockRepo.EXPECT().GetCategoryByID(gomock.Any(), dining.ID).Return(&dining, nil)
+
+	insights, err := service.GetSpendingInsights(context.Background(), userID, start, end)
+	assert.NoError(t, err)
+
+	var anomalies []analytics.SpendingInsight
+	byType := make(map[string][]analytics.SpendingInsight)
+	for _, insight := range insights {
+		if insight.Type == "anomaly" {
+			anomalies = append(anomalies, insight)
+			anomalyType := insight.Data["anomaly_type"].(string)
+			byType[anomalyType] = append(byType[anomalyType], insight)
+		}
+	}
+
+	if assert.Len(t, byType[analytics.AnomalyLargeTransaction], 1) {
+		large := byType[analytics.AnomalyLargeTransaction][0]
+		assert.Equal(t, transactions[0].ID.String(), large.Data["transaction_id"])
+		assert.Equal(t, 52.0, large.Data["median"])
+		assert.Equal(t, 7, large.Data["history_count"])
+		assert.Equal(t, "high", large.Severity)
+	}
+
+	if assert.Len(t, byType[analytics.AnomalyUnusualMerchant], 1) {
+		merchant := byType[analytics.AnomalyUnusualMerchant][0]
+		assert.Equal(t, netflixID.String(), merchant.Data["merchant_id"])
+		assert.Equal(t, 15.99, merchant.Data["median"])
+	}
+
+	// Dining amounts are only a little higher, but there are more of them
+	if assert.Len(t, byType[analytics.AnomalyCategorySpike], 2) {
+		assert.Equal(t, dining.ID.String(), byType[analytics.AnomalyCategorySpike][1].Data["category_id"])
+		assert.Equal(t, "Dining", byType[analytics.AnomalyCategorySpike][1].Data["category_name"])
+		assert.Equal(t, 120.0, byType[analytics.AnomalyCategorySpike][1].Data["amount"])
+		assert.Equal(t, 29.51, byType[analytics.AnomalyCategorySpike][1].Data["expected_amount"])
+	}
+
+	// New merchants are reported once, with their first purchase
+	if assert.Len(t, byType[analytics.AnomalyFirstTimeMerchant], 1) {
+		assert.Equal(t, transactions[5].ID.String(), byType[analytics.AnomalyFirstTimeMerchant][0].Data["transaction_id"])
+		assert.Equal(t, "low", byType[analytics.AnomalyFirstTimeMerchant][0].Severity)
+	}
+
+	assert.Len(t, anomalies, 5)
+	assert.Equal(t, analytics.AnomalyFirstTimeMerchant, anomalies[len(anomalies)-1].Data["anomaly_type"])
+}
+
+func TestGetSpendingInsights_NoAnomaliesWithoutHistory(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	mockRepo := mocks.NewMockRepository(ctrl)
+	service := analytics.NewService(mockRepo)
+
+	userID := uuid.New()
+	start := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+	end := time.Date(2024, 6, 30, 0, 0, 0, 0, time.UTC)
+
+	transactions := []analytics.Transaction{
+		{ID: uuid.New(), Merchant: "Corner Shop", Amount: -400, TransactionDate: start},
+		{ID: uuid.New(), Merchant: "New Bakery", Amount: -12, TransactionDate: start},
+	}
+	mockRepo.EXPECT().GetTransactionsByPeriod(gomock.Any(), userID, start, end).Return(transactions, nil)
+	mockRepo.EXPECT().GetTransactionsByPeriod(gomock.Any(), userID, start.AddDate(0, -6, 0), gomock.Any()).Return(nil, nil)
+
+	insights, err := service.GetSpendingInsights(context.Background(), userID, start, end)
+	assert.NoError(t, err)
+	for _, insight := range insights {
+		assert.NotEqual(t, "anomaly", insight.Type)
+	}
+}
+
+func TestGetCashFlowForecast(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	mockRepo := mocks.NewMockRepository(ctrl)
+	service := analytics.NewService(mockRepo)
+
+	userID := uuid.New()
+	checking := analytics.Account{ID: uuid.New(), Name: "Checking", Balance: 1000, Currency: "USD"}
+	savings := analytics.Account{ID: uuid.New(), Name: "Savings", Balance: 500, Currency: "USD"}
+	euros := analytics.Account{ID: uuid.New(), Name: "Euro account", Balance: 300, Currency: "EUR"}
+	now := time.Now().UTC()
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+
+	var history []analytics.Transaction
+	// Coffee every day is discretionary spending
+	for day := 0; day <= 90; day++ {
+		history = append(history, analytics.Transaction{AccountID: checking.ID, Merchant: "Cafe", Amount: -10, TransactionDate: today.AddDate(0, 0, -day).Add(8 * time.Hour)})
+	}
+	for _, day := range []int{5, 19, 33, 47, 61} {
+		history = append(history, analytics.Transaction{AccountID: checking.ID, Merchant: "Employer", Amount: 2000, TransactionDate: today.AddDate(0, 0, -day)})
+	}
+	rent := today.AddDate(0, 0, -25)
+	for month := 0; month < 3; month++ {
+		history = append(history, analytics.Transaction{AccountID: checking.ID, Description: "Rent", Amount: -1200, TransactionDate: rent.AddDate(0, -month, 0)})
+	}
+	// Transfers to savings are not discretionary spending
+	history = append(history, analytics.Transaction{AccountID: checking.ID, Description: "To savings", Amount: -300, IsTransfer: true, TransactionDate: today.AddDate(0, 0, -40)})
+	history = append(history, analytics.Transaction{AccountID: checking.ID, Merchant: "Electronics", Amount: -900, Status: "cancelled", TransactionDate: today})
+	// Monthly income is detected over the last half year
+	paid := today.AddDate(0, 0, -10)
+	for month := 0; month < 6; month++ {
+		history = append(history, analytics.Transaction{AccountID: euros.ID, Description: "Dividend", Amount: 50, TransactionDate: paid.AddDate(0, -month, 0)})
+	}
+
+	mockRepo.EXPECT().GetUserTimezone(gomock.Any(), userID).Return("UTC", nil)
+	mockRepo.EXPECT().GetActiveAccountsByUser(gomock.Any(), userID).Return([]analytics.Account{checking, savings, euros}, nil)
+	mockRepo.EXPECT().GetTransactionsByPeriod(gomock.Any(), userID, today.AddDate(0, 0, -180), gomock.Any()).Return(history, nil)
+
+	forecast, err := service.GetCashFlowForecast(context.Background(), userID, &analytics.CashFlowForecastRequest{Days: 30, LowBalanceThreshold: 950})
+	assert.NoError(t, err)
+	assert.Equal(t, today.AddDate(0, 0, 1), forecast.StartDate)
+
+	// Balances are totaled per currency
+	if assert.Len(t, forecast.Totals, 2) {
+		assert.Equal(t, "EUR", forecast.Totals[0].Currency)
+		assert.Equal(t, 300.0, forecast.Totals[0].CurrentBalance)
+		assert.Equal(t, 350.0, forecast.Totals[0].Balances[29].Balance)
+		assert.Equal(t, "USD", forecast.Totals[1].Currency)
+		assert.Equal(t, 1500.0, forecast.Totals[1].CurrentBalance)
+		assert.Len(t, forecast.Totals[1].Balances, 30)
+	}
+
+	account := forecast.Accounts[0]
+	assert.Equal(t, 10.0, account.DailyDiscretionary)
+	if assert.Len(t, account.Recurring, 2) {
+		byName := map[string]analytics.RecurringCashFlow{}
+		for _, flow := range account.Recurring {
+			byName[flow.Name] = flow
+		}
+		assert.Equal(t, analytics.RecurringCashFlow{Name: "Employer", Amount: 2000, Frequency: analytics.FrequencyBiweekly, NextDate: today.AddDate(0, 0, 9)}, byName["Employer"])
+		assert.Equal(t, analytics.FrequencyMonthly, byName["Rent"].Frequency)
+		assert.Equal(t, -1200.0, byName["Rent"].Amount)
+		assert.Equal(t, rent.AddDate(0, 1, 0), byName["Rent"].NextDate)
+
+		// The balance first drops below the threshold when rent is due
+		rentDue := byName["Rent"].NextDate
+		assert.Equal(t, &rentDue, account.LowBalanceDate)
+		assert.Equal(t, &rentDue, forecast.Totals[1].LowBalanceDate)
+	}
+
+	// Two paychecks and one rent payment within 30 days
+	last := account.Balances[29]
+	assert.Equal(t, 3500.0, last.Balance)
+	assert.Equal(t, last.Balance, last.Lower)
+	assert.Equal(t, 4000.0, forecast.Totals[1].Balances[29].Balance)
+
+	assert.Empty(t, forecast.Accounts[1].Recurring)
+	assert.Equal(t, forecast.StartDate, *forecast.Accounts[1].LowBalanceDate)
+	assert.Equal(t, 500.0, forecast.Accounts[1].Balances[29].Balance)
+
+	if assert.Len(t, forecast.Accounts[2].Recurring, 1) {
+		assert.Equal(t, analytics.FrequencyMonthly, forecast.Accounts[2].Recurring[0].Frequency)
+		assert.Equal(t, paid.AddDate(0, 1, 0), forecast.Accounts[2].Recurring[0].NextDate)
+	}
+}
+
+func TestGetCashFlowForecast_InvalidDays(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	mockRepo := mocks.NewMockRepository(ctrl)
+	service := analytics.NewService(mockRepo)
+
+	_, err := service.GetCashFlowForecast(context.Background(), uuid.New(), &analytics.CashFlowForecastRequest{Days: 400})
+	assert.ErrorIs(t, err, analytics.ErrInvalidForecastRequest)
+}
+
+// categorizedTransactions returns transactions of three well separated categories. Their
+// IDs are the same on every call, as they decide which transactions are held out
+func categorizedTransactions(groceries, transport, dining uuid.UUID) []analytics.Transaction {
+	id := func(n int) uuid.UUID { return uuid.NewSHA1(uuid.NameSpaceOID, []byte(fmt.Sprint("transaction-", n))) }
+	var transactions []analytics.Transaction
+	for i := 0; i < 10; i++ {
+		transactions = append(transactions,
+			analytics.Transaction{ID: id(3 * i), CategoryID: &groceries, Description: "Weekly grocery shopping", Merchant: []string{"Walmart", "Kroger"}[i%2], Amount: -float64(60 + i*10)},
+			analytics.Transaction{ID: id(3*i + 1), CategoryID: &transport, Description: "Ride to work", Merchant: []string{"Uber", "Lyft"}[i%2], Amount: -float64(10 + i)},
+			analytics.Transaction{ID: id(3*i + 2), CategoryID: &dining, Description: "Dinner with friends", Merchant: []string{"Olive Garden", "Chipotle"}[i%2], Amount: -float64(25 + i*2)},
+		)
+	}
+	return transactions
+}
+
+func TestTrainCategorizationModel(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	mockRepo := mocks.NewMockRepository(ctrl)
+	service := analytics.NewService(mockRepo)
+
+	groceries := analytics.Category{ID: uuid.New(), Name: "Groceries"}
+	transport := analytics.Category{ID: uuid.New(), Name: "Transport"}
+	dining := analytics.Category{ID: uuid.New(), Name: "Dining"}
+	categories := map[uuid.UUID]*analytics.Category{groceries.ID: &groceries, transport.ID: &transport, dining.ID: &dining}
+
+	var saved *analytics.CategorizationModel
+	mockRepo.EXPECT().GetCategorizedTransactions(gomock.Any(), 50000).Return(categorizedTransactions(groceries.ID, transport.ID, dining.ID), nil)
+	mockRepo.EXPECT().GetActiveCategorizationModel(gomock.Any(), analytics.ModelTypeNaiveBayes).Return(nil, nil)
+	mockRepo.EXPECT().CreateCategorizationModel(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, model *analytics.CategorizationModel) error {
+		model.ID = uuid.New()
+		saved = model
+		return nil
+	})
+	mockRepo.EXPECT().ActivateCategorizationModel(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, id uuid.UUID) error {
+		assert.Equal(t, saved.ID, id)
+		return nil
+	})
+
+	result, err := service.TrainCategorizationModel(context.Background())
+	assert.NoError(t, err)
+	assert.True(t, result.Activated)
+	assert.Nil(t, result.PreviousModelID)
+	assert.Equal(t, analytics.ModelTypeNaiveBayes, result.Model.ModelType)
+	assert.True(t, result.Model.IsActive)
+	assert.Equal(t, 1.0, result.Model.Accuracy)
+	assert.NotEmpty(t, saved.ModelData)
+
+	// About one in five transactions is held out for evaluation, chosen by transaction ID
+	metrics := result.Model.Metrics
+	if assert.NotNil(t, metrics) {
+		assert.Equal(t, 24, metrics.TrainingExamples)
+		assert.Equal(t, 6, metrics.EvaluationExamples)
+		assert.Len(t, metrics.Categories, 3)
+		support := map[uuid.UUID]int{}
+		for _, category := range metrics.Categories {
+			assert.Equal(t, 1.0, category.Precision)
+			assert.Equal(t, 1.0, category.Recall)
+			support[category.CategoryID] = category.Support
+		}
+		assert.Equal(t, map[uuid.UUID]int{groceries.ID: 2, transport.ID: 3, dining.ID: 1}, support)
+		assert.Len(t, metrics.ConfusionMatrix, 3)
+		for _, entry := range metrics.ConfusionMatrix {
+			assert.Equal(t, entry.ActualCategoryID, entry.PredictedCategoryID)
+		}
+	}
+
+	// The trained model is used right away, without loading it again
+	mockRepo.EXPECT().GetActiveCategorizationRules(gomock.Any(), gomock.Any()).Return(nil, nil)
+	mockRepo.EXPECT().GetCategoryByID(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, id uuid.UUID) (*analytics.Category, error) {
+		return categories[id], nil
+	}).AnyTimes()
+
+	resp, err := service.CategorizeTransaction(context.Background(), &analytics.CategorizationRequest{Description: "Uber ride home", Merchant: "Uber", Amount: 14})
+	assert.NoError(t, err)
+	assert.Equal(t, transport.ID, resp.CategoryID)
+	assert.Equal(t, "Transport", resp.CategoryName)
+	assert.Equal(t, "ml", resp.CategorizationSource)
+	assert.Greater(t, resp.Confidence, 0.9)
+	for _, alternative := range resp.AlternativeCategories {
+		assert.NotEqual(t, transport.ID, alternative.CategoryID)
+		assert.Less(t, alternative.Confidence, resp.Confidence)
+	}
+}
+
+func TestCategorizeTransaction_ModelAlternatives(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	mockRepo := mocks.NewMockRepository(ctrl)
+
+	groceries := analytics.Category{ID: uuid.New(), Name: "Groceries"}
+	transport := analytics.Category{ID: uuid.New(), Name: "Transport"}
+	dining := analytics.Category{ID: uuid.New(), Name: "Dining"}
+	categories := map[uuid.UUID]*analytics.Category{groceries.ID: &groceries, transport.ID: &transport, dining.ID: &dining}
+
+	// Train a model to store, then load it in a new service
+	var saved analytics.CategorizationModel
+	mockRepo.EXPECT().GetCategorizedTransactions(gomock.Any(), gomock.Any()).Return(categorizedTransactions(groceries.ID, transport.ID, dining.ID), nil)
+	mockRepo.EXPECT().GetActiveCategorizationModel(gomock.Any(), analytics.ModelTypeNaiveBayes).Return(nil, nil)
+	mockRepo.EXPECT().CreateCategorizationModel(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, model *analytics.CategorizationModel) error {
+		saved = *model
+		return nil
+	})
+	mockRepo.EXPECT().ActivateCategorizationModel(gomock.Any(), gomock.Any()).Return(nil)
+	_, err := analytics.NewService(mockRepo).TrainCategorizationModel(context.Background())
+	assert.NoError(t, err)
+
+	service := analytics.NewService(mockRepo)
+	mockRepo.EXPECT().GetActiveCategorizationModel(gomock.Any(), analytics.ModelTypeNaiveBayes).Return(&saved, nil).Times(1)
+	mockRepo.EXPECT().GetActiveCategorizationRules(gomock.Any(), gomock.Any()).Return(nil, nil).Times(1)
+	mockRepo.EXPECT().GetCategoryByID(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, id uuid.UUID) (*analytics.Category, error) {
+		return categories[id], nil
+	}).AnyTimes()
+
+	// Groceries bought through a ride-hailing app could be either
+	resp, err := service.CategorizeTransaction(context.Background(), &analytics.CategorizationRequest{Description: "Grocery", Merchant: "Uber", Amount: 70})
+	assert.NoError(t, err)
+	assert.Equal(t, groceries.ID, resp.CategoryID)
+	assert.Less(t, resp.Confidence, 0.8)
+	if assert.Len(t, resp.AlternativeCategories, 1) {
+		assert.Equal(t, transport.ID, resp.AlternativeCategories[0].CategoryID)
+		assert.Equal(t, "Transport", resp.AlternativeCategories[0].CategoryName)
+		assert.Equal(t, "model", resp.AlternativeCategories[0].Reason)
+		assert.InDelta(t, 1.0, resp.Confidence+resp.AlternativeCategories[0].Confidence, 0.02)
+	}
+
+	// The model and the rules are only loaded once
+	_, err = service.CategorizeTransaction(context.Background(), &analytics.CategorizationRequest{Description: "Dinner", Merchant: "Chipotle", Amount: 30})
+	assert.NoError(t, err)
+}
+
+func TestTrainCategorizationModel_ComparesWithActiveModel(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	mockRepo := mocks.NewMockRepository(ctrl)
+
+	groceries, transport, dining := uuid.New(), uuid.New(), uuid.New()
+
+	// A model trained on transactions with groceries and transport mixed up
+	var mixedUp analytics.CategorizationModel
+	mockRepo.EXPECT().GetCategorizedTransactions(gomock.Any(), gomock.Any()).Return(categorizedTransactions(transport, groceries, dining), nil)
+	mockRepo.EXPECT().GetActiveCategorizationModel(gomock.Any(), gomock.Any()).Return(nil, nil)
+	mockRepo.EXPECT().CreateCategorizationModel(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, model *analytics.CategorizationModel) error {
+		model.ID = uuid.New()
+		mixedUp = *model
+		return nil
+	})
+	mockRepo.EXPECT().ActivateCategorizationModel(gomock.Any(), gomock.Any()).Return(nil)
+	_, err := analytics.NewService(mockRepo).TrainCategorizationModel(context.Background())
+	assert.NoError(t, err)
+
+	// Once the categories are corrected, the new model replaces it
+	var corrected analytics.CategorizationModel
+	mockRepo.EXPECT().GetCategorizedTransactions(gomock.Any(), gomock.Any()).Return(categorizedTransactions(groceries, transport, dining), nil)
+	mockRepo.EXPECT().GetActiveCategorizationModel(gomock.Any(), gomock.Any()).Return(&mixedUp, nil)
+	mockRepo.EXPECT().CreateCategorizationModel(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, model *analytics.CategorizationModel) error {
+		model.ID = uuid.New()
+		corrected = *model
+		return nil
+	})
+	mockRepo.EXPECT().ActivateCategorizationModel(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, id uuid.UUID) error {
+		assert.Equal(t, corrected.ID, id)
+		return nil
+	})
+
+	result, err := analytics.NewService(mockRepo).TrainCategorizationModel(context.Background())
+	assert.NoError(t, err)
+	assert.True(t, result.Activated)
+	assert.Equal(t, mixedUp.ID, *result.PreviousModelID)
+	assert.Less(t, *result.PreviousAccuracy, result.Model.Accuracy)
+
+	// A new model that does no better is stored, but not activated
+	mockRepo.EXPECT().GetCategorizedTransactions(gomock.Any(), gomock.Any()).Return(categorizedTransactions(groceries, transport, dining), nil)
+	mockRepo.EXPECT().GetActiveCategorizationModel(gomock.Any(), gomock.Any()).Return(&corrected, nil)
+	mockRepo.EXPECT().CreateCategorizationModel(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, model *analytics.CategorizationModel) error {
+		assert.False(t, model.IsActive)
+		return nil
+	})
+
+	result, err = analytics.NewService(mockRepo).TrainCategorizationModel(context.Background())
+	assert.NoError(t, err)
+	assert.False(t, result.Activated)
+	assert.False(t, result.Model.IsActive)
+	assert.Equal(t, corrected.ID, *result.PreviousModelID)
+	assert.Equal(t, 1.0, *result.PreviousAccuracy)
+}
+
+func TestCompareCategorizationModels(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	mockRepo := mocks.NewMockRepository(ctrl)
+	service := analytics.NewService(mockRepo)
+
+	groceries, transport := uuid.New(), uuid.New()
+	base := &analytics.CategorizationModel{
+		ID:       uuid.New(),
+		Accuracy: 0.8,
+		Metrics:  fmt.Sprintf(`{"accuracy":0.8,"categories":[{"category_id":"%s","precision":0.75,"recall":0.9,"support":10}]}`, groceries),
+	}
+	candidate := &analytics.CategorizationModel{
+		ID:       uuid.New(),
+		Accuracy: 0.9,
+		Metrics:  fmt.Sprintf(`{"accuracy":0.9,"categories":[{"category_id":"%s","precision":0.85,"recall":0.95,"support":10},{"category_id":"%s","precision":1,"recall":0.5,"support":2}]}`, groceries, transport),
+	}
+	mockRepo.EXPECT().GetCategorizationModelByID(gomock.Any(), base.ID).Return(base, nil)
+	mockRepo.EXPECT().GetCategorizationModelByID(gomock.Any(), candidate.ID).Return(candidate, nil)
+
+	comparison, err := service.CompareCategorizationModels(context.Background(), base.ID, candidate.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, 0.1, comparison.AccuracyChange)
+	assert.Len(t, comparison.Categories, 2)
+
+	byCategory := make(map[uuid.UUID]analytics.CategoryMetricsComparison)
+	for _, category := range comparison.Categories {
+		byCategory[category.CategoryID] = category
+	}
+	assert.Equal(t, analytics.CategoryMetricsComparison{CategoryID: groceries, BasePrecision: 0.75, CandidatePrecision: 0.85, BaseRecall: 0.9, CandidateRecall: 0.95}, byCategory[groceries])
+	// Categories the base model wasn't evaluated on compare with zeros
+	assert.Equal(t, analytics.CategoryMetricsComparison{CategoryID: transport, CandidatePrecision: 1, CandidateRecall: 0.5}, byCategory[transport])
+}
+
+func TestActivateCategorizationModel(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	mockRepo := mocks.NewMockRepository(ctrl)
+	service := analytics.NewService(mockRepo)
+
+	groceries := analytics.Category{ID: uuid.New(), Name: "Groceries"}
+	transport := analytics.Category{ID: uuid.New(), Name: "Transport"}
+	dining := analytics.Category{ID: uuid.New(), Name: "Dining"}
+	categories := map[uuid.UUID]*analytics.Category{groceries.ID: &groceries, transport.ID: &transport, dining.ID: &dining}
+
+	var previous analytics.CategorizationModel
+	mockRepo.EXPECT().GetCategorizedTransactions(gomock.Any(), gomock.Any()).Return(categorizedTransactions(groceries.ID, transport.ID, dining.ID), nil)
+	mockRepo.EXPECT().GetActiveCategorizationModel(gomock.Any(), gomock.Any()).Return(nil, nil)
+	mockRepo.EXPECT().CreateCategorizationModel(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, model *analytics.CategorizationModel) error {
+		model.ID = uuid.New()
+		previous = *model
+		return nil
+	})
+	mockRepo.EXPECT().ActivateCategorizationModel(gomock.Any(), gomock.Any()).Return(nil)
+	_, err := service.TrainCategorizationModel(context.Background())
+	assert.NoError(t, err)
+
+	// Rolling back to a version loads it on the next categorization
+	mockRepo.EXPECT().GetCategorizationModelByID(gomock.Any(), previous.ID).Return(&previous, nil)
+	mockRepo.EXPECT().ActivateCategorizationModel(gomock.Any(), previous.ID).Return(nil)
+
+	model, err := service.ActivateCategorizationModel(context.Background(), previous.ID)
+	assert.NoError(t, err)
+	assert.True(t, model.IsActive)
+	assert.Equal(t, previous.ID, model.ID)
+
+	mockRepo.EXPECT().GetActiveCategorizationRules(gomock.Any(), gomock.Any()).Return(nil, nil)
+	mockRepo.EXPECT().GetActiveCategorizationModel(gomock.Any(), analytics.ModelTypeNaiveBayes).Return(&previous, nil)
+	mockRepo.EXPECT().GetCategoryByID(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, id uuid.UUID) (*analytics.Category, error) {
+		return categories[id], nil
+	}).AnyTimes()
+
+	resp, err := service.CategorizeTransaction(context.Background(), &analytics.CategorizationRequest{Description: "Dinner", Merchant: "Chipotle", Amount: 30})
+	assert.NoError(t, err)
+	assert.Equal(t, dining.ID, resp.CategoryID)
+}
+
+func TestCategorizeTransaction_SkipsHiddenModelCategories(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	mockRepo := mocks.NewMockRepository(ctrl)
+	service := analytics.NewService(mockRepo)
+
+	otherUserID := uuid.New()
+	groceries := analytics.Category{ID: uuid.New(), Name: "Groceries"}
+	transport := analytics.Category{ID: uuid.New(), Name: "Transport"}
+	// A model trained before private categories were excluded still predicts them
+	dining := analytics.Category{ID: uuid.New(), Name: "Date nights", UserID: &otherUserID}
+	categories := map[uuid.UUID]*analytics.Category{groceries.ID: &groceries, transport.ID: &transport, dining.ID: &dining}
+
+	mockRepo.EXPECT().GetCategorizedTransactions(gomock.Any(), gomock.Any()).Return(categorizedTransactions(groceries.ID, transport.ID, dining.ID), nil)
+	mockRepo.EXPECT().GetActiveCategorizationModel(gomock.Any(), gomock.Any()).Return(nil, nil)
+	mockRepo.EXPECT().CreateCategorizationModel(gomock.Any(), gomock.Any()).Return(nil)
+	mockRepo.EXPECT().ActivateCategorizationModel(gomock.Any(), gomock.Any()).Return(nil)
+	_, err := service.TrainCategorizationModel(context.Background())
+	assert.NoError(t, err)
+
+	userID := uuid.New()
+	mockRepo.EXPECT().GetActiveCategorizationRules(gomock.Any(), gomock.Any()).Return(nil, nil)
+	mockRepo.EXPECT().GetCategorizationFeedbackByUser(gomock.Any(), userID, 1000).Return(nil, nil)
+	mockRepo.EXPECT().GetCategoryByID(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, id uuid.UUID) (*analytics.Category, error) {
+		return categories[id], nil
+	}).AnyTimes()
+
+	resp, err := service.CategorizeTransaction(context.Background(), &analytics.CategorizationRequest{Description: "Dinner with friends", Merchant: "Chipotle", Amount: 30, UserID: &userID})
+	assert.NoError(t, err)
+	assert.NotEqual(t, dining.ID, resp.CategoryID)
+	assert.Equal(t, "ml", resp.CategorizationSource)
+	for _, alternative := range resp.AlternativeCategories {
+		assert.NotEqual(t, dining.ID, alternative.CategoryID)
+	}
+}
+
+func TestRecordCorrection_PersonalizesCategorization(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	mockRepo := mocks.NewMockRepository(ctrl)
+	service := analytics.NewService(mockRepo)
+
+	groceries := analytics.Category{ID: uuid.New(), Name: "Groceries"}
+	transport := analytics.Category{ID: uuid.New(), Name: "Transport"}
+	dining := analytics.Category{ID: uuid.New(), Name: "Dining"}
+	business := analytics.Category{ID: uuid.New(), Name: "Business"}
+	categories := map[uuid.UUID]*analytics.Category{groceries.ID: &groceries, transport.ID: &transport, dining.ID: &dining, business.ID: &business}
+
+	mockRepo.EXPECT().GetCategorizedTransactions(gomock.Any(), gomock.Any()).Return(categorizedTransactions(groceries.ID, transport.ID, dining.ID), nil)
+	mockRepo.EXPECT().GetActiveCategorizationModel(gomock.Any(), gomock.Any()).Return(nil, nil)
+	mockRepo.EXPECT().CreateCategorizationModel(gomock.Any(), gomock.Any()).Return(nil)
+	mockRepo.EXPECT().ActivateCategorizationModel(gomock.Any(), gomock.Any()).Return(nil)
+	_, err := service.TrainCategorizationModel(context.Background())
+	assert.NoError(t, err)
+
+	mockRepo.EXPECT().GetActiveCategorizationRules(gomock.Any(), gomock.Any()).Return(nil, nil).AnyTimes()
+	mockRepo.EXPECT().GetCategoryByID(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, id uuid.UUID) (*analytics.Category, error) {
+		return categories[id], nil
+	}).AnyTimes()
+
+	userID := uuid.New()
+	req := &analytics.CategorizationRequest{Description: "Team dinner", Merchant: "Chipotle", Amount: 28, UserID: &userID}
+
+	// Before any corrections the model knows Chipotle as dining
+	mockRepo.EXPECT().GetCategorizationFeedbackByUser(gomock.Any(), userID, 1000).Return(nil, nil)
+	resp, err := service.CategorizeTransaction(context.Background(), req)
+	assert.NoError(t, err)
+	assert.Equal(t, dining.ID, resp.CategoryID)
+
+	var recorded *analytics.CategorizationFeedback
+	mockRepo.EXPECT().CreateCategorizationFeedback(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, feedback *analytics.CategorizationFeedback) error {
+		recorded = feedback
+		return nil
+	})
+	err = service.RecordCorrection(context.Background(), userID, &analytics.CategoryCorrectionRequest{
+		TransactionID:      uuid.New(),
+		Description:        "Team dinner",
+		Merchant:           "Chipotle",
+		Amount:             -30,
+		PreviousCategoryID: &dining.ID,
+		PreviousSource:     "ml",
+		CategoryID:         business.ID,
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, userID, recorded.UserID)
+	assert.Equal(t, business.ID, recorded.CategoryID)
+	assert.Equal(t, "ml", recorded.PreviousSource)
+
+	// The corrections are loaded again and outweigh the model for this user
+	corrections := make([]analytics.CategorizationFeedback, 3)
+	for i := range corrections {
+		corrections[i] = analytics.CategorizationFeedback{UserID: userID, Description: "Team dinner", Merchant: "Chipotle", Amount: -30, CategoryID: business.ID}
+	}
+	mockRepo.EXPECT().GetCategorizationFeedbackByUser(gomock.Any(), userID, 1000).Return(corrections, nil)
+	resp, err = service.CategorizeTransaction(context.Background(), req)
+	assert.NoError(t, err)
+	assert.Equal(t, business.ID, resp.CategoryID)
+	assert.Equal(t, "Business", resp.CategoryName)
+
+	// Other users are categorized by the model alone
+	resp, err = service.CategorizeTransaction(context.Background(), &analytics.CategorizationRequest{Description: "Team dinner", Merchant: "Chipotle", Amount: 28})
+	assert.NoError(t, err)
+	assert.Equal(t, dining.ID, resp.CategoryID)
+}
+
+func TestSuggestCategorizationRules(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	mockRepo := mocks.NewMockRepository(ctrl)
+	service := analytics.NewService(mockRepo)
+
+	userID := uuid.New()
+	business := analytics.Category{ID: uuid.New(), Name: "Business"}
+	groceries := analytics.Category{ID: uuid.New(), Name: "Groceries"}
+	dining := analytics.Category{ID: uuid.New(), Name: "Dining"}
+
+	var corrections []analytics.CategorizationFeedback
+	correct := func(merchant string, categoryID uuid.UUID, times int) {
+		for i := 0; i < times; i++ {
+			corrections = append(corrections, analytics.CategorizationFeedback{UserID: userID, Merchant: merchant, CategoryID: categoryID})
+		}
+	}
+	correct("Chipotle", business.ID, 2)
+	correct("chipotle ", business.ID, 1)
+	correct("Costco", groceries.ID, 3)   // Already categorized by a rule
+	correct("Uber", business.ID, 2)      // Not corrected often enough
+	correct("Starbucks", business.ID, 3) // Corrected to different categories
+	correct("Starbucks", dining.ID, 3)
+
+	mockRepo.EXPECT().GetCategorizationFeedbackByUser(gomock.Any(), userID, gomock.Any()).Return(corrections, nil)
+	mockRepo.EXPECT().GetActiveCategorizationRules(gomock.Any(), gomock.Any()).Return([]analytics.CategorizationRule{
+		{ID: uuid.New(), CategoryID: groceries.ID, Pattern: "costco", PatternType: "keyword", IsActive: true},
+		// Starter rules only count in their locale
+		{ID: uuid.New(), CategoryID: business.ID, Pattern: "chipotle", PatternType: "keyword", Locale: "de", IsActive: true},
+	}, nil)
+	mockRepo.EXPECT().GetCategoryByID(gomock.Any(), business.ID).Return(&business, nil)
+
+	suggestions, err := service.SuggestCategorizationRules(context.Background(), userID, "")
+	assert.NoError(t, err)
+	assert.Equal(t, []analytics.RuleSuggestion{
+		{CategoryID: business.ID, CategoryName: "Business", Pattern: "Chipotle", PatternType: "exact", Corrections: 3},
+	}, suggestions)
+
+	// Merging categories loads the rules again
+	service.CategoriesMerged(context.Background())
+	mockRepo.EXPECT().GetCategorizationFeedbackByUser(gomock.Any(), userID, gomock.Any()).Return(corrections, nil)
+	mockRepo.EXPECT().GetActiveCategorizationRules(gomock.Any(), gomock.Any()).Return([]analytics.CategorizationRule{
+		{ID: uuid.New(), CategoryID: groceries.ID, Pattern: "costco", PatternType: "keyword", IsActive: true},
+		{ID: uuid.New(), CategoryID: business.ID, Pattern: "chipotle", PatternType: "keyword", IsActive: true},
+	}, nil)
+	suggestions, err = service.SuggestCategorizationRules(context.Background(), userID, "")
+	assert.NoError(t, err)
+	assert.Empty(t, suggestions)
+}
+
+func TestTrainCategorizationModel_InsufficientData(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	mockRepo := mocks.NewMockRepository(ctrl)
+	service := analytics.NewService(mockRepo)
+
+	categoryID := uuid.New()
+	mockRepo.EXPECT().GetCategorizedTransactions(gomock.Any(), gomock.Any()).Return([]analytics.Transaction{
+		{ID: uuid.New(), CategoryID: &categoryID, Description: "Coffee", Amount: -4},
+	}, nil)
+
+	_, err := service.TrainCategorizationModel(context.Background())
+	assert.ErrorIs(t, err, analytics.ErrInsufficientTrainingData)
+}
+
+// benchmarkService returns a service with many rules of every kind for a user, all of which
+// categorize with full confidence so that only rules are benchmarked
+func benchmarkService(b *testing.B, userID uuid.UUID) analytics.Service {
+	ctrl := gomock.NewController(b)
+	b.Cleanup(ctrl.Finish)
+	mockRepo := mocks.NewMockRepository(ctrl)
+
+	var rules []analytics.CategorizationRule
+	for i := 0; i < 300; i++ {
+		rule := analytics.CategorizationRule{ID: uuid.New(), UserID: &userID, CategoryID: uuid.New(), Priority: i % 10, IsActive: true}
+		switch i % 3 {
+		case 0:
+			rule.Pattern, rule.PatternType = fmt.Sprintf("merchant %d", i), "exact"
+		case 1:
+			rule.Pattern, rule.PatternType = fmt.Sprintf("store%d purchase", i), "keyword"
+		default:
+			rule.Pattern, rule.PatternType = fmt.Sprintf(`^shop%d\s+#\d+`, i), "regex"
+		}
+		rules = append(rules, rule)
+	}
+	// Everything else is an expense
+	rules = append(rules, analytics.CategorizationRule{
+		ID: uuid.New(), UserID: &userID, CategoryID: uuid.New(), Priority: -1, IsActive: true,
+		Conditions: analytics.RuleConditions{Sign: analytics.RuleSignExpense},
+	})
+
+	mockRepo.EXPECT().GetActiveCategorizationRules(gomock.Any(), &userID).Return(rules, nil).AnyTimes()
+	mockRepo.EXPECT().GetCategoryByID(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, id uuid.UUID) (*analytics.Category, error) {
+		return &analytics.Category{ID: id, Name: "Category"}, nil
+	}).AnyTimes()
+	return analytics.NewService(mockRepo)
+}
+
+// benchmarkTransactions returns transactions matching each kind of rule, and none
+func benchmarkTransactions(count int) []analytics.CategorizationRequest {
+	transactions := make([]analytics.CategorizationRequest, count)
+	for i := range transactions {
+		switch i % 4 {
+		case 0:
+			transactions[i] = analytics.CategorizationRequest{Description: fmt.Sprintf("Card payment Merchant %d", (i%100)*3), Amount: -20}
+		case 1:
+			transactions[i] = analytics.CategorizationRequest{Description: fmt.Sprintf("Store%d online purchase", (i%100)*3+1), Amount: -35}
+		case 2:
+			transactions[i] = analytics.CategorizationRequest{Description: fmt.Sprintf("SHOP%d #1234", (i%100)*3+2), Amount: -8}
+		default:
+			transactions[i] = analytics.CategorizationRequest{Description: "Direct debit", Merchant: "Utility Co", Amount: -60}
+		}
+	}
+	return transactions
+}
+
+func BenchmarkCategorizeTransaction(b *testing.B) {
+	userID := uuid.New()
+	service := benchmarkService(b, userID)
+	transactions := benchmarkTransactions(1000)
+	ctx := context.Background()
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		req := transactions[i%len(transactions)]
+		req.UserID = &userID
+		if _, err := service.CategorizeTransaction(ctx, &req); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkCategorizeTransactions(b *testing.B) {
+	userID := uuid.New()
+	service := benchmarkService(b, userID)
+	req := &analytics.BatchCategorizationRequest{UserID: &userID, Transactions: benchmarkTransactions(5000)}
+	ctx := context.Background()
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := service.CategorizeTransactions(ctx, req); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
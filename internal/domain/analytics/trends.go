@@ -0,0 +1,192 @@
+package analytics
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	// maxTrendBuckets limits the number of periods a spending trend is split into
+	maxTrendBuckets = 1000
+
+	// trendThreshold is the change in percent from the previous period below which
+	// spending is considered stable
+	trendThreshold = 5.0
+)
+
+// ErrInvalidAnalysisRequest is returned for spending analyses that cannot be performed
+var ErrInvalidAnalysisRequest = errors.New("invalid spending analysis request")
+
+// trendLocation resolves the timezone spending trends are bucketed in. The timezone of
+// the request takes precedence over the user's, and UTC is used when neither is set
+func (s *service) trendLocation(ctx context.Context, userID uuid.UUID, timezone string) (*time.Location, error) {
+	if timezone == "" {
+		userTimezone, err := s.repo.GetUserTimezone(ctx, userID)
+		if err != nil {
+			return nil, err
+		}
+		timezone = userTimezone
+	}
+	if timezone == "" {
+		return time.UTC, nil
+	}
+
+	location, err := time.LoadLocation(timezone)
+	if err != nil {
+		return nil, fmt.Errorf("%w: unknown timezone %q", ErrInvalidAnalysisRequest, timezone)
+	}
+	return location, nil
+}
+
+// validateGroupBy checks the grouping of a spending analysis and returns it, defaulting to
+// months
+func validateGroupBy(groupBy string) (string, error) {
+	switch groupBy {
+	case "":
+		return GroupByMonth, nil
+	case GroupByDay, GroupByWeek, GroupByMonth, GroupByQuarter, GroupByYear:
+		return groupBy, nil
+	default:
+		return "", fmt.Errorf("%w: group_by must be one of day, week, month, quarter or year", ErrInvalidAnalysisRequest)
+	}
+}
+
+// generateSpendingTrends sums expenses per period between the start and end date, in the
+// given timezone. Periods without expenses are included so that every period is reported
+func (s *service) generateSpendingTrends(transactions []Transaction, groupBy string, startDate, endDate time.Time, location *time.Location) ([]SpendingTrend, error) {
+	// The start and end dates are calendar dates in the given timezone
+	first := bucketStart(calendarDate(startDate, location), groupBy)
+	last := bucketStart(calendarDate(endDate, location), groupBy)
+
+	var trends []SpendingTrend
+	indexes := make(map[time.Time]int)
+	for start := first; !start.After(last); start = nextBucket(start, groupBy) {
+		if len(trends) == maxTrendBuckets {
+			return nil, fmt.Errorf("%w: more than %d %s periods", ErrInvalidAnalysisRequest, maxTrendBuckets, groupBy)
+		}
+		indexes[start] = len(trends)
+		trends = append(trends, SpendingTrend{
+			Period:    bucketLabel(start, groupBy),
+			StartDate: start,
+			EndDate:   nextBucket(start, groupBy).AddDate(0, 0, -1),
+			Trend:     TrendStable,
+		})
+	}
+
+	for _, tx := range transactions {
+		if tx.Amount >= 0 {
+			continue
+		}
+		i, ok := indexes[bucketStart(tx.TransactionDate.In(location), groupBy)]
+		if !ok {
+			continue
+		}
+		trends[i].Amount += math.Abs(tx.Amount)
+		trends[i].TransactionCount++
+	}
+
+	for i := range trends {
+		trends[i].Amount = math.Round(trends[i].Amount*100) / 100
+		if i == 0 {
+			continue
+		}
+		trends[i].Change, trends[i].Trend = classifyTrend(trends[i-1].Amount, trends[i].Amount)
+	}
+
+	return trends, nil
+}
+
+// classifyTrend calculates the percentage change between the spending of two periods and
+// whether spending is increasing, decreasing or stable
+func classifyTrend(previous, current float64) (float64, string) {
+	if previous == 0 {
+		if current > 0 {
+			return 0, TrendIncreasing
+		}
+		return 0, TrendStable
+	}
+
+	change := math.Round((current-previous)/previous*10000) / 100
+	switch {
+	case change > trendThreshold:
+		return change, TrendIncreasing
+	case change < -trendThreshold:
+		return change, TrendDecreasing
+	default:
+		return change, TrendStable
+	}
+}
+
+// calendarDate returns the start of the day with the same date as a time in a timezone
+func calendarDate(t time.Time, location *time.Location) time.Time {
+	year, month, day := t.Date()
+	return time.Date(year, month, day, 0, 0, 0, 0, location)
+}
+
+// localDay returns the start of the day a requested time falls on in a timezone. Dates sent
+// without a time of day arrive as midnight UTC and are taken as that date in the timezone
+func localDay(t time.Time, location *time.Location) time.Time {
+	if _, offset := t.Zone(); offset == 0 && t.Equal(calendarDate(t, time.UTC)) {
+		return calendarDate(t, location)
+	}
+	return calendarDate(t.In(location), location)
+}
+
+// bucketStart returns the start of the period of the given grouping a time falls in
+func bucketStart(t time.Time, groupBy string) time.Time {
+	year, month, day := t.Date()
+	location := t.Location()
+
+	switch groupBy {
+	case GroupByDay:
+		return time.Date(year, month, day, 0, 0, 0, 0, location)
+	case GroupByWeek:
+		// ISO weeks start on Monday
+		offset := (int(t.Weekday()) + 6) % 7
+		return time.Date(year, month, day-offset, 0, 0, 0, 0, location)
+	case GroupByQuarter:
+		return time.Date(year, month-(month-1)%3, 1, 0, 0, 0, 0, location)
+	case GroupByYear:
+		return time.Date(year, time.January, 1, 0, 0, 0, 0, location)
+	default:
+		return time.Date(year, month, 1, 0, 0, 0, 0, location)
+	}
+}
+
+// nextBucket returns the start of the period following the one starting at the given time
+func nextBucket(start time.Time, groupBy string) time.Time {
+	switch groupBy {
+	case GroupByDay:
+		return start.AddDate(0, 0, 1)
+	case GroupByWeek:
+		return start.AddDate(0, 0, 7)
+	case GroupByQuarter:
+		return start.AddDate(0, 3, 0)
+	case GroupByYear:
+		return start.AddDate(1, 0, 0)
+	default:
+		return start.AddDate(0, 1, 0)
+	}
+}
+
+// bucketLabel names the period starting at the given time
+func bucketLabel(start time.Time, groupBy string) string {
+	switch groupBy {
+	case GroupByDay:
+		return start.Format("2006-01-02")
+	case GroupByWeek:
+		year, week := start.ISOWeek()
+		return fmt.Sprintf("%d-W%02d", year, week)
+	case GroupByQuarter:
+		return fmt.Sprintf("%d-Q%d", start.Year(), (int(start.Month())-1)/3+1)
+	case GroupByYear:
+		return start.Format("2006")
+	default:
+		return start.Format("2006-01")
+	}
+}
@@ -0,0 +1,332 @@
+package budget
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+
+	"fiscaflow/internal/domain/notification"
+)
+
+// NotificationTypeBudgetAlert is the notification type of budget alerts, which users can mute
+const NotificationTypeBudgetAlert = "budget_alert"
+
+// Notifier delivers notifications to users
+type Notifier interface {
+	Send(ctx context.Context, userID uuid.UUID, message *notification.Message) error
+}
+
+// EvaluateAlerts stores the spent amounts of a user's active budgets, including those of their
+// families, computes their alerts and returns those that fire for the first time in the
+// budget's current period
+func (s *service) EvaluateAlerts(ctx context.Context, userID uuid.UUID) ([]TriggeredAlert, error) {
+	ctx, span := otel.Tracer("").Start(ctx, "budget.EvaluateAlerts",
+		trace.WithAttributes(attribute.String("user_id", userID.String())),
+	)
+	defer span.End()
+
+	budgets, err := s.repo.GetActiveBudgetsByUser(ctx, userID)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, err
+	}
+
+	now := time.Now()
+	triggered := []TriggeredAlert{}
+	for i := range budgets {
+		budget := &budgets[i]
+
+		// Budgets that have not started or have ended cannot be acted on anymore
+		periods := budgetPeriods(budget, now)
+		if len(periods) == 0 {
+			continue
+		}
+		period := periods[len(periods)-1]
+		if truncateToDay(now.In(period.start.Location())).After(period.end) {
+			continue
+		}
+
+		// Alerts are evaluated when transactions change, which is when the stored spent
+		// amounts are brought up to date
+		if err := s.refreshSpentAmounts(ctx, budget); err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+			return nil, err
+		}
+
+		summary, err := s.GetBudgetSummary(ctx, userID, budget.ID, nil)
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+			return nil, err
+		}
+
+		var fired []BudgetAlert
+		for _, alert := range summary.Alerts {
+			created, err := s.repo.RecordAlertEvent(ctx, &BudgetAlertEvent{
+				BudgetID:    budget.ID,
+				CategoryID:  alert.CategoryID,
+				AlertType:   alert.AlertType,
+				Threshold:   alert.Threshold,
+				PeriodStart: period.start,
+				CreatedAt:   now,
+			})
+			if err != nil {
+				span.RecordError(err)
+				span.SetStatus(codes.Error, err.Error())
+				return nil, err
+			}
+			if created {
+				fired = append(fired, alert)
+			}
+		}
+		if len(fired) == 0 {
+			continue
+		}
+
+		alerts, err := s.triggeredAlerts(ctx, budget, period.start, fired)
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+			return nil, err
+		}
+		triggered = append(triggered, alerts...)
+	}
+
+	span.SetAttributes(attribute.Int("triggered_count", len(triggered)))
+	return triggered, nil
+}
+
+// triggeredAlerts addresses the alerts that fired for a budget to its users. The category of
+// a category alert is named, but only recipients who can see the category are told its name
+func (s *service) triggeredAlerts(ctx context.Context, budget *Budget, periodStart time.Time, alerts []BudgetAlert) ([]TriggeredAlert, error) {
+	userIDs, err := s.budgetUserIDs(ctx, budget)
+	if err != nil {
+		return nil, err
+	}
+
+	var categoryIDs []uuid.UUID
+	for _, alert := range alerts {
+		if alert.CategoryID != uuid.Nil {
+			categoryIDs = append(categoryIDs, alert.CategoryID)
+		}
+	}
+	categories := make(map[uuid.UUID]*Category)
+	if len(categoryIDs) > 0 {
+		hierarchy, err := s.repo.GetCategoryHierarchy(ctx, categoryIDs)
+		if err != nil {
+			return nil, err
+		}
+		for i := range hierarchy {
+			categories[hierarchy[i].ID] = &hierarchy[i]
+		}
+	}
+
+	viewers := make([]*categoryViewer, len(userIDs))
+	for i, userID := range userIDs {
+		viewers[i] = s.newCategoryViewer(userID)
+	}
+
+	triggered := make([]TriggeredAlert, len(alerts))
+	for i, alert := range alerts {
+		category := categories[alert.CategoryID]
+		if category != nil {
+			alert.CategoryName = category.Name
+		}
+
+		recipients := make([]AlertRecipient, len(viewers))
+		for j, viewer := range viewers {
+			recipients[j] = AlertRecipient{UserID: viewer.userID}
+			if category != nil {
+				if recipients[j].SeesCategory, err = viewer.canSee(ctx, category); err != nil {
+					return nil, err
+				}
+			}
+		}
+
+		triggered[i] = TriggeredAlert{
+			BudgetID:    budget.ID,
+			BudgetName:  budget.Name,
+			PeriodStart: periodStart,
+			Alert:       alert,
+			Recipients:  recipients,
+		}
+	}
+	return triggered, nil
+}
+
+// alertCheckState is where the alert check of a user stands
+type alertCheckState int
+
+const (
+	alertCheckQueued  alertCheckState = iota // Waiting for a worker
+	alertCheckRunning                        // Being checked by a worker
+	alertCheckRerun                          // Changed again while being checked, so queued once more afterwards
+)
+
+// AlertMonitor notifies users when their spending triggers budget alerts. Checks run on a
+// fixed number of workers, and the changes made to a user's transactions while a check of
+// theirs is waiting or running are coalesced into a single further check
+type AlertMonitor struct {
+	service  Service
+	notifier Notifier
+	logger   *zap.Logger
+
+	mu      sync.Mutex
+	wake    *sync.Cond
+	queue   []uuid.UUID // Users waiting for a check, oldest first
+	checks  map[uuid.UUID]alertCheckState
+	stopped bool
+}
+
+// NewAlertMonitor creates a monitor evaluating budget alerts and notifying users of them
+func NewAlertMonitor(service Service, notifier Notifier, logger *zap.Logger) *AlertMonitor {
+	m := &AlertMonitor{service: service, notifier: notifier, logger: logger, checks: make(map[uuid.UUID]alertCheckState)}
+	m.wake = sync.NewCond(&m.mu)
+	return m
+}
+
+// Start runs the given number of workers checking the queued users until the context is
+// done
+func (m *AlertMonitor) Start(ctx context.Context, workers int) {
+	for i := 0; i < workers; i++ {
+		go m.work(ctx)
+	}
+	go func() {
+		<-ctx.Done()
+		m.mu.Lock()
+		m.stopped = true
+		m.mu.Unlock()
+		m.wake.Broadcast()
+	}()
+}
+
+// TransactionsChanged queues a check of the user's budgets after their transactions were
+// created, updated or deleted. A user is queued at most once at a time
+func (m *AlertMonitor) TransactionsChanged(ctx context.Context, userID uuid.UUID) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	state, ok := m.checks[userID]
+	switch {
+	case !ok:
+		m.checks[userID] = alertCheckQueued
+		m.queue = append(m.queue, userID)
+		m.wake.Signal()
+	case state == alertCheckRunning:
+		// The running check may have read the transactions before they changed
+		m.checks[userID] = alertCheckRerun
+	}
+}
+
+// work checks queued users until the context is done
+func (m *AlertMonitor) work(ctx context.Context) {
+	for {
+		m.mu.Lock()
+		for len(m.queue) == 0 && !m.stopped {
+			m.wake.Wait()
+		}
+		if m.stopped {
+			m.mu.Unlock()
+			return
+		}
+		userID := m.queue[0]
+		m.queue = m.queue[1:]
+		m.checks[userID] = alertCheckRunning
+		m.mu.Unlock()
+
+		if err := m.CheckAlerts(ctx, userID); err != nil {
+			m.logger.Error("Failed to check budget alerts", zap.String("user_id", userID.String()), zap.Error(err))
+		}
+
+		m.mu.Lock()
+		if m.checks[userID] == alertCheckRerun {
+			m.checks[userID] = alertCheckQueued
+			m.queue = append(m.queue, userID)
+			m.wake.Signal()
+		} else {
+			delete(m.checks, userID)
+		}
+		m.mu.Unlock()
+	}
+}
+
+// CheckAlerts evaluates the user's budgets and notifies the recipients of newly triggered
+// alerts, which for family budgets are all members of the family
+func (m *AlertMonitor) CheckAlerts(ctx context.Context, userID uuid.UUID) error {
+	ctx, span := otel.Tracer("").Start(ctx, "budget.CheckAlerts",
+		trace.WithAttributes(attribute.String("user_id", userID.String())),
+	)
+	defer span.End()
+
+	triggered, err := m.service.EvaluateAlerts(ctx, userID)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return err
+	}
+
+	var errs []error
+	for _, alert := range triggered {
+		for _, recipient := range alert.Recipients {
+			if err := m.notifier.Send(ctx, recipient.UserID, alertMessage(alert, recipient.SeesCategory)); err != nil {
+				errs = append(errs, err)
+			}
+		}
+	}
+
+	if err := errors.Join(errs...); err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return err
+	}
+	return nil
+}
+
+// alertMessage builds the notification sent for a triggered alert. The category is named
+// only to recipients who can see it
+func alertMessage(triggered TriggeredAlert, showCategory bool) *notification.Message {
+	alert := triggered.Alert
+	data := map[string]interface{}{
+		"budget_id":        triggered.BudgetID.String(),
+		"category_id":      alert.CategoryID.String(),
+		"alert_type":       alert.AlertType,
+		"threshold":        alert.Threshold,
+		"allocated_amount": alert.AllocatedAmount,
+		"spent_amount":     alert.SpentAmount,
+		"period_start":     triggered.PeriodStart.Format("2006-01-02"),
+	}
+	if alert.Scope != "" {
+		data["scope"] = string(alert.Scope)
+	}
+	if alert.Severity != "" {
+		data["severity"] = string(alert.Severity)
+	}
+	if alert.ProjectedDate != nil {
+		data["projected_amount"] = alert.ProjectedAmount
+		data["projected_date"] = alert.ProjectedDate.Format("2006-01-02")
+	}
+
+	title := fmt.Sprintf("Budget alert: %s", triggered.BudgetName)
+	if showCategory && alert.CategoryName != "" {
+		title = fmt.Sprintf("Budget alert: %s - %s", triggered.BudgetName, alert.CategoryName)
+	}
+
+	return &notification.Message{
+		Type:     NotificationTypeBudgetAlert,
+		Title:    title,
+		Body:     alert.Message,
+		Data:     data,
+		Channels: alert.Channels,
+	}
+}
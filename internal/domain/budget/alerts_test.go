@@ -0,0 +1,189 @@
+package budget
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"go.uber.org/zap"
+
+	"fiscaflow/internal/domain/notification"
+)
+
+// recordingNotifier records the messages sent through it
+type recordingNotifier struct {
+	userIDs  []uuid.UUID
+	messages []*notification.Message
+	err      error
+}
+
+func (n *recordingNotifier) Send(ctx context.Context, userID uuid.UUID, message *notification.Message) error {
+	n.userIDs = append(n.userIDs, userID)
+	n.messages = append(n.messages, message)
+	return n.err
+}
+
+// setupAlertBudgets sets up a budget for the current month with two alerts, of which the
+// over budget alert already fired this period, and a budget that has ended
+func setupAlertBudgets(mockRepo *MockRepository, userID uuid.UUID) (uuid.UUID, uuid.UUID) {
+	now := time.Now().UTC()
+	budgetID := uuid.New()
+	groceriesID := uuid.New()
+	rentID := uuid.New()
+	ended := time.Date(now.Year()-1, 1, 31, 0, 0, 0, 0, time.UTC)
+
+	household := Budget{ID: budgetID, UserID: userID, Name: "Household", PeriodType: PeriodTypeMonthly, StartDate: time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)}
+	mockRepo.On("GetActiveBudgetsByUser", mock.Anything, userID).Return([]Budget{
+		household,
+		{ID: uuid.New(), UserID: userID, Name: "Last year", PeriodType: PeriodTypeMonthly, StartDate: time.Date(now.Year()-1, 1, 1, 0, 0, 0, 0, time.UTC), EndDate: &ended},
+	}, nil)
+	mockRepo.On("GetByID", mock.Anything, budgetID).Return(&household, nil)
+	mockRepo.On("GetCategoriesByBudgetID", mock.Anything, budgetID).Return([]BudgetCategory{
+		{BudgetID: budgetID, CategoryID: groceriesID, AllocatedAmount: 100, AlertThreshold: 0.8},
+		{BudgetID: budgetID, CategoryID: rentID, AllocatedAmount: 1000, AlertThreshold: 0.8},
+	}, nil)
+	mockRepo.On("GetPeriodsByBudgetID", mock.Anything, budgetID).Return([]BudgetPeriod{}, nil)
+	mockRepo.On("SavePeriod", mock.Anything, mock.Anything).Return(nil)
+	mockRepo.On("GetSpendingByCategory", mock.Anything, []uuid.UUID{userID}, mock.Anything, mock.Anything).
+		Return(map[uuid.UUID]float64{groceriesID: 85, rentID: 1200}, nil)
+	mockRepo.On("GetCategoryHierarchy", mock.Anything, mock.Anything).Return([]Category{{ID: groceriesID}, {ID: rentID}}, nil)
+	mockRepo.On("UpdateSpentAmount", mock.Anything, budgetID, groceriesID, 85.0).Return(nil)
+	mockRepo.On("UpdateSpentAmount", mock.Anything, budgetID, rentID, 1200.0).Return(nil)
+	mockRepo.On("GetExpenseHistory", mock.Anything, []uuid.UUID{userID}, mock.Anything, mock.Anything).Return([]ExpenseRecord{}, nil)
+	mockRepo.On("RecordAlertEvent", mock.Anything, mock.MatchedBy(func(event *BudgetAlertEvent) bool {
+		return event.CategoryID == groceriesID
+	})).Return(true, nil)
+	mockRepo.On("RecordAlertEvent", mock.Anything, mock.MatchedBy(func(event *BudgetAlertEvent) bool {
+		return event.CategoryID == rentID
+	})).Return(false, nil)
+
+	return budgetID, groceriesID
+}
+
+func TestEvaluateAlerts(t *testing.T) {
+	mockRepo := &MockRepository{}
+	service := NewService(mockRepo)
+	userID := uuid.New()
+	budgetID, groceriesID := setupAlertBudgets(mockRepo, userID)
+
+	triggered, err := service.EvaluateAlerts(context.Background(), userID)
+
+	assert.NoError(t, err)
+	assert.Len(t, triggered, 1)
+	assert.Equal(t, budgetID, triggered[0].BudgetID)
+	assert.Equal(t, groceriesID, triggered[0].Alert.CategoryID)
+	assert.Equal(t, 1, triggered[0].PeriodStart.Day())
+	mockRepo.AssertNumberOfCalls(t, "RecordAlertEvent", 2)
+	// Alerts are evaluated after transactions change, so the spent amounts are stored
+	mockRepo.AssertCalled(t, "UpdateSpentAmount", mock.Anything, budgetID, groceriesID, 85.0)
+}
+
+func TestAlertMonitor_CheckAlerts(t *testing.T) {
+	mockRepo := &MockRepository{}
+	notifier := &recordingNotifier{}
+	monitor := NewAlertMonitor(NewService(mockRepo), notifier, zap.NewNop())
+	userID := uuid.New()
+	budgetID, groceriesID := setupAlertBudgets(mockRepo, userID)
+
+	err := monitor.CheckAlerts(context.Background(), userID)
+
+	assert.NoError(t, err)
+	assert.Len(t, notifier.messages, 1)
+	assert.Equal(t, []uuid.UUID{userID}, notifier.userIDs)
+	message := notifier.messages[0]
+	assert.Equal(t, NotificationTypeBudgetAlert, message.Type)
+	assert.Equal(t, "Budget alert: Household", message.Title)
+	assert.Equal(t, "You've used 85.0% of your budget for this category", message.Body)
+	assert.Equal(t, budgetID.String(), message.Data["budget_id"])
+	assert.Equal(t, groceriesID.String(), message.Data["category_id"])
+
+	// Delivery failures are reported
+	notifier.err = errors.New("smtp unavailable")
+	assert.Error(t, monitor.CheckAlerts(context.Background(), userID))
+}
+
+func TestAlertMonitor_CheckAlerts_FamilyBudget(t *testing.T) {
+	mockRepo := &MockRepository{}
+	notifier := &recordingNotifier{}
+	monitor := NewAlertMonitor(NewService(mockRepo), notifier, zap.NewNop())
+	now := time.Now().UTC()
+	ownerID := uuid.New()
+	memberID := uuid.New()
+	familyID := uuid.New()
+	budgetID := uuid.New()
+	groceriesID := uuid.New()
+
+	// The alert is evaluated for a member who does not own the budget
+	household := Budget{ID: budgetID, UserID: ownerID, FamilyID: &familyID, Name: "Household", PeriodType: PeriodTypeMonthly, StartDate: time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)}
+	members := []FamilyMember{{FamilyID: familyID, UserID: ownerID, Role: FamilyRoleOwner}, {FamilyID: familyID, UserID: memberID, Role: FamilyRoleMember}}
+	mockRepo.On("GetActiveBudgetsByUser", mock.Anything, memberID).Return([]Budget{household}, nil)
+	mockRepo.On("GetByID", mock.Anything, budgetID).Return(&household, nil)
+	mockRepo.On("GetFamilyMember", mock.Anything, familyID, memberID).Return(&members[1], nil)
+	mockRepo.On("GetFamilyMembers", mock.Anything, familyID).Return(members, nil)
+	mockRepo.On("GetCategoriesByBudgetID", mock.Anything, budgetID).Return([]BudgetCategory{
+		{BudgetID: budgetID, CategoryID: groceriesID, AllocatedAmount: 100, AlertThreshold: 0.8},
+	}, nil)
+	mockRepo.On("GetPeriodsByBudgetID", mock.Anything, budgetID).Return([]BudgetPeriod{}, nil)
+	mockRepo.On("SavePeriod", mock.Anything, mock.Anything).Return(nil)
+	mockRepo.On("GetSpendingByCategory", mock.Anything, []uuid.UUID{ownerID, memberID}, mock.Anything, mock.Anything).
+		Return(map[uuid.UUID]float64{groceriesID: 85}, nil)
+	mockRepo.On("GetSpendingByMember", mock.Anything, []uuid.UUID{ownerID, memberID}, mock.Anything, mock.Anything).
+		Return(map[uuid.UUID]map[uuid.UUID]float64{memberID: {groceriesID: 85}}, nil)
+	mockRepo.On("GetCategoryHierarchy", mock.Anything, mock.Anything).Return([]Category{{ID: groceriesID, UserID: &ownerID, Name: "Groceries"}}, nil)
+	mockRepo.On("UpdateSpentAmount", mock.Anything, budgetID, groceriesID, 85.0).Return(nil)
+	mockRepo.On("GetExpenseHistory", mock.Anything, []uuid.UUID{ownerID, memberID}, mock.Anything, mock.Anything).Return([]ExpenseRecord{}, nil)
+	mockRepo.On("RecordAlertEvent", mock.Anything, mock.Anything).Return(true, nil)
+
+	err := monitor.CheckAlerts(context.Background(), memberID)
+
+	// Every member of the family is notified
+	assert.NoError(t, err)
+	assert.Equal(t, []uuid.UUID{ownerID, memberID}, notifier.userIDs)
+
+	// The category is the owner's own, so only the owner is told its name
+	if assert.Len(t, notifier.messages, 2) {
+		assert.Equal(t, "Budget alert: Household - Groceries", notifier.messages[0].Title)
+		assert.Equal(t, "Budget alert: Household", notifier.messages[1].Title)
+		assert.Equal(t, notifier.messages[0].Body, notifier.messages[1].Body)
+	}
+}
+
+func TestAlertMonitor_TransactionsChanged_CoalescesChecks(t *testing.T) {
+	mockRepo := &MockRepository{}
+	monitor := NewAlertMonitor(NewService(mockRepo), &recordingNotifier{}, zap.NewNop())
+	userID := uuid.New()
+	started := make(chan struct{})
+	release := make(chan struct{})
+	mockRepo.On("GetActiveBudgetsByUser", mock.Anything, userID).Run(func(mock.Arguments) {
+		started <- struct{}{}
+		<-release
+	}).Return([]Budget{}, nil)
+
+	// Changes made before a worker picks the user up are checked once
+	monitor.TransactionsChanged(context.Background(), userID)
+	monitor.TransactionsChanged(context.Background(), userID)
+	assert.Len(t, monitor.queue, 1)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	monitor.Start(ctx, 2)
+	<-started
+
+	// Changes made while the user is being checked are checked once more afterwards
+	monitor.TransactionsChanged(context.Background(), userID)
+	monitor.TransactionsChanged(context.Background(), userID)
+	release <- struct{}{}
+	<-started
+	release <- struct{}{}
+
+	assert.Eventually(t, func() bool {
+		monitor.mu.Lock()
+		defer monitor.mu.Unlock()
+		return len(monitor.checks) == 0
+	}, time.Second, time.Millisecond)
+	mockRepo.AssertNumberOfCalls(t, "GetActiveBudgetsByUser", 2)
+}
@@ -0,0 +1,349 @@
+package budget
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+var (
+	ErrInvalidBudgetMode      = errors.New("invalid budget mode")
+	ErrNotEnvelopeBudget      = errors.New("budget does not use envelope budgeting")
+	ErrEnvelopeNotFound       = errors.New("envelope not found")
+	ErrInsufficientFunds      = errors.New("insufficient funds")
+	ErrEnvelopeNotOverspent   = errors.New("envelope is not overspent")
+	ErrEnvelopeAllocationEdit = errors.New("envelope allocations change through assignments and transfers")
+)
+
+// parseSettings parses the settings of a budget. Budgets without a mode use allocations
+func parseSettings(settings string) (BudgetSettings, error) {
+	parsed := BudgetSettings{Mode: BudgetModeAllocation}
+	if settings == "" {
+		return parsed, nil
+	}
+
+	if err := json.Unmarshal([]byte(settings), &parsed); err != nil {
+		return parsed, fmt.Errorf("invalid budget settings: %w", err)
+	}
+
+	switch parsed.Mode {
+	case "":
+		parsed.Mode = BudgetModeAllocation
+	case BudgetModeAllocation, BudgetModeEnvelope:
+	default:
+		return parsed, ErrInvalidBudgetMode
+	}
+	return parsed, nil
+}
+
+// isEnvelopeBudget reports whether a budget uses envelope budgeting
+func isEnvelopeBudget(budget *Budget) bool {
+	settings, err := parseSettings(budget.Settings)
+	return err == nil && settings.Mode == BudgetModeEnvelope
+}
+
+// GetEnvelopes retrieves the envelopes of an envelope budget for the current period
+func (s *service) GetEnvelopes(ctx context.Context, userID, budgetID uuid.UUID) (*EnvelopeSummary, error) {
+	ctx, span := otel.Tracer("").Start(ctx, "budget.GetEnvelopes",
+		trace.WithAttributes(
+			attribute.String("user_id", userID.String()),
+			attribute.String("budget_id", budgetID.String()),
+		),
+	)
+	defer span.End()
+
+	budget, err := s.getEnvelopeBudget(ctx, userID, budgetID, budgetAccessView)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, err
+	}
+
+	summary, err := s.envelopeSummary(ctx, budget)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, err
+	}
+
+	span.SetAttributes(attribute.Float64("to_be_assigned", summary.ToBeAssigned))
+	return summary, nil
+}
+
+// AssignToEnvelope assigns money that is still to be assigned to an envelope, or returns
+// money from an envelope when the amount is negative
+func (s *service) AssignToEnvelope(ctx context.Context, userID, budgetID uuid.UUID, req *AssignEnvelopeRequest) (*EnvelopeSummary, error) {
+	ctx, span := otel.Tracer("").Start(ctx, "budget.AssignToEnvelope",
+		trace.WithAttributes(
+			attribute.String("user_id", userID.String()),
+			attribute.String("budget_id", budgetID.String()),
+			attribute.String("category_id", req.CategoryID.String()),
+			attribute.Float64("amount", req.Amount),
+		),
+	)
+	defer span.End()
+
+	budget, err := s.getEnvelopeBudget(ctx, userID, budgetID, budgetAccessEdit)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, err
+	}
+
+	amount := roundAmount(req.Amount)
+	transfer := &EnvelopeTransfer{BudgetID: budgetID, UserID: userID, Amount: math.Abs(amount), Note: req.Note}
+	if amount > 0 {
+		transfer.ToCategoryID = &req.CategoryID
+	} else {
+		transfer.FromCategoryID = &req.CategoryID
+	}
+
+	return s.transferEnvelopeFunds(ctx, span, budget, transfer, func(summary *EnvelopeSummary) error {
+		envelope := findEnvelope(summary, req.CategoryID)
+		if envelope == nil {
+			return ErrEnvelopeNotFound
+		}
+		if amount == 0 || (amount > 0 && amount > summary.ToBeAssigned) || (amount < 0 && -amount > envelope.AvailableAmount) {
+			return ErrInsufficientFunds
+		}
+		return nil
+	})
+}
+
+// MoveBetweenEnvelopes moves available money from one envelope to another
+func (s *service) MoveBetweenEnvelopes(ctx context.Context, userID, budgetID uuid.UUID, req *MoveEnvelopeRequest) (*EnvelopeSummary, error) {
+	ctx, span := otel.Tracer("").Start(ctx, "budget.MoveBetweenEnvelopes",
+		trace.WithAttributes(
+			attribute.String("user_id", userID.String()),
+			attribute.String("budget_id", budgetID.String()),
+			attribute.String("from_category_id", req.FromCategoryID.String()),
+			attribute.String("to_category_id", req.ToCategoryID.String()),
+			attribute.Float64("amount", req.Amount),
+		),
+	)
+	defer span.End()
+
+	if req.FromCategoryID == req.ToCategoryID {
+		err := fmt.Errorf("cannot move money to the same envelope")
+		span.SetStatus(codes.Error, err.Error())
+		return nil, err
+	}
+
+	budget, err := s.getEnvelopeBudget(ctx, userID, budgetID, budgetAccessEdit)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, err
+	}
+
+	transfer := &EnvelopeTransfer{
+		BudgetID:       budgetID,
+		UserID:         userID,
+		FromCategoryID: &req.FromCategoryID,
+		ToCategoryID:   &req.ToCategoryID,
+		Amount:         roundAmount(req.Amount),
+		Note:           req.Note,
+	}
+	return s.transferEnvelopeFunds(ctx, span, budget, transfer, func(summary *EnvelopeSummary) error {
+		from, to := findEnvelope(summary, req.FromCategoryID), findEnvelope(summary, req.ToCategoryID)
+		if from == nil || to == nil {
+			return ErrEnvelopeNotFound
+		}
+		if transfer.Amount <= 0 || transfer.Amount > from.AvailableAmount {
+			return ErrInsufficientFunds
+		}
+		return nil
+	})
+}
+
+// CoverOverspending moves the amount an envelope is overspent by from another envelope
+func (s *service) CoverOverspending(ctx context.Context, userID, budgetID, categoryID uuid.UUID, req *CoverOverspendingRequest) (*EnvelopeSummary, error) {
+	ctx, span := otel.Tracer("").Start(ctx, "budget.CoverOverspending",
+		trace.WithAttributes(
+			attribute.String("user_id", userID.String()),
+			attribute.String("budget_id", budgetID.String()),
+			attribute.String("category_id", categoryID.String()),
+			attribute.String("from_category_id", req.FromCategoryID.String()),
+		),
+	)
+	defer span.End()
+
+	if req.FromCategoryID == categoryID {
+		err := fmt.Errorf("cannot cover an envelope from itself")
+		span.SetStatus(codes.Error, err.Error())
+		return nil, err
+	}
+
+	budget, err := s.getEnvelopeBudget(ctx, userID, budgetID, budgetAccessEdit)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, err
+	}
+
+	transfer := &EnvelopeTransfer{
+		BudgetID:       budgetID,
+		UserID:         userID,
+		FromCategoryID: &req.FromCategoryID,
+		ToCategoryID:   &categoryID,
+		Note:           "Cover overspending",
+	}
+	return s.transferEnvelopeFunds(ctx, span, budget, transfer, func(summary *EnvelopeSummary) error {
+		overspent, from := findEnvelope(summary, categoryID), findEnvelope(summary, req.FromCategoryID)
+		if overspent == nil || from == nil {
+			return ErrEnvelopeNotFound
+		}
+		if overspent.OverspentAmount <= 0 {
+			return ErrEnvelopeNotOverspent
+		}
+		if overspent.OverspentAmount > from.AvailableAmount {
+			return ErrInsufficientFunds
+		}
+		transfer.Amount = overspent.OverspentAmount
+		return nil
+	})
+}
+
+// GetEnvelopeTransfers retrieves the audit trail of an envelope budget
+func (s *service) GetEnvelopeTransfers(ctx context.Context, userID, budgetID uuid.UUID, offset, limit int) ([]EnvelopeTransfer, error) {
+	ctx, span := otel.Tracer("").Start(ctx, "budget.GetEnvelopeTransfers",
+		trace.WithAttributes(
+			attribute.String("user_id", userID.String()),
+			attribute.String("budget_id", budgetID.String()),
+			attribute.Int("offset", offset),
+			attribute.Int("limit", limit),
+		),
+	)
+	defer span.End()
+
+	if _, err := s.getEnvelopeBudget(ctx, userID, budgetID, budgetAccessView); err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, err
+	}
+
+	transfers, err := s.repo.GetEnvelopeTransfers(ctx, budgetID, offset, limit)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, err
+	}
+
+	span.SetAttributes(attribute.Int("transfers_count", len(transfers)))
+	return transfers, nil
+}
+
+// getEnvelopeBudget retrieves a budget the user has the given access to that uses envelope
+// budgeting
+func (s *service) getEnvelopeBudget(ctx context.Context, userID, budgetID uuid.UUID, access budgetAccess) (*Budget, error) {
+	budget, err := s.repo.GetByID(ctx, budgetID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.authorizeBudget(ctx, userID, budget, access); err != nil {
+		return nil, err
+	}
+
+	if !isEnvelopeBudget(budget) {
+		return nil, ErrNotEnvelopeBudget
+	}
+
+	return budget, nil
+}
+
+// transferEnvelopeFunds records a transfer and returns the updated envelopes. The transfer
+// is checked against the envelopes while the budget is locked, so that concurrent transfers
+// can't both spend the same money
+func (s *service) transferEnvelopeFunds(ctx context.Context, span trace.Span, budget *Budget, transfer *EnvelopeTransfer, check func(summary *EnvelopeSummary) error) (*EnvelopeSummary, error) {
+	err := s.repo.TransferEnvelopeFunds(ctx, transfer, func(repo Repository) error {
+		summary, err := (&service{repo: repo}).envelopeSummary(ctx, budget)
+		if err != nil {
+			return err
+		}
+		return check(summary)
+	})
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, err
+	}
+
+	summary, err := s.envelopeSummary(ctx, budget)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, err
+	}
+
+	span.SetAttributes(attribute.String("transfer_id", transfer.ID.String()))
+	return summary, nil
+}
+
+// envelopeSummary calculates the envelopes of a budget for the current period. Income of
+// the period that is not assigned to an envelope is still to be assigned
+func (s *service) envelopeSummary(ctx context.Context, budget *Budget) (*EnvelopeSummary, error) {
+	categories, err := s.currentCategories(ctx, budget)
+	if err != nil {
+		return nil, err
+	}
+
+	summary := &EnvelopeSummary{BudgetID: budget.ID, Envelopes: make([]Envelope, 0, len(categories))}
+
+	periods := budgetPeriods(budget, time.Now())
+	if len(periods) > 0 {
+		current := periods[len(periods)-1]
+		summary.PeriodStart, summary.PeriodEnd = current.start, current.end
+
+		userIDs, err := s.budgetUserIDs(ctx, budget)
+		if err != nil {
+			return nil, err
+		}
+		income, err := s.repo.GetIncome(ctx, userIDs, current.start, &current.end)
+		if err != nil {
+			return nil, err
+		}
+		summary.Income = roundAmount(income)
+	}
+
+	for _, category := range categories {
+		available := roundAmount(category.AllocatedAmount + category.RolloverAmount - category.SpentAmount)
+		envelope := Envelope{
+			CategoryID:      category.CategoryID,
+			AssignedAmount:  category.AllocatedAmount,
+			RolloverAmount:  category.RolloverAmount,
+			SpentAmount:     category.SpentAmount,
+			AvailableAmount: available,
+		}
+		if available < 0 {
+			envelope.OverspentAmount = -available
+			summary.Overspent += -available
+		}
+		summary.AssignedAmount += category.AllocatedAmount
+		summary.Envelopes = append(summary.Envelopes, envelope)
+	}
+
+	summary.AssignedAmount = roundAmount(summary.AssignedAmount)
+	summary.Overspent = roundAmount(summary.Overspent)
+	summary.ToBeAssigned = roundAmount(summary.Income - summary.AssignedAmount)
+	return summary, nil
+}
+
+// findEnvelope returns the envelope of a category, or nil if the budget has none
+func findEnvelope(summary *EnvelopeSummary, categoryID uuid.UUID) *Envelope {
+	for i := range summary.Envelopes {
+		if summary.Envelopes[i].CategoryID == categoryID {
+			return &summary.Envelopes[i]
+		}
+	}
+	return nil
+}
@@ -0,0 +1,213 @@
+package budget
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// envelopeFixture sets up an envelope budget for the current month with 2000 of income,
+// an overspent groceries envelope, an empty rent envelope and a fun envelope with money left
+type envelopeFixture struct {
+	repo        *MockRepository
+	userID      uuid.UUID
+	budgetID    uuid.UUID
+	groceriesID uuid.UUID
+	rentID      uuid.UUID
+	funID       uuid.UUID
+}
+
+func newEnvelopeFixture() *envelopeFixture {
+	f := &envelopeFixture{
+		repo:        &MockRepository{},
+		userID:      uuid.New(),
+		budgetID:    uuid.New(),
+		groceriesID: uuid.New(),
+		rentID:      uuid.New(),
+		funID:       uuid.New(),
+	}
+
+	now := time.Now().UTC()
+	budget := &Budget{
+		ID:         f.budgetID,
+		UserID:     f.userID,
+		PeriodType: PeriodTypeMonthly,
+		StartDate:  time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC),
+		Settings:   `{"mode":"envelope"}`,
+	}
+	categories := []BudgetCategory{
+		{BudgetID: f.budgetID, CategoryID: f.groceriesID, AllocatedAmount: 300, SpentAmount: 350, Rollover: true},
+		{BudgetID: f.budgetID, CategoryID: f.rentID, AllocatedAmount: 1000, SpentAmount: 1000, Rollover: true},
+		{BudgetID: f.budgetID, CategoryID: f.funID, AllocatedAmount: 100, SpentAmount: 0, Rollover: true},
+	}
+	spending := map[uuid.UUID]float64{f.groceriesID: 350, f.rentID: 1000}
+
+	f.repo.On("GetByID", mock.Anything, f.budgetID).Return(budget, nil)
+	f.repo.On("GetCategoriesByBudgetID", mock.Anything, f.budgetID).Return(categories, nil)
+	f.repo.On("GetPeriodsByBudgetID", mock.Anything, f.budgetID).Return([]BudgetPeriod{}, nil)
+	f.repo.On("GetSpendingByCategory", mock.Anything, []uuid.UUID{f.userID}, mock.Anything, mock.Anything).Return(spending, nil)
+	f.repo.On("GetCategoryHierarchy", mock.Anything, mock.Anything).Return([]Category{}, nil)
+	f.repo.On("GetIncome", mock.Anything, []uuid.UUID{f.userID}, mock.Anything, mock.Anything).Return(2000.0, nil)
+	return f
+}
+
+func TestParseSettings(t *testing.T) {
+	settings, err := parseSettings("")
+	assert.NoError(t, err)
+	assert.Equal(t, BudgetModeAllocation, settings.Mode)
+
+	settings, err = parseSettings(`{"mode":"envelope","theme":"dark"}`)
+	assert.NoError(t, err)
+	assert.Equal(t, BudgetModeEnvelope, settings.Mode)
+
+	_, err = parseSettings(`{"mode":"jars"}`)
+	assert.ErrorIs(t, err, ErrInvalidBudgetMode)
+
+	_, err = parseSettings(`not json`)
+	assert.Error(t, err)
+}
+
+func TestGetEnvelopes(t *testing.T) {
+	f := newEnvelopeFixture()
+	service := NewService(f.repo)
+
+	summary, err := service.GetEnvelopes(context.Background(), f.userID, f.budgetID)
+
+	assert.NoError(t, err)
+	assert.Equal(t, 2000.0, summary.Income)
+	assert.Equal(t, 1400.0, summary.AssignedAmount)
+	assert.Equal(t, 600.0, summary.ToBeAssigned)
+	assert.Equal(t, 50.0, summary.Overspent)
+	assert.Equal(t, Envelope{CategoryID: f.groceriesID, AssignedAmount: 300, SpentAmount: 350, AvailableAmount: -50, OverspentAmount: 50}, summary.Envelopes[0])
+}
+
+func TestGetEnvelopes_NotEnvelopeBudget(t *testing.T) {
+	mockRepo := &MockRepository{}
+	service := NewService(mockRepo)
+	userID := uuid.New()
+	budgetID := uuid.New()
+
+	mockRepo.On("GetByID", mock.Anything, budgetID).Return(&Budget{ID: budgetID, UserID: userID, Settings: "{}"}, nil)
+
+	summary, err := service.GetEnvelopes(context.Background(), userID, budgetID)
+
+	assert.ErrorIs(t, err, ErrNotEnvelopeBudget)
+	assert.Nil(t, summary)
+}
+
+func TestAssignToEnvelope(t *testing.T) {
+	f := newEnvelopeFixture()
+	service := NewService(f.repo)
+	ctx := context.Background()
+
+	// Only 600 is left to assign
+	_, err := service.AssignToEnvelope(ctx, f.userID, f.budgetID, &AssignEnvelopeRequest{CategoryID: f.funID, Amount: 800})
+	assert.ErrorIs(t, err, ErrInsufficientFunds)
+
+	// Rent has nothing left to return
+	_, err = service.AssignToEnvelope(ctx, f.userID, f.budgetID, &AssignEnvelopeRequest{CategoryID: f.rentID, Amount: -10})
+	assert.ErrorIs(t, err, ErrInsufficientFunds)
+
+	f.repo.On("TransferEnvelopeFunds", mock.Anything, mock.MatchedBy(func(transfer *EnvelopeTransfer) bool {
+		return transfer.FromCategoryID == nil && *transfer.ToCategoryID == f.funID && transfer.Amount == 200 && transfer.Note == "Concert"
+	})).Return(nil)
+
+	_, err = service.AssignToEnvelope(ctx, f.userID, f.budgetID, &AssignEnvelopeRequest{CategoryID: f.funID, Amount: 200, Note: "Concert"})
+	assert.NoError(t, err)
+	f.repo.AssertExpectations(t)
+}
+
+func TestMoveBetweenEnvelopes(t *testing.T) {
+	f := newEnvelopeFixture()
+	service := NewService(f.repo)
+	ctx := context.Background()
+
+	_, err := service.MoveBetweenEnvelopes(ctx, f.userID, f.budgetID, &MoveEnvelopeRequest{FromCategoryID: f.funID, ToCategoryID: f.rentID, Amount: 150})
+	assert.ErrorIs(t, err, ErrInsufficientFunds)
+
+	_, err = service.MoveBetweenEnvelopes(ctx, f.userID, f.budgetID, &MoveEnvelopeRequest{FromCategoryID: uuid.New(), ToCategoryID: f.rentID, Amount: 10})
+	assert.ErrorIs(t, err, ErrEnvelopeNotFound)
+
+	f.repo.On("TransferEnvelopeFunds", mock.Anything, mock.MatchedBy(func(transfer *EnvelopeTransfer) bool {
+		return *transfer.FromCategoryID == f.funID && *transfer.ToCategoryID == f.rentID && transfer.Amount == 40
+	})).Return(nil)
+
+	_, err = service.MoveBetweenEnvelopes(ctx, f.userID, f.budgetID, &MoveEnvelopeRequest{FromCategoryID: f.funID, ToCategoryID: f.rentID, Amount: 40})
+	assert.NoError(t, err)
+	f.repo.AssertExpectations(t)
+}
+
+// lockedRepository hands the check of envelope transfers a repository reading the state
+// the budget is in once it is locked
+type lockedRepository struct {
+	*MockRepository
+	locked *MockRepository
+}
+
+func (r *lockedRepository) TransferEnvelopeFunds(ctx context.Context, transfer *EnvelopeTransfer, check func(repo Repository) error) error {
+	return r.MockRepository.TransferEnvelopeFunds(ctx, transfer, func(Repository) error {
+		return check(r.locked)
+	})
+}
+
+func TestMoveBetweenEnvelopes_ChecksLockedBudget(t *testing.T) {
+	f := newEnvelopeFixture()
+	locked := &MockRepository{}
+	service := NewService(&lockedRepository{MockRepository: f.repo, locked: locked})
+
+	// A concurrent transfer moved the fun money to rent before the budget was locked
+	locked.On("GetCategoriesByBudgetID", mock.Anything, f.budgetID).Return([]BudgetCategory{
+		{BudgetID: f.budgetID, CategoryID: f.groceriesID, AllocatedAmount: 300, SpentAmount: 350, Rollover: true},
+		{BudgetID: f.budgetID, CategoryID: f.rentID, AllocatedAmount: 1100, SpentAmount: 1000, Rollover: true},
+		{BudgetID: f.budgetID, CategoryID: f.funID, AllocatedAmount: 0, SpentAmount: 0, Rollover: true},
+	}, nil)
+	locked.On("GetPeriodsByBudgetID", mock.Anything, f.budgetID).Return([]BudgetPeriod{}, nil)
+	locked.On("GetSpendingByCategory", mock.Anything, []uuid.UUID{f.userID}, mock.Anything, mock.Anything).
+		Return(map[uuid.UUID]float64{f.groceriesID: 350, f.rentID: 1000}, nil)
+	locked.On("GetCategoryHierarchy", mock.Anything, mock.Anything).Return([]Category{}, nil)
+	locked.On("GetIncome", mock.Anything, []uuid.UUID{f.userID}, mock.Anything, mock.Anything).Return(2000.0, nil)
+
+	_, err := service.MoveBetweenEnvelopes(context.Background(), f.userID, f.budgetID, &MoveEnvelopeRequest{FromCategoryID: f.funID, ToCategoryID: f.groceriesID, Amount: 40})
+
+	assert.ErrorIs(t, err, ErrInsufficientFunds)
+	f.repo.AssertNotCalled(t, "TransferEnvelopeFunds", mock.Anything, mock.Anything)
+}
+
+func TestCoverOverspending(t *testing.T) {
+	f := newEnvelopeFixture()
+	service := NewService(f.repo)
+	ctx := context.Background()
+
+	// Rent is not overspent and has nothing to cover groceries with
+	_, err := service.CoverOverspending(ctx, f.userID, f.budgetID, f.rentID, &CoverOverspendingRequest{FromCategoryID: f.funID})
+	assert.ErrorIs(t, err, ErrEnvelopeNotOverspent)
+
+	_, err = service.CoverOverspending(ctx, f.userID, f.budgetID, f.groceriesID, &CoverOverspendingRequest{FromCategoryID: f.rentID})
+	assert.ErrorIs(t, err, ErrInsufficientFunds)
+
+	f.repo.On("TransferEnvelopeFunds", mock.Anything, mock.MatchedBy(func(transfer *EnvelopeTransfer) bool {
+		return *transfer.FromCategoryID == f.funID && *transfer.ToCategoryID == f.groceriesID && transfer.Amount == 50
+	})).Return(nil)
+
+	_, err = service.CoverOverspending(ctx, f.userID, f.budgetID, f.groceriesID, &CoverOverspendingRequest{FromCategoryID: f.funID})
+	assert.NoError(t, err)
+	f.repo.AssertExpectations(t)
+}
+
+func TestUpdateBudgetCategory_EnvelopeAllocationEdit(t *testing.T) {
+	f := newEnvelopeFixture()
+	service := NewService(f.repo)
+	categoryID := uuid.New()
+
+	f.repo.On("GetCategoryByID", mock.Anything, categoryID).
+		Return(&BudgetCategory{ID: categoryID, BudgetID: f.budgetID, CategoryID: f.funID}, nil)
+
+	amount := 500.0
+	_, err := service.UpdateBudgetCategory(context.Background(), f.userID, f.budgetID, categoryID, &UpdateBudgetCategoryRequest{AllocatedAmount: &amount})
+
+	assert.ErrorIs(t, err, ErrEnvelopeAllocationEdit)
+}
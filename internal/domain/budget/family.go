@@ -0,0 +1,196 @@
+package budget
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ErrBudgetAccessDenied is returned when a user may not view or manage a budget
+var ErrBudgetAccessDenied = errors.New("unauthorized access to budget")
+
+// budgetAccess is the access to a budget an operation needs
+type budgetAccess int
+
+const (
+	budgetAccessView budgetAccess = iota
+	budgetAccessEdit
+)
+
+// authorizeBudget checks that a user has the given access to a budget. Personal budgets are
+// only accessible to their owner. Family budgets are visible to every member of the family
+// and managed by its owners
+func (s *service) authorizeBudget(ctx context.Context, userID uuid.UUID, budget *Budget, access budgetAccess) error {
+	if budget.FamilyID == nil {
+		if budget.UserID != userID {
+			return ErrBudgetAccessDenied
+		}
+		return nil
+	}
+
+	member, err := s.repo.GetFamilyMember(ctx, *budget.FamilyID, userID)
+	if err != nil {
+		return err
+	}
+	if member == nil || (access == budgetAccessEdit && member.Role != FamilyRoleOwner) {
+		return ErrBudgetAccessDenied
+	}
+	return nil
+}
+
+// budgetUserIDs returns the users whose transactions count toward a budget, which are all
+// members of the family for family budgets
+func (s *service) budgetUserIDs(ctx context.Context, budget *Budget) ([]uuid.UUID, error) {
+	if budget.FamilyID == nil {
+		return []uuid.UUID{budget.UserID}, nil
+	}
+
+	members, err := s.repo.GetFamilyMembers(ctx, *budget.FamilyID)
+	if err != nil {
+		return nil, err
+	}
+	if len(members) == 0 {
+		return []uuid.UUID{budget.UserID}, nil
+	}
+
+	userIDs := make([]uuid.UUID, len(members))
+	for i, member := range members {
+		userIDs[i] = member.UserID
+	}
+	return userIDs, nil
+}
+
+// hiddenCategoryName stands in for the names of categories a user can't see, such as the
+// private categories of other family members whose spending counts toward a family budget
+const hiddenCategoryName = "Other category"
+
+// categoryViewer tells which categories a user can see: system categories, their own and
+// those of the families they belong to. Memberships are looked up once per family
+type categoryViewer struct {
+	repo     Repository
+	userID   uuid.UUID
+	families map[uuid.UUID]bool
+}
+
+// newCategoryViewer creates the category viewer of a user
+func (s *service) newCategoryViewer(userID uuid.UUID) *categoryViewer {
+	return &categoryViewer{repo: s.repo, userID: userID, families: make(map[uuid.UUID]bool)}
+}
+
+// canSee reports whether the user can see a category
+func (v *categoryViewer) canSee(ctx context.Context, category *Category) (bool, error) {
+	switch {
+	case category.UserID != nil:
+		return *category.UserID == v.userID, nil
+	case category.FamilyID != nil:
+		member, checked := v.families[*category.FamilyID]
+		if !checked {
+			membership, err := v.repo.GetFamilyMember(ctx, *category.FamilyID, v.userID)
+			if err != nil {
+				return false, err
+			}
+			member = membership != nil
+			v.families[*category.FamilyID] = member
+		}
+		return member, nil
+	default:
+		return true, nil
+	}
+}
+
+// name returns the name of a category as the user may see it
+func (v *categoryViewer) name(ctx context.Context, category *Category) (string, error) {
+	visible, err := v.canSee(ctx, category)
+	if err != nil || !visible {
+		return hiddenCategoryName, err
+	}
+	return category.Name, nil
+}
+
+// memberSpending breaks the spending of a family budget in its current period down by
+// family member. Members without spending are reported with zero amounts
+func (s *service) memberSpending(ctx context.Context, budget *Budget, categories []BudgetCategory, now time.Time) ([]MemberSpending, error) {
+	members, err := s.repo.GetFamilyMembers(ctx, *budget.FamilyID)
+	if err != nil || len(members) == 0 {
+		return nil, err
+	}
+
+	result := make([]MemberSpending, len(members))
+	userIDs := make([]uuid.UUID, len(members))
+	for i, member := range members {
+		userIDs[i] = member.UserID
+		result[i] = MemberSpending{
+			UserID:     member.UserID,
+			Name:       member.Name,
+			Role:       member.Role,
+			Categories: []MemberCategorySpending{},
+		}
+	}
+
+	periods := budgetPeriods(budget, now)
+	if len(categories) == 0 || len(periods) == 0 {
+		return result, nil
+	}
+	current := periods[len(periods)-1]
+
+	spending, err := s.repo.GetSpendingByMember(ctx, userIDs, current.start, &current.end)
+	if err != nil || len(spending) == 0 {
+		return result, err
+	}
+
+	var categoryIDs []uuid.UUID
+	seen := make(map[uuid.UUID]bool)
+	for _, byCategory := range spending {
+		for categoryID := range byCategory {
+			if !seen[categoryID] {
+				seen[categoryID] = true
+				categoryIDs = append(categoryIDs, categoryID)
+			}
+		}
+	}
+	owners, err := s.budgetedCategories(ctx, categories, categoryIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	var total float64
+	for i := range result {
+		spent := make(map[uuid.UUID]float64)
+		for categoryID, amount := range spending[result[i].UserID] {
+			if owner, ok := owners[categoryID]; ok {
+				spent[owner] += amount
+			}
+		}
+
+		for categoryID, amount := range spent {
+			result[i].Categories = append(result[i].Categories, MemberCategorySpending{
+				CategoryID:  categoryID,
+				SpentAmount: roundAmount(amount),
+			})
+			result[i].SpentAmount += amount
+		}
+		byAmount := result[i].Categories
+		sort.Slice(byAmount, func(a, b int) bool {
+			if byAmount[a].SpentAmount != byAmount[b].SpentAmount {
+				return byAmount[a].SpentAmount > byAmount[b].SpentAmount
+			}
+			return byAmount[a].CategoryID.String() < byAmount[b].CategoryID.String()
+		})
+		result[i].SpentAmount = roundAmount(result[i].SpentAmount)
+		total += result[i].SpentAmount
+	}
+
+	for i := range result {
+		if total > 0 {
+			result[i].Share = roundAmount(result[i].SpentAmount / total * 100)
+		}
+	}
+	sort.SliceStable(result, func(a, b int) bool {
+		return result[a].SpentAmount > result[b].SpentAmount
+	})
+
+	return result, nil
+}
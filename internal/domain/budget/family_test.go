@@ -0,0 +1,174 @@
+package budget
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAuthorizeBudget(t *testing.T) {
+	ownerID := uuid.New()
+	memberID := uuid.New()
+	outsiderID := uuid.New()
+	familyID := uuid.New()
+
+	personal := &Budget{UserID: ownerID}
+	family := &Budget{UserID: ownerID, FamilyID: &familyID}
+
+	tests := []struct {
+		name    string
+		budget  *Budget
+		userID  uuid.UUID
+		access  budgetAccess
+		allowed bool
+	}{
+		{name: "owner of a personal budget", budget: personal, userID: ownerID, access: budgetAccessEdit, allowed: true},
+		{name: "other user on a personal budget", budget: personal, userID: memberID, access: budgetAccessView},
+		{name: "family owner edits", budget: family, userID: ownerID, access: budgetAccessEdit, allowed: true},
+		{name: "family member views", budget: family, userID: memberID, access: budgetAccessView, allowed: true},
+		{name: "family member edits", budget: family, userID: memberID, access: budgetAccessEdit},
+		{name: "outside the family", budget: family, userID: outsiderID, access: budgetAccessView},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := &MockRepository{}
+			service := NewService(mockRepo).(*service)
+			mockRepo.On("GetFamilyMember", mock.Anything, familyID, ownerID).
+				Return(&FamilyMember{UserID: ownerID, Role: FamilyRoleOwner}, nil)
+			mockRepo.On("GetFamilyMember", mock.Anything, familyID, memberID).
+				Return(&FamilyMember{UserID: memberID, Role: FamilyRoleMember}, nil)
+			mockRepo.On("GetFamilyMember", mock.Anything, familyID, outsiderID).Return(nil, nil)
+
+			err := service.authorizeBudget(context.Background(), tt.userID, tt.budget, tt.access)
+
+			if tt.allowed {
+				assert.NoError(t, err)
+			} else {
+				assert.EqualError(t, err, "unauthorized access to budget")
+			}
+		})
+	}
+}
+
+func TestCreateBudget_FamilyMember(t *testing.T) {
+	mockRepo := &MockRepository{}
+	service := NewService(mockRepo)
+	userID := uuid.New()
+	familyID := uuid.New()
+
+	mockRepo.On("GetFamilyMember", mock.Anything, familyID, userID).
+		Return(&FamilyMember{UserID: userID, Role: FamilyRoleMember}, nil)
+
+	_, err := service.CreateBudget(context.Background(), userID, &CreateBudgetRequest{
+		FamilyID:    &familyID,
+		Name:        "Household",
+		PeriodType:  PeriodTypeMonthly,
+		StartDate:   time.Now(),
+		TotalAmount: 2000,
+	})
+
+	assert.EqualError(t, err, "unauthorized access to budget")
+	mockRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+}
+
+func TestMemberSpending(t *testing.T) {
+	mockRepo := &MockRepository{}
+	service := NewService(mockRepo).(*service)
+	familyID := uuid.New()
+	alex := uuid.New()
+	sam := uuid.New()
+	kim := uuid.New()
+	groceries := uuid.New()
+	produce := uuid.New()
+	dining := uuid.New()
+	unbudgeted := uuid.New()
+
+	budget := &Budget{FamilyID: &familyID, PeriodType: PeriodTypeMonthly, StartDate: date(2024, 1, 1)}
+	categories := []BudgetCategory{{CategoryID: groceries}, {CategoryID: dining}}
+
+	mockRepo.On("GetFamilyMembers", mock.Anything, familyID).Return([]FamilyMember{
+		{UserID: alex, Name: "Alex", Role: FamilyRoleOwner},
+		{UserID: sam, Name: "Sam", Role: FamilyRoleMember},
+		{UserID: kim, Name: "Kim", Role: FamilyRoleMember},
+	}, nil)
+	mockRepo.On("GetSpendingByMember", mock.Anything, []uuid.UUID{alex, sam, kim}, date(2024, 3, 1), mock.Anything).
+		Return(map[uuid.UUID]map[uuid.UUID]float64{
+			alex: {groceries: 100, dining: 50},
+			sam:  {produce: 200, groceries: 50, unbudgeted: 80},
+		}, nil)
+	mockRepo.On("GetCategoryHierarchy", mock.Anything, mock.Anything).Return([]Category{
+		{ID: groceries},
+		{ID: produce, ParentID: &groceries},
+		{ID: dining},
+		{ID: unbudgeted},
+	}, nil)
+
+	members, err := service.memberSpending(context.Background(), budget, categories, date(2024, 3, 15))
+
+	require.NoError(t, err)
+	require.Len(t, members, 3)
+
+	// Subcategory spending counts toward the budgeted parent
+	assert.Equal(t, sam, members[0].UserID)
+	assert.Equal(t, 250.0, members[0].SpentAmount)
+	assert.Equal(t, 62.5, members[0].Share)
+	assert.Equal(t, []MemberCategorySpending{{CategoryID: groceries, SpentAmount: 250}}, members[0].Categories)
+
+	assert.Equal(t, alex, members[1].UserID)
+	assert.Equal(t, FamilyRoleOwner, members[1].Role)
+	assert.Equal(t, 150.0, members[1].SpentAmount)
+	assert.Equal(t, 37.5, members[1].Share)
+	assert.Equal(t, []MemberCategorySpending{
+		{CategoryID: groceries, SpentAmount: 100},
+		{CategoryID: dining, SpentAmount: 50},
+	}, members[1].Categories)
+
+	assert.Equal(t, "Kim", members[2].Name)
+	assert.Equal(t, 0.0, members[2].SpentAmount)
+	assert.Empty(t, members[2].Categories)
+}
+
+func TestGetBudgetSummary_FamilySpending(t *testing.T) {
+	mockRepo := &MockRepository{}
+	service := NewService(mockRepo)
+	familyID := uuid.New()
+	ownerID := uuid.New()
+	memberID := uuid.New()
+	budget := &Budget{ID: uuid.New(), UserID: ownerID, FamilyID: &familyID, PeriodType: PeriodTypeMonthly, StartDate: time.Now()}
+	groceries := BudgetCategory{BudgetID: budget.ID, CategoryID: uuid.New(), AllocatedAmount: 500}
+	userIDs := []uuid.UUID{ownerID, memberID}
+
+	mockRepo.On("GetByID", mock.Anything, budget.ID).Return(budget, nil)
+	mockRepo.On("GetFamilyMember", mock.Anything, familyID, memberID).
+		Return(&FamilyMember{UserID: memberID, Role: FamilyRoleMember}, nil)
+	mockRepo.On("GetFamilyMembers", mock.Anything, familyID).Return([]FamilyMember{
+		{UserID: ownerID, Role: FamilyRoleOwner},
+		{UserID: memberID, Role: FamilyRoleMember},
+	}, nil)
+	mockRepo.On("GetCategoriesByBudgetID", mock.Anything, budget.ID).Return([]BudgetCategory{groceries}, nil)
+	mockRepo.On("GetPeriodsByBudgetID", mock.Anything, budget.ID).Return([]BudgetPeriod{}, nil)
+	mockRepo.On("GetSpendingByCategory", mock.Anything, userIDs, mock.Anything, mock.Anything).
+		Return(map[uuid.UUID]float64{groceries.CategoryID: 300}, nil)
+	mockRepo.On("GetCategoryHierarchy", mock.Anything, mock.Anything).Return([]Category{}, nil)
+	mockRepo.On("GetSpendingByMember", mock.Anything, userIDs, mock.Anything, mock.Anything).
+		Return(map[uuid.UUID]map[uuid.UUID]float64{
+			ownerID:  {groceries.CategoryID: 120},
+			memberID: {groceries.CategoryID: 180},
+		}, nil)
+	mockRepo.On("GetExpenseHistory", mock.Anything, userIDs, mock.Anything, mock.Anything).Return([]ExpenseRecord{}, nil)
+
+	summary, err := service.GetBudgetSummary(context.Background(), memberID, budget.ID, nil)
+
+	require.NoError(t, err)
+	require.Len(t, summary.Members, 2)
+	assert.Equal(t, memberID, summary.Members[0].UserID)
+	assert.Equal(t, 180.0, summary.Members[0].SpentAmount)
+	assert.Equal(t, 60.0, summary.Members[0].Share)
+	mockRepo.AssertExpectations(t)
+}
@@ -0,0 +1,351 @@
+package budget
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	// recurringLookbackMonths is the number of months an expense has to appear in, each
+	// month, to be expected again
+	recurringLookbackMonths = 3
+
+	// Bounds of the seasonal factor, so that a single unusual year does not dominate
+	minSeasonalFactor = 0.5
+	maxSeasonalFactor = 2.0
+)
+
+// recurringExpense is an expense expected to repeat every month
+type recurringExpense struct {
+	payee  string
+	amount float64
+	next   time.Time
+}
+
+// GetBudgetForecast projects the spending of a budget to the end of its current period
+func (s *service) GetBudgetForecast(ctx context.Context, userID, budgetID uuid.UUID) (*BudgetForecast, error) {
+	ctx, span := otel.Tracer("").Start(ctx, "budget.GetBudgetForecast",
+		trace.WithAttributes(
+			attribute.String("user_id", userID.String()),
+			attribute.String("budget_id", budgetID.String()),
+		),
+	)
+	defer span.End()
+
+	// Verify budget access
+	budget, err := s.repo.GetByID(ctx, budgetID)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, err
+	}
+
+	if err := s.authorizeBudget(ctx, userID, budget, budgetAccessView); err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, err
+	}
+
+	// Carried over amounts are part of what can be spent in the period
+	categories, err := s.currentCategories(ctx, budget)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, err
+	}
+
+	forecast, err := s.forecastBudget(ctx, budget, categories, time.Now())
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, err
+	}
+
+	span.SetAttributes(
+		attribute.Float64("total_projected", forecast.TotalProjected),
+		attribute.Int("alerts_count", len(forecast.Alerts)),
+	)
+	return forecast, nil
+}
+
+// forecastBudget projects the spending of every category of a budget to the end of the
+// current period from the pace so far, expected recurring expenses and last year's spending
+func (s *service) forecastBudget(ctx context.Context, budget *Budget, categories []BudgetCategory, now time.Time) (*BudgetForecast, error) {
+	forecast := &BudgetForecast{
+		BudgetID:   budget.ID,
+		AsOf:       truncateToDay(now),
+		Categories: []CategoryForecast{},
+		Alerts:     []BudgetAlert{},
+	}
+
+	if len(categories) == 0 {
+		return forecast, nil
+	}
+
+	periods := budgetPeriods(budget, now)
+	if len(periods) == 0 {
+		return forecast, nil
+	}
+	period := periods[len(periods)-1]
+	today := truncateToDay(now.In(period.start.Location()))
+	forecast.PeriodStart, forecast.PeriodEnd, forecast.AsOf = period.start, period.end, today
+
+	historyStart := period.start.AddDate(-1, 0, 0)
+	userIDs, err := s.budgetUserIDs(ctx, budget)
+	if err != nil {
+		return nil, err
+	}
+	records, err := s.repo.GetExpenseHistory(ctx, userIDs, historyStart, period.end)
+	if err != nil {
+		return nil, err
+	}
+
+	// Attribute every expense to its budgeted category
+	var categoryIDs []uuid.UUID
+	seen := make(map[uuid.UUID]bool)
+	for _, record := range records {
+		if !seen[record.CategoryID] {
+			seen[record.CategoryID] = true
+			categoryIDs = append(categoryIDs, record.CategoryID)
+		}
+	}
+	owners := make(map[uuid.UUID]uuid.UUID)
+	if len(categoryIDs) > 0 {
+		if owners, err = s.budgetedCategories(ctx, categories, categoryIDs); err != nil {
+			return nil, err
+		}
+	}
+
+	history := make(map[uuid.UUID][]ExpenseRecord)
+	current := make(map[uuid.UUID][]ExpenseRecord)
+	for _, record := range records {
+		owner, ok := owners[record.CategoryID]
+		if !ok {
+			continue
+		}
+		if record.TransactionDate.Before(period.start) {
+			history[owner] = append(history[owner], record)
+		} else {
+			current[owner] = append(current[owner], record)
+		}
+	}
+
+	for _, category := range categories {
+		categoryForecast := forecastCategory(category, period, today, historyStart, history[category.CategoryID], current[category.CategoryID])
+		forecast.Categories = append(forecast.Categories, categoryForecast)
+		forecast.TotalAllocated += categoryForecast.AllocatedAmount
+		forecast.TotalSpent += categoryForecast.SpentAmount
+		forecast.TotalProjected += categoryForecast.ProjectedAmount
+
+		if alert := projectedOverBudgetAlert(categoryForecast, category.AlertThreshold); alert != nil {
+			forecast.Alerts = append(forecast.Alerts, *alert)
+		}
+	}
+
+	forecast.TotalAllocated = roundAmount(forecast.TotalAllocated)
+	forecast.TotalSpent = roundAmount(forecast.TotalSpent)
+	forecast.TotalProjected = roundAmount(forecast.TotalProjected)
+	return forecast, nil
+}
+
+// forecastCategory projects the spending of a budget category to the end of a period.
+// Spending so far, without recurring expenses, continues at its daily rate adjusted by how
+// last year's spending in the rest of the period compared to usual. Recurring expenses that
+// have not been paid yet are added on the day they are expected
+func forecastCategory(category BudgetCategory, period periodBounds, today, historyStart time.Time, history, current []ExpenseRecord) CategoryForecast {
+	forecast := CategoryForecast{
+		CategoryID:      category.CategoryID,
+		AllocatedAmount: roundAmount(category.AllocatedAmount + category.RolloverAmount),
+		SeasonalFactor:  1,
+	}
+
+	recurring := detectRecurring(history, period.start)
+	isRecurring := make(map[string]bool, len(recurring))
+	for _, expense := range recurring {
+		isRecurring[expense.payee] = true
+	}
+
+	var spent, recurringPaid float64
+	paid := make(map[string]bool)
+	for _, record := range current {
+		spent += record.Amount
+		paid[record.Payee] = true
+		if isRecurring[record.Payee] {
+			recurringPaid += record.Amount
+		}
+	}
+	forecast.SpentAmount = roundAmount(spent)
+
+	asOf := today
+	if asOf.After(period.end) {
+		asOf = period.end
+	}
+	elapsedDays := daysBetween(period.start, asOf) + 1
+	remainingDays := daysBetween(asOf, period.end)
+	if elapsedDays < 1 {
+		elapsedDays = 1
+	}
+
+	dailyRate := (spent - recurringPaid) / float64(elapsedDays)
+	forecast.DailyRate = roundAmount(dailyRate)
+	if remainingDays > 0 {
+		forecast.SeasonalFactor = seasonalFactor(history, historyStart, asOf, period)
+	}
+
+	// Recurring expenses that have not been paid yet are expected by the end of the period.
+	// Those already overdue are expected tomorrow
+	upcoming := make(map[time.Time]float64)
+	if remainingDays > 0 {
+		tomorrow := asOf.AddDate(0, 0, 1)
+		for _, expense := range recurring {
+			if paid[expense.payee] || expense.next.After(period.end) {
+				continue
+			}
+			due := expense.next
+			if due.Before(tomorrow) {
+				due = tomorrow
+			}
+			upcoming[due] += expense.amount
+			forecast.RecurringAmount += expense.amount
+		}
+	}
+	forecast.RecurringAmount = roundAmount(forecast.RecurringAmount)
+
+	dailyProjection := dailyRate * forecast.SeasonalFactor
+	forecast.ProjectedAmount = roundAmount(spent + dailyProjection*float64(remainingDays) + forecast.RecurringAmount)
+
+	// Walk through the rest of the period to find the day the allocation runs out
+	if spent < forecast.AllocatedAmount && forecast.ProjectedAmount > forecast.AllocatedAmount {
+		cumulative := spent
+		for day := asOf.AddDate(0, 0, 1); !day.After(period.end); day = day.AddDate(0, 0, 1) {
+			cumulative += dailyProjection + upcoming[day]
+			if cumulative > forecast.AllocatedAmount {
+				limitReachedOn := day
+				forecast.LimitReachedOn = &limitReachedOn
+				break
+			}
+		}
+	}
+
+	return forecast
+}
+
+// detectRecurring finds expenses that were paid to the same payee in each of the months
+// before the period start. They are expected again a month after they were last paid
+func detectRecurring(history []ExpenseRecord, periodStart time.Time) []recurringExpense {
+	type occurrences struct {
+		months map[int]bool
+		total  float64
+		count  int
+		last   time.Time
+	}
+
+	lookbackStart := addMonths(periodStart, -recurringLookbackMonths)
+	byPayee := make(map[string]*occurrences)
+	var payees []string
+	for _, record := range history {
+		if record.Payee == "" || record.TransactionDate.Before(lookbackStart) || !record.TransactionDate.Before(periodStart) {
+			continue
+		}
+
+		// Months are counted back from the period start
+		month := 0
+		for month < recurringLookbackMonths && record.TransactionDate.Before(addMonths(periodStart, -month-1)) {
+			month++
+		}
+
+		payee := byPayee[record.Payee]
+		if payee == nil {
+			payee = &occurrences{months: make(map[int]bool)}
+			byPayee[record.Payee] = payee
+			payees = append(payees, record.Payee)
+		}
+		payee.months[month] = true
+		payee.total += record.Amount
+		payee.count++
+		if record.TransactionDate.After(payee.last) {
+			payee.last = record.TransactionDate
+		}
+	}
+
+	var recurring []recurringExpense
+	for _, name := range payees {
+		payee := byPayee[name]
+		if len(payee.months) < recurringLookbackMonths {
+			continue
+		}
+		recurring = append(recurring, recurringExpense{
+			payee:  name,
+			amount: roundAmount(payee.total / float64(payee.count)),
+			next:   truncateToDay(addMonths(payee.last, 1)),
+		})
+	}
+	return recurring
+}
+
+// seasonalFactor compares last year's spending in the rest of the period with the average
+// daily spending over the past year. It is 1 without a full year of history
+func seasonalFactor(history []ExpenseRecord, historyStart, asOf time.Time, period periodBounds) float64 {
+	if len(history) == 0 || history[0].TransactionDate.After(historyStart.AddDate(0, 1, 0)) {
+		return 1
+	}
+
+	windowStart := asOf.AddDate(-1, 0, 1)
+	windowEnd := period.end.AddDate(-1, 0, 0)
+	windowDays := daysBetween(windowStart, windowEnd) + 1
+	historyDays := daysBetween(historyStart, period.start)
+	if windowDays <= 0 || historyDays <= 0 {
+		return 1
+	}
+
+	var total, window float64
+	for _, record := range history {
+		total += record.Amount
+		if !record.TransactionDate.Before(windowStart) && record.TransactionDate.Before(windowEnd.AddDate(0, 0, 1)) {
+			window += record.Amount
+		}
+	}
+
+	usual := total / float64(historyDays)
+	if usual == 0 {
+		return 1
+	}
+
+	factor := (window / float64(windowDays)) / usual
+	return roundAmount(math.Max(minSeasonalFactor, math.Min(maxSeasonalFactor, factor)))
+}
+
+// projectedOverBudgetAlert returns an alert when a category that is still within its
+// allocation is projected to exceed it by the end of the period
+func projectedOverBudgetAlert(forecast CategoryForecast, threshold float64) *BudgetAlert {
+	if forecast.LimitReachedOn == nil {
+		return nil
+	}
+
+	return &BudgetAlert{
+		CategoryID:      forecast.CategoryID,
+		AllocatedAmount: forecast.AllocatedAmount,
+		SpentAmount:     forecast.SpentAmount,
+		Threshold:       threshold,
+		AlertType:       "projected_over_budget",
+		Scope:           AlertScopeCategory,
+		Severity:        AlertSeverityWarning,
+		Message: fmt.Sprintf("At your current pace you'll exceed your budget for this category by $%.2f, running out on %s",
+			forecast.ProjectedAmount-forecast.AllocatedAmount, forecast.LimitReachedOn.Format("Jan 2")),
+		ProjectedAmount: forecast.ProjectedAmount,
+		ProjectedDate:   forecast.LimitReachedOn,
+	}
+}
+
+// daysBetween returns the number of whole days from one day to another
+func daysBetween(from, to time.Time) int {
+	return int(math.Round(truncateToDay(to).Sub(truncateToDay(from)).Hours() / 24))
+}
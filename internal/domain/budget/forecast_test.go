@@ -0,0 +1,132 @@
+package budget
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestDetectRecurring(t *testing.T) {
+	history := []ExpenseRecord{
+		{Amount: 15, TransactionDate: date(2023, 10, 5), Payee: "streaming"},
+		{Amount: 15, TransactionDate: date(2023, 11, 5), Payee: "streaming"},
+		{Amount: 18, TransactionDate: date(2023, 12, 5), Payee: "streaming"},
+		// Missing from November
+		{Amount: 60, TransactionDate: date(2023, 10, 20), Payee: "gym"},
+		{Amount: 60, TransactionDate: date(2023, 12, 20), Payee: "gym"},
+		// Too long ago to count
+		{Amount: 40, TransactionDate: date(2023, 9, 12), Payee: "phone"},
+		{Amount: 40, TransactionDate: date(2023, 11, 12), Payee: "phone"},
+		{Amount: 40, TransactionDate: date(2023, 12, 12), Payee: "phone"},
+	}
+
+	recurring := detectRecurring(history, date(2024, 1, 1))
+
+	assert.Equal(t, []recurringExpense{
+		{payee: "streaming", amount: 16, next: date(2024, 1, 5)},
+	}, recurring)
+}
+
+func TestForecastCategory(t *testing.T) {
+	categoryID := uuid.New()
+	period := periodBounds{start: date(2024, 1, 1), end: date(2024, 1, 31)}
+	history := []ExpenseRecord{
+		{CategoryID: categoryID, Amount: 15.99, TransactionDate: date(2023, 10, 5), Payee: "streaming"},
+		{CategoryID: categoryID, Amount: 15.99, TransactionDate: date(2023, 11, 5), Payee: "streaming"},
+		{CategoryID: categoryID, Amount: 15.99, TransactionDate: date(2023, 12, 5), Payee: "streaming"},
+	}
+	current := []ExpenseRecord{
+		{CategoryID: categoryID, Amount: 120, TransactionDate: date(2024, 1, 3), Payee: "market"},
+		{CategoryID: categoryID, Amount: 80, TransactionDate: date(2024, 1, 9), Payee: "bakery"},
+	}
+	category := BudgetCategory{CategoryID: categoryID, AllocatedAmount: 500, RolloverAmount: 50}
+
+	forecast := forecastCategory(category, period, date(2024, 1, 10), date(2023, 1, 1), history, current)
+
+	// 20 a day for the 21 days left, plus the streaming subscription that is overdue
+	assert.Equal(t, 550.0, forecast.AllocatedAmount)
+	assert.Equal(t, 200.0, forecast.SpentAmount)
+	assert.Equal(t, 20.0, forecast.DailyRate)
+	assert.Equal(t, 1.0, forecast.SeasonalFactor)
+	assert.Equal(t, 15.99, forecast.RecurringAmount)
+	assert.Equal(t, 635.99, forecast.ProjectedAmount)
+	assert.Equal(t, date(2024, 1, 27), *forecast.LimitReachedOn)
+
+	// Once the subscription is paid it no longer counts towards the daily pace
+	current = append(current, ExpenseRecord{CategoryID: categoryID, Amount: 15.99, TransactionDate: date(2024, 1, 10), Payee: "streaming"})
+	forecast = forecastCategory(category, period, date(2024, 1, 10), date(2023, 1, 1), history, current)
+
+	assert.Equal(t, 20.0, forecast.DailyRate)
+	assert.Equal(t, 0.0, forecast.RecurringAmount)
+	assert.Equal(t, 635.99, forecast.ProjectedAmount)
+}
+
+func TestForecastCategory_WithinBudget(t *testing.T) {
+	period := periodBounds{start: date(2024, 1, 1), end: date(2024, 1, 31)}
+	current := []ExpenseRecord{{Amount: 50, TransactionDate: date(2024, 1, 5), Payee: "market"}}
+	category := BudgetCategory{CategoryID: uuid.New(), AllocatedAmount: 400}
+
+	forecast := forecastCategory(category, period, date(2024, 1, 10), date(2023, 1, 1), nil, current)
+
+	assert.Equal(t, 155.0, forecast.ProjectedAmount)
+	assert.Nil(t, forecast.LimitReachedOn)
+	assert.Nil(t, projectedOverBudgetAlert(forecast, 0.8))
+}
+
+func TestSeasonalFactor(t *testing.T) {
+	period := periodBounds{start: date(2024, 1, 1), end: date(2024, 1, 31)}
+	historyStart := date(2023, 1, 1)
+
+	// 365 over the year is 1 a day, while the rest of last January averaged 1.5 a day
+	history := []ExpenseRecord{
+		{Amount: 333.5, TransactionDate: date(2023, 1, 1)},
+		{Amount: 31.5, TransactionDate: date(2023, 1, 20)},
+	}
+	assert.Equal(t, 1.5, seasonalFactor(history, historyStart, date(2024, 1, 10), period))
+
+	history[1].Amount = 300
+	assert.Equal(t, maxSeasonalFactor, seasonalFactor(history, historyStart, date(2024, 1, 10), period))
+
+	// Less than a year of history
+	history = []ExpenseRecord{{Amount: 100, TransactionDate: date(2023, 6, 1)}}
+	assert.Equal(t, 1.0, seasonalFactor(history, historyStart, date(2024, 1, 10), period))
+}
+
+func TestForecastBudget(t *testing.T) {
+	mockRepo := &MockRepository{}
+	service := &service{repo: mockRepo}
+	userID := uuid.New()
+	budgetID := uuid.New()
+	food := Category{ID: uuid.New(), Name: "Food"}
+	groceries := Category{ID: uuid.New(), Name: "Groceries", ParentID: &food.ID}
+	travel := Category{ID: uuid.New(), Name: "Travel"}
+
+	budget := &Budget{ID: budgetID, UserID: userID, PeriodType: PeriodTypeMonthly, StartDate: date(2023, 12, 1)}
+	categories := []BudgetCategory{
+		{BudgetID: budgetID, CategoryID: food.ID, AllocatedAmount: 300, AlertThreshold: 0.8},
+	}
+	mockRepo.On("GetExpenseHistory", mock.Anything, []uuid.UUID{userID}, date(2023, 1, 1), date(2024, 1, 31)).Return([]ExpenseRecord{
+		{CategoryID: groceries.ID, Amount: 150, TransactionDate: date(2024, 1, 4), Payee: "market"},
+		{CategoryID: travel.ID, Amount: 900, TransactionDate: date(2024, 1, 6), Payee: "airline"},
+	}, nil)
+	mockRepo.On("GetCategoryHierarchy", mock.Anything, []uuid.UUID{groceries.ID, travel.ID}).
+		Return([]Category{groceries, travel, food}, nil)
+
+	forecast, err := service.forecastBudget(context.Background(), budget, categories, date(2024, 1, 10))
+
+	assert.NoError(t, err)
+	assert.Equal(t, date(2024, 1, 1), forecast.PeriodStart)
+	assert.Equal(t, date(2024, 1, 31), forecast.PeriodEnd)
+	// Travel is not budgeted and groceries roll up into food
+	assert.Equal(t, 150.0, forecast.TotalSpent)
+	assert.Equal(t, 465.0, forecast.TotalProjected)
+	assert.Len(t, forecast.Alerts, 1)
+	assert.Equal(t, "projected_over_budget", forecast.Alerts[0].AlertType)
+	assert.Equal(t, food.ID, forecast.Alerts[0].CategoryID)
+	assert.Equal(t, 465.0, forecast.Alerts[0].ProjectedAmount)
+	assert.Equal(t, date(2024, 1, 21), *forecast.Alerts[0].ProjectedDate)
+	mockRepo.AssertExpectations(t)
+}
@@ -0,0 +1,432 @@
+package budget
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// averageContributionMonths is the number of recent months the average monthly contribution
+// of a goal is taken over
+const averageContributionMonths = 3
+
+// ErrInvalidGoal is returned when a goal is malformed
+var ErrInvalidGoal = errors.New("invalid goal")
+
+// CreateGoal creates a savings goal for a user
+func (s *service) CreateGoal(ctx context.Context, userID uuid.UUID, req *CreateGoalRequest) (*GoalResponse, error) {
+	ctx, span := otel.Tracer("").Start(ctx, "budget.CreateGoal",
+		trace.WithAttributes(
+			attribute.String("user_id", userID.String()),
+			attribute.String("goal_name", req.Name),
+		),
+	)
+	defer span.End()
+
+	today := truncateToDay(time.Now().UTC())
+	goal := &Goal{
+		UserID:          userID,
+		Name:            req.Name,
+		Description:     req.Description,
+		GoalType:        req.GoalType,
+		TargetAmount:    req.TargetAmount,
+		Currency:        req.Currency,
+		StartDate:       today,
+		TargetDate:      req.TargetDate,
+		Accounts:        goalAccounts(uuid.Nil, req.AccountIDs),
+		ContributionTag: req.ContributionTag,
+		Icon:            req.Icon,
+		Color:           req.Color,
+		IsActive:        true,
+	}
+	if goal.GoalType == "" {
+		goal.GoalType = GoalTypeSavings
+	}
+	if req.StartDate != nil {
+		goal.StartDate = truncateToDay(*req.StartDate)
+	}
+
+	if err := s.validateGoal(ctx, goal); err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, err
+	}
+
+	if err := s.repo.CreateGoal(ctx, goal); err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, err
+	}
+
+	return s.toGoalResponse(ctx, goal, today)
+}
+
+// GetGoal retrieves a savings goal with its progress
+func (s *service) GetGoal(ctx context.Context, userID, goalID uuid.UUID) (*GoalResponse, error) {
+	ctx, span := otel.Tracer("").Start(ctx, "budget.GetGoal",
+		trace.WithAttributes(
+			attribute.String("user_id", userID.String()),
+			attribute.String("goal_id", goalID.String()),
+		),
+	)
+	defer span.End()
+
+	goal, err := s.getUserGoal(ctx, userID, goalID)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, err
+	}
+
+	response, err := s.toGoalResponse(ctx, goal, truncateToDay(time.Now().UTC()))
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, err
+	}
+
+	return response, nil
+}
+
+// ListGoals lists the savings goals of a user with their progress
+func (s *service) ListGoals(ctx context.Context, userID uuid.UUID) ([]GoalResponse, error) {
+	ctx, span := otel.Tracer("").Start(ctx, "budget.ListGoals",
+		trace.WithAttributes(attribute.String("user_id", userID.String())),
+	)
+	defer span.End()
+
+	goals, err := s.repo.GetGoalsByUserID(ctx, userID)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, err
+	}
+
+	today := truncateToDay(time.Now().UTC())
+	responses := make([]GoalResponse, len(goals))
+	for i := range goals {
+		response, err := s.toGoalResponse(ctx, &goals[i], today)
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+			return nil, err
+		}
+		responses[i] = *response
+	}
+
+	span.SetAttributes(attribute.Int("goals_count", len(responses)))
+	return responses, nil
+}
+
+// UpdateGoal updates a savings goal
+func (s *service) UpdateGoal(ctx context.Context, userID, goalID uuid.UUID, req *UpdateGoalRequest) (*GoalResponse, error) {
+	ctx, span := otel.Tracer("").Start(ctx, "budget.UpdateGoal",
+		trace.WithAttributes(
+			attribute.String("user_id", userID.String()),
+			attribute.String("goal_id", goalID.String()),
+		),
+	)
+	defer span.End()
+
+	goal, err := s.getUserGoal(ctx, userID, goalID)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, err
+	}
+
+	// Update fields
+	if req.Name != nil {
+		goal.Name = *req.Name
+	}
+	if req.Description != nil {
+		goal.Description = *req.Description
+	}
+	if req.GoalType != nil {
+		goal.GoalType = *req.GoalType
+	}
+	if req.TargetAmount != nil {
+		goal.TargetAmount = *req.TargetAmount
+	}
+	if req.TargetDate != nil {
+		goal.TargetDate = req.TargetDate
+	}
+	if req.AccountIDs != nil {
+		goal.Accounts = goalAccounts(goal.ID, req.AccountIDs)
+	}
+	if req.ContributionTag != nil {
+		goal.ContributionTag = *req.ContributionTag
+	}
+	if req.Icon != nil {
+		goal.Icon = *req.Icon
+	}
+	if req.Color != nil {
+		goal.Color = *req.Color
+	}
+	if req.IsActive != nil {
+		goal.IsActive = *req.IsActive
+	}
+
+	if err := s.validateGoal(ctx, goal); err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, err
+	}
+
+	if err := s.repo.UpdateGoal(ctx, goal); err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, err
+	}
+
+	return s.toGoalResponse(ctx, goal, truncateToDay(time.Now().UTC()))
+}
+
+// DeleteGoal deletes a savings goal
+func (s *service) DeleteGoal(ctx context.Context, userID, goalID uuid.UUID) error {
+	ctx, span := otel.Tracer("").Start(ctx, "budget.DeleteGoal",
+		trace.WithAttributes(
+			attribute.String("user_id", userID.String()),
+			attribute.String("goal_id", goalID.String()),
+		),
+	)
+	defer span.End()
+
+	if _, err := s.getUserGoal(ctx, userID, goalID); err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return err
+	}
+
+	if err := s.repo.DeleteGoal(ctx, goalID); err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return err
+	}
+
+	return nil
+}
+
+// getUserGoal retrieves a goal owned by the user
+func (s *service) getUserGoal(ctx context.Context, userID, goalID uuid.UUID) (*Goal, error) {
+	goal, err := s.repo.GetGoalByID(ctx, goalID)
+	if err != nil {
+		return nil, err
+	}
+
+	if goal.UserID != userID {
+		return nil, fmt.Errorf("unauthorized access to goal")
+	}
+
+	return goal, nil
+}
+
+// validateGoal checks a goal and that its linked accounts belong to its owner
+func (s *service) validateGoal(ctx context.Context, goal *Goal) error {
+	if goal.Name == "" {
+		return fmt.Errorf("%w: name is required", ErrInvalidGoal)
+	}
+	if goal.TargetAmount <= 0 {
+		return fmt.Errorf("%w: target amount must be positive", ErrInvalidGoal)
+	}
+	switch goal.GoalType {
+	case GoalTypeSavings, GoalTypeEmergencyFund, GoalTypeVacation, GoalTypePurchase,
+		GoalTypeDebtPayoff, GoalTypeInvestment, GoalTypeOther:
+	default:
+		return fmt.Errorf("%w: unknown goal type %q", ErrInvalidGoal, goal.GoalType)
+	}
+	if goal.TargetDate != nil && !goal.TargetDate.After(goal.StartDate) {
+		return fmt.Errorf("%w: target date must be after start date", ErrInvalidGoal)
+	}
+	if len(goal.Accounts) == 0 && goal.ContributionTag == "" {
+		return fmt.Errorf("%w: link an account or set a contribution tag to track progress", ErrInvalidGoal)
+	}
+
+	accountIDs := goalAccountIDs(goal)
+	balances, err := s.repo.GetAccountBalances(ctx, goal.UserID, accountIDs)
+	if err != nil {
+		return err
+	}
+	for _, accountID := range accountIDs {
+		if _, ok := balances[accountID]; !ok {
+			return fmt.Errorf("%w: account %s not found", ErrInvalidGoal, accountID)
+		}
+	}
+
+	return nil
+}
+
+// toGoalResponse builds the response of a goal with its progress as of today
+func (s *service) toGoalResponse(ctx context.Context, goal *Goal, today time.Time) (*GoalResponse, error) {
+	accountIDs := goalAccountIDs(goal)
+	balances, err := s.repo.GetAccountBalances(ctx, goal.UserID, accountIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	transactions, err := s.repo.GetGoalTransactions(ctx, goal.UserID, accountIDs, goal.ContributionTag, goal.StartDate)
+	if err != nil {
+		return nil, err
+	}
+
+	var balance float64
+	for _, accountBalance := range balances {
+		balance += accountBalance
+	}
+
+	return &GoalResponse{
+		ID:              goal.ID,
+		UserID:          goal.UserID,
+		Name:            goal.Name,
+		Description:     goal.Description,
+		GoalType:        goal.GoalType,
+		TargetAmount:    goal.TargetAmount,
+		Currency:        goal.Currency,
+		StartDate:       goal.StartDate,
+		TargetDate:      goal.TargetDate,
+		AccountIDs:      accountIDs,
+		ContributionTag: goal.ContributionTag,
+		Icon:            goal.Icon,
+		Color:           goal.Color,
+		IsActive:        goal.IsActive,
+		Progress:        goalProgress(goal, balance, transactions, today),
+		CreatedAt:       goal.CreatedAt,
+		UpdatedAt:       goal.UpdatedAt,
+	}, nil
+}
+
+// goalProgress works out the progress of a goal as of today.
+//
+// The amount saved is the balance of the linked accounts plus the tagged transactions on
+// other accounts, which count as contributions whatever their sign. Transactions on linked
+// accounts are already part of their balance and only show in the monthly contributions.
+// A goal is on track while the amount saved is at least what saving evenly from the start
+// date to the target date would have saved by now
+func goalProgress(goal *Goal, balance float64, transactions []GoalTransaction, today time.Time) GoalProgress {
+	linked := make(map[uuid.UUID]bool, len(goal.Accounts))
+	for _, account := range goal.Accounts {
+		linked[account.AccountID] = true
+	}
+
+	var tagged float64
+	monthly := make(map[string]float64)
+	for _, tx := range transactions {
+		amount := tx.Amount
+		if !linked[tx.AccountID] {
+			amount = math.Abs(tx.Amount)
+			tagged += amount
+		}
+		monthly[tx.TransactionDate.Format("2006-01")] += amount
+	}
+
+	progress := GoalProgress{
+		CurrentAmount: roundAmount(balance + tagged),
+		Contributions: monthlyContributions(goal.StartDate, today, monthly),
+	}
+	progress.RemainingAmount = roundAmount(math.Max(goal.TargetAmount-progress.CurrentAmount, 0))
+	progress.PercentComplete = roundAmount(math.Min(progress.CurrentAmount/goal.TargetAmount, 1) * 100)
+
+	recent := progress.Contributions
+	if len(recent) > averageContributionMonths {
+		recent = recent[len(recent)-averageContributionMonths:]
+	}
+	var recentTotal float64
+	for _, contribution := range recent {
+		recentTotal += contribution.Amount
+	}
+	if len(recent) > 0 {
+		progress.AverageMonthlyContribution = roundAmount(recentTotal / float64(len(recent)))
+	}
+
+	if progress.RemainingAmount == 0 {
+		progress.Status = GoalStatusCompleted
+		return progress
+	}
+
+	if progress.AverageMonthlyContribution > 0 {
+		months := int(math.Ceil(progress.RemainingAmount / progress.AverageMonthlyContribution))
+		projected := today.AddDate(0, months, 0)
+		progress.ProjectedCompletionDate = &projected
+	}
+
+	if goal.TargetDate == nil {
+		progress.Status = GoalStatusInProgress
+		return progress
+	}
+
+	target := truncateToDay(*goal.TargetDate)
+	progress.MonthsRemaining = monthsUntil(today, target)
+	progress.RequiredMonthlyContribution = progress.RemainingAmount
+	if progress.MonthsRemaining > 0 {
+		progress.RequiredMonthlyContribution = roundAmount(progress.RemainingAmount / float64(progress.MonthsRemaining))
+	}
+
+	elapsed := math.Min(float64(daysBetween(goal.StartDate, today))/float64(daysBetween(goal.StartDate, target)), 1)
+	progress.ExpectedAmount = roundAmount(goal.TargetAmount * math.Max(elapsed, 0))
+
+	progress.Status = GoalStatusBehind
+	if progress.CurrentAmount >= progress.ExpectedAmount && today.Before(target) {
+		progress.Status = GoalStatusOnTrack
+	}
+	return progress
+}
+
+// monthlyContributions lists the contributions of every month from the start date to
+// today, including months without any
+func monthlyContributions(startDate, today time.Time, monthly map[string]float64) []GoalMonthlyContribution {
+	contributions := []GoalMonthlyContribution{}
+	month := time.Date(startDate.Year(), startDate.Month(), 1, 0, 0, 0, 0, time.UTC)
+	for !month.After(today) {
+		period := month.Format("2006-01")
+		contributions = append(contributions, GoalMonthlyContribution{Period: period, Amount: roundAmount(monthly[period])})
+		month = month.AddDate(0, 1, 0)
+	}
+	return contributions
+}
+
+// monthsUntil counts the monthly contributions left before the target date, counting a
+// partial month as a whole one
+func monthsUntil(today, target time.Time) int {
+	if !today.Before(target) {
+		return 0
+	}
+
+	months := (target.Year()-today.Year())*12 + int(target.Month()-today.Month())
+	if target.Day() > today.Day() {
+		months++
+	}
+	return months
+}
+
+// goalAccounts links accounts to a goal, ignoring duplicates
+func goalAccounts(goalID uuid.UUID, accountIDs []uuid.UUID) []GoalAccount {
+	accounts := make([]GoalAccount, 0, len(accountIDs))
+	seen := make(map[uuid.UUID]bool, len(accountIDs))
+	for _, accountID := range accountIDs {
+		if seen[accountID] {
+			continue
+		}
+		seen[accountID] = true
+		accounts = append(accounts, GoalAccount{GoalID: goalID, AccountID: accountID})
+	}
+	return accounts
+}
+
+// goalAccountIDs returns the IDs of a goal's linked accounts in a stable order
+func goalAccountIDs(goal *Goal) []uuid.UUID {
+	accountIDs := make([]uuid.UUID, len(goal.Accounts))
+	for i, account := range goal.Accounts {
+		accountIDs[i] = account.AccountID
+	}
+	sort.Slice(accountIDs, func(i, j int) bool { return accountIDs[i].String() < accountIDs[j].String() })
+	return accountIDs
+}
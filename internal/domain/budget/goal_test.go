@@ -0,0 +1,172 @@
+package budget
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// vacationGoal saves 1200 in 2024 in a linked savings account and through tagged transfers
+func vacationGoal(savingsID uuid.UUID) *Goal {
+	target := date(2024, 12, 31)
+	return &Goal{
+		ID:              uuid.New(),
+		Name:            "Vacation",
+		GoalType:        GoalTypeVacation,
+		TargetAmount:    1200,
+		StartDate:       date(2024, 1, 1),
+		TargetDate:      &target,
+		Accounts:        []GoalAccount{{AccountID: savingsID}},
+		ContributionTag: "vacation",
+	}
+}
+
+func TestGoalProgress(t *testing.T) {
+	savingsID := uuid.New()
+	checkingID := uuid.New()
+	transactions := []GoalTransaction{
+		{AccountID: checkingID, Amount: -150, TransactionDate: date(2024, 3, 10)}, // Tagged transfer
+		{AccountID: savingsID, Amount: 200, TransactionDate: date(2024, 5, 1)},
+		{AccountID: savingsID, Amount: 300, TransactionDate: date(2024, 6, 1)},
+	}
+
+	progress := goalProgress(vacationGoal(savingsID), 500, transactions, date(2024, 7, 1))
+
+	// The savings account balance already includes its deposits
+	assert.Equal(t, 650.0, progress.CurrentAmount)
+	assert.Equal(t, 550.0, progress.RemainingAmount)
+	assert.Equal(t, 54.17, progress.PercentComplete)
+	assert.Equal(t, 598.36, progress.ExpectedAmount)
+	assert.Equal(t, GoalStatusOnTrack, progress.Status)
+	assert.Equal(t, 6, progress.MonthsRemaining)
+	assert.Equal(t, 91.67, progress.RequiredMonthlyContribution)
+	assert.Equal(t, 166.67, progress.AverageMonthlyContribution)
+	assert.Equal(t, date(2024, 11, 1), *progress.ProjectedCompletionDate)
+	assert.Equal(t, []GoalMonthlyContribution{
+		{Period: "2024-01", Amount: 0},
+		{Period: "2024-02", Amount: 0},
+		{Period: "2024-03", Amount: 150},
+		{Period: "2024-04", Amount: 0},
+		{Period: "2024-05", Amount: 200},
+		{Period: "2024-06", Amount: 300},
+		{Period: "2024-07", Amount: 0},
+	}, progress.Contributions)
+}
+
+func TestGoalProgress_Status(t *testing.T) {
+	savingsID := uuid.New()
+
+	tests := []struct {
+		name     string
+		goal     func(*Goal)
+		balance  float64
+		today    time.Time
+		status   GoalStatus
+		required float64
+	}{
+		{name: "behind", balance: 300, today: date(2024, 7, 1), status: GoalStatusBehind, required: 150},
+		{name: "completed", balance: 1300, today: date(2024, 7, 1), status: GoalStatusCompleted},
+		{name: "target date passed", balance: 1000, today: date(2025, 1, 15), status: GoalStatusBehind, required: 200},
+		{name: "no target date", goal: func(g *Goal) { g.TargetDate = nil }, balance: 300, today: date(2024, 7, 1), status: GoalStatusInProgress},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			goal := vacationGoal(savingsID)
+			if tt.goal != nil {
+				tt.goal(goal)
+			}
+
+			progress := goalProgress(goal, tt.balance, nil, tt.today)
+
+			assert.Equal(t, tt.status, progress.Status)
+			assert.Equal(t, tt.required, progress.RequiredMonthlyContribution)
+		})
+	}
+}
+
+func TestMonthsUntil(t *testing.T) {
+	assert.Equal(t, 6, monthsUntil(date(2024, 1, 15), date(2024, 7, 15)))
+	assert.Equal(t, 7, monthsUntil(date(2024, 1, 15), date(2024, 7, 20)))
+	assert.Equal(t, 1, monthsUntil(date(2024, 1, 15), date(2024, 1, 20)))
+	assert.Equal(t, 1, monthsUntil(date(2024, 1, 31), date(2024, 2, 29)))
+	assert.Equal(t, 0, monthsUntil(date(2024, 2, 1), date(2024, 1, 31)))
+}
+
+func TestCreateGoal(t *testing.T) {
+	mockRepo := &MockRepository{}
+	service := NewService(mockRepo)
+	userID := uuid.New()
+	savingsID := uuid.New()
+	target := time.Now().UTC().AddDate(1, 0, 0)
+
+	mockRepo.On("GetAccountBalances", mock.Anything, userID, []uuid.UUID{savingsID}).
+		Return(map[uuid.UUID]float64{savingsID: 250}, nil)
+	mockRepo.On("CreateGoal", mock.Anything, mock.AnythingOfType("*budget.Goal")).Return(nil)
+	mockRepo.On("GetGoalTransactions", mock.Anything, userID, []uuid.UUID{savingsID}, "", mock.AnythingOfType("time.Time")).
+		Return([]GoalTransaction{}, nil)
+
+	goal, err := service.CreateGoal(context.Background(), userID, &CreateGoalRequest{
+		Name:         "Emergency fund",
+		GoalType:     GoalTypeEmergencyFund,
+		TargetAmount: 5000,
+		TargetDate:   &target,
+		AccountIDs:   []uuid.UUID{savingsID, savingsID},
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, []uuid.UUID{savingsID}, goal.AccountIDs)
+	assert.Equal(t, 250.0, goal.Progress.CurrentAmount)
+	assert.Equal(t, 5.0, goal.Progress.PercentComplete)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestCreateGoal_Invalid(t *testing.T) {
+	userID := uuid.New()
+	otherAccountID := uuid.New()
+	past := time.Now().UTC().AddDate(0, -1, 0)
+
+	tests := []struct {
+		name string
+		req  *CreateGoalRequest
+	}{
+		{name: "no target amount", req: &CreateGoalRequest{Name: "Car", ContributionTag: "car"}},
+		{name: "unknown goal type", req: &CreateGoalRequest{Name: "Car", GoalType: "lottery", TargetAmount: 100, ContributionTag: "car"}},
+		{name: "target date in the past", req: &CreateGoalRequest{Name: "Car", TargetAmount: 100, TargetDate: &past, ContributionTag: "car"}},
+		{name: "no way to track progress", req: &CreateGoalRequest{Name: "Car", TargetAmount: 100}},
+		{name: "account of another user", req: &CreateGoalRequest{Name: "Car", TargetAmount: 100, AccountIDs: []uuid.UUID{otherAccountID}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := &MockRepository{}
+			service := NewService(mockRepo)
+			mockRepo.On("GetAccountBalances", mock.Anything, userID, mock.Anything).Return(map[uuid.UUID]float64{}, nil)
+
+			_, err := service.CreateGoal(context.Background(), userID, tt.req)
+
+			assert.ErrorIs(t, err, ErrInvalidGoal)
+			mockRepo.AssertNotCalled(t, "CreateGoal", mock.Anything, mock.Anything)
+		})
+	}
+}
+
+func TestUpdateGoal_Unauthorized(t *testing.T) {
+	mockRepo := &MockRepository{}
+	service := NewService(mockRepo)
+	goal := vacationGoal(uuid.New())
+	goal.UserID = uuid.New()
+
+	mockRepo.On("GetGoalByID", mock.Anything, goal.ID).Return(goal, nil)
+
+	name := "Beach"
+	_, err := service.UpdateGoal(context.Background(), uuid.New(), goal.ID, &UpdateGoalRequest{Name: &name})
+
+	assert.EqualError(t, err, "unauthorized access to goal")
+	mockRepo.AssertNotCalled(t, "UpdateGoal", mock.Anything, mock.Anything)
+}
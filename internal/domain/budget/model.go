@@ -6,15 +6,17 @@ import (
 	"github.com/google/uuid"
 )
 
-// Budget represents a user's budget
+// Budget represents a user's budget. Budgets with a family are shared with every member
+// of the family
 type Budget struct {
 	ID          uuid.UUID  `json:"id" gorm:"type:uuid;primary_key;default:gen_random_uuid()"`
 	UserID      uuid.UUID  `json:"user_id" gorm:"type:uuid;not null"`
-	FamilyID    *uuid.UUID `json:"family_id" gorm:"type:uuid"`
+	FamilyID    *uuid.UUID `json:"family_id" gorm:"type:uuid;index"`
 	Name        string     `json:"name" gorm:"not null"`
 	Description string     `json:"description"`
 
 	PeriodType PeriodType `json:"period_type" gorm:"not null"`
+	PeriodDays int        `json:"period_days,omitempty"` // Length of a custom period
 	StartDate  time.Time  `json:"start_date" gorm:"not null"`
 	EndDate    *time.Time `json:"end_date"`
 
@@ -24,6 +26,9 @@ type Budget struct {
 	IsActive bool   `json:"is_active" gorm:"default:true"`
 	Settings string `json:"settings" gorm:"type:jsonb;default:'{}'"`
 
+	// AlertRules raise alerts on the spending of the whole budget against TotalAmount
+	AlertRules AlertRules `json:"alert_rules,omitempty" gorm:"type:jsonb"`
+
 	CreatedAt time.Time `json:"created_at"`
 	UpdatedAt time.Time `json:"updated_at"`
 }
@@ -37,13 +42,52 @@ type BudgetCategory struct {
 	AllocatedAmount float64 `json:"allocated_amount" gorm:"type:decimal(15,2);not null"`
 	SpentAmount     float64 `json:"spent_amount" gorm:"type:decimal(15,2);default:0.00"`
 
-	AlertThreshold float64 `json:"alert_threshold" gorm:"type:decimal(3,2);default:0.80"`
-	IsActive       bool    `json:"is_active" gorm:"default:true"`
+	// Rollover carries the unspent (or overspent) amount of a period into the next one
+	Rollover       bool    `json:"rollover" gorm:"default:false"`
+	RolloverAmount float64 `json:"rollover_amount" gorm:"type:decimal(15,2);default:0.00"` // Carried into the current period
+
+	// AlertRules replace the alerts derived from AlertThreshold when set
+	AlertThreshold float64    `json:"alert_threshold" gorm:"type:decimal(3,2);default:0.80"`
+	AlertRules     AlertRules `json:"alert_rules,omitempty" gorm:"type:jsonb"`
+	IsActive       bool       `json:"is_active" gorm:"default:true"`
+
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// BudgetPeriod is a single period of a recurring budget, e.g. one month of a monthly budget.
+// Closed periods keep the allocations and actuals they ended with
+type BudgetPeriod struct {
+	ID       uuid.UUID `json:"id" gorm:"type:uuid;primary_key;default:gen_random_uuid()"`
+	BudgetID uuid.UUID `json:"budget_id" gorm:"type:uuid;not null;index"`
+
+	StartDate time.Time `json:"start_date" gorm:"not null"`
+	EndDate   time.Time `json:"end_date" gorm:"not null"` // Last day of the period
+
+	AllocatedAmount float64 `json:"allocated_amount" gorm:"type:decimal(15,2);default:0.00"`
+	RolloverAmount  float64 `json:"rollover_amount" gorm:"type:decimal(15,2);default:0.00"`
+	SpentAmount     float64 `json:"spent_amount" gorm:"type:decimal(15,2);default:0.00"`
+	RemainingAmount float64 `json:"remaining_amount" gorm:"type:decimal(15,2);default:0.00"`
+
+	IsClosed   bool                   `json:"is_closed" gorm:"default:false"`
+	Categories []BudgetPeriodCategory `json:"categories" gorm:"foreignKey:PeriodID"`
 
 	CreatedAt time.Time `json:"created_at"`
 	UpdatedAt time.Time `json:"updated_at"`
 }
 
+// BudgetPeriodCategory represents the allocation and actuals of a category in a budget period
+type BudgetPeriodCategory struct {
+	ID         uuid.UUID `json:"id" gorm:"type:uuid;primary_key;default:gen_random_uuid()"`
+	PeriodID   uuid.UUID `json:"period_id" gorm:"type:uuid;not null;index"`
+	CategoryID uuid.UUID `json:"category_id" gorm:"type:uuid;not null"`
+
+	AllocatedAmount float64 `json:"allocated_amount" gorm:"type:decimal(15,2);default:0.00"`
+	RolloverAmount  float64 `json:"rollover_amount" gorm:"type:decimal(15,2);default:0.00"`
+	SpentAmount     float64 `json:"spent_amount" gorm:"type:decimal(15,2);default:0.00"`
+	RemainingAmount float64 `json:"remaining_amount" gorm:"type:decimal(15,2);default:0.00"`
+}
+
 // PeriodType represents the budget period type
 type PeriodType string
 
@@ -54,16 +98,150 @@ const (
 	PeriodTypeCustom    PeriodType = "custom"
 )
 
+// BudgetTemplate is a reusable budget layout that new budgets can be created from
+type BudgetTemplate struct {
+	ID          uuid.UUID `json:"id" gorm:"type:uuid;primary_key;default:gen_random_uuid()"`
+	UserID      uuid.UUID `json:"user_id" gorm:"type:uuid;not null;index"`
+	Name        string    `json:"name" gorm:"not null"`
+	Description string    `json:"description"`
+
+	PeriodType  PeriodType `json:"period_type" gorm:"not null"`
+	PeriodDays  int        `json:"period_days,omitempty"`
+	TotalAmount float64    `json:"total_amount" gorm:"type:decimal(15,2);not null"`
+	Currency    string     `json:"currency" gorm:"default:'USD'"`
+	Settings    string     `json:"settings" gorm:"type:jsonb;default:'{}'"`
+
+	Categories []BudgetTemplateCategory `json:"categories" gorm:"foreignKey:TemplateID"`
+
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// BudgetTemplateCategory represents a category allocation within a budget template
+type BudgetTemplateCategory struct {
+	ID              uuid.UUID  `json:"id" gorm:"type:uuid;primary_key;default:gen_random_uuid()"`
+	TemplateID      uuid.UUID  `json:"template_id" gorm:"type:uuid;not null;index"`
+	CategoryID      uuid.UUID  `json:"category_id" gorm:"type:uuid;not null"`
+	AllocatedAmount float64    `json:"allocated_amount" gorm:"type:decimal(15,2);not null"`
+	Rollover        bool       `json:"rollover" gorm:"default:false"`
+	AlertThreshold  float64    `json:"alert_threshold" gorm:"type:decimal(3,2);default:0.80"`
+	AlertRules      AlertRules `json:"alert_rules,omitempty" gorm:"type:jsonb"`
+}
+
+// BudgetSuggestion proposes allocations based on the user's average spending per category
+type BudgetSuggestion struct {
+	Months      int                   `json:"months"`
+	From        time.Time             `json:"from"`
+	To          time.Time             `json:"to"`
+	TotalAmount float64               `json:"total_amount"`
+	Categories  []SuggestedAllocation `json:"categories"`
+}
+
+// SuggestedAllocation represents a proposed monthly allocation for a category
+type SuggestedAllocation struct {
+	CategoryID      uuid.UUID `json:"category_id"`
+	AverageSpent    float64   `json:"average_spent"`
+	AllocatedAmount float64   `json:"allocated_amount"`
+}
+
+// BudgetMode selects how money is planned in a budget
+type BudgetMode string
+
+const (
+	BudgetModeAllocation BudgetMode = "allocation" // Categories get fixed allocations
+	BudgetModeEnvelope   BudgetMode = "envelope"   // Income is assigned to envelopes until none is left
+)
+
+// BudgetSettings is the parsed form of a budget's Settings
+type BudgetSettings struct {
+	Mode BudgetMode `json:"mode,omitempty"`
+}
+
+// EnvelopeTransfer records money assigned to, returned from or moved between envelopes.
+// A nil category stands for the budget's money that is still to be assigned
+type EnvelopeTransfer struct {
+	ID             uuid.UUID  `json:"id" gorm:"type:uuid;primary_key;default:gen_random_uuid()"`
+	BudgetID       uuid.UUID  `json:"budget_id" gorm:"type:uuid;not null;index"`
+	UserID         uuid.UUID  `json:"user_id" gorm:"type:uuid;not null"`
+	FromCategoryID *uuid.UUID `json:"from_category_id" gorm:"type:uuid"`
+	ToCategoryID   *uuid.UUID `json:"to_category_id" gorm:"type:uuid"`
+	Amount         float64    `json:"amount" gorm:"type:decimal(15,2);not null"`
+	Note           string     `json:"note"`
+	CreatedAt      time.Time  `json:"created_at"`
+}
+
+// Envelope represents the state of an envelope in the current budget period
+type Envelope struct {
+	CategoryID      uuid.UUID `json:"category_id"`
+	AssignedAmount  float64   `json:"assigned_amount"`
+	RolloverAmount  float64   `json:"rollover_amount"`
+	SpentAmount     float64   `json:"spent_amount"`
+	AvailableAmount float64   `json:"available_amount"`
+	OverspentAmount float64   `json:"overspent_amount"` // Must be covered from another envelope
+}
+
+// EnvelopeSummary represents the envelopes of a budget and the money left to assign
+type EnvelopeSummary struct {
+	BudgetID       uuid.UUID  `json:"budget_id"`
+	PeriodStart    time.Time  `json:"period_start"`
+	PeriodEnd      time.Time  `json:"period_end"`
+	Income         float64    `json:"income"`
+	AssignedAmount float64    `json:"assigned_amount"`
+	ToBeAssigned   float64    `json:"to_be_assigned"`
+	Overspent      float64    `json:"overspent"`
+	Envelopes      []Envelope `json:"envelopes"`
+}
+
+// AssignEnvelopeRequest represents a request to assign money to an envelope. A negative
+// amount returns money from the envelope
+type AssignEnvelopeRequest struct {
+	CategoryID uuid.UUID `json:"category_id" binding:"required"`
+	Amount     float64   `json:"amount" binding:"required"`
+	Note       string    `json:"note"`
+}
+
+// MoveEnvelopeRequest represents a request to move money between envelopes
+type MoveEnvelopeRequest struct {
+	FromCategoryID uuid.UUID `json:"from_category_id" binding:"required"`
+	ToCategoryID   uuid.UUID `json:"to_category_id" binding:"required"`
+	Amount         float64   `json:"amount" binding:"required,gt=0"`
+	Note           string    `json:"note"`
+}
+
+// CoverOverspendingRequest represents a request to cover an overspent envelope from another
+type CoverOverspendingRequest struct {
+	FromCategoryID uuid.UUID `json:"from_category_id" binding:"required"`
+}
+
 // CreateBudgetRequest represents a request to create a new budget
 type CreateBudgetRequest struct {
+	FamilyID    *uuid.UUID `json:"family_id"` // Shares the budget with a family the user owns
 	Name        string     `json:"name" binding:"required"`
 	Description string     `json:"description"`
 	PeriodType  PeriodType `json:"period_type" binding:"required"`
+	PeriodDays  int        `json:"period_days"`
 	StartDate   time.Time  `json:"start_date" binding:"required"`
 	EndDate     *time.Time `json:"end_date"`
 	TotalAmount float64    `json:"total_amount" binding:"required"`
 	Currency    string     `json:"currency"`
 	Settings    string     `json:"settings"`
+	AlertRules  AlertRules `json:"alert_rules"`
+}
+
+// CreateTemplateRequest represents a request to save a budget as a template
+type CreateTemplateRequest struct {
+	Name        string `json:"name" binding:"required"`
+	Description string `json:"description"`
+}
+
+// CopyBudgetRequest represents a request to create a budget from a template or an existing
+// budget. Amounts are multiplied by Scale. Copies of a budget start one period after it
+// unless a start date is given
+type CopyBudgetRequest struct {
+	Name      string     `json:"name"`
+	StartDate *time.Time `json:"start_date"`
+	EndDate   *time.Time `json:"end_date"`
+	Scale     float64    `json:"scale"`
 }
 
 // UpdateBudgetRequest represents a request to update a budget
@@ -71,26 +249,32 @@ type UpdateBudgetRequest struct {
 	Name        *string     `json:"name"`
 	Description *string     `json:"description"`
 	PeriodType  *PeriodType `json:"period_type"`
+	PeriodDays  *int        `json:"period_days"`
 	StartDate   *time.Time  `json:"start_date"`
 	EndDate     *time.Time  `json:"end_date"`
 	TotalAmount *float64    `json:"total_amount"`
 	Currency    *string     `json:"currency"`
 	IsActive    *bool       `json:"is_active"`
 	Settings    *string     `json:"settings"`
+	AlertRules  AlertRules  `json:"alert_rules"` // Replaces the rules when present, an empty list removes them
 }
 
 // CreateBudgetCategoryRequest represents a request to create a budget category
 type CreateBudgetCategoryRequest struct {
-	CategoryID      uuid.UUID `json:"category_id" binding:"required"`
-	AllocatedAmount float64   `json:"allocated_amount" binding:"required"`
-	AlertThreshold  float64   `json:"alert_threshold"`
+	CategoryID      uuid.UUID  `json:"category_id" binding:"required"`
+	AllocatedAmount float64    `json:"allocated_amount" binding:"required"`
+	Rollover        bool       `json:"rollover"`
+	AlertThreshold  float64    `json:"alert_threshold"`
+	AlertRules      AlertRules `json:"alert_rules"`
 }
 
 // UpdateBudgetCategoryRequest represents a request to update a budget category
 type UpdateBudgetCategoryRequest struct {
-	AllocatedAmount *float64 `json:"allocated_amount"`
-	AlertThreshold  *float64 `json:"alert_threshold"`
-	IsActive        *bool    `json:"is_active"`
+	AllocatedAmount *float64   `json:"allocated_amount"`
+	Rollover        *bool      `json:"rollover"`
+	AlertThreshold  *float64   `json:"alert_threshold"`
+	AlertRules      AlertRules `json:"alert_rules"` // Replaces the rules when present, an empty list removes them
+	IsActive        *bool      `json:"is_active"`
 }
 
 // BudgetResponse represents a budget response
@@ -101,27 +285,32 @@ type BudgetResponse struct {
 	Name        string     `json:"name"`
 	Description string     `json:"description"`
 	PeriodType  PeriodType `json:"period_type"`
+	PeriodDays  int        `json:"period_days,omitempty"`
 	StartDate   time.Time  `json:"start_date"`
 	EndDate     *time.Time `json:"end_date"`
 	TotalAmount float64    `json:"total_amount"`
 	Currency    string     `json:"currency"`
 	IsActive    bool       `json:"is_active"`
 	Settings    string     `json:"settings"`
+	AlertRules  AlertRules `json:"alert_rules,omitempty"`
 	CreatedAt   time.Time  `json:"created_at"`
 	UpdatedAt   time.Time  `json:"updated_at"`
 }
 
 // BudgetCategoryResponse represents a budget category response
 type BudgetCategoryResponse struct {
-	ID              uuid.UUID `json:"id"`
-	BudgetID        uuid.UUID `json:"budget_id"`
-	CategoryID      uuid.UUID `json:"category_id"`
-	AllocatedAmount float64   `json:"allocated_amount"`
-	SpentAmount     float64   `json:"spent_amount"`
-	AlertThreshold  float64   `json:"alert_threshold"`
-	IsActive        bool      `json:"is_active"`
-	CreatedAt       time.Time `json:"created_at"`
-	UpdatedAt       time.Time `json:"updated_at"`
+	ID              uuid.UUID  `json:"id"`
+	BudgetID        uuid.UUID  `json:"budget_id"`
+	CategoryID      uuid.UUID  `json:"category_id"`
+	AllocatedAmount float64    `json:"allocated_amount"`
+	SpentAmount     float64    `json:"spent_amount"`
+	Rollover        bool       `json:"rollover"`
+	RolloverAmount  float64    `json:"rollover_amount"`
+	AlertThreshold  float64    `json:"alert_threshold"`
+	AlertRules      AlertRules `json:"alert_rules,omitempty"`
+	IsActive        bool       `json:"is_active"`
+	CreatedAt       time.Time  `json:"created_at"`
+	UpdatedAt       time.Time  `json:"updated_at"`
 }
 
 // BudgetSummary represents a budget summary with spending analysis
@@ -133,17 +322,322 @@ type BudgetSummary struct {
 	RemainingAmount  float64                  `json:"remaining_amount"`
 	SpendingProgress float64                  `json:"spending_progress"` // Percentage spent
 	Alerts           []BudgetAlert            `json:"alerts"`
+	Rollups          []CategoryRollup         `json:"rollups,omitempty"` // Set when a category level is requested
+	Members          []MemberSpending         `json:"members,omitempty"` // Set for family budgets
+}
+
+// MemberSpending represents the spending of a family member against a family budget in
+// its current period
+type MemberSpending struct {
+	UserID      uuid.UUID                `json:"user_id"`
+	Name        string                   `json:"name"`
+	Role        FamilyRole               `json:"role"`
+	SpentAmount float64                  `json:"spent_amount"`
+	Share       float64                  `json:"share"` // Percentage of the budget's spending
+	Categories  []MemberCategorySpending `json:"categories"`
+}
+
+// MemberCategorySpending represents the spending of a family member in a budget category
+type MemberCategorySpending struct {
+	CategoryID  uuid.UUID `json:"category_id"`
+	SpentAmount float64   `json:"spent_amount"`
+}
+
+// FamilyRole represents the role of a member within a family
+type FamilyRole string
+
+const (
+	FamilyRoleOwner  FamilyRole = "family_owner"  // Views and manages the family's budgets
+	FamilyRoleMember FamilyRole = "family_member" // Views the family's budgets
+)
+
+// CategoryRollup represents budget totals for a category with its subcategories aggregated into it
+type CategoryRollup struct {
+	CategoryID       uuid.UUID `json:"category_id"`
+	CategoryName     string    `json:"category_name"`
+	Level            int       `json:"level"`
+	AllocatedAmount  float64   `json:"allocated_amount"`
+	SpentAmount      float64   `json:"spent_amount"`
+	RemainingAmount  float64   `json:"remaining_amount"`
+	SpendingProgress float64   `json:"spending_progress"` // Percentage spent
 }
 
 // BudgetAlert represents a budget alert
 type BudgetAlert struct {
-	CategoryID      uuid.UUID `json:"category_id"`
-	CategoryName    string    `json:"category_name"`
-	AllocatedAmount float64   `json:"allocated_amount"`
-	SpentAmount     float64   `json:"spent_amount"`
-	Threshold       float64   `json:"threshold"`
-	AlertType       string    `json:"alert_type"` // "warning", "critical", "over_budget"
-	Message         string    `json:"message"`
+	CategoryID      uuid.UUID     `json:"category_id"` // Nil for alerts on the whole budget
+	CategoryName    string        `json:"category_name"`
+	AllocatedAmount float64       `json:"allocated_amount"`
+	SpentAmount     float64       `json:"spent_amount"`
+	Threshold       float64       `json:"threshold"`
+	AlertType       string        `json:"alert_type"` // "info", "warning", "critical", "over_budget", "projected_over_budget"
+	Message         string        `json:"message"`
+	Scope           AlertScope    `json:"scope,omitempty"`
+	Severity        AlertSeverity `json:"severity,omitempty"`
+	Channels        []string      `json:"channels,omitempty"` // Notification channels, all enabled ones when empty
+
+	ProjectedAmount float64    `json:"projected_amount,omitempty"` // Projected spending at the end of the period
+	ProjectedDate   *time.Time `json:"projected_date,omitempty"`   // Day the allocation is projected to run out
+}
+
+// AlertScope tells whether an alert is about a single category or the whole budget
+type AlertScope string
+
+const (
+	AlertScopeCategory AlertScope = "category"
+	AlertScopeBudget   AlertScope = "budget"
+)
+
+// AlertSeverity ranks alerts, from informational to critical
+type AlertSeverity string
+
+const (
+	AlertSeverityInfo     AlertSeverity = "info"
+	AlertSeverityWarning  AlertSeverity = "warning"
+	AlertSeverityCritical AlertSeverity = "critical"
+)
+
+// AlertRule raises an alert once spending reaches a fraction of the allocation
+type AlertRule struct {
+	Threshold float64       `json:"threshold"` // e.g. 0.75 for 75%, above 1 for overspending
+	Severity  AlertSeverity `json:"severity"`
+	Channels  []string      `json:"channels,omitempty"` // Notification channels, all enabled ones when empty
+}
+
+// AlertRules is a list of alert rules, stored as JSON
+type AlertRules []AlertRule
+
+// BudgetForecast projects the spending of a budget to the end of the current period
+type BudgetForecast struct {
+	BudgetID       uuid.UUID          `json:"budget_id"`
+	PeriodStart    time.Time          `json:"period_start"`
+	PeriodEnd      time.Time          `json:"period_end"`
+	AsOf           time.Time          `json:"as_of"`
+	TotalAllocated float64            `json:"total_allocated"`
+	TotalSpent     float64            `json:"total_spent"`
+	TotalProjected float64            `json:"total_projected"`
+	Categories     []CategoryForecast `json:"categories"`
+	Alerts         []BudgetAlert      `json:"alerts"`
+}
+
+// CategoryForecast projects the spending of a budget category to the end of the current period
+type CategoryForecast struct {
+	CategoryID      uuid.UUID  `json:"category_id"`
+	AllocatedAmount float64    `json:"allocated_amount"` // Including amounts carried over
+	SpentAmount     float64    `json:"spent_amount"`
+	DailyRate       float64    `json:"daily_rate"`      // Spending per day so far, excluding recurring expenses
+	SeasonalFactor  float64    `json:"seasonal_factor"` // Spending in the rest of the period last year relative to usual
+	RecurringAmount float64    `json:"recurring_amount"`
+	ProjectedAmount float64    `json:"projected_amount"`
+	LimitReachedOn  *time.Time `json:"limit_reached_on,omitempty"`
+}
+
+// BudgetReport compares the allocated and actual amounts of a budget per category for every
+// period that started in a year
+type BudgetReport struct {
+	BudgetID         uuid.UUID          `json:"budget_id"`
+	Year             int                `json:"year"`
+	Periods          []ReportPeriod     `json:"periods"`
+	Categories       []BudgetReportRow  `json:"categories"`
+	Totals           BudgetReportTotals `json:"totals"`
+	Unbudgeted       []BudgetReportRow  `json:"unbudgeted"` // Spending in categories outside the budget
+	UnbudgetedTotals BudgetReportTotals `json:"unbudgeted_totals"`
+}
+
+// ReportPeriod represents a column of a budget report
+type ReportPeriod struct {
+	Label     string    `json:"label"`
+	StartDate time.Time `json:"start_date"`
+	EndDate   time.Time `json:"end_date"`
+}
+
+// BudgetReportRow represents the amounts of a category in every period of a budget report
+type BudgetReportRow struct {
+	CategoryID   uuid.UUID             `json:"category_id"`
+	CategoryName string                `json:"category_name"`
+	Periods      []BudgetReportAmounts `json:"periods"` // In the order of the report periods
+	YearToDate   BudgetReportAmounts   `json:"year_to_date"`
+}
+
+// BudgetReportTotals represents the totals of the rows of a budget report
+type BudgetReportTotals struct {
+	Periods    []BudgetReportAmounts `json:"periods"`
+	YearToDate BudgetReportAmounts   `json:"year_to_date"`
+}
+
+// BudgetReportAmounts compares the allocated and actual amounts of a report cell. The
+// variance is positive when less than allocated was spent
+type BudgetReportAmounts struct {
+	AllocatedAmount float64 `json:"allocated_amount"`
+	ActualAmount    float64 `json:"actual_amount"`
+	Variance        float64 `json:"variance"`
+	VariancePercent float64 `json:"variance_percent"` // Variance relative to the allocated amount
+}
+
+// ExpenseRecord is a single expense used for forecasting
+type ExpenseRecord struct {
+	CategoryID      uuid.UUID
+	Amount          float64
+	TransactionDate time.Time
+	Payee           string // Merchant, or the description of transactions without one
+}
+
+// BudgetAlertEvent records that an alert fired, so that it fires once per period
+type BudgetAlertEvent struct {
+	ID          uuid.UUID `json:"id" gorm:"type:uuid;primary_key;default:gen_random_uuid()"`
+	BudgetID    uuid.UUID `json:"budget_id" gorm:"type:uuid;not null;uniqueIndex:idx_budget_alert_event"`
+	CategoryID  uuid.UUID `json:"category_id" gorm:"type:uuid;not null;uniqueIndex:idx_budget_alert_event"`
+	AlertType   string    `json:"alert_type" gorm:"not null;uniqueIndex:idx_budget_alert_event"`
+	Threshold   float64   `json:"threshold" gorm:"type:decimal(3,2);not null;uniqueIndex:idx_budget_alert_event"`
+	PeriodStart time.Time `json:"period_start" gorm:"type:date;not null;uniqueIndex:idx_budget_alert_event"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// TriggeredAlert is a budget alert that fired for the first time in the current period
+type TriggeredAlert struct {
+	BudgetID    uuid.UUID        `json:"budget_id"`
+	BudgetName  string           `json:"budget_name"`
+	PeriodStart time.Time        `json:"period_start"`
+	Alert       BudgetAlert      `json:"alert"`
+	Recipients  []AlertRecipient `json:"recipients"` // Every member of the family for family budgets
+}
+
+// AlertRecipient is a user notified of a triggered alert
+type AlertRecipient struct {
+	UserID       uuid.UUID `json:"user_id"`
+	SeesCategory bool      `json:"sees_category"` // Whether the user can see the alert's category
+}
+
+// Goal is a savings goal. Progress is derived from the balances of its linked accounts and
+// the transactions tagged with its contribution tag
+type Goal struct {
+	ID          uuid.UUID `json:"id" gorm:"type:uuid;primary_key;default:gen_random_uuid()"`
+	UserID      uuid.UUID `json:"user_id" gorm:"type:uuid;not null;index"`
+	Name        string    `json:"name" gorm:"not null"`
+	Description string    `json:"description"`
+	GoalType    GoalType  `json:"goal_type" gorm:"not null"`
+
+	TargetAmount float64    `json:"target_amount" gorm:"type:decimal(15,2);not null"`
+	Currency     string     `json:"currency" gorm:"default:'USD'"`
+	StartDate    time.Time  `json:"start_date" gorm:"type:date;not null"`
+	TargetDate   *time.Time `json:"target_date" gorm:"type:date"`
+
+	Accounts        []GoalAccount `json:"accounts" gorm:"foreignKey:GoalID"`
+	ContributionTag string        `json:"contribution_tag"` // Transactions with this tag count as contributions
+
+	Icon     string `json:"icon"`
+	Color    string `json:"color"`
+	IsActive bool   `json:"is_active" gorm:"default:true"`
+
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// GoalAccount links a savings account to a goal
+type GoalAccount struct {
+	GoalID    uuid.UUID `json:"goal_id" gorm:"type:uuid;primaryKey"`
+	AccountID uuid.UUID `json:"account_id" gorm:"type:uuid;primaryKey"`
+}
+
+// GoalType represents what a goal saves for
+type GoalType string
+
+const (
+	GoalTypeSavings       GoalType = "savings"
+	GoalTypeEmergencyFund GoalType = "emergency_fund"
+	GoalTypeVacation      GoalType = "vacation"
+	GoalTypePurchase      GoalType = "purchase"
+	GoalTypeDebtPayoff    GoalType = "debt_payoff"
+	GoalTypeInvestment    GoalType = "investment"
+	GoalTypeOther         GoalType = "other"
+)
+
+// GoalStatus tells whether a goal is on track to be reached by its target date
+type GoalStatus string
+
+const (
+	GoalStatusCompleted  GoalStatus = "completed"
+	GoalStatusOnTrack    GoalStatus = "on_track"
+	GoalStatusBehind     GoalStatus = "behind"
+	GoalStatusInProgress GoalStatus = "in_progress" // No target date to be on track for
+)
+
+// GoalTransaction is a transaction on a linked account or tagged with a goal's contribution tag
+type GoalTransaction struct {
+	ID              uuid.UUID
+	AccountID       uuid.UUID
+	Amount          float64
+	TransactionDate time.Time
+}
+
+// GoalProgress reports how far a goal is from its target
+type GoalProgress struct {
+	CurrentAmount               float64                   `json:"current_amount"`
+	RemainingAmount             float64                   `json:"remaining_amount"`
+	PercentComplete             float64                   `json:"percent_complete"`
+	ExpectedAmount              float64                   `json:"expected_amount,omitempty"` // Saved by now when saving evenly towards the target date
+	RequiredMonthlyContribution float64                   `json:"required_monthly_contribution"`
+	AverageMonthlyContribution  float64                   `json:"average_monthly_contribution"` // Over the last three months
+	MonthsRemaining             int                       `json:"months_remaining,omitempty"`
+	ProjectedCompletionDate     *time.Time                `json:"projected_completion_date,omitempty"`
+	Status                      GoalStatus                `json:"status"`
+	Contributions               []GoalMonthlyContribution `json:"contributions"`
+}
+
+// GoalMonthlyContribution is the net amount put towards a goal within a calendar month
+type GoalMonthlyContribution struct {
+	Period string  `json:"period"` // "2006-01"
+	Amount float64 `json:"amount"`
+}
+
+// CreateGoalRequest represents a request to create a savings goal
+type CreateGoalRequest struct {
+	Name            string      `json:"name" binding:"required"`
+	Description     string      `json:"description"`
+	GoalType        GoalType    `json:"goal_type"`
+	TargetAmount    float64     `json:"target_amount" binding:"required"`
+	Currency        string      `json:"currency"`
+	StartDate       *time.Time  `json:"start_date"` // Today when unset
+	TargetDate      *time.Time  `json:"target_date"`
+	AccountIDs      []uuid.UUID `json:"account_ids"`
+	ContributionTag string      `json:"contribution_tag"`
+	Icon            string      `json:"icon"`
+	Color           string      `json:"color"`
+}
+
+// UpdateGoalRequest represents a request to update a savings goal. A non-nil AccountIDs
+// replaces the linked accounts
+type UpdateGoalRequest struct {
+	Name            *string     `json:"name"`
+	Description     *string     `json:"description"`
+	GoalType        *GoalType   `json:"goal_type"`
+	TargetAmount    *float64    `json:"target_amount"`
+	TargetDate      *time.Time  `json:"target_date"`
+	AccountIDs      []uuid.UUID `json:"account_ids"`
+	ContributionTag *string     `json:"contribution_tag"`
+	Icon            *string     `json:"icon"`
+	Color           *string     `json:"color"`
+	IsActive        *bool       `json:"is_active"`
+}
+
+// GoalResponse represents a savings goal with its progress
+type GoalResponse struct {
+	ID              uuid.UUID    `json:"id"`
+	UserID          uuid.UUID    `json:"user_id"`
+	Name            string       `json:"name"`
+	Description     string       `json:"description"`
+	GoalType        GoalType     `json:"goal_type"`
+	TargetAmount    float64      `json:"target_amount"`
+	Currency        string       `json:"currency"`
+	StartDate       time.Time    `json:"start_date"`
+	TargetDate      *time.Time   `json:"target_date"`
+	AccountIDs      []uuid.UUID  `json:"account_ids"`
+	ContributionTag string       `json:"contribution_tag"`
+	Icon            string       `json:"icon"`
+	Color           string       `json:"color"`
+	IsActive        bool         `json:"is_active"`
+	Progress        GoalProgress `json:"progress"`
+	CreatedAt       time.Time    `json:"created_at"`
+	UpdatedAt       time.Time    `json:"updated_at"`
 }
 
 // TableName specifies the table name for Budget
@@ -155,3 +649,43 @@ func (Budget) TableName() string {
 func (BudgetCategory) TableName() string {
 	return "budget_categories"
 }
+
+// TableName specifies the table name for BudgetPeriod
+func (BudgetPeriod) TableName() string {
+	return "budget_periods"
+}
+
+// TableName specifies the table name for BudgetPeriodCategory
+func (BudgetPeriodCategory) TableName() string {
+	return "budget_period_categories"
+}
+
+// TableName specifies the table name for EnvelopeTransfer
+func (EnvelopeTransfer) TableName() string {
+	return "envelope_transfers"
+}
+
+// TableName specifies the table name for BudgetTemplate
+func (BudgetTemplate) TableName() string {
+	return "budget_templates"
+}
+
+// TableName specifies the table name for BudgetTemplateCategory
+func (BudgetTemplateCategory) TableName() string {
+	return "budget_template_categories"
+}
+
+// TableName specifies the table name for BudgetAlertEvent
+func (BudgetAlertEvent) TableName() string {
+	return "budget_alert_events"
+}
+
+// TableName specifies the table name for Goal
+func (Goal) TableName() string {
+	return "goals"
+}
+
+// TableName specifies the table name for GoalAccount
+func (GoalAccount) TableName() string {
+	return "goal_accounts"
+}
@@ -0,0 +1,86 @@
+package budget
+
+import (
+	"time"
+)
+
+// periodBounds is the first and last day of a budget period
+type periodBounds struct {
+	start time.Time
+	end   time.Time
+}
+
+// budgetPeriods returns the bounds of every period of a budget that has started by now.
+// Periods follow each other from the start date until the budget's end date, if any.
+// A custom budget without a period length has a single period ending on its end date
+func budgetPeriods(budget *Budget, now time.Time) []periodBounds {
+	start := truncateToDay(budget.StartDate)
+	today := truncateToDay(now.In(start.Location()))
+
+	var last *time.Time
+	if budget.EndDate != nil {
+		endDate := truncateToDay(budget.EndDate.In(start.Location()))
+		last = &endDate
+	}
+
+	var periods []periodBounds
+	for i := 0; ; i++ {
+		from := periodStart(budget, start, i)
+		if from.After(today) || (last != nil && from.After(*last)) {
+			break
+		}
+
+		var to time.Time
+		if budget.PeriodType == PeriodTypeCustom && budget.PeriodDays <= 0 {
+			// A custom budget without a period length is a single period
+			if last == nil {
+				to = today
+			} else {
+				to = *last
+			}
+		} else {
+			to = periodStart(budget, start, i+1).AddDate(0, 0, -1)
+		}
+		if last != nil && to.After(*last) {
+			to = *last
+		}
+
+		periods = append(periods, periodBounds{start: from, end: to})
+
+		if budget.PeriodType == PeriodTypeCustom && budget.PeriodDays <= 0 {
+			break
+		}
+	}
+
+	return periods
+}
+
+// periodStart returns the first day of the index-th period of a budget starting on start
+func periodStart(budget *Budget, start time.Time, index int) time.Time {
+	switch budget.PeriodType {
+	case PeriodTypeQuarterly:
+		return addMonths(start, 3*index)
+	case PeriodTypeYearly:
+		return addMonths(start, 12*index)
+	case PeriodTypeCustom:
+		return start.AddDate(0, 0, budget.PeriodDays*index)
+	default:
+		return addMonths(start, index)
+	}
+}
+
+// addMonths adds months to a date, clamping the day to the length of the resulting month
+// so that a budget starting on the 31st continues on the last day of shorter months
+func addMonths(t time.Time, months int) time.Time {
+	first := time.Date(t.Year(), t.Month()+time.Month(months), 1, 0, 0, 0, 0, t.Location())
+	day := t.Day()
+	if lastDay := first.AddDate(0, 1, -1).Day(); day > lastDay {
+		day = lastDay
+	}
+	return first.AddDate(0, 0, day-1)
+}
+
+// truncateToDay returns midnight of the given day
+func truncateToDay(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+}
@@ -0,0 +1,76 @@
+package budget
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func date(year int, month time.Month, day int) time.Time {
+	return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+}
+
+func TestBudgetPeriods(t *testing.T) {
+	now := date(2024, 5, 15)
+	endDate := date(2024, 2, 10)
+
+	tests := []struct {
+		name     string
+		budget   Budget
+		expected []periodBounds
+	}{
+		{
+			name:   "monthly clamps to the end of shorter months",
+			budget: Budget{PeriodType: PeriodTypeMonthly, StartDate: date(2024, 1, 31)},
+			expected: []periodBounds{
+				{start: date(2024, 1, 31), end: date(2024, 2, 28)},
+				{start: date(2024, 2, 29), end: date(2024, 3, 30)},
+				{start: date(2024, 3, 31), end: date(2024, 4, 29)},
+				{start: date(2024, 4, 30), end: date(2024, 5, 30)},
+			},
+		},
+		{
+			name:   "quarterly",
+			budget: Budget{PeriodType: PeriodTypeQuarterly, StartDate: date(2024, 1, 1)},
+			expected: []periodBounds{
+				{start: date(2024, 1, 1), end: date(2024, 3, 31)},
+				{start: date(2024, 4, 1), end: date(2024, 6, 30)},
+			},
+		},
+		{
+			name:   "end date cuts the last period short",
+			budget: Budget{PeriodType: PeriodTypeMonthly, StartDate: date(2024, 1, 1), EndDate: &endDate},
+			expected: []periodBounds{
+				{start: date(2024, 1, 1), end: date(2024, 1, 31)},
+				{start: date(2024, 2, 1), end: date(2024, 2, 10)},
+			},
+		},
+		{
+			name:   "custom period length",
+			budget: Budget{PeriodType: PeriodTypeCustom, PeriodDays: 60, StartDate: date(2024, 2, 1)},
+			expected: []periodBounds{
+				{start: date(2024, 2, 1), end: date(2024, 3, 31)},
+				{start: date(2024, 4, 1), end: date(2024, 5, 30)},
+			},
+		},
+		{
+			name:   "custom without a period length is a single period",
+			budget: Budget{PeriodType: PeriodTypeCustom, StartDate: date(2024, 1, 15), EndDate: &endDate},
+			expected: []periodBounds{
+				{start: date(2024, 1, 15), end: date(2024, 2, 10)},
+			},
+		},
+		{
+			name:     "not started yet",
+			budget:   Budget{PeriodType: PeriodTypeYearly, StartDate: date(2025, 1, 1)},
+			expected: nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, budgetPeriods(&tt.budget, now))
+		})
+	}
+}
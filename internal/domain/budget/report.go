@@ -0,0 +1,349 @@
+package budget
+
+import (
+	"context"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// GetBudgetReport compares the allocated and actual amounts of a budget per category for
+// every period that started in the given year, or in the current year when it is zero.
+// Spending in categories outside the budget is reported separately
+func (s *service) GetBudgetReport(ctx context.Context, userID, budgetID uuid.UUID, year int) (*BudgetReport, error) {
+	ctx, span := otel.Tracer("").Start(ctx, "budget.GetBudgetReport",
+		trace.WithAttributes(
+			attribute.String("user_id", userID.String()),
+			attribute.String("budget_id", budgetID.String()),
+			attribute.Int("year", year),
+		),
+	)
+	defer span.End()
+
+	// Verify budget access
+	budget, err := s.repo.GetByID(ctx, budgetID)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, err
+	}
+
+	if err := s.authorizeBudget(ctx, userID, budget, budgetAccessView); err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, err
+	}
+
+	if year == 0 {
+		year = time.Now().Year()
+	}
+
+	report, err := s.budgetReport(ctx, userID, budget, year)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, err
+	}
+
+	span.SetAttributes(
+		attribute.Int("periods_count", len(report.Periods)),
+		attribute.Int("unbudgeted_count", len(report.Unbudgeted)),
+	)
+	return report, nil
+}
+
+// budgetReport builds the report of a budget for a year from its periods and the expenses
+// of its users, as seen by the viewing user. Categories of other members that the viewer
+// can't see are reported under a neutral name
+func (s *service) budgetReport(ctx context.Context, viewerID uuid.UUID, budget *Budget, year int) (*BudgetReport, error) {
+	report := &BudgetReport{
+		BudgetID:   budget.ID,
+		Year:       year,
+		Periods:    []ReportPeriod{},
+		Categories: []BudgetReportRow{},
+		Unbudgeted: []BudgetReportRow{},
+	}
+
+	categories, err := s.repo.GetCategoriesByBudgetID(ctx, budget.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	all, _, err := s.calculatePeriods(ctx, budget, categories)
+	if err != nil {
+		return nil, err
+	}
+	var periods []BudgetPeriod
+	for _, period := range all {
+		if period.StartDate.Year() == year {
+			periods = append(periods, period)
+		}
+	}
+	if len(periods) == 0 {
+		report.Totals = reportTotals(nil, 0)
+		report.UnbudgetedTotals = reportTotals(nil, 0)
+		return report, nil
+	}
+
+	userIDs, err := s.budgetUserIDs(ctx, budget)
+	if err != nil {
+		return nil, err
+	}
+	records, err := s.repo.GetExpenseHistory(ctx, userIDs, periods[0].StartDate, periods[len(periods)-1].EndDate)
+	if err != nil {
+		return nil, err
+	}
+
+	// Every category of the report needs a name and its ancestors, to tell whether
+	// spending falls under a budgeted category
+	var categoryIDs []uuid.UUID
+	seen := make(map[uuid.UUID]bool)
+	addCategory := func(categoryID uuid.UUID) {
+		if !seen[categoryID] {
+			seen[categoryID] = true
+			categoryIDs = append(categoryIDs, categoryID)
+		}
+	}
+	for _, category := range categories {
+		addCategory(category.CategoryID)
+	}
+	for _, period := range periods {
+		for _, category := range period.Categories {
+			addCategory(category.CategoryID)
+		}
+	}
+	for _, record := range records {
+		addCategory(record.CategoryID)
+	}
+
+	hierarchy, err := s.repo.GetCategoryHierarchy(ctx, categoryIDs)
+	if err != nil {
+		return nil, err
+	}
+	viewer := s.newCategoryViewer(viewerID)
+	names := make(map[uuid.UUID]string, len(hierarchy))
+	for i := range hierarchy {
+		if names[hierarchy[i].ID], err = viewer.name(ctx, &hierarchy[i]); err != nil {
+			return nil, err
+		}
+	}
+
+	// Rows follow the current layout of the budget, followed by categories that were
+	// only budgeted in earlier periods
+	rows := make(map[uuid.UUID]*BudgetReportRow)
+	var order []uuid.UUID
+	newRow := func(rows map[uuid.UUID]*BudgetReportRow, order *[]uuid.UUID, categoryID uuid.UUID) *BudgetReportRow {
+		row, exists := rows[categoryID]
+		if !exists {
+			row = &BudgetReportRow{
+				CategoryID:   categoryID,
+				CategoryName: names[categoryID],
+				Periods:      make([]BudgetReportAmounts, len(periods)),
+			}
+			rows[categoryID] = row
+			*order = append(*order, categoryID)
+		}
+		return row
+	}
+	for _, category := range categories {
+		newRow(rows, &order, category.CategoryID)
+	}
+
+	unbudgeted := make(map[uuid.UUID]*BudgetReportRow)
+	var unbudgetedOrder []uuid.UUID
+
+	for i, period := range periods {
+		report.Periods = append(report.Periods, ReportPeriod{
+			Label:     periodLabel(budget.PeriodType, period.StartDate),
+			StartDate: period.StartDate,
+			EndDate:   period.EndDate,
+		})
+
+		budgeted := make([]BudgetCategory, len(period.Categories))
+		for j, category := range period.Categories {
+			budgeted[j] = BudgetCategory{CategoryID: category.CategoryID}
+			row := newRow(rows, &order, category.CategoryID)
+			row.Periods[i] = BudgetReportAmounts{
+				AllocatedAmount: category.AllocatedAmount,
+				ActualAmount:    category.SpentAmount,
+			}
+		}
+
+		// Expenses of the period that no budgeted category covers
+		owners := closestBudgetedCategories(budgeted, hierarchy, categoryIDs)
+		for _, record := range records {
+			date := truncateToDay(record.TransactionDate.In(period.StartDate.Location()))
+			if date.Before(period.StartDate) || date.After(period.EndDate) {
+				continue
+			}
+			if _, ok := owners[record.CategoryID]; ok {
+				continue
+			}
+			row := newRow(unbudgeted, &unbudgetedOrder, record.CategoryID)
+			row.Periods[i].ActualAmount += record.Amount
+		}
+	}
+
+	for _, categoryID := range order {
+		report.Categories = append(report.Categories, finishReportRow(rows[categoryID]))
+	}
+	for _, categoryID := range unbudgetedOrder {
+		report.Unbudgeted = append(report.Unbudgeted, finishReportRow(unbudgeted[categoryID]))
+	}
+	sort.SliceStable(report.Unbudgeted, func(i, j int) bool {
+		return report.Unbudgeted[i].YearToDate.ActualAmount > report.Unbudgeted[j].YearToDate.ActualAmount
+	})
+
+	report.Totals = reportTotals(report.Categories, len(periods))
+	report.UnbudgetedTotals = reportTotals(report.Unbudgeted, len(periods))
+	return report, nil
+}
+
+// finishReportRow calculates the variances and year to date amounts of a report row
+func finishReportRow(row *BudgetReportRow) BudgetReportRow {
+	var allocated, actual float64
+	for i, amounts := range row.Periods {
+		row.Periods[i] = newReportAmounts(amounts.AllocatedAmount, amounts.ActualAmount)
+		allocated += amounts.AllocatedAmount
+		actual += amounts.ActualAmount
+	}
+	row.YearToDate = newReportAmounts(allocated, actual)
+	return *row
+}
+
+// reportTotals sums the rows of a report per period and for the year to date
+func reportTotals(rows []BudgetReportRow, periods int) BudgetReportTotals {
+	allocated := make([]float64, periods)
+	actual := make([]float64, periods)
+	for _, row := range rows {
+		for i, amounts := range row.Periods {
+			allocated[i] += amounts.AllocatedAmount
+			actual[i] += amounts.ActualAmount
+		}
+	}
+
+	totals := BudgetReportTotals{Periods: make([]BudgetReportAmounts, periods)}
+	var totalAllocated, totalActual float64
+	for i := range totals.Periods {
+		totals.Periods[i] = newReportAmounts(allocated[i], actual[i])
+		totalAllocated += allocated[i]
+		totalActual += actual[i]
+	}
+	totals.YearToDate = newReportAmounts(totalAllocated, totalActual)
+	return totals
+}
+
+// newReportAmounts compares an allocated amount with the amount actually spent
+func newReportAmounts(allocated, actual float64) BudgetReportAmounts {
+	allocated = roundAmount(allocated)
+	actual = roundAmount(actual)
+	amounts := BudgetReportAmounts{
+		AllocatedAmount: allocated,
+		ActualAmount:    actual,
+		Variance:        roundAmount(allocated - actual),
+	}
+	if allocated != 0 {
+		amounts.VariancePercent = roundAmount((allocated - actual) / allocated * 100)
+	}
+	return amounts
+}
+
+// periodLabel names a budget period after the month, quarter or year it covers, or after
+// the day it starts for other period types
+func periodLabel(periodType PeriodType, start time.Time) string {
+	switch periodType {
+	case PeriodTypeMonthly:
+		return start.Format("2006-01")
+	case PeriodTypeQuarterly:
+		return fmt.Sprintf("%d-Q%d", start.Year(), (int(start.Month())-1)/3+1)
+	case PeriodTypeYearly:
+		return start.Format("2006")
+	default:
+		return start.Format("2006-01-02")
+	}
+}
+
+// WriteCSV writes the report as CSV with a column for each amount of each period, followed
+// by the year to date amounts
+func (r *BudgetReport) WriteCSV(w io.Writer) error {
+	writer := csv.NewWriter(w)
+
+	labels := make([]string, 0, len(r.Periods)+1)
+	for _, period := range r.Periods {
+		labels = append(labels, period.Label)
+	}
+	labels = append(labels, "ytd")
+
+	header := []string{"section", "category_id", "category"}
+	for _, label := range labels {
+		label = csvText(label)
+		header = append(header, label+" allocated", label+" actual", label+" variance", label+" variance %")
+	}
+	if err := writer.Write(header); err != nil {
+		return err
+	}
+
+	writeRow := func(section, categoryID, name string, periods []BudgetReportAmounts, yearToDate BudgetReportAmounts) error {
+		record := []string{section, categoryID, csvText(name)}
+		for _, amounts := range periods {
+			record = appendAmounts(record, amounts)
+		}
+		return writer.Write(appendAmounts(record, yearToDate))
+	}
+
+	sections := []struct {
+		name   string
+		rows   []BudgetReportRow
+		totals BudgetReportTotals
+	}{
+		{name: "budgeted", rows: r.Categories, totals: r.Totals},
+		{name: "unbudgeted", rows: r.Unbudgeted, totals: r.UnbudgetedTotals},
+	}
+	for _, section := range sections {
+		for _, row := range section.rows {
+			if err := writeRow(section.name, row.CategoryID.String(), row.CategoryName, row.Periods, row.YearToDate); err != nil {
+				return err
+			}
+		}
+		if err := writeRow(section.name, "", "Total", section.totals.Periods, section.totals.YearToDate); err != nil {
+			return err
+		}
+	}
+
+	writer.Flush()
+	return writer.Error()
+}
+
+// appendAmounts appends the amounts of a report cell to a CSV record
+func appendAmounts(record []string, amounts BudgetReportAmounts) []string {
+	return append(record,
+		formatAmount(amounts.AllocatedAmount),
+		formatAmount(amounts.ActualAmount),
+		formatAmount(amounts.Variance),
+		formatAmount(amounts.VariancePercent),
+	)
+}
+
+// csvText escapes text chosen by users, such as category names, so spreadsheets opening the
+// CSV do not evaluate it as a formula. Amounts are formatted by us and left as numbers
+func csvText(value string) string {
+	if value != "" && strings.ContainsRune("=+-@\t\r", rune(value[0])) {
+		return "'" + value
+	}
+	return value
+}
+
+// formatAmount formats an amount with two decimals
+func formatAmount(amount float64) string {
+	return strconv.FormatFloat(amount, 'f', 2, 64)
+}
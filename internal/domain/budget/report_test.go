@@ -0,0 +1,186 @@
+package budget
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetBudgetReport(t *testing.T) {
+	mockRepo := &MockRepository{}
+	service := NewService(mockRepo)
+	userID := uuid.New()
+	groceries := Category{ID: uuid.New(), Name: "Groceries"}
+	produce := Category{ID: uuid.New(), Name: "Produce", ParentID: &groceries.ID}
+	travel := Category{ID: uuid.New(), Name: "Travel"}
+	gifts := Category{ID: uuid.New(), Name: "Gifts"}
+
+	end := date(2024, 2, 29)
+	budget := &Budget{ID: uuid.New(), UserID: userID, PeriodType: PeriodTypeMonthly, StartDate: date(2024, 1, 1), EndDate: &end}
+
+	mockRepo.On("GetByID", mock.Anything, budget.ID).Return(budget, nil)
+	mockRepo.On("GetCategoriesByBudgetID", mock.Anything, budget.ID).
+		Return([]BudgetCategory{{BudgetID: budget.ID, CategoryID: groceries.ID, AllocatedAmount: 400}}, nil)
+	mockRepo.On("GetPeriodsByBudgetID", mock.Anything, budget.ID).Return([]BudgetPeriod{
+		{
+			BudgetID: budget.ID, StartDate: date(2024, 1, 1), EndDate: date(2024, 1, 31), IsClosed: true,
+			Categories: []BudgetPeriodCategory{newPeriodCategory(groceries.ID, 400, 0, 300)},
+		},
+		{
+			BudgetID: budget.ID, StartDate: date(2024, 2, 1), EndDate: date(2024, 2, 29), IsClosed: true,
+			Categories: []BudgetPeriodCategory{newPeriodCategory(groceries.ID, 400, 100, 450)},
+		},
+	}, nil)
+	mockRepo.On("GetExpenseHistory", mock.Anything, []uuid.UUID{userID}, date(2024, 1, 1), date(2024, 2, 29)).Return([]ExpenseRecord{
+		{CategoryID: produce.ID, Amount: 50, TransactionDate: date(2024, 1, 10)},
+		{CategoryID: travel.ID, Amount: 100, TransactionDate: date(2024, 1, 20)},
+		{CategoryID: gifts.ID, Amount: 30, TransactionDate: date(2024, 2, 2)},
+		{CategoryID: travel.ID, Amount: 200, TransactionDate: date(2024, 2, 5)},
+	}, nil)
+	mockRepo.On("GetCategoryHierarchy", mock.Anything, mock.Anything).Return([]Category{groceries, produce, travel, gifts}, nil)
+
+	report, err := service.GetBudgetReport(context.Background(), userID, budget.ID, 2024)
+
+	require.NoError(t, err)
+	require.Len(t, report.Periods, 2)
+	assert.Equal(t, "2024-01", report.Periods[0].Label)
+	assert.Equal(t, "2024-02", report.Periods[1].Label)
+
+	// Carried over amounts are not part of the allocation
+	require.Len(t, report.Categories, 1)
+	assert.Equal(t, "Groceries", report.Categories[0].CategoryName)
+	assert.Equal(t, []BudgetReportAmounts{
+		{AllocatedAmount: 400, ActualAmount: 300, Variance: 100, VariancePercent: 25},
+		{AllocatedAmount: 400, ActualAmount: 450, Variance: -50, VariancePercent: -12.5},
+	}, report.Categories[0].Periods)
+	assert.Equal(t, BudgetReportAmounts{AllocatedAmount: 800, ActualAmount: 750, Variance: 50, VariancePercent: 6.25}, report.Categories[0].YearToDate)
+	assert.Equal(t, report.Categories[0].YearToDate, report.Totals.YearToDate)
+
+	// Subcategories of budgeted categories are not unbudgeted
+	require.Len(t, report.Unbudgeted, 2)
+	assert.Equal(t, travel.ID, report.Unbudgeted[0].CategoryID)
+	assert.Equal(t, 100.0, report.Unbudgeted[0].Periods[0].ActualAmount)
+	assert.Equal(t, BudgetReportAmounts{ActualAmount: 300, Variance: -300}, report.Unbudgeted[0].YearToDate)
+	assert.Equal(t, "Gifts", report.Unbudgeted[1].CategoryName)
+	assert.Equal(t, 230.0, report.UnbudgetedTotals.Periods[1].ActualAmount)
+	assert.Equal(t, 330.0, report.UnbudgetedTotals.YearToDate.ActualAmount)
+	mockRepo.AssertNotCalled(t, "SavePeriod", mock.Anything, mock.Anything)
+}
+
+func TestGetBudgetReport_FamilyHidesPrivateCategories(t *testing.T) {
+	mockRepo := &MockRepository{}
+	service := NewService(mockRepo)
+	ownerID := uuid.New()
+	memberID := uuid.New()
+	familyID := uuid.New()
+	otherFamilyID := uuid.New()
+	groceries := Category{ID: uuid.New(), Name: "Groceries"}
+	shared := Category{ID: uuid.New(), FamilyID: &familyID, Name: "Kids"}
+	own := Category{ID: uuid.New(), UserID: &ownerID, Name: "Hobbies"}
+	private := Category{ID: uuid.New(), UserID: &memberID, Name: "Therapy"}
+	otherFamily := Category{ID: uuid.New(), FamilyID: &otherFamilyID, Name: "In-laws"}
+
+	end := date(2024, 1, 31)
+	budget := &Budget{ID: uuid.New(), UserID: ownerID, FamilyID: &familyID, PeriodType: PeriodTypeMonthly, StartDate: date(2024, 1, 1), EndDate: &end}
+	members := []FamilyMember{
+		{FamilyID: familyID, UserID: ownerID, Role: FamilyRoleOwner},
+		{FamilyID: familyID, UserID: memberID, Role: FamilyRoleMember},
+	}
+
+	mockRepo.On("GetByID", mock.Anything, budget.ID).Return(budget, nil)
+	mockRepo.On("GetFamilyMember", mock.Anything, familyID, ownerID).Return(&members[0], nil)
+	mockRepo.On("GetFamilyMember", mock.Anything, otherFamilyID, ownerID).Return(nil, nil)
+	mockRepo.On("GetFamilyMembers", mock.Anything, familyID).Return(members, nil)
+	mockRepo.On("GetCategoriesByBudgetID", mock.Anything, budget.ID).
+		Return([]BudgetCategory{{BudgetID: budget.ID, CategoryID: groceries.ID, AllocatedAmount: 400}}, nil)
+	mockRepo.On("GetPeriodsByBudgetID", mock.Anything, budget.ID).Return([]BudgetPeriod{{
+		BudgetID: budget.ID, StartDate: date(2024, 1, 1), EndDate: date(2024, 1, 31), IsClosed: true,
+		Categories: []BudgetPeriodCategory{newPeriodCategory(groceries.ID, 400, 0, 300)},
+	}}, nil)
+	mockRepo.On("GetExpenseHistory", mock.Anything, []uuid.UUID{ownerID, memberID}, date(2024, 1, 1), date(2024, 1, 31)).Return([]ExpenseRecord{
+		{CategoryID: shared.ID, Amount: 40, TransactionDate: date(2024, 1, 10)},
+		{CategoryID: own.ID, Amount: 30, TransactionDate: date(2024, 1, 11)},
+		{CategoryID: private.ID, Amount: 80, TransactionDate: date(2024, 1, 12)},
+		{CategoryID: otherFamily.ID, Amount: 20, TransactionDate: date(2024, 1, 13)},
+	}, nil)
+	mockRepo.On("GetCategoryHierarchy", mock.Anything, mock.Anything).Return([]Category{groceries, shared, own, private, otherFamily}, nil)
+
+	report, err := service.GetBudgetReport(context.Background(), ownerID, budget.ID, 2024)
+	require.NoError(t, err)
+
+	// Amounts of categories the viewer can't see are kept under a neutral name
+	names := make(map[uuid.UUID]string)
+	for _, row := range report.Unbudgeted {
+		names[row.CategoryID] = row.CategoryName
+	}
+	assert.Equal(t, map[uuid.UUID]string{
+		shared.ID:      "Kids",
+		own.ID:         "Hobbies",
+		private.ID:     hiddenCategoryName,
+		otherFamily.ID: hiddenCategoryName,
+	}, names)
+	assert.Equal(t, 80.0, report.Unbudgeted[0].YearToDate.ActualAmount)
+	assert.Equal(t, 170.0, report.UnbudgetedTotals.YearToDate.ActualAmount)
+
+	var buf bytes.Buffer
+	require.NoError(t, report.WriteCSV(&buf))
+	assert.NotContains(t, buf.String(), "Therapy")
+	assert.NotContains(t, buf.String(), "In-laws")
+}
+
+func TestGetBudgetReport_NoPeriodsInYear(t *testing.T) {
+	mockRepo := &MockRepository{}
+	service := NewService(mockRepo)
+	userID := uuid.New()
+	budget := &Budget{ID: uuid.New(), UserID: userID, PeriodType: PeriodTypeMonthly, StartDate: date(2024, 1, 1)}
+
+	mockRepo.On("GetByID", mock.Anything, budget.ID).Return(budget, nil)
+	mockRepo.On("GetCategoriesByBudgetID", mock.Anything, budget.ID).Return([]BudgetCategory{}, nil)
+	mockRepo.On("GetPeriodsByBudgetID", mock.Anything, budget.ID).Return([]BudgetPeriod{}, nil)
+
+	report, err := service.GetBudgetReport(context.Background(), userID, budget.ID, 2023)
+
+	require.NoError(t, err)
+	assert.Empty(t, report.Periods)
+	assert.Empty(t, report.Categories)
+	assert.Equal(t, BudgetReportAmounts{}, report.Totals.YearToDate)
+	mockRepo.AssertNotCalled(t, "GetExpenseHistory", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestBudgetReport_WriteCSV_EscapesFormulas(t *testing.T) {
+	categoryID := uuid.New()
+	report := &BudgetReport{
+		Periods: []ReportPeriod{{Label: "2024-01"}},
+		Categories: []BudgetReportRow{{
+			CategoryID:   categoryID,
+			CategoryName: "=HYPERLINK(\"http://example.com\")",
+			Periods:      []BudgetReportAmounts{{AllocatedAmount: 100, ActualAmount: 150, Variance: -50, VariancePercent: -50}},
+		}},
+	}
+
+	var buf bytes.Buffer
+	require.NoError(t, report.WriteCSV(&buf))
+
+	// Negative amounts stay numbers
+	assert.Contains(t, buf.String(), "budgeted,"+categoryID.String()+`,"'=HYPERLINK(""http://example.com"")",100.00,150.00,-50.00,-50.00,`)
+}
+
+func TestCSVText(t *testing.T) {
+	for _, value := range []string{"=1+1", "+1", "-1", "@SUM(A1)", "\tx", "\rx"} {
+		assert.Equal(t, "'"+value, csvText(value))
+	}
+	assert.Equal(t, "Groceries", csvText("Groceries"))
+	assert.Equal(t, "", csvText(""))
+}
+
+func TestPeriodLabel(t *testing.T) {
+	assert.Equal(t, "2024-05", periodLabel(PeriodTypeMonthly, date(2024, 5, 1)))
+	assert.Equal(t, "2024-Q2", periodLabel(PeriodTypeQuarterly, date(2024, 4, 1)))
+	assert.Equal(t, "2024", periodLabel(PeriodTypeYearly, date(2024, 1, 1)))
+	assert.Equal(t, "2024-05-06", periodLabel(PeriodTypeCustom, date(2024, 5, 6)))
+}
@@ -2,17 +2,23 @@ package budget
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"time"
 
 	"github.com/google/uuid"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
+// ErrBudgetNotFound is returned when a budget does not exist
+var ErrBudgetNotFound = errors.New("budget not found")
+
 // Repository defines the interface for budget data access
 type Repository interface {
 	// Budget operations
 	Create(ctx context.Context, budget *Budget) error
+	CreateWithCategories(ctx context.Context, budget *Budget, categories []BudgetCategory) error
 	GetByID(ctx context.Context, id uuid.UUID) (*Budget, error)
 	GetByUserID(ctx context.Context, userID uuid.UUID, offset, limit int) ([]Budget, error)
 	Update(ctx context.Context, budget *Budget) error
@@ -28,7 +34,50 @@ type Repository interface {
 	// Budget analysis operations
 	GetBudgetSummary(ctx context.Context, budgetID uuid.UUID) (*BudgetSummary, error)
 	UpdateSpentAmount(ctx context.Context, budgetID, categoryID uuid.UUID, amount float64) error
+	UpdateRolloverAmount(ctx context.Context, budgetID, categoryID uuid.UUID, amount float64) error
 	GetActiveBudgetsByUser(ctx context.Context, userID uuid.UUID) ([]Budget, error)
+
+	// Budget period operations
+	GetPeriodsByBudgetID(ctx context.Context, budgetID uuid.UUID) ([]BudgetPeriod, error)
+	SavePeriod(ctx context.Context, period *BudgetPeriod) error
+	DeletePeriods(ctx context.Context, budgetID uuid.UUID) error
+
+	// Budget template operations
+	CreateTemplate(ctx context.Context, template *BudgetTemplate) error
+	GetTemplateByID(ctx context.Context, id uuid.UUID) (*BudgetTemplate, error)
+	GetTemplatesByUserID(ctx context.Context, userID uuid.UUID) ([]BudgetTemplate, error)
+	DeleteTemplate(ctx context.Context, id uuid.UUID) error
+
+	// Envelope operations
+	TransferEnvelopeFunds(ctx context.Context, transfer *EnvelopeTransfer, check func(repo Repository) error) error
+	GetEnvelopeTransfers(ctx context.Context, budgetID uuid.UUID, offset, limit int) ([]EnvelopeTransfer, error)
+
+	// Alert operations
+	RecordAlertEvent(ctx context.Context, event *BudgetAlertEvent) (bool, error)
+
+	// Goal operations
+	CreateGoal(ctx context.Context, goal *Goal) error
+	GetGoalByID(ctx context.Context, id uuid.UUID) (*Goal, error)
+	GetGoalsByUserID(ctx context.Context, userID uuid.UUID) ([]Goal, error)
+	UpdateGoal(ctx context.Context, goal *Goal) error
+	DeleteGoal(ctx context.Context, id uuid.UUID) error
+
+	// Family operations
+	GetFamilyMember(ctx context.Context, familyID, userID uuid.UUID) (*FamilyMember, error)
+	GetFamilyMembers(ctx context.Context, familyID uuid.UUID) ([]FamilyMember, error)
+
+	// Account operations
+	GetAccountBalances(ctx context.Context, userID uuid.UUID, accountIDs []uuid.UUID) (map[uuid.UUID]float64, error)
+
+	// Category operations
+	GetCategoryHierarchy(ctx context.Context, categoryIDs []uuid.UUID) ([]Category, error)
+
+	// Transaction operations
+	GetSpendingByCategory(ctx context.Context, userIDs []uuid.UUID, startDate time.Time, endDate *time.Time) (map[uuid.UUID]float64, error)
+	GetSpendingByMember(ctx context.Context, userIDs []uuid.UUID, startDate time.Time, endDate *time.Time) (map[uuid.UUID]map[uuid.UUID]float64, error)
+	GetIncome(ctx context.Context, userIDs []uuid.UUID, startDate time.Time, endDate *time.Time) (float64, error)
+	GetExpenseHistory(ctx context.Context, userIDs []uuid.UUID, startDate, endDate time.Time) ([]ExpenseRecord, error)
+	GetGoalTransactions(ctx context.Context, userID uuid.UUID, accountIDs []uuid.UUID, tag string, startDate time.Time) ([]GoalTransaction, error)
 }
 
 // repository implements the Repository interface
@@ -53,24 +102,45 @@ func (r *repository) Create(ctx context.Context, budget *Budget) error {
 	return r.db.WithContext(ctx).Create(budget).Error
 }
 
+// CreateWithCategories creates a budget together with its categories
+func (r *repository) CreateWithCategories(ctx context.Context, budget *Budget, categories []BudgetCategory) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		txRepo := &repository{db: tx}
+		if err := txRepo.Create(ctx, budget); err != nil {
+			return fmt.Errorf("failed to create budget: %w", err)
+		}
+
+		for i := range categories {
+			categories[i].BudgetID = budget.ID
+			if err := txRepo.CreateCategory(ctx, &categories[i]); err != nil {
+				return fmt.Errorf("failed to create budget category: %w", err)
+			}
+		}
+		return nil
+	})
+}
+
 // GetByID retrieves a budget by ID
 func (r *repository) GetByID(ctx context.Context, id uuid.UUID) (*Budget, error) {
 	var budget Budget
 	err := r.db.WithContext(ctx).Where("id = ?", id).First(&budget).Error
 	if err != nil {
 		if err == gorm.ErrRecordNotFound {
-			return nil, fmt.Errorf("budget not found: %w", err)
+			return nil, fmt.Errorf("%w: %w", ErrBudgetNotFound, err)
 		}
 		return nil, fmt.Errorf("failed to get budget: %w", err)
 	}
 	return &budget, nil
 }
 
-// GetByUserID retrieves budgets for a user with pagination
+// GetByUserID retrieves budgets for a user with pagination, including the budgets of the
+// families the user belongs to
 func (r *repository) GetByUserID(ctx context.Context, userID uuid.UUID, offset, limit int) ([]Budget, error) {
+	families := r.db.Model(&FamilyMember{}).Select("family_id").Where("user_id = ?", userID)
+
 	var budgets []Budget
 	err := r.db.WithContext(ctx).
-		Where("user_id = ?", userID).
+		Where("user_id = ? OR family_id IN (?)", userID, families).
 		Order("created_at DESC").
 		Offset(offset).
 		Limit(limit).
@@ -197,10 +267,15 @@ func (r *repository) GetBudgetSummary(ctx context.Context, budgetID uuid.UUID) (
 		return nil, err
 	}
 
+	return newBudgetSummary(budget, categories), nil
+}
+
+// newBudgetSummary summarizes the allocations and spending of a budget's categories
+func newBudgetSummary(budget *Budget, categories []BudgetCategory) *BudgetSummary {
 	// Calculate totals
 	var totalAllocated, totalSpent float64
 	for _, category := range categories {
-		totalAllocated += category.AllocatedAmount
+		totalAllocated += category.AllocatedAmount + category.RolloverAmount
 		totalSpent += category.SpentAmount
 	}
 
@@ -211,7 +286,7 @@ func (r *repository) GetBudgetSummary(ctx context.Context, budgetID uuid.UUID) (
 	}
 
 	// Generate alerts
-	alerts := r.generateAlerts(categories)
+	alerts := generateAlerts(budget, categories)
 
 	// Convert to response types
 	budgetResponse := &BudgetResponse{
@@ -221,12 +296,14 @@ func (r *repository) GetBudgetSummary(ctx context.Context, budgetID uuid.UUID) (
 		Name:        budget.Name,
 		Description: budget.Description,
 		PeriodType:  budget.PeriodType,
+		PeriodDays:  budget.PeriodDays,
 		StartDate:   budget.StartDate,
 		EndDate:     budget.EndDate,
 		TotalAmount: budget.TotalAmount,
 		Currency:    budget.Currency,
 		IsActive:    budget.IsActive,
 		Settings:    budget.Settings,
+		AlertRules:  budget.AlertRules,
 		CreatedAt:   budget.CreatedAt,
 		UpdatedAt:   budget.UpdatedAt,
 	}
@@ -244,7 +321,7 @@ func (r *repository) GetBudgetSummary(ctx context.Context, budgetID uuid.UUID) (
 		RemainingAmount:  totalAllocated - totalSpent,
 		SpendingProgress: spendingProgress,
 		Alerts:           alerts,
-	}, nil
+	}
 }
 
 // UpdateSpentAmount updates the spent amount for a budget category
@@ -263,11 +340,28 @@ func (r *repository) UpdateSpentAmount(ctx context.Context, budgetID, categoryID
 	return nil
 }
 
-// GetActiveBudgetsByUser retrieves active budgets for a user
+// UpdateRolloverAmount updates the amount carried into the current period of a budget category
+func (r *repository) UpdateRolloverAmount(ctx context.Context, budgetID, categoryID uuid.UUID, amount float64) error {
+	result := r.db.WithContext(ctx).
+		Model(&BudgetCategory{}).
+		Where("budget_id = ? AND category_id = ?", budgetID, categoryID).
+		Update("rollover_amount", amount)
+
+	if result.Error != nil {
+		return fmt.Errorf("failed to update rollover amount: %w", result.Error)
+	}
+
+	return nil
+}
+
+// GetActiveBudgetsByUser retrieves active budgets for a user, including the budgets of the
+// families the user belongs to
 func (r *repository) GetActiveBudgetsByUser(ctx context.Context, userID uuid.UUID) ([]Budget, error) {
+	families := r.db.Model(&FamilyMember{}).Select("family_id").Where("user_id = ?", userID)
+
 	var budgets []Budget
 	err := r.db.WithContext(ctx).
-		Where("user_id = ? AND is_active = ?", userID, true).
+		Where("(user_id = ? OR family_id IN (?)) AND is_active = ?", userID, families, true).
 		Order("created_at DESC").
 		Find(&budgets).Error
 
@@ -278,48 +372,546 @@ func (r *repository) GetActiveBudgetsByUser(ctx context.Context, userID uuid.UUI
 	return budgets, nil
 }
 
-// generateAlerts generates budget alerts based on spending thresholds
-func (r *repository) generateAlerts(categories []BudgetCategory) []BudgetAlert {
-	var alerts []BudgetAlert
+// CreateTemplate creates a budget template with its categories
+func (r *repository) CreateTemplate(ctx context.Context, template *BudgetTemplate) error {
+	template.CreatedAt = time.Now()
+	template.UpdatedAt = time.Now()
 
-	for _, category := range categories {
-		if category.AllocatedAmount <= 0 {
-			continue
+	if err := r.db.WithContext(ctx).Create(template).Error; err != nil {
+		return fmt.Errorf("failed to create budget template: %w", err)
+	}
+
+	return nil
+}
+
+// GetTemplateByID retrieves a budget template with its categories
+func (r *repository) GetTemplateByID(ctx context.Context, id uuid.UUID) (*BudgetTemplate, error) {
+	var template BudgetTemplate
+	err := r.db.WithContext(ctx).
+		Preload("Categories").
+		Where("id = ?", id).
+		First(&template).Error
+
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, fmt.Errorf("budget template not found: %w", err)
+		}
+		return nil, fmt.Errorf("failed to get budget template: %w", err)
+	}
+
+	return &template, nil
+}
+
+// GetTemplatesByUserID retrieves the budget templates of a user
+func (r *repository) GetTemplatesByUserID(ctx context.Context, userID uuid.UUID) ([]BudgetTemplate, error) {
+	var templates []BudgetTemplate
+	err := r.db.WithContext(ctx).
+		Preload("Categories").
+		Where("user_id = ?", userID).
+		Order("name ASC").
+		Find(&templates).Error
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to get budget templates: %w", err)
+	}
+
+	return templates, nil
+}
+
+// DeleteTemplate deletes a budget template with its categories
+func (r *repository) DeleteTemplate(ctx context.Context, id uuid.UUID) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("template_id = ?", id).Delete(&BudgetTemplateCategory{}).Error; err != nil {
+			return fmt.Errorf("failed to delete budget template categories: %w", err)
+		}
+
+		result := tx.Delete(&BudgetTemplate{}, id)
+		if result.Error != nil {
+			return fmt.Errorf("failed to delete budget template: %w", result.Error)
+		}
+
+		if result.RowsAffected == 0 {
+			return fmt.Errorf("budget template not found")
+		}
+		return nil
+	})
+}
+
+// GetPeriodsByBudgetID retrieves the periods of a budget with their categories, oldest first
+func (r *repository) GetPeriodsByBudgetID(ctx context.Context, budgetID uuid.UUID) ([]BudgetPeriod, error) {
+	var periods []BudgetPeriod
+	err := r.db.WithContext(ctx).
+		Preload("Categories").
+		Where("budget_id = ?", budgetID).
+		Order("start_date ASC").
+		Find(&periods).Error
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to get budget periods: %w", err)
+	}
+
+	return periods, nil
+}
+
+// SavePeriod creates or updates a budget period and replaces its categories
+func (r *repository) SavePeriod(ctx context.Context, period *BudgetPeriod) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		period.UpdatedAt = time.Now()
+		if period.ID == uuid.Nil {
+			period.CreatedAt = period.UpdatedAt
+			if err := tx.Omit("Categories").Create(period).Error; err != nil {
+				return fmt.Errorf("failed to create budget period: %w", err)
+			}
+		} else if err := tx.Omit("Categories").Save(period).Error; err != nil {
+			return fmt.Errorf("failed to update budget period: %w", err)
+		}
+
+		if err := tx.Where("period_id = ?", period.ID).Delete(&BudgetPeriodCategory{}).Error; err != nil {
+			return fmt.Errorf("failed to delete budget period categories: %w", err)
 		}
 
-		spendingRatio := category.SpentAmount / category.AllocatedAmount
-
-		var alertType string
-		var message string
-
-		if spendingRatio >= 1.0 {
-			// Over budget
-			alertType = "over_budget"
-			message = fmt.Sprintf("You've exceeded your budget for this category by $%.2f",
-				category.SpentAmount-category.AllocatedAmount)
-		} else if spendingRatio >= category.AlertThreshold {
-			// Warning threshold reached
-			alertType = "warning"
-			message = fmt.Sprintf("You've used %.1f%% of your budget for this category",
-				spendingRatio*100)
-		} else if spendingRatio >= 0.9 {
-			// Critical threshold (90%)
-			alertType = "critical"
-			message = fmt.Sprintf("You're approaching your budget limit (%.1f%% used)",
-				spendingRatio*100)
+		if len(period.Categories) == 0 {
+			return nil
+		}
+		for i := range period.Categories {
+			period.Categories[i].ID = uuid.Nil
+			period.Categories[i].PeriodID = period.ID
 		}
+		if err := tx.Create(&period.Categories).Error; err != nil {
+			return fmt.Errorf("failed to create budget period categories: %w", err)
+		}
+		return nil
+	})
+}
 
-		if alertType != "" {
-			alerts = append(alerts, BudgetAlert{
-				CategoryID:      category.CategoryID,
-				AllocatedAmount: category.AllocatedAmount,
-				SpentAmount:     category.SpentAmount,
-				Threshold:       category.AlertThreshold,
-				AlertType:       alertType,
-				Message:         message,
-			})
+// DeletePeriods deletes every period of a budget
+func (r *repository) DeletePeriods(ctx context.Context, budgetID uuid.UUID) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		periodIDs := tx.Model(&BudgetPeriod{}).Select("id").Where("budget_id = ?", budgetID)
+		if err := tx.Where("period_id IN (?)", periodIDs).Delete(&BudgetPeriodCategory{}).Error; err != nil {
+			return fmt.Errorf("failed to delete budget period categories: %w", err)
 		}
+		if err := tx.Where("budget_id = ?", budgetID).Delete(&BudgetPeriod{}).Error; err != nil {
+			return fmt.Errorf("failed to delete budget periods: %w", err)
+		}
+		return nil
+	})
+}
+
+// GetCategoryHierarchy retrieves the given categories together with all of their ancestors
+func (r *repository) GetCategoryHierarchy(ctx context.Context, categoryIDs []uuid.UUID) ([]Category, error) {
+	if len(categoryIDs) == 0 {
+		return nil, nil
 	}
 
-	return alerts
+	// UNION (rather than UNION ALL) stops the recursion if the data contains a cycle
+	var categories []Category
+	err := r.db.WithContext(ctx).Raw(`
+		WITH RECURSIVE hierarchy AS (
+			SELECT id, user_id, family_id, name, parent_id FROM categories WHERE id IN ?
+			UNION
+			SELECT c.id, c.user_id, c.family_id, c.name, c.parent_id FROM categories c JOIN hierarchy h ON c.id = h.parent_id
+		)
+		SELECT id, user_id, family_id, name, parent_id FROM hierarchy`, categoryIDs).
+		Scan(&categories).Error
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to get category hierarchy: %w", err)
+	}
+
+	return categories, nil
+}
+
+// GetSpendingByCategory sums the expenses of users per category between the start date and
+// the end of the end date. Cancelled transactions and transfers are left out
+func (r *repository) GetSpendingByCategory(ctx context.Context, userIDs []uuid.UUID, startDate time.Time, endDate *time.Time) (map[uuid.UUID]float64, error) {
+	query := r.db.WithContext(ctx).
+		Table("transactions").
+		Select("category_id, SUM(ABS(amount)) AS amount").
+		Where("user_id IN ? AND category_id IS NOT NULL AND amount < 0 AND NOT is_transfer AND status <> ?", userIDs, "cancelled").
+		Where("transaction_date >= ?", startDate)
+	if endDate != nil {
+		query = query.Where("transaction_date < ?", endDate.AddDate(0, 0, 1))
+	}
+
+	var rows []struct {
+		CategoryID uuid.UUID
+		Amount     float64
+	}
+	if err := query.Group("category_id").Scan(&rows).Error; err != nil {
+		return nil, fmt.Errorf("failed to get spending by category: %w", err)
+	}
+
+	spending := make(map[uuid.UUID]float64, len(rows))
+	for _, row := range rows {
+		spending[row.CategoryID] = row.Amount
+	}
+	return spending, nil
+}
+
+// GetSpendingByMember sums the expenses of users per user and category between the start
+// date and the end of the end date. Cancelled transactions and transfers are left out
+func (r *repository) GetSpendingByMember(ctx context.Context, userIDs []uuid.UUID, startDate time.Time, endDate *time.Time) (map[uuid.UUID]map[uuid.UUID]float64, error) {
+	query := r.db.WithContext(ctx).
+		Table("transactions").
+		Select("user_id, category_id, SUM(ABS(amount)) AS amount").
+		Where("user_id IN ? AND category_id IS NOT NULL AND amount < 0 AND NOT is_transfer AND status <> ?", userIDs, "cancelled").
+		Where("transaction_date >= ?", startDate)
+	if endDate != nil {
+		query = query.Where("transaction_date < ?", endDate.AddDate(0, 0, 1))
+	}
+
+	var rows []struct {
+		UserID     uuid.UUID
+		CategoryID uuid.UUID
+		Amount     float64
+	}
+	if err := query.Group("user_id, category_id").Scan(&rows).Error; err != nil {
+		return nil, fmt.Errorf("failed to get spending by member: %w", err)
+	}
+
+	spending := make(map[uuid.UUID]map[uuid.UUID]float64)
+	for _, row := range rows {
+		if spending[row.UserID] == nil {
+			spending[row.UserID] = make(map[uuid.UUID]float64)
+		}
+		spending[row.UserID][row.CategoryID] = row.Amount
+	}
+	return spending, nil
+}
+
+// GetIncome sums the income of users between the start date and the end of the end date.
+// Cancelled transactions and transfers are left out
+func (r *repository) GetIncome(ctx context.Context, userIDs []uuid.UUID, startDate time.Time, endDate *time.Time) (float64, error) {
+	query := r.db.WithContext(ctx).
+		Table("transactions").
+		Select("COALESCE(SUM(amount), 0)").
+		Where("user_id IN ? AND amount > 0 AND NOT is_transfer AND status <> ?", userIDs, "cancelled").
+		Where("transaction_date >= ?", startDate)
+	if endDate != nil {
+		query = query.Where("transaction_date < ?", endDate.AddDate(0, 0, 1))
+	}
+
+	var income float64
+	if err := query.Scan(&income).Error; err != nil {
+		return 0, fmt.Errorf("failed to get income: %w", err)
+	}
+
+	return income, nil
+}
+
+// GetExpenseHistory retrieves the categorized expenses of users between the start date and
+// the end of the end date, oldest first. Transfers are not expenses
+func (r *repository) GetExpenseHistory(ctx context.Context, userIDs []uuid.UUID, startDate, endDate time.Time) ([]ExpenseRecord, error) {
+	var records []ExpenseRecord
+	err := r.db.WithContext(ctx).
+		Table("transactions").
+		Select("category_id, ABS(amount) AS amount, transaction_date, COALESCE(CAST(merchant_id AS TEXT), LOWER(description)) AS payee").
+		Where("user_id IN ? AND category_id IS NOT NULL AND amount < 0 AND NOT is_transfer AND status <> ?", userIDs, "cancelled").
+		Where("transaction_date >= ? AND transaction_date < ?", startDate, endDate.AddDate(0, 0, 1)).
+		Order("transaction_date ASC").
+		Scan(&records).Error
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to get expense history: %w", err)
+	}
+
+	return records, nil
+}
+
+// TransferEnvelopeFunds moves money between the allocations of two budget categories and
+// records the transfer. A nil category is the money still to be assigned. The budget is
+// locked and the transfer is only made if check, which reads through the locked
+// transaction, passes
+func (r *repository) TransferEnvelopeFunds(ctx context.Context, transfer *EnvelopeTransfer, check func(repo Repository) error) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var budget Budget
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Select("id").First(&budget, "id = ?", transfer.BudgetID).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrBudgetNotFound
+		}
+		if err != nil {
+			return fmt.Errorf("failed to lock budget: %w", err)
+		}
+
+		if err := check(&repository{db: tx}); err != nil {
+			return err
+		}
+
+		if transfer.FromCategoryID != nil {
+			if err := adjustAllocation(tx, transfer.BudgetID, *transfer.FromCategoryID, -transfer.Amount); err != nil {
+				return err
+			}
+		}
+		if transfer.ToCategoryID != nil {
+			if err := adjustAllocation(tx, transfer.BudgetID, *transfer.ToCategoryID, transfer.Amount); err != nil {
+				return err
+			}
+		}
+
+		transfer.CreatedAt = time.Now()
+		if err := tx.Create(transfer).Error; err != nil {
+			return fmt.Errorf("failed to create envelope transfer: %w", err)
+		}
+		return nil
+	})
+}
+
+// adjustAllocation adds an amount to the allocation of a budget category
+func adjustAllocation(tx *gorm.DB, budgetID, categoryID uuid.UUID, amount float64) error {
+	result := tx.Model(&BudgetCategory{}).
+		Where("budget_id = ? AND category_id = ?", budgetID, categoryID).
+		Updates(map[string]interface{}{
+			"allocated_amount": gorm.Expr("allocated_amount + ?", amount),
+			"updated_at":       time.Now(),
+		})
+	if result.Error != nil {
+		return fmt.Errorf("failed to update allocation: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return fmt.Errorf("budget category not found")
+	}
+	return nil
+}
+
+// GetEnvelopeTransfers retrieves the envelope transfers of a budget, newest first
+func (r *repository) GetEnvelopeTransfers(ctx context.Context, budgetID uuid.UUID, offset, limit int) ([]EnvelopeTransfer, error) {
+	var transfers []EnvelopeTransfer
+	err := r.db.WithContext(ctx).
+		Where("budget_id = ?", budgetID).
+		Order("created_at DESC").
+		Offset(offset).
+		Limit(limit).
+		Find(&transfers).Error
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to get envelope transfers: %w", err)
+	}
+
+	return transfers, nil
+}
+
+// RecordAlertEvent records that an alert fired in a period. It reports false when the
+// alert has already fired in that period
+func (r *repository) RecordAlertEvent(ctx context.Context, event *BudgetAlertEvent) (bool, error) {
+	result := r.db.WithContext(ctx).
+		Clauses(clause.OnConflict{DoNothing: true}).
+		Create(event)
+	if result.Error != nil {
+		return false, fmt.Errorf("failed to record alert event: %w", result.Error)
+	}
+	return result.RowsAffected > 0, nil
+}
+
+// CreateGoal creates a goal with its linked accounts
+func (r *repository) CreateGoal(ctx context.Context, goal *Goal) error {
+	goal.CreatedAt = time.Now()
+	goal.UpdatedAt = time.Now()
+
+	if goal.Currency == "" {
+		goal.Currency = "USD"
+	}
+
+	if err := r.db.WithContext(ctx).Create(goal).Error; err != nil {
+		return fmt.Errorf("failed to create goal: %w", err)
+	}
+
+	return nil
+}
+
+// GetGoalByID retrieves a goal with its linked accounts
+func (r *repository) GetGoalByID(ctx context.Context, id uuid.UUID) (*Goal, error) {
+	var goal Goal
+	err := r.db.WithContext(ctx).
+		Preload("Accounts").
+		Where("id = ?", id).
+		First(&goal).Error
+
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, fmt.Errorf("goal not found: %w", err)
+		}
+		return nil, fmt.Errorf("failed to get goal: %w", err)
+	}
+
+	return &goal, nil
+}
+
+// GetGoalsByUserID retrieves the goals of a user with their linked accounts
+func (r *repository) GetGoalsByUserID(ctx context.Context, userID uuid.UUID) ([]Goal, error) {
+	var goals []Goal
+	err := r.db.WithContext(ctx).
+		Preload("Accounts").
+		Where("user_id = ?", userID).
+		Order("created_at DESC").
+		Find(&goals).Error
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to get goals: %w", err)
+	}
+
+	return goals, nil
+}
+
+// UpdateGoal updates a goal and replaces its linked accounts
+func (r *repository) UpdateGoal(ctx context.Context, goal *Goal) error {
+	goal.UpdatedAt = time.Now()
+
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		result := tx.Omit("Accounts").Save(goal)
+		if result.Error != nil {
+			return fmt.Errorf("failed to update goal: %w", result.Error)
+		}
+
+		if result.RowsAffected == 0 {
+			return fmt.Errorf("goal not found")
+		}
+
+		if err := tx.Where("goal_id = ?", goal.ID).Delete(&GoalAccount{}).Error; err != nil {
+			return fmt.Errorf("failed to update goal accounts: %w", err)
+		}
+		if len(goal.Accounts) > 0 {
+			if err := tx.Create(&goal.Accounts).Error; err != nil {
+				return fmt.Errorf("failed to update goal accounts: %w", err)
+			}
+		}
+		return nil
+	})
+}
+
+// DeleteGoal deletes a goal with its linked accounts
+func (r *repository) DeleteGoal(ctx context.Context, id uuid.UUID) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("goal_id = ?", id).Delete(&GoalAccount{}).Error; err != nil {
+			return fmt.Errorf("failed to delete goal accounts: %w", err)
+		}
+
+		result := tx.Delete(&Goal{}, id)
+		if result.Error != nil {
+			return fmt.Errorf("failed to delete goal: %w", result.Error)
+		}
+
+		if result.RowsAffected == 0 {
+			return fmt.Errorf("goal not found")
+		}
+		return nil
+	})
+}
+
+// GetFamilyMember retrieves the membership of a user in a family. It returns nil when the
+// user is not a member
+func (r *repository) GetFamilyMember(ctx context.Context, familyID, userID uuid.UUID) (*FamilyMember, error) {
+	var members []FamilyMember
+	err := r.db.WithContext(ctx).
+		Where("family_id = ? AND user_id = ?", familyID, userID).
+		Limit(1).
+		Find(&members).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to get family member: %w", err)
+	}
+	if len(members) == 0 {
+		return nil, nil
+	}
+	return &members[0], nil
+}
+
+// GetFamilyMembers retrieves the members of a family with their names, oldest members first
+func (r *repository) GetFamilyMembers(ctx context.Context, familyID uuid.UUID) ([]FamilyMember, error) {
+	var members []FamilyMember
+	err := r.db.WithContext(ctx).
+		Table("family_members").
+		Select("family_members.id, family_members.family_id, family_members.user_id, family_members.role, TRIM(CONCAT(users.first_name, ' ', users.last_name)) AS name").
+		Joins("JOIN users ON users.id = family_members.user_id").
+		Where("family_members.family_id = ?", familyID).
+		Order("family_members.created_at ASC").
+		Scan(&members).Error
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to get family members: %w", err)
+	}
+
+	return members, nil
+}
+
+// GetAccountBalances retrieves the balances of a user's accounts. Accounts of other users
+// are left out
+func (r *repository) GetAccountBalances(ctx context.Context, userID uuid.UUID, accountIDs []uuid.UUID) (map[uuid.UUID]float64, error) {
+	balances := make(map[uuid.UUID]float64, len(accountIDs))
+	if len(accountIDs) == 0 {
+		return balances, nil
+	}
+
+	var rows []struct {
+		ID      uuid.UUID
+		Balance float64
+	}
+	err := r.db.WithContext(ctx).
+		Table("accounts").
+		Select("id, balance").
+		Where("user_id = ? AND id IN ?", userID, accountIDs).
+		Scan(&rows).Error
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to get account balances: %w", err)
+	}
+
+	for _, row := range rows {
+		balances[row.ID] = row.Balance
+	}
+	return balances, nil
+}
+
+// GetGoalTransactions retrieves a user's transactions since the start date that are on one
+// of the accounts or tagged with the tag, oldest first. Cancelled transactions are left out
+func (r *repository) GetGoalTransactions(ctx context.Context, userID uuid.UUID, accountIDs []uuid.UUID, tag string, startDate time.Time) ([]GoalTransaction, error) {
+	query := r.db.WithContext(ctx).
+		Table("transactions").
+		Select("id, account_id, amount, transaction_date").
+		Where("user_id = ? AND status <> ? AND transaction_date >= ?", userID, "cancelled", startDate)
+
+	switch {
+	case len(accountIDs) > 0 && tag != "":
+		query = query.Where("account_id IN ? OR ? = ANY(tags)", accountIDs, tag)
+	case len(accountIDs) > 0:
+		query = query.Where("account_id IN ?", accountIDs)
+	case tag != "":
+		query = query.Where("? = ANY(tags)", tag)
+	default:
+		return nil, nil
+	}
+
+	var transactions []GoalTransaction
+	if err := query.Order("transaction_date ASC").Scan(&transactions).Error; err != nil {
+		return nil, fmt.Errorf("failed to get goal transactions: %w", err)
+	}
+
+	return transactions, nil
+}
+
+// FamilyMember represents a user's membership of a family (imported from user domain)
+type FamilyMember struct {
+	ID       uuid.UUID  `json:"id" gorm:"type:uuid;primary_key"`
+	FamilyID uuid.UUID  `json:"family_id" gorm:"type:uuid"`
+	UserID   uuid.UUID  `json:"user_id" gorm:"type:uuid"`
+	Role     FamilyRole `json:"role"`
+	Name     string     `json:"name" gorm:"->"` // Full name of the user
+}
+
+// TableName specifies the table name for FamilyMember
+func (FamilyMember) TableName() string {
+	return "family_members"
+}
+
+// Category represents a category (imported from transaction domain)
+type Category struct {
+	ID       uuid.UUID  `json:"id" gorm:"type:uuid;primary_key"`
+	UserID   *uuid.UUID `json:"user_id" gorm:"type:uuid"`   // Set on a user's own categories
+	FamilyID *uuid.UUID `json:"family_id" gorm:"type:uuid"` // Set on categories shared within a family
+	Name     string     `json:"name"`
+	ParentID *uuid.UUID `json:"parent_id" gorm:"type:uuid"`
+}
+
+// TableName specifies the table name for Category
+func (Category) TableName() string {
+	return "categories"
 }
@@ -2,7 +2,10 @@ package budget
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"math"
+	"time"
 
 	"github.com/google/uuid"
 	"go.opentelemetry.io/otel"
@@ -28,8 +31,35 @@ type Service interface {
 	DeleteBudgetCategory(ctx context.Context, userID, budgetID, categoryID uuid.UUID) error
 
 	// Budget analysis
-	GetBudgetSummary(ctx context.Context, userID, budgetID uuid.UUID) (*BudgetSummary, error)
+	GetBudgetSummary(ctx context.Context, userID, budgetID uuid.UUID, level *int) (*BudgetSummary, error)
+	RecalculateBudget(ctx context.Context, userID, budgetID uuid.UUID) (*BudgetSummary, error)
+	GetBudgetPeriods(ctx context.Context, userID, budgetID uuid.UUID) ([]BudgetPeriod, error)
+	GetBudgetForecast(ctx context.Context, userID, budgetID uuid.UUID) (*BudgetForecast, error)
+	GetBudgetReport(ctx context.Context, userID, budgetID uuid.UUID, year int) (*BudgetReport, error)
+	EvaluateAlerts(ctx context.Context, userID uuid.UUID) ([]TriggeredAlert, error)
+
+	// Budget templates
+	SaveAsTemplate(ctx context.Context, userID, budgetID uuid.UUID, req *CreateTemplateRequest) (*BudgetTemplate, error)
+	ListTemplates(ctx context.Context, userID uuid.UUID) ([]BudgetTemplate, error)
+	DeleteTemplate(ctx context.Context, userID, templateID uuid.UUID) error
+	CreateBudgetFromTemplate(ctx context.Context, userID, templateID uuid.UUID, req *CopyBudgetRequest) (*BudgetResponse, error)
+	CopyBudget(ctx context.Context, userID, budgetID uuid.UUID, req *CopyBudgetRequest) (*BudgetResponse, error)
+	SuggestBudget(ctx context.Context, userID uuid.UUID, months int) (*BudgetSuggestion, error)
+
+	// Envelope budgeting
+	GetEnvelopes(ctx context.Context, userID, budgetID uuid.UUID) (*EnvelopeSummary, error)
+	AssignToEnvelope(ctx context.Context, userID, budgetID uuid.UUID, req *AssignEnvelopeRequest) (*EnvelopeSummary, error)
+	MoveBetweenEnvelopes(ctx context.Context, userID, budgetID uuid.UUID, req *MoveEnvelopeRequest) (*EnvelopeSummary, error)
+	CoverOverspending(ctx context.Context, userID, budgetID, categoryID uuid.UUID, req *CoverOverspendingRequest) (*EnvelopeSummary, error)
+	GetEnvelopeTransfers(ctx context.Context, userID, budgetID uuid.UUID, offset, limit int) ([]EnvelopeTransfer, error)
 	UpdateBudgetFromTransaction(ctx context.Context, userID, budgetID, categoryID uuid.UUID, amount float64) error
+
+	// Savings goals
+	CreateGoal(ctx context.Context, userID uuid.UUID, req *CreateGoalRequest) (*GoalResponse, error)
+	GetGoal(ctx context.Context, userID, goalID uuid.UUID) (*GoalResponse, error)
+	ListGoals(ctx context.Context, userID uuid.UUID) ([]GoalResponse, error)
+	UpdateGoal(ctx context.Context, userID, goalID uuid.UUID, req *UpdateGoalRequest) (*GoalResponse, error)
+	DeleteGoal(ctx context.Context, userID, goalID uuid.UUID) error
 }
 
 // service implements the Service interface
@@ -63,17 +93,27 @@ func (s *service) CreateBudget(ctx context.Context, userID uuid.UUID, req *Creat
 	// Create budget
 	budget := &Budget{
 		UserID:      userID,
+		FamilyID:    req.FamilyID,
 		Name:        req.Name,
 		Description: req.Description,
 		PeriodType:  req.PeriodType,
+		PeriodDays:  req.PeriodDays,
 		StartDate:   req.StartDate,
 		EndDate:     req.EndDate,
 		TotalAmount: req.TotalAmount,
 		Currency:    req.Currency,
 		Settings:    req.Settings,
+		AlertRules:  req.AlertRules,
 		IsActive:    true,
 	}
 
+	// Only family owners can share budgets with their family
+	if err := s.authorizeBudget(ctx, userID, budget, budgetAccessEdit); err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, err
+	}
+
 	if err := s.repo.Create(ctx, budget); err != nil {
 		span.RecordError(err)
 		span.SetStatus(codes.Error, err.Error())
@@ -101,10 +141,11 @@ func (s *service) GetBudget(ctx context.Context, userID, budgetID uuid.UUID) (*B
 		return nil, err
 	}
 
-	// Check ownership
-	if budget.UserID != userID {
-		span.SetStatus(codes.Error, "unauthorized access to budget")
-		return nil, fmt.Errorf("unauthorized access to budget")
+	// Check access
+	if err := s.authorizeBudget(ctx, userID, budget, budgetAccessView); err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, err
 	}
 
 	return s.toBudgetResponse(budget), nil
@@ -155,12 +196,19 @@ func (s *service) UpdateBudget(ctx context.Context, userID, budgetID uuid.UUID,
 		return nil, err
 	}
 
-	// Check ownership
-	if budget.UserID != userID {
-		span.SetStatus(codes.Error, "unauthorized access to budget")
-		return nil, fmt.Errorf("unauthorized access to budget")
+	// Check access
+	if err := s.authorizeBudget(ctx, userID, budget, budgetAccessEdit); err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, err
 	}
 
+	// Periods are generated from the schedule, so they are rebuilt when it changes
+	scheduleChanged := (req.PeriodType != nil && *req.PeriodType != budget.PeriodType) ||
+		(req.PeriodDays != nil && *req.PeriodDays != budget.PeriodDays) ||
+		(req.StartDate != nil && !req.StartDate.Equal(budget.StartDate)) ||
+		(req.EndDate != nil && (budget.EndDate == nil || !req.EndDate.Equal(*budget.EndDate)))
+
 	// Update fields
 	if req.Name != nil {
 		budget.Name = *req.Name
@@ -171,6 +219,9 @@ func (s *service) UpdateBudget(ctx context.Context, userID, budgetID uuid.UUID,
 	if req.PeriodType != nil {
 		budget.PeriodType = *req.PeriodType
 	}
+	if req.PeriodDays != nil {
+		budget.PeriodDays = *req.PeriodDays
+	}
 	if req.StartDate != nil {
 		budget.StartDate = *req.StartDate
 	}
@@ -189,6 +240,12 @@ func (s *service) UpdateBudget(ctx context.Context, userID, budgetID uuid.UUID,
 	if req.Settings != nil {
 		budget.Settings = *req.Settings
 	}
+	if req.AlertRules != nil {
+		budget.AlertRules = req.AlertRules
+		if len(budget.AlertRules) == 0 {
+			budget.AlertRules = nil
+		}
+	}
 
 	// Validate updated budget
 	if err := s.validateBudget(budget); err != nil {
@@ -203,6 +260,14 @@ func (s *service) UpdateBudget(ctx context.Context, userID, budgetID uuid.UUID,
 		return nil, err
 	}
 
+	if scheduleChanged {
+		if err := s.repo.DeletePeriods(ctx, budgetID); err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+			return nil, err
+		}
+	}
+
 	return s.toBudgetResponse(budget), nil
 }
 
@@ -216,7 +281,7 @@ func (s *service) DeleteBudget(ctx context.Context, userID, budgetID uuid.UUID)
 	)
 	defer span.End()
 
-	// Get existing budget to check ownership
+	// Get existing budget to check access
 	budget, err := s.repo.GetByID(ctx, budgetID)
 	if err != nil {
 		span.RecordError(err)
@@ -224,10 +289,11 @@ func (s *service) DeleteBudget(ctx context.Context, userID, budgetID uuid.UUID)
 		return err
 	}
 
-	// Check ownership
-	if budget.UserID != userID {
-		span.SetStatus(codes.Error, "unauthorized access to budget")
-		return fmt.Errorf("unauthorized access to budget")
+	// Check access
+	if err := s.authorizeBudget(ctx, userID, budget, budgetAccessEdit); err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return err
 	}
 
 	if err := s.repo.Delete(ctx, budgetID); err != nil {
@@ -250,7 +316,12 @@ func (s *service) AddBudgetCategory(ctx context.Context, userID, budgetID uuid.U
 	)
 	defer span.End()
 
-	// Verify budget ownership
+	if err := validateAlertRules(req.AlertRules); err != nil {
+		span.SetStatus(codes.Error, err.Error())
+		return nil, err
+	}
+
+	// Verify budget access
 	budget, err := s.repo.GetByID(ctx, budgetID)
 	if err != nil {
 		span.RecordError(err)
@@ -258,9 +329,25 @@ func (s *service) AddBudgetCategory(ctx context.Context, userID, budgetID uuid.U
 		return nil, err
 	}
 
-	if budget.UserID != userID {
-		span.SetStatus(codes.Error, "unauthorized access to budget")
-		return nil, fmt.Errorf("unauthorized access to budget")
+	if err := s.authorizeBudget(ctx, userID, budget, budgetAccessEdit); err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, err
+	}
+
+	// Envelopes start empty and are filled by assigning money that is still to be assigned
+	envelope := isEnvelopeBudget(budget)
+	if envelope {
+		summary, err := s.envelopeSummary(ctx, budget)
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+			return nil, err
+		}
+		if req.AllocatedAmount < 0 || roundAmount(req.AllocatedAmount) > summary.ToBeAssigned {
+			span.SetStatus(codes.Error, ErrInsufficientFunds.Error())
+			return nil, ErrInsufficientFunds
+		}
 	}
 
 	// Create budget category
@@ -268,9 +355,15 @@ func (s *service) AddBudgetCategory(ctx context.Context, userID, budgetID uuid.U
 		BudgetID:        budgetID,
 		CategoryID:      req.CategoryID,
 		AllocatedAmount: req.AllocatedAmount,
+		Rollover:        req.Rollover,
 		AlertThreshold:  req.AlertThreshold,
+		AlertRules:      req.AlertRules,
 		IsActive:        true,
 	}
+	if envelope {
+		budgetCategory.AllocatedAmount = 0
+		budgetCategory.Rollover = true
+	}
 
 	if err := s.repo.CreateCategory(ctx, budgetCategory); err != nil {
 		span.RecordError(err)
@@ -278,6 +371,38 @@ func (s *service) AddBudgetCategory(ctx context.Context, userID, budgetID uuid.U
 		return nil, err
 	}
 
+	if envelope && req.AllocatedAmount > 0 {
+		transfer := &EnvelopeTransfer{
+			BudgetID:     budgetID,
+			UserID:       userID,
+			ToCategoryID: &req.CategoryID,
+			Amount:       roundAmount(req.AllocatedAmount),
+		}
+		// The money may have been assigned elsewhere since it was checked above, in which
+		// case the envelope is not added
+		err := s.repo.TransferEnvelopeFunds(ctx, transfer, func(repo Repository) error {
+			summary, err := (&service{repo: repo}).envelopeSummary(ctx, budget)
+			if err != nil {
+				return err
+			}
+			if transfer.Amount > summary.ToBeAssigned {
+				return ErrInsufficientFunds
+			}
+			return nil
+		})
+		if err != nil {
+			if errors.Is(err, ErrInsufficientFunds) {
+				if deleteErr := s.repo.DeleteCategory(ctx, budgetCategory.ID); deleteErr != nil {
+					err = errors.Join(err, deleteErr)
+				}
+			}
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+			return nil, err
+		}
+		budgetCategory.AllocatedAmount = transfer.Amount
+	}
+
 	span.SetAttributes(attribute.String("budget_category_id", budgetCategory.ID.String()))
 	return s.toBudgetCategoryResponse(budgetCategory), nil
 }
@@ -293,7 +418,7 @@ func (s *service) GetBudgetCategory(ctx context.Context, userID, budgetID, categ
 	)
 	defer span.End()
 
-	// Verify budget ownership
+	// Verify budget access
 	budget, err := s.repo.GetByID(ctx, budgetID)
 	if err != nil {
 		span.RecordError(err)
@@ -301,9 +426,10 @@ func (s *service) GetBudgetCategory(ctx context.Context, userID, budgetID, categ
 		return nil, err
 	}
 
-	if budget.UserID != userID {
-		span.SetStatus(codes.Error, "unauthorized access to budget")
-		return nil, fmt.Errorf("unauthorized access to budget")
+	if err := s.authorizeBudget(ctx, userID, budget, budgetAccessView); err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, err
 	}
 
 	budgetCategory, err := s.repo.GetCategoryByID(ctx, categoryID)
@@ -332,7 +458,7 @@ func (s *service) ListBudgetCategories(ctx context.Context, userID, budgetID uui
 	)
 	defer span.End()
 
-	// Verify budget ownership
+	// Verify budget access
 	budget, err := s.repo.GetByID(ctx, budgetID)
 	if err != nil {
 		span.RecordError(err)
@@ -340,9 +466,10 @@ func (s *service) ListBudgetCategories(ctx context.Context, userID, budgetID uui
 		return nil, err
 	}
 
-	if budget.UserID != userID {
-		span.SetStatus(codes.Error, "unauthorized access to budget")
-		return nil, fmt.Errorf("unauthorized access to budget")
+	if err := s.authorizeBudget(ctx, userID, budget, budgetAccessView); err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, err
 	}
 
 	categories, err := s.repo.GetCategoriesByBudgetID(ctx, budgetID)
@@ -372,7 +499,12 @@ func (s *service) UpdateBudgetCategory(ctx context.Context, userID, budgetID, ca
 	)
 	defer span.End()
 
-	// Verify budget ownership
+	if err := validateAlertRules(req.AlertRules); err != nil {
+		span.SetStatus(codes.Error, err.Error())
+		return nil, err
+	}
+
+	// Verify budget access
 	budget, err := s.repo.GetByID(ctx, budgetID)
 	if err != nil {
 		span.RecordError(err)
@@ -380,9 +512,10 @@ func (s *service) UpdateBudgetCategory(ctx context.Context, userID, budgetID, ca
 		return nil, err
 	}
 
-	if budget.UserID != userID {
-		span.SetStatus(codes.Error, "unauthorized access to budget")
-		return nil, fmt.Errorf("unauthorized access to budget")
+	if err := s.authorizeBudget(ctx, userID, budget, budgetAccessEdit); err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, err
 	}
 
 	budgetCategory, err := s.repo.GetCategoryByID(ctx, categoryID)
@@ -398,13 +531,28 @@ func (s *service) UpdateBudgetCategory(ctx context.Context, userID, budgetID, ca
 		return nil, fmt.Errorf("category does not belong to budget")
 	}
 
+	// Envelope allocations only change through recorded transfers
+	if req.AllocatedAmount != nil && isEnvelopeBudget(budget) {
+		span.SetStatus(codes.Error, ErrEnvelopeAllocationEdit.Error())
+		return nil, ErrEnvelopeAllocationEdit
+	}
+
 	// Update fields
 	if req.AllocatedAmount != nil {
 		budgetCategory.AllocatedAmount = *req.AllocatedAmount
 	}
+	if req.Rollover != nil {
+		budgetCategory.Rollover = *req.Rollover
+	}
 	if req.AlertThreshold != nil {
 		budgetCategory.AlertThreshold = *req.AlertThreshold
 	}
+	if req.AlertRules != nil {
+		budgetCategory.AlertRules = req.AlertRules
+		if len(budgetCategory.AlertRules) == 0 {
+			budgetCategory.AlertRules = nil
+		}
+	}
 	if req.IsActive != nil {
 		budgetCategory.IsActive = *req.IsActive
 	}
@@ -429,7 +577,7 @@ func (s *service) DeleteBudgetCategory(ctx context.Context, userID, budgetID, ca
 	)
 	defer span.End()
 
-	// Verify budget ownership
+	// Verify budget access
 	budget, err := s.repo.GetByID(ctx, budgetID)
 	if err != nil {
 		span.RecordError(err)
@@ -437,9 +585,10 @@ func (s *service) DeleteBudgetCategory(ctx context.Context, userID, budgetID, ca
 		return err
 	}
 
-	if budget.UserID != userID {
-		span.SetStatus(codes.Error, "unauthorized access to budget")
-		return fmt.Errorf("unauthorized access to budget")
+	if err := s.authorizeBudget(ctx, userID, budget, budgetAccessEdit); err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return err
 	}
 
 	budgetCategory, err := s.repo.GetCategoryByID(ctx, categoryID)
@@ -464,8 +613,9 @@ func (s *service) DeleteBudgetCategory(ctx context.Context, userID, budgetID, ca
 	return nil
 }
 
-// GetBudgetSummary retrieves a comprehensive budget summary
-func (s *service) GetBudgetSummary(ctx context.Context, userID, budgetID uuid.UUID) (*BudgetSummary, error) {
+// GetBudgetSummary retrieves a comprehensive budget summary. When level is set, the
+// summary also reports totals rolled up to the categories at that depth
+func (s *service) GetBudgetSummary(ctx context.Context, userID, budgetID uuid.UUID, level *int) (*BudgetSummary, error) {
 	ctx, span := otel.Tracer("").Start(ctx, "budget.GetBudgetSummary",
 		trace.WithAttributes(
 			attribute.String("user_id", userID.String()),
@@ -474,7 +624,7 @@ func (s *service) GetBudgetSummary(ctx context.Context, userID, budgetID uuid.UU
 	)
 	defer span.End()
 
-	// Verify budget ownership
+	// Verify budget access
 	budget, err := s.repo.GetByID(ctx, budgetID)
 	if err != nil {
 		span.RecordError(err)
@@ -482,17 +632,52 @@ func (s *service) GetBudgetSummary(ctx context.Context, userID, budgetID uuid.UU
 		return nil, err
 	}
 
-	if budget.UserID != userID {
-		span.SetStatus(codes.Error, "unauthorized access to budget")
-		return nil, fmt.Errorf("unauthorized access to budget")
+	if err := s.authorizeBudget(ctx, userID, budget, budgetAccessView); err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, err
 	}
 
-	summary, err := s.repo.GetBudgetSummary(ctx, budgetID)
+	// Spent amounts are derived from the transactions of the user, or of every member of
+	// the family for family budgets. They are only stored when transactions change or the
+	// budget is recalculated, so reading a budget never writes
+	categories, err := s.currentCategories(ctx, budget)
 	if err != nil {
 		span.RecordError(err)
 		span.SetStatus(codes.Error, err.Error())
 		return nil, err
 	}
+	summary := newBudgetSummary(budget, categories)
+
+	if level != nil {
+		rollups, err := s.rollupCategories(ctx, summary.Categories, *level)
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+			return nil, err
+		}
+		summary.Rollups = rollups
+	}
+
+	if budget.FamilyID != nil {
+		members, err := s.memberSpending(ctx, budget, categories, time.Now())
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+			return nil, err
+		}
+		summary.Members = members
+	}
+
+	// Categories still within their allocation can be on track to exceed it
+	forecast, err := s.forecastBudget(ctx, budget, categories, time.Now())
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, err
+	}
+	summary.Alerts = append(summary.Alerts, forecast.Alerts...)
+	sortAlerts(summary.Alerts)
 
 	span.SetAttributes(
 		attribute.Float64("total_allocated", summary.TotalAllocated),
@@ -504,6 +689,90 @@ func (s *service) GetBudgetSummary(ctx context.Context, userID, budgetID uuid.UU
 	return summary, nil
 }
 
+// RecalculateBudget recalculates and stores the spent amounts of a budget from the user's
+// transactions
+func (s *service) RecalculateBudget(ctx context.Context, userID, budgetID uuid.UUID) (*BudgetSummary, error) {
+	ctx, span := otel.Tracer("").Start(ctx, "budget.RecalculateBudget",
+		trace.WithAttributes(
+			attribute.String("user_id", userID.String()),
+			attribute.String("budget_id", budgetID.String()),
+		),
+	)
+	defer span.End()
+
+	// Verify budget access
+	budget, err := s.repo.GetByID(ctx, budgetID)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, err
+	}
+
+	if err := s.authorizeBudget(ctx, userID, budget, budgetAccessView); err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, err
+	}
+
+	if err := s.refreshSpentAmounts(ctx, budget); err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, err
+	}
+
+	summary, err := s.repo.GetBudgetSummary(ctx, budgetID)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, err
+	}
+
+	span.SetAttributes(attribute.Float64("total_spent", summary.TotalSpent))
+	return summary, nil
+}
+
+// GetBudgetPeriods retrieves the history of a budget's periods with their allocations and actuals
+func (s *service) GetBudgetPeriods(ctx context.Context, userID, budgetID uuid.UUID) ([]BudgetPeriod, error) {
+	ctx, span := otel.Tracer("").Start(ctx, "budget.GetBudgetPeriods",
+		trace.WithAttributes(
+			attribute.String("user_id", userID.String()),
+			attribute.String("budget_id", budgetID.String()),
+		),
+	)
+	defer span.End()
+
+	// Verify budget access
+	budget, err := s.repo.GetByID(ctx, budgetID)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, err
+	}
+
+	if err := s.authorizeBudget(ctx, userID, budget, budgetAccessView); err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, err
+	}
+
+	categories, err := s.repo.GetCategoriesByBudgetID(ctx, budgetID)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, err
+	}
+
+	periods, _, err := s.calculatePeriods(ctx, budget, categories)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, err
+	}
+
+	span.SetAttributes(attribute.Int("periods_count", len(periods)))
+	return periods, nil
+}
+
 // UpdateBudgetFromTransaction updates budget spending when a transaction is created/updated
 func (s *service) UpdateBudgetFromTransaction(ctx context.Context, userID, budgetID, categoryID uuid.UUID, amount float64) error {
 	ctx, span := otel.Tracer("").Start(ctx, "budget.UpdateBudgetFromTransaction",
@@ -516,7 +785,7 @@ func (s *service) UpdateBudgetFromTransaction(ctx context.Context, userID, budge
 	)
 	defer span.End()
 
-	// Verify budget ownership
+	// Verify budget access
 	budget, err := s.repo.GetByID(ctx, budgetID)
 	if err != nil {
 		span.RecordError(err)
@@ -524,9 +793,10 @@ func (s *service) UpdateBudgetFromTransaction(ctx context.Context, userID, budge
 		return err
 	}
 
-	if budget.UserID != userID {
-		span.SetStatus(codes.Error, "unauthorized access to budget")
-		return fmt.Errorf("unauthorized access to budget")
+	if err := s.authorizeBudget(ctx, userID, budget, budgetAccessView); err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return err
 	}
 
 	// Update the spent amount for the category
@@ -554,6 +824,18 @@ func (s *service) validateCreateBudgetRequest(req *CreateBudgetRequest) error {
 	if req.EndDate != nil && req.EndDate.Before(req.StartDate) {
 		return fmt.Errorf("end date must be after start date")
 	}
+	if req.PeriodDays < 0 {
+		return fmt.Errorf("period days must not be negative")
+	}
+	if req.PeriodType == PeriodTypeCustom && req.PeriodDays == 0 && req.EndDate == nil {
+		return fmt.Errorf("custom budgets require an end date or period days")
+	}
+	if _, err := parseSettings(req.Settings); err != nil {
+		return err
+	}
+	if err := validateAlertRules(req.AlertRules); err != nil {
+		return err
+	}
 	if req.Currency == "" {
 		req.Currency = "USD"
 	}
@@ -573,6 +855,18 @@ func (s *service) validateBudget(budget *Budget) error {
 	if budget.EndDate != nil && budget.EndDate.Before(budget.StartDate) {
 		return fmt.Errorf("end date must be after start date")
 	}
+	if budget.PeriodDays < 0 {
+		return fmt.Errorf("period days must not be negative")
+	}
+	if budget.PeriodType == PeriodTypeCustom && budget.PeriodDays == 0 && budget.EndDate == nil {
+		return fmt.Errorf("custom budgets require an end date or period days")
+	}
+	if _, err := parseSettings(budget.Settings); err != nil {
+		return err
+	}
+	if err := validateAlertRules(budget.AlertRules); err != nil {
+		return err
+	}
 	return nil
 }
 
@@ -584,12 +878,14 @@ func (s *service) toBudgetResponse(budget *Budget) *BudgetResponse {
 		Name:        budget.Name,
 		Description: budget.Description,
 		PeriodType:  budget.PeriodType,
+		PeriodDays:  budget.PeriodDays,
 		StartDate:   budget.StartDate,
 		EndDate:     budget.EndDate,
 		TotalAmount: budget.TotalAmount,
 		Currency:    budget.Currency,
 		IsActive:    budget.IsActive,
 		Settings:    budget.Settings,
+		AlertRules:  budget.AlertRules,
 		CreatedAt:   budget.CreatedAt,
 		UpdatedAt:   budget.UpdatedAt,
 	}
@@ -602,9 +898,318 @@ func (s *service) toBudgetCategoryResponse(budgetCategory *BudgetCategory) *Budg
 		CategoryID:      budgetCategory.CategoryID,
 		AllocatedAmount: budgetCategory.AllocatedAmount,
 		SpentAmount:     budgetCategory.SpentAmount,
+		Rollover:        budgetCategory.Rollover,
+		RolloverAmount:  budgetCategory.RolloverAmount,
 		AlertThreshold:  budgetCategory.AlertThreshold,
+		AlertRules:      budgetCategory.AlertRules,
 		IsActive:        budgetCategory.IsActive,
 		CreatedAt:       budgetCategory.CreatedAt,
 		UpdatedAt:       budgetCategory.UpdatedAt,
 	}
 }
+
+// rollupCategories aggregates budget category totals into their ancestor at the given
+// level. Categories shallower than the level are reported as they are
+func (s *service) rollupCategories(ctx context.Context, budgetCategories []BudgetCategoryResponse, level int) ([]CategoryRollup, error) {
+	categoryIDs := make([]uuid.UUID, len(budgetCategories))
+	for i, budgetCategory := range budgetCategories {
+		categoryIDs[i] = budgetCategory.CategoryID
+	}
+
+	hierarchy, err := s.repo.GetCategoryHierarchy(ctx, categoryIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	categories := make(map[uuid.UUID]Category, len(hierarchy))
+	for _, category := range hierarchy {
+		categories[category.ID] = category
+	}
+
+	var rollups []CategoryRollup
+	indexes := make(map[uuid.UUID]int)
+	for _, budgetCategory := range budgetCategories {
+		// Build the path from the top-level category down to this one
+		path := []Category{{ID: budgetCategory.CategoryID}}
+		if category, exists := categories[budgetCategory.CategoryID]; exists {
+			path[0] = category
+		}
+		seen := map[uuid.UUID]bool{path[0].ID: true}
+		for current := path[0]; current.ParentID != nil && !seen[*current.ParentID]; {
+			parent, exists := categories[*current.ParentID]
+			if !exists {
+				break
+			}
+			seen[parent.ID] = true
+			path = append([]Category{parent}, path...)
+			current = parent
+		}
+
+		target, targetLevel := path[len(path)-1], len(path)-1
+		if level >= 0 && level < len(path) {
+			target, targetLevel = path[level], level
+		}
+
+		i, exists := indexes[target.ID]
+		if !exists {
+			i = len(rollups)
+			indexes[target.ID] = i
+			rollups = append(rollups, CategoryRollup{
+				CategoryID:   target.ID,
+				CategoryName: target.Name,
+				Level:        targetLevel,
+			})
+		}
+		rollups[i].AllocatedAmount += budgetCategory.AllocatedAmount
+		rollups[i].SpentAmount += budgetCategory.SpentAmount
+	}
+
+	for i := range rollups {
+		rollups[i].RemainingAmount = rollups[i].AllocatedAmount - rollups[i].SpentAmount
+		if rollups[i].AllocatedAmount > 0 {
+			rollups[i].SpendingProgress = (rollups[i].SpentAmount / rollups[i].AllocatedAmount) * 100
+		}
+	}
+
+	return rollups, nil
+}
+
+// currentCategories returns the categories of a budget with the spent and carried over
+// amounts of the current period, calculated from the transactions without storing them
+func (s *service) currentCategories(ctx context.Context, budget *Budget) ([]BudgetCategory, error) {
+	categories, err := s.repo.GetCategoriesByBudgetID(ctx, budget.ID)
+	if err != nil || len(categories) == 0 {
+		return categories, err
+	}
+
+	periods, _, err := s.calculatePeriods(ctx, budget, categories)
+	if err != nil || len(periods) == 0 {
+		return categories, err
+	}
+
+	current := periodCategories(periods[len(periods)-1])
+	for i := range categories {
+		period := current[categories[i].CategoryID]
+		categories[i].SpentAmount = period.SpentAmount
+		categories[i].RolloverAmount = period.RolloverAmount
+	}
+	return categories, nil
+}
+
+// refreshSpentAmounts brings the stored periods of a budget up to date and stores the spent
+// and carried over amounts of the current period on the budget categories that changed
+func (s *service) refreshSpentAmounts(ctx context.Context, budget *Budget) error {
+	categories, err := s.repo.GetCategoriesByBudgetID(ctx, budget.ID)
+	if err != nil || len(categories) == 0 {
+		return err
+	}
+
+	periods, err := s.syncPeriods(ctx, budget, categories)
+	if err != nil || len(periods) == 0 {
+		return err
+	}
+
+	current := periodCategories(periods[len(periods)-1])
+	for _, category := range categories {
+		period := current[category.CategoryID]
+		if period.SpentAmount != category.SpentAmount {
+			if err := s.repo.UpdateSpentAmount(ctx, budget.ID, category.CategoryID, period.SpentAmount); err != nil {
+				return err
+			}
+		}
+		if period.RolloverAmount != category.RolloverAmount {
+			if err := s.repo.UpdateRolloverAmount(ctx, budget.ID, category.CategoryID, period.RolloverAmount); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// periodCategories indexes the categories of a period by category
+func periodCategories(period BudgetPeriod) map[uuid.UUID]BudgetPeriodCategory {
+	categories := make(map[uuid.UUID]BudgetPeriodCategory, len(period.Categories))
+	for _, category := range period.Categories {
+		categories[category.CategoryID] = category
+	}
+	return categories
+}
+
+// syncPeriods returns the periods of a budget that have started and stores the ones that
+// were missing or still open
+func (s *service) syncPeriods(ctx context.Context, budget *Budget, categories []BudgetCategory) ([]BudgetPeriod, error) {
+	periods, calculated, err := s.calculatePeriods(ctx, budget, categories)
+	if err != nil {
+		return nil, err
+	}
+	for i := range periods {
+		if !calculated[i] {
+			continue
+		}
+		if err := s.repo.SavePeriod(ctx, &periods[i]); err != nil {
+			return nil, err
+		}
+	}
+	return periods, nil
+}
+
+// calculatePeriods returns the periods of a budget that have started, and which of them
+// were calculated because they were missing or still open. Closed periods are kept as they
+// were stored, so their allocations stay as they were when the period ended
+func (s *service) calculatePeriods(ctx context.Context, budget *Budget, categories []BudgetCategory) ([]BudgetPeriod, []bool, error) {
+	existing, err := s.repo.GetPeriodsByBudgetID(ctx, budget.ID)
+	if err != nil {
+		return nil, nil, err
+	}
+	byStart := make(map[string]BudgetPeriod, len(existing))
+	for _, period := range existing {
+		byStart[period.StartDate.Format("2006-01-02")] = period
+	}
+
+	userIDs, err := s.budgetUserIDs(ctx, budget)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	now := time.Now()
+	today := truncateToDay(now)
+	bounds := budgetPeriods(budget, now)
+	periods := make([]BudgetPeriod, 0, len(bounds))
+	calculated := make([]bool, 0, len(bounds))
+	carry := make(map[uuid.UUID]float64)
+
+	for _, b := range bounds {
+		period, ok := byStart[b.start.Format("2006-01-02")]
+		calculate := !ok || !period.IsClosed
+		if calculate {
+			spent, err := s.calculateSpentAmounts(ctx, userIDs, b.start, &b.end, categories)
+			if err != nil {
+				return nil, nil, err
+			}
+
+			period = BudgetPeriod{
+				ID:        period.ID,
+				BudgetID:  budget.ID,
+				StartDate: b.start,
+				EndDate:   b.end,
+				IsClosed:  b.end.Before(today),
+				CreatedAt: period.CreatedAt,
+			}
+			for _, category := range categories {
+				var rollover float64
+				if category.Rollover {
+					rollover = carry[category.CategoryID]
+				}
+				period.Categories = append(period.Categories, newPeriodCategory(category.CategoryID, category.AllocatedAmount, rollover, spent[category.CategoryID]))
+			}
+			period.AllocatedAmount, period.RolloverAmount, period.SpentAmount, period.RemainingAmount = periodTotals(period.Categories)
+		}
+
+		carry = make(map[uuid.UUID]float64, len(period.Categories))
+		for _, category := range period.Categories {
+			carry[category.CategoryID] = category.RemainingAmount
+		}
+		periods = append(periods, period)
+		calculated = append(calculated, calculate)
+	}
+
+	return periods, calculated, nil
+}
+
+// newPeriodCategory builds the allocation and actuals of a category for a period
+func newPeriodCategory(categoryID uuid.UUID, allocated, rollover, spent float64) BudgetPeriodCategory {
+	allocated = roundAmount(allocated)
+	rollover = roundAmount(rollover)
+	spent = roundAmount(spent)
+	return BudgetPeriodCategory{
+		CategoryID:      categoryID,
+		AllocatedAmount: allocated,
+		RolloverAmount:  rollover,
+		SpentAmount:     spent,
+		RemainingAmount: roundAmount(allocated + rollover - spent),
+	}
+}
+
+// periodTotals sums the allocations and actuals of a period's categories
+func periodTotals(categories []BudgetPeriodCategory) (allocated, rollover, spent, remaining float64) {
+	for _, category := range categories {
+		allocated += category.AllocatedAmount
+		rollover += category.RolloverAmount
+		spent += category.SpentAmount
+		remaining += category.RemainingAmount
+	}
+	return roundAmount(allocated), roundAmount(rollover), roundAmount(spent), roundAmount(remaining)
+}
+
+// roundAmount rounds an amount to cents
+func roundAmount(amount float64) float64 {
+	return math.Round(amount*100) / 100
+}
+
+// calculateSpentAmounts sums the expenses of users between two dates per budget category.
+// An expense counts toward the closest budgeted category, which is either its own
+// category or one of its ancestors
+func (s *service) calculateSpentAmounts(ctx context.Context, userIDs []uuid.UUID, startDate time.Time, endDate *time.Time, categories []BudgetCategory) (map[uuid.UUID]float64, error) {
+	spent := make(map[uuid.UUID]float64)
+	if len(categories) == 0 {
+		return spent, nil
+	}
+
+	spending, err := s.repo.GetSpendingByCategory(ctx, userIDs, startDate, endDate)
+	if err != nil || len(spending) == 0 {
+		return spent, err
+	}
+
+	categoryIDs := make([]uuid.UUID, 0, len(spending))
+	for categoryID := range spending {
+		categoryIDs = append(categoryIDs, categoryID)
+	}
+	owners, err := s.budgetedCategories(ctx, categories, categoryIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	for categoryID, amount := range spending {
+		if owner, ok := owners[categoryID]; ok {
+			spent[owner] += amount
+		}
+	}
+	return spent, nil
+}
+
+// budgetedCategories maps categories to the closest budgeted category, which is either the
+// category itself or one of its ancestors. Categories outside the budget are left out
+func (s *service) budgetedCategories(ctx context.Context, categories []BudgetCategory, categoryIDs []uuid.UUID) (map[uuid.UUID]uuid.UUID, error) {
+	hierarchy, err := s.repo.GetCategoryHierarchy(ctx, categoryIDs)
+	if err != nil {
+		return nil, err
+	}
+	return closestBudgetedCategories(categories, hierarchy, categoryIDs), nil
+}
+
+// closestBudgetedCategories maps categories to the closest budgeted category using a
+// hierarchy that contains the categories and their ancestors
+func closestBudgetedCategories(categories []BudgetCategory, hierarchy []Category, categoryIDs []uuid.UUID) map[uuid.UUID]uuid.UUID {
+	budgeted := make(map[uuid.UUID]bool, len(categories))
+	for _, category := range categories {
+		budgeted[category.CategoryID] = true
+	}
+
+	parents := make(map[uuid.UUID]*uuid.UUID, len(hierarchy))
+	for _, category := range hierarchy {
+		parents[category.ID] = category.ParentID
+	}
+
+	owners := make(map[uuid.UUID]uuid.UUID, len(categoryIDs))
+	for _, categoryID := range categoryIDs {
+		seen := make(map[uuid.UUID]bool)
+		for current := &categoryID; current != nil && !seen[*current]; current = parents[*current] {
+			seen[*current] = true
+			if budgeted[*current] {
+				owners[categoryID] = *current
+				break
+			}
+		}
+	}
+	return owners
+}
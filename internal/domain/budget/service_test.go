@@ -20,6 +20,37 @@ func (m *MockRepository) Create(ctx context.Context, budget *Budget) error {
 	return args.Error(0)
 }
 
+func (m *MockRepository) CreateWithCategories(ctx context.Context, budget *Budget, categories []BudgetCategory) error {
+	args := m.Called(ctx, budget, categories)
+	return args.Error(0)
+}
+
+func (m *MockRepository) CreateTemplate(ctx context.Context, template *BudgetTemplate) error {
+	args := m.Called(ctx, template)
+	return args.Error(0)
+}
+
+func (m *MockRepository) GetTemplateByID(ctx context.Context, id uuid.UUID) (*BudgetTemplate, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*BudgetTemplate), args.Error(1)
+}
+
+func (m *MockRepository) GetTemplatesByUserID(ctx context.Context, userID uuid.UUID) ([]BudgetTemplate, error) {
+	args := m.Called(ctx, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]BudgetTemplate), args.Error(1)
+}
+
+func (m *MockRepository) DeleteTemplate(ctx context.Context, id uuid.UUID) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
 func (m *MockRepository) GetByID(ctx context.Context, id uuid.UUID) (*Budget, error) {
 	args := m.Called(ctx, id)
 	if args.Get(0) == nil {
@@ -90,6 +121,150 @@ func (m *MockRepository) UpdateSpentAmount(ctx context.Context, budgetID, catego
 	return args.Error(0)
 }
 
+func (m *MockRepository) UpdateRolloverAmount(ctx context.Context, budgetID, categoryID uuid.UUID, amount float64) error {
+	args := m.Called(ctx, budgetID, categoryID, amount)
+	return args.Error(0)
+}
+
+func (m *MockRepository) GetPeriodsByBudgetID(ctx context.Context, budgetID uuid.UUID) ([]BudgetPeriod, error) {
+	args := m.Called(ctx, budgetID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]BudgetPeriod), args.Error(1)
+}
+
+func (m *MockRepository) SavePeriod(ctx context.Context, period *BudgetPeriod) error {
+	args := m.Called(ctx, period)
+	return args.Error(0)
+}
+
+func (m *MockRepository) DeletePeriods(ctx context.Context, budgetID uuid.UUID) error {
+	args := m.Called(ctx, budgetID)
+	return args.Error(0)
+}
+
+func (m *MockRepository) GetCategoryHierarchy(ctx context.Context, categoryIDs []uuid.UUID) ([]Category, error) {
+	args := m.Called(ctx, categoryIDs)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]Category), args.Error(1)
+}
+
+func (m *MockRepository) GetSpendingByCategory(ctx context.Context, userIDs []uuid.UUID, startDate time.Time, endDate *time.Time) (map[uuid.UUID]float64, error) {
+	args := m.Called(ctx, userIDs, startDate, endDate)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(map[uuid.UUID]float64), args.Error(1)
+}
+
+func (m *MockRepository) GetSpendingByMember(ctx context.Context, userIDs []uuid.UUID, startDate time.Time, endDate *time.Time) (map[uuid.UUID]map[uuid.UUID]float64, error) {
+	args := m.Called(ctx, userIDs, startDate, endDate)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(map[uuid.UUID]map[uuid.UUID]float64), args.Error(1)
+}
+
+func (m *MockRepository) GetIncome(ctx context.Context, userIDs []uuid.UUID, startDate time.Time, endDate *time.Time) (float64, error) {
+	args := m.Called(ctx, userIDs, startDate, endDate)
+	return args.Get(0).(float64), args.Error(1)
+}
+
+func (m *MockRepository) GetExpenseHistory(ctx context.Context, userIDs []uuid.UUID, startDate, endDate time.Time) ([]ExpenseRecord, error) {
+	args := m.Called(ctx, userIDs, startDate, endDate)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]ExpenseRecord), args.Error(1)
+}
+
+func (m *MockRepository) RecordAlertEvent(ctx context.Context, event *BudgetAlertEvent) (bool, error) {
+	args := m.Called(ctx, event)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockRepository) CreateGoal(ctx context.Context, goal *Goal) error {
+	args := m.Called(ctx, goal)
+	return args.Error(0)
+}
+
+func (m *MockRepository) GetGoalByID(ctx context.Context, id uuid.UUID) (*Goal, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*Goal), args.Error(1)
+}
+
+func (m *MockRepository) GetGoalsByUserID(ctx context.Context, userID uuid.UUID) ([]Goal, error) {
+	args := m.Called(ctx, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]Goal), args.Error(1)
+}
+
+func (m *MockRepository) UpdateGoal(ctx context.Context, goal *Goal) error {
+	args := m.Called(ctx, goal)
+	return args.Error(0)
+}
+
+func (m *MockRepository) DeleteGoal(ctx context.Context, id uuid.UUID) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+func (m *MockRepository) GetFamilyMember(ctx context.Context, familyID, userID uuid.UUID) (*FamilyMember, error) {
+	args := m.Called(ctx, familyID, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*FamilyMember), args.Error(1)
+}
+
+func (m *MockRepository) GetFamilyMembers(ctx context.Context, familyID uuid.UUID) ([]FamilyMember, error) {
+	args := m.Called(ctx, familyID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]FamilyMember), args.Error(1)
+}
+
+func (m *MockRepository) GetAccountBalances(ctx context.Context, userID uuid.UUID, accountIDs []uuid.UUID) (map[uuid.UUID]float64, error) {
+	args := m.Called(ctx, userID, accountIDs)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(map[uuid.UUID]float64), args.Error(1)
+}
+
+func (m *MockRepository) GetGoalTransactions(ctx context.Context, userID uuid.UUID, accountIDs []uuid.UUID, tag string, startDate time.Time) ([]GoalTransaction, error) {
+	args := m.Called(ctx, userID, accountIDs, tag, startDate)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]GoalTransaction), args.Error(1)
+}
+
+func (m *MockRepository) TransferEnvelopeFunds(ctx context.Context, transfer *EnvelopeTransfer, check func(repo Repository) error) error {
+	if err := check(m); err != nil {
+		return err
+	}
+	args := m.Called(ctx, transfer)
+	return args.Error(0)
+}
+
+func (m *MockRepository) GetEnvelopeTransfers(ctx context.Context, budgetID uuid.UUID, offset, limit int) ([]EnvelopeTransfer, error) {
+	args := m.Called(ctx, budgetID, offset, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]EnvelopeTransfer), args.Error(1)
+}
+
 func (m *MockRepository) GetActiveBudgetsByUser(ctx context.Context, userID uuid.UUID) ([]Budget, error) {
 	args := m.Called(ctx, userID)
 	if args.Get(0) == nil {
@@ -382,32 +557,288 @@ func TestGetBudgetSummary(t *testing.T) {
 	userID := uuid.New()
 	budgetID := uuid.New()
 
+	food := Category{ID: uuid.New(), Name: "Food"}
+	transport := Category{ID: uuid.New(), Name: "Transport"}
+	startDate := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	endDate := time.Date(2024, 1, 31, 0, 0, 0, 0, time.UTC)
 	existingBudget := &Budget{
-		ID:     budgetID,
-		UserID: userID,
-		Name:   "Monthly Budget",
+		ID:         budgetID,
+		UserID:     userID,
+		Name:       "Monthly Budget",
+		PeriodType: PeriodTypeMonthly,
+		StartDate:  startDate,
+		EndDate:    &endDate,
 	}
 
-	expectedSummary := &BudgetSummary{
-		TotalAllocated:   1000.0,
-		TotalSpent:       750.0,
-		SpendingProgress: 0.75,
-		Alerts:           []BudgetAlert{},
+	mockRepo.On("GetByID", mock.Anything, budgetID).Return(existingBudget, nil)
+	mockRepo.On("GetCategoriesByBudgetID", mock.Anything, budgetID).Return([]BudgetCategory{
+		{BudgetID: budgetID, CategoryID: food.ID, AllocatedAmount: 600, SpentAmount: 100},
+		{BudgetID: budgetID, CategoryID: transport.ID, AllocatedAmount: 400},
+	}, nil)
+	mockRepo.On("GetPeriodsByBudgetID", mock.Anything, budgetID).Return([]BudgetPeriod{}, nil)
+	mockRepo.On("GetSpendingByCategory", mock.Anything, []uuid.UUID{userID}, startDate, &endDate).
+		Return(map[uuid.UUID]float64{food.ID: 500, transport.ID: 250}, nil)
+	mockRepo.On("GetCategoryHierarchy", mock.Anything, mock.Anything).Return([]Category{food, transport}, nil)
+	mockRepo.On("GetExpenseHistory", mock.Anything, []uuid.UUID{userID}, mock.Anything, mock.Anything).Return([]ExpenseRecord{}, nil)
+
+	result, err := service.GetBudgetSummary(ctx, userID, budgetID, nil)
+
+	assert.NoError(t, err)
+	assert.NotNil(t, result)
+	assert.Equal(t, 1000.0, result.TotalAllocated)
+	assert.Equal(t, 750.0, result.TotalSpent)
+	assert.Equal(t, 75.0, result.SpendingProgress)
+	// Reading a budget calculates the spent amounts without storing them
+	mockRepo.AssertNotCalled(t, "SavePeriod", mock.Anything, mock.Anything)
+	mockRepo.AssertNotCalled(t, "UpdateSpentAmount", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestGetBudgetSummary_CategoryLevel(t *testing.T) {
+	mockRepo := &MockRepository{}
+	service := NewService(mockRepo)
+	ctx := context.Background()
+	userID := uuid.New()
+	budgetID := uuid.New()
+
+	food := Category{ID: uuid.New(), Name: "Food"}
+	groceries := Category{ID: uuid.New(), Name: "Groceries", ParentID: &food.ID}
+	restaurants := Category{ID: uuid.New(), Name: "Restaurants", ParentID: &food.ID}
+	transport := Category{ID: uuid.New(), Name: "Transport"}
+
+	startDate := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	endDate := time.Date(2024, 1, 31, 0, 0, 0, 0, time.UTC)
+	existingBudget := &Budget{ID: budgetID, UserID: userID, PeriodType: PeriodTypeMonthly, StartDate: startDate, EndDate: &endDate}
+
+	mockRepo.On("GetByID", mock.Anything, budgetID).Return(existingBudget, nil)
+	mockRepo.On("GetCategoriesByBudgetID", mock.Anything, budgetID).Return([]BudgetCategory{
+		{BudgetID: budgetID, CategoryID: groceries.ID, AllocatedAmount: 400},
+		{BudgetID: budgetID, CategoryID: restaurants.ID, AllocatedAmount: 100},
+		{BudgetID: budgetID, CategoryID: transport.ID, AllocatedAmount: 200},
+	}, nil)
+	mockRepo.On("GetPeriodsByBudgetID", mock.Anything, budgetID).Return([]BudgetPeriod{}, nil)
+	mockRepo.On("GetSpendingByCategory", mock.Anything, []uuid.UUID{userID}, startDate, &endDate).
+		Return(map[uuid.UUID]float64{groceries.ID: 300, restaurants.ID: 150, transport.ID: 50}, nil)
+	mockRepo.On("GetCategoryHierarchy", mock.Anything, mock.Anything).
+		Return([]Category{groceries, restaurants, transport, food}, nil)
+	mockRepo.On("GetExpenseHistory", mock.Anything, []uuid.UUID{userID}, mock.Anything, mock.Anything).Return([]ExpenseRecord{}, nil)
+
+	level := 0
+	result, err := service.GetBudgetSummary(ctx, userID, budgetID, &level)
+
+	assert.NoError(t, err)
+	assert.Equal(t, []CategoryRollup{
+		{CategoryID: food.ID, CategoryName: "Food", Level: 0, AllocatedAmount: 500, SpentAmount: 450, RemainingAmount: 50, SpendingProgress: 90},
+		{CategoryID: transport.ID, CategoryName: "Transport", Level: 0, AllocatedAmount: 200, SpentAmount: 50, RemainingAmount: 150, SpendingProgress: 25},
+	}, result.Rollups)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestRecalculateBudget(t *testing.T) {
+	mockRepo := &MockRepository{}
+	service := NewService(mockRepo)
+	ctx := context.Background()
+	userID := uuid.New()
+	budgetID := uuid.New()
+
+	food := Category{ID: uuid.New(), Name: "Food"}
+	groceries := Category{ID: uuid.New(), Name: "Groceries", ParentID: &food.ID}
+	restaurants := Category{ID: uuid.New(), Name: "Restaurants", ParentID: &food.ID}
+	transport := Category{ID: uuid.New(), Name: "Transport"}
+	unbudgeted := Category{ID: uuid.New(), Name: "Travel"}
+
+	startDate := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	endDate := time.Date(2024, 1, 31, 0, 0, 0, 0, time.UTC)
+	existingBudget := &Budget{ID: budgetID, UserID: userID, StartDate: startDate, EndDate: &endDate}
+
+	// Food is budgeted as a whole, so groceries and restaurants roll up into it
+	categories := []BudgetCategory{
+		{BudgetID: budgetID, CategoryID: food.ID, AllocatedAmount: 500, SpentAmount: 100},
+		{BudgetID: budgetID, CategoryID: transport.ID, AllocatedAmount: 200, SpentAmount: 40},
+	}
+	spending := map[uuid.UUID]float64{
+		groceries.ID:   120.254,
+		restaurants.ID: 80,
+		transport.ID:   40,
+		unbudgeted.ID:  300,
 	}
+	summary := &BudgetSummary{TotalAllocated: 700, TotalSpent: 240.25}
 
 	mockRepo.On("GetByID", mock.Anything, budgetID).Return(existingBudget, nil)
-	mockRepo.On("GetBudgetSummary", mock.Anything, budgetID).Return(expectedSummary, nil)
+	mockRepo.On("GetCategoriesByBudgetID", mock.Anything, budgetID).Return(categories, nil)
+	mockRepo.On("GetPeriodsByBudgetID", mock.Anything, budgetID).Return([]BudgetPeriod{}, nil)
+	mockRepo.On("GetSpendingByCategory", mock.Anything, []uuid.UUID{userID}, startDate, &endDate).Return(spending, nil)
+	mockRepo.On("GetCategoryHierarchy", mock.Anything, mock.Anything).
+		Return([]Category{food, groceries, restaurants, transport, unbudgeted}, nil)
+	mockRepo.On("SavePeriod", mock.Anything, mock.AnythingOfType("*budget.BudgetPeriod")).Return(nil)
+	mockRepo.On("UpdateSpentAmount", mock.Anything, budgetID, food.ID, 200.25).Return(nil)
+	mockRepo.On("GetBudgetSummary", mock.Anything, budgetID).Return(summary, nil)
 
-	result, err := service.GetBudgetSummary(ctx, userID, budgetID)
+	result, err := service.RecalculateBudget(ctx, userID, budgetID)
 
 	assert.NoError(t, err)
-	assert.NotNil(t, result)
-	assert.Equal(t, expectedSummary.TotalAllocated, result.TotalAllocated)
-	assert.Equal(t, expectedSummary.TotalSpent, result.TotalSpent)
-	assert.Equal(t, expectedSummary.SpendingProgress, result.SpendingProgress)
+	assert.Equal(t, summary, result)
+	// Transport is unchanged and is not written back
+	mockRepo.AssertNotCalled(t, "UpdateSpentAmount", mock.Anything, budgetID, transport.ID, mock.Anything)
 	mockRepo.AssertExpectations(t)
 }
 
+func TestRecalculateBudget_Unauthorized(t *testing.T) {
+	mockRepo := &MockRepository{}
+	service := NewService(mockRepo)
+	ctx := context.Background()
+	budgetID := uuid.New()
+
+	mockRepo.On("GetByID", mock.Anything, budgetID).Return(&Budget{ID: budgetID, UserID: uuid.New()}, nil)
+
+	result, err := service.RecalculateBudget(ctx, uuid.New(), budgetID)
+
+	assert.Error(t, err)
+	assert.Nil(t, result)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestGetBudgetPeriods_Rollover(t *testing.T) {
+	mockRepo := &MockRepository{}
+	service := NewService(mockRepo)
+	ctx := context.Background()
+	userID := uuid.New()
+	budgetID := uuid.New()
+	foodID := uuid.New()
+	transportID := uuid.New()
+
+	jan := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	feb := time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC)
+	mar := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	endDate := time.Date(2024, 3, 31, 0, 0, 0, 0, time.UTC)
+	existingBudget := &Budget{ID: budgetID, UserID: userID, PeriodType: PeriodTypeMonthly, StartDate: jan, EndDate: &endDate}
+
+	categories := []BudgetCategory{
+		{BudgetID: budgetID, CategoryID: foodID, AllocatedAmount: 100, Rollover: true},
+		{BudgetID: budgetID, CategoryID: transportID, AllocatedAmount: 50},
+	}
+
+	// January is closed and kept as it was stored
+	january := BudgetPeriod{
+		ID:        uuid.New(),
+		BudgetID:  budgetID,
+		StartDate: jan,
+		EndDate:   feb.AddDate(0, 0, -1),
+		IsClosed:  true,
+		Categories: []BudgetPeriodCategory{
+			{CategoryID: foodID, AllocatedAmount: 100, SpentAmount: 70, RemainingAmount: 30},
+			{CategoryID: transportID, AllocatedAmount: 50, SpentAmount: 0, RemainingAmount: 50},
+		},
+	}
+	febEnd := mar.AddDate(0, 0, -1)
+
+	mockRepo.On("GetByID", mock.Anything, budgetID).Return(existingBudget, nil)
+	mockRepo.On("GetCategoriesByBudgetID", mock.Anything, budgetID).Return(categories, nil)
+	mockRepo.On("GetPeriodsByBudgetID", mock.Anything, budgetID).Return([]BudgetPeriod{january}, nil)
+	mockRepo.On("GetSpendingByCategory", mock.Anything, []uuid.UUID{userID}, feb, &febEnd).
+		Return(map[uuid.UUID]float64{foodID: 150}, nil)
+	mockRepo.On("GetSpendingByCategory", mock.Anything, []uuid.UUID{userID}, mar, &endDate).
+		Return(map[uuid.UUID]float64{foodID: 20, transportID: 60}, nil)
+	mockRepo.On("GetCategoryHierarchy", mock.Anything, mock.Anything).Return([]Category{}, nil)
+
+	periods, err := service.GetBudgetPeriods(ctx, userID, budgetID)
+
+	assert.NoError(t, err)
+	assert.Len(t, periods, 3)
+	assert.Equal(t, january, periods[0])
+
+	// The unspent 30 carries into February, which ends 20 over budget
+	assert.Equal(t, BudgetPeriodCategory{CategoryID: foodID, AllocatedAmount: 100, RolloverAmount: 30, SpentAmount: 150, RemainingAmount: -20}, periods[1].Categories[0])
+	// The overspend reduces March, while transport does not roll over
+	assert.Equal(t, BudgetPeriodCategory{CategoryID: foodID, AllocatedAmount: 100, RolloverAmount: -20, SpentAmount: 20, RemainingAmount: 60}, periods[2].Categories[0])
+	assert.Equal(t, BudgetPeriodCategory{CategoryID: transportID, AllocatedAmount: 50, SpentAmount: 60, RemainingAmount: -10}, periods[2].Categories[1])
+	assert.Equal(t, 150.0, periods[2].AllocatedAmount)
+	assert.Equal(t, 50.0, periods[2].RemainingAmount)
+	assert.True(t, periods[2].IsClosed)
+	// Periods are only stored when the budget is recalculated
+	mockRepo.AssertNotCalled(t, "SavePeriod", mock.Anything, mock.Anything)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestUpdateBudget_ScheduleChangeResetsPeriods(t *testing.T) {
+	mockRepo := &MockRepository{}
+	service := NewService(mockRepo)
+	ctx := context.Background()
+	userID := uuid.New()
+	budgetID := uuid.New()
+
+	existingBudget := &Budget{
+		ID:          budgetID,
+		UserID:      userID,
+		Name:        "Budget",
+		PeriodType:  PeriodTypeMonthly,
+		StartDate:   time.Now(),
+		TotalAmount: 1000.0,
+	}
+
+	periodType := PeriodTypeQuarterly
+	mockRepo.On("GetByID", mock.Anything, budgetID).Return(existingBudget, nil)
+	mockRepo.On("Update", mock.Anything, mock.AnythingOfType("*budget.Budget")).Return(nil)
+	mockRepo.On("DeletePeriods", mock.Anything, budgetID).Return(nil)
+
+	result, err := service.UpdateBudget(ctx, userID, budgetID, &UpdateBudgetRequest{PeriodType: &periodType})
+
+	assert.NoError(t, err)
+	assert.Equal(t, PeriodTypeQuarterly, result.PeriodType)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestUpdateBudget_EndDateChangeResetsPeriods(t *testing.T) {
+	ctx := context.Background()
+	userID := uuid.New()
+	budgetID := uuid.New()
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	end := time.Date(2024, 6, 30, 0, 0, 0, 0, time.UTC)
+	later := time.Date(2024, 12, 31, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name    string
+		current *time.Time
+		updated time.Time
+		reset   bool
+	}{
+		{name: "end date set", current: nil, updated: end, reset: true},
+		{name: "end date moved", current: &end, updated: later, reset: true},
+		{name: "end date unchanged", current: &end, updated: end, reset: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := &MockRepository{}
+			service := NewService(mockRepo)
+			existingBudget := &Budget{
+				ID:          budgetID,
+				UserID:      userID,
+				Name:        "Budget",
+				PeriodType:  PeriodTypeMonthly,
+				StartDate:   start,
+				EndDate:     tt.current,
+				TotalAmount: 1000.0,
+			}
+
+			mockRepo.On("GetByID", mock.Anything, budgetID).Return(existingBudget, nil)
+			mockRepo.On("Update", mock.Anything, mock.AnythingOfType("*budget.Budget")).Return(nil)
+			mockRepo.On("DeletePeriods", mock.Anything, budgetID).Return(nil)
+
+			updated := tt.updated
+			result, err := service.UpdateBudget(ctx, userID, budgetID, &UpdateBudgetRequest{EndDate: &updated})
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.updated, *result.EndDate)
+			if tt.reset {
+				mockRepo.AssertCalled(t, "DeletePeriods", mock.Anything, budgetID)
+			} else {
+				mockRepo.AssertNotCalled(t, "DeletePeriods", mock.Anything, budgetID)
+			}
+		})
+	}
+}
+
 func TestUpdateBudgetFromTransaction(t *testing.T) {
 	mockRepo := &MockRepository{}
 	service := NewService(mockRepo)
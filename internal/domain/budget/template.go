@@ -0,0 +1,393 @@
+package budget
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// MaxSuggestionMonths limits how far back budget suggestions look
+const MaxSuggestionMonths = 24
+
+// SaveAsTemplate saves the layout and allocations of a budget as a template
+func (s *service) SaveAsTemplate(ctx context.Context, userID, budgetID uuid.UUID, req *CreateTemplateRequest) (*BudgetTemplate, error) {
+	ctx, span := otel.Tracer("").Start(ctx, "budget.SaveAsTemplate",
+		trace.WithAttributes(
+			attribute.String("user_id", userID.String()),
+			attribute.String("budget_id", budgetID.String()),
+			attribute.String("template_name", req.Name),
+		),
+	)
+	defer span.End()
+
+	if req.Name == "" {
+		err := fmt.Errorf("template name is required")
+		span.SetStatus(codes.Error, err.Error())
+		return nil, err
+	}
+
+	// Verify budget access
+	budget, err := s.repo.GetByID(ctx, budgetID)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, err
+	}
+
+	if err := s.authorizeBudget(ctx, userID, budget, budgetAccessView); err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, err
+	}
+
+	categories, err := s.repo.GetCategoriesByBudgetID(ctx, budgetID)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, err
+	}
+
+	template := &BudgetTemplate{
+		UserID:      userID,
+		Name:        req.Name,
+		Description: req.Description,
+		PeriodType:  budget.PeriodType,
+		PeriodDays:  budget.PeriodDays,
+		TotalAmount: budget.TotalAmount,
+		Currency:    budget.Currency,
+		Settings:    budget.Settings,
+		Categories:  make([]BudgetTemplateCategory, len(categories)),
+	}
+	for i, category := range categories {
+		template.Categories[i] = BudgetTemplateCategory{
+			CategoryID:      category.CategoryID,
+			AllocatedAmount: category.AllocatedAmount,
+			Rollover:        category.Rollover,
+			AlertThreshold:  category.AlertThreshold,
+			AlertRules:      category.AlertRules,
+		}
+	}
+
+	if err := s.repo.CreateTemplate(ctx, template); err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, err
+	}
+
+	span.SetAttributes(attribute.String("template_id", template.ID.String()))
+	return template, nil
+}
+
+// ListTemplates retrieves the budget templates of a user
+func (s *service) ListTemplates(ctx context.Context, userID uuid.UUID) ([]BudgetTemplate, error) {
+	ctx, span := otel.Tracer("").Start(ctx, "budget.ListTemplates",
+		trace.WithAttributes(
+			attribute.String("user_id", userID.String()),
+		),
+	)
+	defer span.End()
+
+	templates, err := s.repo.GetTemplatesByUserID(ctx, userID)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, err
+	}
+
+	span.SetAttributes(attribute.Int("templates_count", len(templates)))
+	return templates, nil
+}
+
+// DeleteTemplate deletes a budget template
+func (s *service) DeleteTemplate(ctx context.Context, userID, templateID uuid.UUID) error {
+	ctx, span := otel.Tracer("").Start(ctx, "budget.DeleteTemplate",
+		trace.WithAttributes(
+			attribute.String("user_id", userID.String()),
+			attribute.String("template_id", templateID.String()),
+		),
+	)
+	defer span.End()
+
+	template, err := s.repo.GetTemplateByID(ctx, templateID)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return err
+	}
+
+	if template.UserID != userID {
+		span.SetStatus(codes.Error, "unauthorized access to budget template")
+		return fmt.Errorf("unauthorized access to budget template")
+	}
+
+	if err := s.repo.DeleteTemplate(ctx, templateID); err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return err
+	}
+
+	return nil
+}
+
+// CreateBudgetFromTemplate creates a budget with the layout and allocations of a template
+func (s *service) CreateBudgetFromTemplate(ctx context.Context, userID, templateID uuid.UUID, req *CopyBudgetRequest) (*BudgetResponse, error) {
+	ctx, span := otel.Tracer("").Start(ctx, "budget.CreateBudgetFromTemplate",
+		trace.WithAttributes(
+			attribute.String("user_id", userID.String()),
+			attribute.String("template_id", templateID.String()),
+		),
+	)
+	defer span.End()
+
+	template, err := s.repo.GetTemplateByID(ctx, templateID)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, err
+	}
+
+	if template.UserID != userID {
+		span.SetStatus(codes.Error, "unauthorized access to budget template")
+		return nil, fmt.Errorf("unauthorized access to budget template")
+	}
+
+	if req.StartDate == nil {
+		err := fmt.Errorf("start date is required")
+		span.SetStatus(codes.Error, err.Error())
+		return nil, err
+	}
+
+	name := req.Name
+	if name == "" {
+		name = template.Name
+	}
+	source := &Budget{
+		Name:        name,
+		Description: template.Description,
+		PeriodType:  template.PeriodType,
+		PeriodDays:  template.PeriodDays,
+		StartDate:   *req.StartDate,
+		EndDate:     req.EndDate,
+		TotalAmount: template.TotalAmount,
+		Currency:    template.Currency,
+		Settings:    template.Settings,
+	}
+	categories := make([]BudgetCategory, len(template.Categories))
+	for i, category := range template.Categories {
+		categories[i] = BudgetCategory{
+			CategoryID:      category.CategoryID,
+			AllocatedAmount: category.AllocatedAmount,
+			Rollover:        category.Rollover,
+			AlertThreshold:  category.AlertThreshold,
+			AlertRules:      category.AlertRules,
+		}
+	}
+
+	budget, err := s.createBudgetCopy(ctx, userID, source, categories, req.Scale)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, err
+	}
+
+	span.SetAttributes(attribute.String("budget_id", budget.ID.String()))
+	return s.toBudgetResponse(budget), nil
+}
+
+// CopyBudget creates a budget with the layout and allocations of an existing budget.
+// Without a start date the copy starts one period after the budget it is copied from
+func (s *service) CopyBudget(ctx context.Context, userID, budgetID uuid.UUID, req *CopyBudgetRequest) (*BudgetResponse, error) {
+	ctx, span := otel.Tracer("").Start(ctx, "budget.CopyBudget",
+		trace.WithAttributes(
+			attribute.String("user_id", userID.String()),
+			attribute.String("budget_id", budgetID.String()),
+		),
+	)
+	defer span.End()
+
+	// Verify budget access
+	original, err := s.repo.GetByID(ctx, budgetID)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, err
+	}
+
+	if err := s.authorizeBudget(ctx, userID, original, budgetAccessView); err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, err
+	}
+
+	categories, err := s.repo.GetCategoriesByBudgetID(ctx, budgetID)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, err
+	}
+
+	source := *original
+	if req.Name != "" {
+		source.Name = req.Name
+	}
+	source.StartDate, source.EndDate = shiftBudgetDates(original, req.StartDate, req.EndDate)
+
+	copies := make([]BudgetCategory, len(categories))
+	for i, category := range categories {
+		copies[i] = BudgetCategory{
+			CategoryID:      category.CategoryID,
+			AllocatedAmount: category.AllocatedAmount,
+			Rollover:        category.Rollover,
+			AlertThreshold:  category.AlertThreshold,
+			AlertRules:      category.AlertRules,
+		}
+	}
+
+	budget, err := s.createBudgetCopy(ctx, userID, &source, copies, req.Scale)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, err
+	}
+
+	span.SetAttributes(attribute.String("new_budget_id", budget.ID.String()))
+	return s.toBudgetResponse(budget), nil
+}
+
+// SuggestBudget proposes monthly allocations from the user's average spending per category
+// over the last complete months
+func (s *service) SuggestBudget(ctx context.Context, userID uuid.UUID, months int) (*BudgetSuggestion, error) {
+	ctx, span := otel.Tracer("").Start(ctx, "budget.SuggestBudget",
+		trace.WithAttributes(
+			attribute.String("user_id", userID.String()),
+			attribute.Int("months", months),
+		),
+	)
+	defer span.End()
+
+	if months <= 0 || months > MaxSuggestionMonths {
+		err := fmt.Errorf("months must be between 1 and %d", MaxSuggestionMonths)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, err
+	}
+
+	now := time.Now()
+	to := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, now.Location()).AddDate(0, 0, -1)
+	from := time.Date(to.Year(), to.Month()-time.Month(months-1), 1, 0, 0, 0, 0, to.Location())
+
+	spending, err := s.repo.GetSpendingByCategory(ctx, []uuid.UUID{userID}, from, &to)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, err
+	}
+
+	suggestion := &BudgetSuggestion{
+		Months:     months,
+		From:       from,
+		To:         to,
+		Categories: make([]SuggestedAllocation, 0, len(spending)),
+	}
+	for categoryID, amount := range spending {
+		average := roundAmount(amount / float64(months))
+		// Round up to whole units so the suggestion covers the average
+		allocated := math.Ceil(average)
+		suggestion.Categories = append(suggestion.Categories, SuggestedAllocation{
+			CategoryID:      categoryID,
+			AverageSpent:    average,
+			AllocatedAmount: allocated,
+		})
+		suggestion.TotalAmount += allocated
+	}
+
+	sort.Slice(suggestion.Categories, func(i, j int) bool {
+		return suggestion.Categories[i].AllocatedAmount > suggestion.Categories[j].AllocatedAmount
+	})
+
+	span.SetAttributes(attribute.Int("categories_count", len(suggestion.Categories)))
+	return suggestion, nil
+}
+
+// createBudgetCopy creates a budget and its categories from a source, scaling all amounts.
+// Envelopes of copied envelope budgets start empty, as money reaches them through assignments
+func (s *service) createBudgetCopy(ctx context.Context, userID uuid.UUID, source *Budget, categories []BudgetCategory, scale float64) (*Budget, error) {
+	if scale == 0 {
+		scale = 1
+	}
+	if scale < 0 {
+		return nil, fmt.Errorf("scale must be positive")
+	}
+
+	budget := &Budget{
+		UserID:      userID,
+		Name:        source.Name,
+		Description: source.Description,
+		PeriodType:  source.PeriodType,
+		PeriodDays:  source.PeriodDays,
+		StartDate:   source.StartDate,
+		EndDate:     source.EndDate,
+		TotalAmount: roundAmount(source.TotalAmount * scale),
+		Currency:    source.Currency,
+		Settings:    source.Settings,
+		IsActive:    true,
+	}
+
+	if err := s.validateBudget(budget); err != nil {
+		return nil, err
+	}
+
+	envelope := isEnvelopeBudget(budget)
+	for i := range categories {
+		categories[i].AllocatedAmount = roundAmount(categories[i].AllocatedAmount * scale)
+		categories[i].IsActive = true
+		if envelope {
+			categories[i].AllocatedAmount = 0
+		}
+	}
+
+	if err := s.repo.CreateWithCategories(ctx, budget, categories); err != nil {
+		return nil, err
+	}
+
+	return budget, nil
+}
+
+// shiftBudgetDates returns the dates of a copy of a budget. Without a start date the copy
+// starts one period after the budget. A budget spanning exactly one period is copied to
+// one period, any other end date keeps the budget's length
+func shiftBudgetDates(budget *Budget, startDate, endDate *time.Time) (time.Time, *time.Time) {
+	singleCustom := budget.PeriodType == PeriodTypeCustom && budget.PeriodDays <= 0
+
+	var start time.Time
+	switch {
+	case startDate != nil:
+		start = *startDate
+	case singleCustom && budget.EndDate != nil:
+		start = budget.EndDate.AddDate(0, 0, 1)
+	default:
+		start = periodStart(budget, budget.StartDate, 1)
+	}
+
+	if endDate != nil {
+		return start, endDate
+	}
+	if budget.EndDate == nil {
+		return start, nil
+	}
+
+	if !singleCustom && budget.EndDate.Equal(periodStart(budget, budget.StartDate, 1).AddDate(0, 0, -1)) {
+		end := periodStart(budget, start, 1).AddDate(0, 0, -1)
+		return start, &end
+	}
+
+	end := start.Add(budget.EndDate.Sub(budget.StartDate))
+	return start, &end
+}
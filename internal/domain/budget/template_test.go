@@ -0,0 +1,175 @@
+package budget
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestSaveAsTemplate(t *testing.T) {
+	mockRepo := &MockRepository{}
+	service := NewService(mockRepo)
+	userID := uuid.New()
+	budgetID := uuid.New()
+	categoryID := uuid.New()
+
+	mockRepo.On("GetByID", mock.Anything, budgetID).Return(&Budget{
+		ID: budgetID, UserID: userID, PeriodType: PeriodTypeMonthly, TotalAmount: 2000, Currency: "EUR", Settings: "{}",
+	}, nil)
+	mockRepo.On("GetCategoriesByBudgetID", mock.Anything, budgetID).Return([]BudgetCategory{
+		{BudgetID: budgetID, CategoryID: categoryID, AllocatedAmount: 400, SpentAmount: 120, Rollover: true, AlertThreshold: 0.9},
+	}, nil)
+	mockRepo.On("CreateTemplate", mock.Anything, mock.AnythingOfType("*budget.BudgetTemplate")).Return(nil)
+
+	template, err := service.SaveAsTemplate(context.Background(), userID, budgetID, &CreateTemplateRequest{Name: "Household"})
+
+	assert.NoError(t, err)
+	assert.Equal(t, "Household", template.Name)
+	assert.Equal(t, PeriodTypeMonthly, template.PeriodType)
+	assert.Equal(t, "EUR", template.Currency)
+	assert.Equal(t, []BudgetTemplateCategory{
+		{CategoryID: categoryID, AllocatedAmount: 400, Rollover: true, AlertThreshold: 0.9},
+	}, template.Categories)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestCopyBudget(t *testing.T) {
+	mockRepo := &MockRepository{}
+	service := NewService(mockRepo)
+	userID := uuid.New()
+	budgetID := uuid.New()
+	groceriesID := uuid.New()
+	rentID := uuid.New()
+
+	endDate := date(2024, 1, 31)
+	mockRepo.On("GetByID", mock.Anything, budgetID).Return(&Budget{
+		ID:          budgetID,
+		UserID:      userID,
+		Name:        "January",
+		PeriodType:  PeriodTypeMonthly,
+		StartDate:   date(2024, 1, 1),
+		EndDate:     &endDate,
+		TotalAmount: 1500,
+		Currency:    "USD",
+	}, nil)
+	mockRepo.On("GetCategoriesByBudgetID", mock.Anything, budgetID).Return([]BudgetCategory{
+		{BudgetID: budgetID, CategoryID: groceriesID, AllocatedAmount: 400, SpentAmount: 380, Rollover: true, RolloverAmount: 20},
+		{BudgetID: budgetID, CategoryID: rentID, AllocatedAmount: 1000, SpentAmount: 1000},
+	}, nil)
+
+	var created *Budget
+	var categories []BudgetCategory
+	mockRepo.On("CreateWithCategories", mock.Anything, mock.AnythingOfType("*budget.Budget"), mock.Anything).
+		Return(nil).
+		Run(func(args mock.Arguments) {
+			created = args.Get(1).(*Budget)
+			categories = args.Get(2).([]BudgetCategory)
+		})
+
+	result, err := service.CopyBudget(context.Background(), userID, budgetID, &CopyBudgetRequest{Name: "February", Scale: 1.1})
+
+	assert.NoError(t, err)
+	assert.Equal(t, "February", result.Name)
+	// The copy moves to the next month and keeps to a single month
+	assert.Equal(t, date(2024, 2, 1), created.StartDate)
+	assert.Equal(t, date(2024, 2, 29), *created.EndDate)
+	assert.Equal(t, 1650.0, created.TotalAmount)
+	// Allocations are scaled while actuals are not copied
+	assert.Equal(t, []BudgetCategory{
+		{CategoryID: groceriesID, AllocatedAmount: 440, Rollover: true, IsActive: true},
+		{CategoryID: rentID, AllocatedAmount: 1100, IsActive: true},
+	}, categories)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestCreateBudgetFromTemplate(t *testing.T) {
+	mockRepo := &MockRepository{}
+	service := NewService(mockRepo)
+	userID := uuid.New()
+	templateID := uuid.New()
+	categoryID := uuid.New()
+
+	mockRepo.On("GetTemplateByID", mock.Anything, templateID).Return(&BudgetTemplate{
+		ID:          templateID,
+		UserID:      userID,
+		Name:        "Household",
+		PeriodType:  PeriodTypeMonthly,
+		TotalAmount: 1000,
+		Categories:  []BudgetTemplateCategory{{CategoryID: categoryID, AllocatedAmount: 250, AlertThreshold: 0.8}},
+	}, nil)
+
+	// A template has no dates of its own
+	_, err := service.CreateBudgetFromTemplate(context.Background(), userID, templateID, &CopyBudgetRequest{})
+	assert.Error(t, err)
+
+	mockRepo.On("CreateWithCategories", mock.Anything, mock.AnythingOfType("*budget.Budget"), []BudgetCategory{
+		{CategoryID: categoryID, AllocatedAmount: 125, AlertThreshold: 0.8, IsActive: true},
+	}).Return(nil)
+
+	startDate := date(2024, 3, 1)
+	result, err := service.CreateBudgetFromTemplate(context.Background(), userID, templateID, &CopyBudgetRequest{StartDate: &startDate, Scale: 0.5})
+
+	assert.NoError(t, err)
+	assert.Equal(t, "Household", result.Name)
+	assert.Equal(t, 500.0, result.TotalAmount)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestCreateBudgetFromTemplate_Unauthorized(t *testing.T) {
+	mockRepo := &MockRepository{}
+	service := NewService(mockRepo)
+	templateID := uuid.New()
+	startDate := date(2024, 3, 1)
+
+	mockRepo.On("GetTemplateByID", mock.Anything, templateID).Return(&BudgetTemplate{ID: templateID, UserID: uuid.New()}, nil)
+
+	result, err := service.CreateBudgetFromTemplate(context.Background(), uuid.New(), templateID, &CopyBudgetRequest{StartDate: &startDate})
+
+	assert.Error(t, err)
+	assert.Nil(t, result)
+}
+
+func TestSuggestBudget(t *testing.T) {
+	mockRepo := &MockRepository{}
+	service := NewService(mockRepo)
+	userID := uuid.New()
+	groceriesID := uuid.New()
+	transportID := uuid.New()
+
+	mockRepo.On("GetSpendingByCategory", mock.Anything, []uuid.UUID{userID}, mock.Anything, mock.Anything).
+		Return(map[uuid.UUID]float64{groceriesID: 1200.5, transportID: 300}, nil)
+
+	suggestion, err := service.SuggestBudget(context.Background(), userID, 3)
+
+	assert.NoError(t, err)
+	assert.Equal(t, []SuggestedAllocation{
+		{CategoryID: groceriesID, AverageSpent: 400.17, AllocatedAmount: 401},
+		{CategoryID: transportID, AverageSpent: 100, AllocatedAmount: 100},
+	}, suggestion.Categories)
+	assert.Equal(t, 501.0, suggestion.TotalAmount)
+	// Three complete months ending before the current one
+	assert.Equal(t, 1, suggestion.From.Day())
+	assert.Equal(t, suggestion.From.AddDate(0, 3, -1), suggestion.To)
+	assert.True(t, suggestion.To.Before(time.Now()))
+
+	_, err = service.SuggestBudget(context.Background(), userID, 0)
+	assert.Error(t, err)
+}
+
+func TestShiftBudgetDates(t *testing.T) {
+	endDate := date(2024, 1, 14)
+	custom := &Budget{PeriodType: PeriodTypeCustom, StartDate: date(2024, 1, 1), EndDate: &endDate}
+
+	start, end := shiftBudgetDates(custom, nil, nil)
+	assert.Equal(t, date(2024, 1, 15), start)
+	assert.Equal(t, date(2024, 1, 28), *end)
+
+	open := &Budget{PeriodType: PeriodTypeQuarterly, StartDate: date(2024, 1, 1)}
+	start, end = shiftBudgetDates(open, nil, nil)
+	assert.Equal(t, date(2024, 4, 1), start)
+	assert.Nil(t, end)
+}
@@ -0,0 +1,223 @@
+package budget
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/google/uuid"
+
+	"fiscaflow/internal/domain/notification"
+)
+
+const (
+	// MaxAlertRules is the maximum number of alert rules of a category or budget
+	MaxAlertRules = 10
+
+	// maxAlertThreshold bounds thresholds to spending five times the allocation
+	maxAlertThreshold = 5.0
+
+	// criticalThreshold is the spending level that is critical when no rules are set
+	criticalThreshold = 0.9
+)
+
+// ErrInvalidAlertRules is returned when alert rules are malformed
+var ErrInvalidAlertRules = errors.New("invalid alert rules")
+
+// severityRank orders severities, most severe first
+var severityRank = map[AlertSeverity]int{
+	AlertSeverityCritical: 0,
+	AlertSeverityWarning:  1,
+	AlertSeverityInfo:     2,
+}
+
+// Value stores alert rules as JSON
+func (r AlertRules) Value() (driver.Value, error) {
+	if r == nil {
+		return nil, nil
+	}
+	encoded, err := json.Marshal(r)
+	if err != nil {
+		return nil, err
+	}
+	return string(encoded), nil
+}
+
+// Scan reads alert rules stored as JSON
+func (r *AlertRules) Scan(value interface{}) error {
+	var data []byte
+	switch v := value.(type) {
+	case nil:
+		*r = nil
+		return nil
+	case []byte:
+		data = v
+	case string:
+		data = []byte(v)
+	default:
+		return fmt.Errorf("cannot scan %T into alert rules", value)
+	}
+	return json.Unmarshal(data, r)
+}
+
+// validateAlertRules checks alert rules and sorts them by threshold
+func validateAlertRules(rules AlertRules) error {
+	if len(rules) > MaxAlertRules {
+		return fmt.Errorf("%w: at most %d rules are allowed", ErrInvalidAlertRules, MaxAlertRules)
+	}
+
+	seen := make(map[float64]bool, len(rules))
+	for _, rule := range rules {
+		if rule.Threshold <= 0 || rule.Threshold > maxAlertThreshold {
+			return fmt.Errorf("%w: threshold must be between 0 and %.0f", ErrInvalidAlertRules, maxAlertThreshold)
+		}
+		if seen[rule.Threshold] {
+			return fmt.Errorf("%w: duplicate threshold %.2f", ErrInvalidAlertRules, rule.Threshold)
+		}
+		seen[rule.Threshold] = true
+
+		if _, ok := severityRank[rule.Severity]; !ok {
+			return fmt.Errorf("%w: unknown severity %q", ErrInvalidAlertRules, rule.Severity)
+		}
+		for _, channel := range rule.Channels {
+			switch channel {
+			case notification.ChannelEmail, notification.ChannelWebhook, notification.ChannelInApp:
+			default:
+				return fmt.Errorf("%w: unknown channel %q", ErrInvalidAlertRules, channel)
+			}
+		}
+	}
+
+	sort.Slice(rules, func(i, j int) bool { return rules[i].Threshold < rules[j].Threshold })
+	return nil
+}
+
+// categoryAlertRules returns the alert rules of a category. Categories without rules warn at
+// their alert threshold, are critical at 90% and when over budget
+func categoryAlertRules(category BudgetCategory) AlertRules {
+	if len(category.AlertRules) > 0 {
+		return category.AlertRules
+	}
+
+	rules := AlertRules{{Threshold: category.AlertThreshold, Severity: AlertSeverityWarning}}
+	if category.AlertThreshold < criticalThreshold {
+		rules = append(rules, AlertRule{Threshold: criticalThreshold, Severity: AlertSeverityCritical})
+	}
+	if category.AlertThreshold < 1 {
+		rules = append(rules, AlertRule{Threshold: 1, Severity: AlertSeverityCritical})
+	}
+	return rules
+}
+
+// highestRuleReached returns the rule with the highest threshold the spending has reached
+func highestRuleReached(rules AlertRules, spent, allocated float64) (AlertRule, bool) {
+	var reached AlertRule
+	found := false
+	for _, rule := range rules {
+		if rule.Threshold > 0 && spent >= allocated*rule.Threshold && (!found || rule.Threshold > reached.Threshold) {
+			reached, found = rule, true
+		}
+	}
+	return reached, found
+}
+
+// generateAlerts raises the alerts of a budget and its categories.
+//
+// Only the highest threshold reached raises an alert, so a category at 95% with rules at
+// 50%, 75% and 90% has a single alert for 90%. Reaching a threshold of 100% or more is
+// reported as "over_budget", lower thresholds by their severity. Budget-wide rules are
+// evaluated on the total spending against the budget's TotalAmount.
+//
+// Alerts are ordered by severity, critical first, then by spending relative to the
+// allocation, highest first, so the most urgent alert always comes first
+func generateAlerts(budget *Budget, categories []BudgetCategory) []BudgetAlert {
+	alerts := []BudgetAlert{}
+
+	var totalSpent float64
+	for _, category := range categories {
+		totalSpent += category.SpentAmount
+
+		// Amounts carried over from the previous period add to the allocation
+		allocated := category.AllocatedAmount + category.RolloverAmount
+		if allocated <= 0 {
+			continue
+		}
+
+		rule, ok := highestRuleReached(categoryAlertRules(category), category.SpentAmount, allocated)
+		if !ok {
+			continue
+		}
+		alerts = append(alerts, newAlert(AlertScopeCategory, category.CategoryID, rule, category.SpentAmount, allocated))
+	}
+
+	if budget != nil && budget.TotalAmount > 0 {
+		if rule, ok := highestRuleReached(budget.AlertRules, totalSpent, budget.TotalAmount); ok {
+			alerts = append(alerts, newAlert(AlertScopeBudget, uuid.Nil, rule, totalSpent, budget.TotalAmount))
+		}
+	}
+
+	sortAlerts(alerts)
+	return alerts
+}
+
+// newAlert builds the alert raised by a rule
+func newAlert(scope AlertScope, categoryID uuid.UUID, rule AlertRule, spent, allocated float64) BudgetAlert {
+	subject := "this category"
+	if scope == AlertScopeBudget {
+		subject = "this budget"
+	}
+
+	alert := BudgetAlert{
+		CategoryID:      categoryID,
+		AllocatedAmount: allocated,
+		SpentAmount:     spent,
+		Threshold:       rule.Threshold,
+		AlertType:       string(rule.Severity),
+		Scope:           scope,
+		Severity:        rule.Severity,
+		Channels:        rule.Channels,
+	}
+
+	ratio := spent / allocated
+	switch {
+	case spent > allocated && rule.Threshold >= 1:
+		alert.AlertType = "over_budget"
+		alert.Message = fmt.Sprintf("You've exceeded your budget for %s by $%.2f (%.1f%% used)", subject, spent-allocated, ratio*100)
+	case rule.Threshold >= 1:
+		alert.AlertType = "over_budget"
+		alert.Message = fmt.Sprintf("You've used all of your budget for %s", subject)
+	case rule.Severity == AlertSeverityCritical:
+		alert.Message = fmt.Sprintf("You're approaching your budget limit for %s (%.1f%% used)", subject, ratio*100)
+	default:
+		alert.Message = fmt.Sprintf("You've used %.1f%% of your budget for %s", ratio*100, subject)
+	}
+	return alert
+}
+
+// sortAlerts orders alerts by severity, then by spending relative to the allocation
+func sortAlerts(alerts []BudgetAlert) {
+	sort.SliceStable(alerts, func(i, j int) bool {
+		ri, rj := alertSeverityRank(alerts[i]), alertSeverityRank(alerts[j])
+		if ri != rj {
+			return ri < rj
+		}
+		return spendingRatio(alerts[i]) > spendingRatio(alerts[j])
+	})
+}
+
+// alertSeverityRank ranks an alert, alerts without a severity rank as warnings
+func alertSeverityRank(alert BudgetAlert) int {
+	if rank, ok := severityRank[alert.Severity]; ok {
+		return rank
+	}
+	return severityRank[AlertSeverityWarning]
+}
+
+func spendingRatio(alert BudgetAlert) float64 {
+	if alert.AllocatedAmount <= 0 {
+		return 0
+	}
+	return alert.SpentAmount / alert.AllocatedAmount
+}
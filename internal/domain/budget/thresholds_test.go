@@ -0,0 +1,145 @@
+package budget
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"fiscaflow/internal/domain/notification"
+)
+
+func TestGenerateAlerts_DefaultRules(t *testing.T) {
+	groceries := uuid.New()
+	dining := uuid.New()
+	rent := uuid.New()
+	categories := []BudgetCategory{
+		{CategoryID: groceries, AllocatedAmount: 100, SpentAmount: 95, AlertThreshold: 0.8},
+		{CategoryID: dining, AllocatedAmount: 100, SpentAmount: 82, AlertThreshold: 0.8},
+		{CategoryID: rent, AllocatedAmount: 100, SpentAmount: 120, AlertThreshold: 0.8},
+		{CategoryID: uuid.New(), AllocatedAmount: 100, SpentAmount: 50, AlertThreshold: 0.8},
+	}
+
+	alerts := generateAlerts(&Budget{TotalAmount: 1000}, categories)
+
+	require.Len(t, alerts, 3)
+	// Over budget first, then critical, then warnings
+	assert.Equal(t, rent, alerts[0].CategoryID)
+	assert.Equal(t, "over_budget", alerts[0].AlertType)
+	assert.Equal(t, groceries, alerts[1].CategoryID)
+	assert.Equal(t, "critical", alerts[1].AlertType)
+	assert.Equal(t, 0.9, alerts[1].Threshold)
+	assert.Equal(t, dining, alerts[2].CategoryID)
+	assert.Equal(t, "warning", alerts[2].AlertType)
+	assert.Equal(t, AlertScopeCategory, alerts[2].Scope)
+}
+
+func TestGenerateAlerts_CustomRules(t *testing.T) {
+	rules := AlertRules{
+		{Threshold: 0.5, Severity: AlertSeverityInfo},
+		{Threshold: 0.75, Severity: AlertSeverityWarning},
+		{Threshold: 0.9, Severity: AlertSeverityWarning, Channels: []string{notification.ChannelInApp}},
+		{Threshold: 1, Severity: AlertSeverityCritical},
+		{Threshold: 1.2, Severity: AlertSeverityCritical, Channels: []string{notification.ChannelEmail}},
+	}
+
+	tests := []struct {
+		name      string
+		spent     float64
+		threshold float64
+		alertType string
+	}{
+		{name: "below every threshold", spent: 40},
+		{name: "informational", spent: 60, threshold: 0.5, alertType: "info"},
+		{name: "highest reached only", spent: 95, threshold: 0.9, alertType: "warning"},
+		{name: "fully spent", spent: 100, threshold: 1, alertType: "over_budget"},
+		{name: "far over budget", spent: 130, threshold: 1.2, alertType: "over_budget"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			categories := []BudgetCategory{
+				{CategoryID: uuid.New(), AllocatedAmount: 100, SpentAmount: tt.spent, AlertThreshold: 0.8, AlertRules: rules},
+			}
+
+			alerts := generateAlerts(&Budget{TotalAmount: 1000}, categories)
+
+			if tt.alertType == "" {
+				assert.Empty(t, alerts)
+				return
+			}
+			require.Len(t, alerts, 1)
+			assert.Equal(t, tt.threshold, alerts[0].Threshold)
+			assert.Equal(t, tt.alertType, alerts[0].AlertType)
+		})
+	}
+
+	alerts := generateAlerts(nil, []BudgetCategory{{AllocatedAmount: 100, SpentAmount: 125, AlertRules: rules}})
+	assert.Equal(t, []string{notification.ChannelEmail}, alerts[0].Channels)
+}
+
+func TestGenerateAlerts_BudgetRules(t *testing.T) {
+	budget := &Budget{
+		TotalAmount: 500,
+		AlertRules:  AlertRules{{Threshold: 0.75, Severity: AlertSeverityWarning}, {Threshold: 1, Severity: AlertSeverityCritical}},
+	}
+	categories := []BudgetCategory{
+		{CategoryID: uuid.New(), AllocatedAmount: 400, SpentAmount: 260, AlertThreshold: 0.8},
+		{CategoryID: uuid.New(), AllocatedAmount: 400, SpentAmount: 260, AlertThreshold: 0.8},
+	}
+
+	alerts := generateAlerts(budget, categories)
+
+	// The categories are within their limits but the budget as a whole is not
+	require.Len(t, alerts, 1)
+	assert.Equal(t, AlertScopeBudget, alerts[0].Scope)
+	assert.Equal(t, uuid.Nil, alerts[0].CategoryID)
+	assert.Equal(t, "over_budget", alerts[0].AlertType)
+	assert.Equal(t, 520.0, alerts[0].SpentAmount)
+	assert.Contains(t, alerts[0].Message, "this budget")
+}
+
+func TestValidateAlertRules(t *testing.T) {
+	tooMany := make(AlertRules, MaxAlertRules+1)
+	for i := range tooMany {
+		tooMany[i] = AlertRule{Threshold: float64(i+1) / 10, Severity: AlertSeverityInfo}
+	}
+
+	tests := []struct {
+		name  string
+		rules AlertRules
+	}{
+		{name: "too many rules", rules: tooMany},
+		{name: "zero threshold", rules: AlertRules{{Threshold: 0, Severity: AlertSeverityInfo}}},
+		{name: "threshold too high", rules: AlertRules{{Threshold: 6, Severity: AlertSeverityInfo}}},
+		{name: "duplicate threshold", rules: AlertRules{{Threshold: 0.5, Severity: AlertSeverityInfo}, {Threshold: 0.5, Severity: AlertSeverityWarning}}},
+		{name: "unknown severity", rules: AlertRules{{Threshold: 0.5, Severity: "urgent"}}},
+		{name: "unknown channel", rules: AlertRules{{Threshold: 0.5, Severity: AlertSeverityInfo, Channels: []string{"sms"}}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.ErrorIs(t, validateAlertRules(tt.rules), ErrInvalidAlertRules)
+		})
+	}
+
+	rules := AlertRules{{Threshold: 1.2, Severity: AlertSeverityCritical}, {Threshold: 0.5, Severity: AlertSeverityInfo}}
+	assert.NoError(t, validateAlertRules(rules))
+	assert.Equal(t, 0.5, rules[0].Threshold)
+	assert.NoError(t, validateAlertRules(nil))
+}
+
+func TestAlertRules_ValueScan(t *testing.T) {
+	rules := AlertRules{{Threshold: 0.75, Severity: AlertSeverityWarning, Channels: []string{notification.ChannelWebhook}}}
+
+	value, err := rules.Value()
+	require.NoError(t, err)
+
+	var scanned AlertRules
+	require.NoError(t, scanned.Scan([]byte(value.(string))))
+	assert.Equal(t, rules, scanned)
+
+	require.NoError(t, scanned.Scan(nil))
+	assert.Nil(t, scanned)
+}
@@ -0,0 +1,67 @@
+package notification
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Recipient holds where a user's notifications are delivered
+type Recipient struct {
+	UserID      uuid.UUID
+	Email       string
+	Preferences *Preferences
+}
+
+// Channel delivers notifications over a single transport
+type Channel interface {
+	// Name returns the name of the channel, e.g. "email"
+	Name() string
+	// Enabled reports whether the user wants notifications over the channel
+	Enabled(preferences *Preferences) bool
+	// Deliver sends a message to the recipient
+	Deliver(ctx context.Context, recipient *Recipient, message *Message) error
+}
+
+// inboxChannel stores notifications in the user's in-app inbox
+type inboxChannel struct {
+	repo Repository
+}
+
+// NewInboxChannel creates a channel delivering to the in-app inbox
+func NewInboxChannel(repo Repository) Channel {
+	return &inboxChannel{repo: repo}
+}
+
+// Name returns the name of the channel
+func (c *inboxChannel) Name() string {
+	return ChannelInApp
+}
+
+// Enabled reports whether the user wants in-app notifications
+func (c *inboxChannel) Enabled(preferences *Preferences) bool {
+	return preferences.InAppEnabled
+}
+
+// Deliver stores the message in the recipient's inbox
+func (c *inboxChannel) Deliver(ctx context.Context, recipient *Recipient, message *Message) error {
+	data := "{}"
+	if len(message.Data) > 0 {
+		encoded, err := json.Marshal(message.Data)
+		if err != nil {
+			return err
+		}
+		data = string(encoded)
+	}
+
+	return c.repo.CreateNotification(ctx, &Notification{
+		UserID:    recipient.UserID,
+		Type:      message.Type,
+		Title:     message.Title,
+		Body:      message.Body,
+		Data:      data,
+		CreatedAt: time.Now(),
+	})
+}
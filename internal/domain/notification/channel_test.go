@@ -0,0 +1,286 @@
+package notification
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// smtpStandIn is a minimal SMTP server accepting every message sent to it
+type smtpStandIn struct {
+	listener net.Listener
+	messages chan smtpMessage
+}
+
+type smtpMessage struct {
+	from string
+	to   []string
+	data string
+}
+
+func newSMTPStandIn(t *testing.T) *smtpStandIn {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { listener.Close() })
+
+	server := &smtpStandIn{listener: listener, messages: make(chan smtpMessage, 10)}
+	go server.serve()
+	return server
+}
+
+func (s *smtpStandIn) config() SMTPConfig {
+	addr := s.listener.Addr().(*net.TCPAddr)
+	return SMTPConfig{Host: addr.IP.String(), Port: addr.Port, From: "notifications@fiscaflow.local"}
+}
+
+func (s *smtpStandIn) serve() {
+	for {
+		conn, err := s.listener.Accept()
+		if err != nil {
+			return
+		}
+		go s.handle(conn)
+	}
+}
+
+func (s *smtpStandIn) handle(conn net.Conn) {
+	defer conn.Close()
+	reader := bufio.NewReader(conn)
+	reply := func(line string) { _, _ = io.WriteString(conn, line+"\r\n") }
+
+	var message smtpMessage
+	reply("220 localhost ESMTP")
+	for {
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			return
+		}
+		command := strings.TrimSpace(line)
+		switch upper := strings.ToUpper(command); {
+		case strings.HasPrefix(upper, "EHLO"), strings.HasPrefix(upper, "HELO"):
+			reply("250 localhost")
+		case strings.HasPrefix(upper, "MAIL FROM:"):
+			message.from = strings.Trim(command[len("MAIL FROM:"):], "<>")
+			reply("250 OK")
+		case strings.HasPrefix(upper, "RCPT TO:"):
+			message.to = append(message.to, strings.Trim(command[len("RCPT TO:"):], "<>"))
+			reply("250 OK")
+		case upper == "DATA":
+			reply("354 End data with <CR><LF>.<CR><LF>")
+			var data strings.Builder
+			for {
+				line, err := reader.ReadString('\n')
+				if err != nil {
+					return
+				}
+				if line == ".\r\n" {
+					break
+				}
+				data.WriteString(line)
+			}
+			message.data = data.String()
+			s.messages <- message
+			message = smtpMessage{}
+			reply("250 OK")
+		case upper == "QUIT":
+			reply("221 Bye")
+			return
+		default:
+			reply("250 OK")
+		}
+	}
+}
+
+func TestEmailChannel_Deliver(t *testing.T) {
+	server := newSMTPStandIn(t)
+	channel := NewEmailChannel(server.config())
+
+	err := channel.Deliver(context.Background(), &Recipient{UserID: uuid.New(), Email: "jane@example.com"}, &Message{
+		Type:  "budget_alert",
+		Title: "Budget alert: Groceries",
+		Body:  "You've spent 80% of your budget\nfor this category",
+	})
+	require.NoError(t, err)
+
+	select {
+	case message := <-server.messages:
+		assert.Equal(t, "notifications@fiscaflow.local", message.from)
+		assert.Equal(t, []string{"jane@example.com"}, message.to)
+		assert.Contains(t, message.data, "To: jane@example.com\r\n")
+		assert.Contains(t, message.data, "Subject: Budget alert: Groceries\r\n")
+		assert.Contains(t, message.data, "\r\n\r\nYou've spent 80% of your budget\r\nfor this category\r\n")
+	case <-time.After(5 * time.Second):
+		t.Fatal("no email received")
+	}
+}
+
+func TestEmailChannel_Unreachable(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	port := listener.Addr().(*net.TCPAddr).Port
+	listener.Close()
+
+	channel := NewEmailChannel(SMTPConfig{Host: "127.0.0.1", Port: port, From: "notifications@fiscaflow.local"})
+	err = channel.Deliver(context.Background(), &Recipient{Email: "jane@example.com"}, &Message{Title: "Hello"})
+
+	assert.ErrorContains(t, err, "failed to send email")
+}
+
+func TestEmailChannel_ContextCanceled(t *testing.T) {
+	// A server accepting connections without ever greeting the client
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer listener.Close()
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			defer conn.Close()
+		}
+	}()
+
+	addr := listener.Addr().(*net.TCPAddr)
+	channel := NewEmailChannel(SMTPConfig{Host: addr.IP.String(), Port: addr.Port, From: "notifications@fiscaflow.local"})
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	err = channel.Deliver(ctx, &Recipient{Email: "jane@example.com"}, &Message{Title: "Hello"})
+
+	assert.ErrorContains(t, err, "failed to send email")
+	assert.Less(t, time.Since(start), 5*time.Second)
+}
+
+func TestBuildEmail_EncodesSubject(t *testing.T) {
+	email := string(buildEmail("a@example.com", "b@example.com", &Message{Title: "Budget € alert"}, time.Now()))
+
+	assert.Contains(t, email, "Subject: =?utf-8?q?Budget_=E2=82=AC_alert?=\r\n")
+}
+
+func TestWebhookChannel_Deliver(t *testing.T) {
+	userID := uuid.New()
+	var received webhookPayload
+	var signature string
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		signature = r.Header.Get(SignatureHeader)
+		assert.Equal(t, "sha256="+sign(body, "s3cret"), signature)
+		assert.NoError(t, json.Unmarshal(body, &received))
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	channel := &webhookChannel{client: server.Client()}
+	preferences := &Preferences{WebhookEnabled: true, WebhookURL: server.URL, WebhookSecret: "s3cret"}
+	err := channel.Deliver(context.Background(), &Recipient{UserID: userID, Preferences: preferences}, &Message{
+		Type:  "budget_alert",
+		Title: "Budget alert",
+		Data:  map[string]interface{}{"threshold": 0.8},
+	})
+
+	assert.NoError(t, err)
+	assert.NotEmpty(t, signature)
+	assert.Equal(t, userID, received.UserID)
+	assert.Equal(t, "budget_alert", received.Type)
+	assert.Equal(t, 0.8, received.Data["threshold"])
+}
+
+func TestWebhookChannel_ErrorStatus(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Empty(t, r.Header.Get(SignatureHeader))
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer server.Close()
+
+	channel := &webhookChannel{client: server.Client()}
+	preferences := &Preferences{WebhookEnabled: true, WebhookURL: server.URL}
+	err := channel.Deliver(context.Background(), &Recipient{Preferences: preferences}, &Message{Type: "budget_alert"})
+
+	assert.ErrorContains(t, err, "status "+strconv.Itoa(http.StatusBadGateway))
+}
+
+func TestWebhookChannel_RequiresHTTPS(t *testing.T) {
+	channel := NewWebhookChannel(time.Second)
+	preferences := &Preferences{WebhookEnabled: true, WebhookURL: "http://example.com/hooks"}
+	err := channel.Deliver(context.Background(), &Recipient{Preferences: preferences}, &Message{Type: "budget_alert"})
+
+	assert.ErrorIs(t, err, ErrInvalidWebhookURL)
+}
+
+func TestWebhookChannel_RejectsInternalAddresses(t *testing.T) {
+	called := false
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		called = true
+	}))
+	defer server.Close()
+
+	channel := NewWebhookChannel(time.Second)
+	for _, url := range []string{server.URL, "https://localhost:" + strconv.Itoa(server.Listener.Addr().(*net.TCPAddr).Port)} {
+		preferences := &Preferences{WebhookEnabled: true, WebhookURL: url}
+		err := channel.Deliver(context.Background(), &Recipient{Preferences: preferences}, &Message{Type: "budget_alert"})
+		assert.ErrorIs(t, err, ErrInvalidWebhookURL, url)
+	}
+	assert.False(t, called)
+}
+
+func TestRejectInternalAddress(t *testing.T) {
+	for _, address := range []string{"127.0.0.1:443", "[::1]:443", "10.0.0.5:443", "192.168.1.1:443", "169.254.169.254:80", "0.0.0.0:443", "[fe80::1]:443"} {
+		assert.ErrorIs(t, rejectInternalAddress("tcp", address, nil), ErrInvalidWebhookURL, address)
+	}
+	assert.NoError(t, rejectInternalAddress("tcp", "93.184.216.34:443", nil))
+}
+
+func TestWebhookChannel_DoesNotFollowRedirects(t *testing.T) {
+	redirected := false
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/internal" {
+			redirected = true
+			return
+		}
+		http.Redirect(w, r, "/internal", http.StatusTemporaryRedirect)
+	}))
+	defer server.Close()
+
+	// Only the redirect policy of the guarded client is under test here
+	client := server.Client()
+	client.CheckRedirect = NewWebhookChannel(time.Second).(*webhookChannel).client.CheckRedirect
+	channel := &webhookChannel{client: client}
+	preferences := &Preferences{WebhookEnabled: true, WebhookURL: server.URL + "/hooks"}
+	err := channel.Deliver(context.Background(), &Recipient{Preferences: preferences}, &Message{Type: "budget_alert"})
+
+	assert.ErrorContains(t, err, "status "+strconv.Itoa(http.StatusTemporaryRedirect))
+	assert.False(t, redirected)
+}
+
+func TestInboxChannel_Deliver(t *testing.T) {
+	mockRepo := &MockRepository{}
+	channel := NewInboxChannel(mockRepo)
+	userID := uuid.New()
+
+	mockRepo.On("CreateNotification", mock.Anything, mock.MatchedBy(func(n *Notification) bool {
+		return n.UserID == userID && n.Type == "budget_alert" && n.Data == `{"alert_type":"over_budget"}`
+	})).Return(nil)
+
+	err := channel.Deliver(context.Background(), &Recipient{UserID: userID}, &Message{
+		Type: "budget_alert",
+		Data: map[string]interface{}{"alert_type": "over_budget"},
+	})
+
+	assert.NoError(t, err)
+	mockRepo.AssertExpectations(t)
+}
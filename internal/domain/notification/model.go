@@ -0,0 +1,98 @@
+package notification
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Delivery channel names
+const (
+	ChannelEmail   = "email"
+	ChannelWebhook = "webhook"
+	ChannelInApp   = "in_app"
+)
+
+// Message is a notification to deliver to a user over their enabled channels
+type Message struct {
+	Type  string                 `json:"type"` // e.g. "budget_alert"
+	Title string                 `json:"title"`
+	Body  string                 `json:"body"`
+	Data  map[string]interface{} `json:"data,omitempty"`
+
+	// Channels restricts delivery to the given channels, all enabled channels when empty
+	Channels []string `json:"channels,omitempty"`
+}
+
+// DeliversOver reports whether the message is to be delivered over a channel
+func (m *Message) DeliversOver(channel string) bool {
+	if len(m.Channels) == 0 {
+		return true
+	}
+	for _, name := range m.Channels {
+		if name == channel {
+			return true
+		}
+	}
+	return false
+}
+
+// Notification represents a notification in a user's in-app inbox
+type Notification struct {
+	ID        uuid.UUID  `json:"id" gorm:"type:uuid;primary_key;default:gen_random_uuid()"`
+	UserID    uuid.UUID  `json:"user_id" gorm:"type:uuid;not null;index"`
+	Type      string     `json:"type" gorm:"not null"`
+	Title     string     `json:"title" gorm:"not null"`
+	Body      string     `json:"body"`
+	Data      string     `json:"data" gorm:"type:jsonb;default:'{}'"`
+	ReadAt    *time.Time `json:"read_at"`
+	CreatedAt time.Time  `json:"created_at"`
+}
+
+// Preferences holds how a user wants to be notified
+type Preferences struct {
+	UserID         uuid.UUID `json:"user_id" gorm:"type:uuid;primary_key"`
+	InAppEnabled   bool      `json:"in_app_enabled" gorm:"not null"`
+	EmailEnabled   bool      `json:"email_enabled" gorm:"not null"` // Emails go to the verified account email
+	WebhookEnabled bool      `json:"webhook_enabled" gorm:"not null"`
+	WebhookURL     string    `json:"webhook_url"`
+	WebhookSecret  string    `json:"-"` // Used to sign webhook payloads
+	MutedTypes     []string  `json:"muted_types" gorm:"type:text[]"`
+	CreatedAt      time.Time `json:"created_at"`
+	UpdatedAt      time.Time `json:"updated_at"`
+}
+
+// UpdatePreferencesRequest represents the request to update notification preferences
+type UpdatePreferencesRequest struct {
+	InAppEnabled   *bool    `json:"in_app_enabled"`
+	EmailEnabled   *bool    `json:"email_enabled"`
+	WebhookEnabled *bool    `json:"webhook_enabled"`
+	WebhookURL     *string  `json:"webhook_url" binding:"omitempty,url"`
+	WebhookSecret  *string  `json:"webhook_secret"`
+	MutedTypes     []string `json:"muted_types"`
+}
+
+// DefaultPreferences returns the preferences of a user who has not changed them
+func DefaultPreferences(userID uuid.UUID) *Preferences {
+	return &Preferences{UserID: userID, InAppEnabled: true, MutedTypes: []string{}}
+}
+
+// IsMuted reports whether the user has muted a type of notification
+func (p *Preferences) IsMuted(notificationType string) bool {
+	for _, muted := range p.MutedTypes {
+		if muted == notificationType {
+			return true
+		}
+	}
+	return false
+}
+
+// TableName specifies the table name for Notification
+func (Notification) TableName() string {
+	return "notifications"
+}
+
+// TableName specifies the table name for Preferences
+func (Preferences) TableName() string {
+	return "notification_preferences"
+}
@@ -0,0 +1,142 @@
+package notification
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Repository defines the interface for notification data operations
+type Repository interface {
+	// Inbox operations
+	CreateNotification(ctx context.Context, notification *Notification) error
+	GetNotifications(ctx context.Context, userID uuid.UUID, unreadOnly bool, offset, limit int) ([]Notification, error)
+	MarkAsRead(ctx context.Context, userID, notificationID uuid.UUID) error
+	MarkAllAsRead(ctx context.Context, userID uuid.UUID) error
+
+	// Preference operations
+	GetPreferences(ctx context.Context, userID uuid.UUID) (*Preferences, error)
+	SavePreferences(ctx context.Context, preferences *Preferences) error
+	GetUserEmail(ctx context.Context, userID uuid.UUID) (string, error)
+}
+
+// Common errors
+var (
+	ErrNotificationNotFound = errors.New("notification not found")
+	ErrPreferencesNotFound  = errors.New("notification preferences not found")
+)
+
+// repository implements the Repository interface
+type repository struct {
+	db *gorm.DB
+}
+
+// NewRepository creates a new notification repository
+func NewRepository(db *gorm.DB) Repository {
+	return &repository{db: db}
+}
+
+// CreateNotification stores a notification in the user's inbox
+func (r *repository) CreateNotification(ctx context.Context, notification *Notification) error {
+	if err := r.db.WithContext(ctx).Create(notification).Error; err != nil {
+		return fmt.Errorf("failed to create notification: %w", err)
+	}
+	return nil
+}
+
+// GetNotifications retrieves the notifications in a user's inbox, newest first
+func (r *repository) GetNotifications(ctx context.Context, userID uuid.UUID, unreadOnly bool, offset, limit int) ([]Notification, error) {
+	query := r.db.WithContext(ctx).Where("user_id = ?", userID)
+	if unreadOnly {
+		query = query.Where("read_at IS NULL")
+	}
+
+	var notifications []Notification
+	err := query.
+		Order("created_at DESC").
+		Offset(offset).
+		Limit(limit).
+		Find(&notifications).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to get notifications: %w", err)
+	}
+
+	return notifications, nil
+}
+
+// MarkAsRead marks a notification in a user's inbox as read
+func (r *repository) MarkAsRead(ctx context.Context, userID, notificationID uuid.UUID) error {
+	result := r.db.WithContext(ctx).
+		Model(&Notification{}).
+		Where("id = ? AND user_id = ? AND read_at IS NULL", notificationID, userID).
+		Update("read_at", time.Now())
+	if result.Error != nil {
+		return fmt.Errorf("failed to mark notification as read: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		var count int64
+		if err := r.db.WithContext(ctx).Model(&Notification{}).
+			Where("id = ? AND user_id = ?", notificationID, userID).
+			Count(&count).Error; err != nil {
+			return fmt.Errorf("failed to get notification: %w", err)
+		}
+		if count == 0 {
+			return ErrNotificationNotFound
+		}
+	}
+	return nil
+}
+
+// MarkAllAsRead marks every notification in a user's inbox as read
+func (r *repository) MarkAllAsRead(ctx context.Context, userID uuid.UUID) error {
+	err := r.db.WithContext(ctx).
+		Model(&Notification{}).
+		Where("user_id = ? AND read_at IS NULL", userID).
+		Update("read_at", time.Now()).Error
+	if err != nil {
+		return fmt.Errorf("failed to mark notifications as read: %w", err)
+	}
+	return nil
+}
+
+// GetPreferences retrieves a user's notification preferences
+func (r *repository) GetPreferences(ctx context.Context, userID uuid.UUID) (*Preferences, error) {
+	var preferences Preferences
+	err := r.db.WithContext(ctx).Where("user_id = ?", userID).First(&preferences).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrPreferencesNotFound
+		}
+		return nil, fmt.Errorf("failed to get notification preferences: %w", err)
+	}
+	return &preferences, nil
+}
+
+// SavePreferences creates or replaces a user's notification preferences
+func (r *repository) SavePreferences(ctx context.Context, preferences *Preferences) error {
+	if err := r.db.WithContext(ctx).Save(preferences).Error; err != nil {
+		return fmt.Errorf("failed to save notification preferences: %w", err)
+	}
+	return nil
+}
+
+// GetUserEmail retrieves the email address of a user's account, or an empty string when the
+// address has not been verified
+func (r *repository) GetUserEmail(ctx context.Context, userID uuid.UUID) (string, error) {
+	var emails []string
+	err := r.db.WithContext(ctx).
+		Table("users").
+		Where("id = ? AND email_verified = ?", userID, true).
+		Pluck("email", &emails).Error
+	if err != nil {
+		return "", fmt.Errorf("failed to get user email: %w", err)
+	}
+	if len(emails) == 0 {
+		return "", nil
+	}
+	return emails[0], nil
+}
@@ -0,0 +1,221 @@
+package notification
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Service defines the interface for notification business logic
+type Service interface {
+	// Delivery
+	Send(ctx context.Context, userID uuid.UUID, message *Message) error
+
+	// Preferences
+	GetPreferences(ctx context.Context, userID uuid.UUID) (*Preferences, error)
+	UpdatePreferences(ctx context.Context, userID uuid.UUID, req *UpdatePreferencesRequest) (*Preferences, error)
+
+	// In-app inbox
+	ListNotifications(ctx context.Context, userID uuid.UUID, unreadOnly bool, offset, limit int) ([]Notification, error)
+	MarkAsRead(ctx context.Context, userID, notificationID uuid.UUID) error
+	MarkAllAsRead(ctx context.Context, userID uuid.UUID) error
+}
+
+// service implements the Service interface
+type service struct {
+	repo     Repository
+	channels []Channel
+}
+
+// NewService creates a new notification service delivering over the given channels
+func NewService(repo Repository, channels ...Channel) Service {
+	return &service{repo: repo, channels: channels}
+}
+
+// Send delivers a message over every channel the user has enabled, limited to the message's
+// channels when it names any. A failing channel does not prevent delivery over the others
+func (s *service) Send(ctx context.Context, userID uuid.UUID, message *Message) error {
+	ctx, span := otel.Tracer("").Start(ctx, "notification.Send",
+		trace.WithAttributes(
+			attribute.String("user_id", userID.String()),
+			attribute.String("type", message.Type),
+		),
+	)
+	defer span.End()
+
+	preferences, err := s.GetPreferences(ctx, userID)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return err
+	}
+
+	if preferences.IsMuted(message.Type) {
+		span.SetAttributes(attribute.Bool("muted", true))
+		return nil
+	}
+
+	recipient := &Recipient{UserID: userID, Preferences: preferences}
+	var errs []error
+	for _, channel := range s.channels {
+		if !channel.Enabled(preferences) || !message.DeliversOver(channel.Name()) {
+			continue
+		}
+
+		if channel.Name() == ChannelEmail && recipient.Email == "" {
+			if recipient.Email, err = s.repo.GetUserEmail(ctx, userID); err != nil {
+				errs = append(errs, fmt.Errorf("%s: %w", channel.Name(), err))
+				continue
+			}
+			// Emails are only sent once the account address has been verified
+			if recipient.Email == "" {
+				span.SetAttributes(attribute.Bool("email_unverified", true))
+				continue
+			}
+		}
+
+		if err := channel.Deliver(ctx, recipient, message); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", channel.Name(), err))
+		}
+	}
+
+	if err := errors.Join(errs...); err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return fmt.Errorf("failed to deliver notification: %w", err)
+	}
+	return nil
+}
+
+// GetPreferences retrieves a user's notification preferences, falling back to the defaults
+func (s *service) GetPreferences(ctx context.Context, userID uuid.UUID) (*Preferences, error) {
+	preferences, err := s.repo.GetPreferences(ctx, userID)
+	if errors.Is(err, ErrPreferencesNotFound) {
+		return DefaultPreferences(userID), nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return preferences, nil
+}
+
+// UpdatePreferences updates a user's notification preferences
+func (s *service) UpdatePreferences(ctx context.Context, userID uuid.UUID, req *UpdatePreferencesRequest) (*Preferences, error) {
+	ctx, span := otel.Tracer("").Start(ctx, "notification.UpdatePreferences",
+		trace.WithAttributes(attribute.String("user_id", userID.String())),
+	)
+	defer span.End()
+
+	preferences, err := s.GetPreferences(ctx, userID)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, err
+	}
+
+	if req.InAppEnabled != nil {
+		preferences.InAppEnabled = *req.InAppEnabled
+	}
+	if req.EmailEnabled != nil {
+		preferences.EmailEnabled = *req.EmailEnabled
+	}
+	if req.WebhookEnabled != nil {
+		preferences.WebhookEnabled = *req.WebhookEnabled
+	}
+	if req.WebhookURL != nil {
+		preferences.WebhookURL = *req.WebhookURL
+	}
+	if req.WebhookSecret != nil {
+		preferences.WebhookSecret = *req.WebhookSecret
+	}
+	if req.MutedTypes != nil {
+		preferences.MutedTypes = req.MutedTypes
+	}
+
+	if preferences.WebhookEnabled && preferences.WebhookURL == "" {
+		err := fmt.Errorf("webhook URL is required to enable webhook notifications")
+		span.SetStatus(codes.Error, err.Error())
+		return nil, err
+	}
+	if preferences.WebhookURL != "" {
+		if err := ValidateWebhookURL(preferences.WebhookURL); err != nil {
+			span.SetStatus(codes.Error, err.Error())
+			return nil, err
+		}
+	}
+
+	now := time.Now()
+	if preferences.CreatedAt.IsZero() {
+		preferences.CreatedAt = now
+	}
+	preferences.UpdatedAt = now
+
+	if err := s.repo.SavePreferences(ctx, preferences); err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, err
+	}
+
+	return preferences, nil
+}
+
+// ListNotifications retrieves the notifications in a user's inbox
+func (s *service) ListNotifications(ctx context.Context, userID uuid.UUID, unreadOnly bool, offset, limit int) ([]Notification, error) {
+	ctx, span := otel.Tracer("").Start(ctx, "notification.ListNotifications",
+		trace.WithAttributes(
+			attribute.String("user_id", userID.String()),
+			attribute.Bool("unread_only", unreadOnly),
+		),
+	)
+	defer span.End()
+
+	notifications, err := s.repo.GetNotifications(ctx, userID, unreadOnly, offset, limit)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, err
+	}
+
+	span.SetAttributes(attribute.Int("notifications_count", len(notifications)))
+	return notifications, nil
+}
+
+// MarkAsRead marks a notification in a user's inbox as read
+func (s *service) MarkAsRead(ctx context.Context, userID, notificationID uuid.UUID) error {
+	ctx, span := otel.Tracer("").Start(ctx, "notification.MarkAsRead",
+		trace.WithAttributes(
+			attribute.String("user_id", userID.String()),
+			attribute.String("notification_id", notificationID.String()),
+		),
+	)
+	defer span.End()
+
+	if err := s.repo.MarkAsRead(ctx, userID, notificationID); err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return err
+	}
+	return nil
+}
+
+// MarkAllAsRead marks every notification in a user's inbox as read
+func (s *service) MarkAllAsRead(ctx context.Context, userID uuid.UUID) error {
+	ctx, span := otel.Tracer("").Start(ctx, "notification.MarkAllAsRead",
+		trace.WithAttributes(attribute.String("user_id", userID.String())),
+	)
+	defer span.End()
+
+	if err := s.repo.MarkAllAsRead(ctx, userID); err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,222 @@
+package notification
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// MockRepository is a mock implementation of the Repository interface
+type MockRepository struct {
+	mock.Mock
+}
+
+func (m *MockRepository) CreateNotification(ctx context.Context, notification *Notification) error {
+	args := m.Called(ctx, notification)
+	return args.Error(0)
+}
+
+func (m *MockRepository) GetNotifications(ctx context.Context, userID uuid.UUID, unreadOnly bool, offset, limit int) ([]Notification, error) {
+	args := m.Called(ctx, userID, unreadOnly, offset, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]Notification), args.Error(1)
+}
+
+func (m *MockRepository) MarkAsRead(ctx context.Context, userID, notificationID uuid.UUID) error {
+	args := m.Called(ctx, userID, notificationID)
+	return args.Error(0)
+}
+
+func (m *MockRepository) MarkAllAsRead(ctx context.Context, userID uuid.UUID) error {
+	args := m.Called(ctx, userID)
+	return args.Error(0)
+}
+
+func (m *MockRepository) GetPreferences(ctx context.Context, userID uuid.UUID) (*Preferences, error) {
+	args := m.Called(ctx, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*Preferences), args.Error(1)
+}
+
+func (m *MockRepository) SavePreferences(ctx context.Context, preferences *Preferences) error {
+	args := m.Called(ctx, preferences)
+	return args.Error(0)
+}
+
+func (m *MockRepository) GetUserEmail(ctx context.Context, userID uuid.UUID) (string, error) {
+	args := m.Called(ctx, userID)
+	return args.String(0), args.Error(1)
+}
+
+// recordingChannel records the messages delivered over it
+type recordingChannel struct {
+	name      string
+	enabled   func(*Preferences) bool
+	err       error
+	delivered []*Recipient
+}
+
+func (c *recordingChannel) Name() string {
+	return c.name
+}
+
+func (c *recordingChannel) Enabled(preferences *Preferences) bool {
+	return c.enabled(preferences)
+}
+
+func (c *recordingChannel) Deliver(ctx context.Context, recipient *Recipient, message *Message) error {
+	c.delivered = append(c.delivered, recipient)
+	return c.err
+}
+
+func newRecordingChannels() (*recordingChannel, *recordingChannel, *recordingChannel) {
+	inbox := &recordingChannel{name: ChannelInApp, enabled: func(p *Preferences) bool { return p.InAppEnabled }}
+	email := &recordingChannel{name: ChannelEmail, enabled: func(p *Preferences) bool { return p.EmailEnabled }}
+	webhook := &recordingChannel{name: ChannelWebhook, enabled: func(p *Preferences) bool { return p.WebhookEnabled }}
+	return inbox, email, webhook
+}
+
+func TestSend_DefaultPreferences(t *testing.T) {
+	mockRepo := &MockRepository{}
+	inbox, email, webhook := newRecordingChannels()
+	service := NewService(mockRepo, inbox, email, webhook)
+	userID := uuid.New()
+
+	mockRepo.On("GetPreferences", mock.Anything, userID).Return(nil, ErrPreferencesNotFound)
+
+	err := service.Send(context.Background(), userID, &Message{Type: "budget_alert", Title: "Budget alert"})
+
+	assert.NoError(t, err)
+	// Only the in-app inbox is enabled by default
+	assert.Len(t, inbox.delivered, 1)
+	assert.Empty(t, email.delivered)
+	assert.Empty(t, webhook.delivered)
+}
+
+func TestSend_EmailUsesAccountEmail(t *testing.T) {
+	mockRepo := &MockRepository{}
+	inbox, email, webhook := newRecordingChannels()
+	service := NewService(mockRepo, inbox, email, webhook)
+	userID := uuid.New()
+
+	mockRepo.On("GetPreferences", mock.Anything, userID).
+		Return(&Preferences{UserID: userID, EmailEnabled: true}, nil)
+	mockRepo.On("GetUserEmail", mock.Anything, userID).Return("jane@example.com", nil)
+
+	err := service.Send(context.Background(), userID, &Message{Type: "budget_alert"})
+
+	assert.NoError(t, err)
+	assert.Empty(t, inbox.delivered)
+	assert.Equal(t, "jane@example.com", email.delivered[0].Email)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestSend_EmailUnverified(t *testing.T) {
+	mockRepo := &MockRepository{}
+	inbox, email, webhook := newRecordingChannels()
+	service := NewService(mockRepo, inbox, email, webhook)
+	userID := uuid.New()
+
+	mockRepo.On("GetPreferences", mock.Anything, userID).
+		Return(&Preferences{UserID: userID, InAppEnabled: true, EmailEnabled: true}, nil)
+	mockRepo.On("GetUserEmail", mock.Anything, userID).Return("", nil)
+
+	err := service.Send(context.Background(), userID, &Message{Type: "budget_alert"})
+
+	// Nothing is emailed until the account address is verified
+	assert.NoError(t, err)
+	assert.Len(t, inbox.delivered, 1)
+	assert.Empty(t, email.delivered)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestSend_Muted(t *testing.T) {
+	mockRepo := &MockRepository{}
+	inbox, email, webhook := newRecordingChannels()
+	service := NewService(mockRepo, inbox, email, webhook)
+	userID := uuid.New()
+
+	mockRepo.On("GetPreferences", mock.Anything, userID).
+		Return(&Preferences{UserID: userID, InAppEnabled: true, MutedTypes: []string{"budget_alert"}}, nil)
+
+	err := service.Send(context.Background(), userID, &Message{Type: "budget_alert"})
+
+	assert.NoError(t, err)
+	assert.Empty(t, inbox.delivered)
+}
+
+func TestSend_ChannelFailure(t *testing.T) {
+	mockRepo := &MockRepository{}
+	inbox, email, webhook := newRecordingChannels()
+	webhook.err = errors.New("connection refused")
+	service := NewService(mockRepo, webhook, inbox, email)
+	userID := uuid.New()
+
+	mockRepo.On("GetPreferences", mock.Anything, userID).
+		Return(&Preferences{UserID: userID, InAppEnabled: true, WebhookEnabled: true, WebhookURL: "https://example.com"}, nil)
+
+	err := service.Send(context.Background(), userID, &Message{Type: "budget_alert"})
+
+	// The inbox still receives the message
+	assert.ErrorContains(t, err, "webhook: connection refused")
+	assert.Len(t, inbox.delivered, 1)
+}
+
+func TestUpdatePreferences(t *testing.T) {
+	mockRepo := &MockRepository{}
+	service := NewService(mockRepo)
+	userID := uuid.New()
+	enabled := true
+
+	mockRepo.On("GetPreferences", mock.Anything, userID).Return(nil, ErrPreferencesNotFound)
+
+	_, err := service.UpdatePreferences(context.Background(), userID, &UpdatePreferencesRequest{WebhookEnabled: &enabled})
+	assert.Error(t, err)
+
+	url := "http://example.com/hooks/fiscaflow"
+	_, err = service.UpdatePreferences(context.Background(), userID, &UpdatePreferencesRequest{WebhookEnabled: &enabled, WebhookURL: &url})
+	assert.ErrorIs(t, err, ErrInvalidWebhookURL)
+
+	mockRepo.On("SavePreferences", mock.Anything, mock.AnythingOfType("*notification.Preferences")).Return(nil)
+
+	url = "https://example.com/hooks/fiscaflow"
+	preferences, err := service.UpdatePreferences(context.Background(), userID, &UpdatePreferencesRequest{
+		WebhookEnabled: &enabled,
+		WebhookURL:     &url,
+		MutedTypes:     []string{"weekly_digest"},
+	})
+
+	assert.NoError(t, err)
+	assert.True(t, preferences.InAppEnabled)
+	assert.True(t, preferences.WebhookEnabled)
+	assert.Equal(t, url, preferences.WebhookURL)
+	assert.True(t, preferences.IsMuted("weekly_digest"))
+	mockRepo.AssertExpectations(t)
+}
+
+func TestSend_MessageChannels(t *testing.T) {
+	mockRepo := &MockRepository{}
+	inbox, email, webhook := newRecordingChannels()
+	service := NewService(mockRepo, inbox, email, webhook)
+	userID := uuid.New()
+
+	mockRepo.On("GetPreferences", mock.Anything, userID).
+		Return(&Preferences{UserID: userID, InAppEnabled: true, EmailEnabled: true}, nil)
+	mockRepo.On("GetUserEmail", mock.Anything, userID).Return("jane@example.com", nil)
+
+	err := service.Send(context.Background(), userID, &Message{Type: "budget_alert", Channels: []string{ChannelEmail, ChannelWebhook}})
+
+	// Channels the user has disabled stay disabled
+	assert.NoError(t, err)
+	assert.Empty(t, inbox.delivered)
+	assert.Len(t, email.delivered, 1)
+	assert.Empty(t, webhook.delivered)
+}
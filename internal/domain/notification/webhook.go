@@ -0,0 +1,148 @@
+package notification
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"syscall"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// SignatureHeader carries the HMAC-SHA256 of a webhook payload when the user has a secret
+const SignatureHeader = "X-Fiscaflow-Signature"
+
+// webhookPayload is the JSON body posted to a user's webhook
+type webhookPayload struct {
+	UserID uuid.UUID              `json:"user_id"`
+	Type   string                 `json:"type"`
+	Title  string                 `json:"title"`
+	Body   string                 `json:"body"`
+	Data   map[string]interface{} `json:"data,omitempty"`
+	SentAt time.Time              `json:"sent_at"`
+}
+
+// ErrInvalidWebhookURL is returned for webhook URLs notifications must not be posted to
+var ErrInvalidWebhookURL = errors.New("invalid webhook URL")
+
+// ValidateWebhookURL checks that a webhook URL is an absolute https URL
+func ValidateWebhookURL(rawURL string) error {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrInvalidWebhookURL, err)
+	}
+	if u.Scheme != "https" || u.Hostname() == "" {
+		return fmt.Errorf("%w: must be an https URL", ErrInvalidWebhookURL)
+	}
+	return nil
+}
+
+// webhookChannel posts notifications to a URL chosen by the user
+type webhookChannel struct {
+	client *http.Client
+}
+
+// NewWebhookChannel creates a channel posting notifications to user webhooks. Since the URL
+// is chosen by the user, the addresses it resolves to are checked when dialing so webhooks
+// cannot reach internal services, and redirects are not followed
+func NewWebhookChannel(timeout time.Duration) Channel {
+	dialer := &net.Dialer{Timeout: timeout, Control: rejectInternalAddress}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = nil
+	transport.DialContext = dialer.DialContext
+
+	return &webhookChannel{client: &http.Client{
+		Timeout:   timeout,
+		Transport: transport,
+		CheckRedirect: func(*http.Request, []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}}
+}
+
+// rejectInternalAddress refuses connections to loopback, private, link-local, multicast and
+// unspecified addresses. It runs after the host is resolved, so DNS cannot be used to bypass it
+func rejectInternalAddress(network, address string, _ syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+	ip := net.ParseIP(host)
+	if ip == nil {
+		return fmt.Errorf("%w: unexpected address %s", ErrInvalidWebhookURL, address)
+	}
+	if ip.IsLoopback() || ip.IsPrivate() || ip.IsUnspecified() || ip.IsMulticast() ||
+		ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() || ip.IsInterfaceLocalMulticast() {
+		return fmt.Errorf("%w: %s is not a public address", ErrInvalidWebhookURL, ip)
+	}
+	return nil
+}
+
+// Name returns the name of the channel
+func (c *webhookChannel) Name() string {
+	return ChannelWebhook
+}
+
+// Enabled reports whether the user wants webhook notifications
+func (c *webhookChannel) Enabled(preferences *Preferences) bool {
+	return preferences.WebhookEnabled && preferences.WebhookURL != ""
+}
+
+// Deliver posts the message to the recipient's webhook
+func (c *webhookChannel) Deliver(ctx context.Context, recipient *Recipient, message *Message) error {
+	if recipient.Preferences == nil || recipient.Preferences.WebhookURL == "" {
+		return errors.New("recipient has no webhook URL")
+	}
+	if err := ValidateWebhookURL(recipient.Preferences.WebhookURL); err != nil {
+		return err
+	}
+
+	payload, err := json.Marshal(webhookPayload{
+		UserID: recipient.UserID,
+		Type:   message.Type,
+		Title:  message.Title,
+		Body:   message.Body,
+		Data:   message.Data,
+		SentAt: time.Now().UTC(),
+	})
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, recipient.Preferences.WebhookURL, bytes.NewReader(payload))
+	if err != nil {
+		return fmt.Errorf("invalid webhook URL: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if secret := recipient.Preferences.WebhookSecret; secret != "" {
+		req.Header.Set(SignatureHeader, "sha256="+sign(payload, secret))
+	}
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to call webhook: %w", err)
+	}
+	defer resp.Body.Close()
+
+	// Redirects are not followed, so they are reported like any other non-2xx status
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook responded with status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// sign returns the hex encoded HMAC-SHA256 of a payload
+func sign(payload []byte, secret string) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(payload)
+	return hex.EncodeToString(mac.Sum(nil))
+}
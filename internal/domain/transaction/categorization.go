@@ -0,0 +1,254 @@
+package transaction
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+
+	"fiscaflow/internal/domain/analytics"
+)
+
+// maxCategorizationBatch is the most transactions asked to be categorized at once
+const maxCategorizationBatch = 1000
+
+// GetReviewQueue retrieves a user's transactions whose suggested category awaits review
+func (s *service) GetReviewQueue(ctx context.Context, userID uuid.UUID, offset, limit int) ([]TransactionResponse, error) {
+	ctx, span := otel.Tracer("transaction").Start(ctx, "GetReviewQueue",
+		trace.WithAttributes(
+			attribute.String("user_id", userID.String()),
+			attribute.Int("offset", offset),
+			attribute.Int("limit", limit),
+		),
+	)
+	defer span.End()
+
+	transactions, err := s.repo.GetTransactionsForReview(ctx, userID, offset, limit)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "failed to get transactions for review")
+		return nil, fmt.Errorf("failed to get transactions for review: %w", err)
+	}
+
+	responses := make([]TransactionResponse, len(transactions))
+	for i := range transactions {
+		responses[i] = *s.toTransactionResponse(&transactions[i])
+	}
+
+	span.SetStatus(codes.Ok, "review queue retrieved successfully")
+	return responses, nil
+}
+
+// ReviewCategory accepts the category suggested for a transaction, or replaces it with the
+// category the user chose, which is learned from as a correction
+func (s *service) ReviewCategory(ctx context.Context, userID, transactionID uuid.UUID, req *ReviewCategoryRequest) (*TransactionResponse, error) {
+	ctx, span := otel.Tracer("transaction").Start(ctx, "ReviewCategory",
+		trace.WithAttributes(
+			attribute.String("user_id", userID.String()),
+			attribute.String("transaction_id", transactionID.String()),
+		),
+	)
+	defer span.End()
+
+	transaction, err := s.repo.GetTransactionByID(ctx, transactionID)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "failed to get transaction")
+		return nil, fmt.Errorf("failed to get transaction: %w", err)
+	}
+
+	// Check if transaction belongs to user
+	if transaction.UserID != userID {
+		span.RecordError(errors.New("transaction does not belong to user"))
+		span.SetStatus(codes.Error, "transaction does not belong to user")
+		return nil, errors.New("transaction does not belong to user")
+	}
+
+	if transaction.CategoryID != nil || transaction.SuggestedCategoryID == nil {
+		span.SetStatus(codes.Error, ErrNothingToReview.Error())
+		return nil, ErrNothingToReview
+	}
+
+	categoryID := *transaction.SuggestedCategoryID
+	if req.CategoryID != nil {
+		categoryID = *req.CategoryID
+	}
+	if _, err := s.getVisibleCategory(ctx, userID, categoryID); err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "failed to get category")
+		return nil, fmt.Errorf("failed to get category: %w", err)
+	}
+
+	correction := changeCategory(transaction, categoryID)
+	if err := s.repo.UpdateTransaction(ctx, transaction); err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "failed to update transaction")
+		return nil, fmt.Errorf("failed to update transaction: %w", err)
+	}
+
+	// Failing to learn from a correction doesn't fail the review
+	if err := s.recordCorrection(ctx, userID, transaction, correction); err != nil {
+		span.RecordError(err)
+	}
+
+	s.notifyChange(ctx, userID)
+
+	span.SetStatus(codes.Ok, "category reviewed successfully")
+	return s.toTransactionResponse(transaction), nil
+}
+
+// autoCategorize applies the category suggested for a transaction when the suggestion is
+// confident enough, or keeps it for the user to review. Transactions are still recorded,
+// uncategorized, when no category can be suggested
+func (s *service) autoCategorize(ctx context.Context, userID uuid.UUID, transaction *Transaction) {
+	if s.categorization.Categorizer == nil {
+		return
+	}
+
+	suggestion, err := s.categorization.Categorizer.CategorizeTransaction(ctx, categorizationRequest(userID, transaction))
+	if err != nil {
+		return
+	}
+	s.applySuggestion(ctx, userID, transaction, suggestion)
+}
+
+// autoCategorizeAll categorizes transactions like autoCategorize, asking for the categories
+// of up to maxCategorizationBatch of them at once. Transactions of a batch that fails are
+// left uncategorized
+func (s *service) autoCategorizeAll(ctx context.Context, userID uuid.UUID, transactions []*Transaction) {
+	if s.categorization.Categorizer == nil {
+		return
+	}
+
+	for start := 0; start < len(transactions); start += maxCategorizationBatch {
+		end := start + maxCategorizationBatch
+		if end > len(transactions) {
+			end = len(transactions)
+		}
+		batch := transactions[start:end]
+		req := &analytics.BatchCategorizationRequest{
+			Transactions: make([]analytics.CategorizationRequest, len(batch)),
+			UserID:       &userID,
+		}
+		for i, transaction := range batch {
+			req.Transactions[i] = *categorizationRequest(userID, transaction)
+		}
+
+		resp, err := s.categorization.Categorizer.CategorizeTransactions(ctx, req)
+		if err != nil || len(resp.Categorizations) != len(batch) {
+			continue
+		}
+		for i, transaction := range batch {
+			s.applySuggestion(ctx, userID, transaction, &resp.Categorizations[i])
+		}
+	}
+}
+
+// categorizationRequest returns the request that asks for the category of a transaction
+func categorizationRequest(userID uuid.UUID, transaction *Transaction) *analytics.CategorizationRequest {
+	return &analytics.CategorizationRequest{
+		Description:     transaction.Description,
+		Merchant:        transaction.Merchant,
+		Amount:          transaction.Amount,
+		Location:        transaction.Location,
+		AccountID:       &transaction.AccountID,
+		Currency:        transaction.Currency,
+		TransactionDate: &transaction.TransactionDate,
+		UserID:          &userID,
+	}
+}
+
+// applySuggestion applies or keeps for review the category suggested for a transaction
+func (s *service) applySuggestion(ctx context.Context, userID uuid.UUID, transaction *Transaction, suggestion *analytics.CategorizationResponse) {
+	if suggestion == nil || suggestion.CategoryID == uuid.Nil {
+		return
+	}
+
+	// Suggestions may come from categories the user can't see
+	if _, err := s.getVisibleCategory(ctx, userID, suggestion.CategoryID); err != nil {
+		return
+	}
+
+	categoryID := suggestion.CategoryID
+	confidence := suggestion.Confidence
+	transaction.CategorizationSource = CategorizationSource(suggestion.CategorizationSource)
+	transaction.CategorizationConfidence = &confidence
+	if confidence > s.categorization.Threshold {
+		transaction.CategoryID = &categoryID
+		applyRuleActions(transaction, suggestion.Actions)
+	} else {
+		transaction.SuggestedCategoryID = &categoryID
+	}
+}
+
+// applyRuleActions does what the rule that categorized a transaction asks for besides
+// setting its category. Tags are added to the transaction's own, and notes it already
+// has are kept
+func applyRuleActions(transaction *Transaction, actions *analytics.RuleActions) {
+	if actions == nil {
+		return
+	}
+
+	for _, tag := range actions.Tags {
+		exists := false
+		for _, existing := range transaction.Tags {
+			exists = exists || existing == tag
+		}
+		if !exists {
+			transaction.Tags = append(transaction.Tags, tag)
+		}
+	}
+	if transaction.Notes == "" {
+		transaction.Notes = actions.Notes
+	}
+	if actions.IsTransfer {
+		transaction.IsTransfer = true
+	}
+}
+
+// changeCategory sets the category the user chose for a transaction, which settles any
+// suggestion awaiting review. Changing a category that was set or suggested automatically
+// is a correction, which is returned to learn from
+func changeCategory(transaction *Transaction, categoryID uuid.UUID) *analytics.CategoryCorrectionRequest {
+	previousCategoryID := transaction.CategoryID
+	if previousCategoryID == nil {
+		previousCategoryID = transaction.SuggestedCategoryID
+	}
+
+	var correction *analytics.CategoryCorrectionRequest
+	if isAutomaticCategorization(transaction.CategorizationSource) && (previousCategoryID == nil || *previousCategoryID != categoryID) {
+		correction = &analytics.CategoryCorrectionRequest{
+			TransactionID:      transaction.ID,
+			PreviousCategoryID: previousCategoryID,
+			PreviousSource:     string(transaction.CategorizationSource),
+			CategoryID:         categoryID,
+		}
+		transaction.CategorizationSource = CategorizationSourceUserCorrection
+	}
+
+	transaction.CategoryID = &categoryID
+	transaction.SuggestedCategoryID = nil
+	return correction
+}
+
+// recordCorrection passes a correction of an updated transaction on to the categorizer
+func (s *service) recordCorrection(ctx context.Context, userID uuid.UUID, transaction *Transaction, correction *analytics.CategoryCorrectionRequest) error {
+	if correction == nil || s.categorization.Categorizer == nil {
+		return nil
+	}
+
+	correction.Description = transaction.Description
+	correction.Merchant = transaction.Merchant
+	correction.Amount = transaction.Amount
+	return s.categorization.Categorizer.RecordCorrection(ctx, userID, correction)
+}
+
+// isAutomaticCategorization reports whether a category was set by a rule or the model
+func isAutomaticCategorization(source CategorizationSource) bool {
+	return source == CategorizationSourceRule || source == CategorizationSourceML
+}
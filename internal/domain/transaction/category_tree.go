@@ -0,0 +1,126 @@
+package transaction
+
+import (
+	"context"
+	"sort"
+
+	"github.com/google/uuid"
+)
+
+// MaxCategoryDepth is the maximum number of levels in the category hierarchy
+const MaxCategoryDepth = 4
+
+// BuildCategoryTree arranges categories into a tree. Categories whose parent is not in
+// the list (for example because it is hidden) are placed at the top level
+func BuildCategoryTree(categories []Category) []CategoryNode {
+	byID := make(map[uuid.UUID]bool, len(categories))
+	for _, category := range categories {
+		byID[category.ID] = true
+	}
+
+	children := make(map[uuid.UUID][]Category)
+	var roots []Category
+	for _, category := range categories {
+		if category.ParentID != nil && byID[*category.ParentID] && *category.ParentID != category.ID {
+			children[*category.ParentID] = append(children[*category.ParentID], category)
+			continue
+		}
+		roots = append(roots, category)
+	}
+
+	visited := make(map[uuid.UUID]bool, len(categories))
+	var build func(categories []Category, level int) []CategoryNode
+	build = func(categories []Category, level int) []CategoryNode {
+		sortCategories(categories)
+		nodes := make([]CategoryNode, 0, len(categories))
+		for _, category := range categories {
+			// Guard against cycles in existing data
+			if visited[category.ID] {
+				continue
+			}
+			visited[category.ID] = true
+			nodes = append(nodes, CategoryNode{
+				Category: category,
+				Level:    level,
+				Children: build(children[category.ID], level+1),
+			})
+		}
+		return nodes
+	}
+
+	return build(roots, 0)
+}
+
+// sortCategories orders categories the same way the repository lists them
+func sortCategories(categories []Category) {
+	sort.SliceStable(categories, func(i, j int) bool {
+		if categories[i].SortOrder != categories[j].SortOrder {
+			return categories[i].SortOrder < categories[j].SortOrder
+		}
+		return categories[i].Name < categories[j].Name
+	})
+}
+
+// validateCategoryParent checks that a category can be placed under parentID without
+// creating a cycle or exceeding MaxCategoryDepth. categoryID is nil for new categories
+func (s *service) validateCategoryParent(ctx context.Context, userID uuid.UUID, categoryID *uuid.UUID, parentID uuid.UUID) error {
+	parent, err := s.getVisibleCategory(ctx, userID, parentID)
+	if err != nil {
+		return err
+	}
+
+	// Walk up from the new parent; meeting the category itself means a cycle
+	parentLevel := 0
+	for current := parent; ; parentLevel++ {
+		if categoryID != nil && current.ID == *categoryID {
+			return ErrCategoryCycle
+		}
+		if current.ParentID == nil {
+			break
+		}
+		if parentLevel >= MaxCategoryDepth {
+			return ErrCategoryTooDeep
+		}
+		current, err = s.repo.GetCategoryByID(ctx, *current.ParentID)
+		if err != nil {
+			return err
+		}
+	}
+
+	height := 0
+	if categoryID != nil {
+		height, err = s.categorySubtreeHeight(ctx, *categoryID, 0)
+		if err != nil {
+			return err
+		}
+	}
+
+	if parentLevel+1+height >= MaxCategoryDepth {
+		return ErrCategoryTooDeep
+	}
+	return nil
+}
+
+// categorySubtreeHeight returns the number of levels below a category
+func (s *service) categorySubtreeHeight(ctx context.Context, categoryID uuid.UUID, level int) (int, error) {
+	if level >= MaxCategoryDepth {
+		return 0, ErrCategoryTooDeep
+	}
+
+	children, err := s.repo.GetChildCategories(ctx, categoryID)
+	if err != nil {
+		return 0, err
+	}
+
+	height := 0
+	for _, child := range children {
+		childHeight, err := s.categorySubtreeHeight(ctx, child.ID, level+1)
+		if err != nil {
+			return 0, err
+		}
+		if childHeight+1 > height {
+			height = childHeight + 1
+		}
+	}
+	return height, nil
+}
@@ -0,0 +1,44 @@
+package transaction
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBuildCategoryTree(t *testing.T) {
+	food := Category{ID: uuid.New(), Name: "Food"}
+	groceries := Category{ID: uuid.New(), Name: "Groceries", ParentID: &food.ID, SortOrder: 2}
+	restaurants := Category{ID: uuid.New(), Name: "Restaurants", ParentID: &food.ID, SortOrder: 1}
+	coffee := Category{ID: uuid.New(), Name: "Coffee", ParentID: &restaurants.ID}
+	hiddenParent := uuid.New()
+	orphan := Category{ID: uuid.New(), Name: "Orphan", ParentID: &hiddenParent}
+
+	tree := BuildCategoryTree([]Category{coffee, groceries, orphan, food, restaurants})
+
+	if assert.Len(t, tree, 2) {
+		assert.Equal(t, food.ID, tree[0].ID)
+		assert.Equal(t, orphan.ID, tree[1].ID)
+		assert.Equal(t, 0, tree[1].Level)
+	}
+	children := tree[0].Children
+	if assert.Len(t, children, 2) {
+		assert.Equal(t, restaurants.ID, children[0].ID)
+		assert.Equal(t, groceries.ID, children[1].ID)
+		assert.Equal(t, 1, children[0].Level)
+	}
+	if assert.Len(t, children[0].Children, 1) {
+		assert.Equal(t, coffee.ID, children[0].Children[0].ID)
+		assert.Equal(t, 2, children[0].Children[0].Level)
+	}
+}
+
+func TestBuildCategoryTree_Cycle(t *testing.T) {
+	a := Category{ID: uuid.New(), Name: "A"}
+	b := Category{ID: uuid.New(), Name: "B", ParentID: &a.ID}
+	a.ParentID = &b.ID
+
+	// Neither category is a root, so nothing in the cycle is reachable
+	assert.Empty(t, BuildCategoryTree([]Category{a, b}))
+}
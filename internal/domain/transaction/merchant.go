@@ -0,0 +1,319 @@
+package transaction
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+
+	"github.com/google/uuid"
+)
+
+// merchantProcessorPrefixes are payment processor and card network prefixes that
+// banks prepend to the real merchant name
+var merchantProcessorPrefixes = []string{
+	"SQ *", "SQ*", "TST* ", "TST*", "PAYPAL *", "PAYPAL*", "PP*", "SP * ", "SP *",
+	"POS ", "DEBIT ", "PURCHASE ", "CHECKCARD ", "CARD PURCHASE ",
+}
+
+// merchantDomainSuffix matches web domain suffixes stripped from merchant names
+var merchantDomainSuffix = regexp.MustCompile(`\.(COM|NET|ORG|CO\.UK|CO|IO)\b`)
+
+// merchantTrailingCountryCodes are country codes banks append to merchant names
+var merchantTrailingCountryCodes = map[string]bool{
+	"US": true, "USA": true, "GB": true, "UK": true, "IE": true,
+	"CA": true, "AU": true, "NZ": true, "DE": true, "FR": true,
+}
+
+// NormalizeMerchantName reduces a raw merchant string to a comparable key so that
+// "AMZN MKTP US*2K3", "Amazon.com" and "AMAZON" can be matched to the same merchant
+func NormalizeMerchantName(raw string) string {
+	name := strings.ToUpper(strings.TrimSpace(raw))
+	if name == "" {
+		return ""
+	}
+
+	for _, prefix := range merchantProcessorPrefixes {
+		if strings.HasPrefix(name, prefix) {
+			name = strings.TrimSpace(strings.TrimPrefix(name, prefix))
+			break
+		}
+	}
+
+	// Everything after a '*' is a reference number (e.g. "AMZN MKTP US*2K3")
+	if idx := strings.Index(name, "*"); idx > 0 {
+		name = name[:idx]
+	}
+
+	name = merchantDomainSuffix.ReplaceAllString(name, "")
+
+	name = strings.ReplaceAll(name, "'", "")
+	name = strings.Map(func(r rune) rune {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) || r == '&' {
+			return r
+		}
+		return ' '
+	}, name)
+
+	var tokens []string
+	for _, token := range strings.Fields(name) {
+		// Drop store numbers and reference codes such as "#1234" or "2K3"
+		if strings.IndexFunc(token, unicode.IsDigit) >= 0 {
+			continue
+		}
+		tokens = append(tokens, token)
+	}
+
+	for len(tokens) > 1 && merchantTrailingCountryCodes[tokens[len(tokens)-1]] {
+		tokens = tokens[:len(tokens)-1]
+	}
+
+	return strings.Join(tokens, " ")
+}
+
+// merchantMatcher matches normalized names to merchants, with the merchants' regex
+// patterns compiled once
+type merchantMatcher struct {
+	merchants []Merchant
+	patterns  [][]*regexp.Regexp // Valid patterns of each merchant
+}
+
+// newMerchantMatcher creates a matcher for merchants, skipping invalid patterns
+func newMerchantMatcher(merchants []Merchant) *merchantMatcher {
+	m := &merchantMatcher{merchants: merchants, patterns: make([][]*regexp.Regexp, len(merchants))}
+	for i := range merchants {
+		for _, pattern := range merchants[i].Patterns {
+			re, err := regexp.Compile("(?i)" + pattern)
+			if err != nil {
+				continue
+			}
+			m.patterns[i] = append(m.patterns[i], re)
+		}
+	}
+	return m
+}
+
+// match finds the merchant a normalized name belongs to. Exact matches on the canonical
+// name win, followed by exact alias matches, the longest alias prefix and finally regex
+// patterns
+func (m *merchantMatcher) match(normalized string) *Merchant {
+	if normalized == "" {
+		return nil
+	}
+
+	for i := range m.merchants {
+		if m.merchants[i].NormalizedName == normalized {
+			return &m.merchants[i]
+		}
+	}
+
+	var best *Merchant
+	bestLength := 0
+	for i := range m.merchants {
+		for _, alias := range m.merchants[i].Aliases {
+			if alias == normalized {
+				return &m.merchants[i]
+			}
+			if strings.HasPrefix(normalized, alias+" ") && len(alias) > bestLength {
+				best = &m.merchants[i]
+				bestLength = len(alias)
+			}
+		}
+	}
+	if best != nil {
+		return best
+	}
+
+	for i := range m.merchants {
+		for _, re := range m.patterns[i] {
+			if re.MatchString(normalized) {
+				return &m.merchants[i]
+			}
+		}
+	}
+
+	return nil
+}
+
+// merchantDisplayName picks a human readable name for a newly discovered merchant
+func merchantDisplayName(raw, normalized string) string {
+	trimmed := strings.TrimSpace(raw)
+	hasLower := strings.IndexFunc(trimmed, unicode.IsLower) >= 0
+	hasNoise := strings.ContainsAny(trimmed, "*#") || strings.IndexFunc(trimmed, unicode.IsDigit) >= 0
+	if hasLower && !hasNoise {
+		return trimmed
+	}
+
+	words := strings.Fields(strings.ToLower(normalized))
+	for i, word := range words {
+		runes := []rune(word)
+		runes[0] = unicode.ToUpper(runes[0])
+		words[i] = string(runes)
+	}
+	return strings.Join(words, " ")
+}
+
+// normalizeMerchantAliases normalizes and de-duplicates merchant aliases
+func normalizeMerchantAliases(aliases []string) []string {
+	seen := make(map[string]bool, len(aliases))
+	normalized := make([]string, 0, len(aliases))
+	for _, alias := range aliases {
+		key := NormalizeMerchantName(alias)
+		if key == "" || seen[key] {
+			continue
+		}
+		seen[key] = true
+		normalized = append(normalized, key)
+	}
+	return normalized
+}
+
+// merchantCacheTTL is how long the shared merchants are kept in memory. Changes made
+// through this service show at once, changes made by other instances after the TTL
+const merchantCacheTTL = 5 * time.Minute
+
+// merchantCache keeps the shared merchants in memory with their patterns compiled
+type merchantCache struct {
+	mu         sync.RWMutex
+	matcher    *merchantMatcher
+	loadedAt   time.Time
+	generation uint64
+}
+
+// get returns the cached matcher, the generation of the cache to set a loaded matcher
+// with, and whether the matcher is still fresh
+func (c *merchantCache) get() (*merchantMatcher, uint64, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.matcher, c.generation, c.matcher != nil && time.Since(c.loadedAt) < merchantCacheTTL
+}
+
+// set caches a matcher, unless the merchants changed since it was loaded
+func (c *merchantCache) set(matcher *merchantMatcher, generation uint64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if generation != c.generation {
+		return
+	}
+	c.matcher = matcher
+	c.loadedAt = time.Now()
+}
+
+// invalidate makes the shared merchants be loaded again on their next use
+func (c *merchantCache) invalidate() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.matcher = nil
+	c.generation++
+}
+
+// sharedMerchants returns the matcher of the merchants shared by all users
+func (s *service) sharedMerchants(ctx context.Context) (*merchantMatcher, error) {
+	matcher, generation, fresh := s.merchants.get()
+	if fresh {
+		return matcher, nil
+	}
+
+	merchants, err := s.repo.GetSharedMerchants(ctx)
+	if err != nil {
+		return nil, err
+	}
+	matcher = newMerchantMatcher(merchants)
+	s.merchants.set(matcher, generation)
+	return matcher, nil
+}
+
+// merchantResolver resolves a user's raw merchant strings to merchants. Shared merchants
+// are matched first, then the merchants discovered in the user's own transactions, which
+// are loaded once so that a batch of transactions only hits the database for genuinely
+// new merchants. New merchants are only visible to the user they were discovered for
+type merchantResolver struct {
+	service *service
+	userID  uuid.UUID
+	shared  *merchantMatcher
+	own     map[string]*Merchant // The user's own merchants by normalized name
+}
+
+// newMerchantResolver creates a new merchant resolver for a user
+func (s *service) newMerchantResolver(userID uuid.UUID) *merchantResolver {
+	return &merchantResolver{service: s, userID: userID}
+}
+
+// resolve returns the merchant for a raw merchant string, creating it if it is unknown
+func (r *merchantResolver) resolve(ctx context.Context, raw string) (*Merchant, error) {
+	normalized := NormalizeMerchantName(raw)
+	if normalized == "" {
+		return nil, nil
+	}
+
+	if r.shared == nil {
+		shared, err := r.service.sharedMerchants(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get merchants: %w", err)
+		}
+		own, err := r.service.repo.GetMerchantsByUser(ctx, r.userID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get merchants: %w", err)
+		}
+		r.shared = shared
+		r.own = make(map[string]*Merchant, len(own))
+		for i := range own {
+			r.own[own[i].NormalizedName] = &own[i]
+		}
+	}
+
+	// Shared merchants are cached for everyone, so callers get a copy
+	if merchant := r.shared.match(normalized); merchant != nil {
+		found := *merchant
+		return &found, nil
+	}
+	if merchant, exists := r.own[normalized]; exists {
+		return merchant, nil
+	}
+
+	userID := r.userID
+	merchant := &Merchant{
+		UserID:         &userID,
+		Name:           merchantDisplayName(raw, normalized),
+		NormalizedName: normalized,
+		Metadata:       "{}",
+	}
+	if err := r.service.repo.CreateMerchant(ctx, merchant); err != nil {
+		// Another request may have created the merchant concurrently
+		existing, lookupErr := r.service.repo.GetMerchantByNormalizedName(ctx, r.userID, normalized)
+		if lookupErr != nil {
+			return nil, fmt.Errorf("failed to create merchant: %w", err)
+		}
+		merchant = existing
+	}
+
+	r.own[normalized] = merchant
+	return merchant, nil
+}
+
+// applyMerchant links a transaction to the merchant its raw merchant string resolves to,
+// keeping the raw string and falling back to the merchant's default category
+func (r *merchantResolver) applyMerchant(ctx context.Context, transaction *Transaction, raw string) error {
+	merchant, err := r.resolve(ctx, raw)
+	if err != nil {
+		return err
+	}
+	if merchant == nil {
+		return nil
+	}
+
+	transaction.RawMerchant = raw
+	transaction.Merchant = merchant.Name
+	transaction.MerchantID = &merchant.ID
+
+	if transaction.CategoryID == nil && merchant.DefaultCategoryID != nil {
+		categoryID := *merchant.DefaultCategoryID
+		transaction.CategoryID = &categoryID
+		transaction.CategorizationSource = CategorizationSourceMerchant
+	}
+
+	return nil
+}
@@ -0,0 +1,66 @@
+package transaction
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNormalizeMerchantName(t *testing.T) {
+	tests := []struct {
+		raw      string
+		expected string
+	}{
+		{"AMZN MKTP US*2K3", "AMZN MKTP"},
+		{"Amazon.com", "AMAZON"},
+		{"AMAZON", "AMAZON"},
+		{"SQ *BLUE BOTTLE COFFEE", "BLUE BOTTLE COFFEE"},
+		{"WALMART #1234", "WALMART"},
+		{"McDonald's", "MCDONALDS"},
+		{"AT&T", "AT&T"},
+		{"  Netflix.com  ", "NETFLIX"},
+		{"", ""},
+		{"#1234", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.raw, func(t *testing.T) {
+			assert.Equal(t, tt.expected, NormalizeMerchantName(tt.raw))
+		})
+	}
+}
+
+func TestMerchantMatcher(t *testing.T) {
+	amazon := Merchant{ID: uuid.New(), Name: "Amazon", NormalizedName: "AMAZON", Aliases: []string{"AMZN", "AMZN MKTP"}}
+	amazonPrime := Merchant{ID: uuid.New(), Name: "Amazon Prime", NormalizedName: "AMAZON PRIME", Aliases: []string{"AMZN PRIME"}}
+	uber := Merchant{ID: uuid.New(), Name: "Uber", NormalizedName: "UBER", Patterns: []string{`^UBER\b`}}
+	matcher := newMerchantMatcher([]Merchant{amazon, amazonPrime, uber})
+
+	tests := []struct {
+		name       string
+		normalized string
+		expected   *uuid.UUID
+	}{
+		{"canonical name", "AMAZON", &amazon.ID},
+		{"exact alias", "AMZN MKTP", &amazon.ID},
+		{"alias prefix", "AMZN DIGITAL", &amazon.ID},
+		{"longest alias prefix wins", "AMZN PRIME MEMBERSHIP", &amazonPrime.ID},
+		{"regex pattern", "UBER TRIP HELP", &uber.ID},
+		{"unknown merchant", "STARBUCKS", nil},
+		{"empty name", "", nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			merchant := matcher.match(tt.normalized)
+			if tt.expected == nil {
+				assert.Nil(t, merchant)
+				return
+			}
+			if assert.NotNil(t, merchant) {
+				assert.Equal(t, *tt.expected, merchant.ID)
+			}
+		})
+	}
+}
@@ -14,11 +14,13 @@ type Transaction struct {
 	AccountID  uuid.UUID  `json:"account_id" gorm:"type:uuid;not null"`
 	CategoryID *uuid.UUID `json:"category_id" gorm:"type:uuid"`
 
-	Amount      float64 `json:"amount" gorm:"type:decimal(15,2);not null"`
-	Currency    string  `json:"currency" gorm:"default:'USD'"`
-	Description string  `json:"description" gorm:"not null"`
-	Merchant    string  `json:"merchant"`
-	Location    string  `json:"location" gorm:"type:jsonb"`
+	Amount      float64    `json:"amount" gorm:"type:decimal(15,2);not null"`
+	Currency    string     `json:"currency" gorm:"default:'USD'"`
+	Description string     `json:"description" gorm:"not null"`
+	Merchant    string     `json:"merchant"`
+	MerchantID  *uuid.UUID `json:"merchant_id" gorm:"type:uuid;index"`
+	RawMerchant string     `json:"raw_merchant"`
+	Location    string     `json:"location" gorm:"type:jsonb"`
 
 	TransactionDate time.Time         `json:"transaction_date" gorm:"not null"`
 	PostedDate      *time.Time        `json:"posted_date"`
@@ -26,10 +28,12 @@ type Transaction struct {
 
 	CategorizationSource     CategorizationSource `json:"categorization_source" gorm:"default:'manual'"`
 	CategorizationConfidence *float64             `json:"categorization_confidence"`
+	SuggestedCategoryID      *uuid.UUID           `json:"suggested_category_id" gorm:"type:uuid"` // Awaiting review, as it was not confident enough to apply
 
 	Tags       []string `json:"tags" gorm:"type:text[]"`
 	Notes      string   `json:"notes"`
 	ReceiptURL string   `json:"receipt_url"`
+	IsTransfer bool     `json:"is_transfer" gorm:"default:false"` // Moves money between accounts rather than spending or earning it
 
 	CreatedAt time.Time `json:"created_at"`
 	UpdatedAt time.Time `json:"updated_at"`
@@ -53,11 +57,15 @@ const (
 	CategorizationSourceML             CategorizationSource = "ml"
 	CategorizationSourcePlaid          CategorizationSource = "plaid"
 	CategorizationSourceUserCorrection CategorizationSource = "user_correction"
+	CategorizationSourceMerchant       CategorizationSource = "merchant"
+	CategorizationSourceRule           CategorizationSource = "rule"
 )
 
 // Category represents a transaction category
 type Category struct {
 	ID          uuid.UUID  `json:"id" gorm:"type:uuid;primary_key;default:gen_random_uuid()"`
+	UserID      *uuid.UUID `json:"user_id" gorm:"type:uuid;index"`   // Owner of a user category
+	FamilyID    *uuid.UUID `json:"family_id" gorm:"type:uuid;index"` // Owner of a family category
 	Name        string     `json:"name" gorm:"not null"`
 	Description string     `json:"description"`
 	Icon        string     `json:"icon"`
@@ -66,10 +74,65 @@ type Category struct {
 	IsDefault   bool       `json:"is_default" gorm:"default:false"`
 	IsActive    bool       `json:"is_active" gorm:"default:true"`
 	SortOrder   int        `json:"sort_order" gorm:"default:0"`
+	Locale      string     `json:"locale,omitempty" gorm:"index"` // Seed set of a system category
+	SeedKey     string     `json:"-" gorm:"index"`                // Identifies a seeded category within its seed set
 	CreatedAt   time.Time  `json:"created_at"`
 	UpdatedAt   time.Time  `json:"updated_at"`
 }
 
+// CategoryScope represents who a category belongs to
+type CategoryScope string
+
+const (
+	CategoryScopeSystem CategoryScope = "system"
+	CategoryScopeUser   CategoryScope = "user"
+	CategoryScopeFamily CategoryScope = "family"
+)
+
+// Scope returns whether the category is a system default, a user category or a family category
+func (c *Category) Scope() CategoryScope {
+	switch {
+	case c.FamilyID != nil:
+		return CategoryScopeFamily
+	case c.UserID != nil:
+		return CategoryScopeUser
+	default:
+		return CategoryScopeSystem
+	}
+}
+
+// CategoryNode represents a category and its subcategories in the category tree
+type CategoryNode struct {
+	Category
+	Level    int            `json:"level"` // 0 for top-level categories
+	Children []CategoryNode `json:"children"`
+}
+
+// HiddenCategory records a system category a user has hidden from their category list
+type HiddenCategory struct {
+	UserID     uuid.UUID `json:"user_id" gorm:"type:uuid;primaryKey"`
+	CategoryID uuid.UUID `json:"category_id" gorm:"type:uuid;primaryKey"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+// Merchant represents a canonical merchant that raw merchant strings are normalized to.
+// Merchants managed by admins are shared by all users, merchants discovered in a user's
+// transactions belong to that user
+type Merchant struct {
+	ID                uuid.UUID  `json:"id" gorm:"type:uuid;primary_key;default:gen_random_uuid()"`
+	UserID            *uuid.UUID `json:"user_id" gorm:"type:uuid;uniqueIndex:idx_merchants_user_normalized_name"` // Nil for shared merchants
+	Name              string     `json:"name" gorm:"not null"`
+	NormalizedName    string     `json:"normalized_name" gorm:"uniqueIndex:idx_merchants_user_normalized_name;not null"`
+	Aliases           []string   `json:"aliases" gorm:"type:text[]"`  // Normalized alias keys, matched exactly or as a prefix
+	Patterns          []string   `json:"patterns" gorm:"type:text[]"` // Regex patterns matched against the normalized name
+	DefaultCategoryID *uuid.UUID `json:"default_category_id" gorm:"type:uuid"`
+	LogoURL           string     `json:"logo_url"`
+	Website           string     `json:"website"`
+	Metadata          string     `json:"metadata" gorm:"type:jsonb;default:'{}'"`
+	CreatedAt         time.Time  `json:"created_at"`
+	UpdatedAt         time.Time  `json:"updated_at"`
+}
+
 // Account represents a financial account
 type Account struct {
 	ID                uuid.UUID   `json:"id" gorm:"type:uuid;primary_key;default:gen_random_uuid()"`
@@ -129,6 +192,7 @@ type UpdateTransactionRequest struct {
 	Status          *TransactionStatus `json:"status"`
 	Tags            []string           `json:"tags"`
 	Notes           string             `json:"notes"`
+	IsTransfer      *bool              `json:"is_transfer"`
 }
 
 // TransactionResponse represents a transaction response
@@ -142,15 +206,19 @@ type TransactionResponse struct {
 	Currency                 string               `json:"currency"`
 	Description              string               `json:"description"`
 	Merchant                 string               `json:"merchant"`
+	MerchantID               *uuid.UUID           `json:"merchant_id"`
+	RawMerchant              string               `json:"raw_merchant"`
 	Location                 string               `json:"location"`
 	TransactionDate          time.Time            `json:"transaction_date"`
 	PostedDate               *time.Time           `json:"posted_date"`
 	Status                   TransactionStatus    `json:"status"`
 	CategorizationSource     CategorizationSource `json:"categorization_source"`
 	CategorizationConfidence *float64             `json:"categorization_confidence"`
+	SuggestedCategoryID      *uuid.UUID           `json:"suggested_category_id"`
 	Tags                     []string             `json:"tags"`
 	Notes                    string               `json:"notes"`
 	ReceiptURL               string               `json:"receipt_url"`
+	IsTransfer               bool                 `json:"is_transfer"`
 	CreatedAt                time.Time            `json:"created_at"`
 	UpdatedAt                time.Time            `json:"updated_at"`
 }
@@ -162,10 +230,55 @@ type CreateCategoryRequest struct {
 	Icon        string     `json:"icon"`
 	Color       string     `json:"color"`
 	ParentID    *uuid.UUID `json:"parent_id"`
-	IsDefault   bool       `json:"is_default"`
+	FamilyID    *uuid.UUID `json:"family_id"` // Create a family category instead of a user category
 	SortOrder   int        `json:"sort_order"`
 }
 
+// MoveCategoryRequest represents a request to move a category under a new parent
+type MoveCategoryRequest struct {
+	ParentID *uuid.UUID `json:"parent_id"` // nil moves the category to the top level
+}
+
+// MergeCategoryRequest represents a request to merge a category into another category
+type MergeCategoryRequest struct {
+	TargetID uuid.UUID `json:"target_id" binding:"required"`
+}
+
+// CreateMerchantRequest represents a request to create or update a merchant
+type CreateMerchantRequest struct {
+	Name              string     `json:"name" binding:"required"`
+	Aliases           []string   `json:"aliases"`
+	Patterns          []string   `json:"patterns"`
+	DefaultCategoryID *uuid.UUID `json:"default_category_id"`
+	LogoURL           string     `json:"logo_url"`
+	Website           string     `json:"website"`
+	Metadata          string     `json:"metadata"`
+}
+
+// ReviewCategoryRequest represents a user reviewing the category suggested for a transaction
+type ReviewCategoryRequest struct {
+	CategoryID *uuid.UUID `json:"category_id"` // Accepts the suggested category when empty
+}
+
+// ImportTransactionsRequest represents a request to import a batch of transactions
+type ImportTransactionsRequest struct {
+	Transactions []CreateTransactionRequest `json:"transactions" binding:"required,min=1,dive"`
+}
+
+// ImportTransactionsResponse represents the result of a transaction import
+type ImportTransactionsResponse struct {
+	Imported      []TransactionResponse `json:"imported"`
+	Failed        []ImportFailure       `json:"failed"`
+	ImportedCount int                   `json:"imported_count"`
+	FailedCount   int                   `json:"failed_count"`
+}
+
+// ImportFailure describes a transaction that could not be imported
+type ImportFailure struct {
+	Index int    `json:"index"`
+	Error string `json:"error"`
+}
+
 // CreateAccountRequest represents a request to create a new account
 type CreateAccountRequest struct {
 	Name              string      `json:"name" binding:"required"`
@@ -187,6 +300,16 @@ func (Category) TableName() string {
 	return "categories"
 }
 
+// TableName specifies the table name for HiddenCategory
+func (HiddenCategory) TableName() string {
+	return "hidden_categories"
+}
+
+// TableName specifies the table name for Merchant
+func (Merchant) TableName() string {
+	return "merchants"
+}
+
 // TableName specifies the table name for Account
 func (Account) TableName() string {
 	return "accounts"
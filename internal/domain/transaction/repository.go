@@ -6,6 +6,7 @@ import (
 
 	"github.com/google/uuid"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 // Repository defines the interface for transaction data operations
@@ -15,16 +16,36 @@ type Repository interface {
 	GetTransactionByID(ctx context.Context, id uuid.UUID) (*Transaction, error)
 	GetTransactionsByUser(ctx context.Context, userID uuid.UUID, offset, limit int) ([]Transaction, error)
 	GetTransactionsByAccount(ctx context.Context, accountID uuid.UUID, offset, limit int) ([]Transaction, error)
+	GetTransactionsForReview(ctx context.Context, userID uuid.UUID, offset, limit int) ([]Transaction, error)
 	UpdateTransaction(ctx context.Context, transaction *Transaction) error
 	DeleteTransaction(ctx context.Context, id uuid.UUID) error
 
 	// Category operations
 	CreateCategory(ctx context.Context, category *Category) error
 	GetCategoryByID(ctx context.Context, id uuid.UUID) (*Category, error)
-	GetCategories(ctx context.Context, offset, limit int) ([]Category, error)
-	GetDefaultCategories(ctx context.Context) ([]Category, error)
+	GetCategories(ctx context.Context, userID uuid.UUID, familyIDs []uuid.UUID, locale string, offset, limit int) ([]Category, error)
+	GetDefaultCategories(ctx context.Context, locale string) ([]Category, error)
+	GetChildCategories(ctx context.Context, parentID uuid.UUID) ([]Category, error)
 	UpdateCategory(ctx context.Context, category *Category) error
 	DeleteCategory(ctx context.Context, id uuid.UUID) error
+	HasCategoryReferences(ctx context.Context, id uuid.UUID) (bool, error)
+	MergeCategory(ctx context.Context, sourceID, targetID uuid.UUID) error
+	HideCategory(ctx context.Context, hidden *HiddenCategory) error
+	UnhideCategory(ctx context.Context, userID, categoryID uuid.UUID) error
+
+	// Family operations
+	GetFamilyIDsByUser(ctx context.Context, userID uuid.UUID) ([]uuid.UUID, error)
+	GetUserCategoryLocale(ctx context.Context, userID uuid.UUID) (string, error)
+
+	// Merchant operations
+	CreateMerchant(ctx context.Context, merchant *Merchant) error
+	GetMerchantByID(ctx context.Context, id uuid.UUID) (*Merchant, error)
+	GetMerchantByNormalizedName(ctx context.Context, userID uuid.UUID, normalizedName string) (*Merchant, error)
+	GetMerchants(ctx context.Context, userID uuid.UUID, offset, limit int) ([]Merchant, error)
+	GetSharedMerchants(ctx context.Context) ([]Merchant, error)
+	GetMerchantsByUser(ctx context.Context, userID uuid.UUID) ([]Merchant, error)
+	UpdateMerchant(ctx context.Context, merchant *Merchant) error
+	DeleteMerchant(ctx context.Context, id uuid.UUID) error
 
 	// Account operations
 	CreateAccount(ctx context.Context, account *Account) error
@@ -76,6 +97,19 @@ func (r *repository) GetTransactionsByUser(ctx context.Context, userID uuid.UUID
 	return transactions, err
 }
 
+// GetTransactionsForReview retrieves a user's uncategorized transactions with a suggested
+// category to review, with pagination
+func (r *repository) GetTransactionsForReview(ctx context.Context, userID uuid.UUID, offset, limit int) ([]Transaction, error) {
+	var transactions []Transaction
+	err := r.db.WithContext(ctx).
+		Where("user_id = ? AND category_id IS NULL AND suggested_category_id IS NOT NULL", userID).
+		Order("transaction_date DESC, created_at DESC").
+		Offset(offset).
+		Limit(limit).
+		Find(&transactions).Error
+	return transactions, err
+}
+
 // GetTransactionsByAccount retrieves transactions for an account with pagination
 func (r *repository) GetTransactionsByAccount(ctx context.Context, accountID uuid.UUID, offset, limit int) ([]Transaction, error) {
 	var transactions []Transaction
@@ -118,10 +152,19 @@ func (r *repository) GetCategoryByID(ctx context.Context, id uuid.UUID) (*Catego
 	return &category, nil
 }
 
-// GetCategories retrieves all categories with pagination
-func (r *repository) GetCategories(ctx context.Context, offset, limit int) ([]Category, error) {
+// GetCategories retrieves the system categories of the locale's seed set plus the categories
+// owned by the user or their families with pagination, leaving out system categories the
+// user has hidden. System categories without a locale are shown for every locale
+func (r *repository) GetCategories(ctx context.Context, userID uuid.UUID, familyIDs []uuid.UUID, locale string, offset, limit int) ([]Category, error) {
+	owned := r.db.Where("user_id IS NULL AND family_id IS NULL AND COALESCE(locale, '') IN ('', ?)", locale).Or("user_id = ?", userID)
+	if len(familyIDs) > 0 {
+		owned = owned.Or("family_id IN ?", familyIDs)
+	}
+
 	var categories []Category
 	err := r.db.WithContext(ctx).
+		Where(owned).
+		Where("NOT EXISTS (SELECT 1 FROM hidden_categories WHERE hidden_categories.category_id = categories.id AND hidden_categories.user_id = ?)", userID).
 		Order("sort_order ASC, name ASC").
 		Offset(offset).
 		Limit(limit).
@@ -129,11 +172,22 @@ func (r *repository) GetCategories(ctx context.Context, offset, limit int) ([]Ca
 	return categories, err
 }
 
-// GetDefaultCategories retrieves default categories
-func (r *repository) GetDefaultCategories(ctx context.Context) ([]Category, error) {
+// GetDefaultCategories retrieves the default system categories for a locale
+func (r *repository) GetDefaultCategories(ctx context.Context, locale string) ([]Category, error) {
+	var categories []Category
+	err := r.db.WithContext(ctx).
+		Where("is_default = ? AND user_id IS NULL AND family_id IS NULL", true).
+		Where("COALESCE(locale, '') IN ('', ?)", locale).
+		Order("sort_order ASC, name ASC").
+		Find(&categories).Error
+	return categories, err
+}
+
+// GetChildCategories retrieves the direct subcategories of a category
+func (r *repository) GetChildCategories(ctx context.Context, parentID uuid.UUID) ([]Category, error) {
 	var categories []Category
 	err := r.db.WithContext(ctx).
-		Where("is_default = ?", true).
+		Where("parent_id = ?", parentID).
 		Order("sort_order ASC, name ASC").
 		Find(&categories).Error
 	return categories, err
@@ -149,6 +203,295 @@ func (r *repository) DeleteCategory(ctx context.Context, id uuid.UUID) error {
 	return r.db.WithContext(ctx).Delete(&Category{}, id).Error
 }
 
+// categoryReferences lists the columns in other tables that reference a category
+var categoryReferences = []struct {
+	table  string
+	column string
+}{
+	{"transactions", "category_id"},
+	{"budget_categories", "category_id"},
+	{"budget_period_categories", "category_id"},
+	{"envelope_transfers", "from_category_id"},
+	{"envelope_transfers", "to_category_id"},
+	{"budget_template_categories", "category_id"},
+	{"budget_alert_events", "category_id"},
+	{"categorization_rules", "category_id"},
+	{"categorization_feedback", "category_id"},
+	{"categorization_feedback", "previous_category_id"},
+	{"rule_applications", "category_id"},
+	{"rule_application_changes", "previous_category_id"},
+	{"rule_application_changes", "previous_suggested_category_id"},
+	{"merchants", "default_category_id"},
+	{"categories", "parent_id"},
+}
+
+// HasCategoryReferences reports whether any transaction, budget, period or template
+// allocation, envelope transfer, fired alert, rule, categorization correction, rule
+// application, merchant or subcategory still references a category
+func (r *repository) HasCategoryReferences(ctx context.Context, id uuid.UUID) (bool, error) {
+	for _, ref := range categoryReferences {
+		var count int64
+		err := r.db.WithContext(ctx).
+			Table(ref.table).
+			Where(ref.column+" = ?", id).
+			Count(&count).Error
+		if err != nil {
+			return false, err
+		}
+		if count > 0 {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// MergeCategory moves everything that references the source category to the target
+// category and deletes the source category in a single database transaction
+func (r *repository) MergeCategory(ctx context.Context, sourceID, targetID uuid.UUID) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&Transaction{}).Where("category_id = ?", sourceID).Update("category_id", targetID).Error; err != nil {
+			return err
+		}
+		if err := tx.Model(&Transaction{}).Where("suggested_category_id = ?", sourceID).Update("suggested_category_id", targetID).Error; err != nil {
+			return err
+		}
+
+		// Budgets that allocate to both categories keep one allocation with the amounts summed
+		if err := tx.Exec(`
+			UPDATE budget_categories AS target
+			SET allocated_amount = target.allocated_amount + source.allocated_amount,
+				spent_amount = target.spent_amount + source.spent_amount
+			FROM budget_categories AS source
+			WHERE source.budget_id = target.budget_id AND source.category_id = ? AND target.category_id = ?`,
+			sourceID, targetID).Error; err != nil {
+			return err
+		}
+		if err := tx.Exec(`
+			DELETE FROM budget_categories
+			WHERE category_id = ? AND budget_id IN (SELECT budget_id FROM budget_categories WHERE category_id = ?)`,
+			sourceID, targetID).Error; err != nil {
+			return err
+		}
+		if err := tx.Table("budget_categories").Where("category_id = ?", sourceID).Update("category_id", targetID).Error; err != nil {
+			return err
+		}
+
+		// Budget periods are merged the same way, keeping their rollover and remaining amounts
+		if err := tx.Exec(`
+			UPDATE budget_period_categories AS target
+			SET allocated_amount = target.allocated_amount + source.allocated_amount,
+				rollover_amount = target.rollover_amount + source.rollover_amount,
+				spent_amount = target.spent_amount + source.spent_amount,
+				remaining_amount = target.remaining_amount + source.remaining_amount
+			FROM budget_period_categories AS source
+			WHERE source.period_id = target.period_id AND source.category_id = ? AND target.category_id = ?`,
+			sourceID, targetID).Error; err != nil {
+			return err
+		}
+		if err := tx.Exec(`
+			DELETE FROM budget_period_categories
+			WHERE category_id = ? AND period_id IN (SELECT period_id FROM budget_period_categories WHERE category_id = ?)`,
+			sourceID, targetID).Error; err != nil {
+			return err
+		}
+		if err := tx.Table("budget_period_categories").Where("category_id = ?", sourceID).Update("category_id", targetID).Error; err != nil {
+			return err
+		}
+
+		// Envelope balances are the sum of their transfers, so the target takes over the source's
+		if err := tx.Table("envelope_transfers").Where("from_category_id = ?", sourceID).Update("from_category_id", targetID).Error; err != nil {
+			return err
+		}
+		if err := tx.Table("envelope_transfers").Where("to_category_id = ?", sourceID).Update("to_category_id", targetID).Error; err != nil {
+			return err
+		}
+
+		// Templates that allocate to both categories keep one allocation with the amounts summed
+		if err := tx.Exec(`
+			UPDATE budget_template_categories AS target
+			SET allocated_amount = target.allocated_amount + source.allocated_amount
+			FROM budget_template_categories AS source
+			WHERE source.template_id = target.template_id AND source.category_id = ? AND target.category_id = ?`,
+			sourceID, targetID).Error; err != nil {
+			return err
+		}
+		if err := tx.Exec(`
+			DELETE FROM budget_template_categories
+			WHERE category_id = ? AND template_id IN (SELECT template_id FROM budget_template_categories WHERE category_id = ?)`,
+			sourceID, targetID).Error; err != nil {
+			return err
+		}
+		if err := tx.Table("budget_template_categories").Where("category_id = ?", sourceID).Update("category_id", targetID).Error; err != nil {
+			return err
+		}
+
+		// Alerts that already fired for the target in a period don't fire again for the source
+		if err := tx.Exec(`
+			DELETE FROM budget_alert_events AS source
+			USING budget_alert_events AS target
+			WHERE source.category_id = ? AND target.category_id = ?
+				AND source.budget_id = target.budget_id AND source.alert_type = target.alert_type
+				AND source.threshold = target.threshold AND source.period_start = target.period_start`,
+			sourceID, targetID).Error; err != nil {
+			return err
+		}
+		if err := tx.Table("budget_alert_events").Where("category_id = ?", sourceID).Update("category_id", targetID).Error; err != nil {
+			return err
+		}
+
+		if err := tx.Table("categorization_rules").Where("category_id = ?", sourceID).Update("category_id", targetID).Error; err != nil {
+			return err
+		}
+		// Corrections keep training the categorizer towards the merged category
+		if err := tx.Table("categorization_feedback").Where("category_id = ?", sourceID).Update("category_id", targetID).Error; err != nil {
+			return err
+		}
+		if err := tx.Table("categorization_feedback").Where("previous_category_id = ?", sourceID).Update("previous_category_id", targetID).Error; err != nil {
+			return err
+		}
+		// Rule applications keep the previous state of backfilled transactions for undo
+		if err := tx.Table("rule_applications").Where("category_id = ?", sourceID).Update("category_id", targetID).Error; err != nil {
+			return err
+		}
+		if err := tx.Table("rule_application_changes").Where("previous_category_id = ?", sourceID).Update("previous_category_id", targetID).Error; err != nil {
+			return err
+		}
+		if err := tx.Table("rule_application_changes").Where("previous_suggested_category_id = ?", sourceID).Update("previous_suggested_category_id", targetID).Error; err != nil {
+			return err
+		}
+		if err := tx.Model(&Merchant{}).Where("default_category_id = ?", sourceID).Update("default_category_id", targetID).Error; err != nil {
+			return err
+		}
+		if err := tx.Model(&Category{}).Where("parent_id = ?", sourceID).Update("parent_id", targetID).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("category_id = ?", sourceID).Delete(&HiddenCategory{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&Category{}, "id = ?", sourceID).Error
+	})
+}
+
+// HideCategory hides a system category for a user
+func (r *repository) HideCategory(ctx context.Context, hidden *HiddenCategory) error {
+	return r.db.WithContext(ctx).
+		Clauses(clause.OnConflict{DoNothing: true}).
+		Create(hidden).Error
+}
+
+// UnhideCategory shows a previously hidden system category again
+func (r *repository) UnhideCategory(ctx context.Context, userID, categoryID uuid.UUID) error {
+	return r.db.WithContext(ctx).
+		Where("user_id = ? AND category_id = ?", userID, categoryID).
+		Delete(&HiddenCategory{}).Error
+}
+
+// Family operations
+
+// GetFamilyIDsByUser retrieves the IDs of the families a user belongs to. Memberships are
+// owned by the user domain and only read here
+func (r *repository) GetFamilyIDsByUser(ctx context.Context, userID uuid.UUID) ([]uuid.UUID, error) {
+	var familyIDs []uuid.UUID
+	err := r.db.WithContext(ctx).
+		Table("family_members").
+		Where("user_id = ?", userID).
+		Pluck("family_id", &familyIDs).Error
+	return familyIDs, err
+}
+
+// GetUserCategoryLocale retrieves the default category seed set chosen for the user at registration
+func (r *repository) GetUserCategoryLocale(ctx context.Context, userID uuid.UUID) (string, error) {
+	var locales []string
+	err := r.db.WithContext(ctx).
+		Table("users").
+		Where("id = ?", userID).
+		Pluck("category_locale", &locales).Error
+	if err != nil || len(locales) == 0 {
+		return "", err
+	}
+	return locales[0], nil
+}
+
+// Merchant operations
+
+// CreateMerchant creates a new merchant
+func (r *repository) CreateMerchant(ctx context.Context, merchant *Merchant) error {
+	return r.db.WithContext(ctx).Create(merchant).Error
+}
+
+// GetMerchantByID retrieves a merchant by ID
+func (r *repository) GetMerchantByID(ctx context.Context, id uuid.UUID) (*Merchant, error) {
+	var merchant Merchant
+	err := r.db.WithContext(ctx).Where("id = ?", id).First(&merchant).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrMerchantNotFound
+		}
+		return nil, err
+	}
+	return &merchant, nil
+}
+
+// GetMerchantByNormalizedName retrieves a merchant discovered for a user by its normalized name
+func (r *repository) GetMerchantByNormalizedName(ctx context.Context, userID uuid.UUID, normalizedName string) (*Merchant, error) {
+	var merchant Merchant
+	err := r.db.WithContext(ctx).Where("user_id = ? AND normalized_name = ?", userID, normalizedName).First(&merchant).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrMerchantNotFound
+		}
+		return nil, err
+	}
+	return &merchant, nil
+}
+
+// GetMerchants retrieves the shared merchants and a user's own merchants with pagination
+func (r *repository) GetMerchants(ctx context.Context, userID uuid.UUID, offset, limit int) ([]Merchant, error) {
+	var merchants []Merchant
+	err := r.db.WithContext(ctx).
+		Where("user_id IS NULL OR user_id = ?", userID).
+		Order("name ASC").
+		Offset(offset).
+		Limit(limit).
+		Find(&merchants).Error
+	return merchants, err
+}
+
+// GetSharedMerchants retrieves every merchant shared by all users for normalization matching
+func (r *repository) GetSharedMerchants(ctx context.Context) ([]Merchant, error) {
+	var merchants []Merchant
+	err := r.db.WithContext(ctx).
+		Where("user_id IS NULL").
+		Order("name ASC").
+		Find(&merchants).Error
+	return merchants, err
+}
+
+// GetMerchantsByUser retrieves the merchants discovered in a user's transactions
+func (r *repository) GetMerchantsByUser(ctx context.Context, userID uuid.UUID) ([]Merchant, error) {
+	var merchants []Merchant
+	err := r.db.WithContext(ctx).
+		Where("user_id = ?", userID).
+		Order("name ASC").
+		Find(&merchants).Error
+	return merchants, err
+}
+
+// UpdateMerchant updates a merchant
+func (r *repository) UpdateMerchant(ctx context.Context, merchant *Merchant) error {
+	return r.db.WithContext(ctx).Save(merchant).Error
+}
+
+// DeleteMerchant deletes a merchant and unlinks its transactions
+func (r *repository) DeleteMerchant(ctx context.Context, id uuid.UUID) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&Transaction{}).Where("merchant_id = ?", id).Update("merchant_id", nil).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&Merchant{}, id).Error
+	})
+}
+
 // Account operations
 
 // CreateAccount creates a new account
@@ -193,5 +536,12 @@ func (r *repository) DeleteAccount(ctx context.Context, id uuid.UUID) error {
 var (
 	ErrTransactionNotFound = errors.New("transaction not found")
 	ErrCategoryNotFound    = errors.New("category not found")
+	ErrCategoryNotOwned    = errors.New("category is not owned by user")
+	ErrCategoryCycle       = errors.New("category cannot be moved under itself or its subcategories")
+	ErrCategoryTooDeep     = errors.New("category hierarchy is too deep")
+	ErrCategoryInUse       = errors.New("category is still in use")
+	ErrInvalidMergeTarget  = errors.New("invalid merge target category")
 	ErrAccountNotFound     = errors.New("account not found")
+	ErrMerchantNotFound    = errors.New("merchant not found")
+	ErrNothingToReview     = errors.New("transaction has no suggested category to review")
 )
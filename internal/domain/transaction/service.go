@@ -4,6 +4,8 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"regexp"
+	"sort"
 	"time"
 
 	"github.com/google/uuid"
@@ -11,6 +13,9 @@ import (
 	"go.opentelemetry.io/otel/attribute"
 	"go.opentelemetry.io/otel/codes"
 	"go.opentelemetry.io/otel/trace"
+
+	"fiscaflow/internal/domain/analytics"
+	"fiscaflow/internal/seeds"
 )
 
 // Service defines the interface for transaction business logic
@@ -21,14 +26,29 @@ type Service interface {
 	GetTransactions(ctx context.Context, userID uuid.UUID, offset, limit int) ([]TransactionResponse, error)
 	UpdateTransaction(ctx context.Context, userID, transactionID uuid.UUID, req *UpdateTransactionRequest) (*TransactionResponse, error)
 	DeleteTransaction(ctx context.Context, userID, transactionID uuid.UUID) error
+	ImportTransactions(ctx context.Context, userID uuid.UUID, req *ImportTransactionsRequest) (*ImportTransactionsResponse, error)
+	GetReviewQueue(ctx context.Context, userID uuid.UUID, offset, limit int) ([]TransactionResponse, error)
+	ReviewCategory(ctx context.Context, userID, transactionID uuid.UUID, req *ReviewCategoryRequest) (*TransactionResponse, error)
 
 	// Category operations
-	CreateCategory(ctx context.Context, req *CreateCategoryRequest) (*Category, error)
-	GetCategory(ctx context.Context, categoryID uuid.UUID) (*Category, error)
-	GetCategories(ctx context.Context, offset, limit int) ([]Category, error)
-	GetDefaultCategories(ctx context.Context) ([]Category, error)
-	UpdateCategory(ctx context.Context, categoryID uuid.UUID, req *CreateCategoryRequest) (*Category, error)
-	DeleteCategory(ctx context.Context, categoryID uuid.UUID) error
+	CreateCategory(ctx context.Context, userID uuid.UUID, req *CreateCategoryRequest) (*Category, error)
+	GetCategory(ctx context.Context, userID, categoryID uuid.UUID) (*Category, error)
+	GetCategories(ctx context.Context, userID uuid.UUID, offset, limit int) ([]Category, error)
+	GetDefaultCategories(ctx context.Context, locale string) ([]Category, error)
+	UpdateCategory(ctx context.Context, userID, categoryID uuid.UUID, req *CreateCategoryRequest) (*Category, error)
+	DeleteCategory(ctx context.Context, userID, categoryID uuid.UUID, reassignTo *uuid.UUID) error
+	MergeCategory(ctx context.Context, userID, categoryID uuid.UUID, req *MergeCategoryRequest) (*Category, error)
+	GetCategoryTree(ctx context.Context, userID uuid.UUID) ([]CategoryNode, error)
+	MoveCategory(ctx context.Context, userID, categoryID uuid.UUID, req *MoveCategoryRequest) (*Category, error)
+	HideCategory(ctx context.Context, userID, categoryID uuid.UUID) error
+	UnhideCategory(ctx context.Context, userID, categoryID uuid.UUID) error
+
+	// Merchant operations
+	CreateMerchant(ctx context.Context, req *CreateMerchantRequest) (*Merchant, error)
+	GetMerchant(ctx context.Context, userID, merchantID uuid.UUID) (*Merchant, error)
+	GetMerchants(ctx context.Context, userID uuid.UUID, offset, limit int) ([]Merchant, error)
+	UpdateMerchant(ctx context.Context, merchantID uuid.UUID, req *CreateMerchantRequest) (*Merchant, error)
+	DeleteMerchant(ctx context.Context, merchantID uuid.UUID) error
 
 	// Account operations
 	CreateAccount(ctx context.Context, userID uuid.UUID, req *CreateAccountRequest) (*Account, error)
@@ -38,14 +58,45 @@ type Service interface {
 	DeleteAccount(ctx context.Context, userID, accountID uuid.UUID) error
 }
 
+// ChangeListener is notified after a user's transactions were created, updated or deleted
+type ChangeListener interface {
+	TransactionsChanged(ctx context.Context, userID uuid.UUID)
+}
+
+// Categorizer suggests categories for transactions and learns from the categories users
+// correct after they were set automatically. It is told when categories were merged, which
+// moves the rules it categorizes by
+type Categorizer interface {
+	CategorizeTransaction(ctx context.Context, req *analytics.CategorizationRequest) (*analytics.CategorizationResponse, error)
+	CategorizeTransactions(ctx context.Context, req *analytics.BatchCategorizationRequest) (*analytics.BatchCategorizationResponse, error)
+	RecordCorrection(ctx context.Context, userID uuid.UUID, req *analytics.CategoryCorrectionRequest) error
+	CategoriesMerged(ctx context.Context)
+}
+
+// CategorizationConfig configures the automatic categorization of transactions
+type CategorizationConfig struct {
+	Categorizer Categorizer // Transactions are not categorized automatically when nil
+	Threshold   float64     // Confidence above which a suggested category is applied
+}
+
 // service implements the Service interface
 type service struct {
-	repo Repository
+	repo           Repository
+	categorization CategorizationConfig
+	listeners      []ChangeListener
+	merchants      *merchantCache
 }
 
 // NewService creates a new transaction service
-func NewService(repo Repository) Service {
-	return &service{repo: repo}
+func NewService(repo Repository, categorization CategorizationConfig, listeners ...ChangeListener) Service {
+	return &service{repo: repo, categorization: categorization, listeners: listeners, merchants: &merchantCache{}}
+}
+
+// notifyChange tells the listeners that a user's transactions changed
+func (s *service) notifyChange(ctx context.Context, userID uuid.UUID) {
+	for _, listener := range s.listeners {
+		listener.TransactionsChanged(ctx, userID)
+	}
 }
 
 // Transaction operations
@@ -61,33 +112,118 @@ func (s *service) CreateTransaction(ctx context.Context, userID uuid.UUID, req *
 	)
 	defer span.End()
 
+	transaction, err := s.createTransaction(ctx, userID, req, s.newMerchantResolver(userID))
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, err
+	}
+
+	s.notifyChange(ctx, userID)
+
+	span.SetStatus(codes.Ok, "transaction created successfully")
+	return s.toTransactionResponse(transaction), nil
+}
+
+// ImportTransactions creates a batch of transactions, reporting failures per row
+func (s *service) ImportTransactions(ctx context.Context, userID uuid.UUID, req *ImportTransactionsRequest) (*ImportTransactionsResponse, error) {
+	ctx, span := otel.Tracer("transaction").Start(ctx, "ImportTransactions",
+		trace.WithAttributes(
+			attribute.String("user_id", userID.String()),
+			attribute.Int("transactions_count", len(req.Transactions)),
+		),
+	)
+	defer span.End()
+
+	resolver := s.newMerchantResolver(userID)
+	response := &ImportTransactionsResponse{
+		Imported: make([]TransactionResponse, 0, len(req.Transactions)),
+		Failed:   []ImportFailure{},
+	}
+
+	// Valid transactions are categorized together before any is stored
+	transactions := make([]*Transaction, len(req.Transactions))
+	var uncategorized []*Transaction
+	for i := range req.Transactions {
+		transaction, err := s.newTransaction(ctx, userID, &req.Transactions[i], resolver)
+		if err != nil {
+			response.Failed = append(response.Failed, ImportFailure{Index: i, Error: err.Error()})
+			continue
+		}
+		transactions[i] = transaction
+		if transaction.CategoryID == nil {
+			uncategorized = append(uncategorized, transaction)
+		}
+	}
+	s.autoCategorizeAll(ctx, userID, uncategorized)
+
+	for i, transaction := range transactions {
+		if transaction == nil {
+			continue
+		}
+		if err := s.repo.CreateTransaction(ctx, transaction); err != nil {
+			response.Failed = append(response.Failed, ImportFailure{Index: i, Error: fmt.Sprintf("failed to create transaction: %v", err)})
+			continue
+		}
+		response.Imported = append(response.Imported, *s.toTransactionResponse(transaction))
+	}
+	sort.Slice(response.Failed, func(i, j int) bool {
+		return response.Failed[i].Index < response.Failed[j].Index
+	})
+
+	response.ImportedCount = len(response.Imported)
+	response.FailedCount = len(response.Failed)
+	if response.ImportedCount > 0 {
+		s.notifyChange(ctx, userID)
+	}
+
+	span.SetAttributes(
+		attribute.Int("imported_count", response.ImportedCount),
+		attribute.Int("failed_count", response.FailedCount),
+	)
+	span.SetStatus(codes.Ok, "transactions imported")
+	return response, nil
+}
+
+// createTransaction validates, categorizes and stores a single transaction
+func (s *service) createTransaction(ctx context.Context, userID uuid.UUID, req *CreateTransactionRequest, resolver *merchantResolver) (*Transaction, error) {
+	transaction, err := s.newTransaction(ctx, userID, req, resolver)
+	if err != nil {
+		return nil, err
+	}
+
+	if transaction.CategoryID == nil {
+		s.autoCategorize(ctx, userID, transaction)
+	}
+
+	if err := s.repo.CreateTransaction(ctx, transaction); err != nil {
+		return nil, fmt.Errorf("failed to create transaction: %w", err)
+	}
+
+	return transaction, nil
+}
+
+// newTransaction validates a transaction and builds it with its merchant normalized
+func (s *service) newTransaction(ctx context.Context, userID uuid.UUID, req *CreateTransactionRequest, resolver *merchantResolver) (*Transaction, error) {
 	// Validate amount
 	if req.Amount == 0 {
-		span.RecordError(errors.New("amount cannot be zero"))
-		span.SetStatus(codes.Error, "amount cannot be zero")
 		return nil, errors.New("amount cannot be zero")
 	}
 
 	// Validate account exists and belongs to user
 	account, err := s.repo.GetAccountByID(ctx, req.AccountID)
 	if err != nil {
-		span.RecordError(err)
-		span.SetStatus(codes.Error, "failed to get account")
 		return nil, fmt.Errorf("failed to get account: %w", err)
 	}
 
 	if account.UserID != userID {
-		span.RecordError(errors.New("account does not belong to user"))
-		span.SetStatus(codes.Error, "account does not belong to user")
 		return nil, errors.New("account does not belong to user")
 	}
 
 	// Validate category if provided
 	if req.CategoryID != nil {
-		_, err := s.repo.GetCategoryByID(ctx, *req.CategoryID)
+		_, err := s.getVisibleCategory(ctx, userID, *req.CategoryID)
 		if err != nil {
-			span.RecordError(err)
-			span.SetStatus(codes.Error, "failed to get category")
 			return nil, fmt.Errorf("failed to get category: %w", err)
 		}
 	}
@@ -114,14 +250,12 @@ func (s *service) CreateTransaction(ctx context.Context, userID uuid.UUID, req *
 		Notes:           req.Notes,
 	}
 
-	if err := s.repo.CreateTransaction(ctx, transaction); err != nil {
-		span.RecordError(err)
-		span.SetStatus(codes.Error, "failed to create transaction")
-		return nil, fmt.Errorf("failed to create transaction: %w", err)
+	// Normalize the merchant
+	if err := resolver.applyMerchant(ctx, transaction, req.Merchant); err != nil {
+		return nil, err
 	}
 
-	span.SetStatus(codes.Ok, "transaction created successfully")
-	return s.toTransactionResponse(transaction), nil
+	return transaction, nil
 }
 
 // GetTransaction retrieves a transaction by ID
@@ -209,15 +343,16 @@ func (s *service) UpdateTransaction(ctx context.Context, userID, transactionID u
 	}
 
 	// Update fields if provided
+	var correction *analytics.CategoryCorrectionRequest
 	if req.CategoryID != nil {
-		// Validate category exists
-		_, err := s.repo.GetCategoryByID(ctx, *req.CategoryID)
+		// Validate category exists and is visible to the user
+		_, err := s.getVisibleCategory(ctx, userID, *req.CategoryID)
 		if err != nil {
 			span.RecordError(err)
 			span.SetStatus(codes.Error, "failed to get category")
 			return nil, fmt.Errorf("failed to get category: %w", err)
 		}
-		transaction.CategoryID = req.CategoryID
+		correction = changeCategory(transaction, *req.CategoryID)
 	}
 
 	if req.Amount != nil {
@@ -239,6 +374,11 @@ func (s *service) UpdateTransaction(ctx context.Context, userID, transactionID u
 
 	if req.Merchant != "" {
 		transaction.Merchant = req.Merchant
+		if err := s.newMerchantResolver(transaction.UserID).applyMerchant(ctx, transaction, req.Merchant); err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, "failed to normalize merchant")
+			return nil, err
+		}
 	}
 
 	if req.Location != "" {
@@ -265,6 +405,10 @@ func (s *service) UpdateTransaction(ctx context.Context, userID, transactionID u
 		transaction.Notes = req.Notes
 	}
 
+	if req.IsTransfer != nil {
+		transaction.IsTransfer = *req.IsTransfer
+	}
+
 	transaction.UpdatedAt = time.Now()
 
 	if err := s.repo.UpdateTransaction(ctx, transaction); err != nil {
@@ -273,6 +417,13 @@ func (s *service) UpdateTransaction(ctx context.Context, userID, transactionID u
 		return nil, fmt.Errorf("failed to update transaction: %w", err)
 	}
 
+	// Failing to learn from a correction doesn't fail the update
+	if err := s.recordCorrection(ctx, userID, transaction, correction); err != nil {
+		span.RecordError(err)
+	}
+
+	s.notifyChange(ctx, userID)
+
 	span.SetStatus(codes.Ok, "transaction updated successfully")
 	return s.toTransactionResponse(transaction), nil
 }
@@ -307,16 +458,19 @@ func (s *service) DeleteTransaction(ctx context.Context, userID, transactionID u
 		return fmt.Errorf("failed to delete transaction: %w", err)
 	}
 
+	s.notifyChange(ctx, userID)
+
 	span.SetStatus(codes.Ok, "transaction deleted successfully")
 	return nil
 }
 
 // Category operations
 
-// CreateCategory creates a new category
-func (s *service) CreateCategory(ctx context.Context, req *CreateCategoryRequest) (*Category, error) {
+// CreateCategory creates a new category owned by the user, or by one of their families
+func (s *service) CreateCategory(ctx context.Context, userID uuid.UUID, req *CreateCategoryRequest) (*Category, error) {
 	ctx, span := otel.Tracer("transaction").Start(ctx, "CreateCategory",
 		trace.WithAttributes(
+			attribute.String("user_id", userID.String()),
 			attribute.String("name", req.Name),
 		),
 	)
@@ -327,9 +481,34 @@ func (s *service) CreateCategory(ctx context.Context, req *CreateCategoryRequest
 		Description: req.Description,
 		Icon:        req.Icon,
 		Color:       req.Color,
-		ParentID:    req.ParentID,
-		IsDefault:   req.IsDefault,
 		SortOrder:   req.SortOrder,
+		IsActive:    true,
+	}
+
+	if req.FamilyID != nil {
+		isMember, err := s.isFamilyMember(ctx, userID, *req.FamilyID)
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, "failed to check family membership")
+			return nil, fmt.Errorf("failed to check family membership: %w", err)
+		}
+		if !isMember {
+			span.RecordError(errors.New("user is not a member of the family"))
+			span.SetStatus(codes.Error, "user is not a member of the family")
+			return nil, errors.New("user is not a member of the family")
+		}
+		category.FamilyID = req.FamilyID
+	} else {
+		category.UserID = &userID
+	}
+
+	if req.ParentID != nil {
+		if err := s.validateCategoryParent(ctx, userID, nil, *req.ParentID); err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, "invalid parent category")
+			return nil, fmt.Errorf("invalid parent category: %w", err)
+		}
+		category.ParentID = req.ParentID
 	}
 
 	if err := s.repo.CreateCategory(ctx, category); err != nil {
@@ -342,16 +521,17 @@ func (s *service) CreateCategory(ctx context.Context, req *CreateCategoryRequest
 	return category, nil
 }
 
-// GetCategory retrieves a category by ID
-func (s *service) GetCategory(ctx context.Context, categoryID uuid.UUID) (*Category, error) {
+// GetCategory retrieves a category visible to the user by ID
+func (s *service) GetCategory(ctx context.Context, userID, categoryID uuid.UUID) (*Category, error) {
 	ctx, span := otel.Tracer("transaction").Start(ctx, "GetCategory",
 		trace.WithAttributes(
+			attribute.String("user_id", userID.String()),
 			attribute.String("category_id", categoryID.String()),
 		),
 	)
 	defer span.End()
 
-	category, err := s.repo.GetCategoryByID(ctx, categoryID)
+	category, err := s.getVisibleCategory(ctx, userID, categoryID)
 	if err != nil {
 		span.RecordError(err)
 		span.SetStatus(codes.Error, "failed to get category")
@@ -362,10 +542,12 @@ func (s *service) GetCategory(ctx context.Context, categoryID uuid.UUID) (*Categ
 	return category, nil
 }
 
-// GetCategories retrieves all categories with pagination
-func (s *service) GetCategories(ctx context.Context, offset, limit int) ([]Category, error) {
+// GetCategories retrieves the system categories merged with the user's and their
+// families' categories with pagination
+func (s *service) GetCategories(ctx context.Context, userID uuid.UUID, offset, limit int) ([]Category, error) {
 	ctx, span := otel.Tracer("transaction").Start(ctx, "GetCategories",
 		trace.WithAttributes(
+			attribute.String("user_id", userID.String()),
 			attribute.Int("offset", offset),
 			attribute.Int("limit", limit),
 		),
@@ -376,7 +558,21 @@ func (s *service) GetCategories(ctx context.Context, offset, limit int) ([]Categ
 		limit = 100
 	}
 
-	categories, err := s.repo.GetCategories(ctx, offset, limit)
+	familyIDs, err := s.repo.GetFamilyIDsByUser(ctx, userID)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "failed to get families")
+		return nil, fmt.Errorf("failed to get families: %w", err)
+	}
+
+	locale, err := s.userCategoryLocale(ctx, userID)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "failed to get category locale")
+		return nil, fmt.Errorf("failed to get category locale: %w", err)
+	}
+
+	categories, err := s.repo.GetCategories(ctx, userID, familyIDs, locale, offset, limit)
 	if err != nil {
 		span.RecordError(err)
 		span.SetStatus(codes.Error, "failed to get categories")
@@ -387,12 +583,17 @@ func (s *service) GetCategories(ctx context.Context, offset, limit int) ([]Categ
 	return categories, nil
 }
 
-// GetDefaultCategories retrieves default categories
-func (s *service) GetDefaultCategories(ctx context.Context) ([]Category, error) {
-	ctx, span := otel.Tracer("transaction").Start(ctx, "GetDefaultCategories")
+// GetDefaultCategories retrieves the default categories of the seed set for a locale
+func (s *service) GetDefaultCategories(ctx context.Context, locale string) ([]Category, error) {
+	locale = seeds.ResolveLocale(locale)
+	ctx, span := otel.Tracer("transaction").Start(ctx, "GetDefaultCategories",
+		trace.WithAttributes(
+			attribute.String("locale", locale),
+		),
+	)
 	defer span.End()
 
-	categories, err := s.repo.GetDefaultCategories(ctx)
+	categories, err := s.repo.GetDefaultCategories(ctx, locale)
 	if err != nil {
 		span.RecordError(err)
 		span.SetStatus(codes.Error, "failed to get default categories")
@@ -403,29 +604,37 @@ func (s *service) GetDefaultCategories(ctx context.Context) ([]Category, error)
 	return categories, nil
 }
 
-// UpdateCategory updates a category
-func (s *service) UpdateCategory(ctx context.Context, categoryID uuid.UUID, req *CreateCategoryRequest) (*Category, error) {
+// UpdateCategory updates a category owned by the user or one of their families
+func (s *service) UpdateCategory(ctx context.Context, userID, categoryID uuid.UUID, req *CreateCategoryRequest) (*Category, error) {
 	ctx, span := otel.Tracer("transaction").Start(ctx, "UpdateCategory",
 		trace.WithAttributes(
+			attribute.String("user_id", userID.String()),
 			attribute.String("category_id", categoryID.String()),
 		),
 	)
 	defer span.End()
 
-	category, err := s.repo.GetCategoryByID(ctx, categoryID)
+	category, err := s.getOwnedCategory(ctx, userID, categoryID)
 	if err != nil {
 		span.RecordError(err)
 		span.SetStatus(codes.Error, "failed to get category")
 		return nil, fmt.Errorf("failed to get category: %w", err)
 	}
 
+	if req.ParentID != nil {
+		if err := s.validateCategoryParent(ctx, userID, &categoryID, *req.ParentID); err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, "invalid parent category")
+			return nil, fmt.Errorf("invalid parent category: %w", err)
+		}
+	}
+
 	// Update fields
 	category.Name = req.Name
 	category.Description = req.Description
 	category.Icon = req.Icon
 	category.Color = req.Color
 	category.ParentID = req.ParentID
-	category.IsDefault = req.IsDefault
 	category.SortOrder = req.SortOrder
 	category.UpdatedAt = time.Now()
 
@@ -439,15 +648,45 @@ func (s *service) UpdateCategory(ctx context.Context, categoryID uuid.UUID, req
 	return category, nil
 }
 
-// DeleteCategory deletes a category
-func (s *service) DeleteCategory(ctx context.Context, categoryID uuid.UUID) error {
+// DeleteCategory deletes a category owned by the user or one of their families
+func (s *service) DeleteCategory(ctx context.Context, userID, categoryID uuid.UUID, reassignTo *uuid.UUID) error {
 	ctx, span := otel.Tracer("transaction").Start(ctx, "DeleteCategory",
 		trace.WithAttributes(
+			attribute.String("user_id", userID.String()),
 			attribute.String("category_id", categoryID.String()),
 		),
 	)
 	defer span.End()
 
+	category, err := s.getOwnedCategory(ctx, userID, categoryID)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "failed to get category")
+		return fmt.Errorf("failed to get category: %w", err)
+	}
+
+	// Anything still referencing the category is moved to the reassignment target
+	if reassignTo != nil {
+		if _, err := s.mergeCategory(ctx, userID, category, *reassignTo); err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, "failed to reassign category")
+			return fmt.Errorf("failed to reassign category: %w", err)
+		}
+		span.SetStatus(codes.Ok, "category deleted successfully")
+		return nil
+	}
+
+	inUse, err := s.repo.HasCategoryReferences(ctx, categoryID)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "failed to check category references")
+		return fmt.Errorf("failed to check category references: %w", err)
+	}
+	if inUse {
+		span.SetStatus(codes.Error, "category is still in use")
+		return ErrCategoryInUse
+	}
+
 	if err := s.repo.DeleteCategory(ctx, categoryID); err != nil {
 		span.RecordError(err)
 		span.SetStatus(codes.Error, "failed to delete category")
@@ -458,6 +697,306 @@ func (s *service) DeleteCategory(ctx context.Context, categoryID uuid.UUID) erro
 	return nil
 }
 
+// MergeCategory merges a category into a target category, moving its transactions,
+// budget allocations, rules and subcategories before deleting it
+func (s *service) MergeCategory(ctx context.Context, userID, categoryID uuid.UUID, req *MergeCategoryRequest) (*Category, error) {
+	ctx, span := otel.Tracer("transaction").Start(ctx, "MergeCategory",
+		trace.WithAttributes(
+			attribute.String("user_id", userID.String()),
+			attribute.String("category_id", categoryID.String()),
+			attribute.String("target_id", req.TargetID.String()),
+		),
+	)
+	defer span.End()
+
+	category, err := s.getOwnedCategory(ctx, userID, categoryID)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "failed to get category")
+		return nil, fmt.Errorf("failed to get category: %w", err)
+	}
+
+	target, err := s.mergeCategory(ctx, userID, category, req.TargetID)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "failed to merge category")
+		return nil, fmt.Errorf("failed to merge category: %w", err)
+	}
+
+	span.SetStatus(codes.Ok, "category merged successfully")
+	return target, nil
+}
+
+// GetCategoryTree retrieves the categories visible to the user arranged as a tree
+func (s *service) GetCategoryTree(ctx context.Context, userID uuid.UUID) ([]CategoryNode, error) {
+	ctx, span := otel.Tracer("transaction").Start(ctx, "GetCategoryTree",
+		trace.WithAttributes(
+			attribute.String("user_id", userID.String()),
+		),
+	)
+	defer span.End()
+
+	familyIDs, err := s.repo.GetFamilyIDsByUser(ctx, userID)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "failed to get families")
+		return nil, fmt.Errorf("failed to get families: %w", err)
+	}
+
+	locale, err := s.userCategoryLocale(ctx, userID)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "failed to get category locale")
+		return nil, fmt.Errorf("failed to get category locale: %w", err)
+	}
+
+	// A limit of -1 disables pagination
+	categories, err := s.repo.GetCategories(ctx, userID, familyIDs, locale, 0, -1)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "failed to get categories")
+		return nil, fmt.Errorf("failed to get categories: %w", err)
+	}
+
+	span.SetStatus(codes.Ok, "category tree retrieved successfully")
+	return BuildCategoryTree(categories), nil
+}
+
+// MoveCategory moves a category and its subcategories under a new parent
+func (s *service) MoveCategory(ctx context.Context, userID, categoryID uuid.UUID, req *MoveCategoryRequest) (*Category, error) {
+	ctx, span := otel.Tracer("transaction").Start(ctx, "MoveCategory",
+		trace.WithAttributes(
+			attribute.String("user_id", userID.String()),
+			attribute.String("category_id", categoryID.String()),
+		),
+	)
+	defer span.End()
+
+	category, err := s.getOwnedCategory(ctx, userID, categoryID)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "failed to get category")
+		return nil, fmt.Errorf("failed to get category: %w", err)
+	}
+
+	if req.ParentID != nil {
+		if err := s.validateCategoryParent(ctx, userID, &categoryID, *req.ParentID); err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, "invalid parent category")
+			return nil, fmt.Errorf("invalid parent category: %w", err)
+		}
+	}
+
+	category.ParentID = req.ParentID
+	category.UpdatedAt = time.Now()
+
+	if err := s.repo.UpdateCategory(ctx, category); err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "failed to move category")
+		return nil, fmt.Errorf("failed to move category: %w", err)
+	}
+
+	span.SetStatus(codes.Ok, "category moved successfully")
+	return category, nil
+}
+
+// HideCategory hides a system category from the user's category list
+func (s *service) HideCategory(ctx context.Context, userID, categoryID uuid.UUID) error {
+	ctx, span := otel.Tracer("transaction").Start(ctx, "HideCategory",
+		trace.WithAttributes(
+			attribute.String("user_id", userID.String()),
+			attribute.String("category_id", categoryID.String()),
+		),
+	)
+	defer span.End()
+
+	category, err := s.repo.GetCategoryByID(ctx, categoryID)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "failed to get category")
+		return fmt.Errorf("failed to get category: %w", err)
+	}
+
+	if category.Scope() != CategoryScopeSystem {
+		span.RecordError(errors.New("only system categories can be hidden"))
+		span.SetStatus(codes.Error, "only system categories can be hidden")
+		return errors.New("only system categories can be hidden")
+	}
+
+	hidden := &HiddenCategory{
+		UserID:     userID,
+		CategoryID: categoryID,
+		CreatedAt:  time.Now(),
+	}
+	if err := s.repo.HideCategory(ctx, hidden); err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "failed to hide category")
+		return fmt.Errorf("failed to hide category: %w", err)
+	}
+
+	span.SetStatus(codes.Ok, "category hidden successfully")
+	return nil
+}
+
+// UnhideCategory shows a hidden system category in the user's category list again
+func (s *service) UnhideCategory(ctx context.Context, userID, categoryID uuid.UUID) error {
+	ctx, span := otel.Tracer("transaction").Start(ctx, "UnhideCategory",
+		trace.WithAttributes(
+			attribute.String("user_id", userID.String()),
+			attribute.String("category_id", categoryID.String()),
+		),
+	)
+	defer span.End()
+
+	if err := s.repo.UnhideCategory(ctx, userID, categoryID); err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "failed to unhide category")
+		return fmt.Errorf("failed to unhide category: %w", err)
+	}
+
+	span.SetStatus(codes.Ok, "category unhidden successfully")
+	return nil
+}
+
+// Merchant operations
+
+// CreateMerchant creates a new merchant
+func (s *service) CreateMerchant(ctx context.Context, req *CreateMerchantRequest) (*Merchant, error) {
+	ctx, span := otel.Tracer("transaction").Start(ctx, "CreateMerchant",
+		trace.WithAttributes(
+			attribute.String("name", req.Name),
+		),
+	)
+	defer span.End()
+
+	merchant := &Merchant{}
+	if err := s.applyMerchantRequest(ctx, merchant, req); err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, err
+	}
+
+	if err := s.repo.CreateMerchant(ctx, merchant); err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "failed to create merchant")
+		return nil, fmt.Errorf("failed to create merchant: %w", err)
+	}
+	s.merchants.invalidate()
+
+	span.SetStatus(codes.Ok, "merchant created successfully")
+	return merchant, nil
+}
+
+// GetMerchant retrieves a shared merchant or one of the user's own merchants by ID
+func (s *service) GetMerchant(ctx context.Context, userID, merchantID uuid.UUID) (*Merchant, error) {
+	ctx, span := otel.Tracer("transaction").Start(ctx, "GetMerchant",
+		trace.WithAttributes(
+			attribute.String("user_id", userID.String()),
+			attribute.String("merchant_id", merchantID.String()),
+		),
+	)
+	defer span.End()
+
+	merchant, err := s.repo.GetMerchantByID(ctx, merchantID)
+	if err == nil && merchant.UserID != nil && *merchant.UserID != userID {
+		err = ErrMerchantNotFound
+	}
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "failed to get merchant")
+		return nil, fmt.Errorf("failed to get merchant: %w", err)
+	}
+
+	span.SetStatus(codes.Ok, "merchant retrieved successfully")
+	return merchant, nil
+}
+
+// GetMerchants retrieves the shared merchants and the user's own merchants with pagination
+func (s *service) GetMerchants(ctx context.Context, userID uuid.UUID, offset, limit int) ([]Merchant, error) {
+	ctx, span := otel.Tracer("transaction").Start(ctx, "GetMerchants",
+		trace.WithAttributes(
+			attribute.String("user_id", userID.String()),
+			attribute.Int("offset", offset),
+			attribute.Int("limit", limit),
+		),
+	)
+	defer span.End()
+
+	if limit <= 0 {
+		limit = 100
+	}
+
+	merchants, err := s.repo.GetMerchants(ctx, userID, offset, limit)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "failed to get merchants")
+		return nil, fmt.Errorf("failed to get merchants: %w", err)
+	}
+
+	span.SetStatus(codes.Ok, "merchants retrieved successfully")
+	return merchants, nil
+}
+
+// UpdateMerchant updates a shared merchant
+func (s *service) UpdateMerchant(ctx context.Context, merchantID uuid.UUID, req *CreateMerchantRequest) (*Merchant, error) {
+	ctx, span := otel.Tracer("transaction").Start(ctx, "UpdateMerchant",
+		trace.WithAttributes(
+			attribute.String("merchant_id", merchantID.String()),
+		),
+	)
+	defer span.End()
+
+	merchant, err := s.getSharedMerchant(ctx, merchantID)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "failed to get merchant")
+		return nil, fmt.Errorf("failed to get merchant: %w", err)
+	}
+
+	if err := s.applyMerchantRequest(ctx, merchant, req); err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, err
+	}
+	merchant.UpdatedAt = time.Now()
+
+	if err := s.repo.UpdateMerchant(ctx, merchant); err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "failed to update merchant")
+		return nil, fmt.Errorf("failed to update merchant: %w", err)
+	}
+	s.merchants.invalidate()
+
+	span.SetStatus(codes.Ok, "merchant updated successfully")
+	return merchant, nil
+}
+
+// DeleteMerchant deletes a shared merchant
+func (s *service) DeleteMerchant(ctx context.Context, merchantID uuid.UUID) error {
+	ctx, span := otel.Tracer("transaction").Start(ctx, "DeleteMerchant",
+		trace.WithAttributes(
+			attribute.String("merchant_id", merchantID.String()),
+		),
+	)
+	defer span.End()
+
+	if _, err := s.getSharedMerchant(ctx, merchantID); err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "failed to get merchant")
+		return fmt.Errorf("failed to get merchant: %w", err)
+	}
+
+	if err := s.repo.DeleteMerchant(ctx, merchantID); err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "failed to delete merchant")
+		return fmt.Errorf("failed to delete merchant: %w", err)
+	}
+	s.merchants.invalidate()
+
+	span.SetStatus(codes.Ok, "merchant deleted successfully")
+	return nil
+}
+
 // Account operations
 
 // CreateAccount creates a new account
@@ -625,6 +1164,160 @@ func (s *service) DeleteAccount(ctx context.Context, userID, accountID uuid.UUID
 
 // Helper methods
 
+// userCategoryLocale returns the default category seed set the user was given at registration
+func (s *service) userCategoryLocale(ctx context.Context, userID uuid.UUID) (string, error) {
+	locale, err := s.repo.GetUserCategoryLocale(ctx, userID)
+	if err != nil {
+		return "", err
+	}
+	return seeds.ResolveLocale(locale), nil
+}
+
+// mergeCategory validates the merge target and merges the source category into it
+func (s *service) mergeCategory(ctx context.Context, userID uuid.UUID, source *Category, targetID uuid.UUID) (*Category, error) {
+	if targetID == source.ID {
+		return nil, ErrInvalidMergeTarget
+	}
+
+	target, err := s.getVisibleCategory(ctx, userID, targetID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Other family members must still be able to see the category their transactions end up in
+	if source.Scope() == CategoryScopeFamily && target.Scope() == CategoryScopeUser {
+		return nil, ErrInvalidMergeTarget
+	}
+
+	// Subcategories move under the target, which must not create a cycle or exceed the depth limit
+	children, err := s.repo.GetChildCategories(ctx, source.ID)
+	if err != nil {
+		return nil, err
+	}
+	for _, child := range children {
+		if err := s.validateCategoryParent(ctx, userID, &child.ID, targetID); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := s.repo.MergeCategory(ctx, source.ID, targetID); err != nil {
+		return nil, err
+	}
+	// Merchants may have defaulted to the merged category, and rules categorized into it
+	s.merchants.invalidate()
+	if s.categorization.Categorizer != nil {
+		s.categorization.Categorizer.CategoriesMerged(ctx)
+	}
+	return target, nil
+}
+
+// getVisibleCategory retrieves a category the user can see: a system category, one of
+// their own categories or a category of a family they belong to. Categories the user
+// cannot see are reported as not found
+func (s *service) getVisibleCategory(ctx context.Context, userID, categoryID uuid.UUID) (*Category, error) {
+	category, err := s.repo.GetCategoryByID(ctx, categoryID)
+	if err != nil {
+		return nil, err
+	}
+
+	switch category.Scope() {
+	case CategoryScopeUser:
+		if *category.UserID != userID {
+			return nil, ErrCategoryNotFound
+		}
+	case CategoryScopeFamily:
+		isMember, err := s.isFamilyMember(ctx, userID, *category.FamilyID)
+		if err != nil {
+			return nil, err
+		}
+		if !isMember {
+			return nil, ErrCategoryNotFound
+		}
+	}
+
+	return category, nil
+}
+
+// getOwnedCategory retrieves a category the user may modify. System categories are
+// visible to everyone but owned by no one
+func (s *service) getOwnedCategory(ctx context.Context, userID, categoryID uuid.UUID) (*Category, error) {
+	category, err := s.getVisibleCategory(ctx, userID, categoryID)
+	if err != nil {
+		return nil, err
+	}
+	if category.Scope() == CategoryScopeSystem {
+		return nil, ErrCategoryNotOwned
+	}
+	return category, nil
+}
+
+// isFamilyMember checks whether a user belongs to a family
+func (s *service) isFamilyMember(ctx context.Context, userID, familyID uuid.UUID) (bool, error) {
+	familyIDs, err := s.repo.GetFamilyIDsByUser(ctx, userID)
+	if err != nil {
+		return false, err
+	}
+	for _, id := range familyIDs {
+		if id == familyID {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// getSharedMerchant retrieves a merchant shared by all users. Merchants discovered in a
+// user's transactions are reported as not found
+func (s *service) getSharedMerchant(ctx context.Context, merchantID uuid.UUID) (*Merchant, error) {
+	merchant, err := s.repo.GetMerchantByID(ctx, merchantID)
+	if err != nil {
+		return nil, err
+	}
+	if merchant.UserID != nil {
+		return nil, ErrMerchantNotFound
+	}
+	return merchant, nil
+}
+
+// applyMerchantRequest validates a merchant request and copies it onto the merchant
+func (s *service) applyMerchantRequest(ctx context.Context, merchant *Merchant, req *CreateMerchantRequest) error {
+	normalized := NormalizeMerchantName(req.Name)
+	if normalized == "" {
+		return errors.New("merchant name must contain letters")
+	}
+
+	for _, pattern := range req.Patterns {
+		if _, err := regexp.Compile(pattern); err != nil {
+			return fmt.Errorf("invalid merchant pattern %q: %w", pattern, err)
+		}
+	}
+
+	// Merchants are shared by all users, so only system categories can be their default
+	if req.DefaultCategoryID != nil {
+		category, err := s.repo.GetCategoryByID(ctx, *req.DefaultCategoryID)
+		if err != nil {
+			return fmt.Errorf("failed to get category: %w", err)
+		}
+		if category.Scope() != CategoryScopeSystem {
+			return errors.New("merchant default category must be a system category")
+		}
+	}
+
+	metadata := req.Metadata
+	if metadata == "" {
+		metadata = "{}"
+	}
+
+	merchant.Name = req.Name
+	merchant.NormalizedName = normalized
+	merchant.Aliases = normalizeMerchantAliases(req.Aliases)
+	merchant.Patterns = req.Patterns
+	merchant.DefaultCategoryID = req.DefaultCategoryID
+	merchant.LogoURL = req.LogoURL
+	merchant.Website = req.Website
+	merchant.Metadata = metadata
+	return nil
+}
+
 // toTransactionResponse converts a Transaction to TransactionResponse
 func (s *service) toTransactionResponse(transaction *Transaction) *TransactionResponse {
 	return &TransactionResponse{
@@ -637,14 +1330,18 @@ func (s *service) toTransactionResponse(transaction *Transaction) *TransactionRe
 		Currency:                 transaction.Currency,
 		Description:              transaction.Description,
 		Merchant:                 transaction.Merchant,
+		MerchantID:               transaction.MerchantID,
+		RawMerchant:              transaction.RawMerchant,
 		Location:                 transaction.Location,
 		TransactionDate:          transaction.TransactionDate,
 		PostedDate:               transaction.PostedDate,
 		Status:                   transaction.Status,
 		CategorizationSource:     transaction.CategorizationSource,
 		CategorizationConfidence: transaction.CategorizationConfidence,
+		SuggestedCategoryID:      transaction.SuggestedCategoryID,
 		Tags:                     transaction.Tags,
 		Notes:                    transaction.Notes,
+		IsTransfer:               transaction.IsTransfer,
 		ReceiptURL:               transaction.ReceiptURL,
 		CreatedAt:                transaction.CreatedAt,
 		UpdatedAt:                transaction.UpdatedAt,
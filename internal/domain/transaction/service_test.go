@@ -9,11 +9,20 @@ import (
 	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+
+	"fiscaflow/internal/domain/analytics"
 )
 
 type mockRepository struct {
 	mock.Mock
-	userID uuid.UUID
+	userID      uuid.UUID
+	merchants   []Merchant
+	sharedLoads int
+	categories  []Category
+	familyIDs   []uuid.UUID
+	hidden      []HiddenCategory
+	referenced  map[uuid.UUID]bool
+	merged      map[uuid.UUID]uuid.UUID
 }
 
 // Implement Repository interface methods for mockRepository
@@ -35,6 +44,10 @@ func (m *mockRepository) GetTransactionsByUser(ctx context.Context, userID uuid.
 func (m *mockRepository) GetTransactionsByAccount(ctx context.Context, accountID uuid.UUID, offset, limit int) ([]Transaction, error) {
 	return nil, nil
 }
+func (m *mockRepository) GetTransactionsForReview(ctx context.Context, userID uuid.UUID, offset, limit int) ([]Transaction, error) {
+	args := m.Called(ctx, userID, offset, limit)
+	return args.Get(0).([]Transaction), args.Error(1)
+}
 func (m *mockRepository) UpdateTransaction(ctx context.Context, t *Transaction) error {
 	args := m.Called(ctx, t)
 	return args.Error(0)
@@ -45,17 +58,100 @@ func (m *mockRepository) DeleteTransaction(ctx context.Context, id uuid.UUID) er
 }
 func (m *mockRepository) CreateCategory(ctx context.Context, c *Category) error { return nil }
 func (m *mockRepository) GetCategoryByID(ctx context.Context, id uuid.UUID) (*Category, error) {
+	for i := range m.categories {
+		if m.categories[i].ID == id {
+			category := m.categories[i]
+			return &category, nil
+		}
+	}
 	return &Category{}, nil
 }
-func (m *mockRepository) GetCategories(ctx context.Context, offset, limit int) ([]Category, error) {
-	return nil, nil
+func (m *mockRepository) GetCategories(ctx context.Context, userID uuid.UUID, familyIDs []uuid.UUID, locale string, offset, limit int) ([]Category, error) {
+	return m.categories, nil
 }
-func (m *mockRepository) GetDefaultCategories(ctx context.Context) ([]Category, error) {
+func (m *mockRepository) GetDefaultCategories(ctx context.Context, locale string) ([]Category, error) {
 	return nil, nil
 }
+func (m *mockRepository) GetChildCategories(ctx context.Context, parentID uuid.UUID) ([]Category, error) {
+	var children []Category
+	for _, category := range m.categories {
+		if category.ParentID != nil && *category.ParentID == parentID {
+			children = append(children, category)
+		}
+	}
+	return children, nil
+}
 func (m *mockRepository) UpdateCategory(ctx context.Context, c *Category) error  { return nil }
 func (m *mockRepository) DeleteCategory(ctx context.Context, id uuid.UUID) error { return nil }
-func (m *mockRepository) CreateAccount(ctx context.Context, a *Account) error    { return nil }
+func (m *mockRepository) HasCategoryReferences(ctx context.Context, id uuid.UUID) (bool, error) {
+	return m.referenced[id], nil
+}
+func (m *mockRepository) MergeCategory(ctx context.Context, sourceID, targetID uuid.UUID) error {
+	if m.merged == nil {
+		m.merged = make(map[uuid.UUID]uuid.UUID)
+	}
+	m.merged[sourceID] = targetID
+	return nil
+}
+func (m *mockRepository) HideCategory(ctx context.Context, hidden *HiddenCategory) error {
+	m.hidden = append(m.hidden, *hidden)
+	return nil
+}
+func (m *mockRepository) UnhideCategory(ctx context.Context, userID, categoryID uuid.UUID) error {
+	return nil
+}
+func (m *mockRepository) GetFamilyIDsByUser(ctx context.Context, userID uuid.UUID) ([]uuid.UUID, error) {
+	return m.familyIDs, nil
+}
+func (m *mockRepository) GetUserCategoryLocale(ctx context.Context, userID uuid.UUID) (string, error) {
+	return "", nil
+}
+func (m *mockRepository) CreateMerchant(ctx context.Context, merchant *Merchant) error {
+	merchant.ID = uuid.New()
+	m.merchants = append(m.merchants, *merchant)
+	return nil
+}
+func (m *mockRepository) GetMerchantByID(ctx context.Context, id uuid.UUID) (*Merchant, error) {
+	for i := range m.merchants {
+		if m.merchants[i].ID == id {
+			return &m.merchants[i], nil
+		}
+	}
+	return nil, ErrMerchantNotFound
+}
+func (m *mockRepository) GetMerchantByNormalizedName(ctx context.Context, userID uuid.UUID, normalizedName string) (*Merchant, error) {
+	for i := range m.merchants {
+		if m.merchants[i].UserID != nil && *m.merchants[i].UserID == userID && m.merchants[i].NormalizedName == normalizedName {
+			return &m.merchants[i], nil
+		}
+	}
+	return nil, ErrMerchantNotFound
+}
+func (m *mockRepository) GetMerchants(ctx context.Context, userID uuid.UUID, offset, limit int) ([]Merchant, error) {
+	return m.merchants, nil
+}
+func (m *mockRepository) GetSharedMerchants(ctx context.Context) ([]Merchant, error) {
+	m.sharedLoads++
+	var merchants []Merchant
+	for _, merchant := range m.merchants {
+		if merchant.UserID == nil {
+			merchants = append(merchants, merchant)
+		}
+	}
+	return merchants, nil
+}
+func (m *mockRepository) GetMerchantsByUser(ctx context.Context, userID uuid.UUID) ([]Merchant, error) {
+	var merchants []Merchant
+	for _, merchant := range m.merchants {
+		if merchant.UserID != nil && *merchant.UserID == userID {
+			merchants = append(merchants, merchant)
+		}
+	}
+	return merchants, nil
+}
+func (m *mockRepository) UpdateMerchant(ctx context.Context, merchant *Merchant) error { return nil }
+func (m *mockRepository) DeleteMerchant(ctx context.Context, id uuid.UUID) error       { return nil }
+func (m *mockRepository) CreateAccount(ctx context.Context, a *Account) error          { return nil }
 func (m *mockRepository) GetAccountByID(ctx context.Context, id uuid.UUID) (*Account, error) {
 	return &Account{ID: id, UserID: m.userID}, nil
 }
@@ -69,7 +165,7 @@ func TestTransactionService_CreateGetUpdateDelete(t *testing.T) {
 	repo := new(mockRepository)
 	userID := uuid.New()
 	repo.userID = userID
-	svc := NewService(repo)
+	svc := NewService(repo, CategorizationConfig{})
 	ctx := context.Background()
 	accountID := uuid.New()
 	transactionID := uuid.New()
@@ -120,7 +216,7 @@ func TestTransactionService_CreateGetUpdateDelete(t *testing.T) {
 
 func TestTransactionService_CreateTransaction_AccountNotFound(t *testing.T) {
 	repo := new(mockRepository)
-	svc := NewService(repo)
+	svc := NewService(repo, CategorizationConfig{})
 	ctx := context.Background()
 	userID := uuid.New()
 	repo.userID = userID
@@ -139,4 +235,512 @@ func TestTransactionService_CreateTransaction_AccountNotFound(t *testing.T) {
 	assert.Error(t, err)
 	assert.Nil(t, resp)
 }
- 
\ No newline at end of file
+
+func TestTransactionService_CreateTransaction_NormalizesMerchant(t *testing.T) {
+	repo := new(mockRepository)
+	userID := uuid.New()
+	repo.userID = userID
+	categoryID := uuid.New()
+	amazon := Merchant{
+		ID:                uuid.New(),
+		Name:              "Amazon",
+		NormalizedName:    "AMAZON",
+		Aliases:           []string{"AMZN"},
+		DefaultCategoryID: &categoryID,
+	}
+	repo.merchants = []Merchant{amazon}
+	svc := NewService(repo, CategorizationConfig{})
+	ctx := context.Background()
+
+	repo.On("CreateTransaction", mock.Anything, mock.AnythingOfType("*transaction.Transaction")).Return(nil)
+
+	resp, err := svc.CreateTransaction(ctx, userID, &CreateTransactionRequest{
+		AccountID:       uuid.New(),
+		Amount:          -25.99,
+		Description:     "Online order",
+		Merchant:        "AMZN MKTP US*2K3",
+		TransactionDate: time.Now(),
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, "Amazon", resp.Merchant)
+	assert.Equal(t, "AMZN MKTP US*2K3", resp.RawMerchant)
+	assert.Equal(t, &amazon.ID, resp.MerchantID)
+	assert.Equal(t, &categoryID, resp.CategoryID)
+	assert.Equal(t, CategorizationSourceMerchant, resp.CategorizationSource)
+
+	// Unknown merchants are created on the fly
+	resp, err = svc.CreateTransaction(ctx, userID, &CreateTransactionRequest{
+		AccountID:       uuid.New(),
+		Amount:          -4.50,
+		Description:     "Coffee",
+		Merchant:        "SQ *BLUE BOTTLE COFFEE #123",
+		TransactionDate: time.Now(),
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, "Blue Bottle Coffee", resp.Merchant)
+	assert.Nil(t, resp.CategoryID)
+	assert.Len(t, repo.merchants, 2)
+	assert.Equal(t, &userID, repo.merchants[1].UserID)
+}
+
+func TestTransactionService_ResolveMerchant_ScopedToUser(t *testing.T) {
+	repo := new(mockRepository)
+	svc := NewService(repo, CategorizationConfig{})
+	ctx := context.Background()
+
+	repo.On("CreateTransaction", mock.Anything, mock.AnythingOfType("*transaction.Transaction")).Return(nil)
+
+	create := func(userID uuid.UUID, merchant string) *TransactionResponse {
+		repo.userID = userID
+		resp, err := svc.CreateTransaction(ctx, userID, &CreateTransactionRequest{
+			AccountID:       uuid.New(),
+			Amount:          -12,
+			Description:     "Purchase",
+			Merchant:        merchant,
+			TransactionDate: time.Now(),
+		})
+		assert.NoError(t, err)
+		return resp
+	}
+
+	// Merchants discovered in one user's transactions are not shared with others
+	alice, bob := uuid.New(), uuid.New()
+	first := create(alice, "DR SMITH CLINIC")
+	second := create(bob, "DR SMITH CLINIC")
+	assert.NotEqual(t, first.MerchantID, second.MerchantID)
+	assert.Equal(t, first.MerchantID, create(alice, "Dr Smith Clinic").MerchantID)
+	assert.Len(t, repo.merchants, 2)
+
+	_, err := svc.GetMerchant(ctx, bob, *first.MerchantID)
+	assert.ErrorIs(t, err, ErrMerchantNotFound)
+
+	// Shared merchants are loaded once and again after they change
+	assert.Equal(t, 1, repo.sharedLoads)
+	_, err = svc.CreateMerchant(ctx, &CreateMerchantRequest{Name: "Smith Clinic", Aliases: []string{"DR SMITH"}})
+	assert.NoError(t, err)
+	shared := create(bob, "DR SMITH CLINIC")
+	assert.Equal(t, repo.merchants[2].ID, *shared.MerchantID)
+	assert.Equal(t, 2, repo.sharedLoads)
+}
+
+func TestTransactionService_ImportTransactions(t *testing.T) {
+	repo := new(mockRepository)
+	userID := uuid.New()
+	repo.userID = userID
+	svc := NewService(repo, CategorizationConfig{})
+	ctx := context.Background()
+
+	repo.On("CreateTransaction", mock.Anything, mock.AnythingOfType("*transaction.Transaction")).Return(nil)
+
+	resp, err := svc.ImportTransactions(ctx, userID, &ImportTransactionsRequest{
+		Transactions: []CreateTransactionRequest{
+			{AccountID: uuid.New(), Amount: -10, Description: "Groceries", Merchant: "WALMART #1234", TransactionDate: time.Now()},
+			{AccountID: uuid.New(), Amount: 0, Description: "Invalid", TransactionDate: time.Now()},
+			{AccountID: uuid.New(), Amount: -20, Description: "More groceries", Merchant: "Walmart", TransactionDate: time.Now()},
+		},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, 2, resp.ImportedCount)
+	assert.Equal(t, 1, resp.FailedCount)
+	assert.Equal(t, 1, resp.Failed[0].Index)
+	assert.Equal(t, resp.Imported[0].MerchantID, resp.Imported[1].MerchantID)
+	assert.Len(t, repo.merchants, 1)
+}
+
+type recordingListener struct {
+	changes []uuid.UUID
+}
+
+func (l *recordingListener) TransactionsChanged(ctx context.Context, userID uuid.UUID) {
+	l.changes = append(l.changes, userID)
+}
+
+func TestTransactionService_NotifiesListeners(t *testing.T) {
+	repo := new(mockRepository)
+	userID := uuid.New()
+	repo.userID = userID
+	listener := &recordingListener{}
+	svc := NewService(repo, CategorizationConfig{}, listener)
+	ctx := context.Background()
+
+	repo.On("CreateTransaction", mock.Anything, mock.AnythingOfType("*transaction.Transaction")).Return(nil)
+
+	// An import notifies once, and only when something was imported
+	_, err := svc.ImportTransactions(ctx, userID, &ImportTransactionsRequest{
+		Transactions: []CreateTransactionRequest{{AccountID: uuid.New(), Amount: 0, Description: "Invalid", TransactionDate: time.Now()}},
+	})
+	assert.NoError(t, err)
+	assert.Empty(t, listener.changes)
+
+	_, err = svc.ImportTransactions(ctx, userID, &ImportTransactionsRequest{
+		Transactions: []CreateTransactionRequest{
+			{AccountID: uuid.New(), Amount: -10, Description: "Groceries", TransactionDate: time.Now()},
+			{AccountID: uuid.New(), Amount: -20, Description: "More groceries", TransactionDate: time.Now()},
+		},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, []uuid.UUID{userID}, listener.changes)
+
+	// Changes by another user are rejected without notifying
+	transactionID := uuid.New()
+	repo.On("GetTransactionByID", mock.Anything, transactionID).Return(&Transaction{ID: transactionID, UserID: uuid.New()}, nil)
+	assert.Error(t, svc.DeleteTransaction(ctx, userID, transactionID))
+	assert.Len(t, listener.changes, 1)
+}
+
+type recordingCategorizer struct {
+	suggestion  *analytics.CategorizationResponse
+	corrections []analytics.CategoryCorrectionRequest
+	batches     []int
+	merges      int
+}
+
+func (c *recordingCategorizer) CategorizeTransaction(ctx context.Context, req *analytics.CategorizationRequest) (*analytics.CategorizationResponse, error) {
+	if c.suggestion == nil {
+		return nil, errors.New("no category suggested")
+	}
+	return c.suggestion, nil
+}
+
+func (c *recordingCategorizer) CategorizeTransactions(ctx context.Context, req *analytics.BatchCategorizationRequest) (*analytics.BatchCategorizationResponse, error) {
+	c.batches = append(c.batches, len(req.Transactions))
+	resp := &analytics.BatchCategorizationResponse{Categorizations: make([]analytics.CategorizationResponse, len(req.Transactions))}
+	for i := range req.Transactions {
+		if c.suggestion != nil {
+			resp.Categorizations[i] = *c.suggestion
+		}
+	}
+	return resp, nil
+}
+
+func (c *recordingCategorizer) RecordCorrection(ctx context.Context, userID uuid.UUID, req *analytics.CategoryCorrectionRequest) error {
+	c.corrections = append(c.corrections, *req)
+	return nil
+}
+
+func (c *recordingCategorizer) CategoriesMerged(ctx context.Context) {
+	c.merges++
+}
+
+func TestTransactionService_RecordsCorrections(t *testing.T) {
+	userID := uuid.New()
+	suggested := Category{ID: uuid.New(), Name: "Dining", IsDefault: true}
+	corrected := Category{ID: uuid.New(), Name: "Groceries", IsDefault: true}
+
+	repo := &mockRepository{categories: []Category{suggested, corrected}}
+	categorizer := &recordingCategorizer{}
+	svc := NewService(repo, CategorizationConfig{Categorizer: categorizer})
+	ctx := context.Background()
+
+	ruleSet := &Transaction{ID: uuid.New(), UserID: userID, CategoryID: &suggested.ID, CategorizationSource: CategorizationSourceRule, Description: "Whole Foods Market", Merchant: "Whole Foods", Amount: -54.2}
+	manual := &Transaction{ID: uuid.New(), UserID: userID, CategoryID: &suggested.ID, CategorizationSource: CategorizationSourceManual, Description: "Lunch"}
+	repo.On("GetTransactionByID", mock.Anything, ruleSet.ID).Return(ruleSet, nil)
+	repo.On("GetTransactionByID", mock.Anything, manual.ID).Return(manual, nil)
+	repo.On("UpdateTransaction", mock.Anything, mock.AnythingOfType("*transaction.Transaction")).Return(nil)
+
+	// Changing a category set by a rule is a correction
+	resp, err := svc.UpdateTransaction(ctx, userID, ruleSet.ID, &UpdateTransactionRequest{CategoryID: &corrected.ID})
+	assert.NoError(t, err)
+	assert.Equal(t, CategorizationSourceUserCorrection, resp.CategorizationSource)
+	if assert.Len(t, categorizer.corrections, 1) {
+		assert.Equal(t, analytics.CategoryCorrectionRequest{
+			TransactionID:      ruleSet.ID,
+			Description:        "Whole Foods Market",
+			Merchant:           "Whole Foods",
+			Amount:             -54.2,
+			PreviousCategoryID: &suggested.ID,
+			PreviousSource:     "rule",
+			CategoryID:         corrected.ID,
+		}, categorizer.corrections[0])
+	}
+
+	// Categories the user chose themselves, or set again, are not
+	_, err = svc.UpdateTransaction(ctx, userID, manual.ID, &UpdateTransactionRequest{CategoryID: &corrected.ID})
+	assert.NoError(t, err)
+	_, err = svc.UpdateTransaction(ctx, userID, ruleSet.ID, &UpdateTransactionRequest{CategoryID: &corrected.ID})
+	assert.NoError(t, err)
+	assert.Len(t, categorizer.corrections, 1)
+}
+
+func TestTransactionService_AutoCategorizes(t *testing.T) {
+	userID := uuid.New()
+	otherUserID := uuid.New()
+	dining := Category{ID: uuid.New(), Name: "Dining", IsDefault: true}
+	private := Category{ID: uuid.New(), Name: "Side business", UserID: &otherUserID}
+
+	repo := &mockRepository{userID: userID, categories: []Category{dining, private}}
+	categorizer := &recordingCategorizer{}
+	svc := NewService(repo, CategorizationConfig{Categorizer: categorizer, Threshold: 0.8})
+	ctx := context.Background()
+
+	repo.On("CreateTransaction", mock.Anything, mock.AnythingOfType("*transaction.Transaction")).Return(nil)
+	req := &CreateTransactionRequest{AccountID: uuid.New(), Amount: -12.5, Description: "Blue Bottle Coffee", TransactionDate: time.Now()}
+
+	// Confident suggestions are applied, along with the actions of the rule
+	categorizer.suggestion = &analytics.CategorizationResponse{
+		CategoryID:           dining.ID,
+		Confidence:           0.92,
+		CategorizationSource: "rule",
+		Actions:              &analytics.RuleActions{Tags: []string{"coffee", "work"}, Notes: "Team coffee"},
+	}
+	tagged := *req
+	tagged.Tags = []string{"work"}
+	resp, err := svc.CreateTransaction(ctx, userID, &tagged)
+	assert.NoError(t, err)
+	assert.Equal(t, &dining.ID, resp.CategoryID)
+	assert.Nil(t, resp.SuggestedCategoryID)
+	assert.Equal(t, CategorizationSourceRule, resp.CategorizationSource)
+	if assert.NotNil(t, resp.CategorizationConfidence) {
+		assert.Equal(t, 0.92, *resp.CategorizationConfidence)
+	}
+	assert.Equal(t, []string{"work", "coffee"}, resp.Tags)
+	assert.Equal(t, "Team coffee", resp.Notes)
+	assert.False(t, resp.IsTransfer)
+
+	// Others are kept for review
+	categorizer.suggestion = &analytics.CategorizationResponse{CategoryID: dining.ID, Confidence: 0.55, CategorizationSource: "ml"}
+	resp, err = svc.CreateTransaction(ctx, userID, req)
+	assert.NoError(t, err)
+	assert.Nil(t, resp.CategoryID)
+	assert.Equal(t, &dining.ID, resp.SuggestedCategoryID)
+	assert.Equal(t, CategorizationSourceML, resp.CategorizationSource)
+
+	// Categories the user chose, and suggestions of categories they can't see, are left alone
+	chosen := *req
+	chosen.CategoryID = &dining.ID
+	resp, err = svc.CreateTransaction(ctx, userID, &chosen)
+	assert.NoError(t, err)
+	assert.Nil(t, resp.CategorizationConfidence)
+
+	categorizer.suggestion = &analytics.CategorizationResponse{CategoryID: private.ID, Confidence: 0.95, CategorizationSource: "rule"}
+	resp, err = svc.CreateTransaction(ctx, userID, req)
+	assert.NoError(t, err)
+	assert.Nil(t, resp.CategoryID)
+	assert.Nil(t, resp.SuggestedCategoryID)
+
+	// Imports ask for the categories of all uncategorized transactions at once
+	categorizer.suggestion = &analytics.CategorizationResponse{CategoryID: dining.ID, Confidence: 0.92, CategorizationSource: "rule"}
+	imported, err := svc.ImportTransactions(ctx, userID, &ImportTransactionsRequest{
+		Transactions: []CreateTransactionRequest{*req, chosen, {AccountID: uuid.New(), Description: "Invalid", TransactionDate: time.Now()}, *req},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, []int{2}, categorizer.batches)
+	assert.Equal(t, 3, imported.ImportedCount)
+	for _, transaction := range imported.Imported {
+		assert.Equal(t, &dining.ID, transaction.CategoryID)
+	}
+}
+
+func TestTransactionService_ReviewCategory(t *testing.T) {
+	userID := uuid.New()
+	suggested := Category{ID: uuid.New(), Name: "Dining", IsDefault: true}
+	corrected := Category{ID: uuid.New(), Name: "Groceries", IsDefault: true}
+	confidence := 0.55
+
+	repo := &mockRepository{categories: []Category{suggested, corrected}}
+	categorizer := &recordingCategorizer{}
+	svc := NewService(repo, CategorizationConfig{Categorizer: categorizer, Threshold: 0.8})
+	ctx := context.Background()
+
+	accepted := &Transaction{ID: uuid.New(), UserID: userID, SuggestedCategoryID: &suggested.ID, CategorizationSource: CategorizationSourceML, CategorizationConfidence: &confidence}
+	rejected := &Transaction{ID: uuid.New(), UserID: userID, SuggestedCategoryID: &suggested.ID, CategorizationSource: CategorizationSourceML, Description: "Trader Joe's", Amount: -31}
+	reviewed := &Transaction{ID: uuid.New(), UserID: userID, CategoryID: &suggested.ID, CategorizationSource: CategorizationSourceManual}
+	repo.On("GetTransactionsForReview", mock.Anything, userID, 0, 20).Return([]Transaction{*accepted, *rejected}, nil)
+	repo.On("GetTransactionByID", mock.Anything, accepted.ID).Return(accepted, nil)
+	repo.On("GetTransactionByID", mock.Anything, rejected.ID).Return(rejected, nil)
+	repo.On("GetTransactionByID", mock.Anything, reviewed.ID).Return(reviewed, nil)
+	repo.On("UpdateTransaction", mock.Anything, mock.AnythingOfType("*transaction.Transaction")).Return(nil)
+
+	queue, err := svc.GetReviewQueue(ctx, userID, 0, 20)
+	assert.NoError(t, err)
+	if assert.Len(t, queue, 2) {
+		assert.Equal(t, &suggested.ID, queue[0].SuggestedCategoryID)
+	}
+
+	// Accepting a suggestion keeps it automatic
+	resp, err := svc.ReviewCategory(ctx, userID, accepted.ID, &ReviewCategoryRequest{})
+	assert.NoError(t, err)
+	assert.Equal(t, &suggested.ID, resp.CategoryID)
+	assert.Nil(t, resp.SuggestedCategoryID)
+	assert.Equal(t, CategorizationSourceML, resp.CategorizationSource)
+	assert.Empty(t, categorizer.corrections)
+
+	// Choosing another category is a correction
+	resp, err = svc.ReviewCategory(ctx, userID, rejected.ID, &ReviewCategoryRequest{CategoryID: &corrected.ID})
+	assert.NoError(t, err)
+	assert.Equal(t, &corrected.ID, resp.CategoryID)
+	assert.Equal(t, CategorizationSourceUserCorrection, resp.CategorizationSource)
+	if assert.Len(t, categorizer.corrections, 1) {
+		assert.Equal(t, &suggested.ID, categorizer.corrections[0].PreviousCategoryID)
+		assert.Equal(t, corrected.ID, categorizer.corrections[0].CategoryID)
+		assert.Equal(t, "Trader Joe's", categorizer.corrections[0].Description)
+	}
+
+	// Transactions without a suggestion have nothing to review
+	_, err = svc.ReviewCategory(ctx, userID, reviewed.ID, &ReviewCategoryRequest{})
+	assert.ErrorIs(t, err, ErrNothingToReview)
+	_, err = svc.ReviewCategory(ctx, uuid.New(), accepted.ID, &ReviewCategoryRequest{})
+	assert.Error(t, err)
+}
+
+func TestTransactionService_CategoryOwnership(t *testing.T) {
+	userID := uuid.New()
+	otherUserID := uuid.New()
+	familyID := uuid.New()
+	otherFamilyID := uuid.New()
+
+	system := Category{ID: uuid.New(), Name: "Groceries", IsDefault: true}
+	own := Category{ID: uuid.New(), Name: "Hobbies", UserID: &userID}
+	others := Category{ID: uuid.New(), Name: "Secret", UserID: &otherUserID}
+	family := Category{ID: uuid.New(), Name: "Kids", FamilyID: &familyID}
+	otherFamily := Category{ID: uuid.New(), Name: "Pets", FamilyID: &otherFamilyID}
+
+	repo := &mockRepository{
+		categories: []Category{system, own, others, family, otherFamily},
+		familyIDs:  []uuid.UUID{familyID},
+	}
+	svc := NewService(repo, CategorizationConfig{})
+	ctx := context.Background()
+
+	t.Run("visible categories", func(t *testing.T) {
+		for _, category := range []Category{system, own, family} {
+			got, err := svc.GetCategory(ctx, userID, category.ID)
+			assert.NoError(t, err)
+			assert.Equal(t, category.ID, got.ID)
+		}
+	})
+
+	t.Run("other users' categories are not found", func(t *testing.T) {
+		for _, category := range []Category{others, otherFamily} {
+			_, err := svc.GetCategory(ctx, userID, category.ID)
+			assert.ErrorIs(t, err, ErrCategoryNotFound)
+		}
+	})
+
+	t.Run("system categories cannot be modified", func(t *testing.T) {
+		_, err := svc.UpdateCategory(ctx, userID, system.ID, &CreateCategoryRequest{Name: "Food"})
+		assert.ErrorIs(t, err, ErrCategoryNotOwned)
+		assert.ErrorIs(t, svc.DeleteCategory(ctx, userID, system.ID, nil), ErrCategoryNotOwned)
+	})
+
+	t.Run("owned categories can be modified", func(t *testing.T) {
+		updated, err := svc.UpdateCategory(ctx, userID, family.ID, &CreateCategoryRequest{Name: "Children"})
+		assert.NoError(t, err)
+		assert.Equal(t, "Children", updated.Name)
+		assert.NoError(t, svc.DeleteCategory(ctx, userID, own.ID, nil))
+	})
+
+	t.Run("create scopes category to user or family", func(t *testing.T) {
+		created, err := svc.CreateCategory(ctx, userID, &CreateCategoryRequest{Name: "Travel"})
+		assert.NoError(t, err)
+		assert.Equal(t, CategoryScopeUser, created.Scope())
+		assert.Equal(t, userID, *created.UserID)
+
+		created, err = svc.CreateCategory(ctx, userID, &CreateCategoryRequest{Name: "Holidays", FamilyID: &familyID})
+		assert.NoError(t, err)
+		assert.Equal(t, CategoryScopeFamily, created.Scope())
+
+		_, err = svc.CreateCategory(ctx, userID, &CreateCategoryRequest{Name: "Holidays", FamilyID: &otherFamilyID})
+		assert.Error(t, err)
+	})
+
+	t.Run("only system categories can be hidden", func(t *testing.T) {
+		assert.NoError(t, svc.HideCategory(ctx, userID, system.ID))
+		assert.Len(t, repo.hidden, 1)
+		assert.Error(t, svc.HideCategory(ctx, userID, own.ID))
+	})
+}
+
+func TestTransactionService_CategoryHierarchy(t *testing.T) {
+	userID := uuid.New()
+	level0 := Category{ID: uuid.New(), UserID: &userID, Name: "Level 0"}
+	level1 := Category{ID: uuid.New(), UserID: &userID, Name: "Level 1", ParentID: &level0.ID}
+	level2 := Category{ID: uuid.New(), UserID: &userID, Name: "Level 2", ParentID: &level1.ID}
+	level3 := Category{ID: uuid.New(), UserID: &userID, Name: "Level 3", ParentID: &level2.ID}
+	other := Category{ID: uuid.New(), UserID: &userID, Name: "Other"}
+	otherChild := Category{ID: uuid.New(), UserID: &userID, Name: "Other Child", ParentID: &other.ID}
+	repo := &mockRepository{userID: userID, categories: []Category{level0, level1, level2, level3, other, otherChild}}
+	svc := NewService(repo, CategorizationConfig{})
+	ctx := context.Background()
+
+	t.Run("tree", func(t *testing.T) {
+		tree, err := svc.GetCategoryTree(ctx, userID)
+		assert.NoError(t, err)
+		if assert.Len(t, tree, 2) {
+			assert.Equal(t, level0.ID, tree[0].ID)
+			assert.Equal(t, level3.ID, tree[0].Children[0].Children[0].Children[0].ID)
+		}
+	})
+
+	t.Run("moving under a descendant is a cycle", func(t *testing.T) {
+		_, err := svc.MoveCategory(ctx, userID, level1.ID, &MoveCategoryRequest{ParentID: &level2.ID})
+		assert.ErrorIs(t, err, ErrCategoryCycle)
+		_, err = svc.MoveCategory(ctx, userID, level1.ID, &MoveCategoryRequest{ParentID: &level1.ID})
+		assert.ErrorIs(t, err, ErrCategoryCycle)
+	})
+
+	t.Run("depth limit", func(t *testing.T) {
+		_, err := svc.CreateCategory(ctx, userID, &CreateCategoryRequest{Name: "Level 4", ParentID: &level3.ID})
+		assert.ErrorIs(t, err, ErrCategoryTooDeep)
+		// Other's subcategory would end up below the deepest level
+		_, err = svc.MoveCategory(ctx, userID, other.ID, &MoveCategoryRequest{ParentID: &level2.ID})
+		assert.ErrorIs(t, err, ErrCategoryTooDeep)
+	})
+
+	t.Run("valid moves", func(t *testing.T) {
+		moved, err := svc.MoveCategory(ctx, userID, level2.ID, &MoveCategoryRequest{ParentID: &other.ID})
+		assert.NoError(t, err)
+		assert.Equal(t, other.ID, *moved.ParentID)
+		moved, err = svc.MoveCategory(ctx, userID, level1.ID, &MoveCategoryRequest{})
+		assert.NoError(t, err)
+		assert.Nil(t, moved.ParentID)
+	})
+}
+
+func TestTransactionService_DeleteAndMergeCategory(t *testing.T) {
+	userID := uuid.New()
+	familyID := uuid.New()
+	system := Category{ID: uuid.New(), Name: "Food", IsDefault: true}
+	groceries := Category{ID: uuid.New(), UserID: &userID, Name: "Groceries"}
+	supermarket := Category{ID: uuid.New(), UserID: &userID, Name: "Supermarket", ParentID: &groceries.ID}
+	family := Category{ID: uuid.New(), FamilyID: &familyID, Name: "Kids"}
+	repo := &mockRepository{
+		userID:     userID,
+		familyIDs:  []uuid.UUID{familyID},
+		categories: []Category{system, groceries, supermarket, family},
+		referenced: map[uuid.UUID]bool{groceries.ID: true},
+	}
+	categorizer := &recordingCategorizer{}
+	svc := NewService(repo, CategorizationConfig{Categorizer: categorizer})
+	ctx := context.Background()
+
+	t.Run("in use category requires a target", func(t *testing.T) {
+		assert.ErrorIs(t, svc.DeleteCategory(ctx, userID, groceries.ID, nil), ErrCategoryInUse)
+		assert.Empty(t, repo.merged)
+	})
+
+	t.Run("invalid targets", func(t *testing.T) {
+		_, err := svc.MergeCategory(ctx, userID, groceries.ID, &MergeCategoryRequest{TargetID: groceries.ID})
+		assert.ErrorIs(t, err, ErrInvalidMergeTarget)
+		_, err = svc.MergeCategory(ctx, userID, groceries.ID, &MergeCategoryRequest{TargetID: supermarket.ID})
+		assert.ErrorIs(t, err, ErrCategoryCycle)
+		_, err = svc.MergeCategory(ctx, userID, family.ID, &MergeCategoryRequest{TargetID: groceries.ID})
+		assert.ErrorIs(t, err, ErrInvalidMergeTarget)
+		assert.Empty(t, repo.merged)
+		assert.Zero(t, categorizer.merges)
+	})
+
+	t.Run("delete with reassignment merges", func(t *testing.T) {
+		assert.NoError(t, svc.DeleteCategory(ctx, userID, groceries.ID, &system.ID))
+		assert.Equal(t, system.ID, repo.merged[groceries.ID])
+	})
+
+	t.Run("merge returns target", func(t *testing.T) {
+		target, err := svc.MergeCategory(ctx, userID, family.ID, &MergeCategoryRequest{TargetID: system.ID})
+		assert.NoError(t, err)
+		assert.Equal(t, system.ID, target.ID)
+		assert.Equal(t, system.ID, repo.merged[family.ID])
+		// The categorizer loads the rules that moved to the target again
+		assert.Equal(t, 2, categorizer.merges)
+	})
+}
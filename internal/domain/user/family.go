@@ -0,0 +1,114 @@
+package user
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Family errors
+var (
+	ErrInvalidInviteCode      = errors.New("invalid invite code")
+	ErrAlreadyFamilyMember    = errors.New("user already belongs to the family")
+	ErrNotFamilyMember        = errors.New("user does not belong to the family")
+	ErrFamilyOwnerCannotLeave = errors.New("the family owner cannot leave the family")
+)
+
+// CreateFamily creates a family owned by the user. The owner is its first member and
+// shares its invite code with the people they want to join
+func (s *service) CreateFamily(ctx context.Context, userID uuid.UUID, req *CreateFamilyRequest) (*Family, error) {
+	inviteCode, err := generateInviteCode()
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate invite code: %w", err)
+	}
+
+	now := time.Now()
+	family := &Family{
+		ID:         uuid.New(),
+		Name:       req.Name,
+		OwnerID:    userID,
+		InviteCode: inviteCode,
+		CreatedAt:  now,
+		UpdatedAt:  now,
+	}
+	owner := &FamilyMember{
+		ID:        uuid.New(),
+		UserID:    userID,
+		Role:      UserRoleFamilyOwner,
+		CreatedAt: now,
+	}
+
+	if err := s.repo.CreateFamily(ctx, family, owner); err != nil {
+		return nil, fmt.Errorf("failed to create family: %w", err)
+	}
+
+	return family, nil
+}
+
+// JoinFamily adds the user to the family the invite code belongs to
+func (s *service) JoinFamily(ctx context.Context, userID uuid.UUID, req *JoinFamilyRequest) (*Family, error) {
+	family, err := s.repo.GetFamilyByInviteCode(ctx, req.InviteCode)
+	if err != nil {
+		if errors.Is(err, ErrFamilyNotFound) {
+			return nil, ErrInvalidInviteCode
+		}
+		return nil, err
+	}
+
+	_, err = s.repo.GetFamilyMember(ctx, family.ID, userID)
+	if err == nil {
+		return nil, ErrAlreadyFamilyMember
+	}
+	if !errors.Is(err, ErrFamilyMemberNotFound) {
+		return nil, err
+	}
+
+	member := &FamilyMember{
+		ID:        uuid.New(),
+		FamilyID:  family.ID,
+		UserID:    userID,
+		Role:      UserRoleFamilyMember,
+		CreatedAt: time.Now(),
+	}
+	if err := s.repo.AddFamilyMember(ctx, member); err != nil {
+		return nil, fmt.Errorf("failed to join family: %w", err)
+	}
+
+	// Only the owner invites others
+	family.InviteCode = ""
+	return family, nil
+}
+
+// LeaveFamily removes the user from a family. The owner stays, since the family's shared
+// categories and budgets are theirs
+func (s *service) LeaveFamily(ctx context.Context, userID, familyID uuid.UUID) error {
+	member, err := s.repo.GetFamilyMember(ctx, familyID, userID)
+	if err != nil {
+		if errors.Is(err, ErrFamilyMemberNotFound) {
+			return ErrNotFamilyMember
+		}
+		return err
+	}
+	if member.Role == UserRoleFamilyOwner {
+		return ErrFamilyOwnerCannotLeave
+	}
+
+	if err := s.repo.RemoveFamilyMember(ctx, familyID, userID); err != nil {
+		return fmt.Errorf("failed to leave family: %w", err)
+	}
+	return nil
+}
+
+// generateInviteCode generates a random code for joining a family
+func generateInviteCode() (string, error) {
+	bytes := make([]byte, 8)
+	if _, err := rand.Read(bytes); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(bytes), nil
+}
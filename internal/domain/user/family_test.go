@@ -0,0 +1,143 @@
+package user
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestUserService_CreateFamily(t *testing.T) {
+	mockRepo := &MockRepository{}
+	service := NewService(mockRepo, "test-secret")
+	ownerID := uuid.New()
+
+	mockRepo.On("CreateFamily", mock.Anything, mock.AnythingOfType("*user.Family"), mock.MatchedBy(func(owner *FamilyMember) bool {
+		return owner.UserID == ownerID && owner.Role == UserRoleFamilyOwner
+	})).Return(nil)
+
+	family, err := service.CreateFamily(context.Background(), ownerID, &CreateFamilyRequest{Name: "Doe household"})
+
+	assert.NoError(t, err)
+	assert.Equal(t, "Doe household", family.Name)
+	assert.Equal(t, ownerID, family.OwnerID)
+	assert.Len(t, family.InviteCode, 16)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestUserService_JoinFamily(t *testing.T) {
+	ownerID := uuid.New()
+	userID := uuid.New()
+	family := &Family{ID: uuid.New(), Name: "Doe household", OwnerID: ownerID, InviteCode: "0123456789abcdef"}
+
+	tests := []struct {
+		name        string
+		inviteCode  string
+		setupMocks  func(*MockRepository)
+		expectedErr error
+	}{
+		{
+			name:       "joins as a member",
+			inviteCode: family.InviteCode,
+			setupMocks: func(m *MockRepository) {
+				found := *family
+				m.On("GetFamilyByInviteCode", mock.Anything, family.InviteCode).Return(&found, nil)
+				m.On("GetFamilyMember", mock.Anything, family.ID, userID).Return(nil, ErrFamilyMemberNotFound)
+				m.On("AddFamilyMember", mock.Anything, mock.MatchedBy(func(member *FamilyMember) bool {
+					return member.FamilyID == family.ID && member.UserID == userID && member.Role == UserRoleFamilyMember
+				})).Return(nil)
+			},
+		},
+		{
+			name:       "unknown invite code",
+			inviteCode: "unknown",
+			setupMocks: func(m *MockRepository) {
+				m.On("GetFamilyByInviteCode", mock.Anything, "unknown").Return(nil, ErrFamilyNotFound)
+			},
+			expectedErr: ErrInvalidInviteCode,
+		},
+		{
+			name:       "already a member",
+			inviteCode: family.InviteCode,
+			setupMocks: func(m *MockRepository) {
+				found := *family
+				m.On("GetFamilyByInviteCode", mock.Anything, family.InviteCode).Return(&found, nil)
+				m.On("GetFamilyMember", mock.Anything, family.ID, userID).Return(&FamilyMember{FamilyID: family.ID, UserID: userID}, nil)
+			},
+			expectedErr: ErrAlreadyFamilyMember,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := &MockRepository{}
+			tt.setupMocks(mockRepo)
+			service := NewService(mockRepo, "test-secret")
+
+			joined, err := service.JoinFamily(context.Background(), userID, &JoinFamilyRequest{InviteCode: tt.inviteCode})
+
+			if tt.expectedErr != nil {
+				assert.ErrorIs(t, err, tt.expectedErr)
+				assert.Nil(t, joined)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, family.ID, joined.ID)
+				// Members don't see the invite code
+				assert.Empty(t, joined.InviteCode)
+			}
+			mockRepo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestUserService_LeaveFamily(t *testing.T) {
+	familyID := uuid.New()
+	userID := uuid.New()
+
+	tests := []struct {
+		name        string
+		setupMocks  func(*MockRepository)
+		expectedErr error
+	}{
+		{
+			name: "member leaves",
+			setupMocks: func(m *MockRepository) {
+				m.On("GetFamilyMember", mock.Anything, familyID, userID).Return(&FamilyMember{FamilyID: familyID, UserID: userID, Role: UserRoleFamilyMember}, nil)
+				m.On("RemoveFamilyMember", mock.Anything, familyID, userID).Return(nil)
+			},
+		},
+		{
+			name: "owner cannot leave",
+			setupMocks: func(m *MockRepository) {
+				m.On("GetFamilyMember", mock.Anything, familyID, userID).Return(&FamilyMember{FamilyID: familyID, UserID: userID, Role: UserRoleFamilyOwner}, nil)
+			},
+			expectedErr: ErrFamilyOwnerCannotLeave,
+		},
+		{
+			name: "not a member",
+			setupMocks: func(m *MockRepository) {
+				m.On("GetFamilyMember", mock.Anything, familyID, userID).Return(nil, ErrFamilyMemberNotFound)
+			},
+			expectedErr: ErrNotFamilyMember,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := &MockRepository{}
+			tt.setupMocks(mockRepo)
+			service := NewService(mockRepo, "test-secret")
+
+			err := service.LeaveFamily(context.Background(), userID, familyID)
+
+			if tt.expectedErr != nil {
+				assert.ErrorIs(t, err, tt.expectedErr)
+			} else {
+				assert.NoError(t, err)
+			}
+			mockRepo.AssertExpectations(t)
+		})
+	}
+}
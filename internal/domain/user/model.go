@@ -17,6 +17,7 @@ type User struct {
 	DateOfBirth      *time.Time `json:"date_of_birth"`
 	Timezone         string     `json:"timezone" gorm:"default:'UTC'"`
 	Locale           string     `json:"locale" gorm:"default:'en-US'"`
+	CategoryLocale   string     `json:"category_locale" gorm:"default:'en-US'"` // Default category set chosen at registration
 	Role             UserRole   `json:"role" gorm:"default:'user'"`
 	Status           UserStatus `json:"status" gorm:"default:'active'"`
 	EmailVerified    bool       `json:"email_verified" gorm:"default:false"`
@@ -48,6 +49,25 @@ const (
 	UserStatusDeleted   UserStatus = "deleted"
 )
 
+// Family represents a group of users sharing categories and budgets
+type Family struct {
+	ID         uuid.UUID `json:"id" gorm:"type:uuid;primary_key;default:gen_random_uuid()"`
+	Name       string    `json:"name" gorm:"not null"`
+	OwnerID    uuid.UUID `json:"owner_id" gorm:"type:uuid;not null"`
+	InviteCode string    `json:"invite_code,omitempty" gorm:"uniqueIndex"` // Lets others join, only shown to the owner
+	CreatedAt  time.Time `json:"created_at"`
+	UpdatedAt  time.Time `json:"updated_at"`
+}
+
+// FamilyMember represents a user's membership of a family
+type FamilyMember struct {
+	ID        uuid.UUID `json:"id" gorm:"type:uuid;primary_key;default:gen_random_uuid()"`
+	FamilyID  uuid.UUID `json:"family_id" gorm:"type:uuid;not null;uniqueIndex:idx_family_members_family_user"`
+	UserID    uuid.UUID `json:"user_id" gorm:"type:uuid;not null;uniqueIndex:idx_family_members_family_user;index"`
+	Role      UserRole  `json:"role" gorm:"not null;default:'family_member'"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
 // DeviceInfo represents device information for a session
 type DeviceInfo struct {
 	DeviceType string `json:"device_type,omitempty"`
@@ -98,6 +118,16 @@ type UpdateUserRequest struct {
 	Locale      string     `json:"locale"`
 }
 
+// CreateFamilyRequest represents a request to create a family
+type CreateFamilyRequest struct {
+	Name string `json:"name" binding:"required"`
+}
+
+// JoinFamilyRequest represents a request to join a family with its invite code
+type JoinFamilyRequest struct {
+	InviteCode string `json:"invite_code" binding:"required"`
+}
+
 // UserResponse represents a user response
 type UserResponse struct {
 	ID            uuid.UUID  `json:"id"`
@@ -122,6 +152,16 @@ func (User) TableName() string {
 	return "users"
 }
 
+// TableName specifies the table name for Family
+func (Family) TableName() string {
+	return "families"
+}
+
+// TableName specifies the table name for FamilyMember
+func (FamilyMember) TableName() string {
+	return "family_members"
+}
+
 // TableName specifies the table name for UserSession
 func (UserSession) TableName() string {
 	return "user_sessions"
@@ -20,6 +20,11 @@ type Repository interface {
 	GetSessionByRefreshToken(ctx context.Context, refreshToken string) (*UserSession, error)
 	RevokeSession(ctx context.Context, sessionID uuid.UUID) error
 	RevokeAllUserSessions(ctx context.Context, userID uuid.UUID) error
+	CreateFamily(ctx context.Context, family *Family, owner *FamilyMember) error
+	GetFamilyByInviteCode(ctx context.Context, inviteCode string) (*Family, error)
+	GetFamilyMember(ctx context.Context, familyID, userID uuid.UUID) (*FamilyMember, error)
+	AddFamilyMember(ctx context.Context, member *FamilyMember) error
+	RemoveFamilyMember(ctx context.Context, familyID, userID uuid.UUID) error
 }
 
 // repository implements the Repository interface
@@ -108,8 +113,57 @@ func (r *repository) RevokeAllUserSessions(ctx context.Context, userID uuid.UUID
 	return r.db.WithContext(ctx).Model(&UserSession{}).Where("user_id = ?", userID).Update("revoked_at", gorm.Expr("NOW()")).Error
 }
 
+// CreateFamily creates a family together with the membership of its owner
+func (r *repository) CreateFamily(ctx context.Context, family *Family, owner *FamilyMember) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(family).Error; err != nil {
+			return err
+		}
+		owner.FamilyID = family.ID
+		return tx.Create(owner).Error
+	})
+}
+
+// GetFamilyByInviteCode retrieves the family an invite code lets users join
+func (r *repository) GetFamilyByInviteCode(ctx context.Context, inviteCode string) (*Family, error) {
+	var family Family
+	err := r.db.WithContext(ctx).Where("invite_code = ?", inviteCode).First(&family).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrFamilyNotFound
+		}
+		return nil, err
+	}
+	return &family, nil
+}
+
+// GetFamilyMember retrieves the membership of a user in a family
+func (r *repository) GetFamilyMember(ctx context.Context, familyID, userID uuid.UUID) (*FamilyMember, error) {
+	var member FamilyMember
+	err := r.db.WithContext(ctx).Where("family_id = ? AND user_id = ?", familyID, userID).First(&member).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrFamilyMemberNotFound
+		}
+		return nil, err
+	}
+	return &member, nil
+}
+
+// AddFamilyMember adds a user to a family
+func (r *repository) AddFamilyMember(ctx context.Context, member *FamilyMember) error {
+	return r.db.WithContext(ctx).Create(member).Error
+}
+
+// RemoveFamilyMember removes a user from a family
+func (r *repository) RemoveFamilyMember(ctx context.Context, familyID, userID uuid.UUID) error {
+	return r.db.WithContext(ctx).Where("family_id = ? AND user_id = ?", familyID, userID).Delete(&FamilyMember{}).Error
+}
+
 // Custom errors
 var (
-	ErrUserNotFound    = errors.New("user not found")
-	ErrSessionNotFound = errors.New("session not found")
+	ErrUserNotFound         = errors.New("user not found")
+	ErrSessionNotFound      = errors.New("session not found")
+	ErrFamilyNotFound       = errors.New("family not found")
+	ErrFamilyMemberNotFound = errors.New("family member not found")
 )
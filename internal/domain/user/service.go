@@ -11,6 +11,8 @@ import (
 	"github.com/golang-jwt/jwt/v5"
 	"github.com/google/uuid"
 	"golang.org/x/crypto/bcrypt"
+
+	"fiscaflow/internal/seeds"
 )
 
 // Service defines the interface for user business logic
@@ -22,6 +24,11 @@ type Service interface {
 	RefreshToken(ctx context.Context, refreshToken string) (*LoginResponse, error)
 	Logout(ctx context.Context, userID uuid.UUID, sessionID uuid.UUID) error
 	ValidateToken(ctx context.Context, tokenString string) (*Claims, error)
+
+	// Family operations
+	CreateFamily(ctx context.Context, userID uuid.UUID, req *CreateFamilyRequest) (*Family, error)
+	JoinFamily(ctx context.Context, userID uuid.UUID, req *JoinFamilyRequest) (*Family, error)
+	LeaveFamily(ctx context.Context, userID, familyID uuid.UUID) error
 }
 
 // service implements the Service interface
@@ -91,6 +98,10 @@ func (s *service) Register(ctx context.Context, req *CreateUserRequest) (*UserRe
 		UpdatedAt:    time.Now(),
 	}
 
+	// The default categories are fixed at registration so later locale changes
+	// don't hide categories the user's transactions already use
+	user.CategoryLocale = seeds.ResolveLocale(req.Locale)
+
 	if err := s.repo.Create(ctx, user); err != nil {
 		return nil, fmt.Errorf("failed to create user: %w", err)
 	}
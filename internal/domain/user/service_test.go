@@ -76,6 +76,37 @@ func (m *MockRepository) RevokeAllUserSessions(ctx context.Context, userID uuid.
 	return args.Error(0)
 }
 
+func (m *MockRepository) CreateFamily(ctx context.Context, family *Family, owner *FamilyMember) error {
+	args := m.Called(ctx, family, owner)
+	return args.Error(0)
+}
+
+func (m *MockRepository) GetFamilyByInviteCode(ctx context.Context, inviteCode string) (*Family, error) {
+	args := m.Called(ctx, inviteCode)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*Family), args.Error(1)
+}
+
+func (m *MockRepository) GetFamilyMember(ctx context.Context, familyID, userID uuid.UUID) (*FamilyMember, error) {
+	args := m.Called(ctx, familyID, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*FamilyMember), args.Error(1)
+}
+
+func (m *MockRepository) AddFamilyMember(ctx context.Context, member *FamilyMember) error {
+	args := m.Called(ctx, member)
+	return args.Error(0)
+}
+
+func (m *MockRepository) RemoveFamilyMember(ctx context.Context, familyID, userID uuid.UUID) error {
+	args := m.Called(ctx, familyID, userID)
+	return args.Error(0)
+}
+
 func TestUserService_Register(t *testing.T) {
 	tests := []struct {
 		name          string
@@ -111,6 +142,31 @@ func TestUserService_Register(t *testing.T) {
 				Status:    UserStatusActive,
 			},
 		},
+		{
+			name: "registration picks default category locale",
+			request: &CreateUserRequest{
+				Email:     "test@example.com",
+				Password:  "password123",
+				FirstName: "Jan",
+				LastName:  "Muster",
+				Locale:    "de-AT",
+			},
+			mockSetup: func(repo *MockRepository) {
+				repo.On("GetByEmail", mock.Anything, "test@example.com").Return(nil, ErrUserNotFound)
+				repo.On("Create", mock.Anything, mock.MatchedBy(func(u *User) bool {
+					return u.Locale == "de-AT" && u.CategoryLocale == "de-DE"
+				})).Return(nil)
+			},
+			expectedError: nil,
+			expectedUser: &UserResponse{
+				Email:     "test@example.com",
+				FirstName: "Jan",
+				LastName:  "Muster",
+				Locale:    "de-AT",
+				Role:      UserRoleUser,
+				Status:    UserStatusActive,
+			},
+		},
 		{
 			name: "user already exists",
 			request: &CreateUserRequest{
@@ -11,6 +11,7 @@ import (
 
 	"fiscaflow/internal/domain/analytics"
 	"fiscaflow/internal/domain/budget"
+	"fiscaflow/internal/domain/notification"
 	"fiscaflow/internal/domain/transaction"
 	"fiscaflow/internal/domain/user"
 )
@@ -60,18 +61,102 @@ func NewDatabase(config *Config) (*Database, error) {
 
 // AutoMigrate runs database migrations
 func (d *Database) AutoMigrate() error {
-	return d.DB.AutoMigrate(
+	err := d.DB.AutoMigrate(
 		&user.User{},
 		&user.UserSession{},
+		&user.Family{},
+		&user.FamilyMember{},
 		&transaction.Transaction{},
 		&transaction.Category{},
+		&transaction.HiddenCategory{},
 		&transaction.Account{},
+		&transaction.Merchant{},
 		&budget.Budget{},
 		&budget.BudgetCategory{},
+		&budget.BudgetPeriod{},
+		&budget.BudgetPeriodCategory{},
+		&budget.EnvelopeTransfer{},
+		&budget.BudgetTemplate{},
+		&budget.BudgetTemplateCategory{},
+		&budget.BudgetAlertEvent{},
+		&budget.Goal{},
+		&budget.GoalAccount{},
+		&notification.Notification{},
+		&notification.Preferences{},
 		&analytics.CategorizationModel{},
 		&analytics.CategorizationRule{},
+		&analytics.CategorizationFeedback{},
+		&analytics.RuleApplication{},
+		&analytics.RuleApplicationChange{},
 		&analytics.SpendingAnalysis{},
+		&SeedVersion{},
 	)
+	if err != nil {
+		return err
+	}
+
+	if err := d.migrateMerchantIndexes(); err != nil {
+		return err
+	}
+
+	return d.addCategoryForeignKeys()
+}
+
+// migrateMerchantIndexes replaces the global unique merchant name with one per user.
+// Shared merchants have no user, so their names are kept unique by a partial index
+func (d *Database) migrateMerchantIndexes() error {
+	if err := d.DB.Exec("DROP INDEX IF EXISTS idx_merchants_normalized_name").Error; err != nil {
+		return fmt.Errorf("failed to drop merchant name index: %w", err)
+	}
+	err := d.DB.Exec("CREATE UNIQUE INDEX IF NOT EXISTS idx_merchants_shared_normalized_name ON merchants (normalized_name) WHERE user_id IS NULL").Error
+	if err != nil {
+		return fmt.Errorf("failed to create shared merchant name index: %w", err)
+	}
+	return nil
+}
+
+// categoryForeignKeys lists the columns that reference categories. Budget alert events
+// are left out: alerts on a whole budget are recorded with the nil category
+var categoryForeignKeys = []struct {
+	name   string
+	table  string
+	column string
+}{
+	{"fk_transactions_category", "transactions", "category_id"},
+	{"fk_budget_categories_category", "budget_categories", "category_id"},
+	{"fk_budget_period_categories_category", "budget_period_categories", "category_id"},
+	{"fk_envelope_transfers_from_category", "envelope_transfers", "from_category_id"},
+	{"fk_envelope_transfers_to_category", "envelope_transfers", "to_category_id"},
+	{"fk_budget_template_categories_category", "budget_template_categories", "category_id"},
+	{"fk_categorization_rules_category", "categorization_rules", "category_id"},
+	{"fk_categorization_feedback_category", "categorization_feedback", "category_id"},
+	{"fk_categorization_feedback_previous_category", "categorization_feedback", "previous_category_id"},
+	{"fk_merchants_default_category", "merchants", "default_category_id"},
+	{"fk_categories_parent", "categories", "parent_id"},
+}
+
+// addCategoryForeignKeys prevents categories from being deleted while they are still
+// referenced. Constraints are added NOT VALID so rows written before they existed
+// do not block startup; new writes are always checked
+func (d *Database) addCategoryForeignKeys() error {
+	for _, fk := range categoryForeignKeys {
+		var count int64
+		if err := d.DB.Raw("SELECT COUNT(*) FROM pg_constraint WHERE conname = ?", fk.name).Scan(&count).Error; err != nil {
+			return fmt.Errorf("failed to check constraint %s: %w", fk.name, err)
+		}
+		if count > 0 {
+			continue
+		}
+
+		err := d.DB.Exec(fmt.Sprintf(
+			"ALTER TABLE %s ADD CONSTRAINT %s FOREIGN KEY (%s) REFERENCES categories(id) ON DELETE RESTRICT NOT VALID",
+			fk.table, fk.name, fk.column,
+		)).Error
+		if err != nil {
+			return fmt.Errorf("failed to add constraint %s: %w", fk.name, err)
+		}
+	}
+	return nil
 }
 
 // Ping checks database connectivity
@@ -0,0 +1,140 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	"fiscaflow/internal/domain/analytics"
+	"fiscaflow/internal/domain/transaction"
+	"fiscaflow/internal/seeds"
+)
+
+// SeedVersion records the version of a seed set that has been applied
+type SeedVersion struct {
+	Name      string    `gorm:"primaryKey"`
+	Version   int       `gorm:"not null"`
+	AppliedAt time.Time `gorm:"not null"`
+}
+
+// TableName specifies the table name for SeedVersion
+func (SeedVersion) TableName() string {
+	return "seed_versions"
+}
+
+// SeedDefaultCategories applies every default category seed set whose version is newer
+// than the one recorded in the database. Running it again is a no-op
+func (d *Database) SeedDefaultCategories(ctx context.Context) error {
+	for _, set := range seeds.CategorySets() {
+		if err := d.applyCategorySet(ctx, set); err != nil {
+			return fmt.Errorf("failed to seed %s categories: %w", set.Locale, err)
+		}
+	}
+	return nil
+}
+
+// applyCategorySet creates or updates the categories and starter rules of a seed set
+func (d *Database) applyCategorySet(ctx context.Context, set seeds.CategorySet) error {
+	name := "categories:" + set.Locale
+
+	return d.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var applied SeedVersion
+		err := tx.Where("name = ?", name).First(&applied).Error
+		if err == nil && applied.Version >= set.Version {
+			return nil
+		}
+		if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			return err
+		}
+
+		var apply func(categorySeeds []seeds.CategorySeed, parentID *uuid.UUID) error
+		apply = func(categorySeeds []seeds.CategorySeed, parentID *uuid.UUID) error {
+			for i, seed := range categorySeeds {
+				category, err := upsertSeedCategory(tx, set.Locale, seed, parentID, i)
+				if err != nil {
+					return err
+				}
+				for _, keyword := range seed.Keywords {
+					if err := ensureSeedRule(tx, set.Locale, category.ID, keyword); err != nil {
+						return err
+					}
+				}
+				if err := apply(seed.Children, &category.ID); err != nil {
+					return err
+				}
+			}
+			return nil
+		}
+		if err := apply(set.Categories, nil); err != nil {
+			return err
+		}
+
+		return tx.Save(&SeedVersion{Name: name, Version: set.Version, AppliedAt: time.Now()}).Error
+	})
+}
+
+// upsertSeedCategory creates a seeded system category or updates it to match the seed
+func upsertSeedCategory(tx *gorm.DB, locale string, seed seeds.CategorySeed, parentID *uuid.UUID, sortOrder int) (*transaction.Category, error) {
+	var category transaction.Category
+	err := tx.Where("locale = ? AND seed_key = ? AND user_id IS NULL AND family_id IS NULL", locale, seed.Key).
+		First(&category).Error
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+	exists := err == nil
+
+	now := time.Now()
+	if !exists {
+		category = transaction.Category{
+			ID:        uuid.New(),
+			Locale:    locale,
+			SeedKey:   seed.Key,
+			IsDefault: true,
+			IsActive:  true,
+			CreatedAt: now,
+		}
+	}
+	category.Name = seed.Name
+	category.Icon = seed.Icon
+	category.Color = seed.Color
+	category.ParentID = parentID
+	category.SortOrder = sortOrder
+	category.UpdatedAt = now
+
+	if exists {
+		err = tx.Save(&category).Error
+	} else {
+		err = tx.Create(&category).Error
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &category, nil
+}
+
+// ensureSeedRule creates a starter keyword rule for a seeded category unless it already exists
+func ensureSeedRule(tx *gorm.DB, locale string, categoryID uuid.UUID, keyword string) error {
+	var count int64
+	err := tx.Model(&analytics.CategorizationRule{}).
+		Where("category_id = ? AND pattern = ? AND pattern_type = ? AND user_id IS NULL AND family_id IS NULL", categoryID, keyword, "keyword").
+		Count(&count).Error
+	if err != nil || count > 0 {
+		return err
+	}
+
+	now := time.Now()
+	return tx.Create(&analytics.CategorizationRule{
+		ID:          uuid.New(),
+		CategoryID:  categoryID,
+		Pattern:     keyword,
+		PatternType: "keyword",
+		IsActive:    true,
+		Locale:      locale,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}).Error
+}
@@ -0,0 +1,269 @@
+package seeds
+
+import (
+	"strings"
+)
+
+// DefaultLocale is the seed set used when a user's locale has no seed set of its own
+const DefaultLocale = "en-US"
+
+// CategorySeed describes a default category and the starter rules that map transactions to it
+type CategorySeed struct {
+	Key      string // Stable identifier within a set, e.g. "food.groceries"
+	Name     string
+	Icon     string
+	Color    string
+	Keywords []string // Merchant or description keywords for starter categorization rules
+	Children []CategorySeed
+}
+
+// CategorySet is a versioned set of default categories for a locale. Bump Version
+// whenever the set changes so it is re-applied at startup
+type CategorySet struct {
+	Locale     string
+	Version    int
+	Categories []CategorySeed
+}
+
+// categoryLayout is a node of the hierarchy shared by every locale
+type categoryLayout struct {
+	key      string
+	icon     string
+	color    string
+	children []categoryLayout
+}
+
+// layout defines the keys, icons, colors and hierarchy of the default categories.
+// Subcategories use the color of their parent
+var layout = []categoryLayout{
+	{key: "income", icon: "wallet", color: "#2E7D32", children: []categoryLayout{
+		{key: "income.salary", icon: "briefcase"},
+		{key: "income.refunds", icon: "rotate-ccw"},
+	}},
+	{key: "housing", icon: "home", color: "#5D4037", children: []categoryLayout{
+		{key: "housing.rent", icon: "key"},
+		{key: "housing.utilities", icon: "zap"},
+		{key: "housing.internet", icon: "wifi"},
+	}},
+	{key: "food", icon: "utensils", color: "#4CAF50", children: []categoryLayout{
+		{key: "food.groceries", icon: "shopping-cart"},
+		{key: "food.restaurants", icon: "coffee"},
+	}},
+	{key: "transport", icon: "car", color: "#1976D2", children: []categoryLayout{
+		{key: "transport.fuel", icon: "droplet"},
+		{key: "transport.public", icon: "train"},
+		{key: "transport.taxi", icon: "navigation"},
+	}},
+	{key: "shopping", icon: "shopping-bag", color: "#8E24AA", children: []categoryLayout{
+		{key: "shopping.online", icon: "package"},
+		{key: "shopping.clothing", icon: "tag"},
+	}},
+	{key: "entertainment", icon: "film", color: "#F4511E", children: []categoryLayout{
+		{key: "entertainment.subscriptions", icon: "repeat"},
+	}},
+	{key: "health", icon: "heart", color: "#E53935", children: []categoryLayout{
+		{key: "health.pharmacy", icon: "plus-square"},
+	}},
+	{key: "other", icon: "more-horizontal", color: "#757575"},
+}
+
+// translation holds the localized names and starter rule keywords for a locale
+type translation struct {
+	locale   string
+	version  int
+	names    map[string]string
+	keywords map[string][]string
+}
+
+var translations = []translation{
+	{
+		locale:  "en-US",
+		version: 1,
+		names: map[string]string{
+			"income": "Income", "income.salary": "Salary", "income.refunds": "Refunds",
+			"housing": "Housing", "housing.rent": "Rent", "housing.utilities": "Utilities", "housing.internet": "Internet & Phone",
+			"food": "Food & Dining", "food.groceries": "Groceries", "food.restaurants": "Restaurants",
+			"transport": "Transportation", "transport.fuel": "Gas", "transport.public": "Public Transit", "transport.taxi": "Rideshare & Taxi",
+			"shopping": "Shopping", "shopping.online": "Online Shopping", "shopping.clothing": "Clothing",
+			"entertainment": "Entertainment", "entertainment.subscriptions": "Subscriptions",
+			"health": "Health", "health.pharmacy": "Pharmacy",
+			"other": "Other",
+		},
+		keywords: map[string][]string{
+			"income.salary":               {"payroll", "salary"},
+			"housing.utilities":           {"electric", "water bill"},
+			"housing.internet":            {"comcast", "verizon", "at&t"},
+			"food.groceries":              {"walmart", "kroger", "whole foods", "trader joe"},
+			"food.restaurants":            {"starbucks", "mcdonalds", "chipotle"},
+			"transport.fuel":              {"shell", "chevron", "exxon"},
+			"transport.taxi":              {"uber", "lyft"},
+			"shopping.online":             {"amazon", "ebay"},
+			"entertainment.subscriptions": {"netflix", "spotify", "hulu"},
+			"health.pharmacy":             {"cvs", "walgreens"},
+		},
+	},
+	{
+		locale:  "en-GB",
+		version: 1,
+		names: map[string]string{
+			"income": "Income", "income.salary": "Salary", "income.refunds": "Refunds",
+			"housing": "Housing", "housing.rent": "Rent", "housing.utilities": "Bills", "housing.internet": "Broadband & Phone",
+			"food": "Food & Drink", "food.groceries": "Groceries", "food.restaurants": "Eating Out",
+			"transport": "Transport", "transport.fuel": "Petrol", "transport.public": "Public Transport", "transport.taxi": "Taxis",
+			"shopping": "Shopping", "shopping.online": "Online Shopping", "shopping.clothing": "Clothing",
+			"entertainment": "Entertainment", "entertainment.subscriptions": "Subscriptions",
+			"health": "Health", "health.pharmacy": "Chemist",
+			"other": "Other",
+		},
+		keywords: map[string][]string{
+			"income.salary":               {"salary", "payroll"},
+			"housing.utilities":           {"british gas", "thames water", "council tax"},
+			"housing.internet":            {"bt group", "virgin media", "sky digital"},
+			"food.groceries":              {"tesco", "sainsbury", "asda", "waitrose"},
+			"food.restaurants":            {"greggs", "nandos", "costa coffee"},
+			"transport.fuel":              {"shell", "esso", "texaco"},
+			"transport.public":            {"tfl", "trainline"},
+			"transport.taxi":              {"uber", "bolt"},
+			"shopping.online":             {"amazon", "ebay"},
+			"entertainment.subscriptions": {"netflix", "spotify"},
+			"health.pharmacy":             {"boots", "superdrug"},
+		},
+	},
+	{
+		locale:  "de-DE",
+		version: 1,
+		names: map[string]string{
+			"income": "Einnahmen", "income.salary": "Gehalt", "income.refunds": "Erstattungen",
+			"housing": "Wohnen", "housing.rent": "Miete", "housing.utilities": "Nebenkosten", "housing.internet": "Internet & Telefon",
+			"food": "Essen & Trinken", "food.groceries": "Lebensmittel", "food.restaurants": "Restaurants",
+			"transport": "Mobilität", "transport.fuel": "Tanken", "transport.public": "ÖPNV", "transport.taxi": "Taxi",
+			"shopping": "Einkaufen", "shopping.online": "Onlineshopping", "shopping.clothing": "Kleidung",
+			"entertainment": "Freizeit", "entertainment.subscriptions": "Abonnements",
+			"health": "Gesundheit", "health.pharmacy": "Apotheke",
+			"other": "Sonstiges",
+		},
+		keywords: map[string][]string{
+			"income.salary":               {"gehalt", "lohn"},
+			"housing.rent":                {"miete"},
+			"housing.utilities":           {"stadtwerke", "vattenfall"},
+			"housing.internet":            {"telekom", "vodafone"},
+			"food.groceries":              {"rewe", "edeka", "aldi", "lidl"},
+			"transport.fuel":              {"aral", "shell"},
+			"transport.public":            {"deutsche bahn", "bvg"},
+			"shopping.online":             {"amazon", "zalando"},
+			"entertainment.subscriptions": {"netflix", "spotify"},
+			"health.pharmacy":             {"apotheke"},
+		},
+	},
+	{
+		locale:  "fr-FR",
+		version: 1,
+		names: map[string]string{
+			"income": "Revenus", "income.salary": "Salaire", "income.refunds": "Remboursements",
+			"housing": "Logement", "housing.rent": "Loyer", "housing.utilities": "Charges", "housing.internet": "Internet & Téléphone",
+			"food": "Alimentation", "food.groceries": "Courses", "food.restaurants": "Restaurants",
+			"transport": "Transports", "transport.fuel": "Carburant", "transport.public": "Transports en commun", "transport.taxi": "Taxi & VTC",
+			"shopping": "Achats", "shopping.online": "Achats en ligne", "shopping.clothing": "Vêtements",
+			"entertainment": "Loisirs", "entertainment.subscriptions": "Abonnements",
+			"health": "Santé", "health.pharmacy": "Pharmacie",
+			"other": "Divers",
+		},
+		keywords: map[string][]string{
+			"income.salary":               {"salaire"},
+			"housing.rent":                {"loyer"},
+			"housing.utilities":           {"edf", "engie"},
+			"housing.internet":            {"free mobile", "bouygues", "sfr"},
+			"food.groceries":              {"carrefour", "leclerc", "auchan", "monoprix"},
+			"transport.fuel":              {"totalenergies"},
+			"transport.public":            {"sncf", "ratp"},
+			"transport.taxi":              {"uber", "g7"},
+			"shopping.online":             {"amazon", "cdiscount"},
+			"entertainment.subscriptions": {"netflix", "deezer", "spotify"},
+			"health.pharmacy":             {"pharmacie"},
+		},
+	},
+	{
+		locale:  "es-ES",
+		version: 1,
+		names: map[string]string{
+			"income": "Ingresos", "income.salary": "Nómina", "income.refunds": "Devoluciones",
+			"housing": "Vivienda", "housing.rent": "Alquiler", "housing.utilities": "Suministros", "housing.internet": "Internet y teléfono",
+			"food": "Alimentación", "food.groceries": "Supermercado", "food.restaurants": "Restaurantes",
+			"transport": "Transporte", "transport.fuel": "Combustible", "transport.public": "Transporte público", "transport.taxi": "Taxi y VTC",
+			"shopping": "Compras", "shopping.online": "Compras online", "shopping.clothing": "Ropa",
+			"entertainment": "Ocio", "entertainment.subscriptions": "Suscripciones",
+			"health": "Salud", "health.pharmacy": "Farmacia",
+			"other": "Otros",
+		},
+		keywords: map[string][]string{
+			"income.salary":               {"nomina"},
+			"housing.rent":                {"alquiler"},
+			"housing.utilities":           {"iberdrola", "endesa", "naturgy"},
+			"housing.internet":            {"movistar", "vodafone"},
+			"food.groceries":              {"mercadona", "carrefour", "lidl", "eroski"},
+			"transport.fuel":              {"repsol", "cepsa"},
+			"transport.public":            {"renfe", "metro de madrid"},
+			"transport.taxi":              {"cabify", "uber"},
+			"shopping.online":             {"amazon", "aliexpress"},
+			"entertainment.subscriptions": {"netflix", "spotify"},
+			"health.pharmacy":             {"farmacia"},
+		},
+	},
+}
+
+// CategorySets returns the default category seed sets for every supported locale
+func CategorySets() []CategorySet {
+	sets := make([]CategorySet, len(translations))
+	for i, t := range translations {
+		sets[i] = CategorySet{
+			Locale:     t.locale,
+			Version:    t.version,
+			Categories: buildSeeds(layout, t, ""),
+		}
+	}
+	return sets
+}
+
+// buildSeeds combines the shared layout with a locale's translation
+func buildSeeds(nodes []categoryLayout, t translation, parentColor string) []CategorySeed {
+	seeds := make([]CategorySeed, len(nodes))
+	for i, node := range nodes {
+		color := node.color
+		if color == "" {
+			color = parentColor
+		}
+		seeds[i] = CategorySeed{
+			Key:      node.key,
+			Name:     t.names[node.key],
+			Icon:     node.icon,
+			Color:    color,
+			Keywords: t.keywords[node.key],
+			Children: buildSeeds(node.children, t, color),
+		}
+	}
+	return seeds
+}
+
+// ResolveLocale returns the seed set locale to use for a user locale. An exact match
+// wins, then the first set with the same language, then DefaultLocale
+func ResolveLocale(locale string) string {
+	locale = strings.ReplaceAll(strings.TrimSpace(locale), "_", "-")
+	if locale == "" {
+		return DefaultLocale
+	}
+
+	for _, t := range translations {
+		if strings.EqualFold(t.locale, locale) {
+			return t.locale
+		}
+	}
+
+	language := strings.ToLower(strings.SplitN(locale, "-", 2)[0])
+	for _, t := range translations {
+		if strings.HasPrefix(strings.ToLower(t.locale), language+"-") {
+			return t.locale
+		}
+	}
+
+	return DefaultLocale
+}
@@ -0,0 +1,76 @@
+package seeds
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCategorySets(t *testing.T) {
+	sets := CategorySets()
+	assert.NotEmpty(t, sets)
+
+	for _, set := range sets {
+		t.Run(set.Locale, func(t *testing.T) {
+			assert.Positive(t, set.Version)
+			keys := make(map[string]bool)
+			var walk func(seeds []CategorySeed, depth int)
+			walk = func(seeds []CategorySeed, depth int) {
+				for _, seed := range seeds {
+					assert.False(t, keys[seed.Key], "duplicate key %s", seed.Key)
+					keys[seed.Key] = true
+					assert.NotEmpty(t, seed.Name, "missing name for %s", seed.Key)
+					assert.NotEmpty(t, seed.Color, "missing color for %s", seed.Key)
+					for _, keyword := range seed.Keywords {
+						assert.Equal(t, strings.ToLower(keyword), keyword)
+					}
+					walk(seed.Children, depth+1)
+				}
+				assert.Less(t, depth, 4)
+			}
+			walk(set.Categories, 0)
+		})
+	}
+
+	// Every translation must only reference keys that exist in the layout
+	for _, tr := range translations {
+		for key := range tr.names {
+			assert.True(t, layoutHasKey(layout, key), "%s: unknown key %s", tr.locale, key)
+		}
+		for key := range tr.keywords {
+			assert.True(t, layoutHasKey(layout, key), "%s: unknown key %s", tr.locale, key)
+		}
+	}
+}
+
+func layoutHasKey(nodes []categoryLayout, key string) bool {
+	for _, node := range nodes {
+		if node.key == key || layoutHasKey(node.children, key) {
+			return true
+		}
+	}
+	return false
+}
+
+func TestResolveLocale(t *testing.T) {
+	tests := []struct {
+		locale   string
+		expected string
+	}{
+		{"", DefaultLocale},
+		{"en-US", "en-US"},
+		{"en-gb", "en-GB"},
+		{"de_DE", "de-DE"},
+		{"de-AT", "de-DE"},
+		{"fr", "fr-FR"},
+		{"es-MX", "es-ES"},
+		{"ja-JP", DefaultLocale},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.locale, func(t *testing.T) {
+			assert.Equal(t, tt.expected, ResolveLocale(tt.locale))
+		})
+	}
+}
@@ -0,0 +1,95 @@
+package integration
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"fiscaflow/internal/domain/analytics"
+	analyticsmocks "fiscaflow/internal/domain/analytics/mocks"
+	"fiscaflow/internal/domain/budget"
+	"fiscaflow/internal/domain/transaction"
+)
+
+// idTransactionRepository gives created transactions the ID Postgres would
+type idTransactionRepository struct {
+	transaction.Repository
+}
+
+func (r *idTransactionRepository) CreateTransaction(ctx context.Context, t *transaction.Transaction) error {
+	if t.ID == uuid.Nil {
+		t.ID = uuid.New()
+	}
+	return r.Repository.CreateTransaction(ctx, t)
+}
+
+func TestBudgetIntegration_RuleTransfersAreNotSpendingOrIncome(t *testing.T) {
+	db := NewTestDatabase(t)
+	defer db.Cleanup()
+	ctx := context.Background()
+
+	transactionRepo := &idTransactionRepository{NewTestTransactionRepository(db.DB)}
+	budgetRepo := budget.NewRepository(db.DB)
+
+	userID := uuid.New()
+	account := &transaction.Account{ID: uuid.New(), UserID: userID, Name: "Checking", Type: transaction.AccountTypeChecking, Currency: "USD", IsActive: true}
+	require.NoError(t, transactionRepo.CreateAccount(ctx, account))
+	groceries := &transaction.Category{ID: uuid.New(), Name: "Groceries", IsDefault: true, IsActive: true}
+	savings := &transaction.Category{ID: uuid.New(), Name: "Savings", IsDefault: true, IsActive: true}
+	require.NoError(t, transactionRepo.CreateCategory(ctx, groceries))
+	require.NoError(t, transactionRepo.CreateCategory(ctx, savings))
+
+	// The user's rule marks money moved to and from savings as a transfer
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	analyticsRepo := analyticsmocks.NewMockRepository(ctrl)
+	analyticsRepo.EXPECT().GetActiveCategorizationRules(gomock.Any(), &userID).Return([]analytics.CategorizationRule{{
+		ID: uuid.New(), UserID: &userID, CategoryID: savings.ID, Pattern: "savings", PatternType: "keyword", IsActive: true,
+		Actions: analytics.RuleActions{IsTransfer: true},
+	}}, nil).AnyTimes()
+	analyticsRepo.EXPECT().GetCategoryByID(gomock.Any(), savings.ID).Return(&analytics.Category{ID: savings.ID, Name: "Savings"}, nil).AnyTimes()
+	analyticsRepo.EXPECT().GetActiveCategorizationModel(gomock.Any(), gomock.Any()).Return(nil, nil).AnyTimes()
+	analyticsRepo.EXPECT().GetCategorizationFeedbackByUser(gomock.Any(), userID, gomock.Any()).Return(nil, nil).AnyTimes()
+
+	transactionService := transaction.NewService(transactionRepo, transaction.CategorizationConfig{
+		Categorizer: analytics.NewService(analyticsRepo),
+		Threshold:   0.8,
+	})
+
+	date := time.Date(2024, 3, 10, 12, 0, 0, 0, time.UTC)
+	requests := []transaction.CreateTransactionRequest{
+		{AccountID: account.ID, CategoryID: &groceries.ID, Amount: -80, Description: "Weekly shop", TransactionDate: date},
+		{AccountID: account.ID, Amount: 2500, Description: "Salary", TransactionDate: date},
+		{AccountID: account.ID, Amount: -500, Description: "Move to savings", TransactionDate: date},
+		{AccountID: account.ID, Amount: 200, Description: "Back from savings", TransactionDate: date},
+	}
+	for i := range requests {
+		_, err := transactionService.CreateTransaction(ctx, userID, &requests[i])
+		require.NoError(t, err)
+	}
+
+	var transfers int64
+	require.NoError(t, db.DB.Model(&TestTransaction{}).Where("is_transfer = ?", true).Count(&transfers).Error)
+	assert.Equal(t, int64(2), transfers)
+
+	start := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	end := time.Date(2024, 3, 31, 0, 0, 0, 0, time.UTC)
+	spending, err := budgetRepo.GetSpendingByCategory(ctx, []uuid.UUID{userID}, start, &end)
+	require.NoError(t, err)
+	assert.Equal(t, map[uuid.UUID]float64{groceries.ID: 80}, spending)
+
+	income, err := budgetRepo.GetIncome(ctx, []uuid.UUID{userID}, start, &end)
+	require.NoError(t, err)
+	assert.Equal(t, 2500.0, income)
+
+	history, err := budgetRepo.GetExpenseHistory(ctx, []uuid.UUID{userID}, start, end)
+	require.NoError(t, err)
+	if assert.Len(t, history, 1) {
+		assert.Equal(t, groceries.ID, history[0].CategoryID)
+	}
+}
@@ -23,7 +23,7 @@ func setupTestServerWithAuth(t *testing.T) (*gin.Engine, string) {
 	userRepo := NewTestRepository(db.DB)
 	transactionRepo := NewTestTransactionRepository(db.DB)
 	userService := user.NewService(userRepo, "test-secret")
-	transactionService := transaction.NewService(transactionRepo)
+	transactionService := transaction.NewService(transactionRepo, transaction.CategorizationConfig{})
 
 	userHandler := handlers.NewUserHandler(userService, nil)
 	categoryHandler := handlers.NewCategoryHandler(transactionService)
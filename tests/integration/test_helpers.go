@@ -23,7 +23,8 @@ func NewTestDatabase(t *testing.T) *TestDatabase {
 	// Auto-migrate the schema with all models needed for integration tests
 	err = db.AutoMigrate(
 		&TestUser{}, &TestUserSession{},
-		&TestTransaction{}, &TestCategory{}, &TestAccount{},
+		&TestTransaction{}, &TestCategory{}, &TestAccount{}, &TestMerchant{},
+		&TestHiddenCategory{}, &TestFamily{}, &TestFamilyMember{},
 	)
 	require.NoError(t, err)
 
@@ -33,7 +34,11 @@ func NewTestDatabase(t *testing.T) *TestDatabase {
 // Cleanup cleans up the test database
 func (td *TestDatabase) Cleanup() {
 	td.DB.Exec("DELETE FROM transactions")
+	td.DB.Exec("DELETE FROM merchants")
+	td.DB.Exec("DELETE FROM hidden_categories")
 	td.DB.Exec("DELETE FROM categories")
+	td.DB.Exec("DELETE FROM family_members")
+	td.DB.Exec("DELETE FROM families")
 	td.DB.Exec("DELETE FROM accounts")
 	td.DB.Exec("DELETE FROM user_sessions")
 	td.DB.Exec("DELETE FROM users")
@@ -50,6 +55,7 @@ type TestUser struct {
 	DateOfBirth      *time.Time `json:"date_of_birth"`
 	Timezone         string     `json:"timezone" gorm:"default:'UTC'"`
 	Locale           string     `json:"locale" gorm:"default:'en-US'"`
+	CategoryLocale   string     `json:"category_locale" gorm:"default:'en-US'"`
 	Role             string     `json:"role" gorm:"default:'user'"`
 	Status           string     `json:"status" gorm:"default:'active'"`
 	EmailVerified    bool       `json:"email_verified" gorm:"default:false"`
@@ -92,11 +98,13 @@ type TestTransaction struct {
 	FamilyID   *string `json:"family_id" gorm:"type:text"`
 	AccountID  string  `json:"account_id" gorm:"type:text;not null"`
 	CategoryID *string `json:"category_id" gorm:"type:text"`
+	MerchantID *string `json:"merchant_id" gorm:"type:text"`
 
 	Amount      float64 `json:"amount" gorm:"type:decimal(15,2);not null"`
 	Currency    string  `json:"currency" gorm:"default:'USD'"`
 	Description string  `json:"description" gorm:"not null"`
 	Merchant    string  `json:"merchant"`
+	RawMerchant string  `json:"raw_merchant"`
 	Location    string  `json:"location" gorm:"type:text"`
 
 	TransactionDate time.Time  `json:"transaction_date" gorm:"not null"`
@@ -105,10 +113,12 @@ type TestTransaction struct {
 
 	CategorizationSource     string   `json:"categorization_source" gorm:"default:'manual'"`
 	CategorizationConfidence *float64 `json:"categorization_confidence"`
+	SuggestedCategoryID      *string  `json:"suggested_category_id" gorm:"type:text"`
 
 	Tags       string `json:"tags" gorm:"type:text"` // Store as JSON string for SQLite
 	Notes      string `json:"notes"`
 	ReceiptURL string `json:"receipt_url"`
+	IsTransfer bool   `json:"is_transfer" gorm:"default:false"`
 
 	CreatedAt time.Time `json:"created_at"`
 	UpdatedAt time.Time `json:"updated_at"`
@@ -122,6 +132,8 @@ func (TestTransaction) TableName() string {
 // TestCategory is a SQLite-compatible version of the Category model for integration tests
 type TestCategory struct {
 	ID          string    `json:"id" gorm:"type:text;primary_key"`
+	UserID      *string   `json:"user_id" gorm:"type:text"`
+	FamilyID    *string   `json:"family_id" gorm:"type:text"`
 	Name        string    `json:"name" gorm:"not null"`
 	Description string    `json:"description"`
 	Icon        string    `json:"icon"`
@@ -130,6 +142,8 @@ type TestCategory struct {
 	IsDefault   bool      `json:"is_default" gorm:"default:false"`
 	IsActive    bool      `json:"is_active" gorm:"default:true"`
 	SortOrder   int       `json:"sort_order" gorm:"default:0"`
+	Locale      string    `json:"locale"`
+	SeedKey     string    `json:"seed_key"`
 	CreatedAt   time.Time `json:"created_at"`
 	UpdatedAt   time.Time `json:"updated_at"`
 }
@@ -162,3 +176,66 @@ type TestAccount struct {
 func (TestAccount) TableName() string {
 	return "accounts"
 }
+
+// TestMerchant is a SQLite-compatible version of the Merchant model for integration tests
+// Aliases and Patterns are stored as JSON strings
+type TestMerchant struct {
+	ID                string    `json:"id" gorm:"type:text;primary_key"`
+	UserID            *string   `json:"user_id" gorm:"type:text;uniqueIndex:idx_merchants_user_normalized_name"`
+	Name              string    `json:"name" gorm:"not null"`
+	NormalizedName    string    `json:"normalized_name" gorm:"uniqueIndex:idx_merchants_user_normalized_name;not null"`
+	Aliases           string    `json:"aliases" gorm:"type:text"`  // Store as JSON string for SQLite
+	Patterns          string    `json:"patterns" gorm:"type:text"` // Store as JSON string for SQLite
+	DefaultCategoryID *string   `json:"default_category_id" gorm:"type:text"`
+	LogoURL           string    `json:"logo_url"`
+	Website           string    `json:"website"`
+	Metadata          string    `json:"metadata" gorm:"type:text;default:'{}'"` // Store as JSON string for SQLite
+	CreatedAt         time.Time `json:"created_at"`
+	UpdatedAt         time.Time `json:"updated_at"`
+}
+
+// TableName specifies the table name for TestMerchant
+func (TestMerchant) TableName() string {
+	return "merchants"
+}
+
+// TestHiddenCategory is a SQLite-compatible version of the HiddenCategory model for integration tests
+type TestHiddenCategory struct {
+	UserID     string    `json:"user_id" gorm:"type:text;primaryKey"`
+	CategoryID string    `json:"category_id" gorm:"type:text;primaryKey"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+// TableName specifies the table name for TestHiddenCategory
+func (TestHiddenCategory) TableName() string {
+	return "hidden_categories"
+}
+
+// TestFamily is a SQLite-compatible version of the Family model for integration tests
+type TestFamily struct {
+	ID         string    `json:"id" gorm:"type:text;primary_key"`
+	Name       string    `json:"name" gorm:"not null"`
+	OwnerID    string    `json:"owner_id" gorm:"type:text;not null"`
+	InviteCode string    `json:"invite_code" gorm:"uniqueIndex"`
+	CreatedAt  time.Time `json:"created_at"`
+	UpdatedAt  time.Time `json:"updated_at"`
+}
+
+// TableName specifies the table name for TestFamily
+func (TestFamily) TableName() string {
+	return "families"
+}
+
+// TestFamilyMember is a SQLite-compatible version of the FamilyMember model for integration tests
+type TestFamilyMember struct {
+	ID        string    `json:"id" gorm:"type:text;primary_key"`
+	FamilyID  string    `json:"family_id" gorm:"type:text;not null"`
+	UserID    string    `json:"user_id" gorm:"type:text;not null"`
+	Role      string    `json:"role" gorm:"default:'family_member'"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// TableName specifies the table name for TestFamilyMember
+func (TestFamilyMember) TableName() string {
+	return "family_members"
+}
@@ -40,6 +40,7 @@ func (r *TestTransactionRepository) CreateTransaction(ctx context.Context, t *tr
 		Currency:                 t.Currency,
 		Description:              t.Description,
 		Merchant:                 t.Merchant,
+		RawMerchant:              t.RawMerchant,
 		Location:                 t.Location,
 		TransactionDate:          t.TransactionDate,
 		PostedDate:               t.PostedDate,
@@ -48,6 +49,7 @@ func (r *TestTransactionRepository) CreateTransaction(ctx context.Context, t *tr
 		CategorizationConfidence: t.CategorizationConfidence,
 		Tags:                     r.tagsToString(t.Tags),
 		Notes:                    t.Notes,
+		IsTransfer:               t.IsTransfer,
 		ReceiptURL:               t.ReceiptURL,
 		CreatedAt:                t.CreatedAt,
 		UpdatedAt:                t.UpdatedAt,
@@ -63,6 +65,16 @@ func (r *TestTransactionRepository) CreateTransaction(ctx context.Context, t *tr
 		testTransaction.CategoryID = &categoryID
 	}
 
+	if t.MerchantID != nil {
+		merchantID := t.MerchantID.String()
+		testTransaction.MerchantID = &merchantID
+	}
+
+	if t.SuggestedCategoryID != nil {
+		suggestedCategoryID := t.SuggestedCategoryID.String()
+		testTransaction.SuggestedCategoryID = &suggestedCategoryID
+	}
+
 	return r.db.WithContext(ctx).Create(testTransaction).Error
 }
 
@@ -119,6 +131,26 @@ func (r *TestTransactionRepository) GetTransactionsByAccount(ctx context.Context
 	return transactions, nil
 }
 
+func (r *TestTransactionRepository) GetTransactionsForReview(ctx context.Context, userID uuid.UUID, offset, limit int) ([]transaction.Transaction, error) {
+	var testTransactions []TestTransaction
+	err := r.db.WithContext(ctx).
+		Where("user_id = ? AND category_id IS NULL AND suggested_category_id IS NOT NULL", userID.String()).
+		Order("transaction_date DESC, created_at DESC").
+		Offset(offset).
+		Limit(limit).
+		Find(&testTransactions).Error
+	if err != nil {
+		return nil, err
+	}
+
+	transactions := make([]transaction.Transaction, len(testTransactions))
+	for i, tt := range testTransactions {
+		transactions[i] = *r.testTransactionToTransaction(&tt)
+	}
+
+	return transactions, nil
+}
+
 func (r *TestTransactionRepository) UpdateTransaction(ctx context.Context, t *transaction.Transaction) error {
 	testTransaction := &TestTransaction{
 		ID:                       t.ID.String(),
@@ -128,6 +160,7 @@ func (r *TestTransactionRepository) UpdateTransaction(ctx context.Context, t *tr
 		Currency:                 t.Currency,
 		Description:              t.Description,
 		Merchant:                 t.Merchant,
+		RawMerchant:              t.RawMerchant,
 		Location:                 t.Location,
 		TransactionDate:          t.TransactionDate,
 		PostedDate:               t.PostedDate,
@@ -136,6 +169,7 @@ func (r *TestTransactionRepository) UpdateTransaction(ctx context.Context, t *tr
 		CategorizationConfidence: t.CategorizationConfidence,
 		Tags:                     r.tagsToString(t.Tags),
 		Notes:                    t.Notes,
+		IsTransfer:               t.IsTransfer,
 		ReceiptURL:               t.ReceiptURL,
 		CreatedAt:                t.CreatedAt,
 		UpdatedAt:                t.UpdatedAt,
@@ -151,6 +185,16 @@ func (r *TestTransactionRepository) UpdateTransaction(ctx context.Context, t *tr
 		testTransaction.CategoryID = &categoryID
 	}
 
+	if t.MerchantID != nil {
+		merchantID := t.MerchantID.String()
+		testTransaction.MerchantID = &merchantID
+	}
+
+	if t.SuggestedCategoryID != nil {
+		suggestedCategoryID := t.SuggestedCategoryID.String()
+		testTransaction.SuggestedCategoryID = &suggestedCategoryID
+	}
+
 	return r.db.WithContext(ctx).Save(testTransaction).Error
 }
 
@@ -168,6 +212,8 @@ func (r *TestTransactionRepository) CreateCategory(ctx context.Context, c *trans
 		IsDefault:   c.IsDefault,
 		IsActive:    c.IsActive,
 		SortOrder:   c.SortOrder,
+		Locale:      c.Locale,
+		SeedKey:     c.SeedKey,
 		CreatedAt:   c.CreatedAt,
 		UpdatedAt:   c.UpdatedAt,
 	}
@@ -177,6 +223,16 @@ func (r *TestTransactionRepository) CreateCategory(ctx context.Context, c *trans
 		testCategory.ParentID = &parentID
 	}
 
+	if c.UserID != nil {
+		userID := c.UserID.String()
+		testCategory.UserID = &userID
+	}
+
+	if c.FamilyID != nil {
+		familyID := c.FamilyID.String()
+		testCategory.FamilyID = &familyID
+	}
+
 	return r.db.WithContext(ctx).Create(testCategory).Error
 }
 
@@ -193,9 +249,20 @@ func (r *TestTransactionRepository) GetCategoryByID(ctx context.Context, id uuid
 	return r.testCategoryToCategory(&testCategory), nil
 }
 
-func (r *TestTransactionRepository) GetCategories(ctx context.Context, offset, limit int) ([]transaction.Category, error) {
+func (r *TestTransactionRepository) GetCategories(ctx context.Context, userID uuid.UUID, familyIDs []uuid.UUID, locale string, offset, limit int) ([]transaction.Category, error) {
+	owned := r.db.Where("user_id IS NULL AND family_id IS NULL AND COALESCE(locale, '') IN ('', ?)", locale).Or("user_id = ?", userID.String())
+	if len(familyIDs) > 0 {
+		ids := make([]string, len(familyIDs))
+		for i, id := range familyIDs {
+			ids[i] = id.String()
+		}
+		owned = owned.Or("family_id IN ?", ids)
+	}
+
 	var testCategories []TestCategory
 	err := r.db.WithContext(ctx).
+		Where(owned).
+		Where("NOT EXISTS (SELECT 1 FROM hidden_categories WHERE hidden_categories.category_id = categories.id AND hidden_categories.user_id = ?)", userID.String()).
 		Order("sort_order ASC, name ASC").
 		Offset(offset).
 		Limit(limit).
@@ -212,10 +279,29 @@ func (r *TestTransactionRepository) GetCategories(ctx context.Context, offset, l
 	return categories, nil
 }
 
-func (r *TestTransactionRepository) GetDefaultCategories(ctx context.Context) ([]transaction.Category, error) {
+func (r *TestTransactionRepository) GetDefaultCategories(ctx context.Context, locale string) ([]transaction.Category, error) {
 	var testCategories []TestCategory
 	err := r.db.WithContext(ctx).
-		Where("is_default = ?", true).
+		Where("is_default = ? AND user_id IS NULL AND family_id IS NULL", true).
+		Where("COALESCE(locale, '') IN ('', ?)", locale).
+		Order("sort_order ASC, name ASC").
+		Find(&testCategories).Error
+	if err != nil {
+		return nil, err
+	}
+
+	categories := make([]transaction.Category, len(testCategories))
+	for i, tc := range testCategories {
+		categories[i] = *r.testCategoryToCategory(&tc)
+	}
+
+	return categories, nil
+}
+
+func (r *TestTransactionRepository) GetChildCategories(ctx context.Context, parentID uuid.UUID) ([]transaction.Category, error) {
+	var testCategories []TestCategory
+	err := r.db.WithContext(ctx).
+		Where("parent_id = ?", parentID.String()).
 		Order("sort_order ASC, name ASC").
 		Find(&testCategories).Error
 	if err != nil {
@@ -240,6 +326,8 @@ func (r *TestTransactionRepository) UpdateCategory(ctx context.Context, c *trans
 		IsDefault:   c.IsDefault,
 		IsActive:    c.IsActive,
 		SortOrder:   c.SortOrder,
+		Locale:      c.Locale,
+		SeedKey:     c.SeedKey,
 		CreatedAt:   c.CreatedAt,
 		UpdatedAt:   c.UpdatedAt,
 	}
@@ -249,6 +337,16 @@ func (r *TestTransactionRepository) UpdateCategory(ctx context.Context, c *trans
 		testCategory.ParentID = &parentID
 	}
 
+	if c.UserID != nil {
+		userID := c.UserID.String()
+		testCategory.UserID = &userID
+	}
+
+	if c.FamilyID != nil {
+		familyID := c.FamilyID.String()
+		testCategory.FamilyID = &familyID
+	}
+
 	return r.db.WithContext(ctx).Save(testCategory).Error
 }
 
@@ -256,6 +354,165 @@ func (r *TestTransactionRepository) DeleteCategory(ctx context.Context, id uuid.
 	return r.db.WithContext(ctx).Delete(&TestCategory{}, "id = ?", id.String()).Error
 }
 
+func (r *TestTransactionRepository) HasCategoryReferences(ctx context.Context, id uuid.UUID) (bool, error) {
+	references := []struct {
+		model  interface{}
+		column string
+	}{
+		{&TestTransaction{}, "category_id"},
+		{&TestMerchant{}, "default_category_id"},
+		{&TestCategory{}, "parent_id"},
+	}
+	for _, ref := range references {
+		var count int64
+		if err := r.db.WithContext(ctx).Model(ref.model).Where(ref.column+" = ?", id.String()).Count(&count).Error; err != nil {
+			return false, err
+		}
+		if count > 0 {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (r *TestTransactionRepository) MergeCategory(ctx context.Context, sourceID, targetID uuid.UUID) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&TestTransaction{}).Where("category_id = ?", sourceID.String()).Update("category_id", targetID.String()).Error; err != nil {
+			return err
+		}
+		if err := tx.Model(&TestMerchant{}).Where("default_category_id = ?", sourceID.String()).Update("default_category_id", targetID.String()).Error; err != nil {
+			return err
+		}
+		if err := tx.Model(&TestCategory{}).Where("parent_id = ?", sourceID.String()).Update("parent_id", targetID.String()).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("category_id = ?", sourceID.String()).Delete(&TestHiddenCategory{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&TestCategory{}, "id = ?", sourceID.String()).Error
+	})
+}
+
+func (r *TestTransactionRepository) HideCategory(ctx context.Context, hidden *transaction.HiddenCategory) error {
+	return r.db.WithContext(ctx).Save(&TestHiddenCategory{
+		UserID:     hidden.UserID.String(),
+		CategoryID: hidden.CategoryID.String(),
+		CreatedAt:  hidden.CreatedAt,
+	}).Error
+}
+
+func (r *TestTransactionRepository) UnhideCategory(ctx context.Context, userID, categoryID uuid.UUID) error {
+	return r.db.WithContext(ctx).
+		Where("user_id = ? AND category_id = ?", userID.String(), categoryID.String()).
+		Delete(&TestHiddenCategory{}).Error
+}
+
+func (r *TestTransactionRepository) GetFamilyIDsByUser(ctx context.Context, userID uuid.UUID) ([]uuid.UUID, error) {
+	var ids []string
+	err := r.db.WithContext(ctx).
+		Model(&TestFamilyMember{}).
+		Where("user_id = ?", userID.String()).
+		Pluck("family_id", &ids).Error
+	if err != nil {
+		return nil, err
+	}
+
+	familyIDs := make([]uuid.UUID, len(ids))
+	for i, id := range ids {
+		familyIDs[i], _ = uuid.Parse(id)
+	}
+
+	return familyIDs, nil
+}
+
+func (r *TestTransactionRepository) GetUserCategoryLocale(ctx context.Context, userID uuid.UUID) (string, error) {
+	var locales []string
+	err := r.db.WithContext(ctx).
+		Model(&TestUser{}).
+		Where("id = ?", userID.String()).
+		Pluck("category_locale", &locales).Error
+	if err != nil || len(locales) == 0 {
+		return "", err
+	}
+	return locales[0], nil
+}
+
+func (r *TestTransactionRepository) CreateMerchant(ctx context.Context, m *transaction.Merchant) error {
+	if m.ID == uuid.Nil {
+		m.ID = uuid.New()
+	}
+	return r.db.WithContext(ctx).Create(r.merchantToTestMerchant(m)).Error
+}
+
+func (r *TestTransactionRepository) GetMerchantByID(ctx context.Context, id uuid.UUID) (*transaction.Merchant, error) {
+	var testMerchant TestMerchant
+	err := r.db.WithContext(ctx).Where("id = ?", id.String()).First(&testMerchant).Error
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, transaction.ErrMerchantNotFound
+		}
+		return nil, err
+	}
+
+	return r.testMerchantToMerchant(&testMerchant), nil
+}
+
+func (r *TestTransactionRepository) GetMerchantByNormalizedName(ctx context.Context, userID uuid.UUID, normalizedName string) (*transaction.Merchant, error) {
+	var testMerchant TestMerchant
+	err := r.db.WithContext(ctx).Where("user_id = ? AND normalized_name = ?", userID.String(), normalizedName).First(&testMerchant).Error
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, transaction.ErrMerchantNotFound
+		}
+		return nil, err
+	}
+
+	return r.testMerchantToMerchant(&testMerchant), nil
+}
+
+func (r *TestTransactionRepository) GetMerchants(ctx context.Context, userID uuid.UUID, offset, limit int) ([]transaction.Merchant, error) {
+	return r.findMerchants(r.db.WithContext(ctx).
+		Where("user_id IS NULL OR user_id = ?", userID.String()).
+		Order("name ASC").
+		Offset(offset).
+		Limit(limit))
+}
+
+func (r *TestTransactionRepository) GetSharedMerchants(ctx context.Context) ([]transaction.Merchant, error) {
+	return r.findMerchants(r.db.WithContext(ctx).Where("user_id IS NULL").Order("name ASC"))
+}
+
+func (r *TestTransactionRepository) GetMerchantsByUser(ctx context.Context, userID uuid.UUID) ([]transaction.Merchant, error) {
+	return r.findMerchants(r.db.WithContext(ctx).Where("user_id = ?", userID.String()).Order("name ASC"))
+}
+
+func (r *TestTransactionRepository) findMerchants(query *gorm.DB) ([]transaction.Merchant, error) {
+	var testMerchants []TestMerchant
+	if err := query.Find(&testMerchants).Error; err != nil {
+		return nil, err
+	}
+
+	merchants := make([]transaction.Merchant, len(testMerchants))
+	for i, tm := range testMerchants {
+		merchants[i] = *r.testMerchantToMerchant(&tm)
+	}
+
+	return merchants, nil
+}
+
+func (r *TestTransactionRepository) UpdateMerchant(ctx context.Context, m *transaction.Merchant) error {
+	return r.db.WithContext(ctx).Save(r.merchantToTestMerchant(m)).Error
+}
+
+func (r *TestTransactionRepository) DeleteMerchant(ctx context.Context, id uuid.UUID) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&TestTransaction{}).Where("merchant_id = ?", id.String()).Update("merchant_id", nil).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&TestMerchant{}, "id = ?", id.String()).Error
+	})
+}
+
 func (r *TestTransactionRepository) CreateAccount(ctx context.Context, a *transaction.Account) error {
 	testAccount := &TestAccount{
 		ID:                a.ID.String(),
@@ -358,6 +615,7 @@ func (r *TestTransactionRepository) testTransactionToTransaction(tt *TestTransac
 		Currency:                 tt.Currency,
 		Description:              tt.Description,
 		Merchant:                 tt.Merchant,
+		RawMerchant:              tt.RawMerchant,
 		Location:                 tt.Location,
 		TransactionDate:          tt.TransactionDate,
 		PostedDate:               tt.PostedDate,
@@ -366,6 +624,7 @@ func (r *TestTransactionRepository) testTransactionToTransaction(tt *TestTransac
 		CategorizationConfidence: tt.CategorizationConfidence,
 		Tags:                     r.stringToTags(tt.Tags),
 		Notes:                    tt.Notes,
+		IsTransfer:               tt.IsTransfer,
 		ReceiptURL:               tt.ReceiptURL,
 		CreatedAt:                tt.CreatedAt,
 		UpdatedAt:                tt.UpdatedAt,
@@ -381,6 +640,16 @@ func (r *TestTransactionRepository) testTransactionToTransaction(tt *TestTransac
 		t.CategoryID = &categoryID
 	}
 
+	if tt.MerchantID != nil {
+		merchantID, _ := uuid.Parse(*tt.MerchantID)
+		t.MerchantID = &merchantID
+	}
+
+	if tt.SuggestedCategoryID != nil {
+		suggestedCategoryID, _ := uuid.Parse(*tt.SuggestedCategoryID)
+		t.SuggestedCategoryID = &suggestedCategoryID
+	}
+
 	return t
 }
 
@@ -396,6 +665,8 @@ func (r *TestTransactionRepository) testCategoryToCategory(tc *TestCategory) *tr
 		IsDefault:   tc.IsDefault,
 		IsActive:    tc.IsActive,
 		SortOrder:   tc.SortOrder,
+		Locale:      tc.Locale,
+		SeedKey:     tc.SeedKey,
 		CreatedAt:   tc.CreatedAt,
 		UpdatedAt:   tc.UpdatedAt,
 	}
@@ -405,6 +676,16 @@ func (r *TestTransactionRepository) testCategoryToCategory(tc *TestCategory) *tr
 		c.ParentID = &parentID
 	}
 
+	if tc.UserID != nil {
+		userID, _ := uuid.Parse(*tc.UserID)
+		c.UserID = &userID
+	}
+
+	if tc.FamilyID != nil {
+		familyID, _ := uuid.Parse(*tc.FamilyID)
+		c.FamilyID = &familyID
+	}
+
 	return c
 }
 
@@ -437,6 +718,60 @@ func (r *TestTransactionRepository) testAccountToAccount(ta *TestAccount) *trans
 	return a
 }
 
+func (r *TestTransactionRepository) merchantToTestMerchant(m *transaction.Merchant) *TestMerchant {
+	testMerchant := &TestMerchant{
+		ID:             m.ID.String(),
+		Name:           m.Name,
+		NormalizedName: m.NormalizedName,
+		Aliases:        r.tagsToString(m.Aliases),
+		Patterns:       r.tagsToString(m.Patterns),
+		LogoURL:        m.LogoURL,
+		Website:        m.Website,
+		Metadata:       m.Metadata,
+		CreatedAt:      m.CreatedAt,
+		UpdatedAt:      m.UpdatedAt,
+	}
+
+	if m.UserID != nil {
+		userID := m.UserID.String()
+		testMerchant.UserID = &userID
+	}
+	if m.DefaultCategoryID != nil {
+		categoryID := m.DefaultCategoryID.String()
+		testMerchant.DefaultCategoryID = &categoryID
+	}
+
+	return testMerchant
+}
+
+func (r *TestTransactionRepository) testMerchantToMerchant(tm *TestMerchant) *transaction.Merchant {
+	merchantID, _ := uuid.Parse(tm.ID)
+
+	m := &transaction.Merchant{
+		ID:             merchantID,
+		Name:           tm.Name,
+		NormalizedName: tm.NormalizedName,
+		Aliases:        r.stringToTags(tm.Aliases),
+		Patterns:       r.stringToTags(tm.Patterns),
+		LogoURL:        tm.LogoURL,
+		Website:        tm.Website,
+		Metadata:       tm.Metadata,
+		CreatedAt:      tm.CreatedAt,
+		UpdatedAt:      tm.UpdatedAt,
+	}
+
+	if tm.UserID != nil {
+		userID, _ := uuid.Parse(*tm.UserID)
+		m.UserID = &userID
+	}
+	if tm.DefaultCategoryID != nil {
+		categoryID, _ := uuid.Parse(*tm.DefaultCategoryID)
+		m.DefaultCategoryID = &categoryID
+	}
+
+	return m
+}
+
 func (r *TestTransactionRepository) tagsToString(tags []string) string {
 	if len(tags) == 0 {
 		return "[]"
@@ -682,17 +1017,61 @@ func TestTransactionIntegration_CategoryOperations(t *testing.T) {
 	assert.Equal(t, "#FF5722", retrievedCategory.Color)
 
 	// Test retrieving all categories
-	categories, err := transactionRepo.GetCategories(context.Background(), 0, 10)
+	userID := uuid.New()
+	categories, err := transactionRepo.GetCategories(context.Background(), userID, nil, "en-US", 0, 10)
 	require.NoError(t, err)
 	assert.Len(t, categories, 1)
 	assert.Equal(t, testCategory.ID, categories[0].ID)
 
+	// Test that another user's categories are left out and hidden categories are filtered
+	otherUserID := uuid.New()
+	otherCategory := &transaction.Category{ID: uuid.New(), Name: "Private", UserID: &otherUserID, IsActive: true}
+	require.NoError(t, transactionRepo.CreateCategory(context.Background(), otherCategory))
+
+	familyID := uuid.New()
+	familyCategory := &transaction.Category{ID: uuid.New(), Name: "Kids", FamilyID: &familyID, IsActive: true}
+	require.NoError(t, transactionRepo.CreateCategory(context.Background(), familyCategory))
+
+	categories, err = transactionRepo.GetCategories(context.Background(), userID, []uuid.UUID{familyID}, "en-US", 0, 10)
+	require.NoError(t, err)
+	assert.Len(t, categories, 2)
+
+	err = transactionRepo.HideCategory(context.Background(), &transaction.HiddenCategory{UserID: userID, CategoryID: testCategory.ID})
+	require.NoError(t, err)
+	categories, err = transactionRepo.GetCategories(context.Background(), userID, []uuid.UUID{familyID}, "en-US", 0, 10)
+	require.NoError(t, err)
+	assert.Len(t, categories, 1)
+	assert.Equal(t, familyCategory.ID, categories[0].ID)
+
+	err = transactionRepo.UnhideCategory(context.Background(), userID, testCategory.ID)
+	require.NoError(t, err)
+
 	// Test retrieving default categories
-	defaultCategories, err := transactionRepo.GetDefaultCategories(context.Background())
+	defaultCategories, err := transactionRepo.GetDefaultCategories(context.Background(), "en-US")
 	require.NoError(t, err)
 	assert.Len(t, defaultCategories, 1)
 	assert.Equal(t, testCategory.ID, defaultCategories[0].ID)
 
+	// Test merging a category moves its subcategories to the target
+	childCategory := &transaction.Category{ID: uuid.New(), Name: "School", FamilyID: &familyID, ParentID: &familyCategory.ID, IsActive: true}
+	require.NoError(t, transactionRepo.CreateCategory(context.Background(), childCategory))
+
+	inUse, err := transactionRepo.HasCategoryReferences(context.Background(), familyCategory.ID)
+	require.NoError(t, err)
+	assert.True(t, inUse)
+
+	err = transactionRepo.MergeCategory(context.Background(), familyCategory.ID, testCategory.ID)
+	require.NoError(t, err)
+	_, err = transactionRepo.GetCategoryByID(context.Background(), familyCategory.ID)
+	assert.Equal(t, transaction.ErrCategoryNotFound, err)
+	retrievedCategory, err = transactionRepo.GetCategoryByID(context.Background(), childCategory.ID)
+	require.NoError(t, err)
+	assert.Equal(t, testCategory.ID, *retrievedCategory.ParentID)
+
+	inUse, err = transactionRepo.HasCategoryReferences(context.Background(), childCategory.ID)
+	require.NoError(t, err)
+	assert.False(t, inUse)
+
 	// Test deleting category
 	err = transactionRepo.DeleteCategory(context.Background(), testCategory.ID)
 	require.NoError(t, err)
@@ -795,7 +1174,7 @@ func TestTransactionIntegration_APIHandlers(t *testing.T) {
 	transactionRepo := NewTestTransactionRepository(db.DB)
 
 	// Create transaction service
-	transactionService := transaction.NewService(transactionRepo)
+	transactionService := transaction.NewService(transactionRepo, transaction.CategorizationConfig{})
 
 	// Setup user service
 	userService := user.NewService(userRepo, "test-secret")
@@ -216,6 +216,75 @@ func (r *TestRepository) RevokeAllUserSessions(ctx context.Context, userID uuid.
 	return r.db.WithContext(ctx).Where("user_id = ?", userID.String()).Delete(&TestUserSession{}).Error
 }
 
+func (r *TestRepository) CreateFamily(ctx context.Context, family *user.Family, owner *user.FamilyMember) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		testFamily := &TestFamily{
+			ID:         family.ID.String(),
+			Name:       family.Name,
+			OwnerID:    family.OwnerID.String(),
+			InviteCode: family.InviteCode,
+			CreatedAt:  family.CreatedAt,
+			UpdatedAt:  family.UpdatedAt,
+		}
+		if err := tx.Create(testFamily).Error; err != nil {
+			return err
+		}
+		owner.FamilyID = family.ID
+		return (&TestRepository{db: tx}).AddFamilyMember(ctx, owner)
+	})
+}
+
+func (r *TestRepository) GetFamilyByInviteCode(ctx context.Context, inviteCode string) (*user.Family, error) {
+	var testFamily TestFamily
+	err := r.db.WithContext(ctx).Where("invite_code = ?", inviteCode).First(&testFamily).Error
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, user.ErrFamilyNotFound
+		}
+		return nil, err
+	}
+	return &user.Family{
+		ID:         uuid.MustParse(testFamily.ID),
+		Name:       testFamily.Name,
+		OwnerID:    uuid.MustParse(testFamily.OwnerID),
+		InviteCode: testFamily.InviteCode,
+		CreatedAt:  testFamily.CreatedAt,
+		UpdatedAt:  testFamily.UpdatedAt,
+	}, nil
+}
+
+func (r *TestRepository) GetFamilyMember(ctx context.Context, familyID, userID uuid.UUID) (*user.FamilyMember, error) {
+	var testMember TestFamilyMember
+	err := r.db.WithContext(ctx).Where("family_id = ? AND user_id = ?", familyID.String(), userID.String()).First(&testMember).Error
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, user.ErrFamilyMemberNotFound
+		}
+		return nil, err
+	}
+	return &user.FamilyMember{
+		ID:        uuid.MustParse(testMember.ID),
+		FamilyID:  familyID,
+		UserID:    userID,
+		Role:      user.UserRole(testMember.Role),
+		CreatedAt: testMember.CreatedAt,
+	}, nil
+}
+
+func (r *TestRepository) AddFamilyMember(ctx context.Context, member *user.FamilyMember) error {
+	return r.db.WithContext(ctx).Create(&TestFamilyMember{
+		ID:        member.ID.String(),
+		FamilyID:  member.FamilyID.String(),
+		UserID:    member.UserID.String(),
+		Role:      string(member.Role),
+		CreatedAt: member.CreatedAt,
+	}).Error
+}
+
+func (r *TestRepository) RemoveFamilyMember(ctx context.Context, familyID, userID uuid.UUID) error {
+	return r.db.WithContext(ctx).Where("family_id = ? AND user_id = ?", familyID.String(), userID.String()).Delete(&TestFamilyMember{}).Error
+}
+
 func TestUserIntegration_RegisterAndLogin(t *testing.T) {
 	// Setup test database
 	db := NewTestDatabase(t)
@@ -515,4 +584,38 @@ func TestUserIntegration_RepositoryOperations(t *testing.T) {
 	require.NoError(t, err)
 	assert.Equal(t, user2.ID, remainingUser.ID)
 }
- 
\ No newline at end of file
+
+func TestUserIntegration_Families(t *testing.T) {
+	db := NewTestDatabase(t)
+	defer db.Cleanup()
+	ctx := context.Background()
+
+	userService := user.NewService(NewTestRepository(db.DB), "test-secret")
+	ownerID := uuid.New()
+	memberID := uuid.New()
+
+	// The owner creates the family and shares its invite code
+	family, err := userService.CreateFamily(ctx, ownerID, &user.CreateFamilyRequest{Name: "Doe household"})
+	require.NoError(t, err)
+	assert.Equal(t, ownerID, family.OwnerID)
+	require.NotEmpty(t, family.InviteCode)
+
+	joined, err := userService.JoinFamily(ctx, memberID, &user.JoinFamilyRequest{InviteCode: family.InviteCode})
+	require.NoError(t, err)
+	assert.Equal(t, family.ID, joined.ID)
+	assert.Empty(t, joined.InviteCode)
+
+	_, err = userService.JoinFamily(ctx, memberID, &user.JoinFamilyRequest{InviteCode: family.InviteCode})
+	assert.ErrorIs(t, err, user.ErrAlreadyFamilyMember)
+	_, err = userService.JoinFamily(ctx, memberID, &user.JoinFamilyRequest{InviteCode: "unknown"})
+	assert.ErrorIs(t, err, user.ErrInvalidInviteCode)
+
+	var members int64
+	require.NoError(t, db.DB.Model(&TestFamilyMember{}).Where("family_id = ?", family.ID.String()).Count(&members).Error)
+	assert.Equal(t, int64(2), members)
+
+	// Members can leave, the owner cannot
+	assert.ErrorIs(t, userService.LeaveFamily(ctx, ownerID, family.ID), user.ErrFamilyOwnerCannotLeave)
+	require.NoError(t, userService.LeaveFamily(ctx, memberID, family.ID))
+	assert.ErrorIs(t, userService.LeaveFamily(ctx, memberID, family.ID), user.ErrNotFamilyMember)
+}